@@ -0,0 +1,55 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/lease"
+	"github.com/syncthing/syncthing/lib/locations"
+	"github.com/syncthing/syncthing/lib/sdnotify"
+	"github.com/syncthing/syncthing/lib/svcutil"
+)
+
+// In active/standby mode two instances share the same home directory on
+// shared storage, and thereby the device key, config and database. Only
+// the one holding the lock on the home directory is active; the other
+// waits on standby until the active one exits, and then takes over as the
+// same device with the same index, as if the previous one had restarted.
+
+// activeLease waits until we hold the lock on the home directory, which we
+// keep until release is called.
+func activeLease(ctx context.Context) (release func()) {
+	ls := lease.New(locations.Get(locations.LockFile))
+
+	waitingLogged := false
+	err := ls.Acquire(ctx, func() {
+		if !waitingLogged {
+			slog.Info("Waiting on standby for the active instance to stop")
+			waitingLogged = true
+		}
+		// Standing by is a perfectly fine state of the service as far as
+		// systemd is concerned.
+		states := []string{sdnotify.Ready, sdnotify.Status("Standby, waiting for the active instance to stop")}
+		if sdnotify.WatchdogInterval() > 0 {
+			states = append(states, sdnotify.Watchdog)
+		}
+		_ = sdnotify.Notify(states...)
+	})
+	if err != nil {
+		slog.Error("Failed to acquire active lease", slogutil.Error(err))
+		os.Exit(svcutil.ExitError.AsInt())
+	}
+	slog.Info("Acquired active lease, starting up as the active instance")
+
+	return func() {
+		_ = ls.Release()
+	}
+}
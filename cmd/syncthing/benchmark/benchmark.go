@@ -0,0 +1,286 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package benchmark implements the `syncthing benchmark` subcommand.
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/syncthing/syncthing/internal/db/sqlite"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/scanner"
+)
+
+const (
+	bufferSize = 16 << 20
+	dbBatch    = 1000
+	mib        = 1 << 20
+)
+
+type CLI struct {
+	Path     string        `arg:"" optional:"" placeholder:"PATH" help:"Folder to measure disk performance in (disk tests are skipped when not given)"`
+	Duration time.Duration `default:"3s" help:"How long to run each hashing test"`
+	DBFiles  int           `name:"db-files" default:"20000" placeholder:"N" help:"Number of files to write in the database test"`
+	CopySize int64         `default:"256" placeholder:"MiB" help:"Size of the file used for the disk copy test, in MiB"`
+	ReadSize int64         `default:"1024" placeholder:"MiB" help:"Maximum amount of existing data to read in the disk read test, in MiB"`
+}
+
+// results are in bytes per second, or files per second for the database.
+type results struct {
+	sha256  float64
+	xchacha float64
+	dbFiles float64
+	read    float64
+	copy    float64
+}
+
+func (c *CLI) Run() error {
+	buf := make([]byte, bufferSize)
+	_, _ = rand.Read(buf)
+
+	var res results
+	var err error
+
+	fmt.Println("Hashing and encryption (single core):")
+	res.sha256 = c.measureHashing(buf)
+	report("SHA-256 block hashing", res.sha256/mib, "MiB/s")
+	res.xchacha, err = c.measureEncryption(buf)
+	if err != nil {
+		return err
+	}
+	report("XChaCha20-Poly1305 encryption", res.xchacha/mib, "MiB/s (untrusted devices)")
+
+	fmt.Println("Database:")
+	res.dbFiles, err = c.measureDatabase()
+	if err != nil {
+		return fmt.Errorf("database test: %w", err)
+	}
+	report("File record writes", res.dbFiles, "files/s")
+
+	if c.Path != "" {
+		fmt.Println("Disk:", c.Path)
+		res.read, err = c.measureRead()
+		if err != nil {
+			return fmt.Errorf("disk read test: %w", err)
+		}
+		if res.read > 0 {
+			report("Read existing files", res.read/mib, "MiB/s")
+		} else {
+			fmt.Printf("  %-30s (no files to read)\n", "Read existing files")
+		}
+		res.copy, err = c.measureCopy(buf)
+		if err != nil {
+			return fmt.Errorf("disk copy test: %w", err)
+		}
+		report("Write and copy", res.copy/mib, "MiB/s")
+	}
+
+	fmt.Println("Expected rates:")
+	exp := res.expected()
+	report("Scanning, per hasher", exp.scan/mib, "MiB/s")
+	report("Syncing large files", exp.sync/mib, "MiB/s (before network limits)")
+	report("Syncing small files", exp.syncFiles, "files/s")
+	if exp.diskBound {
+		fmt.Println("The disk is slower than hashing; it is the bottleneck for scans.")
+	}
+	return nil
+}
+
+// expectedRates are what the measurements mean for scanning and syncing.
+type expectedRates struct {
+	scan      float64 // bytes per second, per hasher
+	sync      float64 // bytes per second for large files
+	syncFiles float64 // files per second for small files
+	diskBound bool    // the disk, not hashing, limits scans
+}
+
+// expected returns the rates expected from the slowest of the measured
+// steps involved. Disk measurements of zero were not made, and don't
+// limit anything.
+func (r results) expected() expectedRates {
+	exp := expectedRates{
+		scan:      r.sha256,
+		sync:      r.sha256,
+		syncFiles: r.dbFiles,
+	}
+	if r.read > 0 {
+		exp.scan = min(exp.scan, r.read)
+		exp.diskBound = r.read < r.sha256
+	}
+	if r.copy > 0 {
+		exp.sync = min(exp.sync, r.copy)
+	}
+	return exp
+}
+
+func report(label string, value float64, unit string) {
+	fmt.Printf("  %-30s %10.1f %s\n", label, value, unit)
+}
+
+func (c *CLI) measureHashing(buf []byte) float64 {
+	ctx := context.Background()
+	var n int64
+	t0 := time.Now()
+	for time.Since(t0) < c.Duration {
+		if _, err := scanner.Blocks(ctx, bytes.NewReader(buf), protocol.MinBlockSize, int64(len(buf)), nil); err != nil {
+			break
+		}
+		n += int64(len(buf))
+	}
+	return float64(n) / time.Since(t0).Seconds()
+}
+
+func (c *CLI) measureEncryption(buf []byte) (float64, error) {
+	var key [chacha20poly1305.KeySize]byte
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return 0, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	out := make([]byte, 0, protocol.MinBlockSize+aead.Overhead())
+	var n int64
+	t0 := time.Now()
+	for time.Since(t0) < c.Duration {
+		for off := 0; off+protocol.MinBlockSize <= len(buf); off += protocol.MinBlockSize {
+			out = aead.Seal(out[:0], nonce, buf[off:off+protocol.MinBlockSize], nil)
+		}
+		n += int64(len(buf))
+	}
+	return float64(n) / time.Since(t0).Seconds(), nil
+}
+
+func (c *CLI) measureDatabase() (float64, error) {
+	dir, err := os.MkdirTemp("", "syncthing-benchmark-db-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+	sdb, err := sqlite.Open(dir)
+	if err != nil {
+		return 0, err
+	}
+	defer sdb.Close()
+
+	files := make([]protocol.FileInfo, 0, dbBatch)
+	t0 := time.Now()
+	for i := 0; i < c.DBFiles; i++ {
+		files = append(files, benchmarkFile(i))
+		if len(files) == dbBatch || i == c.DBFiles-1 {
+			if err := sdb.Update("benchmark", protocol.LocalDeviceID, files); err != nil {
+				return 0, err
+			}
+			files = files[:0]
+		}
+	}
+	return float64(c.DBFiles) / time.Since(t0).Seconds(), nil
+}
+
+// benchmarkFile returns a file record of typical size, with a few blocks.
+func benchmarkFile(i int) protocol.FileInfo {
+	name := fmt.Sprintf("dir%03d/file%06d", i%1000, i)
+	blocks := make([]protocol.BlockInfo, 4)
+	for j := range blocks {
+		hash := sha256.Sum256(fmt.Appendf(nil, "%s-%d", name, j))
+		blocks[j] = protocol.BlockInfo{Hash: hash[:], Offset: int64(j) * protocol.MinBlockSize, Size: protocol.MinBlockSize}
+	}
+	return protocol.FileInfo{
+		Name:         name,
+		Size:         int64(len(blocks)) * protocol.MinBlockSize,
+		ModifiedS:    time.Now().Unix(),
+		Version:      protocol.Vector{}.Update(1),
+		Permissions:  0o644,
+		Blocks:       blocks,
+		RawBlockSize: protocol.MinBlockSize,
+	}
+}
+
+// measureRead reads existing files in the folder. The numbers will be
+// optimistic if the files are already in the page cache.
+func (c *CLI) measureRead() (float64, error) {
+	limit := c.ReadSize * mib
+	buf := make([]byte, mib)
+	var n int64
+	var elapsed time.Duration
+	errDone := errors.New("done")
+	err := filepath.WalkDir(c.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil //nolint:nilerr
+		}
+		fd, err := os.Open(path)
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+		defer fd.Close()
+		t0 := time.Now()
+		read, _ := io.CopyBuffer(io.Discard, io.LimitReader(fd, limit-n), buf)
+		elapsed += time.Since(t0)
+		n += read
+		if n >= limit {
+			return errDone
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDone) {
+		return 0, err
+	}
+	if n == 0 || elapsed == 0 {
+		return 0, nil
+	}
+	return float64(n) / elapsed.Seconds(), nil
+}
+
+// measureCopy writes a file in the folder and then copies it, the way the
+// puller assembles a file from blocks in a temporary file. Both files are
+// synced to disk so that the page cache doesn't hide the real speed.
+func (c *CLI) measureCopy(buf []byte) (float64, error) {
+	src, err := os.CreateTemp(c.Path, ".syncthing-benchmark-*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(src.Name())
+	defer src.Close()
+	dst, err := os.CreateTemp(c.Path, ".syncthing-benchmark-*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(dst.Name())
+	defer dst.Close()
+
+	size := c.CopySize * mib
+	t0 := time.Now()
+	for written := int64(0); written < size; written += int64(len(buf)) {
+		if _, err := src.Write(buf[:min(int64(len(buf)), size-written)]); err != nil {
+			return 0, err
+		}
+	}
+	if err := src.Sync(); err != nil {
+		return 0, err
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if _, err := io.CopyBuffer(dst, src, make([]byte, protocol.MinBlockSize)); err != nil {
+		return 0, err
+	}
+	if err := dst.Sync(); err != nil {
+		return 0, err
+	}
+	// Each byte was written twice and read once
+	return float64(2*size) / time.Since(t0).Seconds(), nil
+}
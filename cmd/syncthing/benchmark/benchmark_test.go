@@ -0,0 +1,100 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package benchmark
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestExpectedRates(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		res  results
+		exp  expectedRates
+	}{
+		{
+			name: "no disk tests",
+			res:  results{sha256: 500 * mib, dbFiles: 2000},
+			exp:  expectedRates{scan: 500 * mib, sync: 500 * mib, syncFiles: 2000},
+		},
+		{
+			name: "fast disk",
+			res:  results{sha256: 500 * mib, dbFiles: 2000, read: 1000 * mib, copy: 800 * mib},
+			exp:  expectedRates{scan: 500 * mib, sync: 500 * mib, syncFiles: 2000},
+		},
+		{
+			name: "slow disk",
+			res:  results{sha256: 500 * mib, dbFiles: 2000, read: 100 * mib, copy: 50 * mib},
+			exp:  expectedRates{scan: 100 * mib, sync: 50 * mib, syncFiles: 2000, diskBound: true},
+		},
+		{
+			name: "nothing to read",
+			res:  results{sha256: 500 * mib, dbFiles: 2000, copy: 50 * mib},
+			exp:  expectedRates{scan: 500 * mib, sync: 50 * mib, syncFiles: 2000},
+		},
+	}
+	for _, tc := range cases {
+		if exp := tc.res.expected(); exp != tc.exp {
+			t.Errorf("%s: expected %+v, got %+v", tc.name, tc.exp, exp)
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	t.Parallel()
+
+	var cli struct {
+		Benchmark CLI `cmd:""`
+	}
+	parser, err := kong.New(&cli)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parser.Parse([]string{"benchmark"}); err != nil {
+		t.Fatal(err)
+	}
+	def := CLI{Duration: 3 * time.Second, DBFiles: 20000, CopySize: 256, ReadSize: 1024}
+	if cli.Benchmark != def {
+		t.Errorf("expected defaults %+v, got %+v", def, cli.Benchmark)
+	}
+
+	if _, err := parser.Parse([]string{"benchmark", "--duration=1s", "--db-files=10", "--copy-size=1", "--read-size=2", "/data"}); err != nil {
+		t.Fatal(err)
+	}
+	exp := CLI{Path: "/data", Duration: time.Second, DBFiles: 10, CopySize: 1, ReadSize: 2}
+	if cli.Benchmark != exp {
+		t.Errorf("expected %+v, got %+v", exp, cli.Benchmark)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "existing"), make([]byte, 1<<20), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := &CLI{Path: dir, Duration: 10 * time.Millisecond, DBFiles: 10, CopySize: 1, ReadSize: 1}
+	if err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The files written for the copy test are removed.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the existing file to be left, got %v", entries)
+	}
+}
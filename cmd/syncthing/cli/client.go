@@ -18,8 +18,10 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/keystore"
 	"github.com/syncthing/syncthing/lib/locations"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
@@ -28,6 +30,7 @@ type APIClient interface {
 	Get(url string) (*http.Response, error)
 	Post(url, body string) (*http.Response, error)
 	PutJSON(url string, o interface{}) (*http.Response, error)
+	Delete(url, body string) (*http.Response, error)
 }
 
 type apiClient struct {
@@ -41,10 +44,16 @@ type apiClientFactory struct {
 	cfg config.GUIConfiguration
 }
 
+// isLocal returns true when no remote instance was given, i.e. the client
+// talks to the instance on this host.
+func (f *apiClientFactory) isLocal() bool {
+	return f.cfg.RawAddress == "" && f.cfg.APIKey == ""
+}
+
 func (f *apiClientFactory) getClient() (APIClient, error) {
 	// Now if the API key and address is not provided (we are not connecting to a remote instance),
 	// try to rip it out of the config.
-	if f.cfg.RawAddress == "" && f.cfg.APIKey == "" {
+	if f.isLocal() {
 		var err error
 		f.cfg, err = loadGUIConfig()
 		if err != nil {
@@ -72,21 +81,9 @@ func (f *apiClientFactory) getClient() (APIClient, error) {
 }
 
 func loadGUIConfig() (config.GUIConfiguration, error) {
-	// Load the certs and get the ID
-	cert, err := tls.LoadX509KeyPair(
-		locations.Get(locations.CertFile),
-		locations.Get(locations.KeyFile),
-	)
+	cfg, err := loadLocalConfig()
 	if err != nil {
-		return config.GUIConfiguration{}, fmt.Errorf("reading device ID: %w", err)
-	}
-
-	myID := protocol.NewDeviceID(cert.Certificate[0])
-
-	// Load the config
-	cfg, _, err := config.Load(locations.Get(locations.ConfigFile), myID, events.NoopLogger)
-	if err != nil {
-		return config.GUIConfiguration{}, fmt.Errorf("loading config: %w", err)
+		return config.GUIConfiguration{}, err
 	}
 
 	guiCfg := cfg.GUI()
@@ -102,6 +99,27 @@ func loadGUIConfig() (config.GUIConfiguration, error) {
 	return guiCfg, nil
 }
 
+// loadLocalConfig loads the config of the Syncthing instance on this host.
+func loadLocalConfig() (config.Wrapper, error) {
+	// Load the certs and get the ID
+	cert, err := keystore.LoadX509KeyPair(
+		locations.Get(locations.CertFile),
+		locations.Get(locations.KeyFile),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("reading device ID: %w", err)
+	}
+
+	myID := protocol.NewDeviceID(cert.Certificate[0])
+
+	// Load the config
+	cfg, _, err := config.Load(locations.Get(locations.ConfigFile), myID, events.NoopLogger)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	return cfg, nil
+}
+
 func (c *apiClient) Endpoint() string {
 	if c.cfg.Network() == "unix" {
 		return "http://unix/"
@@ -115,6 +133,8 @@ func (c *apiClient) Endpoint() string {
 
 func (c *apiClient) Do(req *http.Request) (*http.Response, error) {
 	req.Header.Set("X-Api-Key", c.apikey)
+	// Lets the config history tell CLI changes apart from other API clients
+	req.Header.Set("User-Agent", "syncthing-cli/"+build.Version)
 	resp, err := c.Client.Do(req)
 	if err != nil {
 		return nil, err
@@ -154,6 +174,10 @@ func (c *apiClient) PutJSON(url string, o interface{}) (*http.Response, error) {
 	return c.RequestJSON(url, "PUT", o)
 }
 
+func (c *apiClient) Delete(url, body string) (*http.Response, error) {
+	return c.RequestString(url, "DELETE", body)
+}
+
 var errNotFound = errors.New("invalid endpoint or API call")
 
 func checkResponse(response *http.Response) error {
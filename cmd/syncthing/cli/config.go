@@ -7,6 +7,8 @@
 package cli
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,7 +17,9 @@ import (
 
 	"github.com/AudriusButkevicius/recli"
 	"github.com/alecthomas/kong"
+	"github.com/gofrs/flock"
 	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/locations"
 	"github.com/urfave/cli"
 )
 
@@ -70,6 +74,10 @@ type configHandler struct {
 	original, cfg config.Configuration
 	client        APIClient
 	err           error
+
+	// When no instance is running the config file is edited directly,
+	// holding the lock that keeps an instance from starting meanwhile.
+	offline config.Wrapper
 }
 
 type configCommand struct {
@@ -90,9 +98,21 @@ func (c *configCommand) Run(ctx Context, outerCtx *kong.Context) error {
 	cli.SubcommandHelpTemplate = customSubcommandHelpTemplate
 
 	h := new(configHandler)
-	h.client, h.err = ctx.clientFactory.getClient()
-	if h.err == nil {
-		h.cfg, h.err = getConfig(h.client)
+	if ctx.clientFactory.isLocal() {
+		lock := flock.New(locations.Get(locations.LockFile))
+		if locked, err := lock.TryLock(); err == nil && locked {
+			defer func() { _ = lock.Unlock() }()
+			h.offline, h.err = loadLocalConfig()
+			if h.err == nil {
+				h.cfg = h.offline.RawCopy()
+			}
+		}
+	}
+	if h.offline == nil && h.err == nil {
+		h.client, h.err = ctx.clientFactory.getClient()
+		if h.err == nil {
+			h.cfg, h.err = getConfig(h.client)
+		}
 	}
 	h.original = h.cfg.Copy()
 
@@ -137,6 +157,9 @@ func (h *configHandler) configAfter(_ *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	if h.offline != nil {
+		return h.saveOffline(body)
+	}
 	resp, err := h.client.Post("system/config", string(body))
 	if err != nil {
 		return err
@@ -150,3 +173,32 @@ func (h *configHandler) configAfter(_ *cli.Context) error {
 	}
 	return nil
 }
+
+// saveOffline validates the changed config the same way the REST API does
+// and writes it to the config file. The change goes through the loaded
+// wrapper so that references to secrets are written back as they were.
+func (h *configHandler) saveOffline(body []byte) error {
+	cfg, err := config.ReadJSON(bytes.NewReader(body), h.offline.MyID())
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = h.offline.Serve(ctx)
+		close(done)
+	}()
+	waiter, err := h.offline.Modify(func(c *config.Configuration) {
+		*c = cfg
+	})
+	if err == nil {
+		waiter.Wait()
+	}
+	cancel()
+	<-done
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return h.offline.Save()
+}
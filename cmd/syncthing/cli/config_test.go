@@ -0,0 +1,64 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestClientFactoryIsLocal(t *testing.T) {
+	t.Parallel()
+
+	if f := (&apiClientFactory{}); !f.isLocal() {
+		t.Error("expected a factory without address or API key to be local")
+	}
+	if f := (&apiClientFactory{cfg: config.GUIConfiguration{RawAddress: "192.0.2.1:8384"}}); f.isLocal() {
+		t.Error("expected a factory with an address not to be local")
+	}
+	if f := (&apiClientFactory{cfg: config.GUIConfiguration{APIKey: "abc"}}); f.isLocal() {
+		t.Error("expected a factory with an API key not to be local")
+	}
+}
+
+func TestSaveOffline(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.xml")
+	myID := protocol.LocalDeviceID
+	w := config.Wrap(path, config.New(myID), myID, events.NoopLogger)
+	if err := w.Save(); err != nil {
+		t.Fatal(err)
+	}
+	h := &configHandler{offline: w, cfg: w.RawCopy()}
+
+	h.cfg.Options.MaxSendKbps = 42
+	body, err := json.Marshal(h.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.saveOffline(body); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, _, err := config.Load(path, myID, events.NoopLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := saved.Options().MaxSendKbps; got != 42 {
+		t.Errorf("expected the change to be saved, got MaxSendKbps %d", got)
+	}
+
+	if err := h.saveOffline([]byte(`{"version": "not a number"}`)); err == nil {
+		t.Error("expected an invalid config to be rejected")
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package cli
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/model"
+)
+
+const (
+	maxClockSkew     = 30 * time.Second
+	inotifyWatchFile = "/proc/sys/fs/inotify/max_user_watches"
+)
+
+type doctorCommand struct{}
+
+type findingSeverity string
+
+const (
+	findingOK   findingSeverity = "ok"
+	findingWarn findingSeverity = "warn"
+	findingFail findingSeverity = "fail"
+)
+
+// A finding is the outcome of a single check, with advice on what to do
+// about it when it's not ok.
+type finding struct {
+	severity findingSeverity
+	check    string
+	message  string
+	advice   string
+}
+
+// doctorHealth is the subset of the /rest/system/health response that we
+// look at.
+type doctorHealth struct {
+	Subsystems map[string]struct {
+		Status  string   `json:"status"`
+		Reasons []string `json:"reasons"`
+	} `json:"subsystems"`
+}
+
+func (*doctorCommand) Run(ctx Context) error {
+	client, err := ctx.clientFactory.getClient()
+	if err != nil {
+		return err
+	}
+	cfg, err := getConfig(client)
+	if err != nil {
+		return err
+	}
+
+	var findings []finding
+	findings = append(findings, checkClock(client, cfg)...)
+	findings = append(findings, checkConnectivity(client)...)
+	if isLocalClient(client) {
+		findings = append(findings, checkInotify(client, cfg)...)
+	}
+	for _, fcfg := range cfg.Folders {
+		if fcfg.Paused {
+			continue
+		}
+		findings = append(findings, checkCaseConflicts(client, fcfg)...)
+		findings = append(findings, checkIgnores(client, fcfg)...)
+	}
+
+	problems := 0
+	for _, f := range findings {
+		fmt.Printf("[%s] %s: %s\n", strings.ToUpper(string(f.severity)), f.check, f.message)
+		if f.advice != "" {
+			fmt.Printf("       %s\n", f.advice)
+		}
+		if f.severity != findingOK {
+			problems++
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf("%d problem(s) found", problems)
+	}
+	return nil
+}
+
+// checkClock compares the clock of the Syncthing instance to the clock of
+// the global discovery servers it uses. Modification times are compared
+// between devices, so a skewed clock makes for surprising conflicts.
+func checkClock(client APIClient, cfg config.Configuration) []finding {
+	resp, err := client.Get("system/ping")
+	if err != nil {
+		return []finding{{findingWarn, "clock", "could not query instance time: " + err.Error(), ""}}
+	}
+	resp.Body.Close()
+	local, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		local = time.Now()
+	}
+
+	httpc := &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			// Discovery servers are identified by device ID, not by a
+			// certificate authority; we only want the time.
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	for _, srv := range cfg.Options.GlobalDiscoveryServers() {
+		u, err := url.Parse(srv)
+		if err != nil {
+			continue
+		}
+		u.RawQuery = ""
+		resp, err := httpc.Head(u.String())
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		remote, err := http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			continue
+		}
+		skew := local.Sub(remote)
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > maxClockSkew {
+			return []finding{{findingWarn, "clock", fmt.Sprintf("clock differs by %v from %s", skew.Truncate(time.Second), u.Host), "Enable time synchronisation (NTP) on this host."}}
+		}
+		return []finding{{findingOK, "clock", "clock is in sync with " + u.Host, ""}}
+	}
+	return []finding{{findingWarn, "clock", "no time reference could be reached", "Check that global discovery servers are reachable, or verify the clock by other means."}}
+}
+
+// checkConnectivity reports on listeners, relays and discovery, as seen by
+// the instance itself.
+func checkConnectivity(client APIClient) []finding {
+	health, err := getHealth(client)
+	if err != nil {
+		return []finding{{findingWarn, "connectivity", "could not query health: " + err.Error(), ""}}
+	}
+
+	advice := map[string]string{
+		"listeners": "Check that the listen port (default 22000, TCP and UDP) is free and allowed through the firewall.",
+		"relays":    "Check that outgoing connections are allowed, or disable relaying if it is not wanted.",
+		"discovery": "Check that outgoing HTTPS connections are allowed and that DNS resolves the discovery servers.",
+	}
+	var findings []finding
+	for _, name := range []string{"listeners", "relays", "discovery"} {
+		sub, ok := health.Subsystems[name]
+		if !ok {
+			continue
+		}
+		switch sub.Status {
+		case "failed", "degraded":
+			sev := findingWarn
+			if sub.Status == "failed" {
+				sev = findingFail
+			}
+			findings = append(findings, finding{sev, name, strings.Join(sub.Reasons, "; "), advice[name]})
+		case "disabled":
+			findings = append(findings, finding{findingOK, name, "disabled", ""})
+		default:
+			findings = append(findings, finding{findingOK, name, "working", ""})
+		}
+	}
+	return findings
+}
+
+// getHealth returns the health report. The report is served with status
+// 503 when something has failed, which the API client would otherwise turn
+// into an error.
+func getHealth(client APIClient) (doctorHealth, error) {
+	var health doctorHealth
+	c, ok := client.(*apiClient)
+	if !ok {
+		return health, errors.New("unsupported client")
+	}
+	req, err := http.NewRequest(http.MethodGet, c.Endpoint()+"rest/system/health", nil)
+	if err != nil {
+		return health, err
+	}
+	req.Header.Set("X-Api-Key", c.apikey)
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return health, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return health, checkResponse(resp)
+	}
+	bs, err := responseToBArray(resp)
+	if err != nil {
+		return health, err
+	}
+	return health, json.Unmarshal(bs, &health)
+}
+
+// checkInotify compares the number of directories to watch against the
+// Linux inotify watch limit. Only meaningful when the instance runs on this
+// host.
+func checkInotify(client APIClient, cfg config.Configuration) []finding {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	bs, err := os.ReadFile(inotifyWatchFile)
+	if err != nil {
+		return nil
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(string(bs)))
+	if err != nil {
+		return nil
+	}
+
+	dirs := 0
+	for _, fcfg := range cfg.Folders {
+		if !fcfg.FSWatcherEnabled || fcfg.Paused {
+			continue
+		}
+		var status struct {
+			LocalDirectories int `json:"localDirectories"`
+		}
+		resp, err := client.Get("db/status?folder=" + url.QueryEscape(fcfg.ID))
+		if err != nil {
+			continue
+		}
+		if bs, err := responseToBArray(resp); err == nil {
+			_ = json.Unmarshal(bs, &status)
+		}
+		dirs += status.LocalDirectories
+	}
+
+	if dirs >= limit {
+		return []finding{{findingFail, "inotify", fmt.Sprintf("%d directories to watch but the limit is %d", dirs, limit), "Raise fs.inotify.max_user_watches with sysctl."}}
+	} else if dirs > limit*8/10 {
+		return []finding{{findingWarn, "inotify", fmt.Sprintf("%d directories to watch, close to the limit of %d", dirs, limit), "Raise fs.inotify.max_user_watches with sysctl."}}
+	}
+	return []finding{{findingOK, "inotify", fmt.Sprintf("%d directories to watch, limit is %d", dirs, limit), ""}}
+}
+
+// checkCaseConflicts looks for sync failures caused by files that differ
+// only in case, which can't coexist on a case-insensitive filesystem.
+func checkCaseConflicts(client APIClient, fcfg config.FolderConfiguration) []finding {
+	var res struct {
+		Errors []model.FileError `json:"errors"`
+	}
+	resp, err := client.Get("folder/errors?folder=" + url.QueryEscape(fcfg.ID))
+	if err != nil {
+		return nil
+	}
+	if bs, err := responseToBArray(resp); err == nil {
+		_ = json.Unmarshal(bs, &res)
+	}
+
+	// The error itself doesn't survive the trip through the API, but its
+	// category was determined from it by the instance.
+	var paths []string
+	for _, e := range res.Errors {
+		if e.Category == model.ErrorCategoryCaseConflict {
+			paths = append(paths, e.Path)
+		}
+	}
+	check := "folder " + fcfg.Description()
+	if len(paths) > 0 {
+		return []finding{{findingWarn, check, fmt.Sprintf("%d case conflicts, e.g. %q", len(paths), paths[0]), "Rename the files on the other devices so that names differ by more than case."}}
+	}
+	if fcfg.CaseSensitiveFS && (runtime.GOOS == "windows" || runtime.GOOS == "darwin") {
+		return []finding{{findingWarn, check, "case sensitive filesystem assumed on a platform that usually isn't", "Unset caseSensitiveFS unless the filesystem really is case sensitive."}}
+	}
+	return nil
+}
+
+// checkIgnores looks for ignore patterns that probably don't do what the
+// user intended.
+func checkIgnores(client APIClient, fcfg config.FolderConfiguration) []finding {
+	var res struct {
+		Ignore []string `json:"ignore"`
+		Error  *string  `json:"error"`
+	}
+	resp, err := client.Get("db/ignores?folder=" + url.QueryEscape(fcfg.ID))
+	if err != nil {
+		return nil
+	}
+	if bs, err := responseToBArray(resp); err == nil {
+		_ = json.Unmarshal(bs, &res)
+	}
+
+	check := "folder " + fcfg.Description()
+	if res.Error != nil && *res.Error != "" {
+		return []finding{{findingFail, check, "ignore patterns failed to load: " + *res.Error, "Fix the .stignore file in the folder root."}}
+	}
+	var findings []finding
+	for _, line := range res.Ignore {
+		pat := strings.TrimPrefix(line, "!")
+		switch {
+		case strings.HasPrefix(line, "//"), line == "":
+			// Comments and blank lines
+		case strings.TrimRight(line, " \t") != line:
+			findings = append(findings, finding{findingWarn, check, fmt.Sprintf("ignore pattern %q has trailing whitespace", line), "Trailing whitespace is part of the pattern; remove it."})
+		case runtime.GOOS != "windows" && strings.Contains(pat, `\`) && !strings.ContainsAny(pat, "*?[{"):
+			findings = append(findings, finding{findingWarn, check, fmt.Sprintf("ignore pattern %q contains a backslash", line), "Use forward slashes as path separators in ignore patterns."})
+		case slices.Contains([]string{"*", "**", "/*", "/**"}, pat) && !strings.HasPrefix(line, "!") && line != res.Ignore[len(res.Ignore)-1]:
+			findings = append(findings, finding{findingWarn, check, fmt.Sprintf("ignore pattern %q ignores everything, patterns after it have no effect", line), "Move the catch-all pattern last, after any exceptions."})
+		}
+	}
+	return findings
+}
+
+// isLocalClient returns true when the instance is on this host, so that
+// checks of the local system apply to it.
+func isLocalClient(client APIClient) bool {
+	c, ok := client.(*apiClient)
+	if !ok {
+		return false
+	}
+	if c.cfg.Network() == "unix" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(c.cfg.Address())
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package cli
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+// fakeClient answers GET requests with the canned response for the URL.
+type fakeClient map[string]string
+
+func (c fakeClient) Get(url string) (*http.Response, error) {
+	body, ok := c[url]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func (fakeClient) Post(string, string) (*http.Response, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (fakeClient) PutJSON(string, interface{}) (*http.Response, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (fakeClient) Delete(string, string) (*http.Response, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func TestCheckCaseConflicts(t *testing.T) {
+	t.Parallel()
+
+	fcfg := config.FolderConfiguration{ID: "default"}
+	client := fakeClient{
+		"folder/errors?folder=default": `{"errors": [
+			{"path": "a", "error": "not available because no device has it", "category": "not_available"},
+			{"path": "B", "error": "remote \"B\" uses different upper or lowercase characters", "category": "case_conflict"}
+		]}`,
+	}
+	findings := checkCaseConflicts(client, fcfg)
+	if len(findings) != 1 || findings[0].severity != findingWarn || !strings.Contains(findings[0].message, `"B"`) {
+		t.Errorf("expected a single case conflict for B, got %+v", findings)
+	}
+
+	client["folder/errors?folder=default"] = `{"errors": [{"path": "a", "error": "failed because of something", "category": "other"}]}`
+	if findings := checkCaseConflicts(client, fcfg); len(findings) != 0 {
+		t.Errorf("expected no case conflicts, got %+v", findings)
+	}
+}
+
+func TestCheckIgnores(t *testing.T) {
+	t.Parallel()
+
+	fcfg := config.FolderConfiguration{ID: "default"}
+	client := fakeClient{
+		"db/ignores?folder=default": `{"ignore": ["// comment", "", "*.tmp ", "*", "!keep"]}`,
+	}
+	findings := checkIgnores(client, fcfg)
+	if len(findings) != 2 {
+		t.Fatalf("expected two findings, got %+v", findings)
+	}
+	if !strings.Contains(findings[0].message, "trailing whitespace") {
+		t.Errorf("expected trailing whitespace finding, got %q", findings[0].message)
+	}
+	if !strings.Contains(findings[1].message, "ignores everything") {
+		t.Errorf("expected catch-all finding, got %q", findings[1].message)
+	}
+
+	client["db/ignores?folder=default"] = `{"ignore": [], "error": "bad pattern"}`
+	if findings := checkIgnores(client, fcfg); len(findings) != 1 || findings[0].severity != findingFail {
+		t.Errorf("expected a failure for broken ignores, got %+v", findings)
+	}
+}
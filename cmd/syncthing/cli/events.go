@@ -0,0 +1,103 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/events"
+)
+
+type eventsCommand struct {
+	Watch eventsWatchCommand `cmd:"" help:"Print events as they happen"`
+}
+
+type eventsWatchCommand struct {
+	Types []string `placeholder:"TYPE" help:"Comma separated list of event types to show (default is all except LocalChangeDetected and RemoteChangeDetected)"`
+	JSON  bool     `help:"Print each event as a single line JSON object"`
+	Since int      `placeholder:"ID" help:"Start after the given event ID instead of at the most recent event"`
+}
+
+// watchedEvent is an event as returned by the REST API, keeping the data
+// as-is.
+type watchedEvent struct {
+	ID   int             `json:"id"`
+	Time time.Time       `json:"time"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+func (e *eventsWatchCommand) Run(ctx Context) error {
+	for _, t := range e.Types {
+		if events.UnmarshalEventType(t) == 0 {
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+
+	client, err := ctx.clientFactory.getClient()
+	if err != nil {
+		return err
+	}
+
+	since := e.Since
+	if since == 0 {
+		// Skip past what has already happened
+		evs, err := e.poll(client, 0, 0)
+		if err != nil {
+			return err
+		}
+		if len(evs) > 0 {
+			since = evs[len(evs)-1].ID
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		evs, err := e.poll(client, since, 60)
+		if err != nil {
+			return err
+		}
+		for _, ev := range evs {
+			since = ev.ID
+			if e.JSON {
+				if err := enc.Encode(ev); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Printf("%s %d %s %s\n", ev.Time.Format(time.RFC3339), ev.ID, ev.Type, ev.Data)
+		}
+	}
+}
+
+func (e *eventsWatchCommand) poll(client APIClient, since, timeoutS int) ([]watchedEvent, error) {
+	query := make(url.Values)
+	query.Set("since", strconv.Itoa(since))
+	query.Set("timeout", strconv.Itoa(timeoutS))
+	if len(e.Types) > 0 {
+		query.Set("events", strings.Join(e.Types, ","))
+	}
+	response, err := client.Get("events?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	bs, err := responseToBArray(response)
+	if err != nil {
+		return nil, err
+	}
+	var evs []watchedEvent
+	if err := json.Unmarshal(bs, &evs); err != nil {
+		return nil, err
+	}
+	return evs, nil
+}
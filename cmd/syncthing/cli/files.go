@@ -0,0 +1,173 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/versioner"
+)
+
+type filesCommand struct {
+	Download filesDownloadCommand `cmd:"" help:"Download the current contents of a file"`
+	Versions filesVersionsCommand `cmd:"" help:"List the old versions kept of a file"`
+	Restore  filesRestoreCommand  `cmd:"" help:"Restore an old version of a file"`
+}
+
+type filesDownloadCommand struct {
+	FolderID string `arg:""`
+	Path     string `arg:""`
+	Output   string `short:"o" placeholder:"PATH" help:"Where to write the file (\"-\" for stdout, default is the file name in the current directory)"`
+}
+
+func (f *filesDownloadCommand) Run(ctx Context) error {
+	client, err := ctx.clientFactory.getClient()
+	if err != nil {
+		return err
+	}
+	query := make(url.Values)
+	query.Set("folder", f.FolderID)
+	query.Set("file", normalizePath(f.Path))
+	response, err := client.Get("folder/content?" + query.Encode())
+	if errors.Is(err, errNotFound) {
+		return errors.New("not found (no such folder or file)")
+	}
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if f.Output == "-" {
+		_, err := io.Copy(os.Stdout, response.Body)
+		return err
+	}
+	output := f.Output
+	if output == "" {
+		output = path.Base(normalizePath(f.Path))
+	}
+	fd, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(fd, response.Body); err != nil {
+		_ = fd.Close()
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+	fmt.Println("Wrote file to", output)
+	return nil
+}
+
+type filesVersionsCommand struct {
+	FolderID string `arg:""`
+	Path     string `arg:""`
+}
+
+func (f *filesVersionsCommand) Run(ctx Context) error {
+	client, err := ctx.clientFactory.getClient()
+	if err != nil {
+		return err
+	}
+	_, versions, err := fileVersions(client, f.FolderID, f.Path)
+	if err != nil {
+		return err
+	}
+	return prettyPrintJSON(versions)
+}
+
+type filesRestoreCommand struct {
+	FolderID    string    `arg:""`
+	Path        string    `arg:""`
+	VersionTime time.Time `name:"version-time" placeholder:"TIME" help:"Version time to restore, as shown by the versions command (default is the latest)"`
+}
+
+func (f *filesRestoreCommand) Run(ctx Context) error {
+	client, err := ctx.clientFactory.getClient()
+	if err != nil {
+		return err
+	}
+	name, versions, err := fileVersions(client, f.FolderID, f.Path)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no versions of %q found", f.Path)
+	}
+
+	version := slices.MaxFunc(versions, func(a, b versioner.FileVersion) int {
+		return a.VersionTime.Compare(b.VersionTime)
+	}).VersionTime
+	if !f.VersionTime.IsZero() {
+		idx := slices.IndexFunc(versions, func(v versioner.FileVersion) bool {
+			return v.VersionTime.Equal(f.VersionTime)
+		})
+		if idx < 0 {
+			return fmt.Errorf("no version of %q at %v", f.Path, f.VersionTime)
+		}
+		version = versions[idx].VersionTime
+	}
+
+	body, err := json.Marshal(map[string]time.Time{name: version})
+	if err != nil {
+		return err
+	}
+	query := make(url.Values)
+	query.Set("folder", f.FolderID)
+	response, err := client.Post("folder/versions?"+query.Encode(), string(body))
+	if err != nil {
+		return err
+	}
+	bs, err := responseToBArray(response)
+	if err != nil {
+		return err
+	}
+	var restoreErrors map[string]string
+	if err := json.Unmarshal(bs, &restoreErrors); err != nil {
+		return err
+	}
+	if msg, ok := restoreErrors[name]; ok {
+		return fmt.Errorf("restoring %q: %s", f.Path, msg)
+	}
+	fmt.Println("Restored", f.Path, "from version", version.Format(time.RFC3339))
+	return nil
+}
+
+// fileVersions returns the versions kept of the given file, along with the
+// name under which the versioner knows it.
+func fileVersions(client APIClient, folder, file string) (string, []versioner.FileVersion, error) {
+	query := make(url.Values)
+	query.Set("folder", folder)
+	response, err := client.Get("folder/versions?" + query.Encode())
+	if err != nil {
+		return "", nil, err
+	}
+	bs, err := responseToBArray(response)
+	if err != nil {
+		return "", nil, err
+	}
+	var all map[string][]versioner.FileVersion
+	if err := json.Unmarshal(bs, &all); err != nil {
+		return "", nil, err
+	}
+	file = normalizePath(file)
+	for name, versions := range all {
+		if normalizePath(name) == file {
+			return name, versions, nil
+		}
+	}
+	return file, nil, nil
+}
@@ -23,9 +23,13 @@ type CLI struct {
 
 	Show       showCommand      `cmd:"" help:"Show command group"`
 	Debug      debugCommand     `cmd:"" help:"Debug command group"`
+	Doctor     doctorCommand    `cmd:"" help:"Check for common problems and suggest fixes"`
 	Operations operationCommand `cmd:"" help:"Operation command group"`
 	Errors     errorsCommand    `cmd:"" help:"Error command group"`
-	Config     configCommand    `cmd:"" help:"Configuration modification command group" passthrough:""`
+	Events     eventsCommand    `cmd:"" help:"Event command group"`
+	Files      filesCommand     `cmd:"" help:"File content and version command group"`
+	Pair       pairCommand      `cmd:"" help:"Pair with another device using a one-time code"`
+	Config     configCommand    `cmd:"" help:"Configuration modification command group (edits the config file directly when Syncthing is not running)" passthrough:""`
 	Stdin      stdinCommand     `cmd:"" name:"-" help:"Read commands from stdin"`
 }
 
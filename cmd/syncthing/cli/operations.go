@@ -8,10 +8,14 @@ package cli
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/alecthomas/kong"
 	"github.com/syncthing/syncthing/lib/config"
@@ -26,12 +30,25 @@ type defaultIgnoresCommand struct {
 	Path string `arg:""`
 }
 
+type rotateEncryptionCommand struct {
+	FolderID string `arg:""`
+	DeviceID string `arg:""`
+	Password string `arg:"" optional:"" help:"New encryption password; without it, shows the progress of an ongoing rotation"`
+}
+
+type resetTOTPCommand struct {
+	Code string `arg:"" help:"Current code from the authenticator app, or a recovery code"`
+}
+
 type operationCommand struct {
-	Restart        struct{}              `cmd:"" help:"Restart syncthing"`
-	Shutdown       struct{}              `cmd:"" help:"Shutdown syncthing"`
-	Upgrade        struct{}              `cmd:"" help:"Upgrade syncthing (if a newer version is available)"`
-	FolderOverride folderOverrideCommand `cmd:"" help:"Override changes on folder (remote for sendonly, local for receiveonly). WARNING: Destructive - deletes/changes your data"`
-	DefaultIgnores defaultIgnoresCommand `cmd:"" help:"Set the default ignores (config) from a file"`
+	Restart          struct{}                `cmd:"" help:"Restart syncthing"`
+	Shutdown         struct{}                `cmd:"" help:"Shutdown syncthing"`
+	Upgrade          struct{}                `cmd:"" help:"Upgrade syncthing (if a newer version is available)"`
+	FolderOverride   folderOverrideCommand   `cmd:"" help:"Override changes on folder (remote for sendonly, local for receiveonly). WARNING: Destructive - deletes/changes your data"`
+	DefaultIgnores   defaultIgnoresCommand   `cmd:"" help:"Set the default ignores (config) from a file"`
+	RotateEncryption rotateEncryptionCommand `cmd:"" help:"Re-encrypt the data on an untrusted device with a new password"`
+	EnrollTOTP       struct{}                `cmd:"" name:"enroll-totp" help:"Require a code from an authenticator app, in addition to the password, to log in to the GUI"`
+	ResetTOTP        resetTOTPCommand        `cmd:"" name:"reset-totp" help:"Stop requiring a code from an authenticator app to log in to the GUI"`
 }
 
 func (*operationCommand) Run(ctx Context, kongCtx *kong.Context) error {
@@ -44,10 +61,104 @@ func (*operationCommand) Run(ctx Context, kongCtx *kong.Context) error {
 		return emptyPost("system/shutdown", f)
 	case "upgrade":
 		return emptyPost("system/upgrade", f)
+	case "enroll-totp":
+		return enrollTOTP(f)
 	}
 	return nil
 }
 
+func (r *resetTOTPCommand) Run(ctx Context) error {
+	client, err := ctx.clientFactory.getClient()
+	if err != nil {
+		return err
+	}
+	bs, err := json.Marshal(map[string]string{"code": r.Code})
+	if err != nil {
+		return err
+	}
+	_, err = client.Delete("system/auth/totp", string(bs))
+	return err
+}
+
+func enrollTOTP(f *apiClientFactory) error {
+	client, err := f.getClient()
+	if err != nil {
+		return err
+	}
+	response, err := client.Get("system/auth/totp")
+	if err != nil {
+		return err
+	}
+	bs, err := responseToBArray(response)
+	if err != nil {
+		return err
+	}
+	var proposal struct {
+		Enabled bool   `json:"enabled"`
+		Secret  string `json:"secret"`
+		URI     string `json:"uri"`
+	}
+	if err := json.Unmarshal(bs, &proposal); err != nil {
+		return err
+	}
+
+	fmt.Println("Add this secret to your authenticator app:")
+	fmt.Println()
+	fmt.Println("  ", proposal.Secret)
+	fmt.Println()
+	fmt.Println("Or use this URI, e.g. by converting it into a QR code:")
+	fmt.Println()
+	fmt.Println("  ", proposal.URI)
+	fmt.Println()
+
+	stdin := bufio.NewReader(os.Stdin)
+	req := map[string]string{"secret": proposal.Secret}
+	if proposal.Enabled {
+		if req["currentCode"], err = prompt(stdin, "Current code from the app for the existing secret, or a recovery code: "); err != nil {
+			return err
+		}
+	}
+	if req["code"], err = prompt(stdin, "Code from the app for the new secret: "); err != nil {
+		return err
+	}
+	bs, err = json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	response, err = client.Post("system/auth/totp", string(bs))
+	if err != nil {
+		return err
+	}
+	bs, err = responseToBArray(response)
+	if err != nil {
+		return err
+	}
+	var enrollment struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	if err := json.Unmarshal(bs, &enrollment); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("The code is now required to log in to the GUI.")
+	fmt.Println("Keep these recovery codes in a safe place. Each can be used once instead of a code from the app:")
+	fmt.Println()
+	for _, code := range enrollment.RecoveryCodes {
+		fmt.Println("  ", code)
+	}
+	return nil
+}
+
+func prompt(r *bufio.Reader, text string) (string, error) {
+	fmt.Print(text)
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
 func (f *folderOverrideCommand) Run(ctx Context) error {
 	client, err := ctx.clientFactory.getClient()
 	if err != nil {
@@ -107,3 +218,30 @@ func (d *defaultIgnoresCommand) Run(ctx Context) error {
 	_, err = client.PutJSON("config/defaults/ignores", config.Ignores{Lines: lines})
 	return err
 }
+
+func (r *rotateEncryptionCommand) Run(ctx Context) error {
+	client, err := ctx.clientFactory.getClient()
+	if err != nil {
+		return err
+	}
+	query := make(url.Values)
+	query.Set("folder", r.FolderID)
+	query.Set("device", r.DeviceID)
+	endpoint := "folder/encryption?" + query.Encode()
+
+	if r.Password != "" {
+		body, err := json.Marshal(map[string]string{"password": r.Password})
+		if err != nil {
+			return err
+		}
+		if _, err := client.Post(endpoint, string(body)); err != nil {
+			return err
+		}
+	}
+
+	response, err := client.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	return prettyPrintResponse(response)
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/pairing"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const defaultPairingRelay = "dynamic+https://relays.syncthing.net/endpoint"
+
+type pairCommand struct {
+	Generate bool          `xor:"mode" required:"" help:"Generate a code and wait for the other device to accept it"`
+	Accept   string        `xor:"mode" required:"" placeholder:"CODE" help:"Accept a code generated on the other device"`
+	Timeout  time.Duration `default:"10m" help:"How long to wait for the other device"`
+}
+
+func (p *pairCommand) Run(ctx Context) error {
+	var code string
+	if !p.Generate {
+		var err error
+		code, err = pairing.NormalizeCode(p.Accept)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := ctx.clientFactory.getClient()
+	if err != nil {
+		return err
+	}
+	cfg, err := getConfig(client)
+	if err != nil {
+		return err
+	}
+	self, err := localPeer(client, cfg)
+	if err != nil {
+		return err
+	}
+	servers := cfg.Options.GlobalDiscoveryServers()
+	if len(servers) == 0 {
+		return errors.New("pairing requires global discovery, which is disabled")
+	}
+
+	pctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	pctx, cancel = context.WithTimeout(pctx, p.Timeout)
+	defer cancel()
+
+	var peer pairing.Peer
+	if p.Generate {
+		relay, err := pairingRelay(cfg)
+		if err != nil {
+			return err
+		}
+		code = pairing.NewCode()
+		fmt.Println("Pairing code:", code)
+		fmt.Printf("Run `syncthing cli pair --accept %s` on the other device.\n", code)
+		fmt.Println("Waiting for the other device...")
+		peer, err = pairing.Offer(pctx, code, relay, servers, self)
+		if err != nil {
+			return pairingError(err)
+		}
+	} else {
+		fmt.Println("Looking for the other device...")
+		peer, err = pairing.Accept(pctx, code, servers, self)
+		if err != nil {
+			return pairingError(err)
+		}
+	}
+
+	if _, _, ok := cfg.Device(peer.DeviceID); ok {
+		fmt.Println("Already paired with", peer.DeviceID, peer.Name)
+		return nil
+	}
+	body, err := json.Marshal(map[string]any{
+		"deviceID": peer.DeviceID,
+		"name":     peer.Name,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := client.Post("config/devices", string(body)); err != nil {
+		return fmt.Errorf("adding device: %w", err)
+	}
+	fmt.Println("Paired with", peer.DeviceID, peer.Name)
+	return nil
+}
+
+// localPeer returns what we tell the other side about ourselves.
+func localPeer(client APIClient, cfg config.Configuration) (pairing.Peer, error) {
+	response, err := client.Get("system/status")
+	if err != nil {
+		return pairing.Peer{}, err
+	}
+	bs, err := responseToBArray(response)
+	if err != nil {
+		return pairing.Peer{}, err
+	}
+	var status struct {
+		MyID protocol.DeviceID `json:"myID"`
+	}
+	if err := json.Unmarshal(bs, &status); err != nil {
+		return pairing.Peer{}, err
+	}
+	self := pairing.Peer{DeviceID: status.MyID}
+	if dev, _, ok := cfg.Device(status.MyID); ok {
+		self.Name = dev.Name
+	}
+	return self, nil
+}
+
+// pairingRelay returns the relay (or relay pool) configured for listening,
+// or the default pool when relaying isn't among the listen addresses.
+func pairingRelay(cfg config.Configuration) (*url.URL, error) {
+	addr := defaultPairingRelay
+	for _, la := range cfg.Options.ListenAddresses() {
+		if strings.HasPrefix(la, "relay://") || strings.HasPrefix(la, "dynamic+") {
+			addr = la
+			break
+		}
+	}
+	return url.Parse(addr)
+}
+
+func pairingError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errors.New("timed out waiting for the other device")
+	}
+	return err
+}
@@ -10,7 +10,6 @@ package generate
 import (
 	"bufio"
 	"context"
-	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"os"
@@ -18,6 +17,7 @@ import (
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/events"
 	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/keystore"
 	"github.com/syncthing/syncthing/lib/locations"
 	"github.com/syncthing/syncthing/lib/protocol"
 	"github.com/syncthing/syncthing/lib/syncthing"
@@ -27,6 +27,7 @@ type CLI struct {
 	GUIUser       string `placeholder:"STRING" help:"Specify new GUI authentication user name"`
 	GUIPassword   string `placeholder:"STRING" help:"Specify new GUI authentication password (use - to read from standard input)"`
 	NoPortProbing bool   `help:"Don't try to find free ports for GUI and listen addresses on first startup" env:"STNOPORTPROBING"`
+	KeyStore      string `placeholder:"NAME" help:"Keep the device private key in the given key store (${keyStores})" env:"STKEYSTORE"`
 }
 
 func (c *CLI) Run() error {
@@ -43,6 +44,11 @@ func (c *CLI) Run() error {
 	if err := Generate(locations.GetBaseDir(locations.ConfigBaseDir), c.GUIUser, c.GUIPassword, c.NoPortProbing); err != nil {
 		return fmt.Errorf("failed to generate config and keys: %w", err)
 	}
+	if c.KeyStore != "" {
+		if err := keystore.Migrate(locations.Get(locations.KeyFile), c.KeyStore); err != nil {
+			return fmt.Errorf("failed to move key to key store: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -59,9 +65,11 @@ func Generate(confDir, guiUser, guiPassword string, skipPortProbing bool) error
 
 	var myID protocol.DeviceID
 	certFile, keyFile := locations.Get(locations.CertFile), locations.Get(locations.KeyFile)
-	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	cert, err := keystore.LoadX509KeyPair(certFile, keyFile)
 	if err == nil {
 		slog.Warn("Key exists; will not overwrite")
+	} else if keystore.IsReference(keyFile) {
+		return fmt.Errorf("load key: %w", err)
 	} else {
 		cert, err = syncthing.GenerateCertificate(certFile, keyFile)
 		if err != nil {
@@ -27,6 +27,7 @@ import (
 	"runtime/pprof"
 	"slices"
 	"strconv"
+	"strings"
 	"syscall"
 	"text/tabwriter"
 	"time"
@@ -36,21 +37,26 @@ import (
 	"github.com/thejerf/suture/v4"
 	"github.com/willabides/kongplete"
 
+	"github.com/syncthing/syncthing/cmd/syncthing/benchmark"
 	"github.com/syncthing/syncthing/cmd/syncthing/cli"
 	"github.com/syncthing/syncthing/cmd/syncthing/decrypt"
 	"github.com/syncthing/syncthing/cmd/syncthing/generate"
+	"github.com/syncthing/syncthing/cmd/syncthing/migrate"
 	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/internal/db/sqlite"
 	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/internal/tracing"
 	_ "github.com/syncthing/syncthing/lib/automaxprocs"
 	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/dialer"
 	"github.com/syncthing/syncthing/lib/events"
 	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/keystore"
 	"github.com/syncthing/syncthing/lib/locations"
 	"github.com/syncthing/syncthing/lib/osutil"
 	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/sdnotify"
 	"github.com/syncthing/syncthing/lib/svcutil"
 	"github.com/syncthing/syncthing/lib/syncthing"
 	"github.com/syncthing/syncthing/lib/upgrade"
@@ -80,6 +86,8 @@ above.
                    level for. The valid package strings are listed below. A log
                    level (DEBUG, INFO, WARN or ERROR) can be added after each
                    package, separated by a colon. Ex: "model:WARN,nat:DEBUG".
+                   Levels can also be changed at runtime, optionally for a
+                   limited time, using the /rest/system/debug endpoint.
 
  STVERSIONEXTRA    Add extra information to the version string in logs and the
                    version line in the GUI. Can be set to the name of a wrapper
@@ -130,14 +138,16 @@ type CLI struct {
 	Serve serveCmd `cmd:"" help:"Run Syncthing (default)" default:"withargs"`
 	CLI   cli.CLI  `cmd:"" help:"Command line interface for Syncthing"`
 
-	Browser  browserCmd   `cmd:"" help:"Open GUI in browser, then exit"`
-	Decrypt  decrypt.CLI  `cmd:"" help:"Decrypt or verify an encrypted folder"`
-	DeviceID deviceIDCmd  `cmd:"" help:"Show device ID, then exit"`
-	Generate generate.CLI `cmd:"" help:"Generate key and config, then exit"`
-	Paths    pathsCmd     `cmd:"" help:"Show configuration paths, then exit"`
-	Upgrade  upgradeCmd   `cmd:"" help:"Perform or check for upgrade, then exit"`
-	Version  versionCmd   `cmd:"" help:"Show current version, then exit"`
-	Debug    debugCmd     `cmd:"" help:"Various debugging commands"`
+	Benchmark benchmark.CLI `cmd:"" help:"Measure hashing, database and disk performance, then exit"`
+	Browser   browserCmd    `cmd:"" help:"Open GUI in browser, then exit"`
+	Decrypt   decrypt.CLI   `cmd:"" help:"Decrypt or verify an encrypted folder"`
+	DeviceID  deviceIDCmd   `cmd:"" help:"Show device ID, then exit"`
+	Generate  generate.CLI  `cmd:"" help:"Generate key and config, then exit"`
+	Migrate   migrate.CLI   `cmd:"" help:"Move the device to another machine"`
+	Paths     pathsCmd      `cmd:"" help:"Show configuration paths, then exit"`
+	Upgrade   upgradeCmd    `cmd:"" help:"Perform or check for upgrade, then exit"`
+	Version   versionCmd    `cmd:"" help:"Show current version, then exit"`
+	Debug     debugCmd      `cmd:"" help:"Various debugging commands"`
 
 	InstallCompletions kongplete.InstallCompletions `cmd:"" help:"Print commands to install shell completions"`
 }
@@ -152,13 +162,17 @@ func (c *CLI) AfterApply() error {
 type serveCmd struct {
 	buildSpecificOptions
 
+	ActiveStandby             bool          `name:"active-standby" help:"Share the home directory with another instance on shared storage, one being active while the other waits on standby to take over" env:"STACTIVESTANDBY"`
 	AllowNewerConfig          bool          `help:"Allow loading newer than current config version" env:"STALLOWNEWERCONFIG"`
 	Audit                     bool          `help:"Write events to audit file" env:"STAUDIT"`
 	AuditFile                 string        `name:"auditfile" help:"Specify audit file (use \"-\" for stdout, \"--\" for stderr)" placeholder:"PATH" env:"STAUDITFILE"`
 	DBMaintenanceInterval     time.Duration `help:"Database maintenance interval; set to zero to disable periodic maintenance" default:"8h" env:"STDBMAINTENANCEINTERVAL"`
 	DBDeleteRetentionInterval time.Duration `help:"Database deleted item retention interval" default:"10920h" env:"STDBDELETERETENTIONINTERVAL"`
+	DesiredConfig             string        `name:"desired-config" help:"Reconcile the config with the desired state in the given JSON file at startup and on SIGHUP (instead of restarting)" placeholder:"PATH" env:"STDESIREDCONFIG"`
 	GUIAddress                string        `name:"gui-address" help:"Override GUI address (e.g. \"http://192.0.2.42:8443\")" placeholder:"URL" env:"STGUIADDRESS"`
 	GUIAPIKey                 string        `name:"gui-apikey" help:"Override GUI API key" placeholder:"API-KEY" env:"STGUIAPIKEY"`
+	GUIReadOnly               bool          `name:"gui-read-only" help:"Refuse all GUI and API requests that would change anything, e.g. when exposed for monitoring only" env:"STGUIREADONLY"`
+	KeyStore                  string        `name:"key-store" help:"Keep the device private key in the given key store (${keyStores}), moving it there if necessary" placeholder:"NAME" env:"STKEYSTORE"`
 	LogFile                   string        `name:"log-file" aliases:"logfile" help:"Log file name (see below)" default:"${logFile}" placeholder:"PATH" env:"STLOGFILE"`
 	LogFlags                  int           `name:"logflags" help:"Deprecated option that does nothing, kept for compatibility" hidden:""`
 	LogLevel                  slog.Level    `help:"Log level for all packages (DEBUG,INFO,WARN,ERROR)" env:"STLOGLEVEL" default:"INFO"`
@@ -167,10 +181,12 @@ type serveCmd struct {
 	LogFormatTimestamp        string        `name:"log-format-timestamp" help:"Format for timestamp, set to empty to disable timestamps" env:"STLOGFORMATTIMESTAMP" default:"${timestampFormat}"`
 	LogFormatLevelString      bool          `name:"log-format-level-string" help:"Whether to include level string in log line" env:"STLOGFORMATLEVELSTRING" default:"${levelString}" negatable:""`
 	LogFormatLevelSyslog      bool          `name:"log-format-level-syslog" help:"Whether to include level as syslog prefix in log line" env:"STLOGFORMATLEVELSYSLOG" default:"${levelSyslog}" negatable:""`
+	LogFormatJSON             bool          `name:"log-format-json" help:"Write log lines as JSON objects, ignoring the other format options" env:"STLOGFORMATJSON"`
 	NoBrowser                 bool          `help:"Do not start browser" env:"STNOBROWSER"`
 	NoPortProbing             bool          `help:"Don't try to find free ports for GUI and listen addresses on first startup" env:"STNOPORTPROBING"`
 	NoRestart                 bool          `help:"Do not restart Syncthing when exiting due to API/GUI command, upgrade, or crash" env:"STNORESTART"`
 	NoUpgrade                 bool          `help:"Disable automatic upgrades" env:"STNOUPGRADE"`
+	TraceOTLPEndpoint         string        `name:"trace-otlp-endpoint" help:"Export traces over OTLP/HTTP to the given collector (e.g. \"http://localhost:4318\")" placeholder:"URL" env:"STTRACEOTLPENDPOINT"`
 	Paused                    bool          `help:"Start with all devices and folders paused" env:"STPAUSED"`
 	Unpaused                  bool          `help:"Start with all devices and folders unpaused" env:"STUNPAUSED"`
 
@@ -195,6 +211,7 @@ func defaultVars() kong.Vars {
 		"levelString":     strconv.FormatBool(slogutil.DefaultLineFormat.LevelString),
 		"levelSyslog":     strconv.FormatBool(slogutil.DefaultLineFormat.LevelSyslog),
 		"timestampFormat": slogutil.DefaultLineFormat.TimestampFormat,
+		"keyStores":       strings.Join(keystore.Backends(), ", "),
 	}
 
 	// On non-Windows, we explicitly default to "-" which means stdout. On
@@ -263,6 +280,10 @@ func (c *serveCmd) Run() error {
 		// The config picks this up from the environment.
 		os.Setenv("STGUIAPIKEY", c.GUIAPIKey)
 	}
+	if c.GUIReadOnly {
+		// The config picks this up from the environment.
+		os.Setenv("STGUIREADONLY", "true")
+	}
 
 	if c.HideConsole {
 		osutil.HideConsole()
@@ -273,6 +294,7 @@ func (c *serveCmd) Run() error {
 		TimestampFormat: c.LogFormatTimestamp,
 		LevelString:     c.LogFormatLevelString,
 		LevelSyslog:     c.LogFormatLevelSyslog,
+		JSON:            c.LogFormatJSON,
 	})
 	slogutil.SetDefaultLevel(c.LogLevel)
 	slogutil.SetLevelOverrides(os.Getenv("STTRACE"))
@@ -433,6 +455,16 @@ func (c *serveCmd) syncthingMain() {
 	// early etc. will have it available.
 	slog.Info(build.LongVersion) //nolint:sloglint
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// When sharing the home directory with a standby, wait until we're the
+	// active instance before touching anything in it.
+	releaseLease := func() {}
+	if c.ActiveStandby {
+		releaseLease = activeLease(ctx)
+	}
+
 	// Ensure that we have a certificate and key.
 	cert, err := syncthing.LoadOrGenerateCertificate(
 		locations.Get(locations.CertFile),
@@ -442,20 +474,37 @@ func (c *serveCmd) syncthingMain() {
 		slog.Error("Failed to load/generate certificate", slogutil.Error(err))
 		os.Exit(1)
 	}
+	if c.KeyStore != "" {
+		if err := keystore.Migrate(locations.Get(locations.KeyFile), c.KeyStore); err != nil {
+			slog.Error("Failed to move device key to key store", slog.String("keystore", c.KeyStore), slogutil.Error(err))
+			os.Exit(1)
+		}
+	}
 
-	// Ensure we are the only running instance
-	lf := flock.New(locations.Get(locations.LockFile))
-	locked, err := lf.TryLock()
-	if err != nil {
-		slog.Error("Failed to acquire lock", slogutil.Error(err))
-		os.Exit(1)
-	} else if !locked {
-		slog.Error("Failed to acquire lock: is another Syncthing instance already running?")
-		os.Exit(1)
+	// Ensure we are the only running instance. When sharing the home
+	// directory with a standby we already hold the lock as the lease.
+	var lf *flock.Flock
+	if !c.ActiveStandby {
+		lf = flock.New(locations.Get(locations.LockFile))
+		locked, err := lf.TryLock()
+		if err != nil {
+			slog.Error("Failed to acquire lock", slogutil.Error(err))
+			os.Exit(1)
+		} else if !locked {
+			slog.Error("Failed to acquire lock: is another Syncthing instance already running?")
+			os.Exit(1)
+		}
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	stopTracing := func(context.Context) error { return nil }
+	if c.TraceOTLPEndpoint != "" {
+		stopTracing, err = tracing.Setup(ctx, c.TraceOTLPEndpoint)
+		if err != nil {
+			slog.Error("Failed to set up tracing", slogutil.Error(err))
+			os.Exit(svcutil.ExitError.AsInt())
+		}
+		slog.Info("Exporting traces", slog.String("endpoint", c.TraceOTLPEndpoint))
+	}
 
 	// earlyService is a supervisor that runs the services needed for or
 	// before app startup; the event logger, and the config service.
@@ -474,6 +523,18 @@ func (c *serveCmd) syncthingMain() {
 	earlyService.Add(cfgWrapper)
 	config.RegisterInfoMetrics(cfgWrapper)
 
+	if err := config.MergeDropIns(cfgWrapper, locations.Get(locations.ConfigDropIns)); err != nil {
+		slog.Error("Failed to initialize config", slogutil.Error(err))
+		os.Exit(svcutil.ExitError.AsInt())
+	}
+
+	if c.DesiredConfig != "" {
+		if err := config.ApplyDesiredStateFile(cfgWrapper, c.DesiredConfig); err != nil {
+			slog.Error("Failed to reconcile config", slogutil.Error(err))
+			os.Exit(svcutil.ExitError.AsInt())
+		}
+	}
+
 	// Candidate builds should auto upgrade. Make sure the option is set,
 	// unless we are in a build where it's disabled or the STNOUPGRADE
 	// environment variable is set.
@@ -503,6 +564,9 @@ func (c *serveCmd) syncthingMain() {
 		}
 		go api.Serve(migratingAPICtx)
 	}
+	if sdnotify.Enabled() {
+		go notifyMigrating(migratingAPICtx)
+	}
 
 	if err := syncthing.TryMigrateDatabase(ctx, c.DBDeleteRetentionInterval); err != nil {
 		slog.Error("Failed to migrate old-style database", slogutil.Error(err))
@@ -578,7 +642,7 @@ func (c *serveCmd) syncthingMain() {
 		go autoUpgrade(cfgWrapper, app, evLogger)
 	}
 
-	setupSignalHandling(app)
+	setupSignalHandling(app, cfgWrapper, c.DesiredConfig)
 
 	if c.DebugProfileCPU {
 		f, err := os.Create(fmt.Sprintf("cpu-%d.pprof", os.Getpid()))
@@ -614,21 +678,39 @@ func (c *serveCmd) syncthingMain() {
 		pprof.StopCPUProfile()
 	}
 
+	// Flush any spans not yet sent to the collector
+	traceCtx, traceCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	_ = stopTracing(traceCtx)
+	traceCancel()
+
 	// Best effort remove lockfile, doesn't matter if it succeeds
-	_ = lf.Unlock()
-	_ = os.Remove(locations.Get(locations.LockFile))
+	if lf != nil {
+		_ = lf.Unlock()
+		_ = os.Remove(locations.Get(locations.LockFile))
+	}
+	// Only hand over to the standby once we've stopped writing.
+	releaseLease()
 
 	os.Exit(int(status))
 }
 
-func setupSignalHandling(app *syncthing.App) {
-	// Exit cleanly with "restarting" code on SIGHUP.
+func setupSignalHandling(app *syncthing.App, cfg config.Wrapper, desiredConfig string) {
+	// Exit cleanly with "restarting" code on SIGHUP, or reconcile the
+	// config when running with a desired state file.
 
 	restartSign := make(chan os.Signal, 1)
 	signal.Notify(restartSign, syscall.SIGHUP)
 	go func() {
-		<-restartSign
-		app.Stop(svcutil.ExitRestart)
+		if desiredConfig == "" {
+			<-restartSign
+			app.Stop(svcutil.ExitRestart)
+			return
+		}
+		for range restartSign {
+			if err := config.ApplyDesiredStateFile(cfg, desiredConfig); err != nil {
+				slog.Error("Failed to reconcile config", slogutil.Error(err))
+			}
+		}
 	}()
 
 	// Exit with "success" code (no restart) on INT/TERM
@@ -786,7 +868,7 @@ func cleanConfigDirectory() {
 		"panic-*.log":               7 * 24 * time.Hour,  // keep panic logs for a week
 		"audit-*.log":               7 * 24 * time.Hour,  // keep audit logs for a week
 		"index-v0.14.0.db-migrated": 14 * 24 * time.Hour, // keep old index format for two weeks
-		"config.xml.v*":             30 * 24 * time.Hour, // old config versions for a month
+		"config.*.v*":               30 * 24 * time.Hour, // old config versions for a month
 		"support-bundle-*":          30 * 24 * time.Hour, // keep old support bundle zip or folder for a month
 	}
 
@@ -855,7 +937,7 @@ func (versionCmd) Run() error {
 type deviceIDCmd struct{}
 
 func (deviceIDCmd) Run() error {
-	cert, err := tls.LoadX509KeyPair(
+	cert, err := keystore.LoadX509KeyPair(
 		locations.Get(locations.CertFile),
 		locations.Get(locations.KeyFile),
 	)
@@ -1041,6 +1123,25 @@ func setConfigDataLocationsFromFlags(homeDir, confDir, dataDir string) error {
 	return nil
 }
 
+// notifyMigrating keeps systemd waiting for us to start up, rather than
+// timing out or restarting us on the watchdog, while the database is
+// migrated, which may take a long time.
+func notifyMigrating(ctx context.Context) {
+	const interval = 10 * time.Second
+	for {
+		states := []string{sdnotify.Status("Migrating database"), sdnotify.ExtendTimeout(3 * interval)}
+		if sdnotify.WatchdogInterval() > 0 {
+			states = append(states, sdnotify.Watchdog)
+		}
+		_ = sdnotify.Notify(states...)
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 type migratingAPI struct {
 	addr       string
 	startDelay time.Duration
@@ -0,0 +1,253 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package migrate implements the `syncthing migrate` subcommand.
+package migrate
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofrs/flock"
+
+	"github.com/syncthing/syncthing/lib/keystore"
+	"github.com/syncthing/syncthing/lib/locations"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Moving a device to new hardware means taking along its identity (the
+// certificate and key), its config and its database, so that it carries on
+// as the same device with the same index instead of as a new one. The
+// archive holds each of these under the name of its location, so that it
+// can be unpacked into a home directory laid out differently. Importing
+// leaves a marker for the first start to announce the move to the other
+// devices.
+
+type CLI struct {
+	Export exportCmd `cmd:"" help:"Write the device identity, config and database to an archive, to be imported on the new machine"`
+	Import importCmd `cmd:"" help:"Set up the device from an archive written by export on the old machine"`
+}
+
+type exportCmd struct {
+	To string `arg:"" placeholder:"PATH" help:"Archive to write"`
+}
+
+type importCmd struct {
+	From  string `arg:"" placeholder:"PATH" help:"Archive to read"`
+	Force bool   `help:"Replace the device identity, config and database already there"`
+}
+
+var migratedLocations = []struct {
+	loc      locations.LocationEnum
+	required bool
+}{
+	{locations.CertFile, true},
+	{locations.KeyFile, true},
+	{locations.ConfigFile, true},
+	{locations.ConfigDropIns, false},
+	{locations.HTTPSCertFile, false},
+	{locations.HTTPSKeyFile, false},
+	{locations.Database, false},
+}
+
+func (c *exportCmd) Run() error {
+	unlock, err := lockHome()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if keystore.IsReference(locations.Get(locations.KeyFile)) {
+		return errors.New("the device key is kept in a key store, from which it can't be exported")
+	}
+
+	fd, err := os.OpenFile(c.To, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	gw := gzip.NewWriter(fd)
+	tw := tar.NewWriter(gw)
+	for _, ml := range migratedLocations {
+		if err := addLocation(tw, ml.loc, ml.required); err != nil {
+			fd.Close()
+			os.Remove(c.To)
+			return fmt.Errorf("%s: %w", ml.loc, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+
+	fmt.Println("Exported to", c.To)
+	fmt.Println("Don't start Syncthing here again once the archive is imported on the new machine.")
+	return nil
+}
+
+// addLocation writes the file or directory tree at the location to the
+// archive.
+func addLocation(tw *tar.Writer, loc locations.LocationEnum, required bool) error {
+	root := locations.Get(loc)
+	if _, err := os.Lstat(root); errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	}
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(string(loc), filepath.ToSlash(rel))
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+}
+
+func (c *importCmd) Run() error {
+	unlock, err := lockHome()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := os.Stat(locations.Get(locations.CertFile)); err == nil {
+		if !c.Force {
+			return errors.New("there is a device identity here already; use --force to replace it")
+		}
+		// The database must not be mixed with the one already here.
+		if err := os.RemoveAll(locations.Get(locations.Database)); err != nil {
+			return err
+		}
+	}
+
+	fd, err := os.Open(c.From)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	gr, err := gzip.NewReader(fd)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		if err := extract(tr, hdr); err != nil {
+			return fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+	}
+
+	cert, err := keystore.LoadX509KeyPair(locations.Get(locations.CertFile), locations.Get(locations.KeyFile))
+	if err != nil {
+		return fmt.Errorf("load imported key: %w", err)
+	}
+	if err := os.WriteFile(locations.Get(locations.MovedMarker), nil, 0o600); err != nil {
+		return err
+	}
+
+	fmt.Println("Imported device", protocol.NewDeviceID(cert.Certificate[0]))
+	fmt.Println("The other devices are told of the move once Syncthing is started.")
+	return nil
+}
+
+// extract writes an archive entry to where its location is in this home
+// directory.
+func extract(tr *tar.Reader, hdr *tar.Header) error {
+	name, rel, _ := strings.Cut(hdr.Name, "/")
+	known := false
+	for _, ml := range migratedLocations {
+		known = known || string(ml.loc) == name
+	}
+	if !known {
+		return errors.New("not a migrated location")
+	}
+	if rel != "" && !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return errors.New("invalid path")
+	}
+	dst := filepath.Join(locations.Get(locations.LocationEnum(name)), filepath.FromSlash(rel))
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(dst, 0o700)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, tr); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	default:
+		return errors.New("not a file or directory")
+	}
+}
+
+// lockHome makes sure Syncthing isn't running while we read or write the
+// home directory.
+func lockHome() (func(), error) {
+	lockFile := locations.Get(locations.LockFile)
+	if err := os.MkdirAll(filepath.Dir(lockFile), 0o700); err != nil {
+		return nil, err
+	}
+	lf := flock.New(lockFile)
+	locked, err := lf.TryLock()
+	if err != nil {
+		return nil, fmt.Errorf("lock home directory: %w", err)
+	}
+	if !locked {
+		return nil, errors.New("syncthing is running; stop it first")
+	}
+	return func() { _ = lf.Unlock() }, nil
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package migrate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofrs/flock"
+
+	"github.com/syncthing/syncthing/lib/keystore"
+	"github.com/syncthing/syncthing/lib/locations"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/tlsutil"
+)
+
+// setHome points the config and data locations at dir for the duration of
+// the test.
+func setHome(t *testing.T, dir string) {
+	t.Helper()
+	for _, bd := range []locations.BaseDirEnum{locations.ConfigBaseDir, locations.DataBaseDir} {
+		prev := locations.GetBaseDir(bd)
+		if err := locations.SetBaseDir(bd, dir); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = locations.SetBaseDir(bd, prev) })
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	oldHome, newHome := t.TempDir(), t.TempDir()
+	archive := filepath.Join(t.TempDir(), "migrate.tar.gz")
+
+	setHome(t, oldHome)
+	cert, err := tlsutil.NewCertificate(locations.Get(locations.CertFile), locations.Get(locations.KeyFile), "syncthing", 365, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := protocol.NewDeviceID(cert.Certificate[0])
+	writeFile(t, locations.Get(locations.ConfigFile), "config")
+	writeFile(t, filepath.Join(locations.Get(locations.Database), "index.db"), "index")
+	if err := (&exportCmd{To: archive}).Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&exportCmd{To: archive}).Run(); err == nil {
+		t.Error("expected export not to overwrite an existing archive")
+	}
+
+	setHome(t, newHome)
+	if err := (&importCmd{From: archive}).Run(); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := keystore.LoadX509KeyPair(locations.Get(locations.CertFile), locations.Get(locations.KeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := protocol.NewDeviceID(imported.Certificate[0]); got != id {
+		t.Errorf("expected device %v to be imported, got %v", id, got)
+	}
+	for loc, data := range map[string]string{
+		locations.Get(locations.ConfigFile):                          "config",
+		filepath.Join(locations.Get(locations.Database), "index.db"): "index",
+	} {
+		if bs, err := os.ReadFile(loc); err != nil || string(bs) != data {
+			t.Errorf("expected %s to be imported as %q, got %q, %v", loc, data, bs, err)
+		}
+	}
+	if _, err := os.Stat(locations.Get(locations.MovedMarker)); err != nil {
+		t.Error("expected the moved marker to be left for the first start:", err)
+	}
+
+	// A device already set up here is only replaced when asked to, and
+	// then without mixing in its database.
+	writeFile(t, filepath.Join(locations.Get(locations.Database), "other.db"), "other")
+	if err := (&importCmd{From: archive}).Run(); err == nil {
+		t.Fatal("expected import to refuse replacing the device identity")
+	}
+	if err := (&importCmd{From: archive, Force: true}).Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(locations.Get(locations.Database), "other.db")); err == nil {
+		t.Error("expected the replaced database to be removed")
+	}
+}
+
+func TestExportRunning(t *testing.T) {
+	setHome(t, t.TempDir())
+	if _, err := tlsutil.NewCertificate(locations.Get(locations.CertFile), locations.Get(locations.KeyFile), "syncthing", 365, false); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, locations.Get(locations.ConfigFile), "config")
+
+	lf := flock.New(locations.Get(locations.LockFile))
+	if ok, err := lf.TryLock(); err != nil || !ok {
+		t.Fatal("lock home:", ok, err)
+	}
+	defer lf.Unlock()
+	if err := (&exportCmd{To: filepath.Join(t.TempDir(), "migrate.tar.gz")}).Run(); err == nil {
+		t.Error("expected export to refuse while Syncthing is running")
+	}
+}
+
+func TestImportInvalidPath(t *testing.T) {
+	setHome(t, t.TempDir())
+
+	for _, name := range []string{"config/../../escaped", "unknown/file"} {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o600}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Close()
+		gw.Close()
+		archive := filepath.Join(t.TempDir(), "migrate.tar.gz")
+		writeFile(t, archive, buf.String())
+
+		if err := (&importCmd{From: archive}).Run(); err == nil {
+			t.Errorf("expected import to refuse %q", name)
+		}
+	}
+}
+
+func writeFile(t *testing.T, name, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
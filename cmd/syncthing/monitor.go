@@ -157,19 +157,29 @@ func (c *serveCmd) monitorMain() {
 		}()
 
 		stopped := false
-		select {
-		case s := <-stopSign:
-			slog.Info("Received signal; exiting", "signal", s)
-			cmd.Process.Signal(sigTerm)
-			err = <-exit
-			stopped = true
-
-		case s := <-restartSign:
-			slog.Info("Received signal; restarting", "signal", s)
-			cmd.Process.Signal(sigHup)
-			err = <-exit
-
-		case err = <-exit:
+	wait:
+		for {
+			select {
+			case s := <-stopSign:
+				slog.Info("Received signal; exiting", "signal", s)
+				cmd.Process.Signal(sigTerm)
+				err = <-exit
+				stopped = true
+
+			case s := <-restartSign:
+				if c.DesiredConfig != "" {
+					// The child reconciles its config instead of restarting
+					slog.Info("Received signal; reconciling config", "signal", s)
+					cmd.Process.Signal(sigHup)
+					continue
+				}
+				slog.Info("Received signal; restarting", "signal", s)
+				cmd.Process.Signal(sigHup)
+				err = <-exit
+
+			case err = <-exit:
+			}
+			break wait
 		}
 
 		if err == nil {
@@ -112,11 +112,17 @@ angular.module('syncthing.core')
             $http.post(authUrlbase + '/password', {
               username: $scope.login.username,
               password: $scope.login.password,
+              totpCode: $scope.login.totpCode,
               stayLoggedIn: $scope.login.stayLoggedIn,
             }).then(function () {
                 location.reload();
             }).catch(function (response) {
-                if (response.status === 403) {
+                if (response.status === 401) {
+                    // Correct password, a code from the authenticator app is required.
+                    $scope.login.totpRequired = true;
+                } else if (response.status === 403 && $scope.login.totpRequired) {
+                    $scope.login.errors.badCode = true;
+                } else if (response.status === 403) {
                     $scope.login.errors.badLogin = true;
                 } else {
                     $scope.login.errors.failed = true;
@@ -2484,6 +2490,33 @@ angular.module('syncthing.core')
             }, $scope.emitHTTPError);
         }
 
+        function currentFolderTemplate() {
+            var templates = $scope.config.defaults.folderTemplates || [];
+            for (var i = 0; i < templates.length; i++) {
+                if (templates[i].name === $scope.currentFolder._template) {
+                    return templates[i];
+                }
+            }
+            return null;
+        }
+
+        $scope.applyFolderTemplate = function () {
+            var tmpl = currentFolderTemplate();
+            var base = tmpl ? tmpl.folder : $scope.config.defaults.folder;
+            // Keep what the user has entered so far
+            var kept = {
+                id: $scope.currentFolder.id,
+                label: $scope.currentFolder.label,
+                path: $scope.currentFolder.path,
+                devices: $scope.currentFolder.devices,
+                _editing: $scope.currentFolder._editing,
+                _template: $scope.currentFolder._template,
+            };
+            $scope.currentFolder = angular.extend(angular.copy(base), kept);
+            $scope.currentFolder._addIgnores = !!(tmpl && tmpl.ignores.lines.length > 0);
+            initVersioningEditing();
+        };
+
         $scope.shareFolderWithDevice = function (folder, device) {
             var folderCfg = $scope.folders[folder];
             if (folderCfg.type == "receiveencrypted" || !$scope.pendingIsRemoteEncrypted(folder, device)) {
@@ -2600,10 +2633,11 @@ angular.module('syncthing.core')
                 if (!data) {
                     return;
                 }
+                var tmpl = currentFolderTemplate();
                 if ((data.ignore && data.ignore.length > 0) || data.error) {
                     editFolderInitIgnores(data.ignore, data.error);
                 } else {
-                    getDefaultIgnores().then(function (lines) {
+                    (tmpl ? $q.when(tmpl.ignores.lines) : getDefaultIgnores()).then(function (lines) {
                         setIgnoresText(lines);
                         $scope.ignores.defaultLines = lines;
                         $scope.ignores.disabled = false;
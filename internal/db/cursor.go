@@ -0,0 +1,46 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package db
+
+import (
+	"iter"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const neededCursorBatch = 1000
+
+// AllNeededGlobalFilesCursor returns the files needed by the device in
+// alphabetical order, like AllNeededGlobalFiles. They are read in batches,
+// continuing after the last name seen, so that nothing is held open in the
+// database while the caller handles them, however slow it is.
+func AllNeededGlobalFilesCursor(sdb DB, folder string, device protocol.DeviceID) (iter.Seq[protocol.FileInfo], func() error) {
+	var retErr error
+	return func(yield func(protocol.FileInfo) bool) {
+		var after string
+		for {
+			batch := make([]protocol.FileInfo, 0, neededCursorBatch)
+			it, errFn := sdb.AllNeededGlobalFilesAfter(folder, device, after, neededCursorBatch)
+			for f := range it {
+				batch = append(batch, f)
+			}
+			if err := errFn(); err != nil {
+				retErr = err
+				return
+			}
+			for _, f := range batch {
+				if !yield(f) {
+					return
+				}
+			}
+			if len(batch) < neededCursorBatch {
+				return
+			}
+			after = batch[len(batch)-1].Name
+		}
+	}, func() error { return retErr }
+}
@@ -0,0 +1,54 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package db_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/db/sqlite"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestAllNeededGlobalFilesCursor(t *testing.T) {
+	t.Parallel()
+
+	ldb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ldb.Close()
+	})
+
+	// More files than fit in a batch, all needed locally.
+	var v protocol.Vector
+	v = v.Update(42)
+	files := make([]protocol.FileInfo, 2500)
+	for i := range files {
+		files[i] = protocol.FileInfo{Name: fmt.Sprintf("file%04d", i), Type: protocol.FileInfoTypeDirectory, Version: v, Sequence: int64(i + 1)}
+	}
+	if err := ldb.Update("folder", protocol.DeviceID{42}, files); err != nil {
+		t.Fatal(err)
+	}
+
+	it, errFn := db.AllNeededGlobalFilesCursor(ldb, "folder", protocol.LocalDeviceID)
+	i := 0
+	for f := range it {
+		if f.Name != files[i].Name {
+			t.Fatalf("got %q at %d, expected %q", f.Name, i, files[i].Name)
+		}
+		i++
+	}
+	if err := errFn(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(files) {
+		t.Errorf("got %d files, expected %d", i, len(files))
+	}
+}
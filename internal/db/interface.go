@@ -30,6 +30,9 @@ type DB interface {
 	// Basics
 	Update(folder string, device protocol.DeviceID, fs []protocol.FileInfo) error
 	Close() error
+	// Writes everything from the write-ahead log to the database files,
+	// e.g. before the process is suspended.
+	Checkpoint() error
 
 	// Single files
 	GetDeviceFile(folder string, device protocol.DeviceID, file string) (protocol.FileInfo, bool, error)
@@ -45,11 +48,15 @@ type DB interface {
 	// required.
 	AllGlobalFiles(folder string) (iter.Seq[FileMetadata], func() error)
 	AllGlobalFilesPrefix(folder string, prefix string) (iter.Seq[FileMetadata], func() error)
+	AllGlobalFilesNamed(folder string, names []string) (iter.Seq[protocol.FileInfo], func() error)
 	AllLocalFiles(folder string, device protocol.DeviceID) (iter.Seq[protocol.FileInfo], func() error)
 	AllLocalFilesBySequence(folder string, device protocol.DeviceID, startSeq int64, limit int) (iter.Seq[protocol.FileInfo], func() error)
 	AllLocalFilesWithPrefix(folder string, device protocol.DeviceID, prefix string) (iter.Seq[protocol.FileInfo], func() error)
 	AllLocalFilesWithBlocksHash(folder string, h []byte) (iter.Seq[FileMetadata], func() error)
+	AllLocalFilesNamed(folder string, device protocol.DeviceID, names []string) (iter.Seq[FileMetadata], func() error)
 	AllNeededGlobalFiles(folder string, device protocol.DeviceID, order config.PullOrder, limit, offset int) (iter.Seq[protocol.FileInfo], func() error)
+	// Needed files in alphabetical order, with names after the given one.
+	AllNeededGlobalFilesAfter(folder string, device protocol.DeviceID, after string, limit int) (iter.Seq[protocol.FileInfo], func() error)
 	AllLocalBlocksWithHash(folder string, hash []byte) (iter.Seq[BlockMapEntry], func() error)
 
 	// Cleanup
@@ -89,6 +96,9 @@ type KV interface {
 	PutKV(key string, val []byte) error
 	DeleteKV(key string) error
 	PrefixKV(prefix string) (iter.Seq[KeyValue], func() error)
+	// ReplacePrefixKV replaces all keys with the prefix by the given
+	// ones, in one go.
+	ReplacePrefixKV(prefix string, kvs []KeyValue) error
 }
 
 type BlockMapEntry struct {
@@ -63,6 +63,11 @@ func (m metricsDB) account(folder, op string) func() {
 	}
 }
 
+func (m metricsDB) AllLocalFilesNamed(folder string, device protocol.DeviceID, names []string) (iter.Seq[FileMetadata], func() error) {
+	defer m.account(folder, "AllLocalFilesNamed")()
+	return m.DB.AllLocalFilesNamed(folder, device, names)
+}
+
 func (m metricsDB) AllLocalFilesWithBlocksHash(folder string, h []byte) (iter.Seq[FileMetadata], func() error) {
 	defer m.account(folder, "AllLocalFilesWithBlocksHash")()
 	return m.DB.AllLocalFilesWithBlocksHash(folder, h)
@@ -78,6 +83,11 @@ func (m metricsDB) AllGlobalFilesPrefix(folder string, prefix string) (iter.Seq[
 	return m.DB.AllGlobalFilesPrefix(folder, prefix)
 }
 
+func (m metricsDB) AllGlobalFilesNamed(folder string, names []string) (iter.Seq[protocol.FileInfo], func() error) {
+	defer m.account(folder, "AllGlobalFilesNamed")()
+	return m.DB.AllGlobalFilesNamed(folder, names)
+}
+
 func (m metricsDB) AllLocalFiles(folder string, device protocol.DeviceID) (iter.Seq[protocol.FileInfo], func() error) {
 	defer m.account(folder, "AllLocalFiles")()
 	return m.DB.AllLocalFiles(folder, device)
@@ -98,6 +108,11 @@ func (m metricsDB) AllNeededGlobalFiles(folder string, device protocol.DeviceID,
 	return m.DB.AllNeededGlobalFiles(folder, device, order, limit, offset)
 }
 
+func (m metricsDB) AllNeededGlobalFilesAfter(folder string, device protocol.DeviceID, after string, limit int) (iter.Seq[protocol.FileInfo], func() error) {
+	defer m.account(folder, "AllNeededGlobalFilesAfter")()
+	return m.DB.AllNeededGlobalFilesAfter(folder, device, after, limit)
+}
+
 func (m metricsDB) GetGlobalAvailability(folder, file string) ([]protocol.DeviceID, error) {
 	defer m.account(folder, "GetGlobalAvailability")()
 	return m.DB.GetGlobalAvailability(folder, file)
@@ -113,6 +128,11 @@ func (m metricsDB) Close() error {
 	return m.DB.Close()
 }
 
+func (m metricsDB) Checkpoint() error {
+	defer m.account("-", "Checkpoint")()
+	return m.DB.Checkpoint()
+}
+
 func (m metricsDB) ListDevicesForFolder(folder string) ([]protocol.DeviceID, error) {
 	defer m.account(folder, "ListDevicesForFolder")()
 	return m.DB.ListDevicesForFolder(folder)
@@ -223,3 +243,8 @@ func (m metricsDB) PrefixKV(prefix string) (iter.Seq[KeyValue], func() error) {
 	defer m.account("-", "PrefixKV")()
 	return m.DB.PrefixKV(prefix)
 }
+
+func (m metricsDB) ReplacePrefixKV(prefix string, kvs []KeyValue) error {
+	defer m.account("-", "ReplacePrefixKV")()
+	return m.DB.ReplacePrefixKV(prefix, kvs)
+}
@@ -210,6 +210,11 @@ func (s *baseDB) Close() error {
 	return wrap(s.sql.Close())
 }
 
+func (s *baseDB) checkpoint() error {
+	_, err := s.sql.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`)
+	return wrap(err, s.baseName)
+}
+
 var tplFuncs = template.FuncMap{
 	"or": func(vs ...int) int {
 		v := vs[0]
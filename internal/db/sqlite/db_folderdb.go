@@ -155,6 +155,17 @@ func (s *DB) AllGlobalFilesPrefix(folder string, prefix string) (iter.Seq[db.Fil
 	return fdb.AllGlobalFilesPrefix(prefix)
 }
 
+func (s *DB) AllGlobalFilesNamed(folder string, names []string) (iter.Seq[protocol.FileInfo], func() error) {
+	fdb, err := s.getFolderDB(folder, false)
+	if errors.Is(err, errNoSuchFolder) {
+		return func(yield func(protocol.FileInfo) bool) {}, func() error { return nil }
+	}
+	if err != nil {
+		return func(yield func(protocol.FileInfo) bool) {}, func() error { return err }
+	}
+	return fdb.AllGlobalFilesNamed(names)
+}
+
 func (s *DB) AllLocalBlocksWithHash(folder string, hash []byte) (iter.Seq[db.BlockMapEntry], func() error) {
 	fdb, err := s.getFolderDB(folder, false)
 	if errors.Is(err, errNoSuchFolder) {
@@ -210,6 +221,17 @@ func (s *DB) AllLocalFilesWithBlocksHash(folder string, h []byte) (iter.Seq[db.F
 	return fdb.AllLocalFilesWithBlocksHash(h)
 }
 
+func (s *DB) AllLocalFilesNamed(folder string, device protocol.DeviceID, names []string) (iter.Seq[db.FileMetadata], func() error) {
+	fdb, err := s.getFolderDB(folder, false)
+	if errors.Is(err, errNoSuchFolder) {
+		return func(yield func(db.FileMetadata) bool) {}, func() error { return nil }
+	}
+	if err != nil {
+		return func(yield func(db.FileMetadata) bool) {}, func() error { return err }
+	}
+	return fdb.AllLocalFilesNamed(device, names)
+}
+
 func (s *DB) AllNeededGlobalFiles(folder string, device protocol.DeviceID, order config.PullOrder, limit, offset int) (iter.Seq[protocol.FileInfo], func() error) {
 	fdb, err := s.getFolderDB(folder, false)
 	if errors.Is(err, errNoSuchFolder) {
@@ -221,6 +243,17 @@ func (s *DB) AllNeededGlobalFiles(folder string, device protocol.DeviceID, order
 	return fdb.AllNeededGlobalFiles(device, order, limit, offset)
 }
 
+func (s *DB) AllNeededGlobalFilesAfter(folder string, device protocol.DeviceID, after string, limit int) (iter.Seq[protocol.FileInfo], func() error) {
+	fdb, err := s.getFolderDB(folder, false)
+	if errors.Is(err, errNoSuchFolder) {
+		return func(yield func(protocol.FileInfo) bool) {}, func() error { return nil }
+	}
+	if err != nil {
+		return func(yield func(protocol.FileInfo) bool) {}, func() error { return err }
+	}
+	return fdb.AllNeededGlobalFilesAfter(device, after, limit)
+}
+
 func (s *DB) DropAllFiles(folder string, device protocol.DeviceID) error {
 	fdb, err := s.getFolderDB(folder, false)
 	if errors.Is(err, errNoSuchFolder) {
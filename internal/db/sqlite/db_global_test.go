@@ -10,6 +10,7 @@ import (
 	"slices"
 	"testing"
 
+	"github.com/syncthing/syncthing/internal/itererr"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
@@ -71,6 +72,18 @@ func TestNeed(t *testing.T) {
 		t.Log(remoteNeed)
 		t.Fatal("bad remote need")
 	}
+
+	// Continuing after a name
+	localNeed = fiNames(mustCollect[protocol.FileInfo](t)(db.AllNeededGlobalFilesAfter(folderID, protocol.LocalDeviceID, "test2", 0)))
+	if !slices.Equal(localNeed, []string{"test4"}) {
+		t.Log(localNeed)
+		t.Fatal("bad local need after test2")
+	}
+	remoteNeed = fiNames(mustCollect[protocol.FileInfo](t)(db.AllNeededGlobalFilesAfter(folderID, protocol.DeviceID{42}, "test1", 0)))
+	if !slices.Equal(remoteNeed, []string{"test3"}) {
+		t.Log(remoteNeed)
+		t.Fatal("bad remote need after test1")
+	}
 }
 
 func TestDropRecalcsGlobal(t *testing.T) {
@@ -567,6 +580,13 @@ func TestNeedPagination(t *testing.T) {
 		t.Log(names)
 		t.Error("bad need")
 	}
+
+	// We should get the three after test4
+	names = fiNames(mustCollect[protocol.FileInfo](t)(db.AllNeededGlobalFilesAfter(folderID, protocol.LocalDeviceID, "test4", 3)))
+	if !slices.Equal(names, []string{"test5", "test6", "test7"}) {
+		t.Log(names)
+		t.Error("bad need")
+	}
 }
 
 func TestDeletedAfterConflict(t *testing.T) {
@@ -624,3 +644,45 @@ func TestDeletedAfterConflict(t *testing.T) {
 		t.Error("should be deleted")
 	}
 }
+
+func TestGlobalFilesNamed(t *testing.T) {
+	t.Parallel()
+
+	sdb, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal()
+	}
+	t.Cleanup(func() {
+		if err := sdb.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if err := sdb.Update(folderID, protocol.LocalDeviceID, []protocol.FileInfo{genFile("a", 1, 0), genFile("b", 1, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	newer := genFile("a", 2, 1)
+	newer.Version = newer.Version.Update(42)
+	if err := sdb.Update(folderID, protocol.DeviceID{42}, []protocol.FileInfo{newer, genFile("remote", 1, 2)}); err != nil {
+		t.Fatal(err)
+	}
+
+	hits, err := itererr.Collect(sdb.AllGlobalFilesNamed(folderID, []string{"a", "remote", "missing"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]protocol.FileInfo)
+	for _, hit := range hits {
+		found[hit.Name] = hit
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected a and remote to be found, got %+v", hits)
+	}
+	if a := found["a"]; len(a.Blocks) != 2 || !a.Version.Equal(newer.Version) {
+		t.Errorf("expected the global version of a with its blocks, got %+v", a)
+	}
+
+	if hits, err := itererr.Collect(sdb.AllGlobalFilesNamed(folderID, nil)); err != nil || len(hits) != 0 {
+		t.Errorf("expected no hits for no names, got %+v, %v", hits, err)
+	}
+}
@@ -7,6 +7,7 @@
 package sqlite
 
 import (
+	"context"
 	"iter"
 
 	"github.com/jmoiron/sqlx"
@@ -43,6 +44,44 @@ func (s *baseDB) DeleteKV(key string) error {
 	return wrap(err)
 }
 
+func (s *baseDB) ReplacePrefixKV(prefix string, kvs []db.KeyValue) error {
+	s.updateLock.Lock()
+	defer s.updateLock.Unlock()
+
+	tx, err := s.sql.BeginTxx(context.Background(), nil)
+	if err != nil {
+		return wrap(err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+	txp := &txPreparedStmts{Tx: tx}
+
+	if prefix == "" {
+		_, err = tx.Exec(`DELETE FROM kv`)
+	} else {
+		_, err = tx.Exec(`
+			DELETE FROM kv
+			WHERE key >= ? AND key < ?
+		`, prefix, prefixEnd(prefix))
+	}
+	if err != nil {
+		return wrap(err)
+	}
+
+	insert, err := txp.Preparex(`
+		INSERT OR REPLACE INTO kv (key, value)
+		VALUES (?, ?)
+	`)
+	if err != nil {
+		return wrap(err)
+	}
+	for _, kv := range kvs {
+		if _, err := insert.Exec(kv.Key, kv.Value); err != nil {
+			return wrap(err)
+		}
+	}
+	return wrap(txp.Commit())
+}
+
 func (s *baseDB) PrefixKV(prefix string) (iter.Seq[db.KeyValue], func() error) {
 	var rows *sqlx.Rows
 	var err error
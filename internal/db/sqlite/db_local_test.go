@@ -138,6 +138,46 @@ func TestBlocksDeleted(t *testing.T) {
 	}
 }
 
+func TestLocalFilesNamed(t *testing.T) {
+	t.Parallel()
+
+	sdb, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal()
+	}
+	t.Cleanup(func() {
+		if err := sdb.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	deleted := genFile("deleted", 1, 0)
+	deleted.Deleted = true
+	files := []protocol.FileInfo{genFile("a", 1, 0), genFile("b", 1, 0), deleted}
+	if err := sdb.Update(folderID, protocol.LocalDeviceID, files); err != nil {
+		t.Fatal(err)
+	}
+	if err := sdb.Update(folderID, protocol.DeviceID{42}, []protocol.FileInfo{genFile("remote", 1, 0)}); err != nil {
+		t.Fatal(err)
+	}
+
+	hits, err := itererr.Collect(sdb.AllLocalFilesNamed(folderID, protocol.LocalDeviceID, []string{"a", "deleted", "remote", "missing"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]bool)
+	for _, hit := range hits {
+		found[hit.Name] = hit.Deleted
+	}
+	if len(found) != 2 || found["a"] || !found["deleted"] {
+		t.Errorf("expected a and deleted to be found, got %+v", hits)
+	}
+
+	if hits, err := itererr.Collect(sdb.AllLocalFilesNamed(folderID, protocol.LocalDeviceID, nil)); err != nil || len(hits) != 0 {
+		t.Errorf("expected no hits for no names, got %+v, %v", hits, err)
+	}
+}
+
 func TestRemoteSequence(t *testing.T) {
 	t.Parallel()
 
@@ -149,6 +149,15 @@ func (s *DB) Close() error {
 	return wrap(s.baseDB.Close())
 }
 
+func (s *DB) Checkpoint() error {
+	if err := s.baseDB.checkpoint(); err != nil {
+		return err
+	}
+	return s.forEachFolder(func(fdb *folderDB) error {
+		return fdb.checkpoint()
+	})
+}
+
 func initTmpDir(path string) {
 	if build.IsWindows || build.IsDarwin || os.Getenv("SQLITE_TMPDIR") != "" {
 		// Doesn't use SQLITE_TMPDIR, isn't likely to have a tiny
@@ -704,6 +704,41 @@ func TestDropFolder(t *testing.T) {
 	}
 }
 
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	db, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	for _, folder := range []string{"a", "b"} {
+		if err := db.Update(folder, protocol.LocalDeviceID, []protocol.FileInfo{genFile("test1", 1, 0)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+
+	wals, err := filepath.Glob(filepath.Join(dir, "*-wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wals) == 0 {
+		t.Fatal("expected write-ahead logs")
+	}
+	for _, wal := range wals {
+		if fi, err := os.Stat(wal); err == nil && fi.Size() != 0 {
+			t.Errorf("expected %s to be empty after checkpoint, has %d bytes", wal, fi.Size())
+		}
+	}
+}
+
 func TestDropDevice(t *testing.T) {
 	db, err := Open(t.TempDir())
 	if err != nil {
@@ -12,6 +12,8 @@ import (
 	"fmt"
 	"iter"
 
+	"github.com/jmoiron/sqlx"
+
 	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/internal/itererr"
 	"github.com/syncthing/syncthing/lib/config"
@@ -43,6 +45,31 @@ func (s *folderDB) GetGlobalFile(file string) (protocol.FileInfo, bool, error) {
 	return fi, true, nil
 }
 
+// AllGlobalFilesNamed returns the global versions of those of the named
+// files that exist.
+func (s *folderDB) AllGlobalFilesNamed(names []string) (iter.Seq[protocol.FileInfo], func() error) {
+	if len(names) == 0 {
+		return func(yield func(protocol.FileInfo) bool) {}, func() error { return nil }
+	}
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		normalized[i] = osutil.NormalizedFilename(name)
+	}
+
+	query, args, err := sqlx.In(s.expandTemplateVars(`
+		SELECT fi.fiprotobuf, bl.blprotobuf FROM fileinfos fi
+		INNER JOIN files f on fi.sequence = f.sequence
+		LEFT JOIN blocklists bl ON bl.blocklist_hash = f.blocklist_hash
+		INNER JOIN file_names n ON f.name_idx = n.idx
+		WHERE n.name IN (?) AND f.local_flags & {{.FlagLocalGlobal}} != 0
+	`), normalized)
+	if err != nil {
+		return func(yield func(protocol.FileInfo) bool) {}, func() error { return wrap(err) }
+	}
+	it, errFn := iterStructs[indirectFI](s.sql.Queryx(query, args...))
+	return itererr.Map(it, errFn, indirectFI.FileInfo)
+}
+
 func (s *folderDB) GetGlobalAvailability(file string) ([]protocol.DeviceID, error) {
 	file = osutil.NormalizedFilename(file)
 
@@ -132,25 +159,41 @@ func (s *folderDB) AllNeededGlobalFiles(device protocol.DeviceID, order config.P
 	}
 
 	if device == protocol.LocalDeviceID {
-		return s.neededGlobalFilesLocal(selectOpts)
+		return s.neededGlobalFilesLocal("", selectOpts)
 	}
 
-	return s.neededGlobalFilesRemote(device, selectOpts)
+	return s.neededGlobalFilesRemote(device, "", selectOpts)
 }
 
-func (s *folderDB) neededGlobalFilesLocal(selectOpts string) (iter.Seq[protocol.FileInfo], func() error) {
+func (s *folderDB) AllNeededGlobalFilesAfter(device protocol.DeviceID, after string, limit int) (iter.Seq[protocol.FileInfo], func() error) {
+	after = osutil.NormalizedFilename(after)
+	selectOpts := "ORDER BY n.name ASC"
+	if limit > 0 {
+		selectOpts += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	if device == protocol.LocalDeviceID {
+		return s.neededGlobalFilesLocal(" AND n.name > ?", selectOpts, after)
+	}
+
+	return s.neededGlobalFilesRemote(device, " AND n.name > ?", selectOpts, after)
+}
+
+// neededGlobalFilesLocal returns the files we need, with nameCond
+// restricting the names further using the given arguments.
+func (s *folderDB) neededGlobalFilesLocal(nameCond, selectOpts string, args ...any) (iter.Seq[protocol.FileInfo], func() error) {
 	// Select all the non-ignored files with the need bit set.
 	it, errFn := iterStructs[indirectFI](s.stmt(`
 		SELECT fi.fiprotobuf, bl.blprotobuf, n.name, g.size, g.modified FROM fileinfos fi
 		INNER JOIN files g on fi.sequence = g.sequence
 		LEFT JOIN blocklists bl ON bl.blocklist_hash = g.blocklist_hash
 		INNER JOIN file_names n ON g.name_idx = n.idx
-		WHERE g.local_flags & {{.FlagLocalIgnored}} = 0 AND g.local_flags & {{.FlagLocalNeeded}} != 0
-	` + selectOpts).Queryx())
+		WHERE g.local_flags & {{.FlagLocalIgnored}} = 0 AND g.local_flags & {{.FlagLocalNeeded}} != 0` + nameCond + `
+	` + selectOpts).Queryx(args...))
 	return itererr.Map(it, errFn, indirectFI.FileInfo)
 }
 
-func (s *folderDB) neededGlobalFilesRemote(device protocol.DeviceID, selectOpts string) (iter.Seq[protocol.FileInfo], func() error) {
+func (s *folderDB) neededGlobalFilesRemote(device protocol.DeviceID, nameCond, selectOpts string, args ...any) (iter.Seq[protocol.FileInfo], func() error) {
 	// Select:
 	//
 	// - all the valid, non-deleted global files that don't have a
@@ -159,6 +202,10 @@ func (s *folderDB) neededGlobalFilesRemote(device protocol.DeviceID, selectOpts
 	// - all the valid, deleted global files that have a corresponding
 	//   non-deleted and valid remote file (of any version)
 
+	queryArgs := append([]any{device.String()}, args...)
+	queryArgs = append(queryArgs, device.String())
+	queryArgs = append(queryArgs, args...)
+
 	it, errFn := iterStructs[indirectFI](s.stmt(`
 		SELECT fi.fiprotobuf, bl.blprotobuf, n.name, g.size, g.modified FROM fileinfos fi
 		INNER JOIN files g on fi.sequence = g.sequence
@@ -168,7 +215,7 @@ func (s *folderDB) neededGlobalFilesRemote(device protocol.DeviceID, selectOpts
 			SELECT 1 FROM FILES f
 			INNER JOIN devices d ON d.idx = f.device_idx
 			WHERE f.name_idx = g.name_idx AND f.version_idx = g.version_idx AND d.device_id = ?
-		)
+		)` + nameCond + `
 
 		UNION ALL
 
@@ -180,10 +227,7 @@ func (s *folderDB) neededGlobalFilesRemote(device protocol.DeviceID, selectOpts
 			SELECT 1 FROM FILES f
 			INNER JOIN devices d ON d.idx = f.device_idx
 			WHERE f.name_idx = g.name_idx AND d.device_id = ? AND NOT f.deleted AND f.local_flags & {{.LocalInvalidFlags}} = 0
-		)
-	`+selectOpts).Queryx(
-		device.String(),
-		device.String(),
-	))
+		)` + nameCond + `
+	` + selectOpts).Queryx(queryArgs...))
 	return itererr.Map(it, errFn, indirectFI.FileInfo)
 }
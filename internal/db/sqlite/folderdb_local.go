@@ -17,6 +17,8 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/jmoiron/sqlx"
+
 	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/internal/itererr"
 	"github.com/syncthing/syncthing/lib/osutil"
@@ -102,6 +104,32 @@ func (s *folderDB) AllLocalFilesWithBlocksHash(h []byte) (iter.Seq[db.FileMetada
 	`).Queryx(h))
 }
 
+// AllLocalFilesNamed returns those of the named files the device has.
+func (s *folderDB) AllLocalFilesNamed(device protocol.DeviceID, names []string) (iter.Seq[db.FileMetadata], func() error) {
+	if len(names) == 0 {
+		return func(yield func(db.FileMetadata) bool) {}, func() error { return nil }
+	}
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		normalized[i] = osutil.NormalizedFilename(name)
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT f.sequence, n.name, f.type, f.modified as modnanos, f.size, f.deleted, f.local_flags as localflags FROM files f
+		INNER JOIN file_names n ON f.name_idx = n.idx
+		INNER JOIN devices d ON d.idx = f.device_idx
+		WHERE d.device_id = ? AND n.name IN (?)
+	`, device.String(), normalized)
+	if err != nil {
+		return func(yield func(db.FileMetadata) bool) {}, func() error { return wrap(err) }
+	}
+	it, errFn := iterStructs[db.FileMetadata](s.sql.Queryx(query, args...))
+	return itererr.Map(it, errFn, func(m db.FileMetadata) (db.FileMetadata, error) {
+		m.Name = osutil.NativeFilename(m.Name)
+		return m, nil
+	})
+}
+
 func (s *folderDB) AllLocalBlocksWithHash(hash []byte) (iter.Seq[db.BlockMapEntry], func() error) {
 	// We involve the files table in this select because deletion of blocks
 	// & blocklists is deferred (garbage collected) while the files list is
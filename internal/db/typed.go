@@ -10,6 +10,8 @@ import (
 	"database/sql"
 	"encoding/binary"
 	"errors"
+	"iter"
+	"strings"
 	"time"
 )
 
@@ -128,6 +130,33 @@ func (n *Typed) Delete(key string) error {
 	return n.db.DeleteKV(n.prefixedKey(key))
 }
 
+// PrefixBytes iterates over all values whose key starts with the given
+// prefix. The returned keys are relative to the namespace, i.e. as they
+// were given to PutBytes.
+func (n *Typed) PrefixBytes(prefix string) (iter.Seq[KeyValue], func() error) {
+	it, errFn := n.db.PrefixKV(n.prefixedKey(prefix))
+	nsPrefix := n.prefixedKey("")
+	return func(yield func(KeyValue) bool) {
+		for kv := range it {
+			kv.Key = strings.TrimPrefix(kv.Key, nsPrefix)
+			if !yield(kv) {
+				return
+			}
+		}
+	}, errFn
+}
+
+// ReplacePrefixBytes replaces all values whose key starts with the given
+// prefix by the given ones, in one go. The keys are relative to the
+// namespace, as for PutBytes.
+func (n *Typed) ReplacePrefixBytes(prefix string, kvs []KeyValue) error {
+	nkvs := make([]KeyValue, len(kvs))
+	for i, kv := range kvs {
+		nkvs[i] = KeyValue{Key: n.prefixedKey(kv.Key), Value: kv.Value}
+	}
+	return n.db.ReplacePrefixKV(n.prefixedKey(prefix), nkvs)
+}
+
 func (n *Typed) prefixedKey(key string) string {
 	return n.prefix + "/" + key
 }
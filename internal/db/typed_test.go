@@ -7,6 +7,7 @@
 package db_test
 
 import (
+	"slices"
 	"testing"
 	"time"
 
@@ -112,4 +113,64 @@ func TestNamespacedInt(t *testing.T) {
 			t.Errorf("Incorrect return v %q != \"yo\" || ok %v != true", v, ok)
 		}
 	})
+
+	t.Run("PrefixBytes", func(t *testing.T) {
+		t.Parallel()
+
+		for _, key := range []string{"seq/2", "seq/1", "other"} {
+			if err := n1.PutBytes(key, []byte(key)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := n2.PutBytes("seq/3", []byte("seq/3")); err != nil {
+			t.Fatal(err)
+		}
+
+		it, errFn := n1.PrefixBytes("seq/")
+		var keys []string
+		for kv := range it {
+			if string(kv.Value) != kv.Key {
+				t.Errorf("Incorrect value %q for key %q", kv.Value, kv.Key)
+			}
+			keys = append(keys, kv.Key)
+		}
+		if err := errFn(); err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) != 2 || keys[0] != "seq/1" || keys[1] != "seq/2" {
+			t.Errorf("Incorrect keys %v", keys)
+		}
+	})
+
+	t.Run("ReplacePrefixBytes", func(t *testing.T) {
+		t.Parallel()
+
+		for _, key := range []string{"repl/1", "repl/2", "replaced"} {
+			if err := n1.PutBytes(key, []byte(key)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := n2.PutBytes("repl/1", []byte("repl/1")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := n1.ReplacePrefixBytes("repl/", []db.KeyValue{{Key: "repl/3", Value: []byte("repl/3")}}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Only the keys with the prefix in the namespace are replaced.
+		for ns, keys := range map[*db.Typed][]string{n1: {"repl/3", "replaced"}, n2: {"repl/1"}} {
+			it, errFn := ns.PrefixBytes("repl")
+			var got []string
+			for kv := range it {
+				got = append(got, kv.Key)
+			}
+			if err := errFn(); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, keys) {
+				t.Errorf("Incorrect keys %v, expected %v", got, keys)
+			}
+		}
+	})
 }
@@ -7,8 +7,11 @@
 package slogutil
 
 import (
+	"bytes"
 	"cmp"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"path"
@@ -22,6 +25,9 @@ type LineFormat struct {
 	TimestampFormat string
 	LevelString     bool
 	LevelSyslog     bool
+	// JSON makes the output one JSON object per line instead of text. The
+	// other format options are then ignored.
+	JSON bool
 }
 
 type formattingOptions struct {
@@ -51,8 +57,9 @@ func (h *formattingHandler) Enabled(context.Context, slog.Level) bool {
 func (h *formattingHandler) Handle(_ context.Context, rec slog.Record) error {
 	fr := runtime.CallersFrames([]uintptr{rec.PC})
 	var logAttrs []any
+	var pkgName, typeName string
 	if fram, _ := fr.Next(); fram.Function != "" {
-		pkgName, typeName := funcNameToPkg(fram.Function)
+		pkgName, typeName = funcNameToPkg(fram.Function)
 		lvl := globalLevels.Get(pkgName)
 		if lvl > rec.Level {
 			// Logging not enabled at the record's level
@@ -89,9 +96,11 @@ func (h *formattingHandler) Handle(_ context.Context, rec slog.Record) error {
 
 	// Expand and format attributes
 	var attrCount int
+	var expanded []slog.Attr
 	for _, attr := range attrs {
 		for _, attr := range expandAttrs("", attr) {
 			appendAttr(&sb, "", attr, &attrCount)
+			expanded = append(expanded, attr)
 		}
 	}
 	if attrCount > 0 {
@@ -99,9 +108,10 @@ func (h *formattingHandler) Handle(_ context.Context, rec slog.Record) error {
 	}
 
 	line := Line{
-		When:    cmp.Or(h.opts.timeOverride, rec.Time),
-		Message: sb.String(),
-		Level:   rec.Level,
+		When:     cmp.Or(h.opts.timeOverride, rec.Time),
+		Message:  sb.String(),
+		Level:    rec.Level,
+		Facility: pkgName,
 	}
 
 	// If there is a recorder, record the line.
@@ -111,11 +121,63 @@ func (h *formattingHandler) Handle(_ context.Context, rec slog.Record) error {
 
 	// If there's an output, print the line.
 	if h.opts.out != nil {
-		_, _ = line.WriteTo(h.opts.out, h.opts.LineFormat)
+		if h.opts.JSON {
+			_, _ = writeJSONLine(h.opts.out, line.When, rec.Level, pkgName, rec.Message, expanded)
+		} else {
+			_, _ = line.WriteTo(h.opts.out, h.opts.LineFormat)
+		}
 	}
 	return nil
 }
 
+// writeJSONLine writes the record as a single line JSON object. The
+// attributes are flattened to dotted keys, the same way they are for text
+// output, so that e.g. the folder ID becomes "folder.id".
+func writeJSONLine(w io.Writer, when time.Time, level slog.Level, pkg, msg string, attrs []slog.Attr) (int64, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(`{"time":`)
+	writeJSONValue(buf, when.Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJSONValue(buf, level.String())
+	if pkg != "" {
+		buf.WriteString(`,"facility":`)
+		writeJSONValue(buf, pkg)
+	}
+	buf.WriteString(`,"msg":`)
+	writeJSONValue(buf, msg)
+	for _, attr := range attrs {
+		if attr.Key == "" || attr.Key == "log.pkg" {
+			continue
+		}
+		buf.WriteRune(',')
+		writeJSONValue(buf, attr.Key)
+		buf.WriteRune(':')
+		writeJSONValue(buf, jsonValue(attr.Value.Resolve()))
+	}
+	buf.WriteString("}\n")
+	return buf.WriteTo(w)
+}
+
+func jsonValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString, slog.KindInt64, slog.KindUint64, slog.KindFloat64, slog.KindBool:
+		return v.Any()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	default:
+		return v.String()
+	}
+}
+
+func writeJSONValue(buf *bytes.Buffer, v any) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		// Typically a NaN or infinite float
+		bs, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(bs)
+}
+
 func expandAttrs(prefix string, a slog.Attr) []slog.Attr {
 	if prefix != "" {
 		a.Key = prefix + "." + a.Key
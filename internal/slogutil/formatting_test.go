@@ -56,3 +56,30 @@ func TestFormattingHandler(t *testing.T) {
 		t.Error("mismatch")
 	}
 }
+
+func TestFormattingHandlerJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	format := DefaultLineFormat
+	format.JSON = true
+	h := &formattingHandler{
+		opts: &formattingOptions{
+			LineFormat:   format,
+			out:          buf,
+			timeOverride: time.Unix(1234567890, 0).In(time.UTC),
+		},
+	}
+
+	l := slog.New(h).With("a", "a")
+	l.Info("A basic info line", "attr1", "val with \"quotes\"", "attr2", 2, slog.Group("folder", "id", "abcd-1234"))
+	l.Warn("A warning", "ok", true)
+
+	exp := `
+{"time":"2009-02-13T23:31:30Z","level":"INFO","facility":"slogutil","msg":"A basic info line","attr1":"val with \"quotes\"","attr2":2,"folder.id":"abcd-1234","a":"a"}
+{"time":"2009-02-13T23:31:30Z","level":"WARN","facility":"slogutil","msg":"A warning","ok":true,"a":"a"}`
+
+	if strings.TrimSpace(buf.String()) != strings.TrimSpace(exp) {
+		t.Log(buf.String())
+		t.Log(exp)
+		t.Error("mismatch")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"maps"
 	"strings"
 	"sync"
+	"time"
 )
 
 // A levelTracker keeps track of log level per package. This enables the
@@ -36,6 +37,24 @@ func SetPackageLevel(pkg string, level slog.Level) {
 	globalLevels.Set(pkg, level)
 }
 
+// SetPackageLevelFor sets the package to the given level for the given
+// duration, after which it reverts to the level it had before.
+func SetPackageLevelFor(pkg string, level slog.Level, dur time.Duration) {
+	globalLevels.SetTemporary(pkg, level, dur)
+}
+
+// ResetPackageLevel removes any level set for the package, returning it to
+// the default level.
+func ResetPackageLevel(pkg string) {
+	globalLevels.Reset(pkg)
+}
+
+// PackageLevelExpiries returns the time at which each temporarily set
+// package level reverts.
+func PackageLevelExpiries() map[string]time.Time {
+	return globalLevels.Expiries()
+}
+
 func SetDefaultLevel(level slog.Level) {
 	globalLevels.SetDefault(level)
 }
@@ -63,6 +82,15 @@ type levelTracker struct {
 	defLevel slog.Level
 	descrs   map[string]string     // package name to description
 	levels   map[string]slog.Level // package name to level
+	temps    map[string]*tempLevel // package name to temporary level, if any
+}
+
+// A tempLevel records what to revert to when a temporary level expires.
+type tempLevel struct {
+	prev    slog.Level
+	hadPrev bool
+	expires time.Time
+	timer   *time.Timer
 }
 
 func (t *levelTracker) Get(pkg string) slog.Level {
@@ -76,6 +104,7 @@ func (t *levelTracker) Get(pkg string) slog.Level {
 
 func (t *levelTracker) Set(pkg string, level slog.Level) {
 	t.mut.Lock()
+	t.cancelTempLocked(pkg)
 	changed := t.levels[pkg] != level
 	t.levels[pkg] = level
 	t.mut.Unlock()
@@ -84,6 +113,77 @@ func (t *levelTracker) Set(pkg string, level slog.Level) {
 	}
 }
 
+func (t *levelTracker) SetTemporary(pkg string, level slog.Level, dur time.Duration) {
+	t.mut.Lock()
+	// Each call gets its own tempLevel, so that the timer of an earlier
+	// call that has already fired can't revert this one.
+	tmp := &tempLevel{expires: time.Now().Add(dur)}
+	if old, ok := t.temps[pkg]; ok {
+		// Extending or changing an existing temporary level; keep
+		// reverting to what was there originally.
+		old.timer.Stop()
+		tmp.prev, tmp.hadPrev = old.prev, old.hadPrev
+	} else {
+		tmp.prev, tmp.hadPrev = t.levels[pkg]
+		if t.temps == nil {
+			t.temps = make(map[string]*tempLevel)
+		}
+	}
+	t.temps[pkg] = tmp
+	tmp.timer = time.AfterFunc(dur, func() { t.expire(pkg, tmp) })
+	t.levels[pkg] = level
+	t.mut.Unlock()
+	slog.Info("Temporarily changed package log level", "package", pkg, "level", level, "duration", dur)
+}
+
+func (t *levelTracker) expire(pkg string, tmp *tempLevel) {
+	t.mut.Lock()
+	if t.temps[pkg] != tmp {
+		// Replaced or cancelled since the timer was started
+		t.mut.Unlock()
+		return
+	}
+	delete(t.temps, pkg)
+	level := t.defLevel
+	if tmp.hadPrev {
+		t.levels[pkg] = tmp.prev
+		level = tmp.prev
+	} else {
+		delete(t.levels, pkg)
+	}
+	t.mut.Unlock()
+	slog.Info("Reverted temporary package log level", "package", pkg, "level", level)
+}
+
+func (t *levelTracker) Reset(pkg string) {
+	t.mut.Lock()
+	t.cancelTempLocked(pkg)
+	_, changed := t.levels[pkg]
+	delete(t.levels, pkg)
+	level := t.defLevel
+	t.mut.Unlock()
+	if changed {
+		slog.Info("Changed package log level", "package", pkg, "level", level)
+	}
+}
+
+func (t *levelTracker) cancelTempLocked(pkg string) {
+	if tmp, ok := t.temps[pkg]; ok {
+		tmp.timer.Stop()
+		delete(t.temps, pkg)
+	}
+}
+
+func (t *levelTracker) Expiries() map[string]time.Time {
+	t.mut.RLock()
+	defer t.mut.RUnlock()
+	m := make(map[string]time.Time, len(t.temps))
+	for pkg, tmp := range t.temps {
+		m[pkg] = tmp.expires
+	}
+	return m
+}
+
 func (t *levelTracker) SetDefault(level slog.Level) {
 	t.mut.Lock()
 	changed := t.defLevel != level
@@ -0,0 +1,99 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package slogutil
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestTemporaryLevel(t *testing.T) {
+	t.Parallel()
+
+	lt := &levelTracker{
+		defLevel: slog.LevelInfo,
+		levels:   make(map[string]slog.Level),
+		descrs:   make(map[string]string),
+	}
+	lt.Set("model", slog.LevelWarn)
+
+	lt.SetTemporary("model", slog.LevelDebug, 50*time.Millisecond)
+	lt.SetTemporary("protocol", slog.LevelDebug, 50*time.Millisecond)
+	if got := lt.Get("model"); got != slog.LevelDebug {
+		t.Errorf("model at %v, expected debug", got)
+	}
+	if exp := lt.Expiries(); len(exp) != 2 {
+		t.Errorf("expected two expiries, got %v", exp)
+	}
+
+	// Extending does not forget the original level
+	lt.SetTemporary("model", slog.LevelDebug, 100*time.Millisecond)
+
+	time.Sleep(75 * time.Millisecond)
+	if got := lt.Get("protocol"); got != slog.LevelInfo {
+		t.Errorf("protocol at %v after expiry, expected default", got)
+	}
+	if got := lt.Get("model"); got != slog.LevelDebug {
+		t.Errorf("model at %v before extended expiry, expected debug", got)
+	}
+
+	time.Sleep(75 * time.Millisecond)
+	if got := lt.Get("model"); got != slog.LevelWarn {
+		t.Errorf("model at %v after expiry, expected warn", got)
+	}
+	if exp := lt.Expiries(); len(exp) != 0 {
+		t.Errorf("expected no expiries, got %v", exp)
+	}
+}
+
+func TestPermanentLevelCancelsTemporary(t *testing.T) {
+	t.Parallel()
+
+	lt := &levelTracker{
+		defLevel: slog.LevelInfo,
+		levels:   make(map[string]slog.Level),
+		descrs:   make(map[string]string),
+	}
+	lt.SetTemporary("model", slog.LevelDebug, 20*time.Millisecond)
+	lt.Set("model", slog.LevelError)
+	time.Sleep(40 * time.Millisecond)
+	if got := lt.Get("model"); got != slog.LevelError {
+		t.Errorf("model at %v, expected error", got)
+	}
+
+	lt.SetTemporary("db", slog.LevelDebug, time.Hour)
+	lt.Reset("db")
+	if got := lt.Get("db"); got != slog.LevelInfo {
+		t.Errorf("db at %v after reset, expected default", got)
+	}
+	if exp := lt.Expiries(); len(exp) != 0 {
+		t.Errorf("expected no expiries, got %v", exp)
+	}
+}
+
+func TestStaleTemporaryExpiry(t *testing.T) {
+	t.Parallel()
+
+	lt := &levelTracker{
+		defLevel: slog.LevelInfo,
+		levels:   make(map[string]slog.Level),
+		descrs:   make(map[string]string),
+	}
+
+	lt.SetTemporary("model", slog.LevelDebug, time.Hour)
+	stale := lt.temps["model"]
+	lt.SetTemporary("model", slog.LevelDebug, time.Hour)
+
+	// The timer of the first call firing after the second call must not
+	// revert the level.
+	lt.expire("model", stale)
+	if got := lt.Get("model"); got != slog.LevelDebug {
+		t.Errorf("model at %v after stale expiry, expected debug", got)
+	}
+	lt.Reset("model")
+}
@@ -18,9 +18,10 @@ import (
 // A Line is our internal representation of a formatted log line. This is
 // what we present in the API and what we buffer internally.
 type Line struct {
-	When    time.Time  `json:"when"`
-	Message string     `json:"message"`
-	Level   slog.Level `json:"level"`
+	When     time.Time  `json:"when"`
+	Message  string     `json:"message"`
+	Level    slog.Level `json:"level"`
+	Facility string     `json:"facility,omitempty"`
 }
 
 func (l *Line) WriteTo(w io.Writer, f LineFormat) (int64, error) {
@@ -76,9 +77,13 @@ func (l *Line) syslogPriority() int {
 
 func (l *Line) MarshalJSON() ([]byte, error) {
 	// Custom marshal to get short level strings instead of default JSON serialisation
-	return json.Marshal(map[string]any{
+	m := map[string]any{
 		"when":    l.When,
 		"message": l.Message,
 		"level":   l.levelStr(),
-	})
+	}
+	if l.Facility != "" {
+		m["facility"] = l.Facility
+	}
+	return json.Marshal(m)
 }
@@ -12,11 +12,19 @@ import (
 	"time"
 )
 
-const maxLogLines = 1000
+const (
+	maxLogLines       = 1000
+	subscriberBufSize = 256
+)
 
 type Recorder interface {
 	Since(t time.Time) []Line
 	Clear()
+	// Subscribe returns a channel receiving each new line as it is
+	// recorded, and a function to cancel the subscription. Lines are
+	// dropped rather than blocking logging when the subscriber falls
+	// behind.
+	Subscribe() (<-chan Line, func())
 }
 
 func NewRecorder(level slog.Level) Recorder {
@@ -27,6 +35,7 @@ type lineRecorder struct {
 	level slog.Level
 	mut   sync.Mutex
 	lines []Line
+	subs  map[chan Line]struct{}
 }
 
 func (r *lineRecorder) record(line Line) {
@@ -38,9 +47,30 @@ func (r *lineRecorder) record(line Line) {
 	if len(r.lines) > maxLogLines {
 		r.lines = r.lines[len(r.lines)-maxLogLines:]
 	}
+	for ch := range r.subs {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
 	r.mut.Unlock()
 }
 
+func (r *lineRecorder) Subscribe() (<-chan Line, func()) {
+	ch := make(chan Line, subscriberBufSize)
+	r.mut.Lock()
+	if r.subs == nil {
+		r.subs = make(map[chan Line]struct{})
+	}
+	r.subs[ch] = struct{}{}
+	r.mut.Unlock()
+	return ch, func() {
+		r.mut.Lock()
+		delete(r.subs, ch)
+		r.mut.Unlock()
+	}
+}
+
 func (r *lineRecorder) Clear() {
 	r.mut.Lock()
 	r.lines = nil
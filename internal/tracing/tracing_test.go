@@ -0,0 +1,56 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+
+	var inner trace.SpanContext
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := Start(r.Context(), "inner")
+		inner = span.SpanContext()
+		span.End()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/rest/system/restart", nil))
+
+	spans := exp.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected two spans, got %d", len(spans))
+	}
+	outer := spans[1]
+	if outer.Name != "POST /rest/system/restart" {
+		t.Errorf("unexpected span name %q", outer.Name)
+	}
+	if outer.Status.Code != codes.Error {
+		t.Errorf("expected error status, got %v", outer.Status.Code)
+	}
+	if spans[0].Parent.SpanID() != outer.SpanContext.SpanID() || inner.TraceID() != outer.SpanContext.TraceID() {
+		t.Error("inner span is not a child of the request span")
+	}
+
+	// Spans started without a context carrying a span are roots
+	_, span := Start(context.Background(), "root")
+	span.End()
+	if spans := exp.GetSpans(); spans[len(spans)-1].Parent.IsValid() {
+		t.Error("expected a root span")
+	}
+}
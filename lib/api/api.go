@@ -18,6 +18,7 @@ import (
 	"io"
 	"log"
 	"log/slog"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
@@ -44,6 +45,7 @@ import (
 
 	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/internal/tracing"
 	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/connections"
@@ -54,8 +56,10 @@ import (
 	"github.com/syncthing/syncthing/lib/model"
 	"github.com/syncthing/syncthing/lib/protocol"
 	"github.com/syncthing/syncthing/lib/rand"
+	"github.com/syncthing/syncthing/lib/stats"
 	"github.com/syncthing/syncthing/lib/svcutil"
 	"github.com/syncthing/syncthing/lib/tlsutil"
+	"github.com/syncthing/syncthing/lib/totp"
 	"github.com/syncthing/syncthing/lib/upgrade"
 	"github.com/syncthing/syncthing/lib/ur"
 )
@@ -68,6 +72,7 @@ const (
 	EventSubBufferSize    = 1000
 	defaultEventTimeout   = time.Minute
 	httpsCertLifetimeDays = 820
+	remoteManageTimeout   = 30 * time.Second
 )
 
 type service struct {
@@ -93,6 +98,8 @@ type service struct {
 	listenerAddr         net.Addr
 	exitChan             chan *svcutil.FatalErr
 	miscDB               *db.Typed
+	auditLog             *apiAuditLog
+	configHistory        *configHistory
 	shutdownTimeout      time.Duration
 
 	guiErrors slogutil.Recorder
@@ -130,6 +137,8 @@ func New(id protocol.DeviceID, cfg config.Wrapper, assetDir, tlsDefaultCommonNam
 		startedOnce:          make(chan struct{}),
 		exitChan:             make(chan *svcutil.FatalErr, 1),
 		miscDB:               miscDB,
+		auditLog:             newAPIAuditLog(miscDB),
+		configHistory:        newConfigHistory(miscDB),
 		shutdownTimeout:      100 * time.Millisecond,
 	}
 }
@@ -252,22 +261,36 @@ func (s *service) Serve(ctx context.Context) error {
 	// The GET handlers
 	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/pending/devices", s.getPendingDevices) // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/pending/folders", s.getPendingFolders) // [device]
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/remote/status", s.getRemoteStatus)     // device
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/paths", s.getClusterPaths)             // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/remote/config", s.getRemoteConfig)     // device
 	restMux.HandlerFunc(http.MethodGet, "/rest/db/completion", s.getDBCompletion)             // [device] [folder]
 	restMux.HandlerFunc(http.MethodGet, "/rest/db/file", s.getDBFile)                         // folder file
 	restMux.HandlerFunc(http.MethodGet, "/rest/db/ignores", s.getDBIgnores)                   // folder
 	restMux.HandlerFunc(http.MethodGet, "/rest/db/need", s.getDBNeed)                         // folder [perpage] [page]
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/pins", s.getDBPins)                         // folder
 	restMux.HandlerFunc(http.MethodGet, "/rest/db/remoteneed", s.getDBRemoteNeed)             // device folder [perpage] [page]
 	restMux.HandlerFunc(http.MethodGet, "/rest/db/localchanged", s.getDBLocalChanged)         // folder [perpage] [page]
 	restMux.HandlerFunc(http.MethodGet, "/rest/db/status", s.getDBStatus)                     // folder
 	restMux.HandlerFunc(http.MethodGet, "/rest/db/browse", s.getDBBrowse)                     // folder [prefix] [dirsonly] [levels]
-	restMux.HandlerFunc(http.MethodGet, "/rest/folder/versions", s.getFolderVersions)         // folder
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/versions", s.getFolderVersions)         // folder [from] [to]
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/versions/content", s.getVersionContent) // folder file time
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/content", s.getFolderContent)           // folder file
 	restMux.HandlerFunc(http.MethodGet, "/rest/folder/errors", s.getFolderErrors)             // folder [perpage] [page]
 	restMux.HandlerFunc(http.MethodGet, "/rest/folder/pullerrors", s.getFolderErrors)         // folder (deprecated)
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/encryption", s.getFolderEncryption)     // folder device
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/approval", s.getFolderApproval)         // folder
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/deleted", s.getFolderDeleted)           // folder [prefix]
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/caseconflicts", s.getCaseConflicts)     // folder
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/pullpreview", s.getFolderPullPreview)   // folder
 	restMux.HandlerFunc(http.MethodGet, "/rest/events", s.getIndexEvents)                     // [since] [limit] [timeout] [events]
 	restMux.HandlerFunc(http.MethodGet, "/rest/events/disk", s.getDiskEvents)                 // [since] [limit] [timeout]
 	restMux.HandlerFunc(http.MethodGet, "/rest/noauth/health", s.getHealth)                   // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/stats/device", s.getDeviceStats)               // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/stats/activity", s.getFileActivity)            // [since] [device]
 	restMux.HandlerFunc(http.MethodGet, "/rest/stats/folder", s.getFolderStats)               // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/stats/folder/history", s.getFolderSizeHistory) // folder
+	restMux.HandlerFunc(http.MethodGet, "/rest/stats/transfer", s.getTransferStats)           // [since] [device]
 	restMux.HandlerFunc(http.MethodGet, "/rest/svc/deviceid", s.getDeviceID)                  // id
 	restMux.HandlerFunc(http.MethodGet, "/rest/svc/lang", s.getLang)                          // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/svc/report", s.getReport)                      // -
@@ -276,36 +299,58 @@ func (s *service) Serve(ctx context.Context) error {
 	restMux.HandlerFunc(http.MethodGet, "/rest/system/connections", s.getSystemConnections)   // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/system/discovery", s.getSystemDiscovery)       // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/system/error", s.getSystemError)               // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/health", s.getSystemHealth)             // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/system/paths", s.getSystemPaths)               // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/system/ping", s.restPing)                      // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/system/status", s.getSystemStatus)             // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/system/upgrade", s.getSystemUpgrade)           // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/system/version", s.getSystemVersion)           // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/loglevels", s.getSystemDebug)           // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/loglevels", s.getSystemLogLevels)       // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/debug", s.getSystemLogLevels)           // -
 	restMux.HandlerFunc(http.MethodGet, "/rest/system/log", s.getSystemLog)                   // [since]
 	restMux.HandlerFunc(http.MethodGet, "/rest/system/log.txt", s.getSystemLogTxt)            // [since]
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/log/stream", s.getSystemLogStream)      // [facility] [level]
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/audit", s.getSystemAudit)               // [since] [limit]
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/watches", s.getSystemWatches)           // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/scans", s.getSystemScans)               // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/auth/totp", s.getSystemAuthTOTP)        // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/config/history", s.getConfigHistory)           // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/config/history/diff", s.getConfigHistoryDiff)  // version [to]
 
 	// The POST handlers
-	restMux.HandlerFunc(http.MethodPost, "/rest/db/prio", s.postDBPrio)                          // folder file
-	restMux.HandlerFunc(http.MethodPost, "/rest/db/ignores", s.postDBIgnores)                    // folder
-	restMux.HandlerFunc(http.MethodPost, "/rest/db/override", s.postDBOverride)                  // folder
-	restMux.HandlerFunc(http.MethodPost, "/rest/db/revert", s.postDBRevert)                      // folder
-	restMux.HandlerFunc(http.MethodPost, "/rest/db/scan", s.postDBScan)                          // folder [sub...] [delay]
-	restMux.HandlerFunc(http.MethodPost, "/rest/folder/versions", s.postFolderVersionsRestore)   // folder <body>
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/error", s.postSystemError)                // <body>
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/error/clear", s.postSystemErrorClear)     // -
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/ping", s.restPing)                        // -
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/reset", s.postSystemReset)                // [folder]
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/restart", s.postSystemRestart)            // -
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/shutdown", s.postSystemShutdown)          // -
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/upgrade", s.postSystemUpgrade)            // -
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/pause", s.makeDevicePauseHandler(true))   // [device]
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/resume", s.makeDevicePauseHandler(false)) // [device]
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/loglevels", s.postSystemDebug)            // [enable] [disable]
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/prio", s.postDBPrio)                                // folder file
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/ignores", s.postDBIgnores)                          // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/ignores/preview", s.postDBIgnoresPreview)           // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/override", s.postDBOverride)                        // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/pins", s.postDBPins)                                // folder path [pinned]
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/revert", s.postDBRevert)                            // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/scan", s.postDBScan)                                // folder [sub...] [delay]
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/adopt", s.postDBAdopt)                              // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/versions", s.postFolderVersionsRestore)         // folder <body>
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/snapshot", s.postFolderSnapshotRestore)         // folder dir time dest
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/encryption", s.postFolderEncryption)            // folder device <body>
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/approval", s.postFolderApproval)                // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/undelete", s.postFolderUndelete)                // folder file
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/caseconflicts", s.postCaseConflicts)            // folder file to
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/error", s.postSystemError)                      // <body>
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/error/clear", s.postSystemErrorClear)           // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/ping", s.restPing)                              // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/reset", s.postSystemReset)                      // [folder]
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/restart", s.postSystemRestart)                  // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/shutdown", s.postSystemShutdown)                // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/upgrade", s.postSystemUpgrade)                  // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/pause", s.makeDevicePauseHandler(true))         // [device] [global] [reason] [originator] [duration]
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/resume", s.makeDevicePauseHandler(false))       // [device]
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/loglevels", s.postSystemLogLevels)              // <body>
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/debug", s.postSystemDebug)                      // [enable] [disable] [level] [duration]
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/auth/totp", s.postSystemAuthTOTP)               // <body>
+	restMux.HandlerFunc(http.MethodPost, "/rest/cluster/remote/config", s.postRemoteConfig)            // device <body>
+	restMux.HandlerFunc(http.MethodPost, "/rest/config/history/rollback", s.postConfigHistoryRollback) // version
 
 	// The DELETE handlers
 	restMux.HandlerFunc(http.MethodDelete, "/rest/cluster/pending/devices", s.deletePendingDevices) // device
 	restMux.HandlerFunc(http.MethodDelete, "/rest/cluster/pending/folders", s.deletePendingFolders) // folder [device]
+	restMux.HandlerFunc(http.MethodDelete, "/rest/system/auth/totp", s.deleteSystemAuthTOTP)        // <body>
 
 	// Config endpoints
 
@@ -313,6 +358,7 @@ func (s *service) Serve(ctx context.Context) error {
 		Router: restMux,
 		id:     s.id,
 		cfg:    s.cfg,
+		model:  s.model,
 	}
 
 	configBuilder.registerConfig("/rest/config")
@@ -322,9 +368,12 @@ func (s *service) Serve(ctx context.Context) error {
 	configBuilder.registerDevices("/rest/config/devices")
 	configBuilder.registerFolder("/rest/config/folders/:id")
 	configBuilder.registerDevice("/rest/config/devices/:id")
+	configBuilder.registerDeviceMerge("/rest/config/devices/:id/merge")
 	configBuilder.registerDefaultFolder("/rest/config/defaults/folder")
 	configBuilder.registerDefaultDevice("/rest/config/defaults/device")
 	configBuilder.registerDefaultIgnores("/rest/config/defaults/ignores")
+	configBuilder.registerFolderTemplates("/rest/config/defaults/templates")
+	configBuilder.registerFolderTemplate("/rest/config/defaults/templates/:name")
 	configBuilder.registerOptions("/rest/config/options")
 	configBuilder.registerLDAP("/rest/config/ldap")
 	configBuilder.registerGUI("/rest/config/gui")
@@ -341,8 +390,9 @@ func (s *service) Serve(ctx context.Context) error {
 	debugMux.HandleFunc("/rest/debug/file", s.getDebugFile)
 	restMux.Handler(http.MethodGet, "/rest/debug/*method", debugMux)
 
-	// A handler that disables caching
-	noCacheRestMux := noCacheMiddleware(restMux)
+	// A handler that disables caching, records mutating requests in the
+	// audit log and traces each request
+	noCacheRestMux := tracing.Middleware(noCacheMiddleware(auditMiddleware(s.auditLog, s.configHistory, s.cfg, "sessionid-"+s.id.Short().String(), restMux)))
 
 	// The main routing handler
 	mux := http.NewServeMux()
@@ -363,7 +413,7 @@ func (s *service) Serve(ctx context.Context) error {
 
 	// Wrap everything in CSRF protection. The /rest prefix should be
 	// protected, other requests will grant cookies.
-	var handler http.Handler = newCsrfManager(s.id.Short().String(), "/rest", guiCfg, mux, s.miscDB)
+	var handler http.Handler = newCsrfManager(s.id.Short().String(), "/rest", guiCfg, mux, s.miscDB, s.evLogger)
 
 	// Add our version and ID as a header to responses
 	handler = withDetailsMiddleware(s.id, handler)
@@ -371,7 +421,7 @@ func (s *service) Serve(ctx context.Context) error {
 	// Wrap everything in basic auth, if user/password is set.
 	if guiCfg.IsAuthEnabled() {
 		tokenCookieManager := newTokenCookieManager(s.id.Short().String(), guiCfg, s.evLogger, s.miscDB)
-		authMW := newBasicAuthAndSessionMiddleware(tokenCookieManager, guiCfg, s.cfg.LDAP(), handler, s.evLogger)
+		authMW := newBasicAuthAndSessionMiddleware(tokenCookieManager, guiCfg, s.cfg.LDAP(), handler, s.evLogger, s.useTOTPRecoveryCode)
 		handler = authMW
 
 		restMux.Handler(http.MethodPost, "/rest/noauth/auth/password", http.HandlerFunc(authMW.passwordAuthHandler))
@@ -380,6 +430,12 @@ func (s *service) Serve(ctx context.Context) error {
 		restMux.Handler(http.MethodPost, "/rest/noauth/auth/logout", http.HandlerFunc(authMW.handleLogout))
 	}
 
+	// Refuse anything that would change something, whoever is asking.
+	if guiCfg.IsReadOnly() {
+		handler = readOnlyMiddleware(handler)
+		slog.InfoContext(ctx, "GUI and API are read-only")
+	}
+
 	// Redirect to HTTPS if we are supposed to
 	if guiCfg.UseTLS() {
 		handler = redirectToHTTPSMiddleware(handler)
@@ -486,7 +542,7 @@ func (*service) VerifyConfiguration(_, to config.Configuration) error {
 }
 
 func (s *service) CommitConfiguration(from, to config.Configuration) bool {
-	if to.GUI == from.GUI {
+	if sameGUIConfig(to.GUI, from.GUI) {
 		// No GUI changes, we're done here.
 		return true
 	}
@@ -501,6 +557,17 @@ func (s *service) CommitConfiguration(from, to config.Configuration) bool {
 	return true
 }
 
+// sameGUIConfig returns true if the GUI configurations are equal. The
+// recovery codes are compared by content, as an empty list may or may not
+// be nil.
+func sameGUIConfig(a, b config.GUIConfiguration) bool {
+	if !slices.Equal(a.TOTPRecoveryCodes, b.TOTPRecoveryCodes) {
+		return false
+	}
+	a.TOTPRecoveryCodes, b.TOTPRecoveryCodes = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
 func (s *service) fatal(err *svcutil.FatalErr) {
 	// s.exitChan is 1-buffered and whoever is first gets handled.
 	select {
@@ -610,6 +677,18 @@ func noCacheMiddleware(h http.Handler) http.Handler {
 	})
 }
 
+// readOnlyMiddleware refuses the requests that would change anything,
+// letting only logging in and out through.
+func readOnlyMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isMutatingRequest(r) {
+			http.Error(w, "The GUI and API are read-only", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 func withDetailsMiddleware(id protocol.DeviceID, h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-Syncthing-Version", build.Version)
@@ -671,6 +750,10 @@ func (s *service) getPendingFolders(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, folders)
 }
 
+func (s *service) getClusterPaths(w http.ResponseWriter, _ *http.Request) {
+	sendJSON(w, s.model.FolderPathWarnings())
+}
+
 func (s *service) deletePendingFolders(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 
@@ -687,6 +770,58 @@ func (s *service) deletePendingFolders(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *service) getRemoteStatus(w http.ResponseWriter, r *http.Request) {
+	s.manageRemote(w, r, protocol.ManagementOperationStatus, nil)
+}
+
+func (s *service) getRemoteConfig(w http.ResponseWriter, r *http.Request) {
+	s.manageRemote(w, r, protocol.ManagementOperationGetConfig, nil)
+}
+
+func (s *service) postRemoteConfig(w http.ResponseWriter, r *http.Request) {
+	bs, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.manageRemote(w, r, protocol.ManagementOperationSetConfig, bs)
+}
+
+// manageRemote forwards a management operation to the device given in the
+// request and relays the JSON response. The remote device must have
+// allowed us to manage it.
+func (s *service) manageRemote(w http.ResponseWriter, r *http.Request, op protocol.ManagementOperation, body []byte) {
+	deviceID, err := protocol.DeviceIDFromString(r.URL.Query().Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if op == protocol.ManagementOperationSetConfig {
+		// Catch obviously broken configs before sending them off.
+		if _, err := config.ReadJSON(bytes.NewReader(body), deviceID); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), remoteManageTimeout)
+	defer cancel()
+	resp, err := s.model.ManageDevice(ctx, deviceID, op, body)
+	if errors.Is(err, model.ErrDeviceNotConnected) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if len(resp) == 0 {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(resp)
+}
+
 func (*service) restPing(w http.ResponseWriter, _ *http.Request) {
 	sendJSON(w, map[string]string{"ping": "pong"})
 }
@@ -724,14 +859,15 @@ func (*service) getSystemVersion(w http.ResponseWriter, _ *http.Request) {
 	})
 }
 
-func (*service) getSystemDebug(w http.ResponseWriter, _ *http.Request) {
+func (*service) getSystemLogLevels(w http.ResponseWriter, _ *http.Request) {
 	sendJSON(w, map[string]any{
 		"packages": slogutil.PackageDescrs(),
 		"levels":   slogutil.PackageLevels(),
+		"expires":  slogutil.PackageLevelExpiries(),
 	})
 }
 
-func (*service) postSystemDebug(w http.ResponseWriter, r *http.Request) {
+func (*service) postSystemLogLevels(w http.ResponseWriter, r *http.Request) {
 	var levelRequest map[string]slog.Level
 	if err := json.NewDecoder(r.Body).Decode(&levelRequest); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -742,6 +878,62 @@ func (*service) postSystemDebug(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// postSystemDebug sets the packages given in enable to the given level
+// (DEBUG by default) and returns the packages given in disable to the
+// default level. With a duration the enabled levels revert by themselves
+// once it has passed.
+func (*service) postSystemDebug(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+
+	level := slog.LevelDebug
+	if lv := qs.Get("level"); lv != "" {
+		if err := level.UnmarshalText([]byte(lv)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	var dur time.Duration
+	if ds := qs.Get("duration"); ds != "" {
+		var err error
+		dur, err = time.ParseDuration(ds)
+		if err != nil || dur <= 0 {
+			http.Error(w, "invalid duration", http.StatusBadRequest)
+			return
+		}
+	}
+
+	descrs := slogutil.PackageDescrs()
+	enable := splitPackages(qs.Get("enable"))
+	disable := splitPackages(qs.Get("disable"))
+	for _, pkg := range slices.Concat(enable, disable) {
+		if _, ok := descrs[pkg]; !ok {
+			http.Error(w, "unknown package "+pkg, http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, pkg := range enable {
+		if dur > 0 {
+			slogutil.SetPackageLevelFor(pkg, level, dur)
+		} else {
+			slogutil.SetPackageLevel(pkg, level)
+		}
+	}
+	for _, pkg := range disable {
+		slogutil.ResetPackageLevel(pkg)
+	}
+}
+
+func splitPackages(s string) []string {
+	var pkgs []string
+	for pkg := range strings.SplitSeq(s, ",") {
+		if pkg = strings.TrimSpace(pkg); pkg != "" {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs
+}
+
 func (s *service) getDBBrowse(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 	folder := qs.Get("folder")
@@ -906,6 +1098,49 @@ func (s *service) getDeviceStats(w http.ResponseWriter, _ *http.Request) {
 	sendJSON(w, stats)
 }
 
+// getFileActivity returns the files each device added, changed and
+// deleted per day, from the day given as YYYY-MM-DD on. Devices that aren't
+// configured (anymore) are listed by their short ID.
+func (s *service) getFileActivity(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	since := qs.Get("since")
+	if since != "" {
+		if _, err := time.Parse("2006-01-02", since); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	activity, err := s.model.FileActivity()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if device := qs.Get("device"); device != "" {
+		// Devices that aren't configured are known by their short ID.
+		days := activity[device]
+		if deviceID, err := protocol.DeviceIDFromString(device); err == nil {
+			device = deviceID.String()
+			days = activity[device]
+			if days == nil {
+				days = activity[deviceID.Short().String()]
+			}
+		} else if len(device) != protocol.ShortIDStringLength {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if days == nil {
+			days = []stats.DailyActivity{}
+		}
+		activity = map[string][]stats.DailyActivity{device: days}
+	}
+	for id, days := range activity {
+		activity[id] = slices.DeleteFunc(days, func(d stats.DailyActivity) bool {
+			return d.Day < since
+		})
+	}
+	sendJSON(w, activity)
+}
+
 func (s *service) getFolderStats(w http.ResponseWriter, _ *http.Request) {
 	stats, err := s.model.FolderStatistics()
 	if err != nil {
@@ -915,6 +1150,41 @@ func (s *service) getFolderStats(w http.ResponseWriter, _ *http.Request) {
 	sendJSON(w, stats)
 }
 
+func (s *service) getFolderSizeHistory(w http.ResponseWriter, r *http.Request) {
+	hist, err := s.model.FolderSizeHistory(r.URL.Query().Get("folder"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, hist)
+}
+
+func (s *service) getTransferStats(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	since, err := time.Parse(time.RFC3339, qs.Get("since"))
+	if err != nil {
+		l.Debugln(err)
+	}
+	hist := s.model.TransferHistory(since)
+	if device := qs.Get("device"); device != "" {
+		deviceID, err := protocol.DeviceIDFromString(device)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rates, ok := hist.Devices[deviceID]
+		if !ok {
+			http.Error(w, "unknown device", http.StatusNotFound)
+			return
+		}
+		hist.Devices = map[protocol.DeviceID][]model.TransferRate{deviceID: rates}
+	}
+	sendJSON(w, hist)
+}
+
 func (s *service) getDBFile(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 	folder := qs.Get("folder")
@@ -1066,6 +1336,9 @@ func (s *service) getSystemStatus(w http.ResponseWriter, _ *http.Request) {
 	res["startTime"] = ur.StartTime
 	res["guiAddressOverridden"] = s.cfg.GUI().IsOverridden()
 	res["guiAddressUsed"] = s.listenerAddr.String()
+	globalPause := s.cfg.Options().GlobalPause
+	globalPause.Paused = globalPause.Active(time.Now())
+	res["globalPause"] = globalPause
 
 	sendJSON(w, res)
 }
@@ -1097,6 +1370,31 @@ func (s *service) getSystemLog(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (s *service) getSystemWatches(w http.ResponseWriter, _ *http.Request) {
+	sendJSON(w, s.model.WatchReport())
+}
+
+func (s *service) getSystemScans(w http.ResponseWriter, _ *http.Request) {
+	sendJSON(w, s.model.ScanSchedule())
+}
+
+func (s *service) getSystemAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	since, err := time.Parse(time.RFC3339, q.Get("since"))
+	if err != nil {
+		l.Debugln(err)
+	}
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	entries, err := s.auditLog.since(since, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, map[string][]auditEntry{
+		"entries": entries,
+	})
+}
+
 func (s *service) getSystemLogTxt(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 	since, err := time.Parse(time.RFC3339, q.Get("since"))
@@ -1335,6 +1633,148 @@ func (s *service) postDBIgnores(w http.ResponseWriter, r *http.Request) {
 	s.getDBIgnores(w, r)
 }
 
+func (s *service) postDBIgnoresPreview(w http.ResponseWriter, r *http.Request) {
+	var data map[string][]string
+	if err := unmarshalTo(r.Body, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	files, err := s.model.PreviewIgnores(r.URL.Query().Get("folder"), data["ignore"])
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, files)
+}
+
+func (s *service) getFolderPullPreview(w http.ResponseWriter, r *http.Request) {
+	preview, err := s.model.PreviewPull(r.URL.Query().Get("folder"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, preview)
+}
+
+func (s *service) getDBPins(w http.ResponseWriter, r *http.Request) {
+	pinned, err := s.model.Pinned(r.URL.Query().Get("folder"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if pinned == nil {
+		pinned = []string{}
+	}
+	sendJSON(w, map[string][]string{"pinned": pinned})
+}
+
+func (s *service) postDBPins(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	pinned := true
+	if val := qs.Get("pinned"); val != "" {
+		var err error
+		if pinned, err = strconv.ParseBool(val); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	err := s.model.SetPinned(qs.Get("folder"), qs.Get("path"), pinned)
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.getDBPins(w, r)
+}
+
+func (s *service) getFolderApproval(w http.ResponseWriter, r *http.Request) {
+	pending, ok, err := s.model.PendingRemoteChanges(r.URL.Query().Get("folder"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, map[string]interface{}{
+		"pending": ok,
+		"changes": pending,
+	})
+}
+
+func (s *service) postFolderApproval(w http.ResponseWriter, r *http.Request) {
+	err := s.model.ApproveRemoteChanges(r.URL.Query().Get("folder"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.getFolderApproval(w, r)
+}
+
+func (s *service) getFolderDeleted(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	deleted, err := s.model.DeletedFiles(qs.Get("folder"), qs.Get("prefix"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, deleted)
+}
+
+func (s *service) postFolderUndelete(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	err := s.model.Undelete(r.Context(), qs.Get("folder"), qs.Get("file"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *service) getCaseConflicts(w http.ResponseWriter, r *http.Request) {
+	conflicts, err := s.model.CaseConflicts(r.URL.Query().Get("folder"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, conflicts)
+}
+
+func (s *service) postCaseConflicts(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	err := s.model.ResolveCaseConflict(qs.Get("folder"), qs.Get("file"), qs.Get("to"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (s *service) getIndexEvents(w http.ResponseWriter, r *http.Request) {
 	mask := s.getEventMask(r.URL.Query().Get("events"))
 	sub := s.getEventSub(mask)
@@ -1497,10 +1937,19 @@ func (s *service) makeDevicePauseHandler(paused bool) http.HandlerFunc {
 		qs := r.URL.Query()
 		deviceStr := qs.Get("device")
 
+		if paused && qs.Get("global") == "true" {
+			// A global pause stops all syncing and records why.
+			s.postSystemPauseAll(w, r)
+			return
+		}
+
 		var msg string
 		var status int
 		_, err := s.cfg.Modify(func(cfg *config.Configuration) {
 			if deviceStr == "" {
+				// Resuming everything ends a global pause, and resumes the
+				// devices paused one by one as well.
+				cfg.Options.GlobalPause = config.GlobalPause{}
 				for i := range cfg.Devices {
 					cfg.Devices[i].Paused = paused
 				}
@@ -1532,6 +1981,41 @@ func (s *service) makeDevicePauseHandler(paused bool) http.HandlerFunc {
 	}
 }
 
+func (s *service) postSystemPauseAll(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	var duration time.Duration
+	if ds := qs.Get("duration"); ds != "" {
+		var err error
+		duration, err = time.ParseDuration(ds)
+		if err != nil || duration < 0 {
+			http.Error(w, "invalid duration", http.StatusBadRequest)
+			return
+		}
+	}
+	originator := qs.Get("originator")
+	if originator == "" {
+		originator = config.PauseOriginatorUser
+		if hasAPIKeyHeader(r) {
+			originator = config.PauseOriginatorAPI
+		}
+	}
+	if err := s.model.PauseAll(qs.Get("reason"), originator, duration); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) postDBAdopt(w http.ResponseWriter, r *http.Request) {
+	adopted, err := s.model.Adopt(r.URL.Query().Get("folder"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, map[string]int{"adopted": adopted})
+}
+
 func (s *service) postDBScan(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 	folder := qs.Get("folder")
@@ -1584,7 +2068,19 @@ func (*service) getQR(w http.ResponseWriter, r *http.Request) {
 
 func (s *service) getFolderVersions(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
-	versions, err := s.model.GetFolderVersions(qs.Get("folder"))
+	var from, to time.Time
+	for param, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v := qs.Get(param); v != "" {
+			var err error
+			if *t, err = time.Parse(time.RFC3339, v); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	// Only the versions archived in the range [from, to).
+	versions, err := s.model.GetFolderVersionsBetween(qs.Get("folder"), from, to)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -1592,6 +2088,91 @@ func (s *service) getFolderVersions(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, versions)
 }
 
+// getVersionContent serves an archived version of a file, for previewing
+// it before restoring.
+func (s *service) getVersionContent(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	name, err := fs.Canonicalize(qs.Get("file"))
+	if err != nil || name == "" {
+		http.Error(w, "invalid file name", http.StatusBadRequest)
+		return
+	}
+	versionTime, err := time.Parse(time.RFC3339, qs.Get("time"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fd, err := s.model.OpenFolderVersion(qs.Get("folder"), name, versionTime)
+	if errors.Is(err, model.ErrFolderMissing) || fs.IsNotExist(err) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer fd.Close()
+	info, err := fd.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	serveFileContent(w, r, name, info.ModTime(), fd)
+}
+
+// getFolderContent serves the local copy of a file in the folder, as it
+// currently is on disk.
+func (s *service) getFolderContent(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	fcfg, ok := s.cfg.Folder(qs.Get("folder"))
+	if !ok {
+		http.Error(w, "no such folder", http.StatusNotFound)
+		return
+	}
+	if fcfg.Type == config.FolderTypeReceiveEncrypted {
+		http.Error(w, "folder contents are encrypted", http.StatusBadRequest)
+		return
+	}
+	name, err := fs.Canonicalize(qs.Get("file"))
+	if err != nil || name == "" || fs.IsInternal(name) {
+		http.Error(w, "invalid file name", http.StatusBadRequest)
+		return
+	}
+
+	fd, err := fcfg.Filesystem().Open(name)
+	if fs.IsNotExist(err) {
+		http.Error(w, "no such file", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer fd.Close()
+	info, err := fd.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !info.IsRegular() {
+		http.Error(w, "not a regular file", http.StatusBadRequest)
+		return
+	}
+
+	serveFileContent(w, r, name, info.ModTime(), fd)
+}
+
+// serveFileContent serves the contents of a user file as a download. It's
+// served from the GUI origin, so the browser must neither render it nor
+// guess at its type and run any scripts in it.
+func serveFileContent(w http.ResponseWriter, r *http.Request, name string, modTime time.Time, content io.ReadSeeker) {
+	base := filepath.Base(name)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": base}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	http.ServeContent(w, r, base, modTime, content)
+}
+
 func (s *service) postFolderVersionsRestore(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 
@@ -1617,6 +2198,238 @@ func (s *service) postFolderVersionsRestore(w http.ResponseWriter, r *http.Reque
 	sendJSON(w, errorStringMap(ferr))
 }
 
+func (s *service) postFolderSnapshotRestore(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	asOf, err := time.Parse(time.RFC3339, qs.Get("time"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if qs.Get("dest") == "" {
+		http.Error(w, "missing destination", http.StatusBadRequest)
+		return
+	}
+
+	ferr, err := s.model.RestoreFolderSnapshot(qs.Get("folder"), qs.Get("dir"), asOf, qs.Get("dest"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, errorStringMap(ferr))
+}
+
+func (s *service) getFolderEncryption(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	device, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rot, err := s.model.EncryptionRotation(qs.Get("folder"), device)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if isFolderNotFound(err) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	res := map[string]interface{}{
+		"rotating": rot.Rotating,
+	}
+	if rot.Rotating {
+		res["completion"] = rot.Completion.Map()
+	}
+	sendJSON(w, res)
+}
+
+// postFolderEncryption starts rotating the encryption password of the
+// folder for the untrusted device.
+func (s *service) postFolderEncryption(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	folder := qs.Get("folder")
+	device, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := unmarshalTo(r.Body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var rotateErr error
+	waiter, err := s.cfg.Modify(func(cfg *config.Configuration) {
+		fcfg, i, ok := cfg.Folder(folder)
+		if !ok {
+			rotateErr = model.ErrFolderMissing
+			return
+		}
+		if rotateErr = fcfg.RotateEncryptionPassword(device, req.Password); rotateErr == nil {
+			cfg.Folders[i] = fcfg
+		}
+	})
+	switch {
+	case isFolderNotFound(rotateErr):
+		http.Error(w, rotateErr.Error(), http.StatusNotFound)
+		return
+	case rotateErr != nil:
+		http.Error(w, rotateErr.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	waiter.Wait()
+	if err := s.cfg.Save(); err != nil {
+		slog.Error("Failed to save config", slogutil.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getSystemAuthTOTP returns whether a second factor is required for
+// logging in to the GUI, and a newly generated secret that can be enrolled.
+// The secret is not stored until it's enrolled.
+func (s *service) getSystemAuthTOTP(w http.ResponseWriter, _ *http.Request) {
+	gui := s.cfg.GUI()
+	secret := totp.NewSecret()
+	sendJSON(w, map[string]any{
+		"enabled": gui.TOTPEnabled(),
+		"secret":  secret,
+		"uri":     totp.URI(secret, "Syncthing", cmp.Or(gui.User, s.id.Short().String())),
+	})
+}
+
+// postSystemAuthTOTP enrolls a secret as the second factor for logging in
+// to the GUI, returning the recovery codes. They can't be retrieved later.
+// The body must contain a current code for the new secret, to show that
+// the authenticator app has it, and a current code or recovery code for
+// the existing second factor when replacing it.
+func (s *service) postSystemAuthTOTP(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.GUI().IsAuthEnabled() {
+		http.Error(w, "GUI authentication is not enabled", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Secret      string `json:"secret"`
+		Code        string `json:"code"`
+		CurrentCode string `json:"currentCode"`
+	}
+	if err := unmarshalTo(r.Body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := totp.Validate(req.Secret, req.Code, time.Now()); !ok {
+		http.Error(w, "Incorrect code for the new secret", http.StatusForbidden)
+		return
+	}
+	if !s.checkCurrentTOTPCode(req.CurrentCode) {
+		http.Error(w, "Incorrect current authentication code", http.StatusForbidden)
+		return
+	}
+
+	var recoveryCodes []string
+	var enrollErr error
+	waiter, err := s.cfg.Modify(func(cfg *config.Configuration) {
+		recoveryCodes, enrollErr = cfg.GUI.EnrollTOTP(req.Secret)
+	})
+	if enrollErr == nil {
+		enrollErr = err
+	}
+	if enrollErr != nil {
+		http.Error(w, enrollErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	waiter.Wait()
+	if err := s.cfg.Save(); err != nil {
+		slog.Error("Failed to save config", slogutil.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Enrolled two-factor authentication for the GUI")
+
+	sendJSON(w, map[string]any{
+		"recoveryCodes": recoveryCodes,
+	})
+}
+
+// deleteSystemAuthTOTP removes the second factor for logging in to the GUI.
+// The body must contain a current code or a recovery code.
+func (s *service) deleteSystemAuthTOTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := unmarshalTo(r.Body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.checkCurrentTOTPCode(req.Code) {
+		http.Error(w, "Incorrect current authentication code", http.StatusForbidden)
+		return
+	}
+
+	waiter, err := s.cfg.Modify(func(cfg *config.Configuration) {
+		cfg.GUI.ResetTOTP()
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	waiter.Wait()
+	if err := s.cfg.Save(); err != nil {
+		slog.Error("Failed to save config", slogutil.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Removed two-factor authentication for the GUI")
+}
+
+// checkCurrentTOTPCode returns true if no second factor is set up, or if
+// the code is currently valid for it or is one of the recovery codes. The
+// recovery code isn't used up, as the caller replaces or removes all of
+// them.
+func (s *service) checkCurrentTOTPCode(code string) bool {
+	gui := s.cfg.GUI()
+	if !gui.TOTPEnabled() {
+		return true
+	}
+	if _, ok := totp.Validate(gui.TOTPSecret, code, time.Now()); ok {
+		return true
+	}
+	return code != "" && gui.UseTOTPRecoveryCode(code)
+}
+
+// useTOTPRecoveryCode consumes the recovery code for logging in to the GUI,
+// returning whether it was valid.
+func (s *service) useTOTPRecoveryCode(code string) bool {
+	// Checking is slow, so don't hold up config changes unless the code
+	// is valid.
+	if gui := s.cfg.GUI(); !gui.UseTOTPRecoveryCode(code) {
+		return false
+	}
+	used := false
+	waiter, err := s.cfg.Modify(func(cfg *config.Configuration) {
+		used = cfg.GUI.UseTOTPRecoveryCode(code)
+	})
+	if err != nil || !used {
+		return false
+	}
+	waiter.Wait()
+	if err := s.cfg.Save(); err != nil {
+		slog.Error("Failed to save config", slogutil.Error(err))
+	}
+	return true
+}
+
 func (s *service) getFolderErrors(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 	folder := qs.Get("folder")
@@ -1627,6 +2440,7 @@ func (s *service) getFolderErrors(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	categories := model.FileErrorCategories(errors)
 
 	start := (page - 1) * perpage
 	if start >= len(errors) {
@@ -1639,10 +2453,11 @@ func (s *service) getFolderErrors(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sendJSON(w, map[string]interface{}{
-		"folder":  folder,
-		"errors":  errors,
-		"page":    page,
-		"perpage": perpage,
+		"folder":     folder,
+		"errors":     errors,
+		"categories": categories,
+		"page":       page,
+		"perpage":    perpage,
 	})
 }
 
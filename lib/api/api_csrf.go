@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/lib/events"
 )
 
 const (
@@ -25,6 +26,7 @@ type csrfManager struct {
 	apiKeyValidator apiKeyValidator
 	next            http.Handler
 	tokens          *tokenManager
+	evLogger        events.Logger
 }
 
 type apiKeyValidator interface {
@@ -34,13 +36,14 @@ type apiKeyValidator interface {
 // Check for CSRF token on /rest/ URLs. If a correct one is not given, reject
 // the request with 403. For / and /index.html, set a new CSRF cookie if none
 // is currently set.
-func newCsrfManager(unique string, prefix string, apiKeyValidator apiKeyValidator, next http.Handler, miscDB *db.Typed) *csrfManager {
+func newCsrfManager(unique string, prefix string, apiKeyValidator apiKeyValidator, next http.Handler, miscDB *db.Typed, evLogger events.Logger) *csrfManager {
 	m := &csrfManager{
 		unique:          unique,
 		prefix:          prefix,
 		apiKeyValidator: apiKeyValidator,
 		next:            next,
 		tokens:          newTokenManager("csrfTokens", miscDB, maxCSRFTokenLifetime, maxActiveCSRFTokens),
+		evLogger:        evLogger,
 	}
 	return m
 }
@@ -88,6 +91,12 @@ func (m *csrfManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Verify the CSRF token
 	token := r.Header.Get("X-CSRF-Token-" + m.unique)
 	if !m.tokens.Check(token) {
+		switch {
+		case hasAPIKeyHeader(r):
+			emitAPIAuthFailure("invalidAPIKey", r, m.evLogger)
+		case token != "":
+			emitAPIAuthFailure("invalidCSRFToken", r, m.evLogger)
+		}
 		http.Error(w, "CSRF Error", http.StatusForbidden)
 		return
 	}
@@ -95,6 +104,12 @@ func (m *csrfManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	m.next.ServeHTTP(w, r)
 }
 
+// hasAPIKeyHeader returns true if the request carries an API key, valid or
+// not.
+func hasAPIKeyHeader(r *http.Request) bool {
+	return r.Header.Get("X-API-Key") != "" || strings.HasPrefix(strings.ToLower(r.Header.Get("Authorization")), "bearer ")
+}
+
 func hasValidAPIKeyHeader(r *http.Request, validator apiKeyValidator) bool {
 	if key := r.Header.Get("X-API-Key"); validator.IsValidAPIKey(key) {
 		return true
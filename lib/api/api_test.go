@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/d4l3k/messagediff"
+	"github.com/julienschmidt/httprouter"
 	"github.com/thejerf/suture/v4"
 
 	"github.com/syncthing/syncthing/internal/db"
@@ -45,6 +46,7 @@ import (
 	"github.com/syncthing/syncthing/lib/rand"
 	"github.com/syncthing/syncthing/lib/svcutil"
 	"github.com/syncthing/syncthing/lib/tlsutil"
+	"github.com/syncthing/syncthing/lib/totp"
 	"github.com/syncthing/syncthing/lib/ur"
 )
 
@@ -279,6 +281,36 @@ func TestAPIServiceRequests(t *testing.T) {
 			Type:   "application/json",
 			Prefix: "null",
 		},
+		{
+			URL:    "/rest/folder/approval?folder=default",
+			Code:   200,
+			Type:   "application/json",
+			Prefix: "{",
+		},
+		{
+			URL:    "/rest/folder/deleted?folder=default",
+			Code:   200,
+			Type:   "application/json",
+			Prefix: "null",
+		},
+		{
+			URL:    "/rest/db/pins?folder=default",
+			Code:   200,
+			Type:   "application/json",
+			Prefix: "{",
+		},
+		{
+			URL:    "/rest/stats/folder/history?folder=default",
+			Code:   200,
+			Type:   "application/json",
+			Prefix: "{",
+		},
+		{
+			URL:    "/rest/stats/transfer",
+			Code:   200,
+			Type:   "application/json",
+			Prefix: "{",
+		},
 
 		// /rest/svc
 		{
@@ -350,6 +382,12 @@ func TestAPIServiceRequests(t *testing.T) {
 			Type:   "application/json",
 			Prefix: "{",
 		},
+		{
+			URL:    "/rest/system/health",
+			Code:   200,
+			Type:   "application/json",
+			Prefix: "{",
+		},
 		{
 			URL:    "/rest/system/version",
 			Code:   200,
@@ -362,6 +400,12 @@ func TestAPIServiceRequests(t *testing.T) {
 			Type:   "application/json",
 			Prefix: "{",
 		},
+		{
+			URL:    "/rest/system/debug",
+			Code:   200,
+			Type:   "application/json",
+			Prefix: "{",
+		},
 		{
 			URL:    "/rest/system/log?since=0",
 			Code:   200,
@@ -997,6 +1041,143 @@ func TestHtmlFormLogin(t *testing.T) {
 	})
 }
 
+func TestTOTPLogin(t *testing.T) {
+	t.Parallel()
+
+	secret := totp.NewSecret()
+	cfg := newMockedConfig()
+	cfg.GUIReturns(config.GUIConfiguration{
+		User:       "üser",
+		Password:   "$2a$10$IdIZTxTg/dCNuNEGlmLynOjqg4B1FvDKuIV5e0BB3pnWVHNb8.GSq", // bcrypt of "räksmörgås" in UTF-8
+		TOTPSecret: secret,
+	})
+	baseURL := startHTTP(t, cfg)
+
+	loginURL := baseURL + "/rest/noauth/auth/password"
+	resourceURL := baseURL + "/meta.js"
+	performLogin := func(code string) *http.Response {
+		t.Helper()
+		return httpPost(loginURL, map[string]string{"username": "üser", "password": "räksmörgås", "totpCode": code}, nil, t)
+	}
+
+	if resp := performLogin(""); resp.StatusCode != http.StatusUnauthorized || hasSessionCookie(resp.Cookies()) {
+		t.Errorf("Expected 401 without session for password without code, got %d", resp.StatusCode)
+	}
+	if resp := performLogin("000000x"); resp.StatusCode != http.StatusForbidden || hasSessionCookie(resp.Cookies()) {
+		t.Errorf("Expected 403 without session for incorrect code, got %d", resp.StatusCode)
+	}
+	if resp := httpGet(resourceURL, "üser", "räksmörgås", "", "", nil, t); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected basic auth to be rejected with 403, got %d", resp.StatusCode)
+	}
+
+	code, err := totp.Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := performLogin(code)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected 204 for correct code, got %d", resp.StatusCode)
+	}
+	if resp := httpGet(resourceURL, "", "", "", "", resp.Cookies(), t); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 with session, got %d", resp.StatusCode)
+	}
+	if resp := performLogin(code); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 for reused code, got %d", resp.StatusCode)
+	}
+}
+
+func TestTOTPEnrollment(t *testing.T) {
+	t.Parallel()
+
+	gui := config.GUIConfiguration{User: "user"}
+	if err := gui.SetPassword("pass"); err != nil {
+		t.Fatal(err)
+	}
+	w := config.Wrap(filepath.Join(t.TempDir(), "config.xml"), config.Configuration{GUI: gui}, protocol.LocalDeviceID, events.NoopLogger)
+	go w.Serve(t.Context())
+	svc := &service{id: protocol.LocalDeviceID, cfg: w}
+	c := &configMuxBuilder{Router: httprouter.New(), id: protocol.LocalDeviceID, cfg: w}
+	c.registerGUI("/rest/config/gui")
+
+	do := func(handler http.HandlerFunc, method string, body any, status int) *httptest.ResponseRecorder {
+		t.Helper()
+		bs, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(method, "/rest/system/auth/totp", bytes.NewReader(bs)))
+		if rec.Code != status {
+			t.Fatalf("%s: expected status %d, got %d: %s", method, status, rec.Code, rec.Body)
+		}
+		return rec
+	}
+	propose := func() string {
+		t.Helper()
+		var proposal struct {
+			Secret string `json:"secret"`
+		}
+		if err := json.Unmarshal(do(svc.getSystemAuthTOTP, http.MethodGet, nil, http.StatusOK).Body.Bytes(), &proposal); err != nil {
+			t.Fatal(err)
+		}
+		return proposal.Secret
+	}
+	currentCode := func(secret string) string {
+		t.Helper()
+		code, err := totp.Code(secret, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	secret := propose()
+	do(svc.postSystemAuthTOTP, http.MethodPost, map[string]string{"secret": secret, "code": "000000x"}, http.StatusForbidden)
+	if w.GUI().TOTPEnabled() {
+		t.Fatal("Expected no second factor without a correct code")
+	}
+	rec := do(svc.postSystemAuthTOTP, http.MethodPost, map[string]string{"secret": secret, "code": currentCode(secret)}, http.StatusOK)
+	var enrollment struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &enrollment); err != nil {
+		t.Fatal(err)
+	}
+	if w.GUI().TOTPSecret != secret || len(enrollment.RecoveryCodes) == 0 {
+		t.Fatal("Expected the secret to be enrolled, with recovery codes")
+	}
+
+	// The secret is not shown, and can't be changed, through the config.
+	rec = httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rest/config/gui", nil))
+	var guiMap map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &guiMap); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(rec.Body.Bytes(), []byte(secret)) || guiMap["totpEnabled"] != true {
+		t.Errorf("Unexpected GUI config %s", rec.Body)
+	}
+	guiMap["totpSecret"] = totp.NewSecret()
+	guiMap["totpRecoveryCodes"] = []string{}
+	bs, err := json.Marshal(guiMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/rest/config/gui", bytes.NewReader(bs)))
+	if gui := w.GUI(); gui.TOTPSecret != secret || len(gui.TOTPRecoveryCodes) != len(enrollment.RecoveryCodes) {
+		t.Error("Expected the second factor to be unchanged by a config update")
+	}
+
+	// Replacing or removing it requires a current code.
+	newSecret := propose()
+	do(svc.postSystemAuthTOTP, http.MethodPost, map[string]string{"secret": newSecret, "code": currentCode(newSecret)}, http.StatusForbidden)
+	do(svc.deleteSystemAuthTOTP, http.MethodDelete, map[string]string{"code": ""}, http.StatusForbidden)
+	do(svc.deleteSystemAuthTOTP, http.MethodDelete, map[string]string{"code": enrollment.RecoveryCodes[0]}, http.StatusOK)
+	if w.GUI().TOTPEnabled() {
+		t.Error("Expected the second factor to be removed")
+	}
+}
+
 func TestApiCache(t *testing.T) {
 	t.Parallel()
 
@@ -1646,6 +1827,73 @@ func TestBrowse(t *testing.T) {
 	}
 }
 
+func TestVersionContentHeaders(t *testing.T) {
+	t.Parallel()
+
+	ffs := fs.NewFilesystem(fs.FilesystemTypeFake, rand.String(32)+"?nostfolder=true")
+	_ = fs.WriteFile(ffs, "page.html", []byte("<script>alert(1)</script>"), 0o644)
+
+	m := new(modelmocks.Model)
+	m.OpenFolderVersionStub = func(_, name string, _ time.Time) (fs.File, error) {
+		return ffs.Open(name)
+	}
+	svc := &service{model: m}
+
+	// User content is only ever downloaded, never rendered on the GUI
+	// origin.
+	rec := httptest.NewRecorder()
+	svc.getVersionContent(rec, httptest.NewRequest(http.MethodGet, "/rest/folder/versions/content?folder=default&file=page.html&time=2026-01-01T00:00:00Z", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	for header, exp := range map[string]string{
+		"Content-Disposition":     `attachment; filename=page.html`,
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "sandbox",
+	} {
+		if got := rec.Header().Get(header); got != exp {
+			t.Errorf("expected %s %q, got %q", header, exp, got)
+		}
+	}
+}
+
+func TestPauseAllDevices(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New(protocol.LocalDeviceID)
+	cfg.Devices = append(cfg.Devices, config.DeviceConfiguration{DeviceID: dev1})
+	w := config.Wrap("/dev/null", cfg, protocol.LocalDeviceID, events.NoopLogger)
+	go w.Serve(t.Context())
+	m := new(modelmocks.Model)
+	svc := &service{cfg: w, model: m}
+
+	// Without a device, all devices are paused, as ever.
+	rec := httptest.NewRecorder()
+	svc.makeDevicePauseHandler(true)(rec, httptest.NewRequest(http.MethodPost, "/rest/system/pause", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if dev, _ := w.Device(dev1); !dev.Paused {
+		t.Error("expected the device to be paused")
+	}
+	if m.PauseAllCallCount() != 0 {
+		t.Error("expected no global pause")
+	}
+
+	// A global pause is asked for explicitly.
+	rec = httptest.NewRecorder()
+	svc.makeDevicePauseHandler(true)(rec, httptest.NewRequest(http.MethodPost, "/rest/system/pause?global=true&reason=battery", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if m.PauseAllCallCount() != 1 {
+		t.Fatal("expected a global pause")
+	}
+	if reason, _, _ := m.PauseAllArgsForCall(0); reason != "battery" {
+		t.Errorf("expected reason %q, got %q", "battery", reason)
+	}
+}
+
 func TestPrefixMatch(t *testing.T) {
 	t.Parallel()
 
@@ -1820,6 +2068,61 @@ func TestConfigChanges(t *testing.T) {
 	}
 }
 
+func TestFolderTemplates(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New(protocol.LocalDeviceID)
+	cfg.Defaults.Folder.RescanIntervalS = 600
+	w := config.Wrap(filepath.Join(t.TempDir(), "config.xml"), cfg, protocol.LocalDeviceID, events.NoopLogger)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go w.Serve(ctx)
+
+	m := new(modelmocks.Model)
+	c := &configMuxBuilder{Router: httprouter.New(), id: protocol.LocalDeviceID, cfg: w, model: m}
+	c.registerFolders("/rest/config/folders")
+	c.registerFolderTemplate("/rest/config/defaults/templates/:name")
+
+	do := func(method, path, body string, status int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		c.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		if rec.Code != status {
+			t.Fatalf("%s %s: expected status %d, got %d: %s", method, path, status, rec.Code, rec.Body)
+		}
+	}
+
+	do(http.MethodPut, "/rest/config/defaults/templates/photos", `{"folder": {"order": "newestFirst"}, "ignores": {"lines": ["*.tmp"]}}`, http.StatusOK)
+	tmpl, ok := w.FolderTemplate("photos")
+	if !ok {
+		t.Fatal("template missing")
+	}
+	if tmpl.Folder.Order != config.PullOrderNewestFirst || tmpl.Folder.RescanIntervalS != 600 {
+		t.Errorf("template should be based on the default folder: %+v", tmpl.Folder)
+	}
+
+	do(http.MethodPost, "/rest/config/folders?template=photos", `{"id": "pics", "path": "`+filepath.ToSlash(t.TempDir())+`", "rescanIntervalS": 60}`, http.StatusOK)
+	folder, ok := w.Folder("pics")
+	if !ok {
+		t.Fatal("folder missing")
+	}
+	if folder.Order != config.PullOrderNewestFirst || folder.RescanIntervalS != 60 {
+		t.Errorf("folder should be based on the template: %+v", folder)
+	}
+	if m.SetIgnoresCallCount() != 1 {
+		t.Fatalf("expected ignores to be set once, got %d", m.SetIgnoresCallCount())
+	}
+	if id, lines := m.SetIgnoresArgsForCall(0); id != "pics" || !slices.Equal(lines, []string{"*.tmp"}) {
+		t.Errorf("unexpected ignores %q for %q", lines, id)
+	}
+
+	do(http.MethodPost, "/rest/config/folders?template=nope", `{"id": "other"}`, http.StatusNotFound)
+	do(http.MethodDelete, "/rest/config/defaults/templates/photos", "", http.StatusOK)
+	if _, ok := w.FolderTemplate("photos"); ok {
+		t.Error("template should have been deleted")
+	}
+}
+
 func TestSanitizedHostname(t *testing.T) {
 	cases := []struct {
 		in, out string
@@ -1861,3 +2164,68 @@ func runningInContainer() bool {
 	}
 	return false
 }
+
+func TestFolderContent(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Configuration{
+		Folders: []config.FolderConfiguration{{ID: "default", Path: dir, FilesystemType: config.FilesystemTypeBasic}},
+	}
+	svc := &service{cfg: config.Wrap("/dev/null", cfg, protocol.LocalDeviceID, events.NoopLogger)}
+
+	cases := []struct {
+		query string
+		code  int
+	}{
+		{"folder=default&file=file.txt", http.StatusOK},
+		{"folder=default&file=sub", http.StatusBadRequest},
+		{"folder=default&file=.stfolder", http.StatusBadRequest},
+		{"folder=default&file=missing", http.StatusNotFound},
+		{"folder=default&file=../escape", http.StatusBadRequest},
+		{"folder=other&file=file.txt", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		svc.getFolderContent(rec, httptest.NewRequest(http.MethodGet, "/rest/folder/content?"+tc.query, nil))
+		if rec.Code != tc.code {
+			t.Errorf("%s: expected %d, got %d", tc.query, tc.code, rec.Code)
+		}
+		if tc.code == http.StatusOK && rec.Body.String() != "hello" {
+			t.Errorf("%s: unexpected body %q", tc.query, rec.Body.String())
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	t.Parallel()
+
+	cfg := newMockedConfig()
+	cfg.GUIReturns(config.GUIConfiguration{
+		RawAddress: "127.0.0.1:0",
+		APIKey:     testAPIKey,
+		ReadOnly:   true,
+	})
+	baseURL := startHTTP(t, cfg)
+
+	resp := httpRequest(http.MethodGet, baseURL+"/rest/system/version", nil, "", "", testAPIKey, "", "", "", nil, t)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Error("expected reading to be allowed, got", resp.Status)
+	}
+
+	resp = httpRequest(http.MethodPost, baseURL+"/rest/system/pause", nil, "", "", testAPIKey, "", "", "", nil, t)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Error("expected changing to be refused, got", resp.Status)
+	}
+	if cfg.ModifyCallCount() != 0 {
+		t.Error("expected the config not to be modified")
+	}
+}
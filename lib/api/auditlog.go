@@ -0,0 +1,390 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+const (
+	auditKeyPrefix = "apiaudit/"
+	// The number of entries kept; the oldest ones are removed beyond that.
+	maxAuditEntries = 10000
+)
+
+// An auditEntry records a single mutating API request.
+type auditEntry struct {
+	Time       time.Time `json:"time"`
+	Principal  string    `json:"principal"`
+	RemoteAddr string    `json:"remoteAddr"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Query      string    `json:"query,omitempty"`
+	Status     int       `json:"status"`
+	Diff       []string  `json:"diff,omitempty"`
+}
+
+// The apiAuditLog is an append-only record of mutating API requests, kept
+// in the database. Entries are never modified, and only removed when there
+// are more than maxAuditEntries.
+type apiAuditLog struct {
+	kv  *db.Typed
+	mut sync.Mutex
+	// last is the key of the last written entry, to keep keys strictly
+	// increasing when the clock doesn't move between requests.
+	last int64
+	// keys are the keys of the entries in the database, in order, so that
+	// entries can be found without reading the ones before them. Nil
+	// before they have been loaded.
+	keys       []int64
+	maxEntries int
+}
+
+func newAPIAuditLog(kv *db.Typed) *apiAuditLog {
+	return &apiAuditLog{kv: kv, maxEntries: maxAuditEntries}
+}
+
+func (a *apiAuditLog) record(e auditEntry) {
+	bs, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	if err := a.loadKeysLocked(); err != nil {
+		slog.Warn("Failed to load API audit log", slogutil.Error(err))
+		return
+	}
+	key := e.Time.UnixNano()
+	if key <= a.last {
+		key = a.last + 1
+	}
+	if err := a.kv.PutBytes(auditKey(key), bs); err != nil {
+		slog.Warn("Failed to write API audit log entry", slogutil.Error(err))
+		return
+	}
+	a.last = key
+	a.keys = append(a.keys, key)
+	if err := a.trimLocked(); err != nil {
+		slog.Warn("Failed to trim API audit log", slogutil.Error(err))
+	}
+}
+
+func auditKey(nanos int64) string {
+	return fmt.Sprintf("%s%020d", auditKeyPrefix, nanos)
+}
+
+// loadKeysLocked reads the keys of the entries in the database, once.
+func (a *apiAuditLog) loadKeysLocked() error {
+	if a.keys != nil {
+		return nil
+	}
+	keys := make([]int64, 0, a.maxEntries+1)
+	it, errFn := a.kv.PrefixBytes(auditKeyPrefix)
+	for kv := range it {
+		key, err := strconv.ParseInt(strings.TrimPrefix(kv.Key, auditKeyPrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if err := errFn(); err != nil {
+		return err
+	}
+	a.keys = keys
+	if len(keys) > 0 {
+		a.last = max(a.last, keys[len(keys)-1])
+	}
+	return nil
+}
+
+// trimLocked removes the oldest entries beyond the maximum.
+func (a *apiAuditLog) trimLocked() error {
+	for len(a.keys) > a.maxEntries {
+		if err := a.kv.Delete(auditKey(a.keys[0])); err != nil {
+			return err
+		}
+		a.keys = a.keys[1:]
+	}
+	return nil
+}
+
+// since returns up to limit entries newer than the given time, oldest
+// first. A limit of zero or less means no limit. Entries are keyed by
+// their time, so the first one is found by a binary search over the keys,
+// and only the entries returned are read.
+func (a *apiAuditLog) since(t time.Time, limit int) ([]auditEntry, error) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	if err := a.loadKeysLocked(); err != nil {
+		return nil, err
+	}
+
+	keys := a.keys
+	if !t.IsZero() {
+		i, found := slices.BinarySearch(keys, t.UnixNano())
+		if found {
+			i++
+		}
+		keys = keys[i:]
+	}
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	entries := make([]auditEntry, 0, len(keys))
+	for _, key := range keys {
+		bs, ok, err := a.kv.Bytes(auditKey(key))
+		if err != nil {
+			return nil, err
+		}
+		var e auditEntry
+		if !ok || json.Unmarshal(bs, &e) != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// auditMiddleware records every mutating request to the REST API, along
+// with who made it and how it changed the configuration. Successful
+// requests that changed the configuration are also added to the config
+// history. It must sit behind the authentication middleware, so that only
+// authenticated requests are seen.
+func auditMiddleware(log *apiAuditLog, history *configHistory, cfg config.Wrapper, cookieName string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutatingRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		before := cfg.RawCopy()
+		sw := &statusResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		after := cfg.RawCopy()
+		now := time.Now()
+		principal := requestPrincipal(r, before.GUI, cookieName)
+		diff := configDiff(before, after)
+		remoteAddr, _ := remoteAddress(r)
+		log.record(auditEntry{
+			Time:       now,
+			Principal:  principal,
+			RemoteAddr: remoteAddr,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Query:      r.URL.RawQuery,
+			Status:     sw.status,
+			Diff:       diff,
+		})
+
+		if len(diff) > 0 && sw.status < http.StatusMultipleChoices {
+			err := history.record(before, after, configVersion{
+				Time:      now,
+				Principal: principal,
+				Client:    requestClient(r, before.GUI),
+				Method:    r.Method,
+				Path:      r.URL.Path,
+			})
+			if err != nil {
+				slog.Warn("Failed to record config history", slogutil.Error(err))
+			}
+		}
+	})
+}
+
+func isMutatingRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return !strings.HasPrefix(r.URL.Path, "/rest/noauth/")
+}
+
+// requestPrincipal describes who made the request, without revealing any
+// credentials.
+func requestPrincipal(r *http.Request, guiCfg config.GUIConfiguration, cookieName string) string {
+	if hasValidAPIKeyHeader(r, guiCfg) {
+		return "api-key"
+	}
+	if username, _, ok := r.BasicAuth(); ok {
+		return "user:" + username
+	}
+	if cookie, err := r.Cookie(cookieName); err == nil && cookie.Value != "" {
+		hash := sha256.Sum256([]byte(cookie.Value))
+		return "session:" + hex.EncodeToString(hash[:4])
+	}
+	return "anonymous"
+}
+
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// configDiff returns a human readable list of the differences between two
+// configurations, one line per changed value. Secrets are redacted.
+func configDiff(from, to config.Configuration) []string {
+	var a, b any
+	if bs, err := json.Marshal(from); err == nil {
+		_ = json.Unmarshal(bs, &a)
+	}
+	if bs, err := json.Marshal(to); err == nil {
+		_ = json.Unmarshal(bs, &b)
+	}
+	var diff []string
+	diffValues("", a, b, &diff)
+	slices.Sort(diff)
+	return diff
+}
+
+var redactedConfigKeys = []string{"password", "apiKey", "bindPassword", "encryptionPassword", "previousEncryptionPassword", "totpSecret", "totpRecoveryCodes"}
+
+// redactSecrets returns a copy of the value with everything under a key in
+// redactedConfigKeys replaced, at any depth.
+func redactSecrets(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, e := range v {
+			if slices.Contains(redactedConfigKeys, k) && !isEmptyValue(e) && e != "" {
+				res[k] = "REDACTED"
+			} else {
+				res[k] = redactSecrets(e)
+			}
+		}
+		return res
+	case []any:
+		res := make([]any, len(v))
+		for i, e := range v {
+			res[i] = redactSecrets(e)
+		}
+		return res
+	}
+	return v
+}
+
+func diffValues(path string, a, b any, diff *[]string) {
+	switch av := a.(type) {
+	case map[string]any:
+		if bv, ok := b.(map[string]any); ok {
+			for k := range av {
+				diffValues(joinPath(path, k), av[k], bv[k], diff)
+			}
+			for k := range bv {
+				if _, ok := av[k]; !ok {
+					diffValues(joinPath(path, k), nil, bv[k], diff)
+				}
+			}
+			return
+		}
+	case []any:
+		if bv, ok := b.([]any); ok {
+			am, aok := keyedElements(av)
+			bm, bok := keyedElements(bv)
+			if aok && bok {
+				for k := range am {
+					diffValues(path+"["+k+"]", am[k], bm[k], diff)
+				}
+				for k := range bm {
+					if _, ok := am[k]; !ok {
+						diffValues(path+"["+k+"]", nil, bm[k], diff)
+					}
+				}
+				return
+			}
+		}
+	}
+
+	if isEmptyValue(a) && isEmptyValue(b) {
+		// null and empty lists or objects mean the same thing in the
+		// config
+		return
+	}
+	as, _ := json.Marshal(a)
+	bs, _ := json.Marshal(b)
+	if string(as) == string(bs) {
+		return
+	}
+	if slices.Contains(redactedConfigKeys, path[strings.LastIndex(path, ".")+1:]) {
+		*diff = append(*diff, path+": changed")
+		return
+	}
+	// Objects and lists added, removed or compared as a whole may contain
+	// secrets further down.
+	as, _ = json.Marshal(redactSecrets(a))
+	bs, _ = json.Marshal(redactSecrets(b))
+	*diff = append(*diff, fmt.Sprintf("%s: %s -> %s", path, as, bs))
+}
+
+// keyedElements maps a list of objects by their "id" or "deviceID"
+// attribute, so that folders and devices are compared by identity rather
+// than position.
+func keyedElements(list []any) (map[string]any, bool) {
+	res := make(map[string]any, len(list))
+	for _, e := range list {
+		m, ok := e.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		id, ok := m["id"].(string)
+		if !ok {
+			id, ok = m["deviceID"].(string)
+		}
+		if !ok {
+			return nil, false
+		}
+		res[id] = e
+	}
+	return res, true
+}
+
+func isEmptyValue(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,159 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/db/sqlite"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestConfigDiff(t *testing.T) {
+	t.Parallel()
+
+	from := config.New(protocol.LocalDeviceID)
+	from.Folders = []config.FolderConfiguration{{ID: "a", Label: "A"}, {ID: "b", Label: "B"}}
+	from.GUI.APIKey = "secret"
+	to := from.Copy()
+	// Reordering the folders must not show up as a change
+	to.Folders = []config.FolderConfiguration{to.Folders[1], to.Folders[0]}
+	to.Folders[0].Label = "BB"
+	to.Options.MaxSendKbps = 10
+	to.GUI.APIKey = "other"
+
+	diff := configDiff(from, to)
+	exp := []string{
+		`folders[b].label: "B" -> "BB"`,
+		`gui.apiKey: changed`,
+		`options.maxSendKbps: 0 -> 10`,
+	}
+	if !slices.Equal(diff, exp) {
+		t.Errorf("unexpected diff:\n%q\nexpected:\n%q", diff, exp)
+	}
+}
+
+func TestConfigDiffRedactsNested(t *testing.T) {
+	t.Parallel()
+
+	from := config.New(protocol.LocalDeviceID)
+	to := from.Copy()
+	to.Folders = []config.FolderConfiguration{{
+		ID:      "a",
+		Devices: []config.FolderDeviceConfiguration{{DeviceID: protocol.LocalDeviceID, EncryptionPassword: "hunter2"}},
+	}}
+
+	for _, line := range configDiff(from, to) {
+		if strings.Contains(line, "hunter2") {
+			t.Errorf("secret in diff: %s", line)
+		}
+	}
+}
+
+func TestAuditLogSinceAndTrim(t *testing.T) {
+	t.Parallel()
+
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sdb.Close()
+	})
+	log := newAPIAuditLog(db.NewMiscDB(sdb))
+	log.maxEntries = 5
+
+	start := time.Unix(1700000000, 0)
+	for i := range 8 {
+		log.record(auditEntry{Time: start.Add(time.Duration(i) * time.Second), Status: i})
+	}
+
+	entries, err := log.since(time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 || entries[0].Status != 3 {
+		t.Fatalf("expected the last five entries, got %+v", entries)
+	}
+
+	entries, err = log.since(start.Add(4*time.Second), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Status != 5 || entries[1].Status != 6 {
+		t.Errorf("expected entries 5 and 6, got %+v", entries)
+	}
+
+	// The entries are found again after a restart, and trimmed as before.
+	log = newAPIAuditLog(db.NewMiscDB(sdb))
+	log.maxEntries = 5
+	log.record(auditEntry{Time: start.Add(8 * time.Second), Status: 8})
+	entries, err = log.since(start.Add(5500*time.Millisecond), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].Status != 6 || entries[2].Status != 8 {
+		t.Errorf("expected entries 6 to 8, got %+v", entries)
+	}
+	if entries, err := log.since(time.Time{}, 0); err != nil || len(entries) != 5 || entries[0].Status != 4 {
+		t.Errorf("expected the last five entries, got %+v, %v", entries, err)
+	}
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	t.Parallel()
+
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sdb.Close()
+	})
+	kv := db.NewMiscDB(sdb)
+	log := newAPIAuditLog(kv)
+
+	cfg := config.Wrap("", config.New(protocol.LocalDeviceID), protocol.LocalDeviceID, nil)
+	handler := auditMiddleware(log, newConfigHistory(kv), cfg, "sessionid-test", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	start := time.Now().Add(-time.Second)
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/rest/system/status", nil),
+		httptest.NewRequest(http.MethodPost, "/rest/noauth/auth/password", nil),
+		httptest.NewRequest(http.MethodPost, "/rest/system/pause?device=foo", nil),
+	} {
+		req.SetBasicAuth("jb", "hunter2")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	entries, err := log.since(start, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Path != "/rest/system/pause" || e.Query != "device=foo" || e.Method != http.MethodPost {
+		t.Errorf("unexpected request in entry: %+v", e)
+	}
+	if e.Principal != "user:jb" {
+		t.Errorf("unexpected principal %q", e.Principal)
+	}
+	if e.Status != http.StatusTeapot {
+		t.Errorf("unexpected status %d", e.Status)
+	}
+}
@@ -11,11 +11,13 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 
 	"github.com/julienschmidt/httprouter"
 
 	"github.com/syncthing/syncthing/internal/slogutil"
 	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/model"
 	"github.com/syncthing/syncthing/lib/protocol"
 	"github.com/syncthing/syncthing/lib/structutil"
 )
@@ -23,8 +25,9 @@ import (
 type configMuxBuilder struct {
 	*httprouter.Router
 
-	id  protocol.DeviceID
-	cfg config.Wrapper
+	id    protocol.DeviceID
+	cfg   config.Wrapper
+	model model.Model
 }
 
 func (c *configMuxBuilder) registerConfig(path string) {
@@ -90,6 +93,10 @@ func (c *configMuxBuilder) registerFolders(path string) {
 	})
 
 	c.HandlerFunc(http.MethodPost, path, func(w http.ResponseWriter, r *http.Request) {
+		if name := r.URL.Query().Get("template"); name != "" {
+			c.adjustFolderFromTemplate(w, r, name)
+			return
+		}
 		c.adjustFolder(w, r, c.cfg.DefaultFolder(), false)
 	})
 }
@@ -140,6 +147,10 @@ func (c *configMuxBuilder) registerFolder(path string) {
 	})
 
 	c.Handle(http.MethodPut, path, func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if name := r.URL.Query().Get("template"); name != "" {
+			c.adjustFolderFromTemplate(w, r, name)
+			return
+		}
 		c.adjustFolder(w, r, c.cfg.DefaultFolder(), false)
 	})
 
@@ -208,6 +219,40 @@ func (c *configMuxBuilder) registerDevice(path string) {
 	})
 }
 
+// registerDeviceMerge replaces the device by the one given in the into
+// parameter, which it became after being set up anew with another identity.
+func (c *configMuxBuilder) registerDeviceMerge(path string) {
+	c.Handle(http.MethodPost, path, func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		from, err := protocol.DeviceIDFromString(p.ByName("id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		to, err := protocol.DeviceIDFromString(r.URL.Query().Get("into"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if from == to || from == c.id || to == c.id {
+			http.Error(w, "Cannot merge a device into itself or with this device", http.StatusBadRequest)
+			return
+		}
+		merged := false
+		waiter, err := c.cfg.Modify(func(cfg *config.Configuration) {
+			merged = cfg.MergeDevice(from, to)
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !merged {
+			http.Error(w, "No device with given ID", http.StatusNotFound)
+			return
+		}
+		c.finish(w, waiter)
+	})
+}
+
 func (c *configMuxBuilder) registerDefaultFolder(path string) {
 	c.HandlerFunc(http.MethodGet, path, func(w http.ResponseWriter, _ *http.Request) {
 		sendJSON(w, c.cfg.DefaultFolder())
@@ -262,6 +307,75 @@ func (c *configMuxBuilder) registerDefaultIgnores(path string) {
 	})
 }
 
+func (c *configMuxBuilder) registerFolderTemplates(path string) {
+	c.HandlerFunc(http.MethodGet, path, func(w http.ResponseWriter, _ *http.Request) {
+		sendJSON(w, c.cfg.RawCopy().Defaults.FolderTemplates)
+	})
+
+	c.HandlerFunc(http.MethodPut, path, func(w http.ResponseWriter, r *http.Request) {
+		data, err := unmarshalToRawMessages(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		templates := make([]config.FolderTemplate, len(data))
+		defaultFolder := c.cfg.DefaultFolder()
+		for i, bs := range data {
+			templates[i].Folder = defaultFolder.Copy()
+			if err := unmarshalFolderTemplate(bs, &templates[i]); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		waiter, err := c.cfg.Modify(func(cfg *config.Configuration) {
+			cfg.Defaults.FolderTemplates = templates
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.finish(w, waiter)
+	})
+}
+
+func (c *configMuxBuilder) registerFolderTemplate(path string) {
+	c.Handle(http.MethodGet, path, func(w http.ResponseWriter, _ *http.Request, p httprouter.Params) {
+		tmpl, ok := c.cfg.FolderTemplate(p.ByName("name"))
+		if !ok {
+			http.Error(w, "No folder template with given name", http.StatusNotFound)
+			return
+		}
+		sendJSON(w, tmpl)
+	})
+
+	c.Handle(http.MethodPut, path, func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		c.adjustFolderTemplate(w, r, p.ByName("name"), config.FolderTemplate{Folder: c.cfg.DefaultFolder()})
+	})
+
+	c.Handle(http.MethodPatch, path, func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		tmpl, ok := c.cfg.FolderTemplate(p.ByName("name"))
+		if !ok {
+			http.Error(w, "No folder template with given name", http.StatusNotFound)
+			return
+		}
+		c.adjustFolderTemplate(w, r, tmpl.Name, tmpl)
+	})
+
+	c.Handle(http.MethodDelete, path, func(w http.ResponseWriter, _ *http.Request, p httprouter.Params) {
+		name := p.ByName("name")
+		waiter, err := c.cfg.Modify(func(cfg *config.Configuration) {
+			if _, i, ok := cfg.FolderTemplate(name); ok {
+				cfg.Defaults.FolderTemplates = slices.Delete(cfg.Defaults.FolderTemplates, i, i+1)
+			}
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.finish(w, waiter)
+	})
+}
+
 func (c *configMuxBuilder) registerOptions(path string) {
 	c.HandlerFunc(http.MethodGet, path, func(w http.ResponseWriter, _ *http.Request) {
 		sendJSON(w, c.cfg.Options())
@@ -356,6 +470,66 @@ func (c *configMuxBuilder) adjustFolder(w http.ResponseWriter, r *http.Request,
 	c.finish(w, waiter)
 }
 
+// adjustFolderFromTemplate adds or replaces a folder, starting out from
+// the named template instead of the default folder. A new folder also gets
+// the template's ignore patterns.
+func (c *configMuxBuilder) adjustFolderFromTemplate(w http.ResponseWriter, r *http.Request, name string) {
+	tmpl, ok := c.cfg.FolderTemplate(name)
+	if !ok {
+		http.Error(w, "No folder template with given name", http.StatusNotFound)
+		return
+	}
+	bs, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	folder := tmpl.Folder.Copy()
+	if err := config.UnmarshalFolderOnto(bs, &folder); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_, exists := c.cfg.Folder(folder.ID)
+	waiter, err := c.cfg.Modify(func(cfg *config.Configuration) {
+		cfg.SetFolder(folder)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.finish(w, waiter)
+
+	if !exists && len(tmpl.Ignores.Lines) > 0 {
+		if err := c.model.SetIgnores(folder.ID, tmpl.Ignores.Lines); err != nil {
+			slog.Warn("Failed to set ignore patterns from folder template", folder.LogAttr(), "template", name, slogutil.Error(err))
+		}
+	}
+}
+
+func (c *configMuxBuilder) adjustFolderTemplate(w http.ResponseWriter, r *http.Request, name string, tmpl config.FolderTemplate) {
+	bs, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := unmarshalFolderTemplate(bs, &tmpl); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// The name in the path wins
+	tmpl.Name = name
+	waiter, err := c.cfg.Modify(func(cfg *config.Configuration) {
+		cfg.SetFolderTemplate(tmpl)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.finish(w, waiter)
+}
+
 func (c *configMuxBuilder) adjustDevice(w http.ResponseWriter, r *http.Request, device config.DeviceConfiguration, defaults bool) {
 	if err := unmarshalTo(r.Body, &device); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -416,6 +590,10 @@ func (c *configMuxBuilder) adjustGUI(w http.ResponseWriter, r *http.Request, gui
 }
 
 func (c *configMuxBuilder) postAdjustGui(from *config.GUIConfiguration, to *config.GUIConfiguration) error {
+	// The second factor isn't part of the JSON config, and is only changed
+	// through the dedicated endpoints.
+	to.TOTPSecret = from.TOTPSecret
+	to.TOTPRecoveryCodes = from.TOTPRecoveryCodes
 	if to.Password != from.Password {
 		if err := to.SetPassword(to.Password); err != nil {
 			slog.Error("Failed to hash password", slogutil.Error(err))
@@ -450,6 +628,28 @@ func unmarshalTo(body io.ReadCloser, to interface{}) error {
 	return json.Unmarshal(bs, to)
 }
 
+// unmarshalFolderTemplate decodes a template on top of the given one.
+func unmarshalFolderTemplate(bs []byte, tmpl *config.FolderTemplate) error {
+	var raw struct {
+		Name    *string
+		Folder  json.RawMessage
+		Ignores *config.Ignores
+	}
+	if err := json.Unmarshal(bs, &raw); err != nil {
+		return err
+	}
+	if raw.Name != nil {
+		tmpl.Name = *raw.Name
+	}
+	if raw.Ignores != nil {
+		tmpl.Ignores = *raw.Ignores
+	}
+	if len(raw.Folder) > 0 {
+		return config.UnmarshalFolderOnto(raw.Folder, &tmpl.Folder)
+	}
+	return nil
+}
+
 func unmarshalToRawMessages(body io.ReadCloser) ([]json.RawMessage, error) {
 	var data []json.RawMessage
 	err := unmarshalTo(body, &data)
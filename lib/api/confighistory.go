@@ -0,0 +1,258 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	configHistoryKeyPrefix = "confighistory/"
+	maxConfigHistory       = 50
+
+	// cliUserAgent prefixes the User-Agent sent by `syncthing cli`.
+	cliUserAgent = "syncthing-cli"
+)
+
+var errNoSuchConfigVersion = errors.New("no such config version")
+
+// A configVersion is a config as it was after a change through the API.
+type configVersion struct {
+	Version   int64     `json:"version"`
+	Time      time.Time `json:"time"`
+	Principal string    `json:"principal,omitempty"`
+	Client    string    `json:"client"` // gui, api, cli, or initial
+	Method    string    `json:"method,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	// The config in XML format, as it would be saved, so that references
+	// to secrets are kept as references.
+	Config []byte `json:"config,omitempty"`
+}
+
+// The configHistory keeps the last maxConfigHistory versions of the
+// config in the database, starting with the config as it was before the
+// first recorded change.
+type configHistory struct {
+	kv  *db.Typed
+	mut sync.Mutex
+}
+
+func newConfigHistory(kv *db.Typed) *configHistory {
+	return &configHistory{kv: kv}
+}
+
+func (h *configHistory) record(before, after config.Configuration, v configVersion) error {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	versions, err := h.versionsLocked()
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		initial := configVersion{Version: 1, Time: v.Time, Client: "initial"}
+		if err := h.putLocked(initial, before); err != nil {
+			return err
+		}
+		versions = append(versions, initial)
+	}
+	v.Version = versions[len(versions)-1].Version + 1
+	if err := h.putLocked(v, after); err != nil {
+		return err
+	}
+	versions = append(versions, v)
+
+	for len(versions) > maxConfigHistory {
+		if err := h.kv.Delete(configHistoryKey(versions[0].Version)); err != nil {
+			return err
+		}
+		versions = versions[1:]
+	}
+	return nil
+}
+
+func (h *configHistory) putLocked(v configVersion, cfg config.Configuration) error {
+	var buf bytes.Buffer
+	if err := cfg.WriteXML(&buf); err != nil {
+		return err
+	}
+	v.Config = buf.Bytes()
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return h.kv.PutBytes(configHistoryKey(v.Version), bs)
+}
+
+// versions returns the recorded versions, oldest first, without the
+// configs themselves.
+func (h *configHistory) versions() ([]configVersion, error) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	return h.versionsLocked()
+}
+
+func (h *configHistory) versionsLocked() ([]configVersion, error) {
+	it, errFn := h.kv.PrefixBytes(configHistoryKeyPrefix)
+	var versions []configVersion
+	for kv := range it {
+		var v configVersion
+		if err := json.Unmarshal(kv.Value, &v); err != nil {
+			continue
+		}
+		v.Config = nil
+		versions = append(versions, v)
+	}
+	if err := errFn(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// config returns the config of the given version.
+func (h *configHistory) config(version int64, myID protocol.DeviceID) (config.Configuration, error) {
+	bs, ok, err := h.kv.Bytes(configHistoryKey(version))
+	if err != nil {
+		return config.Configuration{}, err
+	}
+	if !ok {
+		return config.Configuration{}, errNoSuchConfigVersion
+	}
+	var v configVersion
+	if err := json.Unmarshal(bs, &v); err != nil {
+		return config.Configuration{}, err
+	}
+	cfg, _, err := config.ReadXML(bytes.NewReader(v.Config), myID)
+	return cfg, err
+}
+
+func configHistoryKey(version int64) string {
+	return fmt.Sprintf("%s%020d", configHistoryKeyPrefix, version)
+}
+
+// requestClient tells the GUI, the CLI and other API clients apart.
+func requestClient(r *http.Request, guiCfg config.GUIConfiguration) string {
+	if !hasValidAPIKeyHeader(r, guiCfg) {
+		return "gui"
+	}
+	if strings.HasPrefix(r.UserAgent(), cliUserAgent) {
+		return "cli"
+	}
+	return "api"
+}
+
+func (s *service) getConfigHistory(w http.ResponseWriter, _ *http.Request) {
+	versions, err := s.configHistory.versions()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, map[string][]configVersion{
+		"versions": versions,
+	})
+}
+
+// getConfigHistoryDiff shows what changed in the given version, or, with
+// "to", the difference between two versions ("current" being the running
+// config).
+func (s *service) getConfigHistoryDiff(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	version, err := strconv.ParseInt(q.Get("version"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid version", http.StatusBadRequest)
+		return
+	}
+
+	fromVersion, toVersion := version-1, strconv.FormatInt(version, 10)
+	if to := q.Get("to"); to != "" {
+		fromVersion, toVersion = version, to
+	}
+
+	from, err := s.configHistory.config(fromVersion, s.id)
+	if errors.Is(err, errNoSuchConfigVersion) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var to config.Configuration
+	if toVersion == "current" {
+		to = s.cfg.RawCopy()
+	} else {
+		v, err := strconv.ParseInt(toVersion, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid to version", http.StatusBadRequest)
+			return
+		}
+		to, err = s.configHistory.config(v, s.id)
+		if errors.Is(err, errNoSuchConfigVersion) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	sendJSON(w, map[string]any{
+		"from": fromVersion,
+		"to":   toVersion,
+		"diff": configDiff(from, to),
+	})
+}
+
+// postConfigHistoryRollback replaces the running config with the given
+// version. The rollback itself is recorded as a new version.
+func (s *service) postConfigHistoryRollback(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.ParseInt(r.URL.Query().Get("version"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid version", http.StatusBadRequest)
+		return
+	}
+	cfg, err := s.configHistory.config(version, s.id)
+	if errors.Is(err, errNoSuchConfigVersion) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	waiter, err := s.cfg.Modify(func(c *config.Configuration) {
+		// The second factor is only changed through its own endpoints.
+		cfg.GUI.TOTPSecret = c.GUI.TOTPSecret
+		cfg.GUI.TOTPRecoveryCodes = c.GUI.TOTPRecoveryCodes
+		*c = cfg
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	waiter.Wait()
+	if err := s.cfg.Save(); err != nil {
+		slog.Error("Failed to save config", slogutil.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	slog.Info("Rolled back config", "version", version)
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/db/sqlite"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func newTestConfigHistory(t *testing.T) *configHistory {
+	t.Helper()
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sdb.Close()
+	})
+	return newConfigHistory(db.NewMiscDB(sdb))
+}
+
+func TestConfigHistoryRecord(t *testing.T) {
+	t.Parallel()
+
+	h := newTestConfigHistory(t)
+	cfg := config.New(protocol.LocalDeviceID)
+	for i := range maxConfigHistory + 5 {
+		next := cfg.Copy()
+		next.Options.MaxSendKbps = i + 1
+		if err := h.record(cfg, next, configVersion{Time: time.Now(), Client: "api"}); err != nil {
+			t.Fatal(err)
+		}
+		cfg = next
+	}
+
+	versions, err := h.versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != maxConfigHistory {
+		t.Fatalf("expected %d versions, got %d", maxConfigHistory, len(versions))
+	}
+	// The initial version plus 55 changes, the oldest six trimmed
+	if first, last := versions[0].Version, versions[len(versions)-1].Version; first != 7 || last != 56 {
+		t.Errorf("unexpected version range %d-%d", first, last)
+	}
+
+	old, err := h.config(10, protocol.LocalDeviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Options.MaxSendKbps != 9 {
+		t.Errorf("unexpected config for version 10, max send %d", old.Options.MaxSendKbps)
+	}
+	if _, err := h.config(1, protocol.LocalDeviceID); err != errNoSuchConfigVersion {
+		t.Error("expected trimmed version to be gone, got", err)
+	}
+}
+
+func TestConfigHistoryRollback(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.New(protocol.LocalDeviceID)
+	cfg.GUI.APIKey = "abc123"
+	w := config.Wrap(filepath.Join(t.TempDir(), "config.xml"), cfg, protocol.LocalDeviceID, events.NoopLogger)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go w.Serve(ctx)
+
+	svc := &service{
+		id:            protocol.LocalDeviceID,
+		cfg:           w,
+		configHistory: newTestConfigHistory(t),
+	}
+
+	// A change through the API, as seen by the middleware
+	handler := auditMiddleware(newAPIAuditLog(svc.configHistory.kv), svc.configHistory, w, "sessionid-test", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		waiter, _ := w.Modify(func(cfg *config.Configuration) {
+			cfg.Options.MaxSendKbps = 42
+		})
+		waiter.Wait()
+	}))
+	req := httptest.NewRequest(http.MethodPut, "/rest/config/options", nil)
+	req.Header.Set("X-API-Key", "abc123")
+	req.Header.Set("User-Agent", cliUserAgent+"/v2")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	versions, err := svc.configHistory.versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Client != "initial" || versions[1].Client != "cli" {
+		t.Fatalf("unexpected versions: %+v", versions)
+	}
+
+	rec := httptest.NewRecorder()
+	svc.getConfigHistoryDiff(rec, httptest.NewRequest(http.MethodGet, "/rest/config/history/diff?version=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("diff: unexpected status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	svc.postConfigHistoryRollback(rec, httptest.NewRequest(http.MethodPost, "/rest/config/history/rollback?version=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rollback: unexpected status %d: %s", rec.Code, rec.Body)
+	}
+	if kbps := w.Options().MaxSendKbps; kbps != 0 {
+		t.Errorf("expected rolled back config, got max send %d", kbps)
+	}
+
+	rec = httptest.NewRecorder()
+	svc.postConfigHistoryRollback(rec, httptest.NewRequest(http.MethodPost, "/rest/config/history/rollback?version=9", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected not found for unknown version, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/connections"
+)
+
+type healthStatus string
+
+const (
+	healthOK       healthStatus = "ok"
+	healthDegraded healthStatus = "degraded"
+	healthFailed   healthStatus = "failed"
+	healthDisabled healthStatus = "disabled"
+	healthPaused   healthStatus = "paused"
+)
+
+// severity orders statuses from best to worst; disabled and paused
+// subsystems are not considered unhealthy.
+func (h healthStatus) severity() int {
+	switch h {
+	case healthFailed:
+		return 2
+	case healthDegraded:
+		return 1
+	default:
+		return 0
+	}
+}
+
+type healthEntry struct {
+	Status  healthStatus `json:"status"`
+	Reasons []string     `json:"reasons,omitempty"`
+	Since   *time.Time   `json:"since,omitempty"`
+}
+
+type healthReport struct {
+	Status     healthStatus           `json:"status"`
+	CheckedAt  time.Time              `json:"checkedAt"`
+	Subsystems map[string]healthEntry `json:"subsystems"`
+	Folders    map[string]healthEntry `json:"folders"`
+}
+
+// getSystemHealth reports the state of each subsystem. The response code is
+// 503 when the overall status is failed, so that load balancers and
+// monitoring can act on the status code alone.
+func (s *service) getSystemHealth(w http.ResponseWriter, _ *http.Request) {
+	report := healthReport{
+		CheckedAt:  time.Now().Truncate(time.Second),
+		Subsystems: make(map[string]healthEntry),
+		Folders:    make(map[string]healthEntry),
+	}
+
+	report.Subsystems["db"] = s.dbHealth()
+	report.Subsystems["discovery"] = s.discoveryHealth()
+	listeners, relays := listenerHealth(s.connectionsService.ListenerStatus())
+	report.Subsystems["listeners"] = listeners
+	report.Subsystems["relays"] = relays
+
+	for _, fcfg := range s.cfg.Folders() {
+		report.Folders[fcfg.ID] = s.folderHealth(fcfg.ID, fcfg.Paused)
+	}
+
+	report.Status = overallHealth(report)
+	if report.Status == healthFailed {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	sendJSON(w, report)
+}
+
+func (s *service) dbHealth() healthEntry {
+	if _, _, err := s.miscDB.Time("healthCheck"); err != nil {
+		return healthEntry{Status: healthFailed, Reasons: []string{err.Error()}}
+	}
+	return healthEntry{Status: healthOK}
+}
+
+func (s *service) discoveryHealth() healthEntry {
+	opts := s.cfg.Options()
+	if !opts.LocalAnnEnabled && !opts.GlobalAnnEnabled {
+		return healthEntry{Status: healthDisabled}
+	}
+	children := s.discoverer.ChildErrors()
+	var reasons []string
+	for name, err := range children {
+		if err != nil {
+			reasons = append(reasons, name+": "+err.Error())
+		}
+	}
+	slices.Sort(reasons)
+	return healthEntry{Status: partialHealth(len(reasons), len(children)), Reasons: reasons}
+}
+
+// listenerHealth splits the listener status into direct listeners and relay
+// listeners, as a host behind a firewall may well have working relays but
+// no useful listeners, or vice versa.
+func listenerHealth(status map[string]connections.ListenerStatusEntry) (healthEntry, healthEntry) {
+	var lisTotal, relTotal int
+	var lisReasons, relReasons []string
+	for addr, entry := range status {
+		isRelay := strings.HasPrefix(addr, "relay://") || strings.HasPrefix(addr, "dynamic+")
+		if isRelay {
+			relTotal++
+		} else {
+			lisTotal++
+		}
+		if entry.Error == nil {
+			continue
+		}
+		reason := addr + ": " + *entry.Error
+		if isRelay {
+			relReasons = append(relReasons, reason)
+		} else {
+			lisReasons = append(lisReasons, reason)
+		}
+	}
+
+	slices.Sort(lisReasons)
+	slices.Sort(relReasons)
+	listeners := healthEntry{Status: partialHealth(len(lisReasons), lisTotal), Reasons: lisReasons}
+	if lisTotal == 0 {
+		listeners.Status = healthDisabled
+	}
+	relays := healthEntry{Status: partialHealth(len(relReasons), relTotal), Reasons: relReasons}
+	if relTotal == 0 {
+		relays.Status = healthDisabled
+	}
+	return listeners, relays
+}
+
+func (s *service) folderHealth(folder string, paused bool) healthEntry {
+	if paused {
+		return healthEntry{Status: healthPaused}
+	}
+	state, changed, err := s.model.State(folder)
+	entry := healthEntry{Status: healthOK}
+	if !changed.IsZero() {
+		entry.Since = &changed
+	}
+	switch {
+	case err != nil:
+		entry.Status = healthFailed
+		entry.Reasons = append(entry.Reasons, err.Error())
+	case state == "":
+		entry.Status = healthFailed
+		entry.Reasons = append(entry.Reasons, "folder is not running")
+	}
+	if err := s.model.WatchError(folder); err != nil {
+		if entry.Status == healthOK {
+			entry.Status = healthDegraded
+		}
+		entry.Reasons = append(entry.Reasons, "watcher: "+err.Error())
+	}
+	return entry
+}
+
+// partialHealth returns failed when everything failed, degraded when some
+// things failed and ok otherwise.
+func partialHealth(failed, total int) healthStatus {
+	switch {
+	case failed == 0:
+		return healthOK
+	case failed == total:
+		return healthFailed
+	default:
+		return healthDegraded
+	}
+}
+
+// overallHealth is the worst of the subsystem states. A failure with a
+// single folder only degrades the overall state, as the rest of the
+// instance is still serving the other folders.
+func overallHealth(report healthReport) healthStatus {
+	worst := healthOK
+	for _, entry := range report.Subsystems {
+		if entry.Status.severity() > worst.severity() {
+			worst = entry.Status
+		}
+	}
+	if worst == healthOK {
+		for _, entry := range report.Folders {
+			if entry.Status.severity() > 0 {
+				return healthDegraded
+			}
+		}
+	}
+	return worst
+}
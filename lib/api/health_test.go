@@ -0,0 +1,63 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/connections"
+)
+
+func TestListenerHealth(t *testing.T) {
+	t.Parallel()
+
+	failure := "address in use"
+	status := map[string]connections.ListenerStatusEntry{
+		"tcp://0.0.0.0:22000":                           {},
+		"quic://0.0.0.0:22000":                          {Error: &failure},
+		"dynamic+https://relays.syncthing.net/endpoint": {Error: &failure},
+	}
+
+	listeners, relays := listenerHealth(status)
+	if listeners.Status != healthDegraded {
+		t.Errorf("expected degraded listeners, got %v", listeners.Status)
+	}
+	if len(listeners.Reasons) != 1 {
+		t.Errorf("expected one listener reason, got %v", listeners.Reasons)
+	}
+	if relays.Status != healthFailed {
+		t.Errorf("expected failed relays, got %v", relays.Status)
+	}
+
+	listeners, relays = listenerHealth(nil)
+	if listeners.Status != healthDisabled || relays.Status != healthDisabled {
+		t.Errorf("expected disabled, got %v and %v", listeners.Status, relays.Status)
+	}
+}
+
+func TestOverallHealth(t *testing.T) {
+	t.Parallel()
+
+	report := healthReport{
+		Subsystems: map[string]healthEntry{
+			"db":        {Status: healthOK},
+			"discovery": {Status: healthDisabled},
+		},
+		Folders: map[string]healthEntry{
+			"default": {Status: healthFailed},
+			"paused":  {Status: healthPaused},
+		},
+	}
+	if s := overallHealth(report); s != healthDegraded {
+		t.Errorf("a failed folder should degrade, got %v", s)
+	}
+
+	report.Subsystems["db"] = healthEntry{Status: healthFailed}
+	if s := overallHealth(report); s != healthFailed {
+		t.Errorf("a failed db should fail, got %v", s)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const logStreamKeepalive = 30 * time.Second
+
+// getSystemLogStream streams log lines as server-sent events as they are
+// logged, optionally limited to certain facilities and a minimum level.
+// Lines are dropped rather than delaying logging for a slow client.
+func (s *service) getSystemLogStream(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	facilities := splitPackages(qs.Get("facility"))
+	minLevel := slog.Level(-1000)
+	if lv := qs.Get("level"); lv != "" {
+		if err := minLevel.UnmarshalText([]byte(lv)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	lines, cancel := s.systemLog.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(logStreamKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case line := <-lines:
+			if line.Level < minLevel {
+				continue
+			}
+			if len(facilities) > 0 && !slices.Contains(facilities, line.Facility) {
+				continue
+			}
+			bs, err := json.Marshal(&line)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", bs); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+)
+
+func TestSystemLogStream(t *testing.T) {
+	t.Parallel()
+
+	svc := &service{systemLog: slogutil.GlobalRecorder}
+	srv := httptest.NewServer(http.HandlerFunc(svc.getSystemLogStream))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?facility=api&level=WARN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	// The stream is subscribed once the headers have been sent
+	slog.Info("Not interesting enough for the stream")
+	slog.Warn("Streamed warning")
+
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var line map[string]any
+		if err := json.Unmarshal([]byte(data), &line); err != nil {
+			t.Fatal(err)
+		}
+		msg, _ := line["message"].(string)
+		if strings.HasPrefix(msg, "Not interesting") {
+			t.Fatal("line below the requested level was streamed")
+		}
+		if !strings.HasPrefix(msg, "Streamed warning") {
+			// Logged by some other test running in parallel
+			continue
+		}
+		if line["facility"] != "api" || line["level"] != "WRN" {
+			t.Errorf("unexpected facility or level in %v", line)
+		}
+		return
+	}
+	t.Fatal("stream ended without a line", sc.Err())
+}
@@ -23,6 +23,10 @@ func getRedactedConfig(s *service) config.Configuration {
 	if rawConf.GUI.User != "" {
 		rawConf.GUI.User = "REDACTED"
 	}
+	if rawConf.GUI.TOTPSecret != "" {
+		rawConf.GUI.TOTPSecret = "REDACTED"
+	}
+	rawConf.GUI.TOTPRecoveryCodes = nil
 
 	for folderIdx, folderCfg := range rawConf.Folders {
 		for deviceIdx, deviceCfg := range folderCfg.Devices {
@@ -56,3 +56,131 @@ func (c Compression) ToProtocol() protocol.Compression {
 		return protocol.CompressionMetadata
 	}
 }
+
+// CompressionOverride replaces a Compression setting, or keeps it when
+// unset.
+type CompressionOverride int32
+
+const (
+	CompressionOverrideUnset    CompressionOverride = 0
+	CompressionOverrideNever    CompressionOverride = 1
+	CompressionOverrideMetadata CompressionOverride = 2
+	CompressionOverrideAlways   CompressionOverride = 3
+)
+
+var compressionOverrides = map[CompressionOverride]Compression{
+	CompressionOverrideNever:    CompressionNever,
+	CompressionOverrideMetadata: CompressionMetadata,
+	CompressionOverrideAlways:   CompressionAlways,
+}
+
+func (c CompressionOverride) MarshalText() ([]byte, error) {
+	if comp, ok := compressionOverrides[c]; ok {
+		return comp.MarshalText()
+	}
+	return nil, nil
+}
+
+func (c *CompressionOverride) UnmarshalText(bs []byte) error {
+	comp, ok := compressionUnmarshal[string(bs)]
+	switch {
+	case !ok:
+		*c = CompressionOverrideUnset
+	case comp == CompressionNever:
+		*c = CompressionOverrideNever
+	case comp == CompressionAlways:
+		*c = CompressionOverrideAlways
+	default:
+		*c = CompressionOverrideMetadata
+	}
+	return nil
+}
+
+// Apply returns the setting overridden, or the given one when unset.
+func (c CompressionOverride) Apply(comp Compression) Compression {
+	if oc, ok := compressionOverrides[c]; ok {
+		return oc
+	}
+	return comp
+}
+
+// CompressionAlgorithm is how messages that are compressed get compressed.
+// Devices default to LZ4; folders shared with them to the device's
+// algorithm.
+type CompressionAlgorithm int32
+
+const (
+	CompressionAlgorithmDefault CompressionAlgorithm = 0
+	CompressionAlgorithmLZ4     CompressionAlgorithm = 1
+	// Zstd is used with devices that can decompress it, and LZ4 with
+	// others.
+	CompressionAlgorithmZstd CompressionAlgorithm = 2
+)
+
+func (a CompressionAlgorithm) String() string {
+	switch a {
+	case CompressionAlgorithmLZ4:
+		return "lz4"
+	case CompressionAlgorithmZstd:
+		return "zstd"
+	default:
+		return ""
+	}
+}
+
+func (a CompressionAlgorithm) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *CompressionAlgorithm) UnmarshalText(bs []byte) error {
+	switch string(bs) {
+	case "lz4":
+		*a = CompressionAlgorithmLZ4
+	case "zstd":
+		*a = CompressionAlgorithmZstd
+	default:
+		*a = CompressionAlgorithmDefault
+	}
+	return nil
+}
+
+// Apply returns the algorithm, or the given one when it is the default.
+func (a CompressionAlgorithm) Apply(algo CompressionAlgorithm) CompressionAlgorithm {
+	if a == CompressionAlgorithmDefault {
+		return algo
+	}
+	return a
+}
+
+func (a CompressionAlgorithm) ToProtocol() protocol.CompressionAlgorithm {
+	if a == CompressionAlgorithmZstd {
+		return protocol.CompressionAlgorithmZstd
+	}
+	return protocol.CompressionAlgorithmLZ4
+}
+
+// CompressionPolicy returns how messages to the device are compressed: as
+// set for the device, except for the folders shared with it that override
+// that.
+func (cfg DeviceConfiguration) CompressionPolicy(folders []FolderConfiguration) protocol.CompressionPolicy {
+	policy := protocol.CompressionPolicy{
+		Default: protocol.CompressionSettings{
+			Compression: cfg.Compression.ToProtocol(),
+			Algorithm:   cfg.CompressionAlgorithm.ToProtocol(),
+		},
+	}
+	for _, fcfg := range folders {
+		fd, ok := fcfg.Device(cfg.DeviceID)
+		if !ok || fd.Compression == CompressionOverrideUnset && fd.CompressionAlgorithm == CompressionAlgorithmDefault {
+			continue
+		}
+		if policy.Folders == nil {
+			policy.Folders = make(map[string]protocol.CompressionSettings)
+		}
+		policy.Folders[fcfg.ID] = protocol.CompressionSettings{
+			Compression: fd.Compression.Apply(cfg.Compression).ToProtocol(),
+			Algorithm:   fd.CompressionAlgorithm.Apply(cfg.CompressionAlgorithm).ToProtocol(),
+		}
+	}
+	return policy
+}
@@ -2,7 +2,12 @@
 
 package config
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
 
 func TestCompressionMarshal(t *testing.T) {
 	uTestcases := []struct {
@@ -47,3 +52,54 @@ func TestCompressionMarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestCompressionOverrideMarshal(t *testing.T) {
+	testcases := []struct {
+		s string
+		c CompressionOverride
+	}{
+		{"", CompressionOverrideUnset},
+		{"never", CompressionOverrideNever},
+		{"metadata", CompressionOverrideMetadata},
+		{"always", CompressionOverrideAlways},
+	}
+
+	for _, tc := range testcases {
+		var c CompressionOverride
+		if err := c.UnmarshalText([]byte(tc.s)); err != nil {
+			t.Error(err)
+		}
+		if c != tc.c {
+			t.Errorf("%q unmarshalled to %d, not %d", tc.s, c, tc.c)
+		}
+		bs, err := tc.c.MarshalText()
+		if err != nil {
+			t.Error(err)
+		}
+		if s := string(bs); s != tc.s {
+			t.Errorf("%d marshalled to %q, not %q", tc.c, s, tc.s)
+		}
+	}
+}
+
+func TestCompressionPolicy(t *testing.T) {
+	dev := DeviceConfiguration{DeviceID: device1, Compression: CompressionMetadata, CompressionAlgorithm: CompressionAlgorithmZstd}
+	folders := []FolderConfiguration{
+		{ID: "docs", Devices: []FolderDeviceConfiguration{{DeviceID: device1}}},
+		{ID: "media", Devices: []FolderDeviceConfiguration{{DeviceID: device1, Compression: CompressionOverrideNever}}},
+		{ID: "logs", Devices: []FolderDeviceConfiguration{{DeviceID: device1, Compression: CompressionOverrideAlways, CompressionAlgorithm: CompressionAlgorithmLZ4}}},
+		{ID: "other", Devices: []FolderDeviceConfiguration{{DeviceID: device2, Compression: CompressionOverrideNever}}},
+	}
+
+	policy := dev.CompressionPolicy(folders)
+	expected := protocol.CompressionPolicy{
+		Default: protocol.CompressionSettings{Compression: protocol.CompressionMetadata, Algorithm: protocol.CompressionAlgorithmZstd},
+		Folders: map[string]protocol.CompressionSettings{
+			"media": {Compression: protocol.CompressionNever, Algorithm: protocol.CompressionAlgorithmZstd},
+			"logs":  {Compression: protocol.CompressionAlways, Algorithm: protocol.CompressionAlgorithmLZ4},
+		},
+	}
+	if !reflect.DeepEqual(policy, expected) {
+		t.Errorf("expected %+v, got %+v", expected, policy)
+	}
+}
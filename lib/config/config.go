@@ -101,18 +101,43 @@ type Configuration struct {
 	IgnoredDevices           []ObservedDevice      `json:"remoteIgnoredDevices" xml:"remoteIgnoredDevice"`
 	DeprecatedPendingDevices []ObservedDevice      `json:"-" xml:"pendingDevice,omitempty"` // Deprecated: Do not use.
 	Defaults                 Defaults              `json:"defaults" xml:"defaults"`
+	Hooks                    HooksConfiguration    `json:"hooks" xml:"hooks"`
+	Plugins                  []PluginConfiguration `json:"plugins" xml:"plugin"`
+
+	// References to the environment or files that values were loaded
+	// from, by field; see secrets.go.
+	secretRefs map[string]secretRef
+	// What drop-ins merged, so as not to save it; see dropin.go.
+	dropIns *dropInValues
 }
 
 type Defaults struct {
-	Folder  FolderConfiguration `json:"folder" xml:"folder"`
-	Device  DeviceConfiguration `json:"device" xml:"device"`
-	Ignores Ignores             `json:"ignores" xml:"ignores"`
+	Folder          FolderConfiguration `json:"folder" xml:"folder"`
+	Device          DeviceConfiguration `json:"device" xml:"device"`
+	Ignores         Ignores             `json:"ignores" xml:"ignores"`
+	FolderTemplates []FolderTemplate    `json:"folderTemplates" xml:"folderTemplate"`
 }
 
 type Ignores struct {
 	Lines []string `json:"lines" xml:"line"`
 }
 
+// A FolderTemplate is a named alternative to the default folder and
+// ignores, for a kind of folder (e.g. "photos" or "backup-target"). A
+// folder created from a template starts out from the template's folder
+// configuration and gets its ignore patterns.
+type FolderTemplate struct {
+	Name    string              `json:"name" xml:"name,attr"`
+	Folder  FolderConfiguration `json:"folder" xml:"folder"`
+	Ignores Ignores             `json:"ignores" xml:"ignores"`
+}
+
+func (t FolderTemplate) Copy() FolderTemplate {
+	t.Folder = t.Folder.Copy()
+	t.Ignores = t.Ignores.Copy()
+	return t
+}
+
 func New(myID protocol.DeviceID) Configuration {
 	var cfg Configuration
 	cfg.Version = CurrentVersion
@@ -180,9 +205,13 @@ func ReadXML(r io.Reader, myID protocol.DeviceID) (Configuration, int, error) {
 
 	originalVersion := cfg.Version
 
+	if err := cfg.resolveSecretRefs(); err != nil {
+		return Configuration{}, originalVersion, err
+	}
 	if err := cfg.prepare(myID); err != nil {
 		return Configuration{}, originalVersion, err
 	}
+	cfg.recordSecretValues()
 	return cfg.Configuration, originalVersion, nil
 }
 
@@ -192,6 +221,20 @@ func ReadJSON(r io.Reader, myID protocol.DeviceID) (Configuration, error) {
 		return Configuration{}, err
 	}
 
+	cfg, err := decodeJSON(bs)
+	if err != nil {
+		return Configuration{}, err
+	}
+
+	if err := cfg.prepare(myID); err != nil {
+		return Configuration{}, err
+	}
+	return cfg, nil
+}
+
+// decodeJSON decodes a config in JSON format, setting defaults on folders
+// and devices. The config is not prepared.
+func decodeJSON(bs []byte) (Configuration, error) {
 	var cfg Configuration
 
 	structutil.SetDefaults(&cfg)
@@ -225,9 +268,6 @@ func ReadJSON(r io.Reader, myID protocol.DeviceID) (Configuration, error) {
 		}
 	}
 
-	if err := cfg.prepare(myID); err != nil {
-		return Configuration{}, err
-	}
 	return cfg, nil
 }
 
@@ -237,6 +277,11 @@ func (cfg Configuration) Copy() Configuration {
 	// Deep copy Defaults
 	newCfg.Defaults.Folder = cfg.Defaults.Folder.Copy()
 	newCfg.Defaults.Device = cfg.Defaults.Device.Copy()
+	newCfg.Defaults.Ignores = cfg.Defaults.Ignores.Copy()
+	newCfg.Defaults.FolderTemplates = make([]FolderTemplate, len(cfg.Defaults.FolderTemplates))
+	for i := range newCfg.Defaults.FolderTemplates {
+		newCfg.Defaults.FolderTemplates[i] = cfg.Defaults.FolderTemplates[i].Copy()
+	}
 
 	// Deep copy FolderConfigurations
 	newCfg.Folders = make([]FolderConfiguration, len(cfg.Folders))
@@ -253,6 +298,9 @@ func (cfg Configuration) Copy() Configuration {
 	newCfg.Options = cfg.Options.Copy()
 	newCfg.GUI = cfg.GUI.Copy()
 
+	newCfg.Hooks = cfg.Hooks.Copy()
+	newCfg.Plugins = slices.Clone(cfg.Plugins)
+
 	// DeviceIDs are values
 	newCfg.IgnoredDevices = make([]ObservedDevice, len(cfg.IgnoredDevices))
 	copy(newCfg.IgnoredDevices, cfg.IgnoredDevices)
@@ -263,7 +311,7 @@ func (cfg Configuration) Copy() Configuration {
 func (cfg *Configuration) WriteXML(w io.Writer) error {
 	e := xml.NewEncoder(w)
 	e.Indent("", "    ")
-	xmlCfg := xmlConfiguration{Configuration: *cfg}
+	xmlCfg := xmlConfiguration{Configuration: cfg.withoutDropIns().withSecretRefs()}
 	err := e.Encode(xmlCfg)
 	if err != nil {
 		return err
@@ -289,6 +337,9 @@ func (cfg *Configuration) prepare(myID protocol.DeviceID) error {
 
 	cfg.Defaults.prepare(myID, existingDevices)
 
+	cfg.Hooks.prepare()
+	cfg.preparePlugins()
+
 	cfg.removeDeprecatedProtocols()
 
 	structutil.FillNilExceptDeprecated(cfg)
@@ -370,6 +421,10 @@ func (cfg *Configuration) prepareFolders(myID protocol.DeviceID, existingDevices
 			return nil, fmt.Errorf("folder %q: %w", folder.ID, errFolderIDDuplicate)
 		}
 
+		if _, err := ParseRetentionPolicy(folder.Versioning.RetentionPolicy); err != nil {
+			return nil, fmt.Errorf("folder %q: %w", folder.ID, err)
+		}
+
 		folder.prepare(myID, existingDevices)
 
 		existingFolders[folder.ID] = folder
@@ -467,6 +522,45 @@ func (cfg *Configuration) SetDevices(devices []DeviceConfiguration) {
 	cfg.Devices = append(cfg.Devices, filtered...)
 }
 
+// MergeDevice replaces the device by the one it became, e.g. after it was
+// set up anew with another identity, so that no record of the old device is
+// left behind. The new device takes over the settings and folder shares of
+// the old one, except where it has its own already. It returns false if
+// there is no such old device.
+func (cfg *Configuration) MergeDevice(from, to protocol.DeviceID) bool {
+	old, i, ok := cfg.Device(from)
+	if !ok {
+		return false
+	}
+	cfg.Devices = slices.Delete(cfg.Devices, i, i+1)
+	if _, _, ok := cfg.Device(to); !ok {
+		old.DeviceID = to
+		cfg.Devices = append(cfg.Devices, old)
+	}
+	for i := range cfg.Devices {
+		if cfg.Devices[i].IntroducedBy == from {
+			cfg.Devices[i].IntroducedBy = to
+		}
+	}
+
+	for i := range cfg.Folders {
+		folder := &cfg.Folders[i]
+		_, shared := folder.Device(to)
+		folder.Devices = slices.DeleteFunc(folder.Devices, func(dev FolderDeviceConfiguration) bool {
+			return dev.DeviceID == from && shared
+		})
+		for j := range folder.Devices {
+			if folder.Devices[j].DeviceID == from {
+				folder.Devices[j].DeviceID = to
+			}
+			if folder.Devices[j].IntroducedBy == from {
+				folder.Devices[j].IntroducedBy = to
+			}
+		}
+	}
+	return true
+}
+
 func (cfg *Configuration) Folder(id string) (FolderConfiguration, int, bool) {
 	for i, folder := range cfg.Folders {
 		if folder.ID == id {
@@ -497,6 +591,26 @@ func (cfg Configuration) FolderPasswords(device protocol.DeviceID) map[string]st
 	return res
 }
 
+// FolderTemplate returns the folder template with the given name.
+func (cfg *Configuration) FolderTemplate(name string) (FolderTemplate, int, bool) {
+	for i, tmpl := range cfg.Defaults.FolderTemplates {
+		if tmpl.Name == name {
+			return tmpl, i, true
+		}
+	}
+	return FolderTemplate{}, 0, false
+}
+
+// SetFolderTemplate adds the template, or replaces the one with the same
+// name.
+func (cfg *Configuration) SetFolderTemplate(tmpl FolderTemplate) {
+	if _, i, ok := cfg.FolderTemplate(tmpl.Name); ok {
+		cfg.Defaults.FolderTemplates[i] = tmpl
+		return
+	}
+	cfg.Defaults.FolderTemplates = append(cfg.Defaults.FolderTemplates, tmpl)
+}
+
 func (cfg *Configuration) SetFolder(folder FolderConfiguration) {
 	cfg.SetFolders([]FolderConfiguration{folder})
 }
@@ -662,6 +776,25 @@ func (defaults *Defaults) prepare(myID protocol.DeviceID, existingDevices map[pr
 	ensureZeroForNodefault(&DeviceConfiguration{}, &defaults.Device)
 	defaults.Folder.prepare(myID, existingDevices)
 	defaults.Device.prepare(nil)
+
+	seen := make(map[string]struct{}, len(defaults.FolderTemplates))
+	templates := defaults.FolderTemplates[:0]
+	for _, tmpl := range defaults.FolderTemplates {
+		tmpl.Name = strings.TrimSpace(tmpl.Name)
+		if tmpl.Name == "" {
+			slog.Warn("Skipping folder template without a name")
+			continue
+		}
+		if _, ok := seen[tmpl.Name]; ok {
+			slog.Warn("Skipping duplicate folder template", "name", tmpl.Name)
+			continue
+		}
+		seen[tmpl.Name] = struct{}{}
+		ensureZeroForNodefault(&FolderConfiguration{}, &tmpl.Folder)
+		tmpl.Folder.prepare(myID, existingDevices)
+		templates = append(templates, tmpl)
+	}
+	defaults.FolderTemplates = templates
 }
 
 func ensureZeroForNodefault(empty interface{}, target interface{}) {
@@ -18,8 +18,10 @@ import (
 	"reflect"
 	"runtime"
 	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/d4l3k/messagediff"
 	"golang.org/x/crypto/bcrypt"
@@ -29,6 +31,7 @@ import (
 	"github.com/syncthing/syncthing/lib/fs"
 	"github.com/syncthing/syncthing/lib/protocol"
 	"github.com/syncthing/syncthing/lib/rand"
+	"github.com/syncthing/syncthing/lib/totp"
 )
 
 var device1, device2, device3, device4 protocol.DeviceID
@@ -92,6 +95,7 @@ func TestDefaultValues(t *testing.T) {
 			RawStunServers:            []string{"default"},
 			AnnounceLANAddresses:      true,
 			FeatureFlags:              []string{},
+			RateLimitSchedule:         []RateLimitPeriod{},
 			AuditEnabled:              false,
 			AuditFile:                 "",
 			ConnectionPriorityTCPLAN:  10,
@@ -105,7 +109,7 @@ func TestDefaultValues(t *testing.T) {
 				FilesystemType:   FilesystemTypeBasic,
 				Path:             "",
 				Type:             FolderTypeSendReceive,
-				Devices:          []FolderDeviceConfiguration{{DeviceID: device1}},
+				Devices:          []FolderDeviceConfiguration{{DeviceID: device1, EncryptionSubtrees: []FolderDeviceEncryptionSubtree{}, Filter: []string{}}},
 				RescanIntervalS:  3600,
 				FSWatcherEnabled: true,
 				FSWatcherDelayS:  10,
@@ -126,17 +130,38 @@ func TestDefaultValues(t *testing.T) {
 					MaxSingleEntrySize: 1024,
 					MaxTotalSize:       4096,
 				},
+				FilesystemWrappers: []string{},
+				PullFirst:          []string{},
+				PullLast:           []string{},
+				OwnershipMapping: OwnershipMapping{
+					Owners: []OwnershipMapEntry{},
+					Groups: []OwnershipMapEntry{},
+				},
+				AlternateStreamFilter: AlternateStreamFilter{
+					Skip:               []string{},
+					MaxSingleEntrySize: 65536,
+					MaxTotalSize:       262144,
+				},
+				ActiveTimes: []string{},
 			},
 			Device: DeviceConfiguration{
-				Addresses:       []string{"dynamic"},
-				AllowedNetworks: []string{},
-				Compression:     CompressionMetadata,
-				IgnoredFolders:  []ObservedFolder{},
+				Addresses:         []string{"dynamic"},
+				AllowedNetworks:   []string{},
+				AllowedInterfaces: []string{},
+				AllowedTimes:      []string{},
+				Compression:       CompressionMetadata,
+				IgnoredFolders:    []ObservedFolder{},
 			},
 			Ignores: Ignores{
 				Lines: []string{},
 			},
+			FolderTemplates: []FolderTemplate{},
 		},
+		Hooks: HooksConfiguration{
+			MaxConcurrent: 4,
+			Hooks:         []HookConfiguration{},
+		},
+		Plugins:        []PluginConfiguration{},
 		IgnoredDevices: []ObservedDevice{},
 	}
 	expected.Devices = []DeviceConfiguration{expected.Defaults.Device.Copy()}
@@ -180,7 +205,7 @@ func TestDeviceConfig(t *testing.T) {
 				ID:               "test",
 				FilesystemType:   FilesystemTypeBasic,
 				Path:             "testdata",
-				Devices:          []FolderDeviceConfiguration{{DeviceID: device1}, {DeviceID: device4}},
+				Devices:          []FolderDeviceConfiguration{{DeviceID: device1, EncryptionSubtrees: []FolderDeviceEncryptionSubtree{}, Filter: []string{}}, {DeviceID: device4, EncryptionSubtrees: []FolderDeviceEncryptionSubtree{}, Filter: []string{}}},
 				Type:             FolderTypeSendOnly,
 				RescanIntervalS:  600,
 				FSWatcherEnabled: true,
@@ -204,25 +229,42 @@ func TestDeviceConfig(t *testing.T) {
 					MaxTotalSize:       4096,
 					Entries:            []XattrFilterEntry{},
 				},
+				FilesystemWrappers: []string{},
+				PullFirst:          []string{},
+				PullLast:           []string{},
+				OwnershipMapping: OwnershipMapping{
+					Owners: []OwnershipMapEntry{},
+					Groups: []OwnershipMapEntry{},
+				},
+				AlternateStreamFilter: AlternateStreamFilter{
+					Skip:               []string{},
+					MaxSingleEntrySize: 65536,
+					MaxTotalSize:       262144,
+				},
+				ActiveTimes: []string{},
 			},
 		}
 
 		expectedDevices := []DeviceConfiguration{
 			{
-				DeviceID:        device1,
-				Name:            "node one",
-				Addresses:       []string{"tcp://a"},
-				Compression:     CompressionMetadata,
-				AllowedNetworks: []string{},
-				IgnoredFolders:  []ObservedFolder{},
+				DeviceID:          device1,
+				Name:              "node one",
+				Addresses:         []string{"tcp://a"},
+				Compression:       CompressionMetadata,
+				AllowedNetworks:   []string{},
+				AllowedInterfaces: []string{},
+				AllowedTimes:      []string{},
+				IgnoredFolders:    []ObservedFolder{},
 			},
 			{
-				DeviceID:        device4,
-				Name:            "node two",
-				Addresses:       []string{"tcp://b"},
-				Compression:     CompressionMetadata,
-				AllowedNetworks: []string{},
-				IgnoredFolders:  []ObservedFolder{},
+				DeviceID:          device4,
+				Name:              "node two",
+				Addresses:         []string{"tcp://b"},
+				Compression:       CompressionMetadata,
+				AllowedNetworks:   []string{},
+				AllowedInterfaces: []string{},
+				AllowedTimes:      []string{},
+				IgnoredFolders:    []ObservedFolder{},
 			},
 		}
 		expectedDeviceIDs := []protocol.DeviceID{device1, device4}
@@ -297,6 +339,7 @@ func TestOverriddenValues(t *testing.T) {
 		StunKeepaliveMinS:         900,
 		RawStunServers:            []string{"foo"},
 		FeatureFlags:              []string{"feature"},
+		RateLimitSchedule:         []RateLimitPeriod{},
 		AuditEnabled:              true,
 		AuditFile:                 "nggyu",
 		ConnectionPriorityTCPLAN:  40,
@@ -332,30 +375,38 @@ func TestDeviceAddressesDynamic(t *testing.T) {
 	name, _ := os.Hostname()
 	expected := map[protocol.DeviceID]DeviceConfiguration{
 		device1: {
-			DeviceID:        device1,
-			Addresses:       []string{"dynamic"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device1,
+			Addresses:         []string{"dynamic"},
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 		device2: {
-			DeviceID:        device2,
-			Addresses:       []string{"dynamic"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device2,
+			Addresses:         []string{"dynamic"},
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 		device3: {
-			DeviceID:        device3,
-			Addresses:       []string{"dynamic"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device3,
+			Addresses:         []string{"dynamic"},
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 		device4: {
-			DeviceID:        device4,
-			Name:            name, // Set when auto created
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionMetadata,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device4,
+			Name:              name, // Set when auto created
+			Addresses:         []string{"dynamic"},
+			Compression:       CompressionMetadata,
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 	}
 
@@ -375,33 +426,41 @@ func TestDeviceCompression(t *testing.T) {
 	name, _ := os.Hostname()
 	expected := map[protocol.DeviceID]DeviceConfiguration{
 		device1: {
-			DeviceID:        device1,
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionMetadata,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device1,
+			Addresses:         []string{"dynamic"},
+			Compression:       CompressionMetadata,
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 		device2: {
-			DeviceID:        device2,
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionMetadata,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device2,
+			Addresses:         []string{"dynamic"},
+			Compression:       CompressionMetadata,
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 		device3: {
-			DeviceID:        device3,
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionNever,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device3,
+			Addresses:         []string{"dynamic"},
+			Compression:       CompressionNever,
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 		device4: {
-			DeviceID:        device4,
-			Name:            name, // Set when auto created
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionMetadata,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device4,
+			Name:              name, // Set when auto created
+			Addresses:         []string{"dynamic"},
+			Compression:       CompressionMetadata,
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 	}
 
@@ -421,30 +480,38 @@ func TestDeviceAddressesStatic(t *testing.T) {
 	name, _ := os.Hostname()
 	expected := map[protocol.DeviceID]DeviceConfiguration{
 		device1: {
-			DeviceID:        device1,
-			Addresses:       []string{"tcp://192.0.2.1", "tcp://192.0.2.2"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device1,
+			Addresses:         []string{"tcp://192.0.2.1", "tcp://192.0.2.2"},
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 		device2: {
-			DeviceID:        device2,
-			Addresses:       []string{"tcp://192.0.2.3:6070", "tcp://[2001:db8::42]:4242"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device2,
+			Addresses:         []string{"tcp://192.0.2.3:6070", "tcp://[2001:db8::42]:4242"},
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 		device3: {
-			DeviceID:        device3,
-			Addresses:       []string{"tcp://[2001:db8::44]:4444", "tcp://192.0.2.4:6090"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device3,
+			Addresses:         []string{"tcp://[2001:db8::44]:4444", "tcp://192.0.2.4:6090"},
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 		device4: {
-			DeviceID:        device4,
-			Name:            name, // Set when auto created
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionMetadata,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:          device4,
+			Name:              name, // Set when auto created
+			Addresses:         []string{"dynamic"},
+			Compression:       CompressionMetadata,
+			AllowedNetworks:   []string{},
+			AllowedInterfaces: []string{},
+			AllowedTimes:      []string{},
+			IgnoredFolders:    []ObservedFolder{},
 		},
 	}
 
@@ -1472,6 +1539,104 @@ func TestReceiveEncryptedFolderFixed(t *testing.T) {
 	}
 }
 
+func TestRotateEncryptionPassword(t *testing.T) {
+	f := FolderConfiguration{
+		ID: "foo",
+		Devices: []FolderDeviceConfiguration{
+			{DeviceID: device1},
+			{DeviceID: device2, EncryptionPassword: "old"},
+		},
+	}
+
+	if err := f.RotateEncryptionPassword(device1, "new"); err == nil {
+		t.Error("expected error rotating for a device shared without encryption")
+	}
+	if err := f.RotateEncryptionPassword(device3, "new"); err == nil {
+		t.Error("expected error rotating for a device the folder isn't shared with")
+	}
+	if err := f.RotateEncryptionPassword(device2, "old"); err == nil {
+		t.Error("expected error rotating to the same password")
+	}
+
+	if err := f.RotateEncryptionPassword(device2, "new"); err != nil {
+		t.Fatal(err)
+	}
+	dev, _ := f.Device(device2)
+	if dev.EncryptionPassword != "new" || dev.PreviousEncryptionPassword != "old" || !dev.RotatingEncryption() {
+		t.Errorf("unexpected device config after rotating: %+v", dev)
+	}
+	if err := f.RotateEncryptionPassword(device2, "newer"); err == nil {
+		t.Error("expected error rotating while already rotating")
+	}
+}
+
+func TestEncryptionSubtreesPrepared(t *testing.T) {
+	cfg := Configuration{
+		Devices: []DeviceConfiguration{{DeviceID: device1}, {DeviceID: device2}},
+		Folders: []FolderConfiguration{
+			{
+				ID:   "foo",
+				Path: "testdata",
+				Devices: []FolderDeviceConfiguration{
+					{
+						DeviceID:           device1,
+						EncryptionPassword: "secret",
+						EncryptionSubtrees: []FolderDeviceEncryptionSubtree{
+							{Path: "/public/"},
+							{Path: "archive//old", EncryptionPassword: "other"},
+							{Path: "public"},
+							{Path: "."},
+							{Path: "../outside"},
+						},
+					},
+					{
+						DeviceID:           device2,
+						EncryptionSubtrees: []FolderDeviceEncryptionSubtree{{Path: "public"}},
+					},
+				},
+			},
+		},
+	}
+
+	cfg.prepare(device1)
+
+	dev1, _ := cfg.Folders[0].Device(device1)
+	expected := []FolderDeviceEncryptionSubtree{
+		{Path: "public"},
+		{Path: "archive/old", EncryptionPassword: "other"},
+	}
+	if !reflect.DeepEqual(dev1.EncryptionSubtrees, expected) {
+		t.Errorf("expected subtrees %v, got %v", expected, dev1.EncryptionSubtrees)
+	}
+	if dev2, _ := cfg.Folders[0].Device(device2); len(dev2.EncryptionSubtrees) != 0 {
+		t.Errorf("expected no subtrees for trusted device, got %v", dev2.EncryptionSubtrees)
+	}
+}
+
+func TestFolderDeviceExpires(t *testing.T) {
+	dev := FolderDeviceConfiguration{DeviceID: device1}
+	bs, err := json.Marshal(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(bs, []byte("expires")) {
+		t.Errorf("unset expiry in JSON: %s", bs)
+	}
+
+	dev.Expires = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	bs, err = json.Marshal(dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded FolderDeviceConfiguration
+	if err := json.Unmarshal(bs, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Expires.Equal(dev.Expires) {
+		t.Errorf("expected expiry %v, got %v", dev.Expires, loaded.Expires)
+	}
+}
+
 func TestXattrFilter(t *testing.T) {
 	cases := []struct {
 		in     []string
@@ -1512,6 +1677,65 @@ func TestXattrFilter(t *testing.T) {
 	}
 }
 
+func TestAlternateStreamFilter(t *testing.T) {
+	f := AlternateStreamFilter{Skip: []string{"Custom*"}}
+	for name, permit := range map[string]bool{
+		"Zone.Identifier":   false,
+		"ZONE.IDENTIFIER":   false,
+		"SmartScreen":       false,
+		"customData":        false,
+		"com.dropbox.attrs": false,
+		"author":            true,
+		"Zone":              true,
+	} {
+		if f.Permit(name) != permit {
+			t.Errorf("expected Permit(%q) to be %v", name, permit)
+		}
+	}
+}
+
+func TestOwnershipMapping(t *testing.T) {
+	m := OwnershipMapping{
+		Owners: []OwnershipMapEntry{
+			{Remote: "alice", Local: "bob"},
+			{Remote: "1001", Local: "2001"},
+		},
+		Groups:           []OwnershipMapEntry{{Remote: "staff", Local: "100"}},
+		ClearPermissions: "022",
+		SetPermissions:   "600",
+	}
+
+	for _, tc := range []struct {
+		name   string
+		id     int
+		owner  string
+		uid    int
+		groups bool
+	}{
+		{"alice", 1000, "bob", 1000, false},  // by name, keeping the ID as fallback
+		{"carol", 1001, "", 2001, false},     // by ID
+		{"", 1001, "", 2001, false},          // by ID, without a name
+		{"dave", 1002, "dave", 1002, false},  // unmapped
+		{"staff", 50, "", 100, true},         // groups are separate
+		{"alice", 1000, "alice", 1000, true}, // ...from owners
+	} {
+		mapFn := m.MapOwner
+		if tc.groups {
+			mapFn = m.MapGroup
+		}
+		if owner, uid := mapFn(tc.name, tc.id); owner != tc.owner || uid != tc.uid {
+			t.Errorf("mapping %q/%d: got %q/%d, expected %q/%d", tc.name, tc.id, owner, uid, tc.owner, tc.uid)
+		}
+	}
+
+	if perm := m.MapPermissions(0o775); perm != 0o755 {
+		t.Errorf("mapping permissions: got %o, expected 755", perm)
+	}
+	if perm := (OwnershipMapping{}).MapPermissions(0o775); perm != 0o775 {
+		t.Errorf("mapping permissions without masks: got %o, expected 775", perm)
+	}
+}
+
 func TestUntrustedIntroducer(t *testing.T) {
 	fd, err := os.Open("testdata/untrustedintroducer.xml")
 	if err != nil {
@@ -1678,3 +1902,127 @@ func TestCopyMatching(t *testing.T) {
 		t.Error("NoCopy")
 	}
 }
+
+func TestFolderTemplates(t *testing.T) {
+	input := `<configuration version="` + strconv.Itoa(CurrentVersion) + `">
+		<defaults>
+			<folderTemplate name="photos">
+				<folder id="ignored" rescanIntervalS="60"><order>newestFirst</order></folder>
+				<ignores><line>*.tmp</line></ignores>
+			</folderTemplate>
+			<folderTemplate name="photos"><folder rescanIntervalS="1"></folder></folderTemplate>
+			<folderTemplate name=""></folderTemplate>
+		</defaults>
+	</configuration>`
+
+	cfg, _, err := ReadXML(strings.NewReader(input), device1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Defaults.FolderTemplates) != 1 {
+		t.Fatalf("expected duplicate and unnamed templates to be dropped, got %d", len(cfg.Defaults.FolderTemplates))
+	}
+	tmpl, _, ok := cfg.FolderTemplate("photos")
+	if !ok {
+		t.Fatal("template missing")
+	}
+	if tmpl.Folder.ID != "" {
+		t.Errorf("template folder should not have an ID, got %q", tmpl.Folder.ID)
+	}
+	if tmpl.Folder.RescanIntervalS != 60 || tmpl.Folder.Order != PullOrderNewestFirst || !slices.Equal(tmpl.Ignores.Lines, []string{"*.tmp"}) {
+		t.Errorf("unexpected template: %+v", tmpl)
+	}
+
+	cp := cfg.Copy()
+	cp.Defaults.FolderTemplates[0].Ignores.Lines[0] = "changed"
+	if cfg.Defaults.FolderTemplates[0].Ignores.Lines[0] != "*.tmp" {
+		t.Error("copy should not share templates")
+	}
+
+	cfg.SetFolderTemplate(FolderTemplate{Name: "code"})
+	cfg.SetFolderTemplate(FolderTemplate{Name: "photos"})
+	if len(cfg.Defaults.FolderTemplates) != 2 {
+		t.Errorf("expected two templates, got %d", len(cfg.Defaults.FolderTemplates))
+	}
+}
+
+func TestTOTPEnrollment(t *testing.T) {
+	var gui GUIConfiguration
+	if gui.TOTPEnabled() {
+		t.Fatal("expected TOTP to be disabled by default")
+	}
+	secret := totp.NewSecret()
+	codes, err := gui.EnrollTOTP(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gui.TOTPEnabled() || gui.TOTPSecret != secret {
+		t.Fatal("expected TOTP to be enabled with the given secret")
+	}
+	bs, err := json.Marshal(gui)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(bs, []byte(secret)) || !bytes.Contains(bs, []byte(`"totpEnabled":true`)) {
+		t.Errorf("expected only whether TOTP is enabled in JSON, got %s", bs)
+	}
+	if len(codes) != totpRecoveryCodes || len(gui.TOTPRecoveryCodes) != totpRecoveryCodes {
+		t.Fatalf("expected %d recovery codes, got %d", totpRecoveryCodes, len(codes))
+	}
+	if slices.Contains(gui.TOTPRecoveryCodes, codes[0]) {
+		t.Error("expected recovery codes to be stored hashed")
+	}
+
+	copied := gui.Copy()
+	if !gui.UseTOTPRecoveryCode(" " + strings.ToUpper(codes[3]) + " ") {
+		t.Error("expected recovery code to be accepted")
+	}
+	if gui.UseTOTPRecoveryCode(codes[3]) {
+		t.Error("expected recovery code to be accepted only once")
+	}
+	if len(gui.TOTPRecoveryCodes) != totpRecoveryCodes-1 || len(copied.TOTPRecoveryCodes) != totpRecoveryCodes {
+		t.Error("expected only the used code to be removed, from the original only")
+	}
+
+	gui.ResetTOTP()
+	if gui.TOTPEnabled() || len(gui.TOTPRecoveryCodes) != 0 {
+		t.Error("expected TOTP to be disabled after reset")
+	}
+}
+
+func TestMergeDevice(t *testing.T) {
+	cfg := Configuration{
+		Devices: []DeviceConfiguration{
+			{DeviceID: device1},
+			{DeviceID: device2, Name: "laptop", Addresses: []string{"tcp://laptop"}},
+			{DeviceID: device4, IntroducedBy: device2},
+		},
+		Folders: []FolderConfiguration{
+			{ID: "a", Devices: []FolderDeviceConfiguration{{DeviceID: device1}, {DeviceID: device2, EncryptionPassword: "secret"}}},
+			{ID: "b", Devices: []FolderDeviceConfiguration{{DeviceID: device2}, {DeviceID: device3}}},
+		},
+	}
+
+	if cfg.MergeDevice(device3, device2) {
+		t.Error("expected no merge of an unknown device")
+	}
+	if !cfg.MergeDevice(device2, device3) {
+		t.Fatal("expected the device to be merged")
+	}
+
+	if _, _, ok := cfg.Device(device2); ok {
+		t.Error("expected the old device to be removed")
+	}
+	if dev, _, ok := cfg.Device(device3); !ok || dev.Name != "laptop" || !slices.Equal(dev.Addresses, []string{"tcp://laptop"}) {
+		t.Errorf("expected the new device to take over the settings of the old one, got %+v", dev)
+	}
+	if dev, _, _ := cfg.Device(device4); dev.IntroducedBy != device3 {
+		t.Errorf("expected the introducer to be updated, got %v", dev.IntroducedBy)
+	}
+	if dev, ok := cfg.Folders[0].Device(device3); !ok || dev.EncryptionPassword != "secret" {
+		t.Errorf("expected the share to be taken over, got %+v", cfg.Folders[0].Devices)
+	}
+	if ids := cfg.Folders[1].DeviceIDs(); !slices.Equal(ids, []protocol.DeviceID{device3}) {
+		t.Errorf("expected the share to be merged with the existing one, got %v", ids)
+	}
+}
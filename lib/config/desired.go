@@ -0,0 +1,272 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// DesiredState is a declarative description of part of the config, read
+// from a JSON file in the same format as the REST API uses. Sections that
+// are left out are not managed. For a section that is given:
+//
+//   - options: the given options are set, others are left alone;
+//   - devices and folders: the list is authoritative. Entries are matched
+//     by ID and the given fields are set on the existing entry, or on the
+//     defaults for a new one. Entries that aren't listed are removed,
+//     except for our own device.
+//
+// Runtime state kept outside of the config, like pending devices and
+// folders, is not affected.
+type DesiredState struct {
+	Options json.RawMessage    `json:"options,omitempty"`
+	Devices *[]json.RawMessage `json:"devices,omitempty"`
+	Folders *[]json.RawMessage `json:"folders,omitempty"`
+}
+
+func ReadDesiredState(r io.Reader) (DesiredState, error) {
+	var d DesiredState
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&d); err != nil {
+		return DesiredState{}, err
+	}
+	return d, nil
+}
+
+// Apply brings cfg in line with the desired state and returns a
+// description of each change made. On error cfg is left unchanged.
+func (d DesiredState) Apply(cfg *Configuration, myID protocol.DeviceID) ([]string, error) {
+	to := cfg.Copy()
+	var changes []string
+
+	if len(d.Options) > 0 {
+		opts := to.Options.Copy()
+		if err := decodeOver(d.Options, &opts); err != nil {
+			return nil, fmt.Errorf("options: %w", err)
+		}
+		if !reflect.DeepEqual(opts, to.Options) {
+			to.Options = opts
+			changes = append(changes, "changed options")
+		}
+	}
+
+	if d.Devices != nil {
+		devices := make([]DeviceConfiguration, 0, len(*d.Devices))
+		seen := make(map[protocol.DeviceID]struct{})
+		for _, bs := range *d.Devices {
+			var key struct {
+				DeviceID protocol.DeviceID `json:"deviceID"`
+			}
+			if err := json.Unmarshal(bs, &key); err != nil {
+				return nil, fmt.Errorf("devices: %w", err)
+			}
+			if key.DeviceID == protocol.EmptyDeviceID {
+				return nil, fmt.Errorf("devices: entry without deviceID")
+			}
+			if _, ok := seen[key.DeviceID]; ok {
+				return nil, fmt.Errorf("devices: %s listed twice", key.DeviceID.Short())
+			}
+			seen[key.DeviceID] = struct{}{}
+
+			dev := to.Defaults.Device.Copy()
+			cur, _, exists := to.Device(key.DeviceID)
+			if exists {
+				dev = cur.Copy()
+			}
+			if err := decodeOver(bs, &dev); err != nil {
+				return nil, fmt.Errorf("device %s: %w", key.DeviceID.Short(), err)
+			}
+			switch {
+			case !exists:
+				changes = append(changes, "added device "+dev.DeviceID.Short().String())
+			case !reflect.DeepEqual(dev, cur):
+				changes = append(changes, "changed device "+dev.DeviceID.Short().String())
+			}
+			devices = append(devices, dev)
+		}
+		for _, dev := range to.Devices {
+			if _, ok := seen[dev.DeviceID]; ok {
+				continue
+			}
+			if dev.DeviceID == myID {
+				devices = append(devices, dev)
+				continue
+			}
+			changes = append(changes, "removed device "+dev.DeviceID.Short().String())
+		}
+		to.Devices = devices
+	}
+
+	if d.Folders != nil {
+		folders := make([]FolderConfiguration, 0, len(*d.Folders))
+		seen := make(map[string]struct{})
+		for _, bs := range *d.Folders {
+			var key struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(bs, &key); err != nil {
+				return nil, fmt.Errorf("folders: %w", err)
+			}
+			if key.ID == "" {
+				return nil, fmt.Errorf("folders: entry without id")
+			}
+			if _, ok := seen[key.ID]; ok {
+				return nil, fmt.Errorf("folders: %q listed twice", key.ID)
+			}
+			seen[key.ID] = struct{}{}
+
+			fcfg := to.Defaults.Folder.Copy()
+			cur, _, exists := to.Folder(key.ID)
+			if exists {
+				fcfg = cur.Copy()
+			}
+			if err := UnmarshalFolderOnto(bs, &fcfg); err != nil {
+				return nil, fmt.Errorf("folder %q: %w", key.ID, err)
+			}
+			switch {
+			case !exists:
+				changes = append(changes, fmt.Sprintf("added folder %q", fcfg.ID))
+			case !reflect.DeepEqual(fcfg, cur):
+				changes = append(changes, fmt.Sprintf("changed folder %q", fcfg.ID))
+			}
+			folders = append(folders, fcfg)
+		}
+		for _, fcfg := range to.Folders {
+			if _, ok := seen[fcfg.ID]; !ok {
+				changes = append(changes, fmt.Sprintf("removed folder %q", fcfg.ID))
+			}
+		}
+		to.Folders = folders
+	}
+
+	*cfg = to
+	return changes, nil
+}
+
+// UnmarshalFolderOnto decodes a folder on top of the given one, keeping the
+// values of fields not present in the data instead of resetting them to
+// the built-in defaults. The folder's devices are matched by ID.
+func UnmarshalFolderOnto(bs []byte, folder *FolderConfiguration) error {
+	cur := slices.Clone(folder.Devices)
+	// Bypass FolderConfiguration.UnmarshalJSON, which would reset the
+	// fields not given to their defaults.
+	type noDefaults FolderConfiguration
+	if err := decodeOver(bs, (*noDefaults)(folder)); err != nil {
+		return err
+	}
+	return mergeFolderDevices(bs, folder, cur)
+}
+
+// decodeOver decodes the JSON object into the struct pointed to by v,
+// setting only the fields given. Lists and maps that are given replace the
+// existing ones rather than being merged into them by position, which
+// would leave parts of one list entry on another.
+func decodeOver(bs []byte, v any) error {
+	if err := clearGiven(bs, reflect.ValueOf(v).Elem()); err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, v)
+}
+
+func clearGiven(bs []byte, v reflect.Value) error {
+	var given map[string]json.RawMessage
+	if err := json.Unmarshal(bs, &given); err != nil {
+		return err
+	}
+	t := v.Type()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		raw, ok := given[name]
+		if !ok {
+			continue
+		}
+		switch f := v.Field(i); f.Kind() {
+		case reflect.Slice, reflect.Map:
+			f.SetZero()
+		case reflect.Struct:
+			if len(raw) > 0 && raw[0] == '{' {
+				if err := clearGiven(raw, f); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// mergeFolderDevices matches the folder's devices, as given in the JSON
+// object, to the current ones by device ID, so that the fields not given
+// for a device keep the values they have for that same device.
+func mergeFolderDevices(bs []byte, fcfg *FolderConfiguration, cur []FolderDeviceConfiguration) error {
+	var given struct {
+		Devices []json.RawMessage `json:"devices"`
+	}
+	if err := json.Unmarshal(bs, &given); err != nil || given.Devices == nil {
+		return err
+	}
+	for i, dbs := range given.Devices {
+		idx := slices.IndexFunc(cur, func(d FolderDeviceConfiguration) bool {
+			return d.DeviceID == fcfg.Devices[i].DeviceID
+		})
+		if idx < 0 {
+			continue
+		}
+		dev := cur[idx]
+		dev.EncryptionSubtrees = slices.Clone(dev.EncryptionSubtrees)
+		if err := decodeOver(dbs, &dev); err != nil {
+			return err
+		}
+		fcfg.Devices[i] = dev
+	}
+	return nil
+}
+
+// ApplyDesiredStateFile reads the desired state from the given file and
+// applies it to the running config, logging what changed.
+func ApplyDesiredStateFile(w Wrapper, path string) error {
+	fd, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	desired, err := ReadDesiredState(fd)
+	fd.Close()
+	if err != nil {
+		return fmt.Errorf("reading desired config: %w", err)
+	}
+
+	var changes []string
+	var applyErr error
+	waiter, err := w.Modify(func(cfg *Configuration) {
+		changes, applyErr = desired.Apply(cfg, w.MyID())
+	})
+	if err != nil {
+		return err
+	}
+	if applyErr != nil {
+		return fmt.Errorf("applying desired config: %w", applyErr)
+	}
+	waiter.Wait()
+
+	if len(changes) == 0 {
+		slog.Info("Config matches desired state", "path", path)
+		return nil
+	}
+	for _, change := range changes {
+		slog.Info("Reconciled config with desired state", "path", path, "change", change)
+	}
+	return nil
+}
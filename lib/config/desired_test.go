@@ -0,0 +1,175 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestDesiredStateApply(t *testing.T) {
+	cfg := New(device1)
+	cfg.Options.MaxSendKbps = 100
+	cfg.Options.MaxRecvKbps = 200
+	cfg.SetDevice(DeviceConfiguration{DeviceID: device2, Name: "two"})
+	cfg.SetDevice(DeviceConfiguration{DeviceID: device3, Name: "three"})
+	cfg.SetFolder(FolderConfiguration{ID: "f1", Label: "one", Path: "/f1", RescanIntervalS: 60})
+	cfg.SetFolder(FolderConfiguration{ID: "f2", Path: "/f2"})
+	cfg.Defaults.Folder.RescanIntervalS = 1234
+
+	desired, err := ReadDesiredState(strings.NewReader(`{
+		"options": {"maxSendKbps": 50},
+		"devices": [{"deviceID": "` + device2.String() + `", "name": "renamed"}],
+		"folders": [
+			{"id": "f1", "label": "first"},
+			{"id": "f3", "path": "/f3"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := desired.Apply(&cfg, device1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"changed options",
+		"changed device " + device2.Short().String(),
+		"removed device " + device3.Short().String(),
+		`changed folder "f1"`,
+		`added folder "f3"`,
+		`removed folder "f2"`,
+	}
+	if !slices.Equal(changes, expected) {
+		t.Errorf("unexpected changes:\n%q\nexpected:\n%q", changes, expected)
+	}
+
+	if cfg.Options.MaxSendKbps != 50 || cfg.Options.MaxRecvKbps != 200 {
+		t.Error("options not merged", cfg.Options.MaxSendKbps, cfg.Options.MaxRecvKbps)
+	}
+	if _, _, ok := cfg.Device(device1); !ok {
+		t.Error("own device should be kept")
+	}
+	if dev, _, _ := cfg.Device(device2); dev.Name != "renamed" {
+		t.Error("device not updated", dev.Name)
+	}
+	if _, _, ok := cfg.Device(device3); ok {
+		t.Error("unlisted device should be removed")
+	}
+	if f, _, _ := cfg.Folder("f1"); f.Label != "first" || f.Path != "/f1" || f.RescanIntervalS != 60 {
+		t.Error("existing folder not merged", f.Label, f.Path, f.RescanIntervalS)
+	}
+	if f, _, ok := cfg.Folder("f3"); !ok || f.RescanIntervalS != 1234 {
+		t.Error("new folder should be based on defaults", f.RescanIntervalS)
+	}
+	if _, _, ok := cfg.Folder("f2"); ok {
+		t.Error("unlisted folder should be removed")
+	}
+
+	// Applying again changes nothing
+	changes, err = desired.Apply(&cfg, device1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Error("expected no changes, got", changes)
+	}
+}
+
+func TestDesiredStateFolderDevices(t *testing.T) {
+	cfg := New(device1)
+	cfg.SetFolder(FolderConfiguration{ID: "f1", Path: "/f1", Devices: []FolderDeviceConfiguration{
+		{DeviceID: device2, EncryptionPassword: "secret", IntroducedBy: device3},
+		{DeviceID: device3},
+	}})
+
+	// The devices are matched by ID, not position: device3 must not get
+	// the password of device2 that was listed first before.
+	desired, err := ReadDesiredState(strings.NewReader(`{
+		"folders": [{"id": "f1", "devices": [
+			{"deviceID": "` + device3.String() + `"},
+			{"deviceID": "` + device4.String() + `"}
+		]}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := desired.Apply(&cfg, device1); err != nil {
+		t.Fatal(err)
+	}
+	f, _, _ := cfg.Folder("f1")
+	if len(f.Devices) != 2 {
+		t.Fatalf("expected two devices, got %v", f.Devices)
+	}
+	for _, dev := range f.Devices {
+		if dev.EncryptionPassword != "" || dev.IntroducedBy != protocol.EmptyDeviceID {
+			t.Errorf("device %v inherited settings of another device: %+v", dev.DeviceID, dev)
+		}
+	}
+
+	// Fields not given for a listed device keep their values.
+	cfg.SetFolder(FolderConfiguration{ID: "f1", Path: "/f1", Devices: []FolderDeviceConfiguration{
+		{DeviceID: device2, EncryptionPassword: "secret"},
+	}})
+	desired, err = ReadDesiredState(strings.NewReader(`{
+		"folders": [{"id": "f1", "devices": [{"deviceID": "` + device2.String() + `"}]}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := desired.Apply(&cfg, device1); err != nil {
+		t.Fatal(err)
+	}
+	if f, _, _ := cfg.Folder("f1"); f.Devices[0].EncryptionPassword != "secret" {
+		t.Errorf("expected the password to be kept, got %q", f.Devices[0].EncryptionPassword)
+	}
+}
+
+func TestDesiredStateUnmanagedSections(t *testing.T) {
+	cfg := New(device1)
+	cfg.SetDevice(DeviceConfiguration{DeviceID: device2})
+	cfg.SetFolder(FolderConfiguration{ID: "f1", Path: "/f1"})
+
+	desired, err := ReadDesiredState(strings.NewReader(`{"options": {"maxSendKbps": 50}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := desired.Apply(&cfg, device1); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Devices) != 2 || len(cfg.Folders) != 1 {
+		t.Error("sections not in the desired state should be left alone")
+	}
+}
+
+func TestDesiredStateInvalid(t *testing.T) {
+	cases := []string{
+		`{"unknown": true}`,
+		`{"folders": [{"label": "no id"}]}`,
+		`{"folders": [{"id": "a"}, {"id": "a"}]}`,
+		`{"devices": [{"name": "no id"}]}`,
+		`{"options": {"maxSendKbps": "many"}}`,
+	}
+	for _, tc := range cases {
+		cfg := New(device1)
+		orig := cfg.Copy()
+		desired, err := ReadDesiredState(strings.NewReader(tc))
+		if err != nil {
+			continue
+		}
+		if _, err := desired.Apply(&cfg, device1); err == nil {
+			t.Errorf("%s: expected error", tc)
+		}
+		if len(cfg.Devices) != len(orig.Devices) || cfg.Options.MaxSendKbps != orig.Options.MaxSendKbps {
+			t.Errorf("%s: config changed despite error", tc)
+		}
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"log/slog"
 	"slices"
+	"time"
 
+	"github.com/syncthing/syncthing/internal/slogutil"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
 
@@ -27,6 +29,8 @@ type DeviceConfiguration struct {
 	IntroducedBy             protocol.DeviceID `json:"introducedBy" xml:"introducedBy,attr" nodefault:"true"`
 	Paused                   bool              `json:"paused" xml:"paused"`
 	AllowedNetworks          []string          `json:"allowedNetworks" xml:"allowedNetwork,omitempty"`
+	AllowedInterfaces        []string          `json:"allowedInterfaces" xml:"allowedInterface,omitempty"`
+	AllowedTimes             []string          `json:"allowedTimes" xml:"allowedTime,omitempty"`
 	AutoAcceptFolders        bool              `json:"autoAcceptFolders" xml:"autoAcceptFolders"`
 	MaxSendKbps              int               `json:"maxSendKbps" xml:"maxSendKbps"`
 	MaxRecvKbps              int               `json:"maxRecvKbps" xml:"maxRecvKbps"`
@@ -34,8 +38,14 @@ type DeviceConfiguration struct {
 	DeprecatedPendingFolders []ObservedFolder  `json:"-" xml:"pendingFolder,omitempty"` // Deprecated: Do not use.
 	MaxRequestKiB            int               `json:"maxRequestKiB" xml:"maxRequestKiB"`
 	Untrusted                bool              `json:"untrusted" xml:"untrusted"`
+	AllowRemoteManagement    bool              `json:"allowRemoteManagement" xml:"allowRemoteManagement"`
 	RemoteGUIPort            int               `json:"remoteGUIPort" xml:"remoteGUIPort"`
 	RawNumConnections        int               `json:"numConnections" xml:"numConnections"`
+	MonthlyQuotaMiB          int               `json:"monthlyQuotaMiB" xml:"monthlyQuotaMiB"`
+	QuotaAction              QuotaAction       `json:"quotaAction" xml:"quotaAction"`
+	// How the messages that are compressed get compressed; folders may
+	// override this and the compression setting.
+	CompressionAlgorithm CompressionAlgorithm `json:"compressionAlgorithm" xml:"compressionAlgorithm,attr,omitempty"`
 }
 
 func (cfg DeviceConfiguration) Copy() DeviceConfiguration {
@@ -44,6 +54,10 @@ func (cfg DeviceConfiguration) Copy() DeviceConfiguration {
 	copy(c.Addresses, cfg.Addresses)
 	c.AllowedNetworks = make([]string, len(cfg.AllowedNetworks))
 	copy(c.AllowedNetworks, cfg.AllowedNetworks)
+	c.AllowedInterfaces = make([]string, len(cfg.AllowedInterfaces))
+	copy(c.AllowedInterfaces, cfg.AllowedInterfaces)
+	c.AllowedTimes = make([]string, len(cfg.AllowedTimes))
+	copy(c.AllowedTimes, cfg.AllowedTimes)
 	c.IgnoredFolders = make([]ObservedFolder, len(cfg.IgnoredFolders))
 	copy(c.IgnoredFolders, cfg.IgnoredFolders)
 	return c
@@ -73,7 +87,40 @@ func (cfg *DeviceConfiguration) prepare(sharedFolders []string) {
 			slog.Warn("Device is both untrusted and auto-accepting folders, removing auto-accept flag", cfg.DeviceID.LogAttr())
 			cfg.AutoAcceptFolders = false
 		}
+		if cfg.AllowRemoteManagement {
+			slog.Warn("Device is both untrusted and allowed to manage this device, removing remote management flag", cfg.DeviceID.LogAttr())
+			cfg.AllowRemoteManagement = false
+		}
+	}
+
+	cfg.AllowedTimes = slices.DeleteFunc(cfg.AllowedTimes, func(s string) bool {
+		if _, err := ParseTimeWindow(s); err != nil {
+			slog.Warn("Ignoring invalid allowed time for device", cfg.DeviceID.LogAttr(), slogutil.Error(err))
+			return true
+		}
+		return false
+	})
+}
+
+// QuotaExceeded returns true if the device has a monthly quota and the
+// given usage exceeds it.
+func (cfg *DeviceConfiguration) QuotaExceeded(usedBytes int64) bool {
+	return cfg.MonthlyQuotaMiB > 0 && usedBytes >= int64(cfg.MonthlyQuotaMiB)<<20
+}
+
+// IsAllowedTime returns true if connections to the device are allowed at
+// the given time, i.e. there are no allowed times or one of them contains
+// it.
+func (cfg *DeviceConfiguration) IsAllowedTime(t time.Time) bool {
+	if len(cfg.AllowedTimes) == 0 {
+		return true
 	}
+	for _, s := range cfg.AllowedTimes {
+		if w, err := ParseTimeWindow(s); err == nil && w.Contains(t) {
+			return true
+		}
+	}
+	return false
 }
 
 func (cfg *DeviceConfiguration) NumConnections() int {
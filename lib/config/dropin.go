@@ -0,0 +1,195 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// A dropIn is a config fragment, holding folder and device elements in the
+// same format as config.xml:
+//
+//	<configuration>
+//	    <folder id="abcd-1234" path="/data/abcd">...</folder>
+//	    <device id="...">...</device>
+//	</configuration>
+type dropIn struct {
+	XMLName xml.Name              `xml:"configuration"`
+	Folders []FolderConfiguration `xml:"folder"`
+	Devices []DeviceConfiguration `xml:"device"`
+}
+
+// readDropIns reads the *.xml fragments in dir, in lexical order. A
+// missing directory is not an error.
+func readDropIns(dir string) ([]string, []dropIn, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	var names []string
+	var frags []dropIn
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasSuffix(entry.Name(), ".xml") {
+			continue
+		}
+		bs, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, nil, err
+		}
+		var frag dropIn
+		if err := xml.Unmarshal(bs, &frag); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		for _, fcfg := range frag.Folders {
+			if fcfg.ID == "" {
+				return nil, nil, fmt.Errorf("%s: folder without id", entry.Name())
+			}
+		}
+		for _, dev := range frag.Devices {
+			if dev.DeviceID == protocol.EmptyDeviceID {
+				return nil, nil, fmt.Errorf("%s: device without id", entry.Name())
+			}
+		}
+		names = append(names, entry.Name())
+		frags = append(frags, frag)
+	}
+	return names, frags, nil
+}
+
+// What the drop-ins merge into the running config is not written to the
+// main config. When saving, a folder or device from a drop-in is written
+// as it was before merging, or left out if the drop-in added it, for as
+// long as it's unchanged since. Removing a drop-in thus undoes it on the
+// next start. A folder or device changed after merging, for example in
+// the GUI, is saved as changed and becomes part of the main config.
+type dropInValues struct {
+	folders map[string]dropInValue[FolderConfiguration]
+	devices map[protocol.DeviceID]dropInValue[DeviceConfiguration]
+}
+
+type dropInValue[T any] struct {
+	merged   T  // as prepared after merging
+	original *T // nil if added by the drop-in
+}
+
+// MergeDropIns merges the fragments in dir into the running config. Each
+// folder or device replaces the one with the same ID, if any; when
+// several fragments define the same ID the last one wins. Entries are
+// only added or replaced, never removed.
+func MergeDropIns(w Wrapper, dir string) error {
+	names, frags, err := readDropIns(dir)
+	if err != nil {
+		return fmt.Errorf("reading config drop-ins: %w", err)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	waiter, err := w.Modify(func(cfg *Configuration) {
+		values := &dropInValues{
+			folders: make(map[string]dropInValue[FolderConfiguration]),
+			devices: make(map[protocol.DeviceID]dropInValue[DeviceConfiguration]),
+		}
+		for _, frag := range frags {
+			for _, fcfg := range frag.Folders {
+				if _, ok := values.folders[fcfg.ID]; ok {
+					continue
+				}
+				var val dropInValue[FolderConfiguration]
+				if orig, _, ok := cfg.Folder(fcfg.ID); ok {
+					val.original = &orig
+				}
+				values.folders[fcfg.ID] = val
+			}
+			for _, dev := range frag.Devices {
+				if _, ok := values.devices[dev.DeviceID]; ok {
+					continue
+				}
+				var val dropInValue[DeviceConfiguration]
+				if orig, _, ok := cfg.Device(dev.DeviceID); ok {
+					val.original = &orig
+				}
+				values.devices[dev.DeviceID] = val
+			}
+			cfg.SetFolders(frag.Folders)
+			cfg.SetDevices(frag.Devices)
+		}
+
+		// Remember the values as they'll be after preparing the config,
+		// to tell whether they've changed when saving.
+		prepared := cfg.Copy()
+		if err := prepared.prepare(w.MyID()); err != nil {
+			// The merge fails all the same.
+			return
+		}
+		for id, val := range values.folders {
+			val.merged, _, _ = prepared.Folder(id)
+			values.folders[id] = val
+		}
+		for id, val := range values.devices {
+			val.merged, _, _ = prepared.Device(id)
+			values.devices[id] = val
+		}
+		cfg.dropIns = values
+	})
+	if err != nil {
+		return fmt.Errorf("merging config drop-ins: %w", err)
+	}
+	waiter.Wait()
+
+	for i, name := range names {
+		slog.Info("Merged config drop-in", slogutil.FilePath(filepath.Join(dir, name)), "folders", len(frags[i].Folders), "devices", len(frags[i].Devices))
+	}
+	return nil
+}
+
+// withoutDropIns returns a copy of the config with what the drop-ins
+// merged undone, where unchanged since.
+func (cfg Configuration) withoutDropIns() Configuration {
+	if cfg.dropIns == nil {
+		return cfg
+	}
+	out := cfg.Copy()
+
+	out.Folders = out.Folders[:0]
+	for _, fcfg := range cfg.Folders {
+		val, ok := cfg.dropIns.folders[fcfg.ID]
+		switch {
+		case !ok || !reflect.DeepEqual(fcfg, val.merged):
+			out.Folders = append(out.Folders, fcfg.Copy())
+		case val.original != nil:
+			out.Folders = append(out.Folders, val.original.Copy())
+		}
+	}
+
+	out.Devices = out.Devices[:0]
+	for _, dev := range cfg.Devices {
+		val, ok := cfg.dropIns.devices[dev.DeviceID]
+		switch {
+		case !ok || !reflect.DeepEqual(dev, val.merged):
+			out.Devices = append(out.Devices, dev.Copy())
+		case val.original != nil:
+			out.Devices = append(out.Devices, val.original.Copy())
+		}
+	}
+
+	return out
+}
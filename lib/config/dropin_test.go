@@ -0,0 +1,175 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeDropIns(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("10-photos.xml", `<configuration>
+		<folder id="photos" label="Photos" path="/data/photos" rescanIntervalS="60"></folder>
+		<device id="`+device2.String()+`" name="laptop"></device>
+	</configuration>`)
+	write("20-override.xml", `<configuration>
+		<folder id="photos" label="Pictures" path="/data/photos"></folder>
+	</configuration>`)
+	write("README", "not a fragment")
+
+	cfg := New(device1)
+	cfg.SetFolder(FolderConfiguration{ID: "docs", Path: "/data/docs"})
+	w := wrap(filepath.Join(dir, "config.xml"), cfg, device1)
+	defer w.stop()
+
+	if err := MergeDropIns(w, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := w.Folder("docs"); !ok {
+		t.Error("existing folder should be kept")
+	}
+	photos, ok := w.Folder("photos")
+	if !ok {
+		t.Fatal("folder from drop-in missing")
+	}
+	if photos.Label != "Pictures" {
+		t.Errorf("later drop-in should win, got label %q", photos.Label)
+	}
+	if photos.RescanIntervalS != 3600 {
+		t.Errorf("folder should be replaced, not merged, got rescan interval %d", photos.RescanIntervalS)
+	}
+	if dev, ok := w.Device(device2); !ok || dev.Name != "laptop" {
+		t.Error("device from drop-in missing")
+	}
+}
+
+func TestMergeDropInsNotSaved(t *testing.T) {
+	dir := t.TempDir()
+	frag := `<configuration>
+		<folder id="photos" label="Photos" path="/data/photos"></folder>
+		<folder id="docs" label="Documents" path="/data/docs"></folder>
+		<device id="` + device2.String() + `" name="laptop"></device>
+	</configuration>`
+	if err := os.WriteFile(filepath.Join(dir, "10-frag.xml"), []byte(frag), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := New(device1)
+	cfg.SetFolder(FolderConfiguration{ID: "docs", Label: "Docs", Path: "/data/docs"})
+	path := filepath.Join(t.TempDir(), "config.xml")
+	w := wrap(path, cfg, device1)
+	defer w.stop()
+
+	if err := MergeDropIns(w, dir); err != nil {
+		t.Fatal(err)
+	}
+	if docs, _ := w.Folder("docs"); docs.Label != "Documents" {
+		t.Fatalf("expected drop-in to replace folder, got label %q", docs.Label)
+	}
+
+	// What the drop-in merged is saved as it was before.
+	saved := func() Configuration {
+		t.Helper()
+		if err := w.Save(); err != nil {
+			t.Fatal(err)
+		}
+		fd, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fd.Close()
+		cfg, _, err := ReadXML(fd, device1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+	cfg = saved()
+	if _, _, ok := cfg.Folder("photos"); ok {
+		t.Error("folder added by drop-in should not be saved")
+	}
+	if docs, _, _ := cfg.Folder("docs"); docs.Label != "Docs" {
+		t.Errorf("folder replaced by drop-in should be saved as before, got label %q", docs.Label)
+	}
+	if _, _, ok := cfg.Device(device2); ok {
+		t.Error("device added by drop-in should not be saved")
+	}
+
+	// Replacing the config with an equivalent one, as the GUI does when
+	// saving settings, doesn't count as a change.
+	bs, err := json.Marshal(w.RawCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repl, err := ReadJSON(bytes.NewReader(bs), device1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiter, err := w.Modify(func(cfg *Configuration) { *cfg = repl })
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiter.Wait()
+	cfg = saved()
+	if _, _, ok := cfg.Folder("photos"); ok {
+		t.Error("folder added by drop-in should not be saved after replacing the config")
+	}
+
+	// Once changed, it's part of the main config.
+	waiter, err = w.Modify(func(cfg *Configuration) {
+		photos, _, _ := cfg.Folder("photos")
+		photos.Label = "Pictures"
+		cfg.SetFolder(photos)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiter.Wait()
+	cfg = saved()
+	if photos, _, ok := cfg.Folder("photos"); !ok || photos.Label != "Pictures" {
+		t.Errorf("changed folder should be saved, got %v, %q", ok, photos.Label)
+	}
+}
+
+func TestMergeDropInsMissingDir(t *testing.T) {
+	w := wrap("/dev/null", New(device1), device1)
+	defer w.stop()
+
+	if err := MergeDropIns(w, filepath.Join(t.TempDir(), "config.d")); err != nil {
+		t.Error("missing directory should not be an error:", err)
+	}
+}
+
+func TestMergeDropInsInvalid(t *testing.T) {
+	cases := []string{
+		`<configuration><folder path="/no/id"></folder></configuration>`,
+		`<configuration><device name="no id"></device></configuration>`,
+		`<configuration><folder`,
+	}
+	for _, tc := range cases {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "bad.xml"), []byte(tc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		w := wrap("/dev/null", New(device1), device1)
+		if err := MergeDropIns(w, dir); err == nil {
+			t.Errorf("%s: expected error", tc)
+		}
+		w.stop()
+	}
+}
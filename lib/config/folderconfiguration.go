@@ -22,6 +22,7 @@ import (
 
 	"github.com/shirou/gopsutil/v4/disk"
 
+	"github.com/syncthing/syncthing/internal/slogutil"
 	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/fs"
 	"github.com/syncthing/syncthing/lib/protocol"
@@ -29,9 +30,10 @@ import (
 )
 
 var (
-	ErrPathNotDirectory = errors.New("folder path not a directory")
-	ErrPathMissing      = errors.New("folder path missing")
-	ErrMarkerMissing    = errors.New("folder marker missing (this indicates potential data loss, search docs/forum to get information about how to proceed)")
+	ErrPathNotDirectory  = errors.New("folder path not a directory")
+	ErrPathMissing       = errors.New("folder path missing")
+	ErrInsufficientSpace = errors.New("insufficient space")
+	ErrMarkerMissing     = errors.New("folder marker missing (this indicates potential data loss, search docs/forum to get information about how to proceed)")
 )
 
 const (
@@ -45,6 +47,80 @@ type FolderDeviceConfiguration struct {
 	DeviceID           protocol.DeviceID `json:"deviceID" xml:"id,attr"`
 	IntroducedBy       protocol.DeviceID `json:"introducedBy" xml:"introducedBy,attr"`
 	EncryptionPassword string            `json:"encryptionPassword" xml:"encryptionPassword"`
+	// The password being rotated away from. While set, the untrusted
+	// device still accepts it and its data is re-encrypted with the new
+	// password; it is cleared once that is complete.
+	PreviousEncryptionPassword string `json:"previousEncryptionPassword" xml:"previousEncryptionPassword"`
+	// Subtrees of the folder that are encrypted with a password of their
+	// own on the untrusted device, or not encrypted at all.
+	EncryptionSubtrees []FolderDeviceEncryptionSubtree `json:"encryptionSubtrees" xml:"encryptionSubtree"`
+	// When set, the folder is automatically unshared from the device at
+	// this time.
+	Expires time.Time `json:"expires,omitzero" xml:"expires,attr,omitempty"`
+	// The device only receives the folder. Changes it makes are refused,
+	// by us and by the other devices we announce this to.
+	ReadOnly bool `json:"readOnly" xml:"readOnly,attr,omitempty"`
+	// Data sent to the untrusted device is compressed before encryption
+	// where possible, so that it needs less disk, once the device has
+	// announced that it handles that for the folder. All trusted devices
+	// sharing the folder with it must support this, as they may get the
+	// data back from it.
+	CompressEncrypted bool `json:"compressEncrypted" xml:"compressEncrypted,attr,omitempty"`
+	// When set, only the items matching these patterns, in .stignore
+	// syntax, and everything below matching directories, are announced
+	// to and served to the device.
+	Filter []string `json:"filter" xml:"filter,omitempty"`
+	// Compression and CompressionAlgorithm override those of the device
+	// for the messages of the folder, such as when it holds media that
+	// is already compressed.
+	Compression          CompressionOverride  `json:"compression" xml:"compression,attr,omitempty"`
+	CompressionAlgorithm CompressionAlgorithm `json:"compressionAlgorithm" xml:"compressionAlgorithm,attr,omitempty"`
+}
+
+// A FolderDeviceEncryptionSubtree is a directory of the folder, and
+// everything below it, shared with an untrusted device using a different
+// password than the rest of the folder. An empty password means the
+// subtree is shared unencrypted, and with it the names of its parent
+// directories.
+type FolderDeviceEncryptionSubtree struct {
+	Path               string `json:"path" xml:"path,attr"`
+	EncryptionPassword string `json:"encryptionPassword" xml:"encryptionPassword"`
+}
+
+// RotatingEncryption returns true while the data on the untrusted device
+// is being re-encrypted from the previous to the current password.
+func (d FolderDeviceConfiguration) RotatingEncryption() bool {
+	return d.EncryptionPassword != "" && d.PreviousEncryptionPassword != "" && d.PreviousEncryptionPassword != d.EncryptionPassword
+}
+
+// Expired returns true if the share with the device has expired at the
+// given time.
+func (d FolderDeviceConfiguration) Expired(now time.Time) bool {
+	return !d.Expires.IsZero() && !now.Before(d.Expires)
+}
+
+// prepareEncryptionSubtrees cleans the subtree paths, dropping those that
+// don't denote a subtree or are duplicates. Subtrees only apply to
+// untrusted devices.
+func (d *FolderDeviceConfiguration) prepareEncryptionSubtrees() {
+	if d.EncryptionPassword == "" {
+		d.EncryptionSubtrees = nil
+		return
+	}
+	seen := make(map[string]struct{}, len(d.EncryptionSubtrees))
+	subtrees := d.EncryptionSubtrees[:0]
+	for _, st := range d.EncryptionSubtrees {
+		st.Path = strings.Trim(path.Clean(filepath.ToSlash(st.Path)), "/")
+		if st.Path == "" || st.Path == "." || st.Path == ".." || strings.HasPrefix(st.Path, "../") {
+			continue
+		}
+		if _, ok := seen[st.Path]; ok {
+			continue
+		}
+		seen[st.Path] = struct{}{}
+		subtrees = append(subtrees, st)
+	}
+	d.EncryptionSubtrees = subtrees
 }
 
 type FolderConfiguration struct {
@@ -71,6 +147,7 @@ type FolderConfiguration struct {
 	PullerPauseS            int                         `json:"pullerPauseS" xml:"pullerPauseS"`
 	PullerDelayS            float64                     `json:"pullerDelayS" xml:"pullerDelayS" default:"1"`
 	MaxConflicts            int                         `json:"maxConflicts" xml:"maxConflicts" default:"10"`
+	MaxConflictAgeS         int                         `json:"maxConflictAgeS" xml:"maxConflictAgeS"`
 	DisableSparseFiles      bool                        `json:"disableSparseFiles" xml:"disableSparseFiles"`
 	Paused                  bool                        `json:"paused" xml:"paused"`
 	MarkerName              string                      `json:"markerName" xml:"markerName"`
@@ -87,6 +164,67 @@ type FolderConfiguration struct {
 	SyncXattrs              bool                        `json:"syncXattrs" xml:"syncXattrs"`
 	SendXattrs              bool                        `json:"sendXattrs" xml:"sendXattrs"`
 	XattrFilter             XattrFilter                 `json:"xattrFilter" xml:"xattrFilter"`
+	FilesystemWrappers      []string                    `json:"filesystemWrappers" xml:"filesystemWrapper"`
+	MemoryBudgetMiB         int                         `json:"memoryBudgetMiB" xml:"memoryBudgetMiB"`
+	Priority                int                         `json:"priority" xml:"priority"`
+	RequestCacheMiB         int                         `json:"requestCacheMiB" xml:"requestCacheMiB"`
+	Alarms                  FolderAlarms                `json:"alarms" xml:"alarms"`
+	RemoteApproval          RemoteApproval              `json:"remoteApproval" xml:"remoteApproval"`
+	// Store names that are invalid on Windows under valid names that map
+	// back to the original, instead of failing to sync them.
+	SanitizeFilenames bool `json:"sanitizeFilenames" xml:"sanitizeFilenames"`
+	// Tell the devices we share the folder with, other than untrusted
+	// ones, where we keep it, so that they can flag overlapping paths.
+	AdvertisePath bool `json:"advertisePath" xml:"advertisePath"`
+	// Stop serving data to devices that announce they don't have enough
+	// disk space left for what they need of the folder.
+	PauseSendingToFullDevices bool `json:"pauseSendingToFullDevices" xml:"pauseSendingToFullDevices"`
+	// Fetch blocks we don't have from other devices when asked for them by
+	// a trusted device, so that devices that can't connect to each other
+	// still sync through us.
+	ForwardRequests bool `json:"forwardRequests" xml:"forwardRequests"`
+	// Received files are moved to this directory outside the folder, while
+	// the folder stays in sync as if they were still there. Receive-only
+	// folders only.
+	MoveReceivedTo string `json:"moveReceivedTo" xml:"moveReceivedTo,omitempty"`
+	// Rely on the watcher alone after the initial scan, without periodic
+	// rescans. The folder is then only scanned in full on request or when
+	// the watcher may have missed changes. Needs the watcher enabled.
+	FSWatcherOnly bool `json:"fsWatcherOnly" xml:"fsWatcherOnly"`
+	// The most directories to watch, beyond which the least recently
+	// modified subtrees are left to rescans. Zero is no limit other than
+	// the system's.
+	FSWatcherMaxWatches int `json:"fsWatcherMaxWatches" xml:"fsWatcherMaxWatches"`
+	// Files matching these patterns, in .stignore syntax, are pulled
+	// before or after the others, those matching earlier patterns first.
+	// The pull order applies among files of the same priority.
+	PullFirst []string `json:"pullFirst" xml:"pullFirst"`
+	PullLast  []string `json:"pullLast" xml:"pullLast"`
+	// A local directory holding a copy of the folder's data, such as an
+	// older backup, that blocks are copied from when found there rather
+	// than requested from other devices. Useful for the initial sync.
+	SeedPath string `json:"seedPath" xml:"seedPath"`
+	// How ownership and permissions of synced items translate to this
+	// system, when syncing between systems with different users.
+	OwnershipMapping OwnershipMapping `json:"ownershipMapping" xml:"ownershipMapping"`
+	// Send and apply POSIX access and default ACLs, translating the users
+	// and groups of named entries by name where possible. Where ACLs can't
+	// be set, items keep only their permission bits.
+	SyncPosixACLs bool `json:"syncPosixACLs" xml:"syncPosixACLs"`
+	SendPosixACLs bool `json:"sendPosixACLs" xml:"sendPosixACLs"`
+	// Send and apply the NTFS alternate data streams of items, for folders
+	// shared between Windows devices, within the limits of the filter.
+	SyncAlternateStreams  bool                  `json:"syncAlternateStreams" xml:"syncAlternateStreams"`
+	SendAlternateStreams  bool                  `json:"sendAlternateStreams" xml:"sendAlternateStreams"`
+	AlternateStreamFilter AlternateStreamFilter `json:"alternateStreamFilter" xml:"alternateStreamFilter"`
+	// Time windows, as for the allowed times of devices, during which the
+	// folder syncs. The folder is paused when one ends and resumed when the
+	// next begins; pausing or resuming it by hand lasts until then.
+	ActiveTimes []string `json:"activeTimes" xml:"activeTime,omitempty"`
+	// Give files that keep growing or changing often smaller blocks, and
+	// files that had long been static when first seen larger ones, than
+	// their size alone would get them.
+	AdaptiveBlockSize bool `json:"adaptiveBlockSize" xml:"adaptiveBlockSize"`
 	// Legacy deprecated
 	DeprecatedReadOnly       bool    `json:"-" xml:"ro,attr,omitempty"`        // Deprecated: Do not use.
 	DeprecatedMinDiskFreePct float64 `json:"-" xml:"minDiskFreePct,omitempty"` // Deprecated: Do not use.
@@ -111,11 +249,67 @@ type XattrFilterEntry struct {
 	Permit bool   `json:"permit" xml:"permit,attr"`
 }
 
+// AlternateStreamFilter skips alternate data streams with names matching
+// any of the patterns, case insensitively, besides those Windows and common
+// tools keep about files of their own accord. Streams over the size limits
+// are skipped as well.
+type AlternateStreamFilter struct {
+	Skip               []string `json:"skip" xml:"skip"`
+	MaxSingleEntrySize int      `json:"maxSingleEntrySize" xml:"maxSingleEntrySize" default:"65536"`
+	MaxTotalSize       int      `json:"maxTotalSize" xml:"maxTotalSize" default:"262144"`
+}
+
+// builtinSkippedStreams are alternate data streams that only make sense
+// on the device where they were created: download origins, SmartScreen
+// verdicts, thumbnail caches and the like.
+var builtinSkippedStreams = []string{
+	"zone.identifier",
+	"smartscreen",
+	"encryptable",
+	"{4c8cc155-6c1e-11d1-8e41-00c04fb9386d}",
+	"com.dropbox.attr*",
+	"ms-properties",
+}
+
+// Folder alarms are raised when a folder grows beyond a limit, or when
+// more files than expected are deleted in a short time, as a guard against
+// runaway scripts and accidental mass operations. Zero means no limit. An
+// alarm is an event, and pauses the folder if so configured.
+type FolderAlarms struct {
+	MaxDeletes int  `json:"maxDeletes" xml:"maxDeletes"` // files deleted locally or by another device within five minutes
+	MaxFiles   int  `json:"maxFiles" xml:"maxFiles"`
+	MaxSize    Size `json:"maxSize" xml:"maxSize"` // global size of the folder, not a percentage
+	Pause      bool `json:"pause" xml:"pause"`
+}
+
+// Enabled returns true if any alarm is set.
+func (a FolderAlarms) Enabled() bool {
+	return a.MaxDeletes > 0 || a.MaxFiles > 0 || a.MaxSize.Value > 0
+}
+
+// Remote changes affecting more files, or deleting more of the local data,
+// than the limits within five minutes are not pulled until approved. Zero
+// means no limit.
+type RemoteApproval struct {
+	MaxFiles     int `json:"maxFiles" xml:"maxFiles"`
+	MaxDeletePct int `json:"maxDeletePct" xml:"maxDeletePct"`
+}
+
+// Enabled returns true if any limit is set.
+func (a RemoteApproval) Enabled() bool {
+	return a.MaxFiles > 0 || a.MaxDeletePct > 0
+}
+
 func (f FolderConfiguration) Copy() FolderConfiguration {
 	c := f
 	c.Devices = make([]FolderDeviceConfiguration, len(f.Devices))
 	copy(c.Devices, f.Devices)
+	for i := range c.Devices {
+		c.Devices[i].EncryptionSubtrees = slices.Clone(f.Devices[i].EncryptionSubtrees)
+	}
 	c.Versioning = f.Versioning.Copy()
+	c.FilesystemWrappers = slices.Clone(f.FilesystemWrappers)
+	c.ActiveTimes = slices.Clone(f.ActiveTimes)
 	return c
 }
 
@@ -132,10 +326,26 @@ func (f FolderConfiguration) Filesystem(extraOpts ...fs.Option) fs.Filesystem {
 	if !f.CaseSensitiveFS {
 		opts = append(opts, new(fs.OptionDetectCaseConflicts))
 	}
+	if f.SanitizeFilenames {
+		opts = append(opts, new(fs.OptionSanitizeNames))
+	}
+	if f.FilesystemType == FilesystemTypeBasic && f.WatcherOnly() {
+		// Nothing else would pick up what the watcher missed.
+		opts = append(opts, new(fs.OptionRescanAllOnOverflow))
+	}
+	for _, name := range f.FilesystemWrappers {
+		opts = append(opts, fs.NewInterceptOption(name))
+	}
 	opts = append(opts, extraOpts...)
 	return fs.NewFilesystem(f.FilesystemType.ToFS(), f.Path, opts...)
 }
 
+// WatcherOnly returns true if changes are picked up by the watcher alone,
+// without periodic rescans.
+func (f FolderConfiguration) WatcherOnly() bool {
+	return f.FSWatcherEnabled && f.FSWatcherOnly
+}
+
 func (f FolderConfiguration) ModTimeWindow() time.Duration {
 	dur := time.Duration(f.RawModTimeWindowS) * time.Second
 	if f.RawModTimeWindowS < 1 && build.IsAndroid {
@@ -294,6 +504,9 @@ func (f *FolderConfiguration) prepare(myID protocol.DeviceID, existingDevices ma
 	f.Devices = ensureNoDuplicateFolderDevices(f.Devices)
 	f.Devices = ensureDevicePresent(f.Devices, myID)
 	f.Devices = ensureNoUntrustedTrustingSharing(f, f.Devices, existingDevices)
+	for i := range f.Devices {
+		f.Devices[i].prepareEncryptionSubtrees()
+	}
 
 	slices.SortFunc(f.Devices, func(a, b FolderDeviceConfiguration) int {
 		return a.DeviceID.Compare(b.DeviceID)
@@ -331,6 +544,42 @@ func (f *FolderConfiguration) prepare(myID protocol.DeviceID, existingDevices ma
 	if f.Type == FolderTypeReceiveEncrypted {
 		f.IgnorePerms = true
 	}
+
+	if f.MoveReceivedTo != "" && f.Type != FolderTypeReceiveOnly {
+		slog.Warn("Ignoring move of received files, the folder is not receive-only", f.LogAttr())
+		f.MoveReceivedTo = ""
+	}
+
+	if _, err := parsePermissionMask(f.OwnershipMapping.ClearPermissions); err != nil {
+		slog.Warn("Ignoring invalid permission mask", f.LogAttr(), slogutil.Error(err))
+		f.OwnershipMapping.ClearPermissions = ""
+	}
+	if _, err := parsePermissionMask(f.OwnershipMapping.SetPermissions); err != nil {
+		slog.Warn("Ignoring invalid permission mask", f.LogAttr(), slogutil.Error(err))
+		f.OwnershipMapping.SetPermissions = ""
+	}
+
+	f.ActiveTimes = slices.DeleteFunc(f.ActiveTimes, func(s string) bool {
+		if _, err := ParseTimeWindow(s); err != nil {
+			slog.Warn("Ignoring invalid active time for folder", f.LogAttr(), slogutil.Error(err))
+			return true
+		}
+		return false
+	})
+}
+
+// IsActiveTime returns true if the folder is scheduled to sync at the given
+// time, i.e. there are no active times or one of them contains it.
+func (f FolderConfiguration) IsActiveTime(t time.Time) bool {
+	if len(f.ActiveTimes) == 0 {
+		return true
+	}
+	for _, s := range f.ActiveTimes {
+		if w, err := ParseTimeWindow(s); err == nil && w.Contains(t) {
+			return true
+		}
+	}
+	return false
 }
 
 // RequiresRestartOnly returns a copy with only the attributes that require
@@ -360,6 +609,28 @@ func (f *FolderConfiguration) Device(device protocol.DeviceID) (FolderDeviceConf
 	return FolderDeviceConfiguration{}, false
 }
 
+// RotateEncryptionPassword starts re-encrypting the data on the untrusted
+// device with the given password.
+func (f *FolderConfiguration) RotateEncryptionPassword(device protocol.DeviceID, password string) error {
+	for i, dev := range f.Devices {
+		if dev.DeviceID != device {
+			continue
+		}
+		switch {
+		case dev.EncryptionPassword == "":
+			return fmt.Errorf("folder %v is not shared encrypted with %v", f.Description(), device.Short())
+		case dev.RotatingEncryption():
+			return fmt.Errorf("encryption password of folder %v for %v is already being rotated", f.Description(), device.Short())
+		case password == "" || password == dev.EncryptionPassword:
+			return errors.New("new encryption password must be set and differ from the current one")
+		}
+		f.Devices[i].PreviousEncryptionPassword = dev.EncryptionPassword
+		f.Devices[i].EncryptionPassword = password
+		return nil
+	}
+	return fmt.Errorf("folder %v is not shared with %v", f.Description(), device.Short())
+}
+
 func (f *FolderConfiguration) SharedWith(device protocol.DeviceID) bool {
 	_, ok := f.Device(device)
 	return ok
@@ -376,7 +647,7 @@ func (f *FolderConfiguration) CheckAvailableSpace(req uint64) error {
 		return nil //nolint: nilerr
 	}
 	if err := checkAvailableSpace(req, f.MinDiskFree, usage); err != nil {
-		return fmt.Errorf("insufficient space in folder %v (%v): %w", f.Description(), fs.URI(), err)
+		return fmt.Errorf("%w in folder %v (%v): %w", ErrInsufficientSpace, f.Description(), fs.URI(), err)
 	}
 	return nil
 }
@@ -402,6 +673,29 @@ func (f XattrFilter) GetMaxTotalSize() int {
 	return f.MaxTotalSize
 }
 
+func (f AlternateStreamFilter) Permit(s string) bool {
+	s = strings.ToLower(s)
+	for _, pat := range builtinSkippedStreams {
+		if ok, _ := path.Match(pat, s); ok {
+			return false
+		}
+	}
+	for _, pat := range f.Skip {
+		if ok, _ := path.Match(strings.ToLower(pat), s); ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (f AlternateStreamFilter) GetMaxSingleEntrySize() int {
+	return f.MaxSingleEntrySize
+}
+
+func (f AlternateStreamFilter) GetMaxTotalSize() int {
+	return f.MaxTotalSize
+}
+
 func (f *FolderConfiguration) UnmarshalJSON(data []byte) error {
 	structutil.SetDefaults(f)
 
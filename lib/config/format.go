@@ -0,0 +1,166 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// The format of a config file is selected by its extension: ".json" for
+// JSON, ".yaml" or ".yml" for YAML, and XML for anything else. The JSON
+// format is the one used by the REST API, and the YAML format is the same
+// structure in YAML syntax, except that both also contain the TOTP
+// secrets, which the REST API never shows. All formats go through the same
+// defaults, references to secrets and validation.
+type fileFormat int
+
+const (
+	formatXML fileFormat = iota
+	formatJSON
+	formatYAML
+)
+
+func formatForPath(path string) fileFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return formatJSON
+	case ".yaml", ".yml":
+		return formatYAML
+	default:
+		return formatXML
+	}
+}
+
+// readFormat reads a config in the format given by the path.
+func readFormat(r io.Reader, path string, myID protocol.DeviceID) (Configuration, int, error) {
+	switch formatForPath(path) {
+	case formatJSON:
+		bs, err := io.ReadAll(r)
+		if err != nil {
+			return Configuration{}, 0, err
+		}
+		return readJSONFile(bs, myID)
+	case formatYAML:
+		return ReadYAML(r, myID)
+	default:
+		return ReadXML(r, myID)
+	}
+}
+
+// writeFormat writes the config in the format given by the path.
+func (cfg *Configuration) writeFormat(w io.Writer, path string) error {
+	switch formatForPath(path) {
+	case formatJSON:
+		return cfg.WriteJSON(w)
+	case formatYAML:
+		return cfg.WriteYAML(w)
+	default:
+		return cfg.WriteXML(w)
+	}
+}
+
+// ReadYAML reads a config in YAML format, returning it along with the
+// version it was written in.
+func ReadYAML(r io.Reader, myID protocol.DeviceID) (Configuration, int, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return Configuration{}, 0, err
+	}
+	bs, err = yaml.YAMLToJSON(bs)
+	if err != nil {
+		return Configuration{}, 0, err
+	}
+	return readJSONFile(bs, myID)
+}
+
+// readJSONFile is ReadJSON for config files, which may contain references
+// to secrets and may need migrating.
+func readJSONFile(bs []byte, myID protocol.DeviceID) (Configuration, int, error) {
+	cfg, err := decodeJSON(bs)
+	if err != nil {
+		return Configuration{}, 0, err
+	}
+	var file struct {
+		GUI totpSecrets `json:"gui"`
+	}
+	if err := json.Unmarshal(bs, &file); err != nil {
+		return Configuration{}, 0, err
+	}
+	cfg.GUI.TOTPSecret = file.GUI.TOTPSecret
+	cfg.GUI.TOTPRecoveryCodes = file.GUI.TOTPRecoveryCodes
+
+	originalVersion := cfg.Version
+
+	if err := cfg.resolveSecretRefs(); err != nil {
+		return Configuration{}, originalVersion, err
+	}
+	if err := cfg.prepare(myID); err != nil {
+		return Configuration{}, originalVersion, err
+	}
+	cfg.recordSecretValues()
+	return cfg, originalVersion, nil
+}
+
+// fileConfiguration is the configuration as written to JSON and YAML
+// files.
+type fileConfiguration struct {
+	Configuration
+	GUI fileGUIConfiguration `json:"gui"`
+}
+
+type fileGUIConfiguration struct {
+	guiNoCustomMarshal
+	totpSecrets
+}
+
+// guiNoCustomMarshal is GUIConfiguration without its MarshalJSON, which
+// would otherwise be promoted to fileGUIConfiguration.
+type guiNoCustomMarshal GUIConfiguration
+
+type totpSecrets struct {
+	TOTPSecret        string   `json:"totpSecret,omitempty"`
+	TOTPRecoveryCodes []string `json:"totpRecoveryCodes,omitempty"`
+}
+
+func newFileConfiguration(cfg Configuration) fileConfiguration {
+	return fileConfiguration{
+		Configuration: cfg,
+		GUI: fileGUIConfiguration{
+			guiNoCustomMarshal: guiNoCustomMarshal(cfg.GUI),
+			totpSecrets: totpSecrets{
+				TOTPSecret:        cfg.GUI.TOTPSecret,
+				TOTPRecoveryCodes: cfg.GUI.TOTPRecoveryCodes,
+			},
+		},
+	}
+}
+
+func (cfg *Configuration) WriteJSON(w io.Writer) error {
+	bs, err := json.MarshalIndent(newFileConfiguration(cfg.withoutDropIns().withSecretRefs()), "", "    ")
+	if err != nil {
+		return err
+	}
+	bs = append(bs, '\n')
+	_, err = w.Write(bs)
+	return err
+}
+
+func (cfg *Configuration) WriteYAML(w io.Writer) error {
+	bs, err := yaml.Marshal(newFileConfiguration(cfg.withoutDropIns().withSecretRefs()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bs)
+	return err
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d4l3k/messagediff"
+
+	"github.com/syncthing/syncthing/lib/events"
+)
+
+func TestFormatRoundTrip(t *testing.T) {
+	orig := New(device1)
+	orig.SetDevice(DeviceConfiguration{DeviceID: device2, Name: "laptop", Addresses: []string{"dynamic"}})
+	fcfg := orig.Defaults.Folder.Copy()
+	fcfg.ID, fcfg.Label, fcfg.Path = "1234", "yes", "/data/docs"
+	fcfg.RescanIntervalS = 60
+	fcfg.Devices = append(fcfg.Devices, FolderDeviceConfiguration{DeviceID: device2})
+	orig.SetFolder(fcfg)
+	orig.Options.MaxSendKbps = 100
+	orig.GUI.TOTPSecret = "JBSWY3DPEHPK3PXP"
+	orig.GUI.TOTPRecoveryCodes = []string{"$2a$10$abc", "$2a$10$def"}
+	if err := orig.prepare(device1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The REST API never shows the TOTP secret.
+	if bs, err := json.Marshal(orig); err != nil || strings.Contains(string(bs), orig.GUI.TOTPSecret) {
+		t.Errorf("expected the TOTP secret not to be part of the API JSON, got %s, %v", bs, err)
+	}
+
+	for _, name := range []string{"config.xml", "config.json", "config.yaml", "config.yml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			w := Wrap(path, orig, device1, events.NoopLogger)
+			if err := w.Save(); err != nil {
+				t.Fatal(err)
+			}
+			if name != "config.xml" {
+				bs, _ := os.ReadFile(path)
+				if strings.Contains(string(bs), "<configuration") {
+					t.Fatal("expected non-XML file")
+				}
+				if !strings.Contains(string(bs), orig.GUI.TOTPSecret) {
+					t.Error("expected the TOTP secret to be saved")
+				}
+			}
+
+			loaded, version, err := Load(path, device1, events.NoopLogger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != CurrentVersion {
+				t.Errorf("expected version %d, got %d", CurrentVersion, version)
+			}
+			if diff, equal := messagediff.PrettyDiff(orig, loaded.RawCopy()); !equal {
+				t.Errorf("config differs after round trip:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatYAML(t *testing.T) {
+	t.Setenv("ST_TEST_APIKEY", "abc123")
+	input := `
+version: 51
+folders:
+  - id: docs
+    path: /data/docs
+    fsWatcherEnabled: false
+devices:
+  - deviceID: ` + device2.String() + `
+    name: laptop
+gui:
+  apiKey: env:${ST_TEST_APIKEY}
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w, _, err := Load(path, device1, events.NoopLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fcfg, ok := w.Folder("docs")
+	if !ok {
+		t.Fatal("folder missing")
+	}
+	if fcfg.FSWatcherEnabled || fcfg.RescanIntervalS != 3600 {
+		t.Errorf("folder should have the given values and defaults otherwise: %+v", fcfg)
+	}
+	if _, ok := w.Device(device1); !ok {
+		t.Error("own device should be added, as for XML")
+	}
+	if w.GUI().APIKey != "abc123" {
+		t.Errorf("reference not resolved: %q", w.GUI().APIKey)
+	}
+
+	if err := w.Save(); err != nil {
+		t.Fatal(err)
+	}
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bs), "apiKey: env:${ST_TEST_APIKEY}") {
+		t.Error("reference should be kept when saving")
+	}
+}
+
+func TestFormatInvalid(t *testing.T) {
+	cases := map[string]string{
+		"config.json": `{"devices": [{"deviceID": "nope"}]}`,
+		"config.yaml": "folders: [",
+		"config.yml":  "version: 51\nfolders:\n  - path: /no/id\n",
+	}
+	for name, input := range cases {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := Load(path, device1, events.NoopLogger); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
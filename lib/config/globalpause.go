@@ -0,0 +1,34 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import "time"
+
+// Well known originators of a global pause. Others, such as the name of
+// an embedding app's policy, are fine as well.
+const (
+	PauseOriginatorUser    = "user"
+	PauseOriginatorAPI     = "api"
+	PauseOriginatorBattery = "battery"
+)
+
+// A GlobalPause stops all syncing, regardless of the paused state of each
+// folder and device: no connections are made or accepted, and folders are
+// only scanned on request. It records why it was paused and by whom, and
+// ends by itself at Until unless that is zero.
+type GlobalPause struct {
+	Paused     bool      `json:"paused" xml:"paused,attr"`
+	Reason     string    `json:"reason" xml:"reason,attr,omitempty"`
+	Originator string    `json:"originator" xml:"originator,attr,omitempty"`
+	Since      time.Time `json:"since" xml:"since,attr,omitempty"`
+	Until      time.Time `json:"until" xml:"until,attr,omitempty"`
+}
+
+// Active returns true if syncing is paused at the given time.
+func (p GlobalPause) Active(now time.Time) bool {
+	return p.Paused && (p.Until.IsZero() || now.Before(p.Until))
+}
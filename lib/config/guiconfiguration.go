@@ -7,9 +7,11 @@
 package config
 
 import (
+	"encoding/json"
 	"net/url"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -33,6 +35,24 @@ type GUIConfiguration struct {
 	InsecureSkipHostCheck     bool     `json:"insecureSkipHostcheck" xml:"insecureSkipHostcheck,omitempty"`
 	InsecureAllowFrameLoading bool     `json:"insecureAllowFrameLoading" xml:"insecureAllowFrameLoading,omitempty"`
 	SendBasicAuthPrompt       bool     `json:"sendBasicAuthPrompt" xml:"sendBasicAuthPrompt,attr"`
+	ReadOnly                  bool     `json:"readOnly" xml:"readOnly,omitempty"`
+	// With a TOTP secret set, logging in requires a code from an
+	// authenticator app or one of the recovery codes, stored as bcrypt
+	// hashes, in addition to the password. Basic auth is then not accepted.
+	// Neither is part of the JSON used by the REST API, which shows
+	// "totpEnabled" instead; they are only changed by enrolling or
+	// resetting. JSON and YAML config files do contain them, see
+	// format.go.
+	TOTPSecret        string   `json:"-" xml:"totpSecret,omitempty"`
+	TOTPRecoveryCodes []string `json:"-" xml:"totpRecoveryCode,omitempty"`
+}
+
+func (c GUIConfiguration) MarshalJSON() ([]byte, error) {
+	type noCustomMarshal GUIConfiguration
+	return json.Marshal(struct {
+		noCustomMarshal
+		TOTPEnabled bool `json:"totpEnabled"`
+	}{noCustomMarshal(c), c.TOTPEnabled()})
 }
 
 func (c GUIConfiguration) IsAuthEnabled() bool {
@@ -40,6 +60,15 @@ func (c GUIConfiguration) IsAuthEnabled() bool {
 	return c.AuthMode == AuthModeLDAP || (len(c.User) > 0 && len(c.Password) > 0)
 }
 
+// IsReadOnly returns whether the API refuses all requests that would change
+// anything, whoever makes them.
+func (c GUIConfiguration) IsReadOnly() bool {
+	if override, err := strconv.ParseBool(os.Getenv("STGUIREADONLY")); err == nil {
+		return override || c.ReadOnly
+	}
+	return c.ReadOnly
+}
+
 func (GUIConfiguration) IsOverridden() bool {
 	return os.Getenv("STGUIADDRESS") != ""
 }
@@ -172,6 +201,52 @@ func (c GUIConfiguration) IsValidAPIKey(apiKey string) bool {
 	}
 }
 
+const totpRecoveryCodes = 10
+
+// TOTPEnabled returns true if logging in requires a second factor.
+func (c GUIConfiguration) TOTPEnabled() bool {
+	return c.TOTPSecret != ""
+}
+
+// EnrollTOTP sets up the TOTP secret, which the caller must have verified
+// the user has, and new recovery codes, replacing any previous ones. The
+// recovery codes are returned in plain text, to be shown once; only their
+// hashes are stored.
+func (c *GUIConfiguration) EnrollTOTP(secret string) (recoveryCodes []string, err error) {
+	hashes := make([]string, 0, totpRecoveryCodes)
+	for range totpRecoveryCodes {
+		code := strings.ToLower(rand.String(5) + "-" + rand.String(5))
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		recoveryCodes = append(recoveryCodes, code)
+		hashes = append(hashes, string(hash))
+	}
+	c.TOTPSecret = secret
+	c.TOTPRecoveryCodes = hashes
+	return recoveryCodes, nil
+}
+
+// ResetTOTP removes the second factor.
+func (c *GUIConfiguration) ResetTOTP() {
+	c.TOTPSecret = ""
+	c.TOTPRecoveryCodes = nil
+}
+
+// UseTOTPRecoveryCode returns true if the code is one of the recovery
+// codes, removing it so that it can't be used again.
+func (c *GUIConfiguration) UseTOTPRecoveryCode(code string) bool {
+	code = strings.ToLower(strings.TrimSpace(code))
+	for i, hash := range c.TOTPRecoveryCodes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) == nil {
+			c.TOTPRecoveryCodes = slices.Delete(slices.Clone(c.TOTPRecoveryCodes), i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
 func (c *GUIConfiguration) prepare() {
 	if c.APIKey == "" {
 		c.APIKey = rand.String(32)
@@ -179,5 +254,6 @@ func (c *GUIConfiguration) prepare() {
 }
 
 func (c GUIConfiguration) Copy() GUIConfiguration {
+	c.TOTPRecoveryCodes = slices.Clone(c.TOTPRecoveryCodes)
 	return c
 }
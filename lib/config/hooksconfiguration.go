@@ -0,0 +1,74 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// A HookEvent is a point in the sync lifecycle at which hook commands are
+// run.
+type HookEvent string
+
+const (
+	HookFolderSyncStarted  HookEvent = "folderSyncStarted"
+	HookFolderSyncFinished HookEvent = "folderSyncFinished"
+	HookConflict           HookEvent = "conflict"
+	HookFolderError        HookEvent = "folderError"
+	HookDeviceConnected    HookEvent = "deviceConnected"
+)
+
+var hookEvents = []HookEvent{HookFolderSyncStarted, HookFolderSyncFinished, HookConflict, HookFolderError, HookDeviceConnected}
+
+const defaultHookTimeoutS = 60
+
+type HooksConfiguration struct {
+	// The maximum number of hook commands running at the same time.
+	MaxConcurrent int                 `json:"maxConcurrent" xml:"maxConcurrent,attr" default:"4"`
+	Hooks         []HookConfiguration `json:"hooks" xml:"hook"`
+}
+
+// A HookConfiguration is a command to run on an event. The command is
+// split into words like a shell command line, but is not run by a shell.
+// It gets the event as JSON on standard input, and the most useful parts
+// of it in ST_* environment variables.
+type HookConfiguration struct {
+	Event   HookEvent `json:"event" xml:"event,attr"`
+	Command string    `json:"command" xml:"command"`
+	// Only run for events of the folder with this ID, when set.
+	Folder string `json:"folder" xml:"folder,attr,omitempty"`
+	// The command is killed when still running after this long.
+	TimeoutS int `json:"timeoutS" xml:"timeoutS,attr" default:"60"`
+}
+
+func (c HooksConfiguration) Copy() HooksConfiguration {
+	c.Hooks = slices.Clone(c.Hooks)
+	return c
+}
+
+func (c *HooksConfiguration) prepare() {
+	if c.MaxConcurrent < 1 {
+		c.MaxConcurrent = 1
+	}
+	c.Hooks = slices.DeleteFunc(c.Hooks, func(h HookConfiguration) bool {
+		if !slices.Contains(hookEvents, h.Event) {
+			slog.Warn("Ignoring hook for unknown event", "event", h.Event)
+			return true
+		}
+		if h.Command == "" {
+			slog.Warn("Ignoring hook without a command", "event", h.Event)
+			return true
+		}
+		return false
+	})
+	for i := range c.Hooks {
+		if c.Hooks[i].TimeoutS <= 0 {
+			c.Hooks[i].TimeoutS = defaultHookTimeoutS
+		}
+	}
+}
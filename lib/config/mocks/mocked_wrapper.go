@@ -117,6 +117,19 @@ type Wrapper struct {
 	folderPasswordsReturnsOnCall map[int]struct {
 		result1 map[string]string
 	}
+	FolderTemplateStub        func(string) (config.FolderTemplate, bool)
+	folderTemplateMutex       sync.RWMutex
+	folderTemplateArgsForCall []struct {
+		arg1 string
+	}
+	folderTemplateReturns struct {
+		result1 config.FolderTemplate
+		result2 bool
+	}
+	folderTemplateReturnsOnCall map[int]struct {
+		result1 config.FolderTemplate
+		result2 bool
+	}
 	FoldersStub        func() map[string]config.FolderConfiguration
 	foldersMutex       sync.RWMutex
 	foldersArgsForCall []struct {
@@ -860,6 +873,70 @@ func (fake *Wrapper) FolderPasswordsReturnsOnCall(i int, result1 map[string]stri
 	}{result1}
 }
 
+func (fake *Wrapper) FolderTemplate(arg1 string) (config.FolderTemplate, bool) {
+	fake.folderTemplateMutex.Lock()
+	ret, specificReturn := fake.folderTemplateReturnsOnCall[len(fake.folderTemplateArgsForCall)]
+	fake.folderTemplateArgsForCall = append(fake.folderTemplateArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderTemplateStub
+	fakeReturns := fake.folderTemplateReturns
+	fake.recordInvocation("FolderTemplate", []interface{}{arg1})
+	fake.folderTemplateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Wrapper) FolderTemplateCallCount() int {
+	fake.folderTemplateMutex.RLock()
+	defer fake.folderTemplateMutex.RUnlock()
+	return len(fake.folderTemplateArgsForCall)
+}
+
+func (fake *Wrapper) FolderTemplateCalls(stub func(string) (config.FolderTemplate, bool)) {
+	fake.folderTemplateMutex.Lock()
+	defer fake.folderTemplateMutex.Unlock()
+	fake.FolderTemplateStub = stub
+}
+
+func (fake *Wrapper) FolderTemplateArgsForCall(i int) string {
+	fake.folderTemplateMutex.RLock()
+	defer fake.folderTemplateMutex.RUnlock()
+	argsForCall := fake.folderTemplateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Wrapper) FolderTemplateReturns(result1 config.FolderTemplate, result2 bool) {
+	fake.folderTemplateMutex.Lock()
+	defer fake.folderTemplateMutex.Unlock()
+	fake.FolderTemplateStub = nil
+	fake.folderTemplateReturns = struct {
+		result1 config.FolderTemplate
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *Wrapper) FolderTemplateReturnsOnCall(i int, result1 config.FolderTemplate, result2 bool) {
+	fake.folderTemplateMutex.Lock()
+	defer fake.folderTemplateMutex.Unlock()
+	fake.FolderTemplateStub = nil
+	if fake.folderTemplateReturnsOnCall == nil {
+		fake.folderTemplateReturnsOnCall = make(map[int]struct {
+			result1 config.FolderTemplate
+			result2 bool
+		})
+	}
+	fake.folderTemplateReturnsOnCall[i] = struct {
+		result1 config.FolderTemplate
+		result2 bool
+	}{result1, result2}
+}
+
 func (fake *Wrapper) Folders() map[string]config.FolderConfiguration {
 	fake.foldersMutex.Lock()
 	ret, specificReturn := fake.foldersReturnsOnCall[len(fake.foldersArgsForCall)]
@@ -8,11 +8,15 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"net"
 	"runtime"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/protocol"
 	"github.com/syncthing/syncthing/lib/rand"
 	"github.com/syncthing/syncthing/lib/stringutil"
@@ -69,6 +73,10 @@ type OptionsConfiguration struct {
 	FeatureFlags                []string `json:"featureFlags" xml:"featureFlag"`
 	AuditEnabled                bool     `json:"auditEnabled" xml:"auditEnabled" default:"false" restart:"true"`
 	AuditFile                   string   `json:"auditFile" xml:"auditFile" restart:"true"`
+	// Where to send security events through syslog: "local" for the local
+	// syslog daemon, or udp://host:port or tcp://host:port. Empty for
+	// nowhere.
+	SecuritySyslog string `json:"securitySyslog" xml:"securitySyslog,omitempty"`
 	// The number of connections at which we stop trying to connect to more
 	// devices, zero meaning no limit. Does not affect incoming connections.
 	ConnectionLimitEnough int `json:"connectionLimitEnough" xml:"connectionLimitEnough"`
@@ -82,6 +90,25 @@ type OptionsConfiguration struct {
 	ConnectionPriorityQUICWAN          int `json:"connectionPriorityQuicWan" xml:"connectionPriorityQuicWan" default:"40"`
 	ConnectionPriorityRelay            int `json:"connectionPriorityRelay" xml:"connectionPriorityRelay" default:"50"`
 	ConnectionPriorityUpgradeThreshold int `json:"connectionPriorityUpgradeThreshold" xml:"connectionPriorityUpgradeThreshold" default:"0"`
+	// The memory in MiB that all folders together may use for in-flight
+	// block requests, hashing and batches of file infos, zero meaning no
+	// limit. Folders may have a budget of their own within it.
+	MaxMemoryBudgetMiB int `json:"maxMemoryBudgetMiB" xml:"maxMemoryBudgetMiB"`
+	// The number of files hashed at the same time across all folders. Zero
+	// means a default based on the number of CPU cores, negative means no
+	// limit.
+	RawMaxConcurrentHashers int `json:"maxConcurrentHashers" xml:"maxConcurrentHashers"`
+	// Overall rate limits replacing MaxSendKbps and MaxRecvKbps during
+	// certain times. The first matching entry applies.
+	RateLimitSchedule []RateLimitPeriod `json:"rateLimitSchedule" xml:"rateLimitPeriod"`
+	// The number of block requests from other devices served at the same
+	// time, overall and per device, zero meaning no limit. Devices waiting
+	// for a slot are served fairly, those with the fewest requests in
+	// progress first.
+	MaxUploadSlots          int `json:"maxUploadSlots" xml:"maxUploadSlots"`
+	MaxUploadSlotsPerDevice int `json:"maxUploadSlotsPerDevice" xml:"maxUploadSlotsPerDevice"`
+	// Pauses all syncing, see GlobalPause.
+	GlobalPause GlobalPause `json:"globalPause" xml:"globalPause"`
 	// Legacy deprecated
 	DeprecatedUPnPEnabled        bool     `json:"-" xml:"upnpEnabled,omitempty"`        // Deprecated: Do not use.
 	DeprecatedUPnPLeaseM         int      `json:"-" xml:"upnpLeaseMinutes,omitempty"`   // Deprecated: Do not use.
@@ -102,15 +129,43 @@ func (opts OptionsConfiguration) Copy() OptionsConfiguration {
 	copy(optsCopy.AlwaysLocalNets, opts.AlwaysLocalNets)
 	optsCopy.UnackedNotificationIDs = make([]string, len(opts.UnackedNotificationIDs))
 	copy(optsCopy.UnackedNotificationIDs, opts.UnackedNotificationIDs)
+	optsCopy.RateLimitSchedule = slices.Clone(opts.RateLimitSchedule)
 	return optsCopy
 }
 
+// A RateLimitPeriod sets the overall rate limits, in KiB/s with zero
+// meaning unlimited, during a time window in the format of TimeWindow.
+type RateLimitPeriod struct {
+	Time        string `json:"time" xml:"time,attr"`
+	MaxSendKbps int    `json:"maxSendKbps" xml:"maxSendKbps"`
+	MaxRecvKbps int    `json:"maxRecvKbps" xml:"maxRecvKbps"`
+}
+
+// RateLimitsAt returns the overall send and receive rate limits in KiB/s
+// at the given time, taking the rate limit schedule into account.
+func (opts OptionsConfiguration) RateLimitsAt(t time.Time) (sendKbps, recvKbps int) {
+	for _, p := range opts.RateLimitSchedule {
+		if w, err := ParseTimeWindow(p.Time); err == nil && w.Contains(t) {
+			return p.MaxSendKbps, p.MaxRecvKbps
+		}
+	}
+	return opts.MaxSendKbps, opts.MaxRecvKbps
+}
+
 func (opts *OptionsConfiguration) prepare(guiPWIsSet bool) {
 	structutil.FillNilSlices(opts)
 
 	opts.RawListenAddresses = stringutil.UniqueTrimmedStrings(opts.RawListenAddresses)
 	opts.RawGlobalAnnServers = stringutil.UniqueTrimmedStrings(opts.RawGlobalAnnServers)
 
+	opts.RateLimitSchedule = slices.DeleteFunc(opts.RateLimitSchedule, func(p RateLimitPeriod) bool {
+		if _, err := ParseTimeWindow(p.Time); err != nil {
+			slog.Warn("Ignoring invalid rate limit period", slogutil.Error(err))
+			return true
+		}
+		return false
+	})
+
 	// Very short reconnection intervals are annoying
 	if opts.ReconnectIntervalS < 5 {
 		opts.ReconnectIntervalS = 5
@@ -246,6 +301,23 @@ func (opts OptionsConfiguration) MaxFolderConcurrency() int {
 	return 4 // https://xkcd.com/221/
 }
 
+func (opts OptionsConfiguration) MaxConcurrentHashers() int {
+	if opts.RawMaxConcurrentHashers > 0 {
+		return opts.RawMaxConcurrentHashers
+	}
+	if opts.RawMaxConcurrentHashers < 0 {
+		// Unlimited, spelled zero
+		return 0
+	}
+	numCpus := runtime.GOMAXPROCS(-1)
+	if build.IsWindows || build.IsDarwin || build.IsIOS || build.IsAndroid {
+		// Interactive operating systems; don't load the system too heavily
+		// by default.
+		numCpus = numCpus / 4
+	}
+	return max(1, numCpus)
+}
+
 func (opts OptionsConfiguration) MaxConcurrentIncomingRequestKiB() int {
 	// Negative is disabled, which in limiter land is spelled zero
 	if opts.RawMaxCIRequestKiB < 0 {
@@ -0,0 +1,76 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Ownership mapping translates the owners, groups and permissions that
+// items have on other devices into those they get here, and is taken into
+// account when scanning, so that the two are considered in sync. Owners and
+// groups are given by name or numeric ID; the first matching entry is used
+// and unmatched ones are kept as they are. Permission masks are in octal,
+// the bits of the clear mask are removed and then those of the set mask
+// added.
+type OwnershipMapping struct {
+	Owners           []OwnershipMapEntry `json:"owners" xml:"owner"`
+	Groups           []OwnershipMapEntry `json:"groups" xml:"group"`
+	ClearPermissions string              `json:"clearPermissions" xml:"clearPermissions"`
+	SetPermissions   string              `json:"setPermissions" xml:"setPermissions"`
+}
+
+type OwnershipMapEntry struct {
+	Remote string `json:"remote" xml:"remote,attr"`
+	Local  string `json:"local" xml:"local,attr"`
+}
+
+// MapOwner returns the local owner for an item owned by the given user
+// name and ID elsewhere. A local owner given by ID is returned without a
+// name, one given by name with the remote ID as fallback.
+func (m OwnershipMapping) MapOwner(name string, id int) (string, int) {
+	return mapOwnership(m.Owners, name, id)
+}
+
+// MapGroup is like MapOwner, for groups.
+func (m OwnershipMapping) MapGroup(name string, id int) (string, int) {
+	return mapOwnership(m.Groups, name, id)
+}
+
+// MapPermissions returns the local permission bits for an item with the
+// given permission bits elsewhere.
+func (m OwnershipMapping) MapPermissions(perm uint32) uint32 {
+	clearMask, _ := parsePermissionMask(m.ClearPermissions)
+	setMask, _ := parsePermissionMask(m.SetPermissions)
+	return perm&^clearMask | setMask
+}
+
+func mapOwnership(entries []OwnershipMapEntry, name string, id int) (string, int) {
+	remoteID := strconv.Itoa(id)
+	for _, e := range entries {
+		if e.Remote != remoteID && (name == "" || e.Remote != name) {
+			continue
+		}
+		if localID, err := strconv.Atoi(e.Local); err == nil {
+			return "", localID
+		}
+		return e.Local, id
+	}
+	return name, id
+}
+
+func parsePermissionMask(s string) (uint32, error) {
+	if s == "" {
+		return 0, nil
+	}
+	mask, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mask > 0o777 {
+		return 0, fmt.Errorf("invalid permission mask %q", s)
+	}
+	return uint32(mask), nil
+}
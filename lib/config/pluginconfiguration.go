@@ -0,0 +1,36 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// A PluginConfiguration is a separate process that extends Syncthing over
+// gRPC; see the plugin package for what it may provide. The command is
+// split into words like a shell command line, but is not run by a shell.
+type PluginConfiguration struct {
+	ID      string `json:"id" xml:"id,attr"`
+	Command string `json:"command" xml:"command"`
+}
+
+func (cfg *Configuration) preparePlugins() {
+	seen := make(map[string]struct{}, len(cfg.Plugins))
+	cfg.Plugins = slices.DeleteFunc(cfg.Plugins, func(p PluginConfiguration) bool {
+		if p.ID == "" || p.Command == "" {
+			slog.Warn("Ignoring plugin without an ID or command", "id", p.ID)
+			return true
+		}
+		if _, ok := seen[p.ID]; ok {
+			slog.Warn("Ignoring duplicate plugin", "id", p.ID)
+			return true
+		}
+		seen[p.ID] = struct{}{}
+		return false
+	})
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+// QuotaAction is what happens when the monthly data quota for a device is
+// exceeded.
+type QuotaAction int32
+
+const (
+	// QuotaActionPause pauses the device until the next month.
+	QuotaActionPause QuotaAction = 0
+	// QuotaActionDisableRelays stops connecting to the device over relays
+	// until the next month.
+	QuotaActionDisableRelays QuotaAction = 1
+)
+
+func (a QuotaAction) String() string {
+	switch a {
+	case QuotaActionPause:
+		return "pause"
+	case QuotaActionDisableRelays:
+		return "disableRelays"
+	default:
+		return "unknown"
+	}
+}
+
+func (a QuotaAction) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *QuotaAction) UnmarshalText(bs []byte) error {
+	switch string(bs) {
+	case "disableRelays":
+		*a = QuotaActionDisableRelays
+	default:
+		*a = QuotaActionPause
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A RetentionPolicy says which versions of a file to keep, by their age. It
+// is written as a comma separated list of rules such as "keep all for 24h,
+// hourly for 7d, daily for 90d, weekly for 1y", ordered by age. Versions
+// older than the last rule are removed, unless it is "for ever".
+type RetentionPolicy []RetentionRule
+
+// A RetentionRule keeps one version per Every, or all versions when Every
+// is zero, up to the age For. A zero For is forever.
+type RetentionRule struct {
+	Every time.Duration
+	For   time.Duration
+}
+
+var errEmptyRetentionRule = errors.New("empty rule")
+
+var retentionEvery = map[string]time.Duration{
+	"all":     0,
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// ParseRetentionPolicy parses and validates a retention policy. The empty
+// string is no policy.
+func ParseRetentionPolicy(s string) (RetentionPolicy, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var policy RetentionPolicy
+	for _, clause := range strings.Split(s, ",") {
+		rule, err := parseRetentionRule(clause)
+		if err != nil {
+			return nil, fmt.Errorf("retention rule %q: %w", strings.TrimSpace(clause), err)
+		}
+		if len(policy) > 0 {
+			prev := policy[len(policy)-1]
+			if prev.For == 0 {
+				return nil, errors.New("retention rules after one kept for ever")
+			}
+			if rule.For != 0 && rule.For <= prev.For {
+				return nil, fmt.Errorf("retention rule %q: not for longer than the rule before it", strings.TrimSpace(clause))
+			}
+		}
+		policy = append(policy, rule)
+	}
+	return policy, nil
+}
+
+func parseRetentionRule(clause string) (RetentionRule, error) {
+	fields := strings.Fields(strings.ToLower(clause))
+	if len(fields) > 0 && fields[0] == "keep" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return RetentionRule{}, errEmptyRetentionRule
+	}
+
+	var rule RetentionRule
+	if every, ok := retentionEvery[fields[0]]; ok {
+		rule.Every = every
+		fields = fields[1:]
+	} else if fields[0] == "every" && len(fields) > 1 {
+		every, err := parseRetentionDuration(fields[1])
+		if err != nil {
+			return RetentionRule{}, err
+		}
+		rule.Every = every
+		fields = fields[2:]
+	} else {
+		return RetentionRule{}, fmt.Errorf("unknown interval %q", fields[0])
+	}
+
+	switch {
+	case len(fields) == 1 && fields[0] == "forever":
+	case len(fields) == 2 && fields[0] == "for" && fields[1] == "ever":
+	case len(fields) == 2 && fields[0] == "for":
+		d, err := parseRetentionDuration(fields[1])
+		if err != nil {
+			return RetentionRule{}, err
+		}
+		if d < rule.Every {
+			return RetentionRule{}, errors.New("kept for less than its interval")
+		}
+		rule.For = d
+	default:
+		return RetentionRule{}, errors.New(`expected "for" and a duration`)
+	}
+	return rule, nil
+}
+
+// parseRetentionDuration parses a duration, which besides the units of
+// time.ParseDuration may be in days, weeks or years.
+func parseRetentionDuration(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+		'y': 365 * 24 * time.Hour,
+	}
+	if unit, ok := units[s[len(s)-1]]; ok {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
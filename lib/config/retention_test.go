@@ -0,0 +1,52 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseRetentionPolicy(t *testing.T) {
+	const day = 24 * time.Hour
+	policy, err := ParseRetentionPolicy("keep all for 24h, hourly for 7d, daily for 90d, weekly for 1y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := RetentionPolicy{
+		{Every: 0, For: day},
+		{Every: time.Hour, For: 7 * day},
+		{Every: day, For: 90 * day},
+		{Every: 7 * day, For: 365 * day},
+	}
+	if !slices.Equal(policy, expected) {
+		t.Errorf("got %v, expected %v", policy, expected)
+	}
+
+	policy, err = ParseRetentionPolicy("every 30m for 2d, monthly forever")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(policy, RetentionPolicy{{30 * time.Minute, 2 * day}, {30 * day, 0}}) {
+		t.Error("unexpected policy", policy)
+	}
+
+	for _, invalid := range []string{
+		"hourly",
+		"sometimes for 1d",
+		"daily for 1h",
+		"hourly for 7d, daily for 2d",
+		"hourly forever, daily for 90d",
+		"daily for 7x",
+		"hourly for 1d,",
+	} {
+		if _, err := ParseRetentionPolicy(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Values of the fields listed in secretFields can refer to the environment
+// or to a file instead of holding the value itself:
+//
+//   - in a value starting with "env:", "${NAME}" anywhere in the rest of
+//     the value is replaced by the environment variable NAME, which must
+//     be set;
+//   - a value starting with "file:" is replaced by the contents of the
+//     named file, without trailing newlines.
+//
+// Other values are taken as is, so that a password or path that happens
+// to contain "${" is not mistaken for a reference. References are
+// resolved when the config is loaded. The references are remembered and
+// written back in place of the value when saving, for as long as the
+// value isn't changed.
+const (
+	secretEnvPrefix  = "env:"
+	secretFilePrefix = "file:"
+)
+
+var envRefExpr = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// A secretRef is the reference as written in the config file, and the value
+// it resolved to after loading.
+type secretRef struct {
+	raw   string
+	value string
+}
+
+// secretFields returns pointers to the fields that may contain references,
+// keyed by a name that stays the same over copies of the config.
+func secretFields(cfg *Configuration) map[string]*string {
+	fields := map[string]*string{
+		"gui.user":       &cfg.GUI.User,
+		"gui.password":   &cfg.GUI.Password,
+		"gui.apikey":     &cfg.GUI.APIKey,
+		"gui.totpSecret": &cfg.GUI.TOTPSecret,
+		// LDAP binds with the password of the user logging in, so there is
+		// no bind password; these name the directory and the account.
+		"ldap.address": &cfg.LDAP.Address,
+		"ldap.bindDN":  &cfg.LDAP.BindDN,
+	}
+	for i := range cfg.Folders {
+		f := &cfg.Folders[i]
+		fields["folder."+f.ID+".path"] = &f.Path
+		for j := range f.Devices {
+			d := &f.Devices[j]
+			fields["folder."+f.ID+".device."+d.DeviceID.String()+".encryptionPassword"] = &d.EncryptionPassword
+			fields["folder."+f.ID+".device."+d.DeviceID.String()+".previousEncryptionPassword"] = &d.PreviousEncryptionPassword
+			for k := range d.EncryptionSubtrees {
+				st := &d.EncryptionSubtrees[k]
+				fields["folder."+f.ID+".device."+d.DeviceID.String()+".subtree."+st.Path+".encryptionPassword"] = &st.EncryptionPassword
+			}
+		}
+	}
+	return fields
+}
+
+func isSecretRef(s string) bool {
+	return strings.HasPrefix(s, secretFilePrefix) || strings.HasPrefix(s, secretEnvPrefix)
+}
+
+func resolveSecretRef(s string) (string, error) {
+	if name, ok := strings.CutPrefix(s, secretFilePrefix); ok {
+		bs, err := os.ReadFile(name)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(bs), "\r\n"), nil
+	}
+
+	s = strings.TrimPrefix(s, secretEnvPrefix)
+	var err error
+	res := envRefExpr.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envRefExpr.FindStringSubmatch(ref)[1]
+		val, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("environment variable %s is not set", name)
+		}
+		return val
+	})
+	return res, err
+}
+
+// resolveSecretRefs replaces references with their values, remembering the
+// references. To be called before prepare.
+func (cfg *Configuration) resolveSecretRefs() error {
+	for key, field := range secretFields(cfg) {
+		if !isSecretRef(*field) {
+			continue
+		}
+		val, err := resolveSecretRef(*field)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", key, err)
+		}
+		if cfg.secretRefs == nil {
+			cfg.secretRefs = make(map[string]secretRef)
+		}
+		cfg.secretRefs[key] = secretRef{raw: *field}
+		*field = val
+	}
+	if _, ok := cfg.secretRefs["gui.password"]; ok {
+		// The stored password is always a hash
+		if err := cfg.GUI.SetPassword(cfg.GUI.Password); err != nil {
+			return fmt.Errorf("resolving gui.password: %w", err)
+		}
+	}
+	return nil
+}
+
+// recordSecretValues remembers the final values of the resolved references.
+// To be called after prepare, which may change the values.
+func (cfg *Configuration) recordSecretValues() {
+	fields := secretFields(cfg)
+	for key, ref := range cfg.secretRefs {
+		if field, ok := fields[key]; ok {
+			ref.value = *field
+			cfg.secretRefs[key] = ref
+		}
+	}
+}
+
+// withSecretRefs returns a copy of the config with the references put back
+// in place of values that are unchanged since loading.
+func (cfg Configuration) withSecretRefs() Configuration {
+	if len(cfg.secretRefs) == 0 {
+		return cfg
+	}
+	out := cfg.Copy()
+	for key, field := range secretFields(&out) {
+		if ref, ok := cfg.secretRefs[key]; ok && *field == ref.value {
+			*field = ref.raw
+		}
+	}
+	return out
+}
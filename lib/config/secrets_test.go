@@ -0,0 +1,140 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSecretRefs(t *testing.T) {
+	dir := t.TempDir()
+	pwFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(pwFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ST_TEST_APIKEY", "abc123")
+	t.Setenv("ST_TEST_DATA", "/srv/data")
+	t.Setenv("ST_TEST_LDAP", "ldap.example.com:636")
+
+	input := `<configuration version="` + strconv.Itoa(CurrentVersion) + `">
+		<folder id="docs" path="env:${ST_TEST_DATA}/docs">
+			<device id="` + device2.String() + `"><encryptionPassword>file:` + pwFile + `</encryptionPassword></device>
+		</folder>
+		<device id="` + device2.String() + `" untrusted="true"></device>
+		<gui><apikey>env:${ST_TEST_APIKEY}</apikey><user>admin</user><password>file:` + pwFile + `</password></gui>
+		<ldap><address>env:${ST_TEST_LDAP}</address><bindDN>uid=%s,ou=users,dc=example,dc=com</bindDN></ldap>
+	</configuration>`
+
+	cfg, _, err := ReadXML(strings.NewReader(input), device1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.GUI.APIKey != "abc123" {
+		t.Errorf("API key not resolved: %q", cfg.GUI.APIKey)
+	}
+	if err := cfg.GUI.CompareHashedPassword("s3cret"); err != nil {
+		t.Error("password not resolved and hashed:", err)
+	}
+	fcfg, _, _ := cfg.Folder("docs")
+	if fcfg.Path != "/srv/data/docs" {
+		t.Errorf("path not resolved: %q", fcfg.Path)
+	}
+	if dev, _ := fcfg.Device(device2); dev.EncryptionPassword != "s3cret" {
+		t.Errorf("encryption password not resolved: %q", dev.EncryptionPassword)
+	}
+	if cfg.LDAP.Address != "ldap.example.com:636" {
+		t.Errorf("LDAP address not resolved: %q", cfg.LDAP.Address)
+	}
+
+	// The references are written back, not the values, except where the
+	// value has changed.
+	cfg.GUI.APIKey = "changed"
+	var buf bytes.Buffer
+	if err := cfg.WriteXML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, exp := range []string{"env:${ST_TEST_DATA}/docs", "<address>env:${ST_TEST_LDAP}", "<password>file:" + pwFile, "<encryptionPassword>file:" + pwFile, "<apikey>changed"} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected %q in output", exp)
+		}
+	}
+	if strings.Contains(out, "s3cret") || strings.Contains(out, "/srv/data") {
+		t.Error("resolved secrets should not be written")
+	}
+}
+
+func TestSecretRefsUnset(t *testing.T) {
+	input := `<configuration version="` + strconv.Itoa(CurrentVersion) + `">
+		<gui><apikey>env:${ST_TEST_SURELY_UNSET}</apikey></gui>
+	</configuration>`
+	if _, _, err := ReadXML(strings.NewReader(input), device1); err == nil {
+		t.Error("expected error for unset variable")
+	}
+}
+
+func TestSecretRefsKeptOverReplace(t *testing.T) {
+	t.Setenv("ST_TEST_APIKEY", "abc123")
+	input := `<configuration version="` + strconv.Itoa(CurrentVersion) + `">
+		<gui><apikey>env:${ST_TEST_APIKEY}</apikey></gui>
+	</configuration>`
+	cfg, _, err := ReadXML(strings.NewReader(input), device1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := wrap(filepath.Join(t.TempDir(), "config.xml"), cfg, device1)
+	defer w.stop()
+
+	// Replacing the config with an equivalent one, as the REST API does,
+	// keeps the reference.
+	repl := New(device1)
+	repl.GUI.APIKey = "abc123"
+	repl.Options.MaxSendKbps = 10
+	waiter, err := w.Modify(func(cfg *Configuration) { *cfg = repl })
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiter.Wait()
+
+	var buf bytes.Buffer
+	cur := w.RawCopy()
+	if err := cur.WriteXML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<apikey>env:${ST_TEST_APIKEY}</apikey>") {
+		t.Error("reference lost over replace")
+	}
+}
+
+func TestSecretRefsLiteral(t *testing.T) {
+	// Without the prefix, values are taken as is, whether or not they look
+	// like references.
+	input := `<configuration version="` + strconv.Itoa(CurrentVersion) + `">
+		<gui><apikey>${ST_TEST_SURELY_UNSET}</apikey></gui>
+	</configuration>`
+	cfg, _, err := ReadXML(strings.NewReader(input), device1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GUI.APIKey != "${ST_TEST_SURELY_UNSET}" {
+		t.Errorf("expected literal API key, got %q", cfg.GUI.APIKey)
+	}
+
+	var buf bytes.Buffer
+	if err := cfg.WriteXML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<apikey>${ST_TEST_SURELY_UNSET}</apikey>") {
+		t.Error("literal value not written back")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A TimeWindow is a recurring period of the week, such as "Mon-Fri
+// 08:00-18:00", "Sat,Sun 10:00-14:00" or "22:00-06:00". Without days it
+// recurs every day. A window ending before it starts runs past midnight,
+// into the day after the given days.
+type TimeWindow struct {
+	days       [7]bool // indexed by time.Weekday
+	start, end int     // minutes after midnight
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseTimeWindow parses a time window in the format described for
+// TimeWindow.
+func ParseTimeWindow(s string) (TimeWindow, error) {
+	var w TimeWindow
+	fields := strings.Fields(s)
+	switch len(fields) {
+	case 1:
+		for i := range w.days {
+			w.days[i] = true
+		}
+	case 2:
+		if err := w.parseDays(fields[0]); err != nil {
+			return TimeWindow{}, fmt.Errorf("time window %q: %w", s, err)
+		}
+		fields = fields[1:]
+	default:
+		return TimeWindow{}, fmt.Errorf("time window %q: expected [days] hh:mm-hh:mm", s)
+	}
+
+	start, end, ok := strings.Cut(fields[0], "-")
+	if !ok {
+		return TimeWindow{}, fmt.Errorf("time window %q: expected hh:mm-hh:mm", s)
+	}
+	var err error
+	if w.start, err = parseTimeOfDay(start); err != nil {
+		return TimeWindow{}, fmt.Errorf("time window %q: %w", s, err)
+	}
+	if w.end, err = parseTimeOfDay(end); err != nil {
+		return TimeWindow{}, fmt.Errorf("time window %q: %w", s, err)
+	}
+	if w.start == w.end {
+		return TimeWindow{}, fmt.Errorf("time window %q: empty", s)
+	}
+	return w, nil
+}
+
+func (w *TimeWindow) parseDays(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(strings.ToLower(part), "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return fmt.Errorf("unknown day %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return fmt.Errorf("unknown day %q", to)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			w.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		if s == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains returns true if the given time, in its own location, is in the
+// window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	if w.start < w.end {
+		return w.days[day] && minute >= w.start && minute < w.end
+	}
+	// Running past midnight, from the given day until the end on the day
+	// after.
+	if minute >= w.start {
+		return w.days[day]
+	}
+	return minute < w.end && w.days[(day+6)%7]
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeWindow(t *testing.T) {
+	// 2026-10-12 is a Monday.
+	at := func(day int, hhmm string) time.Time {
+		tod, err := time.Parse("15:04", hhmm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(2026, 10, 12+day, tod.Hour(), tod.Minute(), 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		window string
+		at     time.Time
+		want   bool
+	}{
+		{"08:00-18:00", at(0, "08:00"), true},
+		{"08:00-18:00", at(3, "17:59"), true},
+		{"08:00-18:00", at(0, "18:00"), false},
+		{"08:00-18:00", at(0, "07:59"), false},
+		{"Mon-Fri 08:00-18:00", at(4, "12:00"), true},
+		{"Mon-Fri 08:00-18:00", at(5, "12:00"), false},
+		{"sat,sun 10:00-14:00", at(6, "10:30"), true},
+		{"sat,sun 10:00-14:00", at(0, "10:30"), false},
+		{"Fri-Mon 00:00-24:00", at(6, "23:59"), true},
+		{"Fri-Mon 00:00-24:00", at(1, "00:00"), false},
+		// Past midnight
+		{"22:00-06:00", at(0, "23:00"), true},
+		{"22:00-06:00", at(0, "05:59"), true},
+		{"22:00-06:00", at(0, "06:00"), false},
+		{"Fri 22:00-06:00", at(5, "05:00"), true},
+		{"Fri 22:00-06:00", at(4, "05:00"), false},
+		{"Fri 22:00-06:00", at(4, "22:00"), true},
+	}
+	for _, tc := range cases {
+		w, err := ParseTimeWindow(tc.window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := w.Contains(tc.at); got != tc.want {
+			t.Errorf("%q contains %v: got %v, expected %v", tc.window, tc.at, got, tc.want)
+		}
+	}
+
+	for _, invalid := range []string{"", "08:00", "08:00-08:00", "25:00-08:00", "Mon Tue 08:00-09:00", "Moon 08:00-09:00", "Mon-Fry 08:00-09:00"} {
+		if _, err := ParseTimeWindow(invalid); err == nil {
+			t.Errorf("expected error parsing %q", invalid)
+		}
+	}
+}
+
+func TestDeviceAllowedTimes(t *testing.T) {
+	cfg := DeviceConfiguration{AllowedTimes: []string{"Mon 08:00-09:00", "invalid"}}
+	cfg.prepare(nil)
+	if len(cfg.AllowedTimes) != 1 {
+		t.Fatalf("expected invalid allowed time to be removed, got %v", cfg.AllowedTimes)
+	}
+	if !cfg.IsAllowedTime(time.Date(2026, 10, 12, 8, 30, 0, 0, time.UTC)) {
+		t.Error("expected Monday morning to be allowed")
+	}
+	if cfg.IsAllowedTime(time.Date(2026, 10, 13, 8, 30, 0, 0, time.UTC)) {
+		t.Error("expected Tuesday morning not to be allowed")
+	}
+	if cfg := (DeviceConfiguration{}); !cfg.IsAllowedTime(time.Now()) {
+		t.Error("expected any time to be allowed without allowed times")
+	}
+}
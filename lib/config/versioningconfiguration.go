@@ -22,6 +22,8 @@ type VersioningConfiguration struct {
 	CleanupIntervalS int               `json:"cleanupIntervalS" xml:"cleanupIntervalS" default:"3600"`
 	FSPath           string            `json:"fsPath" xml:"fsPath"`
 	FSType           FilesystemType    `json:"fsType" xml:"fsType" default:"basic"`
+	RetentionPolicy  string            `json:"retentionPolicy" xml:"retentionPolicy"`
+	MaxSize          Size              `json:"maxSize" xml:"maxSize" default:"0"`
 }
 
 func (c *VersioningConfiguration) Reset() {
@@ -35,6 +37,8 @@ type internalVersioningConfiguration struct {
 	CleanupIntervalS int             `xml:"cleanupIntervalS" default:"3600"`
 	FSPath           string          `xml:"fsPath"`
 	FSType           FilesystemType  `xml:"fsType" default:"basic"`
+	RetentionPolicy  string          `xml:"retentionPolicy,omitempty"`
+	MaxSize          Size            `xml:"maxSize" default:"0"`
 }
 
 type internalParam struct {
@@ -82,6 +86,8 @@ func (c *VersioningConfiguration) toInternal() internalVersioningConfiguration {
 	tmp.CleanupIntervalS = c.CleanupIntervalS
 	tmp.FSPath = c.FSPath
 	tmp.FSType = c.FSType
+	tmp.RetentionPolicy = c.RetentionPolicy
+	tmp.MaxSize = c.MaxSize
 	for k, v := range c.Params {
 		tmp.Params = append(tmp.Params, internalParam{k, v})
 	}
@@ -96,6 +102,8 @@ func (c *VersioningConfiguration) fromInternal(intCfg internalVersioningConfigur
 	c.CleanupIntervalS = intCfg.CleanupIntervalS
 	c.FSPath = intCfg.FSPath
 	c.FSType = intCfg.FSType
+	c.RetentionPolicy = intCfg.RetentionPolicy
+	c.MaxSize = intCfg.MaxSize
 	c.Params = make(map[string]string, len(intCfg.Params))
 	for _, p := range intCfg.Params {
 		c.Params[p.Key] = p.Val
@@ -110,6 +110,7 @@ type Wrapper interface {
 	FolderList() []FolderConfiguration
 	FolderPasswords(device protocol.DeviceID) map[string]string
 	DefaultFolder() FolderConfiguration
+	FolderTemplate(name string) (FolderTemplate, bool)
 
 	Device(id protocol.DeviceID) (DeviceConfiguration, bool)
 	Devices() map[protocol.DeviceID]DeviceConfiguration
@@ -157,7 +158,8 @@ func Wrap(path string, cfg Configuration, myID protocol.DeviceID, evLogger event
 }
 
 // Load loads an existing file on disk and returns a new configuration
-// wrapper.
+// wrapper. The file is read, and later saved, in the format given by its
+// extension.
 // The returned Wrapper is a suture.Service, thus needs to be started (added to
 // a supervisor).
 func Load(path string, myID protocol.DeviceID, evLogger events.Logger) (Wrapper, int, error) {
@@ -167,7 +169,7 @@ func Load(path string, myID protocol.DeviceID, evLogger events.Logger) (Wrapper,
 	}
 	defer fd.Close()
 
-	cfg, originalVersion, err := ReadXML(fd, myID)
+	cfg, originalVersion, err := readFormat(fd, path, myID)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -304,6 +306,15 @@ func (w *wrapper) serveSave() {
 func (w *wrapper) replaceLocked(to Configuration) (Waiter, error) {
 	from := w.cfg
 
+	if to.secretRefs == nil {
+		// A config from elsewhere, like the REST API, still refers to the
+		// same secrets.
+		to.secretRefs = from.secretRefs
+	}
+	if to.dropIns == nil {
+		to.dropIns = from.dropIns
+	}
+
 	if err := to.prepare(w.myID); err != nil {
 		return noopWaiter{}, err
 	}
@@ -421,6 +432,14 @@ func (w *wrapper) DefaultFolder() FolderConfiguration {
 	return w.cfg.Defaults.Folder.Copy()
 }
 
+// FolderTemplate returns the folder template with the given name.
+func (w *wrapper) FolderTemplate(name string) (FolderTemplate, bool) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	tmpl, _, ok := w.cfg.FolderTemplate(name)
+	return tmpl.Copy(), ok
+}
+
 // Options returns the current options configuration object.
 func (w *wrapper) Options() OptionsConfiguration {
 	w.mut.Lock()
@@ -513,8 +532,8 @@ func (w *wrapper) Save() error {
 		return err
 	}
 
-	if err := w.cfg.WriteXML(osutil.LineEndingsWriter(fd)); err != nil {
-		l.Debugln("WriteXML:", err)
+	if err := w.cfg.writeFormat(osutil.LineEndingsWriter(fd), w.path); err != nil {
+		l.Debugln("Write:", err)
 		fd.Close()
 		return err
 	}
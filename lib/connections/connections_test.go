@@ -28,6 +28,8 @@ import (
 	"github.com/syncthing/syncthing/lib/events"
 	"github.com/syncthing/syncthing/lib/nat"
 	"github.com/syncthing/syncthing/lib/protocol"
+	protocolmocks "github.com/syncthing/syncthing/lib/protocol/mocks"
+	"github.com/syncthing/syncthing/lib/stats"
 	"github.com/syncthing/syncthing/lib/tlsutil"
 )
 
@@ -139,6 +141,55 @@ func TestAllowedNetworks(t *testing.T) {
 	}
 }
 
+func TestAllowedInterfaces(t *testing.T) {
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Skip(err)
+	}
+	var loopback string
+	for _, intf := range intfs {
+		if intf.Flags&net.FlagLoopback != 0 {
+			loopback = intf.Name
+			break
+		}
+	}
+	if loopback == "" {
+		t.Skip("no loopback interface")
+	}
+
+	local := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22000}
+	if !IsAllowedInterface(local, []string{"nonexistent", loopback}) {
+		t.Errorf("expected %v to be on %s", local, loopback)
+	}
+	if IsAllowedInterface(local, []string{"nonexistent"}) {
+		t.Errorf("expected %v not to be on a nonexistent interface", local)
+	}
+	other := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22000}
+	if IsAllowedInterface(other, []string{loopback}) {
+		t.Errorf("expected %v not to be on %s", other, loopback)
+	}
+}
+
+func TestRelaysAllowed(t *testing.T) {
+	over := stats.DeviceStatistics{MonthlyUsage: stats.MonthlyUsage{InBytes: 2 << 20}}
+	under := stats.DeviceStatistics{MonthlyUsage: stats.MonthlyUsage{OutBytes: 512 << 10}}
+
+	cfg := config.DeviceConfiguration{MonthlyQuotaMiB: 1, QuotaAction: config.QuotaActionDisableRelays}
+	if relaysAllowed(cfg, over) {
+		t.Error("expected relays disabled over the quota")
+	}
+	if !relaysAllowed(cfg, under) {
+		t.Error("expected relays allowed under the quota")
+	}
+	cfg.QuotaAction = config.QuotaActionPause
+	if !relaysAllowed(cfg, over) {
+		t.Error("expected relays allowed when pausing for the quota")
+	}
+	if !relaysAllowed(config.DeviceConfiguration{}, over) {
+		t.Error("expected relays allowed without a quota")
+	}
+}
+
 func TestGetDialer(t *testing.T) {
 	mustParseURI := func(v string) *url.URL {
 		uri, err := url.Parse(v)
@@ -232,6 +283,68 @@ func TestConnectionStatus(t *testing.T) {
 	check(nil, nil)
 }
 
+func TestAnnounceMoved(t *testing.T) {
+	s := &service{}
+	dev1, dev2 := protocol.DeviceID{1}, protocol.DeviceID{2}
+
+	if s.moveUnacknowledged(dev1) {
+		t.Fatal("expected nothing to announce before moving")
+	}
+
+	acknowledged := 0
+	s.AnnounceMoved(func() { acknowledged++ })
+	if !s.moveUnacknowledged(dev1) || !s.moveUnacknowledged(dev2) {
+		t.Fatal("expected the move to be announced to all devices")
+	}
+
+	s.moveAcknowledged(dev1)
+	if s.moveUnacknowledged(dev1) {
+		t.Error("expected the move not to be announced again once acknowledged")
+	}
+	if !s.moveUnacknowledged(dev2) {
+		t.Error("expected the move to be announced until acknowledged")
+	}
+	s.moveAcknowledged(dev1)
+	s.moveAcknowledged(dev2)
+	if acknowledged != 1 {
+		t.Errorf("expected to be told about the first acknowledgement only, got %d calls", acknowledged)
+	}
+}
+
+func TestCloseUnmovedConnections(t *testing.T) {
+	var c deviceConnectionTracker
+	dev := protocol.DeviceID{1}
+	newConn := func(id string) (*protocolmocks.Connection, chan error) {
+		conn := &protocolmocks.Connection{}
+		conn.DeviceIDReturns(dev)
+		conn.ConnectionIDReturns(id)
+		closed := make(chan error, 1)
+		conn.CloseCalls(func(err error) { closed <- err })
+		return conn, closed
+	}
+
+	stale, staleClosed := newConn("stale")
+	c.accountAddedConnection(stale, protocol.Hello{}, 0)
+	moved1, moved1Closed := newConn("moved1")
+	c.accountAddedConnection(moved1, protocol.Hello{Moved: true}, 0)
+	moved2, _ := newConn("moved2")
+	c.accountAddedConnection(moved2, protocol.Hello{Moved: true}, 0)
+
+	select {
+	case err := <-staleClosed:
+		if !errors.Is(err, errDeviceMoved) {
+			t.Error("expected stale connection to be closed as moved, got", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected stale connection to be closed")
+	}
+	select {
+	case err := <-moved1Closed:
+		t.Error("expected connection on which the device moved to be kept, got closed with", err)
+	default:
+	}
+}
+
 func TestNextDialRegistryCleanup(t *testing.T) {
 	now := time.Now()
 	firsts := []time.Time{
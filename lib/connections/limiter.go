@@ -13,6 +13,7 @@ import (
 	"log/slog"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"golang.org/x/time/rate"
 
@@ -28,6 +29,8 @@ type limiter struct {
 	write               *rate.Limiter
 	read                *rate.Limiter
 	limitsLAN           atomic.Bool
+	opts                config.OptionsConfiguration
+	sendKbps, recvKbps  int // currently applied overall limits
 	deviceReadLimiters  map[protocol.DeviceID]*rate.Limiter
 	deviceWriteLimiters map[protocol.DeviceID]*rate.Limiter
 }
@@ -45,6 +48,8 @@ const (
 func newLimiter(myId protocol.DeviceID, cfg config.Wrapper) *limiter {
 	l := &limiter{
 		myID:                myId,
+		sendKbps:            -1,
+		recvKbps:            -1,
 		write:               rate.NewLimiter(rate.Inf, limiterBurstSize),
 		read:                rate.NewLimiter(rate.Inf, limiterBurstSize),
 		deviceReadLimiters:  make(map[protocol.DeviceID]*rate.Limiter),
@@ -52,12 +57,26 @@ func newLimiter(myId protocol.DeviceID, cfg config.Wrapper) *limiter {
 	}
 
 	cfg.Subscribe(l)
-	prev := config.Configuration{Options: config.OptionsConfiguration{MaxRecvKbps: -1, MaxSendKbps: -1}}
-
-	l.CommitConfiguration(prev, cfg.RawCopy())
+	l.CommitConfiguration(config.Configuration{}, cfg.RawCopy())
 	return l
 }
 
+// serve applies the rate limit schedule as time passes.
+func (lim *limiter) serve(ctx context.Context) error {
+	t := time.NewTicker(time.Minute)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-t.C:
+			lim.mu.Lock()
+			lim.setOverallLimitsLocked(now)
+			lim.mu.Unlock()
+		}
+	}
+}
+
 // This function sets limiters according to corresponding DeviceConfiguration
 func (lim *limiter) setLimitsLocked(device config.DeviceConfiguration) bool {
 	readLimiter := lim.getReadLimiterLocked(device.DeviceID)
@@ -128,11 +147,21 @@ func (lim *limiter) CommitConfiguration(from, to config.Configuration) bool {
 	// Delete, add or update limiters for devices
 	lim.processDevicesConfigurationLocked(from, to)
 
-	if from.Options.MaxRecvKbps == to.Options.MaxRecvKbps &&
-		from.Options.MaxSendKbps == to.Options.MaxSendKbps &&
-		from.Options.LimitBandwidthInLan == to.Options.LimitBandwidthInLan {
-		return true
+	lim.opts = to.Options
+	lim.setOverallLimitsLocked(time.Now())
+
+	return true
+}
+
+// setOverallLimitsLocked sets the overall rate limits in effect at the
+// given time according to the options.
+func (lim *limiter) setOverallLimitsLocked(now time.Time) {
+	sendKbps, recvKbps := lim.opts.RateLimitsAt(now)
+	if sendKbps == lim.sendKbps && recvKbps == lim.recvKbps &&
+		lim.opts.LimitBandwidthInLan == lim.limitsLAN.Load() {
+		return
 	}
+	lim.sendKbps, lim.recvKbps = sendKbps, recvKbps
 
 	limited := false
 	sendLimitStr := "is unlimited"
@@ -140,35 +169,33 @@ func (lim *limiter) CommitConfiguration(from, to config.Configuration) bool {
 
 	// The rate variables are in KiB/s in the config (despite the camel casing
 	// of the name). We multiply by 1024 to get bytes/s.
-	if to.Options.MaxRecvKbps <= 0 {
+	if recvKbps <= 0 {
 		lim.read.SetLimit(rate.Inf)
 	} else {
-		lim.read.SetLimit(1024 * rate.Limit(to.Options.MaxRecvKbps))
-		recvLimitStr = fmt.Sprintf("limit is %d KiB/s", to.Options.MaxRecvKbps)
+		lim.read.SetLimit(1024 * rate.Limit(recvKbps))
+		recvLimitStr = fmt.Sprintf("limit is %d KiB/s", recvKbps)
 		limited = true
 	}
 
-	if to.Options.MaxSendKbps <= 0 {
+	if sendKbps <= 0 {
 		lim.write.SetLimit(rate.Inf)
 	} else {
-		lim.write.SetLimit(1024 * rate.Limit(to.Options.MaxSendKbps))
-		sendLimitStr = fmt.Sprintf("limit is %d KiB/s", to.Options.MaxSendKbps)
+		lim.write.SetLimit(1024 * rate.Limit(sendKbps))
+		sendLimitStr = fmt.Sprintf("limit is %d KiB/s", sendKbps)
 		limited = true
 	}
 
-	lim.limitsLAN.Store(to.Options.LimitBandwidthInLan)
+	lim.limitsLAN.Store(lim.opts.LimitBandwidthInLan)
 
 	slog.Info("Overall rate limit in use", "send", sendLimitStr, "recv", recvLimitStr)
 
 	if limited {
-		if to.Options.LimitBandwidthInLan {
+		if lim.opts.LimitBandwidthInLan {
 			slog.Info("Rate limits apply to LAN connections")
 		} else {
 			slog.Info("Rate limits do not apply to LAN connections")
 		}
 	}
-
-	return true
 }
 
 func (*limiter) String() string {
@@ -14,6 +14,7 @@ import (
 	"math/rand"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"golang.org/x/time/rate"
 
@@ -128,6 +129,43 @@ func TestSetDeviceLimits(t *testing.T) {
 	checkActualAndExpected(t, actualR, actualW, expectedR, expectedW)
 }
 
+func TestRateLimitSchedule(t *testing.T) {
+	wrapper, wrapperCancel := initConfig()
+	defer wrapperCancel()
+	lim := newLimiter(device1, wrapper)
+
+	waiter, _ := wrapper.Modify(func(cfg *config.Configuration) {
+		cfg.Options.MaxSendKbps = 100
+		cfg.Options.RateLimitSchedule = []config.RateLimitPeriod{
+			{Time: "Mon-Fri 09:00-17:00", MaxSendKbps: 10, MaxRecvKbps: 20},
+			{Time: "22:00-06:00"},
+		}
+	})
+	waiter.Wait()
+
+	cases := []struct {
+		time       string
+		send, recv rate.Limit
+	}{
+		{"2024-01-01T12:00:00Z", 10 * 1024, 20 * 1024}, // Monday
+		{"2024-01-01T18:00:00Z", 100 * 1024, rate.Inf},
+		{"2024-01-06T12:00:00Z", 100 * 1024, rate.Inf}, // Saturday
+		{"2024-01-06T23:00:00Z", rate.Inf, rate.Inf},
+	}
+	for _, tc := range cases {
+		now, _ := time.Parse(time.RFC3339, tc.time)
+		lim.mu.Lock()
+		lim.setOverallLimitsLocked(now)
+		lim.mu.Unlock()
+		if l := lim.write.Limit(); l != tc.send {
+			t.Errorf("%s: send limit %v, expected %v", tc.time, l, tc.send)
+		}
+		if l := lim.read.Limit(); l != tc.recv {
+			t.Errorf("%s: recv limit %v, expected %v", tc.time, l, tc.recv)
+		}
+	}
+}
+
 func TestRemoveDevice(t *testing.T) {
 	wrapper, wrapperCancel := initConfig()
 	defer wrapperCancel()
@@ -19,6 +19,11 @@ type Service struct {
 	allAddressesReturnsOnCall map[int]struct {
 		result1 []string
 	}
+	AnnounceMovedStub        func(func())
+	announceMovedMutex       sync.RWMutex
+	announceMovedArgsForCall []struct {
+		arg1 func()
+	}
 	ConnectionStatusStub        func() map[string]connections.ConnectionStatusEntry
 	connectionStatusMutex       sync.RWMutex
 	connectionStatusArgsForCall []struct {
@@ -70,6 +75,11 @@ type Service struct {
 	serveReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetSuspendedStub        func(bool)
+	setSuspendedMutex       sync.RWMutex
+	setSuspendedArgsForCall []struct {
+		arg1 bool
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -127,6 +137,38 @@ func (fake *Service) AllAddressesReturnsOnCall(i int, result1 []string) {
 	}{result1}
 }
 
+func (fake *Service) AnnounceMoved(arg1 func()) {
+	fake.announceMovedMutex.Lock()
+	fake.announceMovedArgsForCall = append(fake.announceMovedArgsForCall, struct {
+		arg1 func()
+	}{arg1})
+	stub := fake.AnnounceMovedStub
+	fake.recordInvocation("AnnounceMoved", []interface{}{arg1})
+	fake.announceMovedMutex.Unlock()
+	if stub != nil {
+		fake.AnnounceMovedStub(arg1)
+	}
+}
+
+func (fake *Service) AnnounceMovedCallCount() int {
+	fake.announceMovedMutex.RLock()
+	defer fake.announceMovedMutex.RUnlock()
+	return len(fake.announceMovedArgsForCall)
+}
+
+func (fake *Service) AnnounceMovedCalls(stub func(func())) {
+	fake.announceMovedMutex.Lock()
+	defer fake.announceMovedMutex.Unlock()
+	fake.AnnounceMovedStub = stub
+}
+
+func (fake *Service) AnnounceMovedArgsForCall(i int) func() {
+	fake.announceMovedMutex.RLock()
+	defer fake.announceMovedMutex.RUnlock()
+	argsForCall := fake.announceMovedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *Service) ConnectionStatus() map[string]connections.ConnectionStatusEntry {
 	fake.connectionStatusMutex.Lock()
 	ret, specificReturn := fake.connectionStatusReturnsOnCall[len(fake.connectionStatusArgsForCall)]
@@ -400,6 +442,38 @@ func (fake *Service) ServeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Service) SetSuspended(arg1 bool) {
+	fake.setSuspendedMutex.Lock()
+	fake.setSuspendedArgsForCall = append(fake.setSuspendedArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	stub := fake.SetSuspendedStub
+	fake.recordInvocation("SetSuspended", []interface{}{arg1})
+	fake.setSuspendedMutex.Unlock()
+	if stub != nil {
+		fake.SetSuspendedStub(arg1)
+	}
+}
+
+func (fake *Service) SetSuspendedCallCount() int {
+	fake.setSuspendedMutex.RLock()
+	defer fake.setSuspendedMutex.RUnlock()
+	return len(fake.setSuspendedArgsForCall)
+}
+
+func (fake *Service) SetSuspendedCalls(stub func(bool)) {
+	fake.setSuspendedMutex.Lock()
+	defer fake.setSuspendedMutex.Unlock()
+	fake.SetSuspendedStub = stub
+}
+
+func (fake *Service) SetSuspendedArgsForCall(i int) bool {
+	fake.setSuspendedMutex.RLock()
+	defer fake.setSuspendedMutex.RUnlock()
+	argsForCall := fake.setSuspendedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *Service) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -93,7 +93,10 @@ func (d *quicDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL
 		priority = d.lanPriority
 	}
 
-	return newInternalConn(&quicTlsConn{session, stream, createdConn}, connTypeQUICClient, isLocal, priority), nil
+	conn := &quicTlsConn{Conn: session, Stream: stream, createdConn: createdConn}
+	go conn.migrateOnNetworkChange()
+
+	return newInternalConn(conn, connTypeQUICClient, isLocal, priority), nil
 }
 
 type quicDialerFactory struct{}
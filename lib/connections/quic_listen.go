@@ -209,7 +209,7 @@ func (t *quicListener) serve(ctx context.Context) error {
 		if isLocal {
 			priority = t.cfg.Options().ConnectionPriorityQUICLAN
 		}
-		t.conns <- newInternalConn(&quicTlsConn{session, stream, nil}, connTypeQUICServer, isLocal, priority)
+		t.conns <- newInternalConn(&quicTlsConn{Conn: session, Stream: stream}, connTypeQUICServer, isLocal, priority)
 	}
 }
 
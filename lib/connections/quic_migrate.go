@@ -0,0 +1,129 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !noquic
+// +build !noquic
+
+package connections
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+)
+
+const (
+	// How often a dialed QUIC connection checks whether the local address
+	// towards the remote device has changed.
+	quicMigrationCheckInterval = 5 * time.Second
+)
+
+// migrateOnNetworkChange keeps a dialed QUIC connection alive over changes
+// in the local network, such as a mobile device moving from Wi-Fi to
+// cellular. When the local address used to reach the remote changes it
+// probes a path from a new socket and switches the connection over to it,
+// so that streams and transfers in progress carry on. It returns when the
+// connection is closed, closing the transport it migrated to.
+func (q *quicTlsConn) migrateOnNetworkChange() {
+	ctx := q.Conn.Context()
+	remote := q.Conn.RemoteAddr()
+	current := localAddrTowards(remote)
+
+	defer func() {
+		q.mut.Lock()
+		transport := q.migrated
+		q.migrated = nil
+		q.mut.Unlock()
+		if transport != nil {
+			_ = transport.Close()
+		}
+	}()
+
+	t := time.NewTicker(quicMigrationCheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		addr := localAddrTowards(remote)
+		if addr == nil || current != nil && addr.Equal(current) {
+			continue
+		}
+		if err := q.migrate(ctx); err != nil {
+			slog.DebugContext(ctx, "Failed to migrate QUIC connection", slogutil.Address(remote), slogutil.Error(err))
+			continue
+		}
+		slog.DebugContext(ctx, "Migrated QUIC connection", slogutil.Address(remote), slog.String("local", addr.String()))
+		current = addr
+	}
+}
+
+// migrate moves the connection to a path from a newly created socket. The
+// socket and its transport replace the ones we created when dialing or
+// migrating before, if any.
+func (q *quicTlsConn) migrate(ctx context.Context) error {
+	packetConn, err := net.ListenPacket("udp", ":0")
+	if err != nil {
+		return err
+	}
+	transport := &quic.Transport{Conn: packetConn}
+	path, err := q.Conn.AddPath(transport)
+	if err != nil {
+		_ = transport.Close()
+		_ = packetConn.Close()
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, quicOperationTimeout)
+	defer cancel()
+	if err := path.Probe(ctx); err != nil {
+		_ = path.Close()
+		_ = transport.Close()
+		_ = packetConn.Close()
+		return err
+	}
+	if err := path.Switch(); err != nil {
+		_ = path.Close()
+		_ = transport.Close()
+		_ = packetConn.Close()
+		return err
+	}
+
+	q.mut.Lock()
+	oldConn, oldTransport := q.createdConn, q.migrated
+	q.createdConn, q.migrated = packetConn, transport
+	q.mut.Unlock()
+	if oldTransport != nil {
+		// This doesn't close the socket, which we created.
+		_ = oldTransport.Close()
+	}
+	if oldConn != nil {
+		_ = oldConn.Close()
+	}
+	return nil
+}
+
+// localAddrTowards returns the local address the system would use to send
+// to the given address, or nil if there is no route. No packets are sent.
+func localAddrTowards(addr net.Addr) net.IP {
+	conn, err := net.Dial("udp", addr.String())
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+	if udpAddr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		return udpAddr.IP
+	}
+	return nil
+}
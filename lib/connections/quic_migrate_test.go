@@ -0,0 +1,77 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !noquic
+// +build !noquic
+
+package connections
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+func TestQUICMigrate(t *testing.T) {
+	withConnectionPair(t, "quic://127.0.0.1:0", func(client, server internalConn) {
+		q := client.tlsConn.(*quicTlsConn)
+		q.mut.Lock()
+		dialedConn := q.createdConn
+		q.mut.Unlock()
+
+		if err := q.migrate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		// The connection carries on over the new path.
+		send := []byte("after migration")
+		if _, err := client.Write(send); err != nil {
+			t.Fatal(err)
+		}
+		recv := make([]byte, len(send))
+		if _, err := io.ReadFull(server, recv); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(recv, send) {
+			t.Fatal("data mismatch")
+		}
+
+		q.mut.Lock()
+		migratedConn, migrated := q.createdConn, q.migrated
+		q.mut.Unlock()
+		if migratedConn == dialedConn || migrated == nil {
+			t.Fatal("expected the connection to use a new socket and transport")
+		}
+		if _, err := dialedConn.WriteTo([]byte("x"), server.RemoteAddr()); !errors.Is(err, net.ErrClosed) {
+			t.Error("expected the socket from dialing to be closed, got", err)
+		}
+
+		// Closing the connection closes the transport it migrated to.
+		_ = client.Close()
+		for i := 0; ; i++ {
+			q.mut.Lock()
+			closed := q.migrated == nil
+			q.mut.Unlock()
+			if closed {
+				break
+			}
+			if i == 100 {
+				t.Fatal("timed out waiting for the transport to be closed")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if _, err := migrated.Dial(context.Background(), server.RemoteAddr(), &tls.Config{}, quicConfig); !errors.Is(err, quic.ErrTransportClosed) {
+			t.Error("expected the migrated transport to be closed, got", err)
+		}
+	})
+}
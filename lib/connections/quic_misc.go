@@ -14,6 +14,7 @@ import (
 	"crypto/tls"
 	"net"
 	"net/url"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -43,16 +44,22 @@ type quicTlsConn struct {
 	*quic.Stream
 
 	// If we created this connection, we should be the ones closing it.
+	// It changes when the connection migrates, as does the transport
+	// we created for it.
+	mut         sync.Mutex
 	createdConn net.PacketConn
+	migrated    *quic.Transport
 }
 
 func (q *quicTlsConn) Close() error {
 	sterr := q.Stream.Close()
 	seerr := q.Conn.CloseWithError(0, "closing")
 	var pcerr error
+	q.mut.Lock()
 	if q.createdConn != nil {
 		pcerr = q.createdConn.Close()
 	}
+	q.mut.Unlock()
 	if sterr != nil {
 		return sterr
 	}
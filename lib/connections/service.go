@@ -25,6 +25,7 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/thejerf/suture/v4"
@@ -40,6 +41,7 @@ import (
 	"github.com/syncthing/syncthing/lib/protocol"
 	"github.com/syncthing/syncthing/lib/semaphore"
 	"github.com/syncthing/syncthing/lib/sliceutil"
+	"github.com/syncthing/syncthing/lib/stats"
 	"github.com/syncthing/syncthing/lib/stringutil"
 	"github.com/syncthing/syncthing/lib/svcutil"
 
@@ -65,13 +67,20 @@ var (
 
 	// Various reasons to reject a connection
 	errNetworkNotAllowed      = errors.New("network not allowed")
+	errInterfaceNotAllowed    = errors.New("interface not allowed")
+	errTimeNotAllowed         = errors.New("outside of allowed times")
+	errRelaysDisabled         = errors.New("relays disabled by data quota")
 	errDeviceAlreadyConnected = errors.New("already connected to this device")
 	errDeviceIgnored          = errors.New("device is ignored")
 	errConnLimitReached       = errors.New("connection limit reached")
 	errDevicePaused           = errors.New("device is paused")
+	errGloballyPaused         = errors.New("syncing is paused")
+	errDeviceNotAccepted      = errors.New("device not accepted")
+	errBadCertificateName     = errors.New("bad certificate name")
 
 	// A connection is being closed to make space for better ones
 	errReplacingConnection = errors.New("replacing connection")
+	errDeviceMoved         = errors.New("device moved to other hardware")
 )
 
 const (
@@ -132,6 +141,13 @@ type Service interface {
 	ListenerStatus() map[string]ListenerStatusEntry
 	ConnectionStatus() map[string]ConnectionStatusEntry
 	NATType() string
+	// SetSuspended stops (or restarts) listening and dialing, e.g. while a
+	// mobile app is in the background. Existing connections are kept.
+	SetSuspended(suspended bool)
+	// AnnounceMoved tells the devices we connect to from now on that our
+	// identity was just migrated to this machine, until each of them has
+	// taken note. acknowledged is called once the first one has.
+	AnnounceMoved(acknowledged func())
 }
 
 type ListenerStatusEntry struct {
@@ -145,12 +161,20 @@ type ConnectionStatusEntry struct {
 	Error *string   `json:"error"`
 }
 
+// addressForgetter is implemented by discoverers that cache the addresses
+// they find.
+type addressForgetter interface {
+	Forget(deviceID protocol.DeviceID)
+}
+
 type connWithHello struct {
-	c          internalConn
-	hello      protocol.Hello
-	err        error
-	remoteID   protocol.DeviceID
-	remoteCert *x509.Certificate
+	c                internalConn
+	hello            protocol.Hello
+	err              error
+	remoteID         protocol.DeviceID
+	remoteCert       *x509.Certificate
+	alreadyConnected bool // accepted only if the device says it moved
+	announcedMove    bool // we told the device that we moved
 }
 
 type service struct {
@@ -181,6 +205,14 @@ type service struct {
 	listenersMut   sync.RWMutex
 	listeners      map[string]genericListener
 	listenerTokens map[string]suture.ServiceToken
+
+	suspended atomic.Bool
+
+	// Devices to tell that we moved, and whether they have taken note;
+	// nil unless we did move. See AnnounceMoved.
+	movedMut          sync.Mutex
+	moved             map[protocol.DeviceID]bool
+	movedAcknowledged func()
 }
 
 func NewService(cfg config.Wrapper, myID protocol.DeviceID, mdl Model, tlsCfg *tls.Config, discoverer discover.Finder, bepProtocolName string, tlsDefaultCommonName string, evLogger events.Logger, registry *registry.Registry, keyGen *protocol.KeyGenerator) Service {
@@ -228,6 +260,7 @@ func NewService(cfg config.Wrapper, myID protocol.DeviceID, mdl Model, tlsCfg *t
 	service.Add(svcutil.AsService(service.connect, fmt.Sprintf("%s/connect", service)))
 	service.Add(svcutil.AsService(service.handleConns, fmt.Sprintf("%s/handleConns", service)))
 	service.Add(svcutil.AsService(service.handleHellos, fmt.Sprintf("%s/handleHellos", service)))
+	service.Add(svcutil.AsService(service.limiter.serve, fmt.Sprintf("%s/limiter", service)))
 	service.Add(service.natService)
 
 	svcutil.OnSupervisorDone(service.Supervisor, func() {
@@ -278,8 +311,14 @@ func (s *service) handleConns(ctx context.Context) error {
 			continue
 		}
 
-		if err := s.connectionCheckEarly(remoteID, c); err != nil {
+		// A device that moved to other hardware leaves connections from
+		// where it was, which take a while to time out. Until they do, it
+		// can only connect again by telling us in its Hello that it moved.
+		err := s.connectionCheckEarly(remoteID, c)
+		alreadyConnected := errors.Is(err, errDeviceAlreadyConnected)
+		if err != nil && !alreadyConnected {
 			slog.DebugContext(ctx, "Connection rejected", remoteID.LogAttr(), slogutil.Address(c.RemoteAddr()), slog.String("type", c.Type()), slogutil.Error(err))
+			s.logRejection(remoteID, c, err)
 			c.Close()
 			continue
 		}
@@ -293,7 +332,7 @@ func (s *service) handleConns(ctx context.Context) error {
 			c.connectionID = newConnectionID(outgoing.Timestamp, incoming.Timestamp)
 
 			select {
-			case s.hellos <- &connWithHello{c, incoming, err, remoteID, remoteCert}:
+			case s.hellos <- &connWithHello{c, incoming, err, remoteID, remoteCert, alreadyConnected, outgoing.Moved}:
 			case <-ctx.Done():
 			}
 		}()
@@ -305,6 +344,7 @@ func (s *service) helloForDevice(remoteID protocol.DeviceID) protocol.Hello {
 		ClientName:    "syncthing",
 		ClientVersion: build.Version,
 		Timestamp:     time.Now().UnixNano(),
+		Moved:         s.moveUnacknowledged(remoteID),
 	}
 	if cfg, ok := s.cfg.Device(remoteID); ok {
 		hello.NumConnections = cfg.NumConnections()
@@ -327,6 +367,10 @@ func (s *service) connectionCheckEarly(remoteID protocol.DeviceID, c internalCon
 		return errConnLimitReached
 	}
 
+	if s.cfg.Options().GlobalPause.Active(time.Now()) {
+		return errGloballyPaused
+	}
+
 	cfg, ok := s.cfg.Device(remoteID)
 	if !ok {
 		// We do go ahead exchanging hello messages to get information about the device.
@@ -342,6 +386,21 @@ func (s *service) connectionCheckEarly(remoteID protocol.DeviceID, c internalCon
 		return errNetworkNotAllowed
 	}
 
+	if len(cfg.AllowedInterfaces) > 0 && !IsAllowedInterface(c.LocalAddr(), cfg.AllowedInterfaces) {
+		// The connection is not on an allowed interface.
+		return errInterfaceNotAllowed
+	}
+
+	if !cfg.IsAllowedTime(time.Now()) {
+		return errTimeNotAllowed
+	}
+
+	if c.connType.Transport() == "relay" && cfg.QuotaAction == config.QuotaActionDisableRelays && cfg.MonthlyQuotaMiB > 0 {
+		if st, err := s.model.DeviceStatistics(); err == nil && !relaysAllowed(cfg, st[remoteID]) {
+			return errRelaysDisabled
+		}
+	}
+
 	currentConns := s.numConnectionsForDevice(cfg.DeviceID)
 	desiredConns := s.desiredConnectionsToDevice(cfg.DeviceID)
 	worstPrio := s.worstConnectionPriority(remoteID)
@@ -361,6 +420,7 @@ func (s *service) handleHellos(ctx context.Context) error {
 		var err error
 		var remoteID protocol.DeviceID
 		var remoteCert *x509.Certificate
+		var alreadyConnected, announcedMove bool
 
 		select {
 		case <-ctx.Done():
@@ -371,6 +431,8 @@ func (s *service) handleHellos(ctx context.Context) error {
 			err = withHello.err
 			remoteID = withHello.remoteID
 			remoteCert = withHello.remoteCert
+			alreadyConnected = withHello.alreadyConnected
+			announcedMove = withHello.announcedMove
 		}
 
 		if err != nil {
@@ -385,10 +447,18 @@ func (s *service) handleHellos(ctx context.Context) error {
 		}
 		_ = c.SetDeadline(time.Time{})
 
+		if alreadyConnected && !hello.Moved {
+			slog.DebugContext(ctx, "Connection rejected", remoteID.LogAttr(), slogutil.Address(c.RemoteAddr()), slog.String("type", c.Type()), slogutil.Error(errDeviceAlreadyConnected))
+			s.logRejection(remoteID, c, errDeviceAlreadyConnected)
+			c.Close()
+			continue
+		}
+
 		// The Model will return an error for devices that we don't want to
 		// have a connection with for whatever reason, for example unknown devices.
 		if err := s.model.OnHello(remoteID, c.RemoteAddr(), hello); err != nil {
 			slog.WarnContext(ctx, "Connection rejected", remoteID.LogAttr(), slogutil.Address(c.RemoteAddr()), slog.Any("type", c.Type()), slogutil.Error(err))
+			s.logRejection(remoteID, c, fmt.Errorf("%w: %w", errDeviceNotAccepted, err))
 			c.Close()
 			continue
 		}
@@ -416,16 +486,31 @@ func (s *service) handleHellos(ctx context.Context) error {
 			// likely wants to know about, since it's an advanced
 			// config. Warn instead of Info.
 			slog.ErrorContext(ctx, "Bad certificate from remote", remoteID.LogAttr(), slogutil.Address(c.RemoteAddr()), slogutil.Error(err))
+			s.logRejection(remoteID, c, fmt.Errorf("%w: %w", errBadCertificateName, err))
 			c.Close()
 			continue
 		}
 
+		if hello.Moved {
+			// Whatever we cached about where to find the device is stale
+			// now, as are the connections to it, which are closed once
+			// this one is added.
+			slog.InfoContext(ctx, "Device identity was migrated to other hardware", remoteID.LogAttr(), slogutil.Address(c.RemoteAddr()))
+			if f, ok := s.discoverer.(addressForgetter); ok {
+				f.Forget(remoteID)
+			}
+		}
+
 		// Wrap the connection in rate limiters. The limiter itself will
 		// keep up with config changes to the rate and whether or not LAN
 		// connections are limited.
 		rd, wr := s.limiter.getLimiters(remoteID, c, c.IsLocal())
 
-		protoConn := protocol.NewConnection(remoteID, rd, wr, c, s.model, c, deviceCfg.Compression.ToProtocol(), s.keyGen)
+		var mdl protocol.Model = s.model
+		if announcedMove {
+			mdl = &moveAcknowledger{Model: s.model, acknowledge: func() { s.moveAcknowledged(remoteID) }}
+		}
+		protoConn := protocol.NewConnection(remoteID, rd, wr, c, mdl, c, deviceCfg.Compression.ToProtocol(), s.keyGen)
 		s.accountAddedConnection(protoConn, hello, s.cfg.Options().ConnectionPriorityUpgradeThreshold)
 		go func() {
 			<-protoConn.Closed()
@@ -467,6 +552,10 @@ func (s *service) connect(ctx context.Context) error {
 		// while we try connections etc.
 		now := time.Now()
 
+		// Disconnect devices we're no longer allowed to be connected to at
+		// this time.
+		s.closeConnectionsOutsideAllowedTimes(now, cfg)
+
 		// Attempt to dial all devices that are unconnected or can be connection-upgraded
 		s.dialDevices(ctx, now, cfg, bestDialerPriority, nextDialAt, isInitialRampup)
 
@@ -510,6 +599,15 @@ func (s *service) connect(ctx context.Context) error {
 	}
 }
 
+func (s *service) closeConnectionsOutsideAllowedTimes(now time.Time, cfg config.Configuration) {
+	for _, deviceCfg := range cfg.Devices {
+		if !deviceCfg.IsAllowedTime(now) && s.numConnectionsForDevice(deviceCfg.DeviceID) > 0 {
+			slog.Info("Disconnecting device outside of allowed times", deviceCfg.DeviceID.LogAttr())
+			s.closeConnectionsForDevice(deviceCfg.DeviceID, errTimeNotAllowed)
+		}
+	}
+}
+
 func (s *service) bestDialerPriority(cfg config.Configuration) int {
 	bestDialerPriority := worstDialerPriority
 	for _, df := range dialers {
@@ -525,6 +623,15 @@ func (s *service) bestDialerPriority(cfg config.Configuration) int {
 }
 
 func (s *service) dialDevices(ctx context.Context, now time.Time, cfg config.Configuration, bestDialerPriority int, nextDialAt nextDialRegistry, initial bool) {
+	if s.suspended.Load() {
+		l.Debugln("Skipping dial because we're suspended")
+		return
+	}
+	if cfg.Options.GlobalPause.Active(now) {
+		l.Debugln("Skipping dial because syncing is paused")
+		return
+	}
+
 	// Figure out current connection limits up front to see if there's any
 	// point in resolving devices and such at all.
 	allowAdditional := 0 // no limit
@@ -554,6 +661,11 @@ func (s *service) dialDevices(ctx context.Context, now time.Time, cfg config.Con
 			continue
 		}
 
+		// ... nor to devices outside of their allowed times.
+		if !deviceCfg.IsAllowedTime(now) {
+			continue
+		}
+
 		// See if we are already connected and, if so, what our cutoff is
 		// for dialer priority.
 		priorityCutoff := worstDialerPriority
@@ -575,7 +687,7 @@ func (s *service) dialDevices(ctx context.Context, now time.Time, cfg config.Con
 			}
 		}
 
-		dialTargets := s.resolveDialTargets(ctx, now, cfg, deviceCfg, nextDialAt, initial, priorityCutoff)
+		dialTargets := s.resolveDialTargets(ctx, now, cfg, deviceCfg, stats[deviceCfg.DeviceID], nextDialAt, initial, priorityCutoff)
 		if len(dialTargets) > 0 {
 			queue = append(queue, dialQueueEntry{
 				id:         deviceCfg.DeviceID,
@@ -634,10 +746,11 @@ func (s *service) dialDevices(ctx context.Context, now time.Time, cfg config.Con
 	}
 }
 
-func (s *service) resolveDialTargets(ctx context.Context, now time.Time, cfg config.Configuration, deviceCfg config.DeviceConfiguration, nextDialAt nextDialRegistry, initial bool, priorityCutoff int) []dialTarget {
+func (s *service) resolveDialTargets(ctx context.Context, now time.Time, cfg config.Configuration, deviceCfg config.DeviceConfiguration, deviceStats stats.DeviceStatistics, nextDialAt nextDialRegistry, initial bool, priorityCutoff int) []dialTarget {
 	deviceID := deviceCfg.DeviceID
+	allowRelays := relaysAllowed(deviceCfg, deviceStats)
 
-	addrs := s.resolveDeviceAddrs(ctx, deviceCfg)
+	addrs := s.resolveDeviceAddrs(ctx, deviceCfg, deviceStats.LastAddress)
 	l.Debugln("Resolved device", deviceID.Short(), "addresses:", addrs)
 
 	dialTargets := make([]dialTarget, 0, len(addrs))
@@ -660,6 +773,11 @@ func (s *service) resolveDialTargets(ctx context.Context, now time.Time, cfg con
 			continue
 		}
 
+		if uri.Scheme == "relay" && !allowRelays {
+			l.Debugf("Not dialing %s via %v as the device exceeded its data quota", deviceID.Short(), addr)
+			continue
+		}
+
 		if len(deviceCfg.AllowedNetworks) > 0 {
 			if !IsAllowedNetwork(uri.Host, deviceCfg.AllowedNetworks) {
 				s.setConnectionStatus(addr, errors.New("network disallowed"))
@@ -710,10 +828,22 @@ func (s *service) resolveDialTargets(ctx context.Context, now time.Time, cfg con
 	return dialTargets
 }
 
-func (s *service) resolveDeviceAddrs(ctx context.Context, cfg config.DeviceConfiguration) []string {
+// relaysAllowed returns false if connecting to the device over relays is
+// disabled for having exceeded its monthly data quota.
+func relaysAllowed(cfg config.DeviceConfiguration, st stats.DeviceStatistics) bool {
+	return cfg.QuotaAction != config.QuotaActionDisableRelays || !cfg.QuotaExceeded(st.MonthlyUsage.TotalBytes())
+}
+
+// resolveDeviceAddrs returns the addresses to dial the device at. For
+// dynamic addresses that includes the address we were last connected at,
+// as discovery may not know about the device yet right after startup.
+func (s *service) resolveDeviceAddrs(ctx context.Context, cfg config.DeviceConfiguration, lastAddr string) []string {
 	var addrs []string
 	for _, addr := range cfg.Addresses {
 		if addr == "dynamic" {
+			if lastAddr != "" {
+				addrs = append(addrs, lastAddr)
+			}
 			if s.discoverer != nil {
 				if t, err := s.discoverer.Lookup(ctx, cfg.DeviceID); err == nil {
 					addrs = append(addrs, t...)
@@ -841,9 +971,14 @@ func (s *service) CommitConfiguration(from, to config.Configuration) bool {
 
 	s.checkAndSignalConnectLoopOnUpdatedDevices(from, to)
 
+	listenAddrs := to.Options.ListenAddresses()
+	if s.suspended.Load() {
+		listenAddrs = nil
+	}
+
 	s.listenersMut.Lock()
 	seen := make(map[string]struct{})
-	for _, addr := range to.Options.ListenAddresses() {
+	for _, addr := range listenAddrs {
 		if addr == "" {
 			// We can get an empty address if there is an empty listener
 			// element in the config, indicating no listeners should be
@@ -899,18 +1034,75 @@ func (s *service) CommitConfiguration(from, to config.Configuration) bool {
 	return true
 }
 
+func (s *service) SetSuspended(suspended bool) {
+	if s.suspended.Swap(suspended) == suspended {
+		return
+	}
+	// Stops or starts the listeners.
+	raw := s.cfg.RawCopy()
+	s.CommitConfiguration(raw, raw)
+	if !suspended {
+		s.scheduleDialNow()
+	}
+}
+
+func (s *service) AnnounceMoved(acknowledged func()) {
+	s.movedMut.Lock()
+	defer s.movedMut.Unlock()
+	s.moved = make(map[protocol.DeviceID]bool)
+	s.movedAcknowledged = acknowledged
+}
+
+// moveUnacknowledged returns whether we moved and the device hasn't taken
+// note yet, so that the Hello to it should say so.
+func (s *service) moveUnacknowledged(device protocol.DeviceID) bool {
+	s.movedMut.Lock()
+	defer s.movedMut.Unlock()
+	return s.moved != nil && !s.moved[device]
+}
+
+func (s *service) moveAcknowledged(device protocol.DeviceID) {
+	s.movedMut.Lock()
+	if s.moved == nil || s.moved[device] {
+		s.movedMut.Unlock()
+		return
+	}
+	s.moved[device] = true
+	acknowledged := s.movedAcknowledged
+	s.movedAcknowledged = nil
+	s.movedMut.Unlock()
+	if acknowledged != nil {
+		acknowledged()
+	}
+}
+
+// moveAcknowledger is the model for a connection on which we told the
+// device that we moved. The device has taken note once it sends its
+// cluster config, as it handles the Hello before that.
+type moveAcknowledger struct {
+	protocol.Model
+	acknowledge func()
+}
+
+func (m *moveAcknowledger) ClusterConfig(conn protocol.Connection, config *protocol.ClusterConfig) error {
+	m.acknowledge()
+	return m.Model.ClusterConfig(conn, config)
+}
+
 func (s *service) checkAndSignalConnectLoopOnUpdatedDevices(from, to config.Configuration) {
 	oldDevices := from.DeviceMap()
+	// Ending a global pause is like resuming all devices.
+	resumed := from.Options.GlobalPause.Paused && !to.Options.GlobalPause.Paused
 	dial := false
 	s.dialNowDevicesMut.Lock()
 	for _, dev := range to.Devices {
 		if dev.Paused {
 			continue
 		}
-		if oldDev, ok := oldDevices[dev.DeviceID]; !ok || oldDev.Paused {
+		if oldDev, ok := oldDevices[dev.DeviceID]; !ok || oldDev.Paused || resumed {
 			s.dialNowDevices[dev.DeviceID] = struct{}{}
 			dial = true
-		} else if !slices.Equal(oldDev.Addresses, dev.Addresses) {
+		} else if !slices.Equal(oldDev.Addresses, dev.Addresses) || !slices.Equal(oldDev.AllowedTimes, dev.AllowedTimes) {
 			dial = true
 		}
 	}
@@ -1095,6 +1287,81 @@ func IsAllowedNetwork(host string, allowed []string) bool {
 	return false
 }
 
+// IsAllowedInterface returns true if the given local address belongs to
+// one of the named network interfaces.
+func IsAllowedInterface(local net.Addr, allowed []string) bool {
+	host := local.String()
+	if hostNoPort, _, err := net.SplitHostPort(host); err == nil {
+		host = hostNoPort
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, name := range allowed {
+		intf, err := net.InterfaceByName(name)
+		if err != nil {
+			continue
+		}
+		addrs, err := intf.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.Equal(ip) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// rejectionReason returns the reason for rejecting a connection, if it is
+// relevant to security, and whether it's due to the connection constraints
+// of the device.
+func rejectionReason(err error) (reason string, constraint, ok bool) {
+	switch {
+	case errors.Is(err, errNetworkNotAllowed):
+		return "network", true, true
+	case errors.Is(err, errInterfaceNotAllowed):
+		return "interface", true, true
+	case errors.Is(err, errTimeNotAllowed):
+		return "time", true, true
+	case errors.Is(err, errDeviceIgnored):
+		return "ignored", false, true
+	case errors.Is(err, errDevicePaused):
+		return "paused", false, true
+	case errors.Is(err, errDeviceNotAccepted):
+		return "notAccepted", false, true
+	case errors.Is(err, errBadCertificateName):
+		return "certificateName", false, true
+	default:
+		// Already connected, connection limits and the like.
+		return "", false, false
+	}
+}
+
+// logRejection emits the events for a rejected connection.
+func (s *service) logRejection(remoteID protocol.DeviceID, c internalConn, err error) {
+	reason, constraint, ok := rejectionReason(err)
+	if !ok {
+		return
+	}
+	data := map[string]string{
+		"device":  remoteID.String(),
+		"address": c.RemoteAddr().String(),
+		"type":    c.Type(),
+		"reason":  reason,
+		"error":   err.Error(),
+	}
+	if constraint {
+		s.evLogger.Log(events.ConnectionRejected, data)
+	}
+	events.LogSecurity(s.evLogger, events.SecurityConnectionRejected, data)
+}
+
 func (s *service) dialParallel(ctx context.Context, deviceID protocol.DeviceID, dialTargets []dialTarget, parentSema *semaphore.Semaphore) (internalConn, bool) {
 	// Group targets into buckets by priority
 	dialTargetBuckets := make(map[int][]dialTarget, len(dialTargets))
@@ -1323,9 +1590,10 @@ func (s *service) desiredConnectionsToDevice(deviceID protocol.DeviceID) int {
 // connected to and how many connections we have to each device. It also
 // tracks how many connections they are willing to use.
 type deviceConnectionTracker struct {
-	connectionsMut  sync.Mutex
-	connections     map[protocol.DeviceID][]protocol.Connection // current connections
-	wantConnections map[protocol.DeviceID]int                   // number of connections they want
+	connectionsMut   sync.Mutex
+	connections      map[protocol.DeviceID][]protocol.Connection // current connections
+	wantConnections  map[protocol.DeviceID]int                   // number of connections they want
+	movedConnections map[string]struct{}                         // IDs of connections on which the device said it moved
 }
 
 func (c *deviceConnectionTracker) accountAddedConnection(conn protocol.Connection, h protocol.Hello, upgradeThreshold int) {
@@ -1335,6 +1603,7 @@ func (c *deviceConnectionTracker) accountAddedConnection(conn protocol.Connectio
 	if c.connections == nil {
 		c.connections = make(map[protocol.DeviceID][]protocol.Connection)
 		c.wantConnections = make(map[protocol.DeviceID]int)
+		c.movedConnections = make(map[string]struct{})
 	}
 	// Add the connection to the list of current connections and remember
 	// how many total connections they want
@@ -1348,6 +1617,11 @@ func (c *deviceConnectionTracker) accountAddedConnection(conn protocol.Connectio
 
 	// Close any connections we no longer want to retain.
 	c.closeWorsePriorityConnectionsLocked(d, conn.Priority()-upgradeThreshold)
+
+	if h.Moved {
+		c.movedConnections[conn.ConnectionID()] = struct{}{}
+		c.closeUnmovedConnectionsLocked(d)
+	}
 }
 
 func (c *deviceConnectionTracker) accountRemovedConnection(conn protocol.Connection) {
@@ -1362,6 +1636,7 @@ func (c *deviceConnectionTracker) accountRemovedConnection(conn protocol.Connect
 			break
 		}
 	}
+	delete(c.movedConnections, cid)
 	// Clean up if required
 	if len(c.connections[d]) == 0 {
 		delete(c.connections, d)
@@ -1407,6 +1682,18 @@ func (c *deviceConnectionTracker) worstConnectionPriority(d protocol.DeviceID) i
 	return worstPriority
 }
 
+// closeUnmovedConnectionsLocked closes the connections to the given device
+// other than those on which it said it moved, as they are to where it was
+// before. Must be called with the lock held.
+func (c *deviceConnectionTracker) closeUnmovedConnectionsLocked(d protocol.DeviceID) {
+	for _, conn := range c.connections[d] {
+		if _, ok := c.movedConnections[conn.ConnectionID()]; !ok {
+			l.Debugf("Closing connection %s to %s, which moved", conn, d.Short())
+			go conn.Close(errDeviceMoved)
+		}
+	}
+}
+
 // closeWorsePriorityConnectionsLocked closes all connections to the given
 // device that are worse than the cutoff priority. Must be called with the
 // lock held.
@@ -1419,6 +1706,14 @@ func (c *deviceConnectionTracker) closeWorsePriorityConnectionsLocked(d protocol
 	}
 }
 
+func (c *deviceConnectionTracker) closeConnectionsForDevice(d protocol.DeviceID, err error) {
+	c.connectionsMut.Lock()
+	defer c.connectionsMut.Unlock()
+	for _, conn := range c.connections[d] {
+		go conn.Close(err)
+	}
+}
+
 // newConnectionID generates a connection ID. The connection ID is designed
 // to be unique for each connection and chronologically sortable. It is
 // based on the sum of two timestamps: when we think the connection was
@@ -9,6 +9,7 @@ package connections
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -126,6 +127,24 @@ func (c internalConn) Crypto() string {
 	return fmt.Sprintf("%s-%s", tlsVersionNames[cs.Version], tlsCipherSuiteNames[cs.CipherSuite])
 }
 
+// SetSocketBuffers sets the send and receive buffer sizes of the TCP
+// socket underneath the connection. QUIC connections share the socket of
+// the listener and tune their flow control windows by themselves.
+func (c internalConn) SetSocketBuffers(size int) error {
+	nc, ok := c.tlsConn.(interface{ NetConn() net.Conn })
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	tc, ok := nc.NetConn().(*net.TCPConn)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	if err := tc.SetReadBuffer(size); err != nil {
+		return err
+	}
+	return tc.SetWriteBuffer(size)
+}
+
 func (c internalConn) Transport() string {
 	transport := c.connType.Transport()
 	ip, err := osutil.IPFromAddr(c.RemoteAddr())
@@ -57,6 +57,12 @@ func (c *cache) Get(id protocol.DeviceID) (CacheEntry, bool) {
 	return ce, ok
 }
 
+func (c *cache) Delete(id protocol.DeviceID) {
+	c.mut.Lock()
+	delete(c.entries, id)
+	c.mut.Unlock()
+}
+
 func (c *cache) Cache() map[protocol.DeviceID]CacheEntry {
 	c.mut.Lock()
 	m := make(map[protocol.DeviceID]CacheEntry, len(c.entries))
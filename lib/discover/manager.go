@@ -37,6 +37,10 @@ import (
 type Manager interface {
 	FinderService
 	ChildErrors() map[string]error
+	// AddFinder adds a Finder that is not managed by the configuration,
+	// such as one provided by a plugin, until removed by RemoveFinder.
+	AddFinder(identity string, finder Finder, cacheTime, negCacheTime time.Duration)
+	RemoveFinder(identity string)
 }
 
 type manager struct {
@@ -50,6 +54,7 @@ type manager struct {
 	registry      *registry.Registry
 
 	finders map[string]cachedFinder
+	extra   map[string]struct{} // identities of finders added by AddFinder
 	mut     sync.RWMutex
 }
 
@@ -64,6 +69,7 @@ func NewManager(myID protocol.DeviceID, cfg config.Wrapper, cert tls.Certificate
 		registry:      registry,
 
 		finders: make(map[string]cachedFinder),
+		extra:   make(map[string]struct{}),
 	}
 	m.Add(svcutil.AsService(m.serve, m.String()))
 	return m
@@ -108,6 +114,33 @@ func (m *manager) removeLocked(identity string) {
 	slog.Info("Stopped using discovery mechanism", "identity", identity)
 }
 
+func (m *manager) AddFinder(identity string, finder Finder, cacheTime, negCacheTime time.Duration) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.removeLocked(identity)
+	m.addLocked(identity, finder, cacheTime, negCacheTime)
+	m.extra[identity] = struct{}{}
+}
+
+func (m *manager) RemoveFinder(identity string) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if _, ok := m.extra[identity]; ok {
+		m.removeLocked(identity)
+		delete(m.extra, identity)
+	}
+}
+
+// Forget drops the cached lookup results for the device, so that it is
+// looked up afresh next time.
+func (m *manager) Forget(deviceID protocol.DeviceID) {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	for _, finder := range m.finders {
+		finder.cache.Delete(deviceID)
+	}
+}
+
 // Lookup attempts to resolve the device ID using any of the added Finders,
 // while obeying the cache settings.
 func (m *manager) Lookup(ctx context.Context, deviceID protocol.DeviceID) (addresses []string, err error) {
@@ -232,6 +265,9 @@ func (m *manager) CommitConfiguration(_, to config.Configuration) (handled bool)
 	m.mut.Lock()
 	defer m.mut.Unlock()
 	toIdentities := make(map[string]struct{})
+	for identity := range m.extra {
+		toIdentities[identity] = struct{}{}
+	}
 	if to.Options.GlobalAnnEnabled {
 		for _, srv := range to.Options.GlobalDiscoveryServers() {
 			toIdentities[globalDiscoveryIdentity(srv)] = struct{}{}
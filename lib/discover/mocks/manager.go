@@ -4,12 +4,21 @@ package mocks
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/syncthing/syncthing/lib/discover"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
 
 type Manager struct {
+	AddFinderStub        func(string, discover.Finder, time.Duration, time.Duration)
+	addFinderMutex       sync.RWMutex
+	addFinderArgsForCall []struct {
+		arg1 string
+		arg2 discover.Finder
+		arg3 time.Duration
+		arg4 time.Duration
+	}
 	CacheStub        func() map[protocol.DeviceID]discover.CacheEntry
 	cacheMutex       sync.RWMutex
 	cacheArgsForCall []struct {
@@ -54,6 +63,11 @@ type Manager struct {
 		result1 []string
 		result2 error
 	}
+	RemoveFinderStub        func(string)
+	removeFinderMutex       sync.RWMutex
+	removeFinderArgsForCall []struct {
+		arg1 string
+	}
 	ServeStub        func(context.Context) error
 	serveMutex       sync.RWMutex
 	serveArgsForCall []struct {
@@ -79,6 +93,41 @@ type Manager struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *Manager) AddFinder(arg1 string, arg2 discover.Finder, arg3 time.Duration, arg4 time.Duration) {
+	fake.addFinderMutex.Lock()
+	fake.addFinderArgsForCall = append(fake.addFinderArgsForCall, struct {
+		arg1 string
+		arg2 discover.Finder
+		arg3 time.Duration
+		arg4 time.Duration
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.AddFinderStub
+	fake.recordInvocation("AddFinder", []interface{}{arg1, arg2, arg3, arg4})
+	fake.addFinderMutex.Unlock()
+	if stub != nil {
+		fake.AddFinderStub(arg1, arg2, arg3, arg4)
+	}
+}
+
+func (fake *Manager) AddFinderCallCount() int {
+	fake.addFinderMutex.RLock()
+	defer fake.addFinderMutex.RUnlock()
+	return len(fake.addFinderArgsForCall)
+}
+
+func (fake *Manager) AddFinderCalls(stub func(string, discover.Finder, time.Duration, time.Duration)) {
+	fake.addFinderMutex.Lock()
+	defer fake.addFinderMutex.Unlock()
+	fake.AddFinderStub = stub
+}
+
+func (fake *Manager) AddFinderArgsForCall(i int) (string, discover.Finder, time.Duration, time.Duration) {
+	fake.addFinderMutex.RLock()
+	defer fake.addFinderMutex.RUnlock()
+	argsForCall := fake.addFinderArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
 func (fake *Manager) Cache() map[protocol.DeviceID]discover.CacheEntry {
 	fake.cacheMutex.Lock()
 	ret, specificReturn := fake.cacheReturnsOnCall[len(fake.cacheArgsForCall)]
@@ -303,6 +352,38 @@ func (fake *Manager) LookupReturnsOnCall(i int, result1 []string, result2 error)
 	}{result1, result2}
 }
 
+func (fake *Manager) RemoveFinder(arg1 string) {
+	fake.removeFinderMutex.Lock()
+	fake.removeFinderArgsForCall = append(fake.removeFinderArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RemoveFinderStub
+	fake.recordInvocation("RemoveFinder", []interface{}{arg1})
+	fake.removeFinderMutex.Unlock()
+	if stub != nil {
+		fake.RemoveFinderStub(arg1)
+	}
+}
+
+func (fake *Manager) RemoveFinderCallCount() int {
+	fake.removeFinderMutex.RLock()
+	defer fake.removeFinderMutex.RUnlock()
+	return len(fake.removeFinderArgsForCall)
+}
+
+func (fake *Manager) RemoveFinderCalls(stub func(string)) {
+	fake.removeFinderMutex.Lock()
+	defer fake.removeFinderMutex.Unlock()
+	fake.RemoveFinderStub = stub
+}
+
+func (fake *Manager) RemoveFinderArgsForCall(i int) string {
+	fake.removeFinderMutex.RLock()
+	defer fake.removeFinderMutex.RUnlock()
+	argsForCall := fake.removeFinderArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *Manager) Serve(arg1 context.Context) error {
 	fake.serveMutex.Lock()
 	ret, specificReturn := fake.serveReturnsOnCall[len(fake.serveArgsForCall)]
@@ -57,6 +57,12 @@ const (
 	ListenAddressesChanged
 	LoginAttempt
 	Failure
+	ConflictCreated
+	ConnectionRejected
+	SecurityEvent
+	FolderAlarm
+	RemoteChangesPending
+	ConflictRemoved
 
 	AllEvents = (1 << iota) - 1
 )
@@ -134,6 +140,18 @@ func (t EventType) String() string {
 		return "FolderWatchStateChanged"
 	case Failure:
 		return "Failure"
+	case ConflictCreated:
+		return "ConflictCreated"
+	case ConnectionRejected:
+		return "ConnectionRejected"
+	case SecurityEvent:
+		return "SecurityEvent"
+	case FolderAlarm:
+		return "FolderAlarm"
+	case RemoteChangesPending:
+		return "RemoteChangesPending"
+	case ConflictRemoved:
+		return "ConflictRemoved"
 	default:
 		return "Unknown"
 	}
@@ -221,6 +239,18 @@ func UnmarshalEventType(s string) EventType {
 		return FolderWatchStateChanged
 	case "Failure":
 		return Failure
+	case "ConflictCreated":
+		return ConflictCreated
+	case "ConnectionRejected":
+		return ConnectionRejected
+	case "SecurityEvent":
+		return SecurityEvent
+	case "FolderAlarm":
+		return FolderAlarm
+	case "RemoteChangesPending":
+		return RemoteChangesPending
+	case "ConflictRemoved":
+		return ConflictRemoved
 	default:
 		return 0
 	}
@@ -0,0 +1,29 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package events
+
+// The kinds of SecurityEvent, given by the "kind" field of its data.
+const (
+	SecurityConnectionRejected = "connectionRejected"
+	SecurityLoginFailed        = "loginFailed"
+	SecurityAPIAuthFailed      = "apiAuthFailed"
+	SecurityPermissionChanged  = "permissionChanged"
+	SecurityReadOnlyViolation  = "readOnlyViolation"
+)
+
+// LogSecurity logs a SecurityEvent of the given kind. Empty values are left
+// out of the event data.
+func LogSecurity(l Logger, kind string, data map[string]string) {
+	ev := make(map[string]string, len(data)+1)
+	for k, v := range data {
+		if v != "" {
+			ev[k] = v
+		}
+	}
+	ev["kind"] = kind
+	l.Log(SecurityEvent, ev)
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"errors"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+var ErrAlternateStreamsNotSupported = errors.New("alternate data streams are not supported on this platform")
+
+// alternateStreamer is implemented by filesystems that can access the NTFS
+// alternate data streams of items, or that translate names on the way
+// there.
+type alternateStreamer interface {
+	getAlternateStreams(name string, filter XattrFilter) ([]protocol.AlternateStream, error)
+	setAlternateStreams(name string, streams []protocol.AlternateStream, filter XattrFilter) error
+}
+
+// GetAlternateStreams returns the alternate data streams of the named item
+// permitted by the filter and within its size limits, sorted by name. Items
+// on platforms or filesystems without alternate data streams have none.
+func GetAlternateStreams(fs Filesystem, name string, filter XattrFilter) ([]protocol.AlternateStream, error) {
+	as, ok := alternateStreamerOf(fs)
+	if !ok {
+		return nil, nil
+	}
+	streams, err := as.getAlternateStreams(name, filter)
+	if errors.Is(err, ErrAlternateStreamsNotSupported) {
+		return nil, nil
+	}
+	return streams, err
+}
+
+// SetAlternateStreams sets the alternate data streams of the named item,
+// removing those permitted by the filter that are not given.
+func SetAlternateStreams(fs Filesystem, name string, streams []protocol.AlternateStream, filter XattrFilter) error {
+	as, ok := alternateStreamerOf(fs)
+	if !ok {
+		return ErrAlternateStreamsNotSupported
+	}
+	return as.setAlternateStreams(name, streams, filter)
+}
+
+func alternateStreamerOf(fs Filesystem) (alternateStreamer, bool) {
+	for {
+		if as, ok := fs.(alternateStreamer); ok {
+			return as, true
+		}
+		wfs, ok := fs.(wrappingFilesystem)
+		if !ok {
+			return nil, false
+		}
+		if fs, ok = wfs.underlying(); !ok {
+			return nil, false
+		}
+	}
+}
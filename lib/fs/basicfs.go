@@ -44,14 +44,32 @@ func (*OptionJunctionsAsDirs) String() string {
 	return "junctionsAsDirs"
 }
 
+// OptionRescanAllOnOverflow makes the watcher have everything rescanned
+// when events were lost to an overflow, not just the recently active
+// directories, for when nothing else rescans the rest. It only affects
+// FilesystemTypeBasic.
+type OptionRescanAllOnOverflow struct{}
+
+func (*OptionRescanAllOnOverflow) apply(fs Filesystem) Filesystem {
+	if basic, ok := fs.(*BasicFilesystem); ok {
+		basic.rescanAllOnOverflow = true
+	}
+	return fs
+}
+
+func (*OptionRescanAllOnOverflow) String() string {
+	return "rescanAllOnOverflow"
+}
+
 // The BasicFilesystem implements all aspects by delegating to package os.
 // All paths are relative to the root and cannot (should not) escape the root directory.
 type BasicFilesystem struct {
-	root            string
-	junctionsAsDirs bool
-	options         []Option
-	userCache       *userCache
-	groupCache      *groupCache
+	root                string
+	junctionsAsDirs     bool
+	rescanAllOnOverflow bool
+	options             []Option
+	userCache           *userCache
+	groupCache          *groupCache
 }
 
 type (
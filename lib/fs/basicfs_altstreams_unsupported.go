@@ -0,0 +1,20 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !windows
+// +build !windows
+
+package fs
+
+import "github.com/syncthing/syncthing/lib/protocol"
+
+func (*BasicFilesystem) getAlternateStreams(string, XattrFilter) ([]protocol.AlternateStream, error) {
+	return nil, ErrAlternateStreamsNotSupported
+}
+
+func (*BasicFilesystem) setAlternateStreams(string, []protocol.AlternateStream, XattrFilter) error {
+	return ErrAlternateStreamsNotSupported
+}
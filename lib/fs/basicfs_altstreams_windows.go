@@ -0,0 +1,157 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build windows
+// +build windows
+
+package fs
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unsafe"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	procFindFirstStreamW = windows.NewLazySystemDLL("kernel32.dll").NewProc("FindFirstStreamW")
+	procFindNextStreamW  = windows.NewLazySystemDLL("kernel32.dll").NewProc("FindNextStreamW")
+)
+
+// win32FindStreamData is WIN32_FIND_STREAM_DATA.
+type win32FindStreamData struct {
+	StreamSize int64
+	StreamName [windows.MAX_PATH + 36]uint16
+}
+
+type alternateStreamInfo struct {
+	name string
+	size int64
+}
+
+func (f *BasicFilesystem) getAlternateStreams(name string, filter XattrFilter) ([]protocol.AlternateStream, error) {
+	path, err := f.rooted(name)
+	if err != nil {
+		return nil, fmt.Errorf("get alternate streams %s: %w", name, err)
+	}
+
+	infos, err := listAlternateStreams(path)
+	if err != nil {
+		return nil, fmt.Errorf("get alternate streams %s: %w", path, err)
+	}
+
+	res := make([]protocol.AlternateStream, 0, len(infos))
+	var totSize int64
+	for _, info := range infos {
+		if !filter.Permit(info.name) {
+			l.Debugf("get alternate streams %s: skipping stream %q denied by filter", path, info.name)
+			continue
+		}
+		size := int64(len(info.name)) + info.size
+		if max := filter.GetMaxSingleEntrySize(); max > 0 && size > int64(max) {
+			l.Debugf("get alternate streams %s: stream %q exceeds max size", path, info.name)
+			continue
+		}
+		if max := filter.GetMaxTotalSize(); max > 0 && totSize+size > int64(max) {
+			l.Debugf("get alternate streams %s: stream %q would cause max size to be exceeded", path, info.name)
+			continue
+		}
+		data, err := os.ReadFile(path + ":" + info.name)
+		if err != nil {
+			return nil, fmt.Errorf("get alternate streams %s: %w", path, err)
+		}
+		totSize += size
+		res = append(res, protocol.AlternateStream{
+			Name: info.name,
+			Data: data,
+		})
+	}
+	slices.SortFunc(res, func(a, b protocol.AlternateStream) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return res, nil
+}
+
+func (f *BasicFilesystem) setAlternateStreams(name string, streams []protocol.AlternateStream, filter XattrFilter) error {
+	// Streams that are not permitted, or too large to be sent, are left
+	// alone.
+	current, err := f.getAlternateStreams(name, filter)
+	if err != nil {
+		return fmt.Errorf("set alternate streams %s: %w", name, err)
+	}
+	path, err := f.rooted(name)
+	if err != nil {
+		return fmt.Errorf("set alternate streams %s: %w", name, err)
+	}
+
+	for _, cur := range current {
+		if !slices.ContainsFunc(streams, func(s protocol.AlternateStream) bool { return strings.EqualFold(s.Name, cur.Name) }) {
+			if err := os.Remove(path + ":" + cur.Name); err != nil {
+				return fmt.Errorf("set alternate streams %s: remove %q: %w", path, cur.Name, err)
+			}
+		}
+	}
+
+	for _, s := range streams {
+		if !filter.Permit(s.Name) || strings.ContainsAny(s.Name, `:\/`) {
+			continue
+		}
+		if idx := slices.IndexFunc(current, func(c protocol.AlternateStream) bool { return strings.EqualFold(c.Name, s.Name) }); idx >= 0 && bytes.Equal(current[idx].Data, s.Data) {
+			continue
+		}
+		if err := os.WriteFile(path+":"+s.Name, s.Data, 0o644); err != nil {
+			return fmt.Errorf("set alternate streams %s: write %q: %w", path, s.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// listAlternateStreams returns the names and sizes of the named data
+// streams of the item at path.
+func listAlternateStreams(path string) ([]alternateStreamInfo, error) {
+	pathp, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var data win32FindStreamData
+	h, _, err := procFindFirstStreamW.Call(uintptr(unsafe.Pointer(pathp)), 0, uintptr(unsafe.Pointer(&data)), 0)
+	if windows.Handle(h) == windows.InvalidHandle {
+		switch {
+		case errors.Is(err, windows.ERROR_HANDLE_EOF):
+			// No streams at all, as with directories.
+			return nil, nil
+		case errors.Is(err, windows.ERROR_INVALID_FUNCTION), errors.Is(err, windows.ERROR_NOT_SUPPORTED):
+			return nil, ErrAlternateStreamsNotSupported
+		}
+		return nil, fmt.Errorf("FindFirstStreamW: %w", err)
+	}
+	defer windows.FindClose(windows.Handle(h))
+
+	var infos []alternateStreamInfo
+	for {
+		// Streams are named ":name:$DATA", the unnamed stream with the
+		// contents of a file "::$DATA".
+		full := windows.UTF16ToString(data.StreamName[:])
+		if name, ok := strings.CutSuffix(strings.TrimPrefix(full, ":"), ":$DATA"); ok && name != "" {
+			infos = append(infos, alternateStreamInfo{name: name, size: data.StreamSize})
+		}
+		if r, _, err := procFindNextStreamW.Call(h, uintptr(unsafe.Pointer(&data))); r == 0 {
+			if errors.Is(err, windows.ERROR_HANDLE_EOF) {
+				return infos, nil
+			}
+			return nil, fmt.Errorf("FindNextStreamW: %w", err)
+		}
+	}
+}
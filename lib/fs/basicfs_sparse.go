@@ -0,0 +1,39 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"syscall"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Holes returns the holes of the first size bytes of the file, as reported
+// by the filesystem, in order. Files on filesystems or platforms without
+// support for finding holes have none. The file offset is reset to the
+// start of the file.
+func Holes(f File, size int64) ([]protocol.Hole, error) {
+	bf, ok := unwrap(f).(basicFile)
+	if !ok {
+		return nil, nil
+	}
+	return holes(bf, size)
+}
+
+// PunchHoles deallocates the given regions of the file, keeping its size,
+// so that they read as zeroes. Where that is unsupported it returns
+// syscall.ENOTSUP.
+func PunchHoles(f File, holes []protocol.Hole) error {
+	if len(holes) == 0 {
+		return nil
+	}
+	bf, ok := unwrap(f).(basicFile)
+	if !ok {
+		return syscall.ENOTSUP
+	}
+	return punchHoles(bf, holes)
+}
@@ -0,0 +1,15 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !linux && !darwin && !freebsd
+
+package fs
+
+import "github.com/syncthing/syncthing/lib/protocol"
+
+func holes(basicFile, int64) ([]protocol.Hole, error) {
+	return nil, nil
+}
@@ -0,0 +1,35 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build linux
+
+package fs
+
+import (
+	"golang.org/x/sys/unix"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func punchHoles(f basicFile, holes []protocol.Hole) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var punchErr error
+	err = conn.Control(func(fd uintptr) {
+		for _, h := range holes {
+			punchErr = unix.Fallocate(int(fd), unix.FALLOC_FL_PUNCH_HOLE|unix.FALLOC_FL_KEEP_SIZE, h.Offset, h.Size)
+			if punchErr != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return punchErr
+}
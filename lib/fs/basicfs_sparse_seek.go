@@ -0,0 +1,56 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build linux || darwin || freebsd
+
+package fs
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func holes(f basicFile, size int64) ([]protocol.Hole, error) {
+	holes, err := seekHoles(f, size)
+	if _, serr := f.Seek(0, io.SeekStart); serr != nil {
+		return nil, serr
+	}
+	return holes, err
+}
+
+func seekHoles(f basicFile, size int64) ([]protocol.Hole, error) {
+	var holes []protocol.Hole
+	for offset := int64(0); offset < size; {
+		start, err := f.Seek(offset, unix.SEEK_HOLE)
+		if errors.Is(err, unix.ENXIO) {
+			break
+		} else if errors.Is(err, unix.EINVAL) {
+			// The filesystem doesn't support finding holes.
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if start >= size {
+			// The implicit hole at the end of the file.
+			break
+		}
+		end, err := f.Seek(start, unix.SEEK_DATA)
+		if errors.Is(err, unix.ENXIO) {
+			// The file ends in a hole.
+			end = size
+		} else if err != nil {
+			return nil, err
+		}
+		end = min(end, size)
+		holes = append(holes, protocol.Hole{Offset: start, Size: end - start})
+		offset = end
+	}
+	return holes, nil
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestPunchAndFindHoles(t *testing.T) {
+	fs, _ := setup(t)
+
+	const size = 4 << 20
+	fd, err := fs.Create("sparse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = 1
+	}
+	if _, err := fd.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	punched := []protocol.Hole{{Offset: 1 << 20, Size: 1 << 20}, {Offset: 3 << 20, Size: 1 << 20}}
+	if err := PunchHoles(fd, punched); err != nil {
+		t.Skip("punching holes unsupported:", err)
+	}
+
+	holes, err := Holes(fd, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holes) == 0 {
+		t.Skip("finding holes unsupported")
+	}
+	if !slices.Equal(holes, punched) {
+		t.Errorf("expected holes %v, got %v", punched, holes)
+	}
+
+	// The offset is back at the start and the size is unchanged.
+	if _, err := io.ReadFull(fd, data); err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != 1 || data[1<<20] != 0 {
+		t.Error("unexpected file contents after finding holes")
+	}
+}
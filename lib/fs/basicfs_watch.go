@@ -14,7 +14,9 @@ package fs
 
 import (
 	"context"
-	"errors"
+	"path/filepath"
+	"slices"
+	"time"
 	"unicode/utf8"
 
 	"github.com/syncthing/notify"
@@ -25,6 +27,78 @@ import (
 // Not meant to be changed, but must be changeable for tests
 var backendBuffer = 500
 
+// When events were lost to an overflow, the directories with events in the
+// last one to two overflowWindows are rescanned, as that's where the lost
+// events most likely were. If there were none or too many of them, or with
+// OptionRescanAllOnOverflow, the whole watched path is rescanned instead.
+const (
+	overflowWindow  = 10 * time.Second
+	maxOverflowDirs = 64
+)
+
+// activeDirs keeps the directories events were seen in, in two generations
+// of overflowWindow each, so that older activity is forgotten.
+type activeDirs struct {
+	cur, prev map[string]struct{} // nil when there were too many
+	rotated   time.Time
+}
+
+func newActiveDirs(now time.Time) *activeDirs {
+	return &activeDirs{
+		cur:     make(map[string]struct{}),
+		prev:    make(map[string]struct{}),
+		rotated: now,
+	}
+}
+
+// add records activity in the directory of the named file.
+func (a *activeDirs) add(name string, now time.Time) {
+	a.rotate(now)
+	if a.cur == nil {
+		return
+	}
+	a.cur[filepath.Dir(name)] = struct{}{}
+	if len(a.cur) > maxOverflowDirs {
+		a.cur = nil
+	}
+}
+
+func (a *activeDirs) rotate(now time.Time) {
+	switch since := now.Sub(a.rotated); {
+	case since >= 2*overflowWindow:
+		a.prev = make(map[string]struct{})
+		a.cur = make(map[string]struct{})
+		a.rotated = now
+	case since >= overflowWindow:
+		a.prev = a.cur
+		a.cur = make(map[string]struct{})
+		a.rotated = now
+	}
+}
+
+// list returns the directories active in the current and the previous
+// generation, or false if that's not a useful subset of everything.
+func (a *activeDirs) list(now time.Time) ([]string, bool) {
+	a.rotate(now)
+	if a.cur == nil || a.prev == nil {
+		return nil, false
+	}
+	dirs := make([]string, 0, len(a.cur)+len(a.prev))
+	for dir := range a.cur {
+		dirs = append(dirs, dir)
+	}
+	for dir := range a.prev {
+		if _, ok := a.cur[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 || len(dirs) > maxOverflowDirs || slices.Contains(dirs, ".") {
+		return nil, false
+	}
+	slices.Sort(dirs)
+	return dirs, true
+}
+
 func (f *BasicFilesystem) Watch(name string, ignore Matcher, ctx context.Context, ignorePerms bool) (<-chan Event, <-chan error, error) {
 	watchPath, roots, err := f.watchPaths(name)
 	if err != nil {
@@ -54,7 +128,7 @@ func (f *BasicFilesystem) Watch(name string, ignore Matcher, ctx context.Context
 	if err != nil {
 		notify.Stop(backendChan)
 		if reachedMaxUserWatches(err) {
-			err = errors.New("failed to set up inotify handler. Please increase inotify limits, see https://docs.syncthing.net/users/faq.html#inotify-limits")
+			err = ErrWatchLimit
 		}
 		return nil, nil, err
 	}
@@ -66,20 +140,32 @@ func (f *BasicFilesystem) Watch(name string, ignore Matcher, ctx context.Context
 }
 
 func (f *BasicFilesystem) watchLoop(ctx context.Context, name string, roots []string, backendChan chan notify.EventInfo, outChan chan<- Event, errChan chan<- error, ignore Matcher) {
+	active := newActiveDirs(time.Now())
 	for {
 		// Detect channel overflow
 		if len(backendChan) == backendBuffer {
 		outer:
 			for {
 				select {
-				case <-backendChan:
+				case ev := <-backendChan:
+					if relPath, err := f.unrootedChecked(ev.Path(), roots); err == nil && utf8.ValidString(relPath) && !ignore.Match(relPath).IsIgnored() {
+						active.add(relPath, time.Now())
+					}
 				default:
 					break outer
 				}
 			}
-			// When next scheduling a scan, do it on the entire folder as events have been lost.
-			outChan <- Event{Name: name, Type: NonRemove}
-			l.Debugln(f.Type(), f.URI(), "Watch: Event overflow, send \".\"")
+			// When next scheduling a scan, do it on the directories that
+			// were active, or else the entire folder, as events have been lost.
+			if dirs, ok := active.list(time.Now()); ok && !f.rescanAllOnOverflow {
+				for _, dir := range dirs {
+					outChan <- Event{Name: dir, Type: NonRemove}
+				}
+				l.Debugln(f.Type(), f.URI(), "Watch: Event overflow, send active directories", dirs)
+			} else {
+				outChan <- Event{Name: name, Type: NonRemove}
+				l.Debugln(f.Type(), f.URI(), "Watch: Event overflow, send \".\"")
+			}
 		}
 
 		select {
@@ -107,6 +193,7 @@ func (f *BasicFilesystem) watchLoop(ctx context.Context, name string, roots []st
 				l.Debugln(f.Type(), f.URI(), "Watch: Ignoring", relPath)
 				continue
 			}
+			active.add(relPath, time.Now())
 			evType := f.eventType(ev.Event())
 			select {
 			case outChan <- Event{Name: relPath, Type: evType}:
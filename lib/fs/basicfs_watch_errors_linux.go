@@ -11,6 +11,8 @@ package fs
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"syscall"
 )
 
@@ -22,3 +24,14 @@ func reachedMaxUserWatches(err error) bool {
 	}
 	return false
 }
+
+// MaxWatches returns the system wide limit on the number of watched
+// directories per user, if there is one.
+func MaxWatches() (int, bool) {
+	bs, err := os.ReadFile("/proc/sys/fs/inotify/max_user_watches")
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(bs)))
+	return n, err == nil && n > 0
+}
@@ -12,3 +12,9 @@ package fs
 func reachedMaxUserWatches(_ error) bool {
 	return false
 }
+
+// MaxWatches returns the system wide limit on the number of watched
+// directories per user, if there is one.
+func MaxWatches() (int, bool) {
+	return 0, false
+}
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -313,6 +314,70 @@ func TestWatchOverflow(t *testing.T) {
 	testScenario(t, name, testCase, expectedEvents, allowedEvents, fakeMatcher{}, false)
 }
 
+func TestWatchOverflowRescanAll(t *testing.T) {
+	for _, rescanAll := range []bool{false, true} {
+		fs := newBasicFilesystem(testDirAbs)
+		fs.rescanAllOnOverflow = rescanAll
+
+		// A full channel means events were lost.
+		backendChan := make(chan notify.EventInfo, backendBuffer)
+		for range backendBuffer {
+			backendChan <- fakeEventInfo(filepath.Join(testDirAbs, "sub", "file"))
+		}
+		outChan := make(chan Event)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			fs.watchLoop(ctx, ".", []string{testDirAbs}, backendChan, outChan, make(chan error), fakeMatcher{})
+			close(done)
+		}()
+
+		expected := "sub"
+		if rescanAll {
+			expected = "."
+		}
+		select {
+		case ev := <-outChan:
+			if ev.Name != expected {
+				t.Errorf("rescanAllOnOverflow=%v: expected overflow event for %q, got %q", rescanAll, expected, ev.Name)
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("rescanAllOnOverflow=%v: timed out before receiving an event", rescanAll)
+		}
+		cancel()
+		<-done
+	}
+}
+
+func TestActiveDirs(t *testing.T) {
+	now := time.Now()
+	a := newActiveDirs(now)
+	if _, ok := a.list(now); ok {
+		t.Error("no active directories should mean everything")
+	}
+
+	a.add(filepath.Join("a", "file"), now)
+	now = now.Add(overflowWindow)
+	a.add(filepath.Join("b", "c", "file"), now)
+	if dirs, ok := a.list(now); !ok || !slices.Equal(dirs, []string{"a", filepath.Join("b", "c")}) {
+		t.Error("unexpected active directories", dirs, ok)
+	}
+
+	// Activity older than two generations is forgotten.
+	now = now.Add(overflowWindow)
+	if dirs, ok := a.list(now); !ok || !slices.Equal(dirs, []string{filepath.Join("b", "c")}) {
+		t.Error("unexpected active directories", dirs, ok)
+	}
+
+	// Too many directories mean everything.
+	for i := 0; i <= maxOverflowDirs; i++ {
+		a.add(filepath.Join(strconv.Itoa(i), "file"), now)
+	}
+	if _, ok := a.list(now); ok {
+		t.Error("too many active directories should mean everything")
+	}
+}
+
 func TestWatchErrorLinuxInterpretation(t *testing.T) {
 	if !build.IsLinux {
 		t.Skip("testing of linux specific error codes")
@@ -0,0 +1,46 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"errors"
+	"slices"
+	"syscall"
+)
+
+// IsLocked returns true if the error is due to the file being in use or
+// locked by another process.
+func IsLocked(err error) bool {
+	return isErrno(err, lockedErrnos)
+}
+
+// IsNameTooLong returns true if the error is due to a file name or path
+// exceeding what the filesystem supports.
+func IsNameTooLong(err error) bool {
+	return isErrno(err, nameTooLongErrnos)
+}
+
+// IsNoSpace returns true if the error is due to the filesystem being full,
+// or a quota being exceeded.
+func IsNoSpace(err error) bool {
+	return isErrno(err, noSpaceErrnos)
+}
+
+// IsInvalidFilename returns true if the error is due to a file name not
+// being valid, either by our own rules or those of the filesystem.
+func IsInvalidFilename(err error) bool {
+	return errors.Is(err, errInvalidFilenameEmpty) ||
+		errors.Is(err, errInvalidFilenameWindowsSpacePeriod) ||
+		errors.Is(err, errInvalidFilenameWindowsReservedName) ||
+		errors.Is(err, errInvalidFilenameWindowsReservedChar) ||
+		isErrno(err, invalidFilenameErrnos)
+}
+
+func isErrno(err error, errnos []syscall.Errno) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && slices.Contains(errnos, errno)
+}
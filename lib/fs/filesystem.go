@@ -154,6 +154,7 @@ func (evType EventType) String() string {
 
 var (
 	ErrWatchNotSupported  = errors.New("watching is not supported")
+	ErrWatchLimit         = errors.New("failed to set up inotify handler. Please increase inotify limits, see https://docs.syncthing.net/users/faq.html#inotify-limits")
 	ErrXattrsNotSupported = errors.New("extended attributes are not supported on this platform")
 )
 
@@ -208,16 +209,19 @@ var IsPathSeparator = os.IsPathSeparator
 func NewFilesystem(fsType FilesystemType, uri string, opts ...Option) Filesystem {
 	var caseOpt Option
 	var mtimeOpt Option
+	var sanitizeOpt Option
+	var interceptOpts []Option
 	i := 0
 	for _, opt := range opts {
-		if caseOpt != nil && mtimeOpt != nil {
-			break
-		}
 		switch opt.(type) {
 		case *OptionDetectCaseConflicts:
 			caseOpt = opt
 		case *optionMtime:
 			mtimeOpt = opt
+		case *OptionSanitizeNames:
+			sanitizeOpt = opt
+		case *optionIntercept:
+			interceptOpts = append(interceptOpts, opt)
 		default:
 			opts[i] = opt
 			i++
@@ -245,6 +249,17 @@ func NewFilesystem(fsType FilesystemType, uri string, opts ...Option) Filesystem
 		}
 	}
 
+	// Interceptors see the operations as they are made on the underlying
+	// filesystem.
+	for _, opt := range interceptOpts {
+		fs = opt.apply(fs)
+	}
+
+	// Everything but interceptors deals in original names.
+	if sanitizeOpt != nil {
+		fs = sanitizeOpt.apply(fs)
+	}
+
 	// mtime handling should happen inside walking, as filesystem calls while
 	// walking should be mtime-resolved too
 	if mtimeOpt != nil {
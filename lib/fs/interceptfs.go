@@ -0,0 +1,192 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// An Operation is a modifying call on a filesystem, as seen by an
+// Interceptor.
+type Operation struct {
+	Op      string // "chmod", "create", "remove", "rename", ...
+	Root    string // the URI of the filesystem
+	Name    string
+	NewName string // the rename destination or the symlink target
+}
+
+// An Interceptor is called before each modifying operation on a filesystem
+// created with NewInterceptOption, and rejects the operation by returning
+// an error.
+type Interceptor func(Operation) error
+
+var (
+	interceptors    = make(map[string]Interceptor)
+	interceptorsMut sync.RWMutex
+)
+
+// RegisterInterceptor makes the interceptor available under the given
+// name, replacing any previous one.
+func RegisterInterceptor(name string, fn Interceptor) {
+	interceptorsMut.Lock()
+	defer interceptorsMut.Unlock()
+	interceptors[name] = fn
+}
+
+func UnregisterInterceptor(name string) {
+	interceptorsMut.Lock()
+	defer interceptorsMut.Unlock()
+	delete(interceptors, name)
+}
+
+type optionIntercept struct {
+	name string
+}
+
+// NewInterceptOption wraps the filesystem to pass modifying operations by
+// the interceptor registered under the given name. The interceptor is
+// looked up for every operation; while none is registered, modifying
+// operations fail.
+func NewInterceptOption(name string) Option {
+	return &optionIntercept{name: name}
+}
+
+func (o *optionIntercept) apply(fs Filesystem) Filesystem {
+	return &interceptFS{Filesystem: fs, opt: o}
+}
+
+func (o *optionIntercept) String() string {
+	return "intercept:" + o.name
+}
+
+type interceptFS struct {
+	Filesystem
+	opt *optionIntercept
+}
+
+func (fs *interceptFS) intercept(op, name, newName string) error {
+	interceptorsMut.RLock()
+	fn, ok := interceptors[fs.opt.name]
+	interceptorsMut.RUnlock()
+	if !ok {
+		return fmt.Errorf("%s %s: filesystem wrapper %q is not available", op, name, fs.opt.name)
+	}
+	return fn(Operation{Op: op, Root: fs.URI(), Name: name, NewName: newName})
+}
+
+func (fs *interceptFS) Chmod(name string, mode FileMode) error {
+	if err := fs.intercept("chmod", name, ""); err != nil {
+		return err
+	}
+	return fs.Filesystem.Chmod(name, mode)
+}
+
+func (fs *interceptFS) Lchown(name, uid, gid string) error {
+	if err := fs.intercept("lchown", name, ""); err != nil {
+		return err
+	}
+	return fs.Filesystem.Lchown(name, uid, gid)
+}
+
+func (fs *interceptFS) Chtimes(name string, atime, mtime time.Time) error {
+	if err := fs.intercept("chtimes", name, ""); err != nil {
+		return err
+	}
+	return fs.Filesystem.Chtimes(name, atime, mtime)
+}
+
+func (fs *interceptFS) Create(name string) (File, error) {
+	if err := fs.intercept("create", name, ""); err != nil {
+		return nil, err
+	}
+	return fs.Filesystem.Create(name)
+}
+
+func (fs *interceptFS) CreateSymlink(target, name string) error {
+	if err := fs.intercept("symlink", name, target); err != nil {
+		return err
+	}
+	return fs.Filesystem.CreateSymlink(target, name)
+}
+
+func (fs *interceptFS) Mkdir(name string, perm FileMode) error {
+	if err := fs.intercept("mkdir", name, ""); err != nil {
+		return err
+	}
+	return fs.Filesystem.Mkdir(name, perm)
+}
+
+func (fs *interceptFS) MkdirAll(name string, perm FileMode) error {
+	if err := fs.intercept("mkdir", name, ""); err != nil {
+		return err
+	}
+	return fs.Filesystem.MkdirAll(name, perm)
+}
+
+func (fs *interceptFS) OpenFile(name string, flags int, mode FileMode) (File, error) {
+	if flags&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		if err := fs.intercept("write", name, ""); err != nil {
+			return nil, err
+		}
+	}
+	return fs.Filesystem.OpenFile(name, flags, mode)
+}
+
+func (fs *interceptFS) Remove(name string) error {
+	if err := fs.intercept("remove", name, ""); err != nil {
+		return err
+	}
+	return fs.Filesystem.Remove(name)
+}
+
+func (fs *interceptFS) RemoveAll(name string) error {
+	if err := fs.intercept("remove", name, ""); err != nil {
+		return err
+	}
+	return fs.Filesystem.RemoveAll(name)
+}
+
+func (fs *interceptFS) Rename(oldname, newname string) error {
+	if err := fs.intercept("rename", oldname, newname); err != nil {
+		return err
+	}
+	return fs.Filesystem.Rename(oldname, newname)
+}
+
+func (fs *interceptFS) Hide(name string) error {
+	if err := fs.intercept("hide", name, ""); err != nil {
+		return err
+	}
+	return fs.Filesystem.Hide(name)
+}
+
+func (fs *interceptFS) Unhide(name string) error {
+	if err := fs.intercept("unhide", name, ""); err != nil {
+		return err
+	}
+	return fs.Filesystem.Unhide(name)
+}
+
+func (fs *interceptFS) SetXattr(path string, xattrs []protocol.Xattr, xattrFilter XattrFilter) error {
+	if err := fs.intercept("setxattr", path, ""); err != nil {
+		return err
+	}
+	return fs.Filesystem.SetXattr(path, xattrs, xattrFilter)
+}
+
+func (fs *interceptFS) Options() []Option {
+	return append(fs.Filesystem.Options(), fs.opt)
+}
+
+func (fs *interceptFS) underlying() (Filesystem, bool) {
+	return fs.Filesystem, true
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestInterceptFS(t *testing.T) {
+	var ops []Operation
+	RegisterInterceptor("test", func(op Operation) error {
+		ops = append(ops, op)
+		if op.Name == "denied" {
+			return errors.New("denied")
+		}
+		return nil
+	})
+	defer UnregisterInterceptor("test")
+
+	fs := NewFilesystem(FilesystemTypeFake, "/intercept", NewInterceptOption("test"))
+	if err := fs.Mkdir("dir", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fd, err := fs.Create("dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd.Close()
+	if err := fs.Rename("dir/file", "dir/other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat("dir/other"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mkdir("denied", 0o755); err == nil {
+		t.Error("expected the interceptor to deny the operation")
+	}
+	if _, err := fs.Stat("denied"); !IsNotExist(err) {
+		t.Error("denied operation should not have been made")
+	}
+
+	root := fs.URI()
+	exp := []Operation{
+		{Op: "mkdir", Root: root, Name: "dir"},
+		{Op: "create", Root: root, Name: "dir/file"},
+		{Op: "rename", Root: root, Name: "dir/file", NewName: "dir/other"},
+		{Op: "mkdir", Root: root, Name: "denied"},
+	}
+	if !slices.Equal(ops, exp) {
+		t.Errorf("expected operations %v, got %v", exp, ops)
+	}
+
+	UnregisterInterceptor("test")
+	if err := fs.Remove("dir/other"); err == nil {
+		t.Error("expected modifications to fail without the interceptor")
+	}
+}
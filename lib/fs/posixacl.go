@@ -0,0 +1,162 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os/user"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// POSIX ACLs are stored by Linux in extended attributes, in a binary
+// format of a version header followed by entries of a tag, permissions and
+// an ID each.
+const (
+	posixACLAccessXattr  = "system.posix_acl_access"
+	posixACLDefaultXattr = "system.posix_acl_default"
+	posixACLVersion      = 2
+	posixACLEntrySize    = 8
+	posixACLUndefinedID  = 1<<32 - 1
+)
+
+var (
+	posixACLTags = map[uint16]protocol.PosixACLTag{
+		0x01: protocol.PosixACLTagUserObj,
+		0x02: protocol.PosixACLTagUser,
+		0x04: protocol.PosixACLTagGroupObj,
+		0x08: protocol.PosixACLTagGroup,
+		0x10: protocol.PosixACLTagMask,
+		0x20: protocol.PosixACLTagOther,
+	}
+	posixACLRawTags = map[protocol.PosixACLTag]uint16{
+		protocol.PosixACLTagUserObj:  0x01,
+		protocol.PosixACLTagUser:     0x02,
+		protocol.PosixACLTagGroupObj: 0x04,
+		protocol.PosixACLTagGroup:    0x08,
+		protocol.PosixACLTagMask:     0x10,
+		protocol.PosixACLTagOther:    0x20,
+	}
+)
+
+var (
+	posixACLUserCache  = newValueCache(time.Hour, user.LookupId)
+	posixACLGroupCache = newValueCache(time.Hour, user.LookupGroupId)
+)
+
+// posixACLXattrFilter permits the extended attributes holding ACLs, and
+// only those.
+type posixACLXattrFilter struct{}
+
+func (posixACLXattrFilter) Permit(name string) bool {
+	return name == posixACLAccessXattr || name == posixACLDefaultXattr
+}
+
+func (posixACLXattrFilter) GetMaxSingleEntrySize() int { return 0 }
+
+func (posixACLXattrFilter) GetMaxTotalSize() int { return 0 }
+
+// GetPosixACL returns the POSIX ACLs of the named item, with the names of
+// the users and groups of named entries where they can be looked up. Items
+// without ACLs beyond their permission bits, and those on platforms or
+// filesystems without POSIX ACLs, have an empty ACL.
+func GetPosixACL(fs Filesystem, name string) (*protocol.PosixACL, error) {
+	xattrs, err := fs.GetXattr(name, posixACLXattrFilter{})
+	if errors.Is(err, ErrXattrsNotSupported) || errors.Is(err, syscall.ENOTSUP) {
+		return &protocol.PosixACL{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	acl := &protocol.PosixACL{}
+	for _, xa := range xattrs {
+		entries, err := decodePosixACL(xa.Value)
+		if err != nil {
+			return nil, fmt.Errorf("get POSIX ACL %s: %w", name, err)
+		}
+		if xa.Name == posixACLAccessXattr {
+			acl.Access = entries
+		} else {
+			acl.Default = entries
+		}
+	}
+	return acl, nil
+}
+
+// SetPosixACL sets the POSIX ACLs of the named item, removing those not
+// given. The IDs of named entries are used as they are, their names are
+// for the caller to translate.
+func SetPosixACL(fs Filesystem, name string, acl *protocol.PosixACL) error {
+	var xattrs []protocol.Xattr
+	if len(acl.Access) > 0 {
+		xattrs = append(xattrs, protocol.Xattr{Name: posixACLAccessXattr, Value: encodePosixACL(acl.Access)})
+	}
+	if len(acl.Default) > 0 {
+		xattrs = append(xattrs, protocol.Xattr{Name: posixACLDefaultXattr, Value: encodePosixACL(acl.Default)})
+	}
+	return fs.SetXattr(name, xattrs, posixACLXattrFilter{})
+}
+
+func decodePosixACL(bs []byte) ([]protocol.PosixACLEntry, error) {
+	if len(bs) < 4 || binary.LittleEndian.Uint32(bs) != posixACLVersion || (len(bs)-4)%posixACLEntrySize != 0 {
+		return nil, errors.New("unsupported ACL format")
+	}
+	entries := make([]protocol.PosixACLEntry, 0, (len(bs)-4)/posixACLEntrySize)
+	for bs = bs[4:]; len(bs) > 0; bs = bs[posixACLEntrySize:] {
+		tag, ok := posixACLTags[binary.LittleEndian.Uint16(bs)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported ACL tag %#x", binary.LittleEndian.Uint16(bs))
+		}
+		e := protocol.PosixACLEntry{
+			Tag:   tag,
+			Perms: uint32(binary.LittleEndian.Uint16(bs[2:])),
+		}
+		if e.IsNamed() {
+			e.ID = int(binary.LittleEndian.Uint32(bs[4:]))
+			if tag == protocol.PosixACLTagUser {
+				if us := posixACLUserCache.lookup(strconv.Itoa(e.ID)); us != nil {
+					e.Name = us.Username
+				}
+			} else if gr := posixACLGroupCache.lookup(strconv.Itoa(e.ID)); gr != nil {
+				e.Name = gr.Name
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func encodePosixACL(entries []protocol.PosixACLEntry) []byte {
+	// The kernel wants the entries ordered by tag, then ID.
+	entries = slices.Clone(entries)
+	slices.SortFunc(entries, func(a, b protocol.PosixACLEntry) int {
+		if c := cmp.Compare(posixACLRawTags[a.Tag], posixACLRawTags[b.Tag]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	bs := make([]byte, 4, 4+len(entries)*posixACLEntrySize)
+	binary.LittleEndian.PutUint32(bs, posixACLVersion)
+	for _, e := range entries {
+		id := uint32(posixACLUndefinedID)
+		if e.IsNamed() {
+			id = uint32(e.ID) //nolint:gosec
+		}
+		bs = binary.LittleEndian.AppendUint16(bs, posixACLRawTags[e.Tag])
+		bs = binary.LittleEndian.AppendUint16(bs, uint16(e.Perms)) //nolint:gosec
+		bs = binary.LittleEndian.AppendUint32(bs, id)
+	}
+	return bs
+}
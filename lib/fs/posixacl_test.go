@@ -0,0 +1,59 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestPosixACLEncoding(t *testing.T) {
+	// user::rwx, user:4242:r-x, group::r--, group:4343:rw-, mask::rwx,
+	// other::--- as stored by Linux.
+	raw := []byte{
+		0x02, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x07, 0x00, 0xff, 0xff, 0xff, 0xff,
+		0x02, 0x00, 0x05, 0x00, 0x92, 0x10, 0x00, 0x00,
+		0x04, 0x00, 0x04, 0x00, 0xff, 0xff, 0xff, 0xff,
+		0x08, 0x00, 0x06, 0x00, 0xf7, 0x10, 0x00, 0x00,
+		0x10, 0x00, 0x07, 0x00, 0xff, 0xff, 0xff, 0xff,
+		0x20, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff,
+	}
+
+	entries, err := decodePosixACL(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range entries {
+		// Names depend on the system.
+		entries[i].Name = ""
+	}
+	expected := []protocol.PosixACLEntry{
+		{Tag: protocol.PosixACLTagUserObj, Perms: 7},
+		{Tag: protocol.PosixACLTagUser, ID: 4242, Perms: 5},
+		{Tag: protocol.PosixACLTagGroupObj, Perms: 4},
+		{Tag: protocol.PosixACLTagGroup, ID: 4343, Perms: 6},
+		{Tag: protocol.PosixACLTagMask, Perms: 7},
+		{Tag: protocol.PosixACLTagOther, Perms: 0},
+	}
+	if !slices.Equal(entries, expected) {
+		t.Fatalf("decoded %v, expected %v", entries, expected)
+	}
+
+	// Encoding sorts the entries the way the kernel wants them.
+	slices.Reverse(entries)
+	if enc := encodePosixACL(entries); !bytes.Equal(enc, raw) {
+		t.Errorf("encoded %x, expected %x", enc, raw)
+	}
+
+	if _, err := decodePosixACL(raw[:10]); err == nil {
+		t.Error("expected an error for a truncated ACL")
+	}
+}
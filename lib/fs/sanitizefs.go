@@ -0,0 +1,299 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/ignore/ignoreresult"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Names that aren't valid on Windows are stored with the offending
+// characters mapped into the private use area, at sanitizeOffset plus the
+// ASCII value, as Cygwin and others do. Names are restored when read back,
+// so everything above the filesystem sees the original names. Characters
+// in the mapped range are taken to be encoded whatever their origin.
+const sanitizeOffset = 0xf000
+
+// OptionSanitizeNames makes the filesystem store names that are invalid on
+// Windows, due to reserved characters, trailing spaces or periods, or
+// reserved names, as valid names that map back to the original.
+type OptionSanitizeNames struct{}
+
+func (*OptionSanitizeNames) apply(fs Filesystem) Filesystem {
+	return &sanitizeFS{Filesystem: fs}
+}
+
+func (*OptionSanitizeNames) String() string {
+	return "sanitizeNames"
+}
+
+// sanitizeName returns the name as stored on disk.
+func sanitizeName(name string) string {
+	if name == "" {
+		return name
+	}
+	parts := strings.Split(name, string(PathSeparator))
+	for i, part := range parts {
+		parts[i] = sanitizeNamePart(part)
+	}
+	return strings.Join(parts, string(PathSeparator))
+}
+
+func sanitizeNamePart(part string) string {
+	if part == "" || part == "." || part == ".." {
+		return part
+	}
+	if !strings.ContainsAny(part, windowsDisallowedCharacters) && !strings.HasSuffix(part, " ") &&
+		!strings.HasSuffix(part, ".") && windowsReservedNamePart(part) == "" {
+		return part
+	}
+
+	runes := []rune(part)
+	for i, r := range runes {
+		if r < 0x80 && strings.ContainsRune(windowsDisallowedCharacters, r) {
+			runes[i] = sanitizeOffset + r
+		}
+	}
+	if last := runes[len(runes)-1]; last == ' ' || last == '.' {
+		runes[len(runes)-1] = sanitizeOffset + last
+	}
+	if reserved := windowsReservedNamePart(part); reserved != "" {
+		// "nul.txt" becomes "nu\uf06c.txt".
+		i := len(reserved) - 1
+		runes[i] = sanitizeOffset + runes[i]
+	}
+	return string(runes)
+}
+
+// restoreName returns the name as stored on disk to the original.
+func restoreName(name string) string {
+	if !strings.ContainsFunc(name, isSanitized) {
+		return name
+	}
+	return strings.Map(func(r rune) rune {
+		if isSanitized(r) {
+			return r - sanitizeOffset
+		}
+		return r
+	}, name)
+}
+
+func isSanitized(r rune) bool {
+	return r >= sanitizeOffset && r < sanitizeOffset+0x80
+}
+
+type sanitizeFS struct {
+	Filesystem
+}
+
+func (fs *sanitizeFS) Chmod(name string, mode FileMode) error {
+	return fs.Filesystem.Chmod(sanitizeName(name), mode)
+}
+
+func (fs *sanitizeFS) Lchown(name, uid, gid string) error {
+	return fs.Filesystem.Lchown(sanitizeName(name), uid, gid)
+}
+
+func (fs *sanitizeFS) Chtimes(name string, atime, mtime time.Time) error {
+	return fs.Filesystem.Chtimes(sanitizeName(name), atime, mtime)
+}
+
+func (fs *sanitizeFS) Create(name string) (File, error) {
+	return sanitizeFile(fs.Filesystem.Create(sanitizeName(name)))
+}
+
+func (fs *sanitizeFS) CreateSymlink(target, name string) error {
+	return fs.Filesystem.CreateSymlink(target, sanitizeName(name))
+}
+
+func (fs *sanitizeFS) DirNames(name string) ([]string, error) {
+	names, err := fs.Filesystem.DirNames(sanitizeName(name))
+	for i := range names {
+		names[i] = restoreName(names[i])
+	}
+	return names, err
+}
+
+func (fs *sanitizeFS) Lstat(name string) (FileInfo, error) {
+	return sanitizeFileInfo(fs.Filesystem.Lstat(sanitizeName(name)))
+}
+
+func (fs *sanitizeFS) Mkdir(name string, perm FileMode) error {
+	return fs.Filesystem.Mkdir(sanitizeName(name), perm)
+}
+
+func (fs *sanitizeFS) MkdirAll(name string, perm FileMode) error {
+	return fs.Filesystem.MkdirAll(sanitizeName(name), perm)
+}
+
+func (fs *sanitizeFS) Open(name string) (File, error) {
+	return sanitizeFile(fs.Filesystem.Open(sanitizeName(name)))
+}
+
+func (fs *sanitizeFS) OpenFile(name string, flags int, mode FileMode) (File, error) {
+	return sanitizeFile(fs.Filesystem.OpenFile(sanitizeName(name), flags, mode))
+}
+
+func (fs *sanitizeFS) ReadSymlink(name string) (string, error) {
+	return fs.Filesystem.ReadSymlink(sanitizeName(name))
+}
+
+func (fs *sanitizeFS) Remove(name string) error {
+	return fs.Filesystem.Remove(sanitizeName(name))
+}
+
+func (fs *sanitizeFS) RemoveAll(name string) error {
+	return fs.Filesystem.RemoveAll(sanitizeName(name))
+}
+
+func (fs *sanitizeFS) Rename(oldname, newname string) error {
+	return fs.Filesystem.Rename(sanitizeName(oldname), sanitizeName(newname))
+}
+
+func (fs *sanitizeFS) Stat(name string) (FileInfo, error) {
+	return sanitizeFileInfo(fs.Filesystem.Stat(sanitizeName(name)))
+}
+
+func (fs *sanitizeFS) Walk(name string, walkFn WalkFunc) error {
+	return fs.Filesystem.Walk(sanitizeName(name), func(path string, info FileInfo, err error) error {
+		if info != nil {
+			info = sanitizedFileInfo{info}
+		}
+		return walkFn(restoreName(path), info, err)
+	})
+}
+
+func (fs *sanitizeFS) Watch(path string, ignore Matcher, ctx context.Context, ignorePerms bool) (<-chan Event, <-chan error, error) {
+	if ignore != nil {
+		ignore = restoringMatcher{ignore}
+	}
+	events, errs, err := fs.Filesystem.Watch(sanitizeName(path), ignore, ctx, ignorePerms)
+	if err != nil {
+		return nil, nil, err
+	}
+	restored := make(chan Event)
+	go func() {
+		defer close(restored)
+		for ev := range events {
+			ev.Name = restoreName(ev.Name)
+			select {
+			case restored <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return restored, errs, nil
+}
+
+func (fs *sanitizeFS) Hide(name string) error {
+	return fs.Filesystem.Hide(sanitizeName(name))
+}
+
+func (fs *sanitizeFS) Unhide(name string) error {
+	return fs.Filesystem.Unhide(sanitizeName(name))
+}
+
+func (fs *sanitizeFS) Glob(pattern string) ([]string, error) {
+	names, err := fs.Filesystem.Glob(pattern)
+	for i := range names {
+		names[i] = restoreName(names[i])
+	}
+	return names, err
+}
+
+func (fs *sanitizeFS) Usage(name string) (Usage, error) {
+	return fs.Filesystem.Usage(sanitizeName(name))
+}
+
+func (fs *sanitizeFS) PlatformData(name string, withOwnership, withXattrs bool, xattrFilter XattrFilter) (protocol.PlatformData, error) {
+	return fs.Filesystem.PlatformData(sanitizeName(name), withOwnership, withXattrs, xattrFilter)
+}
+
+func (fs *sanitizeFS) GetXattr(name string, xattrFilter XattrFilter) ([]protocol.Xattr, error) {
+	return fs.Filesystem.GetXattr(sanitizeName(name), xattrFilter)
+}
+
+func (fs *sanitizeFS) SetXattr(path string, xattrs []protocol.Xattr, xattrFilter XattrFilter) error {
+	return fs.Filesystem.SetXattr(sanitizeName(path), xattrs, xattrFilter)
+}
+
+func (fs *sanitizeFS) getAlternateStreams(name string, filter XattrFilter) ([]protocol.AlternateStream, error) {
+	return GetAlternateStreams(fs.Filesystem, sanitizeName(name), filter)
+}
+
+func (fs *sanitizeFS) setAlternateStreams(name string, streams []protocol.AlternateStream, filter XattrFilter) error {
+	return SetAlternateStreams(fs.Filesystem, sanitizeName(name), streams, filter)
+}
+
+func (fs *sanitizeFS) SameFile(fi1, fi2 FileInfo) bool {
+	if s, ok := fi1.(sanitizedFileInfo); ok {
+		fi1 = s.FileInfo
+	}
+	if s, ok := fi2.(sanitizedFileInfo); ok {
+		fi2 = s.FileInfo
+	}
+	return fs.Filesystem.SameFile(fi1, fi2)
+}
+
+func (fs *sanitizeFS) Options() []Option {
+	return append(fs.Filesystem.Options(), new(OptionSanitizeNames))
+}
+
+func (fs *sanitizeFS) underlying() (Filesystem, bool) {
+	return fs.Filesystem, true
+}
+
+type restoringMatcher struct {
+	Matcher
+}
+
+func (m restoringMatcher) Match(name string) ignoreresult.R {
+	return m.Matcher.Match(restoreName(name))
+}
+
+type sanitizedFileInfo struct {
+	FileInfo
+}
+
+func (fi sanitizedFileInfo) Name() string {
+	return restoreName(fi.FileInfo.Name())
+}
+
+func sanitizeFileInfo(fi FileInfo, err error) (FileInfo, error) {
+	if err != nil {
+		return nil, err
+	}
+	return sanitizedFileInfo{fi}, nil
+}
+
+type sanitizedFile struct {
+	File
+}
+
+func (f sanitizedFile) Name() string {
+	return restoreName(f.File.Name())
+}
+
+func (f sanitizedFile) Stat() (FileInfo, error) {
+	return sanitizeFileInfo(f.File.Stat())
+}
+
+func (f sanitizedFile) unwrap() File {
+	return f.File
+}
+
+func sanitizeFile(f File, err error) (File, error) {
+	if err != nil {
+		return nil, err
+	}
+	return sanitizedFile{f}, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeName(t *testing.T) {
+	cases := []struct {
+		name, sanitized string
+	}{
+		{"plain.txt", "plain.txt"},
+		{`what?.txt`, "what\uf03f.txt"},
+		{`a<b>:c"d|e*`, "a\uf03cb\uf03e\uf03ac\uf022d\uf07ce\uf02a"},
+		{"trailing.", "trailing\uf02e"},
+		{"trailing ", "trailing\uf020"},
+		{"nul.txt", "nu\uf06c.txt"},
+		{"COM1", "COM\uf031"},
+		{"console", "console"},
+		{filepath.Join("dir.", "aux"), filepath.Join("dir\uf02e", "au\uf078")},
+	}
+	for _, tc := range cases {
+		if s := sanitizeName(tc.name); s != tc.sanitized {
+			t.Errorf("sanitizeName(%q) = %q, expected %q", tc.name, s, tc.sanitized)
+		}
+		if r := restoreName(tc.sanitized); r != tc.name {
+			t.Errorf("restoreName(%q) = %q, expected %q", tc.sanitized, r, tc.name)
+		}
+		if err := WindowsInvalidFilename(tc.sanitized); err != nil {
+			t.Errorf("sanitized %q is invalid: %v", tc.sanitized, err)
+		}
+	}
+}
+
+func TestSanitizeFS(t *testing.T) {
+	fs := NewFilesystem(FilesystemTypeFake, "/sanitize", new(OptionSanitizeNames))
+	raw := NewFilesystem(FilesystemTypeFake, "/sanitize")
+
+	dir := "what?"
+	name := filepath.Join(dir, "nul.")
+	if err := fs.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fd, err := fs.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(fd.Name(), "nul.") {
+		t.Errorf("expected file name ending in %q, got %q", "nul.", fd.Name())
+	}
+	fd.Close()
+
+	if names, err := raw.DirNames("."); err != nil || !slices.Equal(names, []string{".stfolder", "what\uf03f"}) {
+		t.Errorf("unexpected names on disk %q, %v", names, err)
+	}
+	if names, err := fs.DirNames(dir); err != nil || !slices.Equal(names, []string{"nul."}) {
+		t.Errorf("unexpected names %q, %v", names, err)
+	}
+	info, err := fs.Lstat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name() != "nul." {
+		t.Errorf("expected name %q, got %q", "nul.", info.Name())
+	}
+
+	var walked []string
+	err = fs.Walk(".", func(path string, _ FileInfo, err error) error {
+		walked = append(walked, path)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(walked, []string{".", ".stfolder", dir, name}) {
+		t.Errorf("unexpected walk %q", walked)
+	}
+
+	if err := fs.Rename(name, filepath.Join(dir, "ok")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := raw.Lstat(filepath.Join("what\uf03f", "ok")); err != nil {
+		t.Error(err)
+	}
+}
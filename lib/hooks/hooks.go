@@ -0,0 +1,290 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package hooks runs user configured commands on sync lifecycle events.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/semaphore"
+)
+
+// maxQueued is the number of hook runs that may wait for a free slot;
+// runs beyond that are dropped, so that a burst of events can't pile up
+// an unbounded number of commands.
+const maxQueued = 100
+
+// The data passed to the hook command on standard input.
+type hookInput struct {
+	Event     config.HookEvent `json:"event"`
+	Time      time.Time        `json:"time"`
+	EventType string           `json:"eventType"`
+	Data      any              `json:"data"`
+}
+
+type Service struct {
+	cfg      config.Wrapper
+	evLogger events.Logger
+
+	// set on config commit
+	mut     sync.Mutex
+	hooks   []config.HookConfiguration
+	folders map[string]config.FolderConfiguration
+	sem     *semaphore.Semaphore
+
+	queued  atomic.Int32
+	running sync.WaitGroup
+
+	// folder ID -> in a sync batch; only used by Serve
+	syncing map[string]bool
+}
+
+func New(cfg config.Wrapper, evLogger events.Logger) *Service {
+	return &Service{
+		cfg:      cfg,
+		evLogger: evLogger,
+		sem:      semaphore.New(1),
+		syncing:  make(map[string]bool),
+	}
+}
+
+func (s *Service) Serve(ctx context.Context) error {
+	sub := s.evLogger.Subscribe(events.StateChanged | events.FolderErrors | events.ConflictCreated | events.DeviceConnected)
+	defer sub.Unsubscribe()
+
+	s.CommitConfiguration(config.Configuration{}, s.cfg.Subscribe(s))
+	defer s.cfg.Unsubscribe(s)
+
+	// Let running hooks finish or time out, they are killed when ctx is
+	// cancelled.
+	defer s.running.Wait()
+
+	for {
+		select {
+		case ev, ok := <-sub.C():
+			if !ok {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			for _, run := range s.hookRuns(ev) {
+				s.dispatch(ctx, run)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) CommitConfiguration(_, to config.Configuration) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.hooks = to.Hooks.Hooks
+	s.folders = to.FolderMap()
+	s.sem.SetCapacity(to.Hooks.MaxConcurrent)
+	return true
+}
+
+func (*Service) String() string {
+	return "hooks.Service"
+}
+
+// A hookRun is a hook command to run for an event.
+type hookRun struct {
+	hook  config.HookConfiguration
+	input hookInput
+	env   []string
+}
+
+// hookRuns returns the hooks to run for the given event.
+func (s *Service) hookRuns(ev events.Event) []hookRun {
+	kind, folderID, data, ok := s.classify(ev)
+	if !ok {
+		return nil
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	var env []string
+	if folderID != "" {
+		env = append(env, "ST_FOLDER_ID="+folderID)
+		if fcfg, ok := s.folders[folderID]; ok {
+			env = append(env, "ST_FOLDER_LABEL="+fcfg.Label, "ST_FOLDER_PATH="+fcfg.Path)
+		}
+	}
+	for key, envKey := range map[string]string{
+		"id":           "ST_DEVICE_ID",
+		"deviceName":   "ST_DEVICE_NAME",
+		"item":         "ST_ITEM",
+		"conflictCopy": "ST_CONFLICT_COPY",
+		"error":        "ST_ERROR",
+	} {
+		if v, ok := data[key].(string); ok {
+			env = append(env, envKey+"="+v)
+		}
+	}
+
+	var runs []hookRun
+	for _, hook := range s.hooks {
+		if hook.Event != kind || (hook.Folder != "" && hook.Folder != folderID) {
+			continue
+		}
+		runs = append(runs, hookRun{
+			hook: hook,
+			input: hookInput{
+				Event:     kind,
+				Time:      ev.Time,
+				EventType: ev.Type.String(),
+				Data:      data,
+			},
+			env: append([]string{"ST_HOOK_EVENT=" + string(kind)}, env...),
+		})
+	}
+	return runs
+}
+
+// classify maps a Syncthing event to a hook event, if any, returning its
+// folder and data.
+func (s *Service) classify(ev events.Event) (config.HookEvent, string, map[string]any, bool) {
+	data := eventData(ev.Data)
+	folderID, _ := data["folder"].(string)
+
+	switch ev.Type {
+	case events.StateChanged:
+		to, _ := data["to"].(string)
+		if to == "error" {
+			delete(s.syncing, folderID)
+			return config.HookFolderError, folderID, data, true
+		}
+		// A sync batch starts when preparing to pull, and lasts through
+		// repeated pulls until the folder goes into any other state.
+		inBatch := to == "sync-preparing" || to == "syncing"
+		switch {
+		case to == "sync-waiting":
+			// Waiting for a pull slot, which neither starts nor ends a batch
+		case inBatch && !s.syncing[folderID]:
+			s.syncing[folderID] = true
+			return config.HookFolderSyncStarted, folderID, data, true
+		case !inBatch && s.syncing[folderID]:
+			delete(s.syncing, folderID)
+			return config.HookFolderSyncFinished, folderID, data, true
+		}
+
+	case events.FolderErrors:
+		if errs, _ := data["errors"].([]any); len(errs) > 0 {
+			return config.HookFolderError, folderID, data, true
+		}
+
+	case events.ConflictCreated:
+		return config.HookConflict, folderID, data, true
+
+	case events.DeviceConnected:
+		return config.HookDeviceConnected, "", data, true
+	}
+
+	return "", "", nil, false
+}
+
+// eventData returns the event data as a generic map, as it would be seen
+// by a client of the event API.
+func eventData(v any) map[string]any {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var data map[string]any
+	if err := json.Unmarshal(bs, &data); err != nil {
+		return nil
+	}
+	return data
+}
+
+func (s *Service) dispatch(ctx context.Context, run hookRun) {
+	if s.queued.Add(1) > maxQueued {
+		s.queued.Add(-1)
+		slog.Warn("Too many hook commands waiting to run; skipping", "event", run.hook.Event, "command", run.hook.Command)
+		return
+	}
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		err := s.sem.TakeWithContext(ctx, 1)
+		s.queued.Add(-1)
+		if err != nil {
+			return
+		}
+		defer s.sem.Give(1)
+
+		if err := runHook(ctx, run); err != nil {
+			slog.Warn("Hook command failed", "event", run.hook.Event, "command", run.hook.Command, slogutil.Error(err))
+		}
+	}()
+}
+
+func runHook(ctx context.Context, run hookRun) error {
+	words, err := shellquote.Split(run.hook.Command)
+	if err != nil {
+		return fmt.Errorf("command is invalid: %w", err)
+	}
+	if len(words) == 0 {
+		return errors.New("command is empty")
+	}
+	input, err := json.Marshal(run.input)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(run.hook.TimeoutS)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, words[0], words[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(hookEnviron(), run.env...)
+	// Don't wait forever for output from children the command left behind
+	cmd.WaitDelay = time.Second
+
+	l.Debugln("running hook", run.hook.Event, words)
+	out, err := cmd.CombinedOutput()
+	l.Debugln("hook output:", string(out))
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %ds", run.hook.TimeoutS)
+	}
+	if err != nil {
+		if out = bytes.TrimSpace(out); len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
+
+// hookEnviron returns our environment without the GUI credentials.
+func hookEnviron() []string {
+	var env []string
+	for _, x := range os.Environ() {
+		if !strings.HasPrefix(x, "STGUIAUTH=") && !strings.HasPrefix(x, "STGUIAPIKEY=") {
+			env = append(env, x)
+		}
+	}
+	return env
+}
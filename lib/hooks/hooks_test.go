@@ -0,0 +1,113 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !windows
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func newTestService(hooks ...config.HookConfiguration) *Service {
+	cfg := config.New(protocol.LocalDeviceID)
+	cfg.Folders = []config.FolderConfiguration{{ID: "f1", Label: "Photos", Path: "/data/photos"}}
+	cfg.Hooks.Hooks = hooks
+	s := New(config.Wrap("/dev/null", cfg, protocol.LocalDeviceID, events.NoopLogger), events.NoopLogger)
+	s.CommitConfiguration(config.Configuration{}, cfg)
+	return s
+}
+
+func stateChanged(folder, from, to string) events.Event {
+	return events.Event{Type: events.StateChanged, Time: time.Now(), Data: map[string]any{"folder": folder, "from": from, "to": to}}
+}
+
+func TestSyncBatchEvents(t *testing.T) {
+	s := newTestService(
+		config.HookConfiguration{Event: config.HookFolderSyncStarted, Command: "start"},
+		config.HookConfiguration{Event: config.HookFolderSyncFinished, Command: "finish"},
+		config.HookConfiguration{Event: config.HookFolderSyncFinished, Command: "other", Folder: "f2"},
+	)
+
+	var commands []string
+	for _, ev := range []events.Event{
+		stateChanged("f1", "idle", "sync-waiting"),
+		stateChanged("f1", "sync-waiting", "sync-preparing"),
+		stateChanged("f1", "sync-preparing", "syncing"),
+		stateChanged("f1", "syncing", "sync-preparing"),
+		stateChanged("f1", "sync-preparing", "syncing"),
+		stateChanged("f1", "syncing", "idle"),
+		stateChanged("f1", "idle", "scanning"),
+	} {
+		for _, run := range s.hookRuns(ev) {
+			commands = append(commands, run.hook.Command)
+		}
+	}
+	if exp := []string{"start", "finish"}; !slices.Equal(commands, exp) {
+		t.Errorf("expected %v, got %v", exp, commands)
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	s := newTestService(config.HookConfiguration{
+		Event:    config.HookConflict,
+		Command:  `sh -c 'cat > "$0"; echo >> "$0"; echo "$ST_FOLDER_LABEL $ST_ITEM" >> "$0"' ` + out,
+		TimeoutS: 10,
+	})
+
+	runs := s.hookRuns(events.Event{Type: events.ConflictCreated, Time: time.Now(), Data: map[string]string{
+		"folder":       "f1",
+		"item":         "a.txt",
+		"conflictCopy": "a.sync-conflict-20260101-000000-AAAAAAA.txt",
+	}})
+	if len(runs) != 1 {
+		t.Fatalf("expected one run, got %d", len(runs))
+	}
+	if err := runHook(context.Background(), runs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, env, _ := strings.Cut(strings.TrimSpace(string(bs)), "\n")
+	var in hookInput
+	if err := json.Unmarshal([]byte(input), &in); err != nil {
+		t.Fatal(err)
+	}
+	if in.Event != config.HookConflict || in.EventType != "ConflictCreated" {
+		t.Errorf("unexpected input: %s", input)
+	}
+	if env != "Photos a.txt" {
+		t.Errorf("unexpected environment output %q", env)
+	}
+}
+
+func TestRunHookTimeout(t *testing.T) {
+	run := hookRun{hook: config.HookConfiguration{Command: "sleep 10", TimeoutS: 1}}
+	t0 := time.Now()
+	err := runHook(context.Background(), run)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout, got %v", err)
+	}
+	if time.Since(t0) > 5*time.Second {
+		t.Error("command should have been killed")
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -124,6 +125,7 @@ type Matcher struct {
 	fs             fs.Filesystem
 	lines          []string  // exact lines read from .stignore
 	patterns       []Pattern // patterns including those from included files
+	pinned         []string  // paths never ignored, with everything below them
 	withCache      bool
 	matches        *cache
 	curHash        string
@@ -211,7 +213,7 @@ func (m *Matcher) parseLocked(r io.Reader, file string) error {
 
 	m.lines = lines
 
-	newHash := hashPatterns(patterns)
+	newHash := hashPatterns(patterns, m.pinned)
 	if newHash == m.curHash {
 		// We've already loaded exactly these patterns.
 		return err
@@ -226,6 +228,38 @@ func (m *Matcher) parseLocked(r io.Reader, file string) error {
 	return err
 }
 
+// SetPinned sets the paths, relative to the folder root, that are never
+// ignored, including anything below them. The directories above them are
+// still ignored if the patterns say so, but no longer skipped.
+func (m *Matcher) SetPinned(paths []string) {
+	pinned := make([]string, 0, len(paths))
+	for _, p := range paths {
+		pinned = append(pinned, filepath.ToSlash(p))
+	}
+	slices.Sort(pinned)
+
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	newHash := hashPatterns(m.patterns, pinned)
+	if newHash == m.curHash {
+		return
+	}
+	m.curHash = newHash
+	m.pinned = pinned
+	if m.withCache {
+		m.matches = newCache()
+	}
+}
+
+func (m *Matcher) isPinnedLocked(file string) bool {
+	for _, p := range m.pinned {
+		if file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // Match matches the patterns plus temporary and internal files.
 //
 // The "file" parameter must be in the OS' native unicode format (NFD on macos,
@@ -267,12 +301,17 @@ func (m *Matcher) Match(file string) (result ignoreresult.R) {
 		}()
 	}
 
+	if m.isPinnedLocked(file) {
+		return ignoreresult.NotIgnored
+	}
+
 	// Check all the patterns for a match. Track whether the patterns so far
 	// allow skipping matched directories or not. As soon as we hit an
 	// exclude pattern (with some exceptions), we can't skip directories
-	// anymore.
+	// anymore, and neither can we when there are pinned paths which might
+	// be below them.
 	var lowercaseFile string
-	canSkipDir := true
+	canSkipDir := len(m.pinned) == 0
 	for _, pattern := range m.patterns {
 		if canSkipDir && !pattern.allowsSkippingIgnoredDirs() {
 			canSkipDir = false
@@ -348,12 +387,16 @@ func (m *Matcher) clean(d time.Duration) {
 	}
 }
 
-func hashPatterns(patterns []Pattern) string {
+func hashPatterns(patterns []Pattern, pinned []string) string {
 	h := sha256.New()
 	for _, pat := range patterns {
 		h.Write([]byte(pat.String()))
 		h.Write([]byte("\n"))
 	}
+	for _, p := range pinned {
+		h.Write([]byte("pinned " + p))
+		h.Write([]byte("\n"))
+	}
 	return hex.EncodeToString(h.Sum(nil))
 }
 
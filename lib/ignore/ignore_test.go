@@ -1731,3 +1731,49 @@ func testEscape(t *testing.T, tests []escapeTest, noErrors bool) {
 		}
 	}
 }
+
+func TestPinned(t *testing.T) {
+	testFS := fs.NewFilesystem(fs.FilesystemTypeFake, rand.String(32))
+	pats := New(testFS, WithCache(true))
+	if err := pats.Parse(bytes.NewBufferString("/photos\n*.tmp\n"), ".stignore"); err != nil {
+		t.Fatal(err)
+	}
+	if res := pats.Match("photos"); !res.IsIgnored() || !res.CanSkipDir() {
+		t.Fatal("expected photos to be ignored and skipped")
+	}
+
+	hash := pats.Hash()
+	pats.SetPinned([]string{"photos/2026/cover.jpg", "notes"})
+	if pats.Hash() == hash {
+		t.Error("expected pinning to change the hash")
+	}
+
+	cases := []struct {
+		file    string
+		ignored bool
+	}{
+		{"photos", true},
+		{"photos/2026", true},
+		{"photos/2026/cover.jpg", false},
+		{"photos/2026/other.jpg", true},
+		{"notes/todo.tmp", false},
+		{"other.tmp", true},
+	}
+	for _, tc := range cases {
+		res := pats.Match(tc.file)
+		if res.IsIgnored() != tc.ignored {
+			t.Errorf("%s: got ignored %v, want %v", tc.file, res.IsIgnored(), tc.ignored)
+		}
+		if res.CanSkipDir() {
+			t.Errorf("%s: expected not to be skipped with pinned paths", tc.file)
+		}
+	}
+
+	pats.SetPinned(nil)
+	if pats.Hash() != hash {
+		t.Error("expected the hash to be back to that without pinned paths")
+	}
+	if !pats.Match("photos/2026/cover.jpg").IsIgnored() {
+		t.Error("expected unpinned file to be ignored again")
+	}
+}
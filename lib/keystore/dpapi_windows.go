@@ -0,0 +1,70 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package keystore
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+func init() {
+	register("dpapi", dpapi{})
+}
+
+// dpapi keeps the key in a file next to the key file, encrypted with the
+// Data Protection API so that only the same user on the same machine can
+// decrypt it.
+type dpapi struct{}
+
+func (dpapi) Save(keyFile string, key []byte) (string, error) {
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(newBlob(key), nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return "", err
+	}
+	protected := blobBytes(out)
+	windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	protectedFile := keyFile + ".dpapi"
+	if err := writeFile(protectedFile, protected); err != nil {
+		return "", err
+	}
+	return protectedFile, nil
+}
+
+func (dpapi) Load(protectedFile string) ([]byte, error) {
+	protected, err := os.ReadFile(protectedFile)
+	if err != nil {
+		return nil, err
+	}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(newBlob(protected), nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, err
+	}
+	key := blobBytes(out)
+	windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return key, nil
+}
+
+func (dpapi) Delete(protectedFile string) error {
+	if err := os.Remove(protectedFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func newBlob(data []byte) *windows.DataBlob {
+	if len(data) == 0 {
+		return &windows.DataBlob{}
+	}
+	return &windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+}
+
+func blobBytes(blob windows.DataBlob) []byte {
+	return append([]byte(nil), unsafe.Slice(blob.Data, blob.Size)...)
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package keystore
+
+import (
+	"fmt"
+
+	"github.com/syncthing/syncthing/lib/rand"
+)
+
+const keychainService = "syncthing"
+
+func init() {
+	register("keychain", keychain{})
+}
+
+// keychain keeps the key as a generic password in the login keychain, using
+// the security tool.
+type keychain struct{}
+
+func (keychain) Save(_ string, key []byte) (string, error) {
+	account := rand.String(16)
+	// Commands given on standard input don't show up in the process list.
+	cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -l syncthing-device-key -w %s\n", keychainService, account, encodeSecret(key))
+	if _, err := runCommand([]byte(cmd), "security", "-i"); err != nil {
+		return "", err
+	}
+	return account, nil
+}
+
+func (keychain) Load(account string) ([]byte, error) {
+	out, err := runCommand(nil, "security", "find-generic-password", "-s", keychainService, "-a", account, "-w")
+	if err != nil {
+		return nil, err
+	}
+	return decodeSecret(out)
+}
+
+func (keychain) Delete(account string) error {
+	_, err := runCommand(nil, "security", "delete-generic-password", "-s", keychainService, "-a", account)
+	return err
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package keystore keeps the device private key in a key store provided by
+// the operating system instead of a PEM file. The key file then holds a
+// reference to where the key is kept, so that it is found in the same place
+// as before regardless of where it's stored.
+package keystore
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/osutil"
+)
+
+// File is the name for keeping the key in the key file itself.
+const File = "file"
+
+const referencePrefix = "syncthing-keystore "
+
+// A Backend keeps private keys in a key store.
+type Backend interface {
+	// Save stores the PEM encoded key that belongs at the given key file,
+	// returning the reference to load it by.
+	Save(keyFile string, key []byte) (ref string, err error)
+	Load(ref string) ([]byte, error)
+	Delete(ref string) error
+}
+
+var backends = make(map[string]Backend)
+
+func register(name string, b Backend) {
+	backends[name] = b
+}
+
+// Backends returns the names of the key stores available on this system.
+func Backends() []string {
+	names := []string{File}
+	for name := range backends {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// IsReference returns true if the key file refers to a key store, i.e. the
+// key isn't in the file itself.
+func IsReference(keyFile string) bool {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return false
+	}
+	_, _, ok := parseReference(data)
+	return ok
+}
+
+// LoadX509KeyPair is like tls.LoadX509KeyPair, except the key is loaded from
+// the key store the key file refers to, if any.
+func LoadX509KeyPair(certFile, keyFile string) (tls.Certificate, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyPEM, _, err := loadKey(keyFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// Migrate moves the key into the named key store, or back into the key file
+// if the name is File, leaving a reference to it in the key file. The key
+// is removed from where it was only once it has been stored and read back
+// successfully.
+func Migrate(keyFile, to string) error {
+	keyPEM, from, err := loadKey(keyFile)
+	if err != nil {
+		return err
+	}
+	if from.name == to {
+		return nil
+	}
+	if block, _ := pem.Decode(keyPEM); block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return errors.New("key file does not contain a private key")
+	}
+
+	if to == File {
+		if err := writeFile(keyFile, keyPEM); err != nil {
+			return err
+		}
+	} else {
+		b, ok := backends[to]
+		if !ok {
+			return fmt.Errorf("key store %q is not available on this system (available: %s)", to, strings.Join(Backends(), ", "))
+		}
+		ref, err := b.Save(keyFile, keyPEM)
+		if err != nil {
+			return fmt.Errorf("saving key to %s key store: %w", to, err)
+		}
+		if stored, err := b.Load(ref); err != nil || !bytes.Equal(stored, keyPEM) {
+			_ = b.Delete(ref)
+			if err == nil {
+				err = errors.New("key read back differs")
+			}
+			return fmt.Errorf("verifying key in %s key store: %w", to, err)
+		}
+		if err := writeFile(keyFile, formatReference(to, ref)); err != nil {
+			_ = b.Delete(ref)
+			return err
+		}
+	}
+
+	if from.name != File {
+		if err := backends[from.name].Delete(from.ref); err != nil {
+			slog.Warn("Failed to remove key from previous key store", slog.String("keystore", from.name), slogutil.Error(err))
+		}
+	}
+	slog.Info("Moved device key", slog.String("from", from.name), slog.String("to", to))
+	return nil
+}
+
+type location struct {
+	name string
+	ref  string
+}
+
+// loadKey returns the PEM encoded key for the key file, and where it was
+// loaded from.
+func loadKey(keyFile string) ([]byte, location, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, location{}, err
+	}
+	name, ref, ok := parseReference(data)
+	if !ok {
+		return data, location{name: File}, nil
+	}
+	b, ok := backends[name]
+	if !ok {
+		return nil, location{}, fmt.Errorf("key file refers to key store %q, which is not available on this system", name)
+	}
+	key, err := b.Load(ref)
+	if err != nil {
+		return nil, location{}, fmt.Errorf("loading key from %s key store: %w", name, err)
+	}
+	return key, location{name: name, ref: ref}, nil
+}
+
+func formatReference(name, ref string) []byte {
+	return []byte(referencePrefix + name + " " + ref + "\n")
+}
+
+func parseReference(data []byte) (name, ref string, ok bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(string(data)), referencePrefix)
+	if !ok {
+		return "", "", false
+	}
+	name, ref, ok = strings.Cut(rest, " ")
+	return name, ref, ok && name != "" && ref != ""
+}
+
+func writeFile(path string, data []byte) error {
+	fd, err := osutil.CreateAtomic(path)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(data); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/tlsutil"
+)
+
+type fakeBackend struct {
+	keys map[string][]byte
+}
+
+func (b *fakeBackend) Save(keyFile string, key []byte) (string, error) {
+	ref := filepath.Base(keyFile)
+	b.keys[ref] = bytes.Clone(key)
+	return ref, nil
+}
+
+func (b *fakeBackend) Load(ref string) ([]byte, error) {
+	key, ok := b.keys[ref]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return key, nil
+}
+
+func (b *fakeBackend) Delete(ref string) error {
+	delete(b.keys, ref)
+	return nil
+}
+
+func TestMigrate(t *testing.T) {
+	fake := &fakeBackend{keys: make(map[string][]byte)}
+	register("fake", fake)
+	defer delete(backends, "fake")
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	cert, err := tlsutil.NewCertificate(certFile, keyFile, "syncthing", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(keyFile, "fake"); err != nil {
+		t.Fatal(err)
+	}
+	if !IsReference(keyFile) {
+		t.Error("expected key file to refer to the key store")
+	}
+	if !bytes.Equal(fake.keys["key.pem"], keyPEM) {
+		t.Error("expected key in the key store")
+	}
+	loaded, err := LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(loaded.Certificate[0], cert.Certificate[0]) {
+		t.Error("loaded a different certificate")
+	}
+
+	// Migrating again is a no-op.
+	if err := Migrate(keyFile, "fake"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Migrate(keyFile, "nonexistent"); err == nil {
+		t.Error("expected error migrating to an unknown key store")
+	}
+
+	// And back into the file.
+	if err := Migrate(keyFile, File); err != nil {
+		t.Fatal(err)
+	}
+	if IsReference(keyFile) {
+		t.Error("expected key in key file")
+	}
+	if len(fake.keys) != 0 {
+		t.Error("expected key to be removed from the key store")
+	}
+	if data, _ := os.ReadFile(keyFile); !bytes.Equal(data, keyPEM) {
+		t.Error("expected original key in key file")
+	}
+}
+
+func TestLoadUnavailableBackend(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if _, err := tlsutil.NewCertificate(certFile, keyFile, "syncthing", 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, formatReference("nonexistent", "ref"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsReference(keyFile) {
+		t.Error("expected key file to refer to a key store")
+	}
+	if _, err := LoadX509KeyPair(certFile, keyFile); err == nil {
+		t.Error("expected error loading from an unavailable key store")
+	}
+}
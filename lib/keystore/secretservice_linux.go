@@ -0,0 +1,70 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package keystore
+
+import (
+	"os"
+
+	"github.com/syncthing/syncthing/lib/rand"
+)
+
+func init() {
+	register("secret-service", secretService{})
+	register("tpm2", tpm2{})
+}
+
+// secretService keeps the key with the desktop's Secret Service (GNOME
+// Keyring, KWallet), using secret-tool.
+type secretService struct{}
+
+func (secretService) Save(_ string, key []byte) (string, error) {
+	account := rand.String(16)
+	if _, err := runCommand([]byte(encodeSecret(key)), "secret-tool", "store", "--label=Syncthing device key", "service", "syncthing", "account", account); err != nil {
+		return "", err
+	}
+	return account, nil
+}
+
+func (secretService) Load(account string) ([]byte, error) {
+	out, err := runCommand(nil, "secret-tool", "lookup", "service", "syncthing", "account", account)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSecret(out)
+}
+
+func (secretService) Delete(account string) error {
+	_, err := runCommand(nil, "secret-tool", "clear", "service", "syncthing", "account", account)
+	return err
+}
+
+const tpm2CredentialName = "syncthing-device-key"
+
+// tpm2 keeps the key in a file next to the key file, encrypted by a key
+// sealed in the TPM using systemd-creds. Decrypting it requires access to
+// the TPM of the same machine, typically given by membership of the tss
+// group.
+type tpm2 struct{}
+
+func (tpm2) Save(keyFile string, key []byte) (string, error) {
+	credFile := keyFile + ".cred"
+	if _, err := runCommand(key, "systemd-creds", "encrypt", "--with-key=tpm2", "--name="+tpm2CredentialName, "-", credFile); err != nil {
+		return "", err
+	}
+	return credFile, nil
+}
+
+func (tpm2) Load(credFile string) ([]byte, error) {
+	return runCommand(nil, "systemd-creds", "decrypt", "--name="+tpm2CredentialName, credFile, "-")
+}
+
+func (tpm2) Delete(credFile string) error {
+	if err := os.Remove(credFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
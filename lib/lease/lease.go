@@ -0,0 +1,66 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package lease elects which of several instances sharing the same storage
+// is the active one, through an advisory lock on a file on that storage.
+// The lock is held until it's released or the process exits, so there is
+// never more than one active instance and no clocks are involved. The
+// storage must support locking across hosts, as NFS (with lockd or v4) and
+// SMB do.
+package lease
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// How often to try taking the lock while another instance holds it.
+var retryInterval = time.Second
+
+type Lease struct {
+	lock *flock.Flock
+}
+
+// New returns a lease kept as a lock on the given file.
+func New(path string) *Lease {
+	return &Lease{lock: flock.New(path)}
+}
+
+// TryAcquire takes the lease if it's free, returning whether we're now the
+// holder.
+func (l *Lease) TryAcquire() (bool, error) {
+	return l.lock.TryLock()
+}
+
+// Acquire waits until the lease is ours, calling waiting each time another
+// instance holds it.
+func (l *Lease) Acquire(ctx context.Context, waiting func()) error {
+	for {
+		ok, err := l.TryAcquire()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if waiting != nil {
+			waiting()
+		}
+		select {
+		case <-time.After(retryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release gives up the lease, if we hold it. This should only happen once
+// nothing is written to the shared storage anymore.
+func (l *Lease) Release() error {
+	return l.lock.Unlock()
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package lease
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func init() {
+	retryInterval = 10 * time.Millisecond
+}
+
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease")
+	active := New(path)
+	standby := New(path)
+
+	if ok, err := active.TryAcquire(); err != nil || !ok {
+		t.Fatal("expected to acquire free lease:", ok, err)
+	}
+	if ok, err := standby.TryAcquire(); err != nil || ok {
+		t.Fatal("expected not to acquire held lease:", ok, err)
+	}
+
+	// Standby waits for as long as the lease is held, however long that is.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	waited := false
+	if err := standby.Acquire(ctx, func() { waited = true }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected to keep waiting, got", err)
+	}
+	if !waited {
+		t.Error("expected to be told about waiting")
+	}
+
+	// Once the active one releases it, the standby gets it.
+	acquired := make(chan error, 1)
+	go func() { acquired <- standby.Acquire(context.Background(), nil) }()
+	if err := active.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-acquired; err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := active.TryAcquire(); err != nil || ok {
+		t.Fatal("expected not to acquire lease held by the standby:", ok, err)
+	}
+	if err := standby.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
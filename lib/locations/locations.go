@@ -23,6 +23,7 @@ type LocationEnum string
 // more meaningful.
 const (
 	ConfigFile     LocationEnum = "config"
+	ConfigDropIns  LocationEnum = "configDropIns"
 	CertFile       LocationEnum = "certFile"
 	KeyFile        LocationEnum = "keyFile"
 	HTTPSCertFile  LocationEnum = "httpsCertFile"
@@ -35,6 +36,7 @@ const (
 	GUIAssets      LocationEnum = "guiAssets"
 	DefFolder      LocationEnum = "defFolder"
 	LockFile       LocationEnum = "lockFile"
+	MovedMarker    LocationEnum = "movedMarker"
 )
 
 type BaseDirEnum string
@@ -117,6 +119,7 @@ func GetBaseDir(baseDir BaseDirEnum) string {
 // Use the variables from baseDirs here
 var locationTemplates = map[LocationEnum]string{
 	ConfigFile:     "${config}/config.xml",
+	ConfigDropIns:  "${config}/config.d",
 	CertFile:       "${config}/cert.pem",
 	KeyFile:        "${config}/key.pem",
 	HTTPSCertFile:  "${config}/https-cert.pem",
@@ -129,6 +132,7 @@ var locationTemplates = map[LocationEnum]string{
 	GUIAssets:      "${config}/gui",
 	DefFolder:      "${userHome}/Sync",
 	LockFile:       "${data}/syncthing.lock",
+	MovedMarker:    "${data}/syncthing.moved", // left by `syncthing migrate import`
 }
 
 var locations = make(map[LocationEnum]string)
@@ -148,10 +152,29 @@ func expandLocations() error {
 		}
 		newLocations[key] = filepath.Clean(dir)
 	}
+	newLocations[ConfigFile] = existingConfigFile(newLocations[ConfigFile])
 	locations = newLocations
 	return nil
 }
 
+// altConfigFileNames are the names the config file may have instead of
+// config.xml, selecting another format.
+var altConfigFileNames = []string{"config.yaml", "config.yml", "config.json"}
+
+// existingConfigFile returns the given config file path, or, when it
+// doesn't exist, a config file in another format next to it.
+func existingConfigFile(path string) string {
+	if fileExists(path) {
+		return path
+	}
+	for _, name := range altConfigFileNames {
+		if alt := filepath.Join(filepath.Dir(path), name); fileExists(alt) {
+			return alt
+		}
+	}
+	return path
+}
+
 // ListExpandedPaths returns a machine-readable mapping of the currently configured locations.
 func ListExpandedPaths() map[string]string {
 	res := make(map[string]string, len(locations))
@@ -168,6 +191,7 @@ func ListExpandedPaths() map[string]string {
 func PrettyPaths() string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "Configuration file:\n\t%s\n\n", Get(ConfigFile))
+	fmt.Fprintf(&b, "Configuration drop-in directory:\n\t%s\n\n", Get(ConfigDropIns))
 	fmt.Fprintf(&b, "Device private key & certificate files:\n\t%s\n\t%s\n\n", Get(KeyFile), Get(CertFile))
 	fmt.Fprintf(&b, "GUI / API HTTPS private key & certificate files:\n\t%s\n\t%s\n\n", Get(HTTPSKeyFile), Get(HTTPSCertFile))
 	fmt.Fprintf(&b, "Database location:\n\t%s\n\n", Get(Database))
@@ -9,6 +9,7 @@
 package locations
 
 import (
+	"os"
 	"path/filepath"
 	"slices"
 	"testing"
@@ -109,3 +110,28 @@ func TestGetTimestamped(t *testing.T) {
 		t.Errorf("got %q, expected %q", file, exp)
 	}
 }
+
+func TestExistingConfigFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	xml := filepath.Join(dir, "config.xml")
+	if got := existingConfigFile(xml); got != xml {
+		t.Errorf("expected %q for a new config, got %q", xml, got)
+	}
+
+	yaml := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(yaml, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := existingConfigFile(xml); got != yaml {
+		t.Errorf("expected %q, got %q", yaml, got)
+	}
+
+	if err := os.WriteFile(xml, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := existingConfigFile(xml); got != xml {
+		t.Errorf("expected existing %q to be preferred, got %q", xml, got)
+	}
+}
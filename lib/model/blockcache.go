@@ -0,0 +1,75 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"container/list"
+	"sync"
+)
+
+// blockCache keeps recently served blocks in memory, keyed by block hash,
+// up to a total size in bytes. Evicts the least recently used blocks first.
+// It is safe for use from multiple goroutines.
+type blockCache struct {
+	max     int
+	size    int
+	entries map[string]*list.Element
+	lru     list.List // of *blockCacheEntry, most recently used first
+	mut     sync.Mutex
+}
+
+type blockCacheEntry struct {
+	hash string
+	data []byte
+}
+
+func newBlockCache(maxBytes int) *blockCache {
+	return &blockCache{
+		max:     maxBytes,
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get copies the block with the given hash into buf, returning false if it
+// is not cached or of a different size than buf.
+func (c *blockCache) get(hash []byte, buf []byte) bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	e, ok := c.entries[string(hash)]
+	if !ok {
+		return false
+	}
+	data := e.Value.(*blockCacheEntry).data
+	if len(data) != len(buf) {
+		return false
+	}
+	c.lru.MoveToFront(e)
+	copy(buf, data)
+	return true
+}
+
+// put adds a copy of the block with the given hash.
+func (c *blockCache) put(hash []byte, data []byte) {
+	if len(hash) == 0 || len(data) > c.max {
+		return
+	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if e, ok := c.entries[string(hash)]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+	entry := &blockCacheEntry{hash: string(hash), data: append([]byte(nil), data...)}
+	c.entries[entry.hash] = c.lru.PushFront(entry)
+	c.size += len(data)
+	for c.size > c.max {
+		oldest := c.lru.Back()
+		old := c.lru.Remove(oldest).(*blockCacheEntry)
+		delete(c.entries, old.hash)
+		c.size -= len(old.data)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBlockCache(t *testing.T) {
+	c := newBlockCache(10)
+	c.put([]byte("a"), []byte("aaaa"))
+	c.put([]byte("b"), []byte("bbbb"))
+
+	buf := make([]byte, 4)
+	if !c.get([]byte("a"), buf) || !bytes.Equal(buf, []byte("aaaa")) {
+		t.Fatalf("expected a to be cached, got %q", buf)
+	}
+	if c.get([]byte("a"), make([]byte, 3)) {
+		t.Error("expected no hit for a buffer of the wrong size")
+	}
+
+	// Adding c exceeds the limit and evicts b, which was used least
+	// recently.
+	c.put([]byte("c"), []byte("cccc"))
+	if c.get([]byte("b"), buf) {
+		t.Error("expected b to be evicted")
+	}
+	if !c.get([]byte("a"), buf) || !c.get([]byte("c"), buf) {
+		t.Error("expected a and c to be cached")
+	}
+
+	c.put([]byte("d"), make([]byte, 11))
+	if c.get([]byte("d"), make([]byte, 11)) {
+		t.Error("expected a block larger than the cache not to be cached")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+var (
+	errCaseVariantNotLocal = errors.New("file is not present locally; rename it on a device that has it")
+	errCaseConflictRemains = errors.New("new name differs from an existing file only in case")
+)
+
+// A CaseConflict is a set of files in the global index whose names differ
+// only in upper or lowercase characters. They can't all exist on a case
+// insensitive filesystem, so at most one of them syncs there.
+type CaseConflict struct {
+	Names []string `json:"names"`
+	// Local is the variant we have on disk, if any.
+	Local string `json:"local,omitempty"`
+}
+
+// CaseConflicts returns the case conflicts in the global index of the
+// folder, sorted by their first name.
+func (m *model) CaseConflicts(folder string) ([]CaseConflict, error) {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	m.mut.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	byFolded := make(map[string][]string)
+	it, errFn := m.sdb.AllGlobalFiles(folder)
+	for meta := range it {
+		if meta.Deleted {
+			continue
+		}
+		folded := fs.UnicodeLowercaseNormalized(meta.Name)
+		byFolded[folded] = append(byFolded[folded], meta.Name)
+	}
+	if err := errFn(); err != nil {
+		return nil, err
+	}
+
+	var conflicts []CaseConflict
+	for _, names := range byFolded {
+		if len(names) < 2 {
+			continue
+		}
+		slices.Sort(names)
+		conflict := CaseConflict{Names: names}
+		for _, name := range names {
+			fi, ok, err := m.sdb.GetDeviceFile(folder, protocol.LocalDeviceID, name)
+			if err != nil {
+				return nil, err
+			}
+			if ok && !fi.IsDeleted() && !fi.IsInvalid() {
+				conflict.Local = name
+				break
+			}
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	slices.SortFunc(conflicts, func(a, b CaseConflict) int {
+		return strings.Compare(a.Names[0], b.Names[0])
+	})
+	return conflicts, nil
+}
+
+// ResolveCaseConflict renames the local variant of a case conflict, so
+// that the other variants can sync. The rename syncs to other devices like
+// any other change.
+func (m *model) ResolveCaseConflict(folder, name, newName string) error {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	fcfg := m.folderCfgs[folder]
+	m.mut.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if name, err = fs.Canonicalize(name); err != nil {
+		return err
+	}
+	if newName, err = fs.Canonicalize(newName); err != nil {
+		return err
+	}
+
+	fi, ok, err := m.sdb.GetDeviceFile(folder, protocol.LocalDeviceID, name)
+	if err != nil {
+		return err
+	}
+	if !ok || fi.IsDeleted() || fi.IsInvalid() {
+		return errCaseVariantNotLocal
+	}
+
+	// The new name must not bring on another case conflict.
+	folded := fs.UnicodeLowercaseNormalized(newName)
+	clash := false
+	it, errFn := m.sdb.AllGlobalFiles(folder)
+	for meta := range it {
+		if !meta.Deleted && meta.Name != name && fs.UnicodeLowercaseNormalized(meta.Name) == folded {
+			clash = true
+			break
+		}
+	}
+	if err := errFn(); err != nil {
+		return err
+	}
+	if clash {
+		return errCaseConflictRemains
+	}
+
+	ffs := fcfg.Filesystem()
+	if _, err := ffs.Lstat(newName); err == nil {
+		return fmt.Errorf("%s: file exists", newName)
+	}
+	if err := ffs.MkdirAll(filepath.Dir(newName), 0o755); err != nil {
+		return err
+	}
+	if err := ffs.Rename(name, newName); err != nil {
+		return err
+	}
+	return m.ScanFolderSubdirs(folder, []string{name, newName})
+}
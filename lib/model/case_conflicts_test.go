@@ -0,0 +1,58 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestCaseConflicts(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	ffs := fcfg.Filesystem()
+	writeFile(t, ffs, "file.txt", []byte("local"))
+	writeFile(t, ffs, "other.txt", []byte("other"))
+	m, conn := setupModelWithConnectionFromWrapper(t, w)
+	defer cleanupModel(m)
+
+	remote := protocol.FileInfo{
+		Name:    "File.txt",
+		Type:    protocol.FileInfoTypeFile,
+		Size:    1,
+		Version: protocol.Vector{}.Update(device1.Short()),
+		Blocks:  []protocol.BlockInfo{{Size: 1, Hash: []byte("0123456789abcdef0123456789abcdef")}},
+	}
+	must(t, m.Index(conn, &protocol.Index{Folder: fcfg.ID, Files: []protocol.FileInfo{remote}}))
+
+	conflicts, err := m.CaseConflicts(fcfg.ID)
+	must(t, err)
+	if len(conflicts) != 1 {
+		t.Fatal("expected one conflict, got", conflicts)
+	}
+	if !slices.Equal(conflicts[0].Names, []string{"File.txt", "file.txt"}) || conflicts[0].Local != "file.txt" {
+		t.Error("unexpected conflict", conflicts[0])
+	}
+
+	if err := m.ResolveCaseConflict(fcfg.ID, "File.txt", "renamed.txt"); err != errCaseVariantNotLocal {
+		t.Error("expected remote only variant to be refused, got", err)
+	}
+	if err := m.ResolveCaseConflict(fcfg.ID, "file.txt", "OTHER.txt"); err != errCaseConflictRemains {
+		t.Error("expected new conflict to be refused, got", err)
+	}
+	must(t, m.ResolveCaseConflict(fcfg.ID, "file.txt", "file (local).txt"))
+
+	if _, err := ffs.Lstat("file (local).txt"); err != nil {
+		t.Error("renamed file missing:", err)
+	}
+	conflicts, err = m.CaseConflicts(fcfg.ID)
+	must(t, err)
+	if len(conflicts) != 0 {
+		t.Error("expected no conflicts, got", conflicts)
+	}
+}
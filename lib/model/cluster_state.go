@@ -0,0 +1,148 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// ClusterFolderState describes which devices share a folder and what each
+// of them has told us about it.
+type ClusterFolderState struct {
+	FolderID string               `json:"folderID"`
+	Paused   bool                 `json:"paused"`
+	Devices  []ClusterDeviceState `json:"devices"`
+}
+
+// ClusterDeviceState is what a device has told us about a shared folder,
+// as of its latest cluster config and index updates. Fields other than
+// DeviceID, Connected and Paused are zero for devices we have not heard
+// from since startup.
+type ClusterDeviceState struct {
+	DeviceID  protocol.DeviceID `json:"deviceID"`
+	Connected bool              `json:"connected"`
+	// Paused is true when we have paused the device, or when the device
+	// announces the folder as paused.
+	Paused bool `json:"paused"`
+	// RemoteState is the folder state announced in the device's cluster
+	// config while connected.
+	RemoteState remoteFolderState `json:"remoteState"`
+	// MaxSequence is the highest sequence number the device has announced
+	// for its own files in the folder.
+	MaxSequence     int64     `json:"maxSequence"`
+	LastIndexUpdate time.Time `json:"lastIndexUpdate"`
+	// AvailableBytes is the disk space the device announced it can still
+	// use for the folder, zero if unknown and negative if out of space.
+	AvailableBytes int64 `json:"availableBytes"`
+	// Label is the device's label for the folder. Path is where it keeps
+	// the folder and MarkerPresent whether the folder marker exists there,
+	// when the device advertises its folder paths.
+	Label         string `json:"label"`
+	Path          string `json:"path"`
+	MarkerPresent bool   `json:"markerPresent"`
+}
+
+// clusterIndexInfo keeps the sequence numbers announced by devices, and
+// when they last sent index data, per folder. Unlike the remote folder
+// states it is kept when devices disconnect.
+type clusterIndexInfo struct {
+	mut     sync.Mutex
+	devices map[protocol.DeviceID]map[string]clusterIndexEntry
+}
+
+type clusterIndexEntry struct {
+	maxSequence int64
+	lastIndex   time.Time
+}
+
+func newClusterIndexInfo() *clusterIndexInfo {
+	return &clusterIndexInfo{devices: make(map[protocol.DeviceID]map[string]clusterIndexEntry)}
+}
+
+// announced records the max sequence the device announced for the folder
+// in a cluster config.
+func (c *clusterIndexInfo) announced(device protocol.DeviceID, folder string, maxSequence int64) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	e := c.folderLocked(device)[folder]
+	e.maxSequence = maxSequence
+	c.devices[device][folder] = e
+}
+
+// received records that the device sent index data for the folder, up to
+// the given sequence.
+func (c *clusterIndexInfo) received(device protocol.DeviceID, folder string, lastSequence int64, now time.Time) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	e := c.folderLocked(device)[folder]
+	e.maxSequence = max(e.maxSequence, lastSequence)
+	e.lastIndex = now
+	c.devices[device][folder] = e
+}
+
+func (c *clusterIndexInfo) get(device protocol.DeviceID, folder string) clusterIndexEntry {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.devices[device][folder]
+}
+
+func (c *clusterIndexInfo) folderLocked(device protocol.DeviceID) map[string]clusterIndexEntry {
+	folders, ok := c.devices[device]
+	if !ok {
+		folders = make(map[string]clusterIndexEntry)
+		c.devices[device] = folders
+	}
+	return folders
+}
+
+// ClusterState returns, for each configured folder, the devices it is
+// shared with and their state as known from cluster configs and index
+// updates.
+func (m *model) ClusterState() []ClusterFolderState {
+	folders := m.cfg.Folders()
+	devices := m.cfg.Devices()
+
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+
+	res := make([]ClusterFolderState, 0, len(folders))
+	for _, fcfg := range folders {
+		fs := ClusterFolderState{FolderID: fcfg.ID, Paused: fcfg.Paused}
+		for _, dev := range fcfg.Devices {
+			if dev.DeviceID == m.id {
+				continue
+			}
+			state := m.remoteFolderStates[dev.DeviceID][fcfg.ID]
+			index := m.clusterIndexInfo.get(dev.DeviceID, fcfg.ID)
+			space, _ := m.space.get(dev.DeviceID, fcfg.ID)
+			info := m.remoteFolderInfos.get(dev.DeviceID, fcfg.ID)
+			_, connected := m.deviceConnIDs[dev.DeviceID]
+			fs.Devices = append(fs.Devices, ClusterDeviceState{
+				DeviceID:        dev.DeviceID,
+				Connected:       connected,
+				Paused:          devices[dev.DeviceID].Paused || state == remoteFolderPaused,
+				RemoteState:     state,
+				MaxSequence:     index.maxSequence,
+				LastIndexUpdate: index.lastIndex,
+				AvailableBytes:  space.available,
+				Label:           info.label,
+				Path:            info.path,
+				MarkerPresent:   info.markerPresent,
+			})
+		}
+		res = append(res, fs)
+	}
+	slices.SortFunc(res, func(a, b ClusterFolderState) int {
+		return strings.Compare(a.FolderID, b.FolderID)
+	})
+	return res
+}
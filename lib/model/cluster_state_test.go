@@ -0,0 +1,64 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestClusterState(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	m, fc := setupModelWithConnectionFromWrapper(t, w)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	m.ClusterConfig(fc, &protocol.ClusterConfig{
+		Folders: []protocol.Folder{
+			{
+				ID: fcfg.ID,
+				Devices: []protocol.Device{
+					{ID: myID},
+					{ID: device1, MaxSequence: 42},
+				},
+			},
+		},
+	})
+	fc.addFile("a", 0o644, protocol.FileInfoTypeFile, []byte("a"))
+	fc.sendIndexUpdate()
+
+	var dev ClusterDeviceState
+	for i := 0; ; i++ {
+		state := m.ClusterState()
+		if len(state) != 1 || state[0].FolderID != fcfg.ID {
+			t.Fatalf("unexpected cluster state %v", state)
+		}
+		for _, d := range state[0].Devices {
+			if d.DeviceID == device1 {
+				dev = d
+			}
+		}
+		if !dev.LastIndexUpdate.IsZero() {
+			break
+		}
+		if i == 100 {
+			t.Fatal("timed out waiting for index update")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if !dev.Connected || dev.Paused {
+		t.Errorf("expected device1 to be connected and not paused, got %+v", dev)
+	}
+	if dev.RemoteState != remoteFolderValid {
+		t.Errorf("expected remote state valid, got %v", dev.RemoteState)
+	}
+	if dev.MaxSequence != 42 {
+		t.Errorf("expected the announced max sequence 42, got %d", dev.MaxSequence)
+	}
+}
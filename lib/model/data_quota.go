@@ -0,0 +1,118 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// The data exchanged with each device is accounted per calendar month. A
+// device may have a monthly quota, which once exceeded either pauses the
+// device or stops us from using relays to connect to it, until the next
+// month. We remember the month in which we acted on the quota, so that we
+// act only once: a device that is resumed by the user stays resumed, and a
+// device that was paused for the quota is resumed in the next month.
+
+var errQuotaExceeded = errors.New("monthly data quota exceeded")
+
+func (m *model) quotaExceeded() *db.Typed {
+	return db.NewTyped(m.sdb, "quotaexceeded/")
+}
+
+// accountUsage adds the bytes transferred with each device since the last
+// sample to its usage for the month, and acts on the quotas.
+func (m *model) accountUsage(now time.Time, deltas map[protocol.DeviceID]transferBytes) {
+	kv := m.quotaExceeded()
+	month := now.Format("2006-01")
+	for dev, cfg := range m.cfg.Devices() {
+		if dev == m.id {
+			continue
+		}
+		m.mut.RLock()
+		ref, ok := m.deviceStatRefs[dev]
+		m.mut.RUnlock()
+		if !ok {
+			continue
+		}
+		delta := deltas[dev]
+		usage, err := ref.AddUsage(now, delta.in, delta.out)
+		if err != nil {
+			slog.Warn("Failed to record data usage", dev.LogAttr(), slogutil.Error(err))
+			continue
+		}
+
+		exceededIn, ok, err := kv.String(dev.String())
+		if err != nil {
+			continue
+		}
+		if ok && exceededIn != month {
+			m.quotaRenewed(dev)
+			_ = kv.Delete(dev.String())
+			ok = false
+		}
+		if !cfg.QuotaExceeded(usage.TotalBytes()) {
+			continue
+		}
+		if cfg.QuotaAction == config.QuotaActionDisableRelays {
+			m.closeRelayConnections(dev)
+		}
+		if ok {
+			continue
+		}
+		if err := kv.PutString(dev.String(), month); err != nil {
+			slog.Warn("Failed to record exceeded data quota", dev.LogAttr(), slogutil.Error(err))
+			continue
+		}
+		slog.Warn("Device exceeded its monthly data quota", dev.LogAttr(), slog.Int("quotaMiB", cfg.MonthlyQuotaMiB), slog.String("action", cfg.QuotaAction.String()))
+		if cfg.QuotaAction == config.QuotaActionPause {
+			m.setDevicePaused(dev, true)
+		}
+	}
+}
+
+// quotaRenewed resumes a device that was paused for exceeding its quota in
+// a previous month.
+func (m *model) quotaRenewed(dev protocol.DeviceID) {
+	cfg, ok := m.cfg.Device(dev)
+	if !ok || cfg.QuotaAction != config.QuotaActionPause || !cfg.Paused {
+		return
+	}
+	slog.Info("Resuming device for the new month of its data quota", dev.LogAttr())
+	m.setDevicePaused(dev, false)
+}
+
+func (m *model) setDevicePaused(dev protocol.DeviceID, paused bool) {
+	// Modifying the config waits for us to commit it, which must not
+	// happen on the serve routine.
+	go m.cfg.Modify(func(cfg *config.Configuration) {
+		for i := range cfg.Devices {
+			if cfg.Devices[i].DeviceID == dev {
+				cfg.Devices[i].Paused = paused
+			}
+		}
+	})
+}
+
+// closeRelayConnections closes the connections to the device that go over
+// a relay, if there are any.
+func (m *model) closeRelayConnections(dev protocol.DeviceID) {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	for _, connID := range m.deviceConnIDs[dev] {
+		conn := m.connections[connID]
+		if t := conn.Type(); t == "relay-client" || t == "relay-server" {
+			go conn.Close(errQuotaExceeded)
+		}
+	}
+}
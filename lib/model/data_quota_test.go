@@ -0,0 +1,57 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestDataQuotaPause(t *testing.T) {
+	w, _ := newDefaultCfgWrapper(t)
+	dev, _ := w.Device(device1)
+	dev.MonthlyQuotaMiB = 1
+	setDevice(t, w, dev)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	oct := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	m.accountUsage(oct, map[protocol.DeviceID]transferBytes{device1: {in: 512 << 10}})
+	if dev, _ := w.Device(device1); dev.Paused {
+		t.Fatal("device paused below its quota")
+	}
+
+	m.accountUsage(oct.Add(transferInterval), map[protocol.DeviceID]transferBytes{device1: {out: 512 << 10}})
+	waitForDevicePaused(t, w, true)
+
+	// A device resumed by the user stays resumed for the month.
+	pauseDevice(t, w, device1, false)
+	m.accountUsage(oct.Add(2*transferInterval), nil)
+	time.Sleep(100 * time.Millisecond)
+	if dev, _ := w.Device(device1); dev.Paused {
+		t.Fatal("device paused again in the same month")
+	}
+
+	// A device paused for its quota is resumed in the next month.
+	pauseDevice(t, w, device1, true)
+	m.accountUsage(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), nil)
+	waitForDevicePaused(t, w, false)
+}
+
+func waitForDevicePaused(t *testing.T, w config.Wrapper, paused bool) {
+	t.Helper()
+	for range 100 {
+		if dev, _ := w.Device(device1); dev.Paused == paused {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected device paused to be %v", paused)
+}
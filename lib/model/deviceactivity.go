@@ -8,32 +8,57 @@ package model
 
 import (
 	"sync"
+	"time"
 
 	"github.com/syncthing/syncthing/lib/protocol"
 )
 
-// deviceActivity tracks the number of outstanding requests per device and can
-// answer which device is least busy. It is safe for use from multiple
-// goroutines.
+// deviceActivity tracks the number of outstanding requests per device and
+// how fast each device has been answering them, and can answer which device
+// is expected to serve another request the soonest. It is safe for use from
+// multiple goroutines.
 type deviceActivity struct {
-	act map[protocol.DeviceID]int
-	mut sync.Mutex
+	act  map[protocol.DeviceID]int
+	cost map[protocol.DeviceID]float64 // moving average of nanoseconds per byte
+	mut  sync.Mutex
 }
 
+// The weight given to each new observation of how fast a device answered.
+const deviceCostWeight = 0.2
+
 func newDeviceActivity() *deviceActivity {
 	return &deviceActivity{
-		act: make(map[protocol.DeviceID]int),
+		act:  make(map[protocol.DeviceID]int),
+		cost: make(map[protocol.DeviceID]float64),
 	}
 }
 
-// Returns the index of the least busy device, or -1 if all are too busy.
+// Returns the index of the device expected to answer the soonest, given
+// the requests already outstanding to it and how fast it has answered
+// recently, or -1 if there is no device. Devices we have not yet measured
+// are assumed to be as fast as the fastest one we have, so that they get
+// tried.
 func (m *deviceActivity) leastBusy(availability []Availability) int {
 	m.mut.Lock()
-	low := 2<<30 - 1
+	fastest := 0.0
+	for i := range availability {
+		if c, ok := m.cost[availability[i].ID]; ok && (fastest == 0 || c < fastest) {
+			fastest = c
+		}
+	}
+	if fastest == 0 {
+		fastest = 1
+	}
+
+	low := 0.0
 	best := -1
 	for i := range availability {
-		if usage := m.act[availability[i].ID]; usage < low {
-			low = usage
+		c, ok := m.cost[availability[i].ID]
+		if !ok {
+			c = fastest
+		}
+		if score := float64(m.act[availability[i].ID]+1) * c; best == -1 || score < low {
+			low = score
 			best = i
 		}
 	}
@@ -41,6 +66,33 @@ func (m *deviceActivity) leastBusy(availability []Availability) int {
 	return best
 }
 
+// observe records how long a request for the given number of bytes took
+// to be answered by the device. A failed request makes the device look
+// twice as slow, so that requests move to other devices when one degrades,
+// also in the middle of a file.
+func (m *deviceActivity) observe(id protocol.DeviceID, bytes int, d time.Duration, err error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	prev, ok := m.cost[id]
+	if err != nil {
+		if ok {
+			m.cost[id] = 2 * prev
+		}
+		return
+	}
+	if bytes <= 0 {
+		return
+	}
+	c := float64(d.Nanoseconds()) / float64(bytes)
+	if c <= 0 {
+		c = 1e-3
+	}
+	if ok {
+		c = (1-deviceCostWeight)*prev + deviceCostWeight*c
+	}
+	m.cost[id] = c
+}
+
 func (m *deviceActivity) using(availability Availability) {
 	m.mut.Lock()
 	m.act[availability.ID]++
@@ -7,15 +7,17 @@
 package model
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/syncthing/syncthing/lib/protocol"
 )
 
 func TestDeviceActivity(t *testing.T) {
-	n0 := Availability{protocol.DeviceID([32]byte{1, 2, 3, 4}), false}
-	n1 := Availability{protocol.DeviceID([32]byte{5, 6, 7, 8}), true}
-	n2 := Availability{protocol.DeviceID([32]byte{9, 10, 11, 12}), false}
+	n0 := Availability{ID: protocol.DeviceID([32]byte{1, 2, 3, 4}), FromTemporary: false}
+	n1 := Availability{ID: protocol.DeviceID([32]byte{5, 6, 7, 8}), FromTemporary: true}
+	n2 := Availability{ID: protocol.DeviceID([32]byte{9, 10, 11, 12}), FromTemporary: false}
 	devices := []Availability{n0, n1, n2}
 	na := newDeviceActivity()
 
@@ -58,3 +60,32 @@ func TestDeviceActivity(t *testing.T) {
 		t.Errorf("Least busy device should be n0 (%v) not %v", n0, lb)
 	}
 }
+
+func TestDeviceActivityObserve(t *testing.T) {
+	n0 := Availability{ID: protocol.DeviceID([32]byte{1, 2, 3, 4}), FromTemporary: false}
+	n1 := Availability{ID: protocol.DeviceID([32]byte{5, 6, 7, 8}), FromTemporary: false}
+	devices := []Availability{n0, n1}
+	na := newDeviceActivity()
+
+	// n1 answers ten times as fast as n0, so it should be preferred even
+	// with a few requests outstanding.
+	na.observe(n0.ID, 128<<10, 100*time.Millisecond, nil)
+	na.observe(n1.ID, 128<<10, 10*time.Millisecond, nil)
+	for i := 0; i < 5; i++ {
+		if lb := na.leastBusy(devices); lb != 1 {
+			t.Fatalf("Fastest device should be n1 (%v) not %v", n1, lb)
+		}
+		na.using(n1)
+	}
+
+	// When n1 starts failing it should lose its preference.
+	for i := 0; i < 5; i++ {
+		na.done(n1)
+	}
+	for i := 0; i < 4; i++ {
+		na.observe(n1.ID, 128<<10, 0, errors.New("failed"))
+	}
+	if lb := na.leastBusy(devices); lb != 0 {
+		t.Errorf("Degraded device n1 should lose to n0 (%v), got %v", n0, lb)
+	}
+}
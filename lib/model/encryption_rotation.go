@@ -0,0 +1,182 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Rotating the encryption password of a folder shared with an untrusted
+// device works like this:
+//
+//   - On the trusted device the new password is set, keeping the current
+//     one as the previous password. What we know of the untrusted device's
+//     data is dropped, and from now on we send it everything encrypted with
+//     the new password, announcing both tokens in the cluster config.
+//   - The untrusted device accepts the new token as its previous one is
+//     announced alongside, and keeps accepting the previous token from
+//     other devices. It pulls the newly encrypted data like any other
+//     change.
+//   - Data still encrypted with the previous password is ignored in the
+//     index from the untrusted device, so its completion as seen by the
+//     trusted device is the progress of the rotation. Once complete, the
+//     previous password is removed from the config.
+//   - That makes the untrusted device see the new token without the
+//     previous one, at which point it stops accepting the previous token
+//     and removes the data no trusted device announces anymore.
+
+// EncryptionRotation is the progress of rotating the encryption password
+// for an untrusted device.
+type EncryptionRotation struct {
+	Rotating   bool
+	Completion FolderCompletion
+}
+
+// EncryptionRotation returns the progress of rotating the encryption
+// password of the folder for the given untrusted device.
+func (m *model) EncryptionRotation(folder string, device protocol.DeviceID) (EncryptionRotation, error) {
+	fcfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return EncryptionRotation{}, ErrFolderMissing
+	}
+	folderDevice, ok := fcfg.Device(device)
+	if !ok || folderDevice.EncryptionPassword == "" {
+		return EncryptionRotation{}, fmt.Errorf("folder %s is not shared encrypted with %s", fcfg.Description(), device.Short())
+	}
+	if !folderDevice.RotatingEncryption() {
+		return EncryptionRotation{}, nil
+	}
+	comp, err := m.folderCompletion(device, folder)
+	if err != nil {
+		return EncryptionRotation{}, err
+	}
+	return EncryptionRotation{Rotating: true, Completion: comp}, nil
+}
+
+// encryptionRotationsStarted returns the untrusted devices for which
+// rotating the encryption password starts with the config change.
+func encryptionRotationsStarted(from, to config.FolderConfiguration) []protocol.DeviceID {
+	var started []protocol.DeviceID
+	for _, dev := range to.Devices {
+		if !dev.RotatingEncryption() {
+			continue
+		}
+		if prev, ok := from.Device(dev.DeviceID); ok && prev.RotatingEncryption() && prev.EncryptionPassword == dev.EncryptionPassword {
+			continue
+		}
+		started = append(started, dev.DeviceID)
+	}
+	return started
+}
+
+// checkEncryptionRotationDone finishes rotating the encryption password
+// for the untrusted device once it has everything encrypted with the new
+// one.
+func (m *model) checkEncryptionRotationDone(folder string, device protocol.DeviceID) {
+	fcfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return
+	}
+	if folderDevice, ok := fcfg.Device(device); !ok || !folderDevice.RotatingEncryption() {
+		return
+	}
+	comp, err := m.folderCompletion(device, folder)
+	if err != nil || comp.RemoteState != remoteFolderValid || comp.NeedItems > 0 || comp.NeedDeletes > 0 {
+		return
+	}
+
+	slog.Info("Finished rotating encryption password", fcfg.LogAttr(), device.LogAttr())
+	// Modifying the config waits for us to commit it, which must not
+	// happen on the connection's receiving routine.
+	go m.cfg.Modify(func(cfg *config.Configuration) {
+		fcfg, i, ok := cfg.Folder(folder)
+		if !ok {
+			return
+		}
+		for j := range fcfg.Devices {
+			if fcfg.Devices[j].DeviceID == device {
+				fcfg.Devices[j].PreviousEncryptionPassword = ""
+			}
+		}
+		cfg.Folders[i] = fcfg
+	})
+}
+
+// ccCheckEncryptionRotation decides whether a token different from ours is
+// acceptable for a receive-encrypted folder, which is the case if the
+// password is being rotated, and starts the rotation on our end if
+// necessary.
+func (m *model) ccCheckEncryptionRotation(fcfg config.FolderConfiguration, remote protocol.DeviceID, token []byte, ccDevice protocol.Device) error {
+	m.mut.RLock()
+	previous := m.folderPreviousPasswordTokens[fcfg.ID]
+	m.mut.RUnlock()
+
+	if len(previous) > 0 && bytes.Equal(previous, ccDevice.EncryptionPasswordToken) {
+		// A device that hasn't started rotating yet.
+		return nil
+	}
+	if len(ccDevice.PreviousEncryptionPasswordToken) == 0 || !bytes.Equal(token, ccDevice.PreviousEncryptionPasswordToken) {
+		return errEncryptionPassword
+	}
+
+	stored := storedEncryptionToken{
+		FolderID:      fcfg.ID,
+		Token:         ccDevice.EncryptionPasswordToken,
+		PreviousToken: token,
+	}
+	if err := writeStoredEncryptionToken(stored, fcfg); err != nil {
+		if rerr, ok := redactPathError(err); ok {
+			return rerr
+		}
+		return &redactedError{
+			error:    err,
+			redacted: errEncryptionTokenWrite,
+		}
+	}
+	m.mut.Lock()
+	m.folderEncryptionPasswordTokens[fcfg.ID] = stored.Token
+	m.folderPreviousPasswordTokens[fcfg.ID] = stored.PreviousToken
+	m.mut.Unlock()
+	slog.Info("Rotating encryption password", fcfg.LogAttr(), remote.LogAttr())
+
+	// Announce the new token.
+	m.sendClusterConfig(fcfg.DeviceIDs())
+	return nil
+}
+
+// finishEncryptionRotation stops accepting the previous token for a
+// receive-encrypted folder, if we were rotating, and removes the data
+// encrypted with the previous password.
+func (m *model) finishEncryptionRotation(fcfg config.FolderConfiguration) {
+	m.mut.RLock()
+	_, rotating := m.folderPreviousPasswordTokens[fcfg.ID]
+	token := m.folderEncryptionPasswordTokens[fcfg.ID]
+	runner, _ := m.folderRunners.Get(fcfg.ID)
+	m.mut.RUnlock()
+	if !rotating {
+		return
+	}
+
+	if err := writeEncryptionToken(token, fcfg); err != nil {
+		slog.Warn("Failed to finish rotating encryption password", fcfg.LogAttr(), slogutil.Error(err))
+		return
+	}
+	m.mut.Lock()
+	delete(m.folderPreviousPasswordTokens, fcfg.ID)
+	m.mut.Unlock()
+	slog.Info("Finished rotating encryption password", fcfg.LogAttr())
+
+	if rf, ok := runner.(*receiveEncryptedFolder); ok {
+		go rf.RemoveUnannounced()
+	}
+}
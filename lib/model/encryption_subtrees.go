@@ -0,0 +1,70 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Subtrees of a folder shared with an untrusted device may be encrypted
+// with a password of their own, or not at all. When they change, the
+// affected files are sent with their new names, which requires sending the
+// untrusted device our full index once. What we know of its data is dropped
+// at the same time, so that we see it catching up. Data it still has under
+// the old names is ignored.
+
+// encryptionSubtreesChanged returns the untrusted devices for which the
+// subtrees change with the config change.
+func encryptionSubtreesChanged(from, to config.FolderConfiguration) []protocol.DeviceID {
+	var changed []protocol.DeviceID
+	for _, dev := range to.Devices {
+		if dev.EncryptionPassword == "" {
+			continue
+		}
+		prev, ok := from.Device(dev.DeviceID)
+		if !ok || prev.EncryptionPassword == "" || slices.Equal(prev.EncryptionSubtrees, dev.EncryptionSubtrees) {
+			continue
+		}
+		changed = append(changed, dev.DeviceID)
+	}
+	return changed
+}
+
+func (m *model) fullIndexPending() *db.Typed {
+	return db.NewTyped(m.sdb, "fullindex/")
+}
+
+func fullIndexKey(folder string, device protocol.DeviceID) string {
+	return device.String() + "/" + folder
+}
+
+// setFullIndexPending makes us send the device our full index for the
+// folder the next time it connects.
+func (m *model) setFullIndexPending(folder string, device protocol.DeviceID) error {
+	return m.fullIndexPending().PutBool(fullIndexKey(folder, device), true)
+}
+
+// takeFullIndexPending returns whether we need to send the device our full
+// index for the folder, and forgets about it.
+func (m *model) takeFullIndexPending(folder string, device protocol.DeviceID) bool {
+	kv := m.fullIndexPending()
+	key := fullIndexKey(folder, device)
+	pending, _, err := kv.Bool(key)
+	if err != nil || !pending {
+		return false
+	}
+	if err := kv.Delete(key); err != nil {
+		slog.Warn("Failed to clear pending full index", slog.String("folder", folder), device.LogAttr(), slogutil.Error(err))
+	}
+	return true
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/stats"
+)
+
+// The files added, changed and deleted in each folder, whether by us or
+// pulled from others, are counted per day for the device that made the
+// change, so that it can be found out afterwards who did what. Files only
+// record the short ID of the device that changed them, so that's what the
+// activity is kept by, including that of devices we don't know (anymore).
+
+const fileActivityPrefix = "fileactivity/" // + short ID in hex
+
+type fileActivity struct {
+	kv   db.KV
+	mut  sync.Mutex
+	refs map[protocol.ShortID]*stats.DeviceStatisticsReference
+}
+
+func newFileActivity(kv db.KV) *fileActivity {
+	return &fileActivity{
+		kv:   kv,
+		refs: make(map[protocol.ShortID]*stats.DeviceStatisticsReference),
+	}
+}
+
+func (a *fileActivity) ref(short protocol.ShortID) *stats.DeviceStatisticsReference {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	ref, ok := a.refs[short]
+	if !ok {
+		ref = stats.NewDeviceStatisticsReference(db.NewTyped(a.kv, fmt.Sprintf("%s%016x", fileActivityPrefix, uint64(short))))
+		a.refs[short] = ref
+	}
+	return ref
+}
+
+// record adds to the activity of each device for the day.
+func (a *fileActivity) record(activity map[protocol.ShortID]stats.Activity) {
+	now := time.Now()
+	for short, act := range activity {
+		if err := a.ref(short).AddActivity(now, act); err != nil {
+			slog.Warn("Failed to record device activity", slogutil.Error(err))
+		}
+	}
+}
+
+// all returns the activity of each device that had any.
+func (a *fileActivity) all() (map[protocol.ShortID][]stats.DailyActivity, error) {
+	res := make(map[protocol.ShortID][]stats.DailyActivity)
+	for kv, err := range itererr.Zip(a.kv.PrefixKV(fileActivityPrefix)) {
+		if err != nil {
+			return nil, err
+		}
+		hex, _, _ := strings.Cut(strings.TrimPrefix(kv.Key, fileActivityPrefix), "/")
+		short, err := strconv.ParseUint(hex, 16, 64)
+		if err != nil {
+			continue
+		}
+		if _, ok := res[protocol.ShortID(short)]; ok {
+			continue
+		}
+		days, err := a.ref(protocol.ShortID(short)).GetActivity()
+		if err != nil {
+			return nil, err
+		}
+		res[protocol.ShortID(short)] = days
+	}
+	return res, nil
+}
+
+// activity counts the files in the update as added, changed or deleted,
+// going by what we have before applying it, for the devices that made the
+// changes.
+func (f *folder) activity(files []protocol.FileInfo) map[protocol.ShortID]stats.Activity {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		if !file.IsDirectory() && !file.IsInvalid() && file.ModifiedBy != 0 {
+			names = append(names, file.Name)
+		}
+	}
+	had := make(map[string]bool, len(names))
+	for cur, err := range itererr.Zip(f.db.AllLocalFilesNamed(f.folderID, protocol.LocalDeviceID, names)) {
+		if err != nil {
+			return nil
+		}
+		had[cur.Name] = !cur.Deleted && !cur.LocalFlags.IsInvalid()
+	}
+
+	res := make(map[protocol.ShortID]stats.Activity)
+	for _, file := range files {
+		if file.IsDirectory() || file.IsInvalid() || file.ModifiedBy == 0 {
+			continue
+		}
+		a := res[file.ModifiedBy]
+		switch {
+		case file.IsDeleted():
+			if !had[file.Name] {
+				continue
+			}
+			a.Deleted++
+		case had[file.Name]:
+			a.Changed++
+		default:
+			a.Added++
+		}
+		res[file.ModifiedBy] = a
+	}
+	return res
+}
+
+// FileActivity returns the files each device added, changed and deleted
+// per day, by device ID, or by short device ID for devices that aren't
+// configured (anymore).
+func (m *model) FileActivity() (map[string][]stats.DailyActivity, error) {
+	activity, err := m.fileActivity.all()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mut.RLock()
+	devices := make(map[protocol.ShortID]protocol.DeviceID, len(m.deviceStatRefs))
+	for id := range m.deviceStatRefs {
+		devices[id.Short()] = id
+	}
+	m.mut.RUnlock()
+
+	res := make(map[string][]stats.DailyActivity, len(activity))
+	for short, days := range activity {
+		if id, ok := devices[short]; ok {
+			res[id.String()] = days
+		} else {
+			res[short.String()] = days
+		}
+	}
+	return res, nil
+}
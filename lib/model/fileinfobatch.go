@@ -24,6 +24,10 @@ type FileInfoBatch struct {
 	size    int
 	flushFn func([]protocol.FileInfo) error
 	error   error
+
+	budget   *memoryBudget
+	taken    int
+	exceeded bool
 }
 
 // NewFileInfoBatch returns a new FileInfoBatch that calls fn when it's time
@@ -39,6 +43,13 @@ func (b *FileInfoBatch) SetFlushFunc(fn func([]protocol.FileInfo) error) {
 	b.flushFn = fn
 }
 
+// SetMemoryBudget makes the batch take the memory for the files it holds
+// from the budget. When that memory isn't available, the batch is full
+// early.
+func (b *FileInfoBatch) SetMemoryBudget(budget *memoryBudget) {
+	b.budget = budget
+}
+
 func (b *FileInfoBatch) Append(f protocol.FileInfo) {
 	if b.error != nil {
 		panic("bug: calling append on a failed batch")
@@ -47,11 +58,19 @@ func (b *FileInfoBatch) Append(f protocol.FileInfo) {
 		b.infos = make([]protocol.FileInfo, 0, MaxBatchSizeFiles)
 	}
 	b.infos = append(b.infos, f)
-	b.size += proto.Size(f.ToWire(true))
+	size := proto.Size(f.ToWire(true))
+	b.size += size
+	if b.budget != nil {
+		if b.budget.TryTake(size) {
+			b.taken += size
+		} else {
+			b.exceeded = true
+		}
+	}
 }
 
 func (b *FileInfoBatch) Full() bool {
-	return len(b.infos) >= MaxBatchSizeFiles || b.size >= MaxBatchSizeBytes
+	return len(b.infos) >= MaxBatchSizeFiles || b.size >= MaxBatchSizeBytes || b.exceeded
 }
 
 func (b *FileInfoBatch) FlushIfFull() error {
@@ -73,6 +92,7 @@ func (b *FileInfoBatch) Flush() error {
 	}
 	if err := b.flushFn(b.infos); err != nil {
 		b.error = err
+		b.releaseBudget()
 		return err
 	}
 	b.Reset()
@@ -83,6 +103,15 @@ func (b *FileInfoBatch) Reset() {
 	b.infos = nil
 	b.error = nil
 	b.size = 0
+	b.releaseBudget()
+}
+
+func (b *FileInfoBatch) releaseBudget() {
+	if b.budget != nil {
+		b.budget.Give(b.taken)
+	}
+	b.taken = 0
+	b.exceeded = false
 }
 
 func (b *FileInfoBatch) Size() int {
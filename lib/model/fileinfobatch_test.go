@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/semaphore"
 )
 
 func TestFileInfoBatchError(t *testing.T) {
@@ -62,3 +63,33 @@ func TestFileInfoBatchError(t *testing.T) {
 		t.Fatalf("expected 3, got %d", called)
 	}
 }
+
+func TestFileInfoBatchMemoryBudget(t *testing.T) {
+	// A batch is full as soon as the memory budget doesn't cover it, and
+	// gives the memory back when flushed.
+
+	folder := semaphore.New(100)
+	b := NewFileInfoBatch(func([]protocol.FileInfo) error { return nil })
+	b.SetMemoryBudget(newMemoryBudget("budgettest", memoryUseIndex, folder, nil))
+
+	b.Append(protocol.FileInfo{Name: "a"})
+	if b.Full() {
+		t.Fatal("batch should not be full yet")
+	}
+	for !b.Full() {
+		b.Append(protocol.FileInfo{Name: "a"})
+	}
+	if len(b.infos) >= MaxBatchSizeFiles || b.Size() >= MaxBatchSizeBytes {
+		t.Errorf("batch should be full due to the budget, not its size")
+	}
+	if folder.Available() == 100 {
+		t.Error("batch should have taken from the budget")
+	}
+
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if folder.Available() != 100 {
+		t.Errorf("flush should give back the budget, %d available", folder.Available())
+	}
+}
@@ -18,9 +18,12 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/internal/itererr"
 	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/internal/tracing"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/events"
 	"github.com/syncthing/syncthing/lib/fs"
@@ -45,7 +48,13 @@ type folder struct {
 	config.FolderConfiguration
 	*stats.FolderStatisticsReference
 
-	ioLimiter *semaphore.Semaphore
+	ioLimiter   *priorityLimiter
+	hashLimiter *priorityLimiter
+
+	// Memory budgets, taken from the folder's own and the global one.
+	memPull  *memoryBudget
+	memHash  *memoryBudget
+	memIndex *memoryBudget
 
 	localFlags protocol.FlagLocal
 
@@ -65,15 +74,19 @@ type folder struct {
 	scanScheduled          chan struct{}
 	versionCleanupInterval time.Duration
 	versionCleanupTimer    *time.Timer
+	conflictCleanupTimer   *time.Timer
 
 	pullScheduled chan struct{}
-	pullPause     time.Duration
+	pullFailures  int
 	pullFailTimer *time.Timer
 
 	scanErrors []FileError
 	pullErrors []FileError
 	errorsMut  sync.Mutex
 
+	scanSkipped    scanner.SkipCounts // as of the last full scan
+	scanSkippedMut sync.Mutex
+
 	doInSyncChan chan syncRequest
 
 	forcedRescanRequested chan struct{}
@@ -86,8 +99,17 @@ type folder struct {
 	watchErr         error
 	watchMut         sync.Mutex
 
+	// Scans that were due while scans were suspended, done once resumed.
+	// Only accessed from the serve routine.
+	scansResumedChan  chan struct{}
+	suspendedFullScan bool
+	suspendedSubs     []string
+
 	puller    puller
 	versioner versioner.Versioner
+	seed      *seedIndex // nil if the folder has no seed path
+
+	blockSizeProfiles *scanner.BlockSizeProfiles // nil unless AdaptiveBlockSize
 
 	warnedKqueue bool
 }
@@ -101,12 +123,13 @@ type puller interface {
 	pull(ctx context.Context) (bool, error) // true when successful and should not be retried
 }
 
-func newFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, evLogger events.Logger, ioLimiter *semaphore.Semaphore, ver versioner.Versioner) *folder {
+func newFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, evLogger events.Logger, sched *scheduler, ver versioner.Versioner) *folder {
 	f := folder{
 		stateTracker:              newStateTracker(cfg.ID, evLogger),
 		FolderConfiguration:       cfg,
 		FolderStatisticsReference: stats.NewFolderStatisticsReference(db.NewTyped(model.sdb, "folderstats/"+cfg.ID)),
-		ioLimiter:                 ioLimiter,
+		ioLimiter:                 sched.ioLimiter(cfg.Priority),
+		hashLimiter:               sched.hashLimiter(cfg.Priority),
 
 		model:         model,
 		shortID:       model.shortID,
@@ -124,6 +147,7 @@ func newFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfigura
 		scanScheduled:          make(chan struct{}, 1),
 		versionCleanupInterval: time.Duration(cfg.Versioning.CleanupIntervalS) * time.Second,
 		versionCleanupTimer:    time.NewTimer(time.Duration(cfg.Versioning.CleanupIntervalS) * time.Second),
+		conflictCleanupTimer:   time.NewTimer(conflictCleanupInterval),
 
 		pullScheduled: make(chan struct{}, 1), // This needs to be 1-buffered so that we queue a pull if we're busy when it comes.
 
@@ -135,12 +159,25 @@ func newFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfigura
 		watchCancel:      func() {},
 		restartWatchChan: make(chan struct{}, 1),
 
+		scansResumedChan: make(chan struct{}, 1),
+
 		versioner: ver,
 	}
-	f.pullPause = f.pullBasePause()
+	if cfg.WatcherOnly() {
+		// There are no periodic rescans after the initial scan.
+		f.scanInterval = 0
+	}
+	if cfg.AdaptiveBlockSize {
+		f.blockSizeProfiles = scanner.NewBlockSizeProfiles()
+	}
 	f.pullFailTimer = time.NewTimer(0)
 	<-f.pullFailTimer.C
 
+	folderMem := semaphore.New(cfg.MemoryBudgetMiB << 20)
+	f.memPull = newMemoryBudget(cfg.ID, memoryUsePull, folderMem, model.memoryLimiter)
+	f.memHash = newMemoryBudget(cfg.ID, memoryUseHash, folderMem, model.memoryLimiter)
+	f.memIndex = newMemoryBudget(cfg.ID, memoryUseIndex, folderMem, model.memoryLimiter)
+
 	registerFolderMetrics(f.ID)
 
 	return &f
@@ -155,14 +192,23 @@ func (f *folder) Serve(ctx context.Context) error {
 
 	defer func() {
 		f.scanTimer.Stop()
+		f.model.scanSchedule.forget(f.ID)
 		f.versionCleanupTimer.Stop()
+		f.conflictCleanupTimer.Stop()
 		f.setState(FolderIdle)
 	}()
 
+	// The scan timer starts out firing right away, for the initial scan.
+	f.model.scanSchedule.set(f.ID, time.Now(), f.scanInterval)
+
 	if f.FSWatcherEnabled && f.getHealthErrorAndLoadIgnores() == nil {
 		f.startWatch(ctx)
 	}
 
+	if f.seed != nil {
+		go f.seed.serve(ctx)
+	}
+
 	// If we're configured to not do version cleanup, or we don't have a
 	// versioner, cancel and drain that timer now.
 	if f.versionCleanupInterval == 0 || f.versioner == nil {
@@ -171,6 +217,12 @@ func (f *folder) Serve(ctx context.Context) error {
 		}
 	}
 
+	if !f.conflictCleanupEnabled() {
+		if !f.conflictCleanupTimer.Stop() {
+			<-f.conflictCleanupTimer.C
+		}
+	}
+
 	initialCompleted := f.initialScanFinished
 	pullTimer := time.NewTimer(0)
 	pullTimer.Stop()
@@ -204,9 +256,9 @@ func (f *folder) Serve(ctx context.Context) error {
 		case <-f.pullFailTimer.C:
 			var success bool
 			success, err = f.pull(ctx)
-			if (err != nil || !success) && f.pullPause < 60*f.pullBasePause() {
+			if err != nil || !success {
 				// Back off from retrying to pull
-				f.pullPause *= 2
+				f.pullFailures++
 			}
 
 		case <-initialCompleted:
@@ -218,6 +270,11 @@ func (f *folder) Serve(ctx context.Context) error {
 			err = f.handleForcedRescans(ctx)
 
 		case <-f.scanTimer.C:
+			if f.model.scansPaused() {
+				f.sl.DebugContext(ctx, "Scan timer fired while scans are suspended")
+				f.suspendedFullScan = true
+				break
+			}
 			f.sl.DebugContext(ctx, "Scanning due to timer")
 			err = f.scanTimerFired(ctx)
 
@@ -228,16 +285,27 @@ func (f *folder) Serve(ctx context.Context) error {
 
 		case next := <-f.scanDelay:
 			f.sl.DebugContext(ctx, "Delaying scan")
-			f.scanTimer.Reset(next)
+			f.setScanTimer(next)
 
 		case <-f.scanScheduled:
 			f.sl.DebugContext(ctx, "Scan was scheduled")
-			f.scanTimer.Reset(0)
+			f.setScanTimer(0)
 
 		case fsEvents := <-f.watchChan:
-			f.sl.DebugContext(ctx, "Scan due to watcher")
+			if f.model.scansPaused() {
+				f.suspendedSubs = append(f.suspendedSubs, fsEvents...)
+				break
+			}
+			if f.WatcherOnly() && slices.Contains(fsEvents, ".") {
+				f.sl.InfoContext(ctx, "Running consistency check, as the watcher may have missed changes")
+			} else {
+				f.sl.DebugContext(ctx, "Scan due to watcher")
+			}
 			err = f.scanSubdirs(ctx, fsEvents)
 
+		case <-f.scansResumedChan:
+			err = f.resumeScans(ctx)
+
 		case <-f.restartWatchChan:
 			f.sl.DebugContext(ctx, "Restart watcher")
 			err = f.restartWatch(ctx)
@@ -245,6 +313,10 @@ func (f *folder) Serve(ctx context.Context) error {
 		case <-f.versionCleanupTimer.C:
 			f.sl.DebugContext(ctx, "Doing version cleanup")
 			f.versionCleanupTimerFired(ctx)
+
+		case <-f.conflictCleanupTimer.C:
+			f.sl.DebugContext(ctx, "Doing conflict cleanup")
+			err = f.conflictCleanupTimerFired(ctx)
 		}
 
 		if err != nil {
@@ -305,6 +377,49 @@ func (f *folder) Scan(subdirs []string) error {
 	})
 }
 
+// RescanFile scans the file at the given path alone and returns it as it
+// is now in the local index, from where it is sent to the other devices
+// right away. If the directory it is in isn't in the index yet, that is
+// scanned in full instead. Directories aren't rescanned this way, as that
+// would mean scanning everything below them.
+func (f *folder) RescanFile(name string) (protocol.FileInfo, error) {
+	<-f.initialScanFinished
+	var file protocol.FileInfo
+	err := f.doInSync(func(ctx context.Context) error {
+		var err error
+		file, err = f.rescanFile(ctx, osutil.NativeFilename(name))
+		return err
+	})
+	return file, err
+}
+
+func (f *folder) rescanFile(ctx context.Context, name string) (protocol.FileInfo, error) {
+	if info, err := f.mtimefs.Lstat(name); err == nil && info.IsDir() {
+		return protocol.FileInfo{}, fmt.Errorf("%s: is a directory", name)
+	}
+	if err := f.scanSubdirs(ctx, []string{name}); err != nil {
+		return protocol.FileInfo{}, err
+	}
+
+	f.errorsMut.Lock()
+	for _, fe := range f.scanErrors {
+		if fe.Path == name {
+			f.errorsMut.Unlock()
+			return protocol.FileInfo{}, fmt.Errorf("%s: %s", name, fe.Err)
+		}
+	}
+	f.errorsMut.Unlock()
+
+	file, ok, err := f.db.GetDeviceFile(f.folderID, protocol.LocalDeviceID, name)
+	if err != nil {
+		return protocol.FileInfo{}, err
+	}
+	if !ok {
+		return protocol.FileInfo{}, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+	return file, nil
+}
+
 // doInSync allows to run functions synchronously in folder.serve from exported,
 // asynchronously called methods.
 func (f *folder) doInSync(fn func(context.Context) error) error {
@@ -325,9 +440,12 @@ func (f *folder) Reschedule() {
 	if f.scanInterval == 0 {
 		return
 	}
-	// Sleep a random time between 3/4 and 5/4 of the configured interval.
+	// Sleep a random time between 3/4 and 5/4 of the configured interval,
+	// and then some to stay clear of the scans of other folders.
 	sleepNanos := (f.scanInterval.Nanoseconds()*3 + rand.Int63n(2*f.scanInterval.Nanoseconds())) / 4 //nolint:gosec
-	interval := time.Duration(sleepNanos) * time.Nanosecond
+	now := time.Now()
+	at := f.model.scanSchedule.stagger(f.ID, now.Add(time.Duration(sleepNanos)), f.scanInterval)
+	interval := at.Sub(now)
 	f.sl.Debug("Next rescan scheduled", slog.Duration("interval", interval))
 	f.scanTimer.Reset(interval)
 }
@@ -381,7 +499,7 @@ func (f *folder) pull(ctx context.Context) (success bool, err error) {
 	defer func() {
 		if success {
 			// We're good, reset the pause interval.
-			f.pullPause = f.pullBasePause()
+			f.pullFailures = 0
 		}
 	}()
 
@@ -398,6 +516,11 @@ func (f *folder) pull(ctx context.Context) (success bool, err error) {
 		return true, nil
 	}
 
+	if f.model.remoteChangesPending(f.ID) {
+		f.sl.DebugContext(ctx, "Skipping pull as remote changes are waiting for approval")
+		return false, errApprovalPending
+	}
+
 	// Abort early (before acquiring a token) if there's a folder error
 	err = f.getHealthErrorWithoutIgnores()
 	if err != nil {
@@ -439,7 +562,10 @@ func (f *folder) pull(ctx context.Context) (success bool, err error) {
 	}
 
 	// Pulling failed, try again later.
-	delay := f.pullPause + time.Since(startTime)
+	f.errorsMut.Lock()
+	delay := pullRetryDelay(f.pullErrors, f.pullBasePause(), f.pullFailures)
+	f.errorsMut.Unlock()
+	delay += time.Since(startTime)
 	f.sl.InfoContext(ctx, "Folder failed to sync, will be retried", slog.String("wait", stringutil.NiceDurationString(delay)))
 	f.pullFailTimer.Reset(delay)
 
@@ -447,6 +573,9 @@ func (f *folder) pull(ctx context.Context) (success bool, err error) {
 }
 
 func (f *folder) scanSubdirs(ctx context.Context, subDirs []string) error {
+	ctx, span := tracing.Start(ctx, "scan", tracing.Folder(f.ID), attribute.StringSlice("syncthing.subdirs", subDirs))
+	defer span.End()
+
 	f.sl.DebugContext(ctx, "Scanning")
 
 	oldHash := f.ignores.Hash()
@@ -477,6 +606,9 @@ func (f *folder) scanSubdirs(ctx context.Context, subDirs []string) error {
 	defer f.ioLimiter.Give(1)
 
 	metricFolderScans.WithLabelValues(f.ID).Inc()
+	defer func(start time.Time) {
+		metricFolderScanDurationSeconds.WithLabelValues(f.ID).Observe(time.Since(start).Seconds())
+	}(time.Now())
 	scanCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	go addTimeUntilCancelled(scanCtx, metricFolderScanSeconds.WithLabelValues(f.ID))
@@ -506,6 +638,9 @@ func (f *folder) scanSubdirs(ctx context.Context, subDirs []string) error {
 	f.clearScanErrors(subDirs)
 
 	batch := f.newScanBatch()
+	// Returns what's still taken from the memory budget when the scan
+	// is cut short.
+	defer batch.updateBatch.Reset()
 
 	// Schedule a pull after scanning, but only if we actually detected any
 	// changes.
@@ -517,6 +652,15 @@ func (f *folder) scanSubdirs(ctx context.Context, subDirs []string) error {
 		}
 	}()
 
+	fullScan := len(subDirs) == 0
+	if fullScan {
+		changesHere, err := f.scanRecentlyChanged(ctx, batch)
+		changes += changesHere
+		if err != nil {
+			return err
+		}
+	}
+
 	changesHere, err := f.scanSubdirsChangedAndNew(ctx, subDirs, batch)
 	changes += changesHere
 	if err != nil {
@@ -546,6 +690,11 @@ func (f *folder) scanSubdirs(ctx context.Context, subDirs []string) error {
 		return err
 	}
 
+	if fullScan && f.seed != nil {
+		// Whatever changed in the folder may have changed in the seed too.
+		f.seed.invalidate()
+	}
+
 	f.ScanCompleted()
 	return nil
 }
@@ -571,6 +720,7 @@ func (f *folder) newScanBatch() *scanBatch {
 		b.f.updateLocalsFromScanning(fs)
 		return nil
 	})
+	b.updateBatch.SetMemoryBudget(f.memIndex)
 	return b
 }
 
@@ -631,12 +781,14 @@ func (b *scanBatch) Update(fi protocol.FileInfo) (bool, error) {
 		}
 	case (b.f.Type == config.FolderTypeReceiveOnly || b.f.Type == config.FolderTypeReceiveEncrypted) &&
 		gf.IsEquivalentOptional(fi, protocol.FileInfoComparison{
-			ModTimeWindow:   b.f.modTimeWindow,
-			IgnorePerms:     b.f.IgnorePerms,
-			IgnoreBlocks:    true,
-			IgnoreFlags:     protocol.FlagLocalReceiveOnly,
-			IgnoreOwnership: !b.f.SyncOwnership && !b.f.SendOwnership,
-			IgnoreXattrs:    !b.f.SyncXattrs && !b.f.SendXattrs,
+			ModTimeWindow:          b.f.modTimeWindow,
+			IgnorePerms:            b.f.IgnorePerms,
+			IgnoreBlocks:           true,
+			IgnoreFlags:            protocol.FlagLocalReceiveOnly,
+			IgnoreOwnership:        !b.f.SyncOwnership && !b.f.SendOwnership,
+			IgnoreXattrs:           !b.f.SyncXattrs && !b.f.SendXattrs,
+			IgnorePosixACLs:        !b.f.SyncPosixACLs && !b.f.SendPosixACLs,
+			IgnoreAlternateStreams: !b.f.SyncAlternateStreams && !b.f.SendAlternateStreams,
 		}):
 		// What we have locally is equivalent to the global file.
 		b.f.sl.Debug("Merging identical locally changed item with global", slogutil.FilePath(fi.Name))
@@ -672,6 +824,14 @@ func (f *folder) scanSubdirsChangedAndNew(ctx context.Context, subDirs []string,
 		ScanOwnership:         f.SendOwnership || f.SyncOwnership,
 		ScanXattrs:            f.SendXattrs || f.SyncXattrs,
 		XattrFilter:           f.XattrFilter,
+		ScanPosixACLs:         f.SendPosixACLs || f.SyncPosixACLs,
+		ScanAlternateStreams:  f.SendAlternateStreams || f.SyncAlternateStreams,
+		AlternateStreamFilter: f.AlternateStreamFilter,
+		HashLimiter:           f.hashLimiter,
+		MemoryLimiter:         f.memHash,
+		OwnershipMapper:       f.OwnershipMapping,
+		BlockSizeProfiles:     f.blockSizeProfiles,
+		Skipped:               new(scanner.SkipCounts),
 	}
 	var fchan chan scanner.ScanResult
 	if f.Type == config.FolderTypeReceiveEncrypted {
@@ -696,6 +856,17 @@ func (f *folder) scanSubdirsChangedAndNew(ctx context.Context, subDirs []string,
 			return changes, err
 		}
 
+		if len(subDirs) == 0 {
+			changesHere, err := f.scanPendingWatchEvents(ctx, batch)
+			changes += changesHere
+			if err != nil {
+				scanCancel()
+				for range fchan {
+				}
+				return changes, err
+			}
+		}
+
 		if ok, err := batch.Update(res.File); err != nil {
 			return 0, err
 		} else if ok {
@@ -715,6 +886,14 @@ func (f *folder) scanSubdirsChangedAndNew(ctx context.Context, subDirs []string,
 		}
 	}
 
+	// Only full scans give a picture of what the ignore patterns do to the
+	// folder as a whole.
+	if len(subDirs) == 0 && ctx.Err() == nil {
+		f.scanSkippedMut.Lock()
+		f.scanSkipped = *scanConfig.Skipped
+		f.scanSkippedMut.Unlock()
+	}
+
 	return changes, nil
 }
 
@@ -792,7 +971,7 @@ outer:
 				// it's still here. Simply stat:ing it won't do as there are
 				// tons of corner cases (e.g. parent dir->symlink, missing
 				// permissions)
-				if !osutil.IsDeleted(f.mtimefs, fi.Name) {
+				if !osutil.IsDeleted(f.mtimefs, fi.Name) || f.isArchived(fi.Name) {
 					if ignoredParent != "" {
 						// Don't ignore parents of this not ignored item
 						toIgnore = toIgnore[:0]
@@ -979,6 +1158,13 @@ func (f *folder) versionCleanupTimerFired(ctx context.Context) {
 	f.versionCleanupTimer.Reset(f.versionCleanupInterval)
 }
 
+// ScanSkipped returns the numbers of items the last full scan passed over.
+func (f *folder) ScanSkipped() scanner.SkipCounts {
+	f.scanSkippedMut.Lock()
+	defer f.scanSkippedMut.Unlock()
+	return f.scanSkipped
+}
+
 func (f *folder) WatchError() error {
 	f.watchMut.Lock()
 	defer f.watchMut.Unlock()
@@ -1035,6 +1221,12 @@ func (f *folder) monitorWatch(ctx context.Context) {
 	warnedOutside := false
 	var lastWatch time.Time
 	pause := time.Minute
+	// Watching failed on the system limit this many times in a row.
+	limitErrors := 0
+	// Unwatched subtrees are rescanned by themselves in watcher only mode.
+	var unwatched []string
+	var unwatchedTicker *time.Ticker
+	var unwatchedC <-chan time.Time
 	// Subscribe to folder summaries only on kqueue systems, to warn about potential high resource usage
 	var summarySub events.Subscription
 	var summaryChan <-chan events.Event
@@ -1047,22 +1239,53 @@ func (f *folder) monitorWatch(ctx context.Context) {
 		if summaryChan != nil {
 			summarySub.Unsubscribe()
 		}
+		if unwatchedTicker != nil {
+			unwatchedTicker.Stop()
+		}
+		f.model.watchUsages.remove(f.folderID)
 	}()
 	for {
 		select {
 		case <-failTimer.C:
-			eventChan, errChan, err = f.mtimefs.Watch(".", f.ignores, ctx, f.IgnorePerms)
+			var matcher fs.Matcher = f.ignores
+			var usage WatchUsage
+			if budget := f.watchBudget(limitErrors); budget > 0 {
+				if m, u, err := f.planWatch(budget); err != nil {
+					f.sl.WarnContext(ctx, "Failed to plan filesystem watches", slogutil.Error(err))
+				} else {
+					matcher, usage = m, u
+				}
+			}
+			eventChan, errChan, err = f.mtimefs.Watch(".", matcher, ctx, f.IgnorePerms)
 			// We do this once per minute initially increased to
 			// max one hour in case of repeat failures.
-			f.scanOnWatchErr()
+			f.scanOnWatchErr(err)
 			f.setWatchError(err, pause)
 			if err != nil {
+				f.model.watchUsages.remove(f.folderID)
+				if errors.Is(err, fs.ErrWatchLimit) {
+					limitErrors++
+				}
 				failTimer.Reset(pause)
 				if pause < 60*time.Minute {
 					pause *= 2
 				}
 				continue
 			}
+			limitErrors = 0
+			f.model.watchUsages.set(f.folderID, usage)
+			if len(usage.Unwatched) > 0 {
+				f.sl.InfoContext(ctx, "Leaving subtrees unwatched to stay within the watch budget", slog.Int("budget", usage.Budget), slog.Int("unwatched", len(usage.Unwatched)))
+			}
+			unwatched = usage.Unwatched
+			if unwatchedTicker != nil {
+				unwatchedTicker.Stop()
+				unwatchedTicker, unwatchedC = nil, nil
+			}
+			if len(unwatched) > 0 && f.WatcherOnly() && f.RescanIntervalS > 0 {
+				unwatchedTicker = time.NewTicker(time.Duration(f.RescanIntervalS) * time.Second)
+				unwatchedC = unwatchedTicker.C
+			}
 			lastWatch = time.Now()
 			watchaggregator.Aggregate(aggrCtx, eventChan, f.watchChan, f.FolderConfiguration, f.model.cfg, f.evLogger)
 			f.sl.DebugContext(ctx, "Started filesystem watcher")
@@ -1092,6 +1315,13 @@ func (f *folder) monitorWatch(ctx context.Context) {
 			aggrCancel()
 			errChan = nil
 			aggrCtx, aggrCancel = context.WithCancel(ctx)
+		case <-unwatchedC:
+			select {
+			case f.watchChan <- unwatched:
+			case <-ctx.Done():
+				aggrCancel()
+				return
+			}
 		case ev := <-summaryChan:
 			if data, ok := ev.Data.(FolderSummaryEventData); !ok {
 				f.evLogger.Log(events.Failure, "Unexpected type of folder-summary event in folder.monitorWatch")
@@ -1138,11 +1368,13 @@ func (f *folder) setWatchError(err error, nextTryIn time.Duration) {
 }
 
 // scanOnWatchErr schedules a full scan immediately if an error occurred while watching.
-func (f *folder) scanOnWatchErr() {
+// Without periodic rescans, that is only once the watcher works again, given
+// the result of starting it.
+func (f *folder) scanOnWatchErr(startErr error) {
 	f.watchMut.Lock()
 	err := f.watchErr
 	f.watchMut.Unlock()
-	if err != nil {
+	if err != nil && (startErr == nil || !f.WatcherOnly()) {
 		f.DelayScan(0)
 	}
 }
@@ -1196,8 +1428,9 @@ func (f *folder) newScanError(path string, err error) {
 	f.errorsMut.Lock()
 	f.sl.Warn("Failed to scan", slogutil.FilePath(path), slogutil.Error(err))
 	f.scanErrors = append(f.scanErrors, FileError{
-		Err:  err.Error(),
-		Path: path,
+		Err:      err.Error(),
+		Path:     path,
+		Category: fileErrorCategory(err),
 	})
 	f.errorsMut.Unlock()
 }
@@ -1248,18 +1481,25 @@ func (f *folder) ScheduleForceRescan(path string) {
 }
 
 func (f *folder) updateLocalsFromScanning(fs []protocol.FileInfo) error {
+	activity := f.activity(fs)
 	if err := f.updateLocals(fs); err != nil {
 		return err
 	}
+	f.model.fileActivity.record(activity)
 	f.emitDiskChangeEvents(fs, events.LocalChangeDetected)
+	f.model.checkFolderAlarms(f.ID, countDeleted(fs))
 	return nil
 }
 
 func (f *folder) updateLocalsFromPulling(fs []protocol.FileInfo) error {
+	activity := f.activity(fs)
 	if err := f.updateLocals(fs); err != nil {
 		return err
 	}
+	f.model.fileActivity.record(activity)
 	f.emitDiskChangeEvents(fs, events.RemoteChangeDetected)
+	// Deletes we pull were counted when we received them.
+	f.model.checkFolderAlarms(f.ID, 0)
 	return nil
 }
 
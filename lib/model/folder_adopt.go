@@ -0,0 +1,148 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"errors"
+
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Data put in a folder by other means than syncing, such as restoring a
+// backup, is scanned as new local changes. Where other devices have the same
+// items, those would either win and cause conflicts there, or be replaced.
+// Adopting the folder gives up the local version of such items, so that the
+// puller takes the remote version over with a metadata only update.
+
+var errAdoptNotSupported = errors.New("adopting is not supported for send only and receive encrypted folders")
+
+// Adopt scans the folder and takes local items that have no history but
+// the local one, and are identical to the items on the other devices, as
+// in sync with those. It returns the number of adopted items.
+func (m *model) Adopt(folder string) (int, error) {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	cfg := m.folderCfgs[folder]
+	runner, _ := m.folderRunners.Get(folder)
+	m.mut.RUnlock()
+	if err != nil {
+		return 0, err
+	}
+	if cfg.Type == config.FolderTypeSendOnly || cfg.Type == config.FolderTypeReceiveEncrypted {
+		return 0, errAdoptNotSupported
+	}
+	return runner.Adopt()
+}
+
+func (f *folder) Adopt() (int, error) {
+	<-f.initialScanFinished
+	var adopted int
+	err := f.doInSync(func(ctx context.Context) error {
+		// Hash what's there now, as what is adopted is the content the
+		// database has for it.
+		if err := f.scanSubdirs(ctx, nil); err != nil {
+			return err
+		}
+		var err error
+		adopted, err = f.adoptIdentical(ctx)
+		return err
+	})
+	if adopted > 0 {
+		f.SchedulePull()
+	}
+	return adopted, err
+}
+
+// adoptIdentical drops the local version of items with only local history
+// that all remote devices having them agree with, making them needed and
+// pulled as metadata updates.
+func (f *folder) adoptIdentical(ctx context.Context) (int, error) {
+	var devices []protocol.DeviceID
+	for _, dev := range f.DeviceIDs() {
+		if dev != f.model.id {
+			devices = append(devices, dev)
+		}
+	}
+
+	batch := NewFileInfoBatch(f.updateLocals)
+	adopted := 0
+	for fi, err := range itererr.Zip(f.db.AllLocalFiles(f.folderID, protocol.LocalDeviceID)) {
+		if err != nil {
+			return adopted, err
+		}
+		if err := ctx.Err(); err != nil {
+			return adopted, err
+		}
+		if fi.IsDeleted() || fi.IsInvalid() || !onlyLocalHistory(fi.Version, f.shortID) {
+			continue
+		}
+		ok, err := f.identicalOnRemotes(fi, devices)
+		if err != nil {
+			return adopted, err
+		}
+		if !ok {
+			continue
+		}
+
+		f.sl.DebugContext(ctx, "Adopting item identical to remote devices", slogutil.FilePath(fi.Name))
+		fi.Version = protocol.Vector{}
+		batch.Append(fi)
+		adopted++
+		if err := batch.FlushIfFull(); err != nil {
+			return adopted, err
+		}
+	}
+	return adopted, batch.Flush()
+}
+
+// identicalOnRemotes returns true if at least one of the devices has the
+// item, and those that have it have the same content, modification time
+// aside.
+func (f *folder) identicalOnRemotes(fi protocol.FileInfo, devices []protocol.DeviceID) (bool, error) {
+	found := false
+	for _, dev := range devices {
+		rf, ok, err := f.db.GetDeviceFile(f.folderID, dev, fi.Name)
+		if err != nil {
+			return false, err
+		}
+		if !ok || rf.IsInvalid() {
+			continue
+		}
+		if rf.IsDeleted() || !sameContent(fi, rf) {
+			return false, nil
+		}
+		found = true
+	}
+	return found, nil
+}
+
+func sameContent(a, b protocol.FileInfo) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case protocol.FileInfoTypeFile:
+		return a.Size == b.Size && a.BlocksEqual(b)
+	case protocol.FileInfoTypeSymlink:
+		return string(a.SymlinkTarget) == string(b.SymlinkTarget)
+	default:
+		return true
+	}
+}
+
+func onlyLocalHistory(v protocol.Vector, id protocol.ShortID) bool {
+	for _, c := range v.Counters {
+		if c.ID != id {
+			return false
+		}
+	}
+	return len(v.Counters) > 0
+}
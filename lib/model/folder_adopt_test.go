@@ -0,0 +1,80 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestAdoptIdentical(t *testing.T) {
+	local := func(name string, blockNumbers ...int) protocol.FileInfo {
+		fi := setupFile(name, blockNumbers)
+		fi.Version = protocol.Vector{}.Update(myID.Short())
+		return fi
+	}
+	var sequence int64
+	remote := func(name string, blockNumbers ...int) protocol.FileInfo {
+		sequence++
+		fi := setupFile(name, blockNumbers)
+		fi.Version = protocol.Vector{}.Update(device1.Short())
+		fi.Sequence = sequence
+		return fi
+	}
+
+	synced := local("synced", 4)
+	synced.Version = synced.Version.Update(device1.Short())
+	m, f := setupSendReceiveFolder(t,
+		local("same", 1, 2),
+		local("different", 3),
+		local("localonly", 5),
+		synced,
+	)
+	defer cleanupModel(m)
+
+	syncedRemote := synced
+	syncedRemote.Sequence = 10
+	must(t, m.sdb.Update(f.folderID, device1, []protocol.FileInfo{
+		remote("same", 1, 2),
+		remote("different", 6),
+		syncedRemote,
+	}))
+
+	adopted, err := f.adoptIdentical(context.Background())
+	must(t, err)
+	if adopted != 1 {
+		t.Errorf("expected one adopted item, got %d", adopted)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		adopted bool
+	}{
+		{"same", true},
+		{"different", false},
+		{"localonly", false},
+		{"synced", false},
+	} {
+		fi, ok, err := m.sdb.GetDeviceFile(f.folderID, protocol.LocalDeviceID, tc.name)
+		must(t, err)
+		if !ok {
+			t.Fatalf("%s: missing", tc.name)
+		}
+		if adopted := len(fi.Version.Counters) == 0; adopted != tc.adopted {
+			t.Errorf("%s: expected adopted %v, got version %v", tc.name, tc.adopted, fi.Version)
+		}
+	}
+
+	// The remote version is now the global one, to be taken over.
+	gf, ok, err := m.sdb.GetGlobalFile(f.folderID, "same")
+	must(t, err)
+	if !ok || !gf.Version.Equal(protocol.Vector{}.Update(device1.Short())) {
+		t.Error("expected the remote version to be global, got", gf.Version)
+	}
+}
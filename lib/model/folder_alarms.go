@@ -0,0 +1,157 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const deleteAlarmWindow = 5 * time.Minute
+
+const (
+	alarmDeletes = "deletes"
+	alarmFiles   = "files"
+	alarmSize    = "size"
+)
+
+// folderAlarm is an alarm raised for a folder, with the value that
+// exceeded the limit.
+type folderAlarm struct {
+	alarm string
+	value int64
+	limit int64
+}
+
+// folderAlarms keeps track of the alarms raised per folder, so that each
+// is raised once: the size alarms until the folder is back below the
+// limit, the deletes alarm once per window.
+type folderAlarms struct {
+	mut     sync.Mutex
+	folders map[string]*folderAlarmState
+}
+
+type folderAlarmState struct {
+	deletes      int
+	deletesSince time.Time
+	raised       map[string]bool
+}
+
+func newFolderAlarms() *folderAlarms {
+	return &folderAlarms{folders: make(map[string]*folderAlarmState)}
+}
+
+// check adds the deletes to the folder's count and returns the alarms that
+// are newly raised given the folder's global counts.
+func (a *folderAlarms) check(now time.Time, folder string, alarms config.FolderAlarms, deletes int, global db.Counts) []folderAlarm {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	st, ok := a.folders[folder]
+	if !ok {
+		st = &folderAlarmState{raised: make(map[string]bool)}
+		a.folders[folder] = st
+	}
+
+	var raised []folderAlarm
+	raise := func(alarm string, exceeded bool, value, limit int64) {
+		if !exceeded {
+			st.raised[alarm] = false
+			return
+		}
+		if !st.raised[alarm] {
+			st.raised[alarm] = true
+			raised = append(raised, folderAlarm{alarm: alarm, value: value, limit: limit})
+		}
+	}
+
+	if now.Sub(st.deletesSince) > deleteAlarmWindow {
+		st.deletes = 0
+		st.deletesSince = now
+		st.raised[alarmDeletes] = false
+	}
+	st.deletes += deletes
+	if alarms.MaxDeletes > 0 && st.deletes > alarms.MaxDeletes {
+		raise(alarmDeletes, true, int64(st.deletes), int64(alarms.MaxDeletes))
+	}
+
+	files := int64(global.Files + global.Directories + global.Symlinks)
+	raise(alarmFiles, alarms.MaxFiles > 0 && files > int64(alarms.MaxFiles), files, int64(alarms.MaxFiles))
+
+	maxSize := int64(alarms.MaxSize.BaseValue())
+	if alarms.MaxSize.Percentage() {
+		maxSize = 0
+	}
+	raise(alarmSize, maxSize > 0 && global.Bytes > maxSize, global.Bytes, maxSize)
+
+	return raised
+}
+
+func (a *folderAlarms) forget(folder string) {
+	a.mut.Lock()
+	delete(a.folders, folder)
+	a.mut.Unlock()
+}
+
+// checkFolderAlarms raises the alarms of the folder that are exceeded,
+// given the files just deleted locally or by another device.
+func (m *model) checkFolderAlarms(folder string, deletes int) {
+	cfg, ok := m.cfg.Folder(folder)
+	if !ok || !cfg.Alarms.Enabled() {
+		return
+	}
+	var global db.Counts
+	if cfg.Alarms.MaxFiles > 0 || cfg.Alarms.MaxSize.Value > 0 {
+		var err error
+		global, err = m.sdb.CountGlobal(folder)
+		if err != nil {
+			slog.Warn("Failed to check folder alarms", cfg.LogAttr(), slogutil.Error(err))
+			return
+		}
+	}
+
+	raised := m.folderAlarms.check(time.Now(), folder, cfg.Alarms, deletes, global)
+	for _, alarm := range raised {
+		slog.Warn("Folder alarm raised", cfg.LogAttr(), slog.String("alarm", alarm.alarm), slog.Int64("value", alarm.value), slog.Int64("limit", alarm.limit))
+		m.evLogger.Log(events.FolderAlarm, map[string]interface{}{
+			"folder": cfg.ID,
+			"label":  cfg.Label,
+			"alarm":  alarm.alarm,
+			"value":  alarm.value,
+			"limit":  alarm.limit,
+			"paused": cfg.Alarms.Pause,
+		})
+	}
+	if len(raised) > 0 && cfg.Alarms.Pause {
+		slog.Warn("Pausing folder for raised alarm", cfg.LogAttr())
+		// Modifying the config waits for us to commit it, which must not
+		// happen on the folder's or connection's routine.
+		go m.cfg.Modify(func(c *config.Configuration) {
+			if fcfg, i, ok := c.Folder(folder); ok {
+				fcfg.Paused = true
+				c.Folders[i] = fcfg
+			}
+		})
+	}
+}
+
+func countDeleted(fs []protocol.FileInfo) int {
+	n := 0
+	for _, f := range fs {
+		if f.IsDeleted() && !f.IsInvalid() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+func TestFolderAlarmsCheck(t *testing.T) {
+	a := newFolderAlarms()
+	alarms := config.FolderAlarms{
+		MaxDeletes: 10,
+		MaxFiles:   100,
+		MaxSize:    config.Size{Value: 1, Unit: "MB"},
+	}
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	if raised := a.check(now, "default", alarms, 6, db.Counts{Files: 50}); len(raised) != 0 {
+		t.Fatalf("expected no alarms, got %+v", raised)
+	}
+	raised := a.check(now.Add(time.Minute), "default", alarms, 6, db.Counts{Files: 50})
+	if len(raised) != 1 || raised[0].alarm != alarmDeletes || raised[0].value != 12 {
+		t.Fatalf("expected deletes alarm, got %+v", raised)
+	}
+	// Raised once per window.
+	if raised := a.check(now.Add(2*time.Minute), "default", alarms, 6, db.Counts{Files: 50}); len(raised) != 0 {
+		t.Fatalf("expected no repeated alarm, got %+v", raised)
+	}
+	if raised := a.check(now.Add(2*deleteAlarmWindow), "default", alarms, 6, db.Counts{Files: 50}); len(raised) != 0 {
+		t.Fatalf("expected the deletes to be counted anew, got %+v", raised)
+	}
+
+	raised = a.check(now.Add(3*deleteAlarmWindow), "default", alarms, 0, db.Counts{Files: 90, Directories: 20, Bytes: 2e6})
+	if len(raised) != 2 || raised[0].alarm != alarmFiles || raised[1].alarm != alarmSize {
+		t.Fatalf("expected files and size alarms, got %+v", raised)
+	}
+	if raised := a.check(now.Add(3*deleteAlarmWindow), "default", alarms, 0, db.Counts{Files: 110, Bytes: 2e6}); len(raised) != 0 {
+		t.Fatalf("expected no repeated alarms, got %+v", raised)
+	}
+	// Raised again after having been back below the limit.
+	a.check(now.Add(3*deleteAlarmWindow), "default", alarms, 0, db.Counts{Files: 10})
+	if raised := a.check(now.Add(3*deleteAlarmWindow), "default", alarms, 0, db.Counts{Files: 110}); len(raised) != 1 {
+		t.Fatalf("expected files alarm again, got %+v", raised)
+	}
+}
+
+func TestFolderAlarmPause(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	fcfg.Alarms = config.FolderAlarms{MaxDeletes: 2, Pause: true}
+	setFolder(t, w, fcfg)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	ffs := fcfg.Filesystem()
+	for _, name := range []string{"a", "b", "c"} {
+		writeFile(t, ffs, name, []byte(name))
+	}
+	must(t, m.ScanFolder(fcfg.ID))
+	if cfg, _ := w.Folder(fcfg.ID); cfg.Paused {
+		t.Fatal("folder paused without deletes")
+	}
+
+	for _, name := range []string{"a", "b", "c"} {
+		must(t, ffs.Remove(name))
+	}
+	must(t, m.ScanFolder(fcfg.ID))
+	for range 100 {
+		if cfg, _ := w.Folder(fcfg.ID); cfg.Paused {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("expected folder to be paused for deleting too many files")
+}
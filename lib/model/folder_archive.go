@@ -0,0 +1,124 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Receive-only folders with a moveReceivedTo path move each file out of
+// the folder once it has been pulled, for example to import photos from a
+// phone. The database keeps the file as we received it, so that other
+// devices see us as in sync and the originals can be deleted there. The
+// names of the moved files are kept in the database so that the next scan
+// doesn't take their absence for a local delete.
+
+func (m *model) archived(folder string) *db.Typed {
+	return db.NewTyped(m.sdb, "archived/"+folder+"/")
+}
+
+// isArchived returns true if the file was moved out of the folder after
+// being received.
+func (f *folder) isArchived(name string) bool {
+	if f.MoveReceivedTo == "" {
+		return false
+	}
+	_, ok, err := f.model.archived(f.folderID).String(name)
+	return err == nil && ok
+}
+
+// archiveReceived moves the pulled files out of the folder, and forgets
+// about those since deleted.
+func (f *folder) archiveReceived(files []protocol.FileInfo) {
+	if f.MoveReceivedTo == "" {
+		return
+	}
+	archived := f.model.archived(f.folderID)
+	dst := fs.NewFilesystem(f.FilesystemType.ToFS(), f.MoveReceivedTo)
+	for _, file := range files {
+		switch {
+		case file.IsDeleted():
+			if err := archived.Delete(file.Name); err != nil {
+				f.sl.Warn("Failed to forget moved file", slogutil.FilePath(file.Name), slogutil.Error(err))
+			}
+		case file.IsInvalid() || file.Type != protocol.FileInfoTypeFile:
+		default:
+			name, err := f.moveOut(dst, file.Name)
+			if err != nil {
+				f.sl.Warn("Failed to move received file", slogutil.FilePath(file.Name), slogutil.Error(err))
+				continue
+			}
+			if err := archived.PutString(file.Name, name); err != nil {
+				f.sl.Warn("Failed to record moved file", slogutil.FilePath(file.Name), slogutil.Error(err))
+				continue
+			}
+			f.sl.Debug("Moved received file", slogutil.FilePath(file.Name), "to", name)
+		}
+	}
+}
+
+// moveOut moves the file to the same relative path in dst, adding a
+// number to the name if there is a file there already, and returns the
+// name it got.
+func (f *folder) moveOut(dst fs.Filesystem, name string) (string, error) {
+	if err := dst.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return "", err
+	}
+	target := name
+	ext := filepath.Ext(name)
+	for i := 1; ; i++ {
+		if _, err := dst.Lstat(target); fs.IsNotExist(err) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		target = fmt.Sprintf("%s~%d%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+
+	// A rename does it if both are on the same disk, otherwise we copy.
+	if err := os.Rename(filepath.Join(f.mtimefs.URI(), name), filepath.Join(dst.URI(), target)); err == nil {
+		return target, nil
+	}
+	if err := copyBetween(f.mtimefs, name, dst, target); err != nil {
+		_ = dst.Remove(target)
+		return "", err
+	}
+	return target, f.mtimefs.Remove(name)
+}
+
+func copyBetween(srcFs fs.Filesystem, src string, dstFs fs.Filesystem, dst string) error {
+	info, err := srcFs.Lstat(src)
+	if err != nil {
+		return err
+	}
+	in, err := srcFs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := dstFs.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return dstFs.Chtimes(dst, info.ModTime(), info.ModTime())
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/rand"
+)
+
+func TestArchiveReceived(t *testing.T) {
+	w, cancel := newConfigWrapper(defaultCfg)
+	defer cancel()
+	cfg := w.RawCopy()
+	fcfg := newFolderConfig()
+	fcfg.ID = "ro"
+	fcfg.Type = config.FolderTypeReceiveOnly
+	fcfg.MoveReceivedTo = rand.String(32) + "?content=true"
+	cfg.Folders = []config.FolderConfiguration{fcfg}
+	replace(t, w, cfg)
+
+	m := newModel(t, w, myID, nil)
+	m.ServeBackground()
+	defer cleanupModel(m)
+	<-m.started
+
+	m.mut.RLock()
+	r, _ := m.folderRunners.Get("ro")
+	m.mut.RUnlock()
+	f := r.(*receiveOnlyFolder)
+
+	ffs := fcfg.Filesystem()
+	dst := fs.NewFilesystem(fs.FilesystemTypeFake, fcfg.MoveReceivedTo)
+	must(t, ffs.MkdirAll("dcim", 0o755))
+	writeFile(t, ffs, "dcim/photo.jpg", []byte("photo"))
+	must(t, dst.MkdirAll("dcim", 0o755))
+	writeFile(t, dst, "dcim/photo.jpg", []byte("other photo"))
+	must(t, m.ScanFolder("ro"))
+
+	fi, ok, err := m.CurrentFolderFile("ro", "dcim/photo.jpg")
+	if err != nil || !ok {
+		t.Fatal("file should have been scanned", err)
+	}
+	// Pretend we pulled it, rather than it being a local change.
+	fi.LocalFlags = 0
+	fi.Version = protocol.Vector{}.Update(device1.Short())
+	must(t, f.updateLocalsFromPulling([]protocol.FileInfo{fi}))
+	f.archiveReceived([]protocol.FileInfo{fi})
+
+	if _, err := ffs.Lstat("dcim/photo.jpg"); !fs.IsNotExist(err) {
+		t.Fatal("file should have been moved out of the folder, got", err)
+	}
+	if _, err := dst.Lstat("dcim/photo~1.jpg"); err != nil {
+		t.Fatal("file should have been moved next to the existing one:", err)
+	}
+	if !f.isArchived("dcim/photo.jpg") {
+		t.Fatal("file should be marked as archived")
+	}
+
+	// The missing file is not a local delete.
+	must(t, m.ScanFolder("ro"))
+	if cur, _, _ := m.CurrentFolderFile("ro", "dcim/photo.jpg"); cur.IsDeleted() || !cur.Version.Equal(fi.Version) {
+		t.Fatal("archived file should be unchanged, got", cur)
+	}
+
+	// Until the file is deleted remotely.
+	fi.SetDeleted(device1.Short())
+	f.archiveReceived([]protocol.FileInfo{fi})
+	if f.isArchived("dcim/photo.jpg") {
+		t.Fatal("deleted file should not be marked as archived")
+	}
+}
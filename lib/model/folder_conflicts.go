@@ -0,0 +1,122 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// conflictCleanupInterval is how often conflict copies are checked against
+// the folder's maximum number and age of conflicts. The maximum number is
+// otherwise only enforced when a new conflict copy is made.
+const conflictCleanupInterval = time.Hour
+
+const (
+	conflictMarker     = ".sync-conflict-"
+	conflictTimeLayout = "20060102-150405"
+)
+
+// parseConflictName returns the name of the file a conflict copy was made
+// of, and when it was made.
+func parseConflictName(name string) (string, time.Time, bool) {
+	dir, base := filepath.Split(name)
+	idx := strings.LastIndex(base, conflictMarker)
+	if idx < 0 {
+		return "", time.Time{}, false
+	}
+	rest := base[idx+len(conflictMarker):]
+	if len(rest) < len(conflictTimeLayout) {
+		return "", time.Time{}, false
+	}
+	made, err := time.ParseInLocation(conflictTimeLayout, rest[:len(conflictTimeLayout)], time.Local)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	// What follows the time is the ID of the device that made the
+	// conflicting change, if known, and then the extension.
+	ext := filepath.Ext(rest[len(conflictTimeLayout):])
+	return dir + base[:idx] + ext, made, true
+}
+
+type conflictCopy struct {
+	name string
+	made time.Time
+}
+
+func (f *folder) conflictCleanupEnabled() bool {
+	return f.MaxConflicts > 0 || f.MaxConflictAgeS > 0
+}
+
+func (f *folder) conflictCleanupTimerFired(ctx context.Context) error {
+	defer f.conflictCleanupTimer.Reset(conflictCleanupInterval)
+	return f.cleanConflicts(ctx, time.Now())
+}
+
+// cleanConflicts removes the conflict copies beyond the maximum number per
+// file, keeping the newest, and those older than the maximum age.
+func (f *folder) cleanConflicts(ctx context.Context, now time.Time) error {
+	byOriginal := make(map[string][]conflictCopy)
+	it, errFn := f.db.AllLocalFiles(f.folderID, protocol.LocalDeviceID)
+	for fi := range it {
+		if fi.IsDeleted() || fi.IsInvalid() || fi.IsDirectory() || !isConflict(fi.Name) {
+			continue
+		}
+		original, made, ok := parseConflictName(fi.Name)
+		if !ok {
+			continue
+		}
+		byOriginal[original] = append(byOriginal[original], conflictCopy{name: fi.Name, made: made})
+	}
+	if err := errFn(); err != nil {
+		return err
+	}
+
+	maxAge := time.Duration(f.MaxConflictAgeS) * time.Second
+	var removed []string
+	for original, copies := range byOriginal {
+		slices.SortFunc(copies, func(a, b conflictCopy) int {
+			return b.made.Compare(a.made)
+		})
+		for i, c := range copies {
+			var reason string
+			switch {
+			case f.MaxConflicts > 0 && i >= f.MaxConflicts:
+				reason = "count"
+			case maxAge > 0 && now.Sub(c.made) > maxAge:
+				reason = "age"
+			default:
+				continue
+			}
+			if err := f.mtimefs.Remove(c.name); err != nil && !fs.IsNotExist(err) {
+				f.sl.WarnContext(ctx, "Failed to remove old conflict copy", slogutil.FilePath(c.name), slogutil.Error(err))
+				continue
+			}
+			f.evLogger.Log(events.ConflictRemoved, map[string]string{
+				"folder":       f.folderID,
+				"item":         original,
+				"conflictCopy": c.name,
+				"reason":       reason,
+			})
+			removed = append(removed, c.name)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	f.sl.InfoContext(ctx, "Removed old conflict copies", "count", len(removed))
+	return f.scanSubdirs(ctx, removed)
+}
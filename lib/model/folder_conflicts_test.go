@@ -0,0 +1,93 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/events"
+)
+
+func TestParseConflictName(t *testing.T) {
+	made := time.Date(2026, 3, 4, 5, 6, 7, 0, time.Local)
+	cases := []struct {
+		name, original string
+	}{
+		{"doc.sync-conflict-20260304-050607-ABCDEFG.txt", "doc.txt"},
+		{"noext.sync-conflict-20260304-050607-ABCDEFG", "noext"},
+		{"old.sync-conflict-20260304-050607.txt", "old.txt"},
+		{filepath.Join("dir", "a.tar.sync-conflict-20260304-050607-ABCDEFG.gz"), filepath.Join("dir", "a.tar.gz")},
+	}
+	for _, tc := range cases {
+		original, at, ok := parseConflictName(tc.name)
+		if !ok || original != tc.original || !at.Equal(made) {
+			t.Errorf("%s: got %q, %v, %v", tc.name, original, at, ok)
+		}
+	}
+	if _, _, ok := parseConflictName("doc.sync-conflict-garbage.txt"); ok {
+		t.Error("unexpected parse of invalid conflict name")
+	}
+}
+
+func TestCleanConflicts(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	fcfg.MaxConflicts = 2
+	fcfg.MaxConflictAgeS = 86400
+	setFolder(t, w, fcfg)
+	ffs := fcfg.Filesystem()
+
+	now := time.Now()
+	conflict := func(base, ext string, age time.Duration) string {
+		return base + ".sync-conflict-" + now.Add(-age).Format(conflictTimeLayout) + "-ABCDEFG" + ext
+	}
+	keep := []string{"doc.txt", conflict("doc", ".txt", time.Hour), conflict("doc", ".txt", 2*time.Hour)}
+	remove := []string{conflict("doc", ".txt", 3*time.Hour), conflict("old", ".txt", 48*time.Hour)}
+	for _, name := range append(keep, remove...) {
+		writeFile(t, ffs, name, []byte(name))
+	}
+
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+	sub := m.evLogger.Subscribe(events.ConflictRemoved)
+	defer sub.Unsubscribe()
+
+	m.mut.RLock()
+	r, _ := m.folderRunners.Get(fcfg.ID)
+	m.mut.RUnlock()
+	f := r.(*sendReceiveFolder)
+	must(t, f.doInSync(func(ctx context.Context) error {
+		return f.cleanConflicts(ctx, now)
+	}))
+
+	for _, name := range keep {
+		if _, err := ffs.Lstat(name); err != nil {
+			t.Errorf("%s should be kept: %v", name, err)
+		}
+	}
+	for _, name := range remove {
+		if _, err := ffs.Lstat(name); err == nil {
+			t.Errorf("%s should be removed", name)
+		}
+		if fi, ok := m.testCurrentFolderFile(fcfg.ID, name); !ok || !fi.IsDeleted() {
+			t.Errorf("%s should be deleted in the index", name)
+		}
+	}
+
+	for range remove {
+		select {
+		case ev := <-sub.C():
+			if data := ev.Data.(map[string]string); data["reason"] != "count" && data["reason"] != "age" {
+				t.Error("unexpected event", data)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("missing conflict removed event")
+		}
+	}
+}
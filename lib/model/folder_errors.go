@@ -0,0 +1,125 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+// Categories of errors on files while scanning and syncing, as given in
+// FileError.Category and used as metric label.
+const (
+	ErrorCategoryNotAvailable = "not_available"
+	ErrorCategoryModified     = "modified"
+	ErrorCategoryPermission   = "permission"
+	ErrorCategoryNotExist     = "not_exist"
+	ErrorCategoryNoSpace      = "no_space"
+	ErrorCategoryCaseConflict = "case_conflict"
+	ErrorCategoryLocked       = "locked"
+	ErrorCategoryPathTooLong  = "path_too_long"
+	ErrorCategoryInvalidName  = "invalid_name"
+	ErrorCategoryOther        = "other"
+)
+
+// fileErrorCategory sorts errors on files into a small number of buckets,
+// by what it takes to resolve them.
+func fileErrorCategory(err error) string {
+	switch {
+	case errors.Is(err, errNotAvailable), errors.Is(err, errNoDevice):
+		return ErrorCategoryNotAvailable
+	case errors.Is(err, errModified):
+		return ErrorCategoryModified
+	case errors.Is(err, config.ErrInsufficientSpace), fs.IsNoSpace(err):
+		return ErrorCategoryNoSpace
+	case fs.IsErrCaseConflict(err):
+		return ErrorCategoryCaseConflict
+	case fs.IsPermission(err):
+		return ErrorCategoryPermission
+	case fs.IsNotExist(err):
+		return ErrorCategoryNotExist
+	case fs.IsLocked(err):
+		return ErrorCategoryLocked
+	case fs.IsNameTooLong(err):
+		return ErrorCategoryPathTooLong
+	case fs.IsInvalidFilename(err):
+		return ErrorCategoryInvalidName
+	default:
+		return ErrorCategoryOther
+	}
+}
+
+// FileErrorCategories returns the number of errors in each category.
+func FileErrorCategories(errs []FileError) map[string]int {
+	counts := make(map[string]int)
+	for _, fe := range errs {
+		if fe.Category != "" {
+			counts[fe.Category]++
+		}
+	}
+	return counts
+}
+
+// retryPolicy is how long to wait before pulling again after failing to
+// sync items with a category of error, in multiples of the folder's pull
+// pause. The wait doubles for every further failure, up to the maximum.
+type retryPolicy struct {
+	initial float64
+	max     float64
+}
+
+var (
+	defaultRetryPolicy = retryPolicy{initial: 1, max: 60}
+
+	retryPolicies = map[string]retryPolicy{
+		// Files in use are usually released soon, as are those that
+		// changed while we were syncing them.
+		ErrorCategoryLocked:   {initial: 0.25, max: 4},
+		ErrorCategoryModified: {initial: 0.25, max: 4},
+		// These need someone to do something about them, which a rescan
+		// will notice anyway.
+		ErrorCategoryPermission:   {initial: 5, max: 60},
+		ErrorCategoryCaseConflict: {initial: 5, max: 60},
+		ErrorCategoryPathTooLong:  {initial: 10, max: 60},
+		ErrorCategoryInvalidName:  {initial: 10, max: 60},
+	}
+)
+
+func (p retryPolicy) delay(base time.Duration, failures int) time.Duration {
+	mult := p.initial
+	for range failures {
+		if mult >= p.max {
+			break
+		}
+		mult *= 2
+	}
+	return time.Duration(min(mult, p.max) * float64(base))
+}
+
+// pullRetryDelay returns how long to wait before pulling again, given the
+// errors of the last pull and the number of failed pulls before it. The
+// category retried soonest decides; without errors on items, the pull
+// failed as a whole and the default policy applies.
+func pullRetryDelay(errs []FileError, base time.Duration, failures int) time.Duration {
+	if len(errs) == 0 {
+		return defaultRetryPolicy.delay(base, failures)
+	}
+	var delay time.Duration
+	for i, fe := range errs {
+		p, ok := retryPolicies[fe.Category]
+		if !ok {
+			p = defaultRetryPolicy
+		}
+		if d := p.delay(base, failures); i == 0 || d < delay {
+			delay = d
+		}
+	}
+	return delay
+}
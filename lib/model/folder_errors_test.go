@@ -0,0 +1,63 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPullRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	base := time.Minute
+	locked := FileError{Path: "a", Category: ErrorCategoryLocked}
+	permission := FileError{Path: "b", Category: ErrorCategoryPermission}
+	other := FileError{Path: "c", Category: ErrorCategoryOther}
+
+	cases := []struct {
+		errs     []FileError
+		failures int
+		exp      time.Duration
+	}{
+		// Without errors on items, and for uncategorized ones, the pause
+		// doubles up to an hour.
+		{nil, 0, time.Minute},
+		{nil, 3, 8 * time.Minute},
+		{nil, 10, time.Hour},
+		{[]FileError{other}, 1, 2 * time.Minute},
+		// Locked files are retried soon, and not much later even after
+		// failing repeatedly.
+		{[]FileError{locked}, 0, 15 * time.Second},
+		{[]FileError{locked}, 10, 4 * time.Minute},
+		// Permission errors wait longer.
+		{[]FileError{permission}, 0, 5 * time.Minute},
+		{[]FileError{permission}, 1, 10 * time.Minute},
+		// The category to retry first decides.
+		{[]FileError{permission, other}, 0, time.Minute},
+		{[]FileError{permission, locked, other}, 2, time.Minute},
+	}
+	for _, tc := range cases {
+		if d := pullRetryDelay(tc.errs, base, tc.failures); d != tc.exp {
+			t.Errorf("pullRetryDelay(%v, %d) = %v, expected %v", tc.errs, tc.failures, d, tc.exp)
+		}
+	}
+}
+
+func TestFileErrorCategories(t *testing.T) {
+	t.Parallel()
+
+	counts := FileErrorCategories([]FileError{
+		{Path: "a", Category: ErrorCategoryLocked},
+		{Path: "b", Category: ErrorCategoryLocked},
+		{Path: "c", Category: ErrorCategoryPermission},
+		{Path: "d"},
+	})
+	if len(counts) != 2 || counts[ErrorCategoryLocked] != 2 || counts[ErrorCategoryPermission] != 1 {
+		t.Error("unexpected counts", counts)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Devices may advertise where they keep the folders they share, which
+// lets us flag setups that are likely mistakes: folders synced into the
+// same or nested directories, as happens when several devices use the
+// same network share, and folders whose marker has gone missing.
+
+// Reasons for a FolderPathWarning.
+const (
+	// The folder marker is missing on the device.
+	FolderPathMarkerMissing = "markerMissing"
+	// The path is the same as that of another folder.
+	FolderPathSame = "samePath"
+	// The path is inside, or contains, the path of a folder.
+	FolderPathNested = "nested"
+)
+
+// A FolderPathWarning flags the path a device keeps a folder at.
+type FolderPathWarning struct {
+	FolderID string            `json:"folderID"`
+	DeviceID protocol.DeviceID `json:"deviceID"`
+	Path     string            `json:"path"`
+	Reason   string            `json:"reason"`
+	// The folder, and the device keeping it, that the path clashes with,
+	// for all reasons but a missing marker.
+	OtherFolderID string            `json:"otherFolderID,omitempty"`
+	OtherDeviceID protocol.DeviceID `json:"otherDeviceID"`
+	OtherPath     string            `json:"otherPath,omitempty"`
+}
+
+// remoteFolderInfo is what a device told us about its side of a folder in
+// its latest cluster config.
+type remoteFolderInfo struct {
+	label            string
+	path             string
+	markerPresent    bool
+	forwardsRequests bool
+}
+
+// remoteFolderInfos keeps the remote folder infos per device and folder.
+// Like the cluster index info it is kept when devices disconnect.
+type remoteFolderInfos struct {
+	mut     sync.Mutex
+	devices map[protocol.DeviceID]map[string]remoteFolderInfo
+}
+
+func newRemoteFolderInfos() *remoteFolderInfos {
+	return &remoteFolderInfos{devices: make(map[protocol.DeviceID]map[string]remoteFolderInfo)}
+}
+
+func (r *remoteFolderInfos) announced(device protocol.DeviceID, folder protocol.Folder) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	folders, ok := r.devices[device]
+	if !ok {
+		folders = make(map[string]remoteFolderInfo)
+		r.devices[device] = folders
+	}
+	folders[folder.ID] = remoteFolderInfo{
+		label:            folder.Label,
+		path:             folder.Path,
+		markerPresent:    folder.MarkerPresent,
+		forwardsRequests: folder.ForwardsRequests,
+	}
+}
+
+func (r *remoteFolderInfos) get(device protocol.DeviceID, folder string) remoteFolderInfo {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return r.devices[device][folder]
+}
+
+// folderPath is a folder path kept by a device.
+type folderPath struct {
+	folder string
+	device protocol.DeviceID
+	path   string
+}
+
+// FolderPathWarnings returns warnings about the paths devices keep shared
+// folders at, as far as they advertise them, compared to each other and to
+// our own.
+func (m *model) FolderPathWarnings() []FolderPathWarning {
+	var warnings []FolderPathWarning
+	var paths []folderPath
+	for _, fcfg := range m.cfg.Folders() {
+		paths = append(paths, folderPath{folder: fcfg.ID, device: m.id, path: fcfg.Filesystem().URI()})
+		for _, dev := range fcfg.Devices {
+			if dev.DeviceID == m.id {
+				continue
+			}
+			info := m.remoteFolderInfos.get(dev.DeviceID, fcfg.ID)
+			if info.path == "" {
+				continue
+			}
+			paths = append(paths, folderPath{folder: fcfg.ID, device: dev.DeviceID, path: info.path})
+			if !info.markerPresent {
+				warnings = append(warnings, FolderPathWarning{
+					FolderID: fcfg.ID,
+					DeviceID: dev.DeviceID,
+					Path:     info.path,
+					Reason:   FolderPathMarkerMissing,
+				})
+			}
+		}
+	}
+
+	for i, a := range paths {
+		for _, b := range paths[i+1:] {
+			if a.device == m.id && b.device == m.id {
+				// Our own folders are checked when starting them.
+				continue
+			}
+			reason := clashingFolderPaths(a, b)
+			if reason == "" {
+				continue
+			}
+			// Flag the remote side, or both if both are remote.
+			if a.device != m.id {
+				warnings = append(warnings, newFolderPathWarning(a, b, reason))
+			}
+			if b.device != m.id {
+				warnings = append(warnings, newFolderPathWarning(b, a, reason))
+			}
+		}
+	}
+
+	slices.SortFunc(warnings, func(a, b FolderPathWarning) int {
+		if c := strings.Compare(a.FolderID, b.FolderID); c != 0 {
+			return c
+		}
+		return a.DeviceID.Compare(b.DeviceID)
+	})
+	return warnings
+}
+
+func newFolderPathWarning(p, other folderPath, reason string) FolderPathWarning {
+	return FolderPathWarning{
+		FolderID:      p.folder,
+		DeviceID:      p.device,
+		Path:          p.path,
+		Reason:        reason,
+		OtherFolderID: other.folder,
+		OtherDeviceID: other.device,
+		OtherPath:     other.path,
+	}
+}
+
+// clashingFolderPaths returns the reason the two folder paths shouldn't
+// both be synced, or the empty string if they are fine. Different devices
+// keeping the same folder at the same path is common and fine.
+func clashingFolderPaths(a, b folderPath) string {
+	pa, pb := normalizeFolderPath(a.path), normalizeFolderPath(b.path)
+	switch {
+	case pa == pb:
+		if a.folder == b.folder {
+			return ""
+		}
+		return FolderPathSame
+	case strings.HasPrefix(pa, strings.TrimSuffix(pb, "/")+"/"),
+		strings.HasPrefix(pb, strings.TrimSuffix(pa, "/")+"/"):
+		return FolderPathNested
+	default:
+		return ""
+	}
+}
+
+// normalizeFolderPath makes paths advertised by devices on any platform
+// comparable.
+func normalizeFolderPath(p string) string {
+	return path.Clean(strings.ReplaceAll(p, `\`, "/"))
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestClashingFolderPaths(t *testing.T) {
+	cases := []struct {
+		a, b   folderPath
+		reason string
+	}{
+		{folderPath{"f", device1, "/srv/a"}, folderPath{"f", device2, "/srv/a/"}, ""},
+		{folderPath{"f", device1, "/srv/a"}, folderPath{"g", device2, "/srv/a"}, FolderPathSame},
+		{folderPath{"f", device1, "/srv/a"}, folderPath{"f", device2, "/srv/a/b"}, FolderPathNested},
+		{folderPath{"f", device1, `C:\Sync\b`}, folderPath{"g", device1, `C:\Sync`}, FolderPathNested},
+		{folderPath{"f", device1, "/"}, folderPath{"g", device2, "/srv"}, FolderPathNested},
+		{folderPath{"f", device1, "/srv/a"}, folderPath{"g", device2, "/srv/ab"}, ""},
+	}
+	for _, tc := range cases {
+		if reason := clashingFolderPaths(tc.a, tc.b); reason != tc.reason {
+			t.Errorf("%v vs %v: expected %q, got %q", tc.a, tc.b, tc.reason, reason)
+		}
+	}
+}
+
+func TestFolderPathWarnings(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	other := newFolderConfiguration(w, "other", "other", config.FilesystemTypeFake, "other")
+	addDevice2(t, w, other)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	m.remoteFolderInfos.announced(device1, protocol.Folder{ID: fcfg.ID, Path: "/srv/share", MarkerPresent: true})
+	m.remoteFolderInfos.announced(device2, protocol.Folder{ID: other.ID, Path: "/srv/share/sub"})
+
+	warnings := m.FolderPathWarnings()
+	expected := []FolderPathWarning{
+		{FolderID: fcfg.ID, DeviceID: device1, Path: "/srv/share", Reason: FolderPathNested, OtherFolderID: other.ID, OtherDeviceID: device2, OtherPath: "/srv/share/sub"},
+		{FolderID: other.ID, DeviceID: device2, Path: "/srv/share/sub", Reason: FolderPathMarkerMissing},
+		{FolderID: other.ID, DeviceID: device2, Path: "/srv/share/sub", Reason: FolderPathNested, OtherFolderID: fcfg.ID, OtherDeviceID: device1, OtherPath: "/srv/share"},
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, warnings)
+	}
+	for i := range warnings {
+		if warnings[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], warnings[i])
+		}
+	}
+}
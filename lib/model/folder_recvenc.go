@@ -18,7 +18,6 @@ import (
 	"github.com/syncthing/syncthing/lib/fs"
 	"github.com/syncthing/syncthing/lib/ignore"
 	"github.com/syncthing/syncthing/lib/protocol"
-	"github.com/syncthing/syncthing/lib/semaphore"
 	"github.com/syncthing/syncthing/lib/versioner"
 )
 
@@ -30,8 +29,8 @@ type receiveEncryptedFolder struct {
 	*sendReceiveFolder
 }
 
-func newReceiveEncryptedFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, ver versioner.Versioner, evLogger events.Logger, ioLimiter *semaphore.Semaphore) service {
-	f := &receiveEncryptedFolder{newSendReceiveFolder(model, ignores, cfg, ver, evLogger, ioLimiter).(*sendReceiveFolder)}
+func newReceiveEncryptedFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, ver versioner.Versioner, evLogger events.Logger, sched *scheduler) service {
+	f := &receiveEncryptedFolder{newSendReceiveFolder(model, ignores, cfg, ver, evLogger, sched).(*sendReceiveFolder)}
 	f.localFlags = protocol.FlagLocalReceiveOnly // gets propagated to the scanner, and set on locally changed files
 	return f
 }
@@ -40,6 +39,51 @@ func (f *receiveEncryptedFolder) Revert() {
 	f.doInSync(f.revert)
 }
 
+// RemoveUnannounced removes the items no other device announces, which
+// after rotating the encryption password are those still encrypted with the
+// previous one.
+func (f *receiveEncryptedFolder) RemoveUnannounced() {
+	f.doInSync(f.removeUnannounced)
+}
+
+func (f *receiveEncryptedFolder) removeUnannounced(ctx context.Context) error {
+	batch := NewFileInfoBatch(func(fs []protocol.FileInfo) error {
+		f.updateLocalsFromScanning(fs)
+		return nil
+	})
+
+	for fi, err := range itererr.Zip(f.db.AllLocalFiles(f.folderID, protocol.LocalDeviceID)) {
+		if err != nil {
+			return err
+		}
+		if err := batch.FlushIfFull(); err != nil {
+			return err
+		}
+
+		if fi.IsReceiveOnlyChanged() || fi.IsDeleted() {
+			continue
+		}
+		devs, err := f.db.GetGlobalAvailability(f.folderID, fi.Name)
+		if err != nil {
+			return err
+		}
+		if len(devs) > 0 {
+			continue
+		}
+
+		// Marking it as changed locally makes it unexpected, to be removed
+		// by reverting.
+		fi.LocalFlags |= protocol.FlagLocalReceiveOnly
+		batch.Append(fi)
+	}
+
+	if err := batch.Flush(); err != nil {
+		return err
+	}
+
+	return f.revert(ctx)
+}
+
 func (f *receiveEncryptedFolder) revert(ctx context.Context) error {
 	f.sl.InfoContext(ctx, "Reverting unexpected items")
 
@@ -18,7 +18,6 @@ import (
 	"github.com/syncthing/syncthing/lib/events"
 	"github.com/syncthing/syncthing/lib/ignore"
 	"github.com/syncthing/syncthing/lib/protocol"
-	"github.com/syncthing/syncthing/lib/semaphore"
 	"github.com/syncthing/syncthing/lib/versioner"
 )
 
@@ -60,8 +59,8 @@ type receiveOnlyFolder struct {
 	*sendReceiveFolder
 }
 
-func newReceiveOnlyFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, ver versioner.Versioner, evLogger events.Logger, ioLimiter *semaphore.Semaphore) service {
-	sr := newSendReceiveFolder(model, ignores, cfg, ver, evLogger, ioLimiter).(*sendReceiveFolder)
+func newReceiveOnlyFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, ver versioner.Versioner, evLogger events.Logger, sched *scheduler) service {
+	sr := newSendReceiveFolder(model, ignores, cfg, ver, evLogger, sched).(*sendReceiveFolder)
 	sr.localFlags = protocol.FlagLocalReceiveOnly // gets propagated to the scanner, and set on locally changed files
 	return &receiveOnlyFolder{sr}
 }
@@ -130,10 +129,12 @@ func (f *receiveOnlyFolder) revert(ctx context.Context) error {
 			fi.SetDeleted(f.shortID)
 			fi.Version = protocol.Vector{} // if this file ever resurfaces anywhere we want our delete to be strictly older
 		case gf.IsEquivalentOptional(fi, protocol.FileInfoComparison{
-			ModTimeWindow:   f.modTimeWindow,
-			IgnoreFlags:     protocol.FlagLocalReceiveOnly,
-			IgnoreOwnership: !f.SyncOwnership,
-			IgnoreXattrs:    !f.SyncXattrs,
+			ModTimeWindow:          f.modTimeWindow,
+			IgnoreFlags:            protocol.FlagLocalReceiveOnly,
+			IgnoreOwnership:        !f.SyncOwnership,
+			IgnoreXattrs:           !f.SyncXattrs,
+			IgnorePosixACLs:        !f.SyncPosixACLs,
+			IgnoreAlternateStreams: !f.SyncAlternateStreams,
 		}):
 			// What we have locally is equivalent to the global file.
 			fi = gf
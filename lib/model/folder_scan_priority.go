@@ -0,0 +1,126 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/osutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// A full scan of a large folder can take hours, during which changes
+// elsewhere in the folder would wait their turn. Full scans therefore
+// first go through the directories where things changed most recently,
+// which are where new changes are most likely, and handle watcher events
+// as they arrive instead of after the scan.
+
+// recentDirsScannedFirst is the number of most recently changed
+// directories scanned ahead of a full scan.
+const recentDirsScannedFirst = 64
+
+// recentlyChangedDirs returns the directories holding the most recently
+// modified items in the local index, most recent first.
+func (f *folder) recentlyChangedDirs() ([]string, error) {
+	latest := make(map[string]time.Time)
+	note := func(dir string, t time.Time) {
+		if dir == "." || dir == "" {
+			return
+		}
+		if t.After(latest[dir]) {
+			latest[dir] = t
+		}
+	}
+
+	it, errFn := f.db.AllLocalFiles(f.folderID, protocol.LocalDeviceID)
+	for fi := range it {
+		if fi.IsDeleted() || fi.IsIgnored() {
+			continue
+		}
+		if fi.IsDirectory() {
+			note(fi.Name, fi.ModTime())
+		}
+		note(filepath.Dir(fi.Name), fi.ModTime())
+	}
+	if err := errFn(); err != nil {
+		return nil, err
+	}
+
+	dirs := make([]string, 0, len(latest))
+	for dir := range latest {
+		dirs = append(dirs, dir)
+	}
+	slices.SortFunc(dirs, func(a, b string) int {
+		if c := latest[b].Compare(latest[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	if len(dirs) > recentDirsScannedFirst {
+		dirs = dirs[:recentDirsScannedFirst]
+	}
+	return dirs, nil
+}
+
+// scanRecentlyChanged scans the most recently changed directories, ahead
+// of a full scan.
+func (f *folder) scanRecentlyChanged(ctx context.Context, batch *scanBatch) (int, error) {
+	dirs, err := f.recentlyChangedDirs()
+	if err != nil || len(dirs) == 0 {
+		return 0, err
+	}
+	dirs = unifySubs(dirs, func(string) bool { return true })
+	f.sl.DebugContext(ctx, "Scanning recently changed directories first", "dirs", len(dirs))
+	changes, err := f.scanSubdirsChangedAndNew(ctx, dirs, batch)
+	if err != nil {
+		return changes, err
+	}
+	return changes, batch.Flush()
+}
+
+// scanPendingWatchEvents scans the paths of watcher events that arrived
+// during a full scan, if any, without waiting for the full scan to end.
+func (f *folder) scanPendingWatchEvents(ctx context.Context, batch *scanBatch) (int, error) {
+	var subs []string
+	select {
+	case subs = <-f.watchChan:
+	default:
+		return 0, nil
+	}
+	if f.model.scansPaused() {
+		f.suspendedSubs = append(f.suspendedSubs, subs...)
+		return 0, nil
+	}
+
+	for i := range subs {
+		subs[i] = osutil.NativeFilename(subs[i])
+	}
+	subs = unifySubs(subs, func(file string) bool {
+		_, ok, err := f.db.GetDeviceFile(f.folderID, protocol.LocalDeviceID, file)
+		return err == nil && ok
+	})
+	if len(subs) == 0 {
+		// The whole folder, which the full scan covers anyway.
+		return 0, nil
+	}
+
+	f.sl.DebugContext(ctx, "Scanning watcher events during full scan", "subs", subs)
+	changes, err := f.scanSubdirsChangedAndNew(ctx, subs, batch)
+	if err != nil {
+		return changes, err
+	}
+	deleted, err := f.scanSubdirsDeletedAndIgnored(ctx, subs, batch)
+	changes += deleted
+	if err != nil {
+		return changes, err
+	}
+	return changes, batch.Flush()
+}
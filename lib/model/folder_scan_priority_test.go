@@ -0,0 +1,45 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecentlyChangedDirs(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	ffs := fcfg.Filesystem()
+
+	// Directories and their files, from least to most recently changed.
+	now := time.Now()
+	for i, dir := range []string{"cold", "warm", "hot"} {
+		must(t, ffs.MkdirAll(dir+"/sub", 0o755))
+		writeFile(t, ffs, dir+"/sub/file", []byte(dir))
+		mtime := now.Add(time.Duration(i-3) * time.Hour)
+		must(t, ffs.Chtimes(dir+"/sub/file", mtime, mtime))
+		must(t, ffs.Chtimes(dir+"/sub", mtime, mtime))
+		must(t, ffs.Chtimes(dir, mtime.Add(-time.Hour), mtime.Add(-time.Hour)))
+	}
+	writeFile(t, ffs, "toplevel", []byte("top"))
+
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+	m.mut.RLock()
+	r, _ := m.folderRunners.Get(fcfg.ID)
+	m.mut.RUnlock()
+	f := r.(*sendReceiveFolder)
+
+	dirs, err := f.recentlyChangedDirs()
+	must(t, err)
+	// A directory changed when anything directly in it did.
+	expected := []string{"hot", "hot/sub", "warm", "warm/sub", "cold", "cold/sub"}
+	if !slices.Equal(dirs, expected) {
+		t.Errorf("expected %v, got %v", expected, dirs)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+// Folders with active times are paused when an active time ends and
+// resumed when the next begins. We act only when a folder moves in or out
+// of its active times, so that a folder paused or resumed by hand in the
+// meantime stays that way until the next change.
+
+const folderScheduleInterval = time.Minute
+
+// folderSchedules remembers, for each folder with active times, whether it
+// was in one when last checked.
+type folderSchedules struct {
+	mut    sync.Mutex
+	active map[string]bool
+}
+
+func newFolderSchedules() *folderSchedules {
+	return &folderSchedules{active: make(map[string]bool)}
+}
+
+// update records whether the folder is in an active time, and returns true
+// if that is news.
+func (s *folderSchedules) update(folder string, active bool) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	prev, ok := s.active[folder]
+	s.active[folder] = active
+	return !ok || prev != active
+}
+
+func (s *folderSchedules) forget(folder string) {
+	s.mut.Lock()
+	delete(s.active, folder)
+	s.mut.Unlock()
+}
+
+// applyFolderSchedules pauses and resumes folders as their active times
+// end and begin.
+func (m *model) applyFolderSchedules(now time.Time) {
+	paused := make(map[string]bool)
+	for id, fcfg := range m.cfg.Folders() {
+		if len(fcfg.ActiveTimes) == 0 {
+			m.folderSchedules.forget(id)
+			continue
+		}
+		active := fcfg.IsActiveTime(now)
+		if !m.folderSchedules.update(id, active) || fcfg.Paused != active {
+			continue
+		}
+		if active {
+			slog.Info("Resuming folder at the start of its active time", fcfg.LogAttr())
+		} else {
+			slog.Info("Pausing folder at the end of its active time", fcfg.LogAttr())
+		}
+		paused[id] = !active
+	}
+	if len(paused) == 0 {
+		return
+	}
+
+	// Modifying the config waits for us to commit it, which must not
+	// happen on the serve routine.
+	go m.cfg.Modify(func(cfg *config.Configuration) {
+		for i := range cfg.Folders {
+			if p, ok := paused[cfg.Folders[i].ID]; ok {
+				cfg.Folders[i].Paused = p
+			}
+		}
+	})
+}
+
+// SetFolderSchedule sets the active times of the folder and pauses or
+// resumes it accordingly right away. Without active times the folder
+// stays as it is.
+func (m *model) SetFolderSchedule(folder string, activeTimes []string) error {
+	for _, s := range activeTimes {
+		if _, err := config.ParseTimeWindow(s); err != nil {
+			return err
+		}
+	}
+	if _, ok := m.cfg.Folder(folder); !ok {
+		return ErrFolderMissing
+	}
+
+	now := time.Now()
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		fcfg, i, ok := cfg.Folder(folder)
+		if !ok {
+			return
+		}
+		fcfg.ActiveTimes = slices.Clone(activeTimes)
+		if len(activeTimes) > 0 {
+			active := fcfg.IsActiveTime(now)
+			fcfg.Paused = !active
+			m.folderSchedules.update(folder, active)
+		} else {
+			m.folderSchedules.forget(folder)
+		}
+		cfg.Folders[i] = fcfg
+	})
+	if err != nil {
+		return err
+	}
+	waiter.Wait()
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+func TestFolderSchedule(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	if err := m.SetFolderSchedule(fcfg.ID, []string{"invalid"}); err == nil {
+		t.Error("expected an invalid schedule to be rejected")
+	}
+	if err := m.SetFolderSchedule("nonexistent", []string{"10:00-12:00"}); err == nil {
+		t.Error("expected a missing folder to be rejected")
+	}
+
+	// A schedule that never includes the present pauses the folder right
+	// away.
+	now := time.Now()
+	other := now.Add(-2*time.Hour).Format("15:04") + "-" + now.Add(-time.Hour).Format("15:04")
+	must(t, m.SetFolderSchedule(fcfg.ID, []string{other}))
+	if cfg, _ := w.Folder(fcfg.ID); !cfg.Paused || len(cfg.ActiveTimes) != 1 {
+		t.Fatal("expected the folder to be paused with its schedule set, got", cfg.Paused, cfg.ActiveTimes)
+	}
+
+	must(t, m.SetFolderSchedule(fcfg.ID, nil))
+	if cfg, _ := w.Folder(fcfg.ID); !cfg.Paused || len(cfg.ActiveTimes) != 0 {
+		t.Fatal("expected the folder to stay paused without a schedule, got", cfg.Paused, cfg.ActiveTimes)
+	}
+}
+
+func TestApplyFolderSchedules(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	fcfg.ActiveTimes = []string{"Mon 10:00-12:00"}
+	setFolder(t, w, fcfg)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	setPaused := func(paused bool) {
+		t.Helper()
+		waiter, err := w.Modify(func(cfg *config.Configuration) {
+			f, i, _ := cfg.Folder(fcfg.ID)
+			f.Paused = paused
+			cfg.Folders[i] = f
+		})
+		must(t, err)
+		waiter.Wait()
+	}
+	awaitPaused := func(paused bool) {
+		t.Helper()
+		for range 100 {
+			if cfg, _ := w.Folder(fcfg.ID); cfg.Paused == paused {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("expected the folder to be paused:", paused)
+	}
+
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 12, hour, minute, 0, 0, time.Local)
+	}
+
+	m.applyFolderSchedules(monday(11, 0))
+	awaitPaused(false)
+	m.applyFolderSchedules(monday(12, 30))
+	awaitPaused(true)
+
+	// Resuming by hand lasts until the schedule changes again.
+	setPaused(false)
+	m.applyFolderSchedules(monday(13, 0))
+	time.Sleep(50 * time.Millisecond)
+	awaitPaused(false)
+	m.applyFolderSchedules(monday(10, 30).AddDate(0, 0, 7))
+	m.applyFolderSchedules(monday(12, 30).AddDate(0, 0, 7))
+	awaitPaused(true)
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/syncthing/syncthing/lib/events"
 	"github.com/syncthing/syncthing/lib/ignore"
 	"github.com/syncthing/syncthing/lib/protocol"
-	"github.com/syncthing/syncthing/lib/semaphore"
 	"github.com/syncthing/syncthing/lib/versioner"
 )
 
@@ -26,9 +25,9 @@ type sendOnlyFolder struct {
 	*folder
 }
 
-func newSendOnlyFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, _ versioner.Versioner, evLogger events.Logger, ioLimiter *semaphore.Semaphore) service {
+func newSendOnlyFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, _ versioner.Versioner, evLogger events.Logger, sched *scheduler) service {
 	f := &sendOnlyFolder{
-		folder: newFolder(model, ignores, cfg, evLogger, ioLimiter, nil),
+		folder: newFolder(model, ignores, cfg, evLogger, sched, nil),
 	}
 	f.puller = f
 	return f
@@ -73,10 +72,12 @@ func (f *sendOnlyFolder) pull(ctx context.Context) (bool, error) {
 		}
 
 		if !file.IsEquivalentOptional(curFile, protocol.FileInfoComparison{
-			ModTimeWindow:   f.modTimeWindow,
-			IgnorePerms:     f.IgnorePerms,
-			IgnoreOwnership: !f.SyncOwnership,
-			IgnoreXattrs:    !f.SyncXattrs,
+			ModTimeWindow:          f.modTimeWindow,
+			IgnorePerms:            f.IgnorePerms,
+			IgnoreOwnership:        !f.SyncOwnership,
+			IgnoreXattrs:           !f.SyncXattrs,
+			IgnorePosixACLs:        !f.SyncPosixACLs,
+			IgnoreAlternateStreams: !f.SyncAlternateStreams,
 		}) {
 			continue
 		}
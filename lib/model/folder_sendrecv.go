@@ -21,8 +21,11 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/syncthing/syncthing/internal/itererr"
 	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/internal/tracing"
 	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/events"
@@ -129,19 +132,27 @@ type sendReceiveFolder struct {
 	queue              *jobQueue
 	blockPullReorderer blockPullReorderer
 	writeLimiter       *semaphore.Semaphore
+	pullPriority       *pullPriority // nil if the folder has no priority patterns
 
-	tempPullErrors map[string]string // pull errors that might be just transient
+	tempPullErrors map[string]FileError // pull errors that might be just transient
 }
 
-func newSendReceiveFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, ver versioner.Versioner, evLogger events.Logger, ioLimiter *semaphore.Semaphore) service {
+func newSendReceiveFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, ver versioner.Versioner, evLogger events.Logger, sched *scheduler) service {
 	f := &sendReceiveFolder{
-		folder:             newFolder(model, ignores, cfg, evLogger, ioLimiter, ver),
+		folder:             newFolder(model, ignores, cfg, evLogger, sched, ver),
 		queue:              newJobQueue(),
 		blockPullReorderer: newBlockPullReorderer(cfg.BlockPullOrder, model.id, cfg.DeviceIDs()),
 		writeLimiter:       semaphore.New(cfg.MaxConcurrentWrites),
+		pullPriority:       newPullPriority(cfg),
 	}
 	f.puller = f
 
+	// Blocks of encrypted folders are not identified by their plaintext
+	// hash, so can't be found in a seed.
+	if cfg.SeedPath != "" && cfg.Type != config.FolderTypeReceiveEncrypted {
+		f.seed = newSeedIndex(cfg.SeedPath)
+	}
+
 	if f.Copiers == 0 {
 		f.Copiers = defaultCopiers
 	}
@@ -212,12 +223,9 @@ func (f *sendReceiveFolder) pull(ctx context.Context) (bool, error) {
 	pullErrNum := len(f.tempPullErrors)
 	if pullErrNum > 0 {
 		f.pullErrors = make([]FileError, 0, len(f.tempPullErrors))
-		for path, err := range f.tempPullErrors {
-			f.sl.WarnContext(ctx, "Failed to sync", slogutil.FilePath(path), slogutil.Error(err))
-			f.pullErrors = append(f.pullErrors, FileError{
-				Err:  err,
-				Path: path,
-			})
+		for path, fe := range f.tempPullErrors {
+			f.sl.WarnContext(ctx, "Failed to sync", slogutil.FilePath(path), slogutil.Error(fe.Err))
+			f.pullErrors = append(f.pullErrors, fe)
 		}
 		f.tempPullErrors = nil
 	}
@@ -241,7 +249,7 @@ func (f *sendReceiveFolder) pull(ctx context.Context) (bool, error) {
 // flagged as needed in the folder.
 func (f *sendReceiveFolder) pullerIteration(ctx context.Context, scanChan chan<- string) (int, error) {
 	f.errorsMut.Lock()
-	f.tempPullErrors = make(map[string]string)
+	f.tempPullErrors = make(map[string]FileError)
 	f.errorsMut.Unlock()
 
 	pullChan := make(chan pullBlockState)
@@ -345,7 +353,7 @@ loop:
 			f.sl.DebugContext(ctx, "Handling ignored file", file.LogAttr())
 			dbUpdateChan <- dbUpdateJob{file, dbUpdateInvalidate}
 
-		case build.IsWindows && fs.WindowsInvalidFilename(file.Name) != nil:
+		case build.IsWindows && !f.SanitizeFilenames && fs.WindowsInvalidFilename(file.Name) != nil:
 			if file.IsDeleted() {
 				// Just pretend we deleted it, no reason to create an error
 				// about a deleted file that we can't have anyway.
@@ -432,6 +440,12 @@ loop:
 	default:
 	}
 
+	// Pull the files matching the priority patterns first or last, in the
+	// pull order otherwise.
+	if f.pullPriority != nil {
+		f.queue.SortByRank(f.pullPriority.rank)
+	}
+
 	// Process the file queue.
 
 nextFile:
@@ -574,7 +588,7 @@ func (f *sendReceiveFolder) handleDir(file protocol.FileInfo, dbUpdateChan chan<
 		})
 	}()
 
-	mode := fs.FileMode(file.Permissions & 0o777)
+	mode := f.localMode(file)
 	if f.IgnorePerms || file.NoPermissions {
 		mode = 0o777
 	}
@@ -611,7 +625,7 @@ func (f *sendReceiveFolder) handleDir(file protocol.FileInfo, dbUpdateChan chan<
 				return f.moveForConflict(name, file.ModifiedBy.String(), scanChan)
 			}, curFile.Name)
 		} else {
-			err = f.deleteItemOnDisk(curFile, scanChan)
+			err = f.replaceItemOnDisk(curFile, file, scanChan)
 		}
 		if err != nil {
 			f.newPullError(file.Name, err)
@@ -809,7 +823,7 @@ func (f *sendReceiveFolder) handleSymlinkCheckExisting(file protocol.FileInfo, s
 			return f.moveForConflict(name, file.ModifiedBy.String(), scanChan)
 		}, curFile.Name)
 	} else {
-		return f.deleteItemOnDisk(curFile, scanChan)
+		return f.replaceItemOnDisk(curFile, file, scanChan)
 	}
 }
 
@@ -931,7 +945,9 @@ func (f *sendReceiveFolder) deleteFileWithCurrent(file, cur protocol.FileInfo, h
 
 	case f.versioner != nil && !cur.IsSymlink():
 		// If we have a versioner, use that to move the file away
-		err = f.inWritableDir(f.versioner.Archive, file.Name)
+		err = f.inWritableDir(func(name string) error {
+			return f.archive(name, cur, file)
+		}, file.Name)
 
 	default:
 		// Delete the file
@@ -1036,14 +1052,17 @@ func (f *sendReceiveFolder) renameFile(cur, source, target protocol.FileInfo, db
 		err = errModified
 	default:
 		var fi protocol.FileInfo
-		if fi, err = scanner.CreateFileInfo(stat, target.Name, f.mtimefs, f.SyncOwnership, f.SyncXattrs, f.XattrFilter); err == nil {
+		if fi, err = scanner.CreateFileInfo(stat, target.Name, f.mtimefs, f.SyncOwnership, f.SyncXattrs, f.SyncPosixACLs, f.SyncAlternateStreams, f.XattrFilter, f.AlternateStreamFilter); err == nil {
+			scanner.UnmapOwnership(&fi, curTarget, f.OwnershipMapping)
 			if !fi.IsEquivalentOptional(curTarget, protocol.FileInfoComparison{
-				ModTimeWindow:   f.modTimeWindow,
-				IgnorePerms:     f.IgnorePerms,
-				IgnoreBlocks:    true,
-				IgnoreFlags:     protocol.LocalAllFlags,
-				IgnoreOwnership: !f.SyncOwnership,
-				IgnoreXattrs:    !f.SyncXattrs,
+				ModTimeWindow:          f.modTimeWindow,
+				IgnorePerms:            f.IgnorePerms,
+				IgnoreBlocks:           true,
+				IgnoreFlags:            protocol.LocalAllFlags,
+				IgnoreOwnership:        !f.SyncOwnership,
+				IgnoreXattrs:           !f.SyncXattrs,
+				IgnorePosixACLs:        !f.SyncPosixACLs,
+				IgnoreAlternateStreams: !f.SyncAlternateStreams,
 			}) {
 				// Target changed
 				scanChan <- target.Name
@@ -1062,7 +1081,9 @@ func (f *sendReceiveFolder) renameFile(cur, source, target protocol.FileInfo, db
 		if err == nil {
 			err = osutil.Copy(f.CopyRangeMethod.ToFS(), f.mtimefs, f.mtimefs, source.Name, tempName)
 			if err == nil {
-				err = f.inWritableDir(f.versioner.Archive, source.Name)
+				err = f.inWritableDir(func(name string) error {
+					return f.archive(name, cur, source)
+				}, source.Name)
 			}
 		}
 	} else {
@@ -1166,6 +1187,8 @@ func (f *sendReceiveFolder) handleFile(ctx context.Context, file protocol.FileIn
 	})
 
 	s := newSharedPullerState(file, f.mtimefs, f.folderID, tempName, blocks, reused, f.IgnorePerms || file.NoPermissions, hasCurFile, curFile, !f.DisableSparseFiles, !f.DisableFsync)
+	_, s.span = tracing.Start(ctx, "pull file", tracing.Folder(f.folderID), tracing.File(file.Name),
+		attribute.Int64("syncthing.size", file.Size), attribute.Int("syncthing.blocks", len(file.Blocks)))
 
 	f.sl.DebugContext(ctx, "Handling file", slogutil.FilePath(file.Name), "blocksToCopy", len(blocks), "reused", len(reused))
 
@@ -1179,6 +1202,25 @@ func (f *sendReceiveFolder) handleFile(ctx context.Context, file protocol.FileIn
 }
 
 func (f *sendReceiveFolder) reuseBlocks(ctx context.Context, blocks []protocol.BlockInfo, reused []int, file protocol.FileInfo, tempName string) ([]protocol.BlockInfo, []int) {
+	// If we know which blocks are done from an interrupted pull, there's
+	// no need to hash the temporary file.
+	if available, ok := f.resumedBlocks(file, tempName); ok {
+		done := make(map[int]struct{}, len(available))
+		for _, i := range available {
+			done[i] = struct{}{}
+		}
+		blocks = blocks[:0]
+		for i, block := range file.Blocks {
+			if _, ok := done[i]; ok {
+				reused = append(reused, i)
+			} else {
+				blocks = append(blocks, block)
+			}
+		}
+		f.sl.DebugContext(ctx, "Resuming interrupted pull", slogutil.FilePath(file.Name), "reused", len(reused))
+		return blocks, reused
+	}
+
 	// Check for an old temporary file which might have some blocks we could
 	// reuse.
 	tempBlocks, err := scanner.HashFile(ctx, f.ID, f.mtimefs, tempName, file.BlockSize(), nil)
@@ -1287,7 +1329,7 @@ func (f *sendReceiveFolder) shortcutFile(file protocol.FileInfo, dbUpdateChan ch
 	f.queue.Done(file.Name)
 
 	if !f.IgnorePerms && !file.NoPermissions {
-		if err = f.mtimefs.Chmod(file.Name, fs.FileMode(file.Permissions&0o777)); err != nil {
+		if err = f.mtimefs.Chmod(file.Name, f.localMode(file)); err != nil {
 			f.newPullError(file.Name, fmt.Errorf("shortcut file (setting permissions): %w", err))
 			return
 		}
@@ -1416,6 +1458,13 @@ func (f *sendReceiveFolder) copyBlock(ctx context.Context, block protocol.BlockI
 		}
 	}
 
+	if f.seed != nil {
+		if name, offset, ok := f.seed.lookup(block.Hash, state.file); ok && f.copyBlockFromFile(ctx, name, offset, state, f.seed.fs, block, buf) {
+			state.copiedFromElsewhere(block.Size)
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -1534,11 +1583,21 @@ func (f *sendReceiveFolder) pullerRoutine(ctx context.Context, in <-chan pullBlo
 			continue
 		}
 
+		// The block data is also taken from the folder's memory budget,
+		// which may be shared with other folders.
+		if err := f.memPull.TakeWithContext(ctx, bytes); err != nil {
+			requestLimiter.Give(bytes)
+			state.fail(err)
+			out <- state.sharedPullerState
+			continue
+		}
+
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
 			defer requestLimiter.Give(bytes)
+			defer f.memPull.Give(bytes)
 
 			f.pullBlock(ctx, state, out)
 		}()
@@ -1575,9 +1634,9 @@ loop:
 		default:
 		}
 
-		// Select the least busy device to pull the block from. If we found no
-		// feasible device at all, fail the block (and in the long run, the
-		// file).
+		// Select the device expected to answer the soonest to pull the
+		// block from. If we found no feasible device at all, fail the block
+		// (and in the long run, the file).
 		found := activity.leastBusy(candidates)
 		if found == -1 {
 			if lastError != nil {
@@ -1597,7 +1656,12 @@ loop:
 		activity.using(selected)
 		var buf []byte
 		blockNo := int(state.block.Offset / int64(state.file.BlockSize()))
-		buf, lastError = f.model.RequestGlobal(ctx, selected.ID, f.folderID, state.file.Name, blockNo, state.block.Offset, state.block.Size, state.block.Hash, selected.FromTemporary)
+		reqCtx, span := tracing.Start(state.traceContext(ctx), "pull block", tracing.Device(selected.ID),
+			attribute.Int("syncthing.block", blockNo), attribute.Int("syncthing.size", state.block.Size))
+		t0 := time.Now()
+		buf, lastError = f.model.RequestGlobal(reqCtx, selected.ID, f.folderID, state.file.Name, blockNo, state.block.Offset, state.block.Size, state.block.Hash, selected.FromTemporary, false)
+		tracing.End(span, lastError)
+		activity.observe(selected.ID, state.block.Size, time.Since(t0), lastError)
 		activity.done(selected)
 		if lastError != nil {
 			f.sl.DebugContext(ctx, "Block request returned error", slogutil.FilePath(state.file.Name), "offset", state.block.Offset, "size", state.block.Size, "device", selected.ID.Short(), slogutil.Error(lastError))
@@ -1618,6 +1682,14 @@ loop:
 			continue
 		}
 
+		metricFolderDeviceBytesTotal.WithLabelValues(f.folderID, selected.ID.String(), metricDirectionPulled).Add(float64(len(buf)))
+
+		// Blocks compressed before encryption are padded with zeroes,
+		// which we leave as holes in the sparse temporary file.
+		if f.Type == config.FolderTypeReceiveEncrypted && !f.DisableSparseFiles && state.reused == 0 {
+			buf = buf[:len(buf)-protocol.CompressedPadding(buf)]
+		}
+
 		// Save the block data we got from the cluster
 		err = f.limitedWriteAt(ctx, fd, buf, state.block.Offset)
 		if err != nil {
@@ -1633,7 +1705,7 @@ loop:
 func (f *sendReceiveFolder) performFinish(file, curFile protocol.FileInfo, hasCurFile bool, tempName string, dbUpdateChan chan<- dbUpdateJob, scanChan chan<- string) error {
 	// Set the correct permission bits on the new file
 	if !f.IgnorePerms && !file.NoPermissions {
-		if err := f.mtimefs.Chmod(tempName, fs.FileMode(file.Permissions&0o777)); err != nil {
+		if err := f.mtimefs.Chmod(tempName, f.localMode(file)); err != nil {
 			return fmt.Errorf("setting permissions: %w", err)
 		}
 	}
@@ -1661,7 +1733,7 @@ func (f *sendReceiveFolder) performFinish(file, curFile protocol.FileInfo, hasCu
 				return f.moveForConflict(name, file.ModifiedBy.String(), scanChan)
 			}, curFile.Name)
 		} else {
-			err = f.deleteItemOnDisk(curFile, scanChan)
+			err = f.replaceItemOnDisk(curFile, file, scanChan)
 		}
 		if err != nil {
 			return fmt.Errorf("moving for conflict: %w", err)
@@ -1695,9 +1767,12 @@ func (f *sendReceiveFolder) finisherRoutine(ctx context.Context, in <-chan *shar
 				err = f.performFinish(state.file, state.curFile, state.hasCurFile, state.tempName, dbUpdateChan, scanChan)
 			}
 
+			state.endSpan(err)
 			if err != nil {
 				f.newPullError(state.file.Name, fmt.Errorf("finishing: %w", err))
+				f.savePullProgress(state)
 			} else {
+				f.clearPullProgress(state.file.Name)
 				slog.InfoContext(ctx, "Synced file", f.LogAttr(), state.file.LogAttr(), slog.Group("blocks", slog.Int("local", state.reused+state.copyTotal), slog.Int("download", state.pullTotal)))
 
 				minBlocksPerBlock := state.file.BlockSize() / protocol.MinBlockSize
@@ -1767,6 +1842,7 @@ func (f *sendReceiveFolder) dbUpdaterRoutine(dbUpdateChan <-chan dbUpdateJob) in
 		// All updates to file/folder objects that originated remotely
 		// (across the network) use this call to updateLocals
 		f.updateLocalsFromPulling(files)
+		f.archiveReceived(files)
 
 		if found {
 			f.ReceivedFile(lastFile.Name, lastFile.IsDeleted())
@@ -1775,6 +1851,7 @@ func (f *sendReceiveFolder) dbUpdaterRoutine(dbUpdateChan <-chan dbUpdateJob) in
 
 		return nil
 	})
+	batch.SetMemoryBudget(f.memIndex)
 
 loop:
 	for {
@@ -1867,6 +1944,7 @@ func (f *sendReceiveFolder) moveForConflict(name, lastModBy string, scanChan cha
 	metricFolderConflictsTotal.WithLabelValues(f.ID).Inc()
 	newName := conflictName(name, lastModBy)
 	err := f.mtimefs.Rename(name, newName)
+	moved := err == nil
 	if fs.IsNotExist(err) {
 		// We were supposed to move a file away but it does not exist. Either
 		// the user has already moved it away, or the conflict was between a
@@ -1887,6 +1965,14 @@ func (f *sendReceiveFolder) moveForConflict(name, lastModBy string, scanChan cha
 			}
 		}
 	}
+	if moved {
+		f.evLogger.Log(events.ConflictCreated, map[string]string{
+			"folder":       f.folderID,
+			"item":         name,
+			"conflictCopy": newName,
+			"modifiedBy":   lastModBy,
+		})
+	}
 	if err == nil {
 		scanChan <- newName
 	}
@@ -1912,14 +1998,25 @@ func (f *sendReceiveFolder) newPullError(path string, err error) {
 	// Establish context to differentiate from errors while scanning.
 	// Use "syncing" as opposed to "pulling" as the latter might be used
 	// for errors occurring specifically in the puller routine.
-	errStr := fmt.Sprintf("syncing: %s", err)
-	f.tempPullErrors[path] = errStr
+	category := fileErrorCategory(err)
+	f.tempPullErrors[path] = FileError{
+		Path:     path,
+		Err:      fmt.Sprintf("syncing: %s", err),
+		Category: category,
+	}
+	metricFolderPullErrorsTotal.WithLabelValues(f.folderID, category).Inc()
 
 	f.sl.Debug("New pull error", slogutil.FilePath(path), slogutil.Error(err))
 }
 
-// deleteItemOnDisk deletes the file represented by old that is about to be replaced by new.
-func (f *sendReceiveFolder) deleteItemOnDisk(item protocol.FileInfo, scanChan chan<- string) (err error) {
+// deleteItemOnDisk deletes the file represented by item.
+func (f *sendReceiveFolder) deleteItemOnDisk(item protocol.FileInfo, scanChan chan<- string) error {
+	return f.replaceItemOnDisk(item, protocol.FileInfo{Name: item.Name, Deleted: true}, scanChan)
+}
+
+// replaceItemOnDisk deletes the file represented by item that is about to
+// be replaced by file.
+func (f *sendReceiveFolder) replaceItemOnDisk(item, file protocol.FileInfo, scanChan chan<- string) (err error) {
 	defer func() {
 		if err != nil {
 			err = fmt.Errorf("%s: %w", contextRemovingOldItem, err)
@@ -1938,12 +2035,35 @@ func (f *sendReceiveFolder) deleteItemOnDisk(item protocol.FileInfo, scanChan ch
 		// an error.
 		// Symlinks aren't archived.
 
-		return f.inWritableDir(f.versioner.Archive, item.Name)
+		return f.inWritableDir(func(name string) error {
+			return f.archive(name, item, file)
+		}, item.Name)
 	}
 
 	return f.inWritableDir(f.mtimefs.Remove, item.Name)
 }
 
+// archive lets the versioner archive the named file, the current version
+// of which is cur, as it is replaced by file.
+func (f *sendReceiveFolder) archive(name string, cur, file protocol.FileInfo) error {
+	return versioner.ArchiveWithMetadata(f.versioner, name, versioner.ArchiveMetadata{
+		Folder:     f.folderID,
+		Path:       name,
+		OldVersion: versionCounters(cur.Version),
+		NewVersion: versionCounters(file.Version),
+		ModifiedBy: file.ModifiedBy.String(),
+		Conflict:   cur.Version.Concurrent(file.Version),
+	})
+}
+
+func versionCounters(v protocol.Vector) map[string]uint64 {
+	counters := make(map[string]uint64, len(v.Counters))
+	for _, c := range v.Counters {
+		counters[c.ID.String()] = c.Value
+	}
+	return counters
+}
+
 // deleteDirOnDisk attempts to delete a directory. It checks for files/dirs inside
 // the directory and removes them if possible or returns an error if it fails
 func (f *sendReceiveFolder) deleteDirOnDisk(dir string, scanChan chan<- string) error {
@@ -2014,20 +2134,23 @@ func (f *sendReceiveFolder) deleteDirOnDiskHandleChildren(dir string, scanChan c
 			hasReceiveOnlyChanged = true
 			return nil
 		}
-		diskFile, err := scanner.CreateFileInfo(info, path, f.mtimefs, f.SyncOwnership, f.SyncXattrs, f.XattrFilter)
+		diskFile, err := scanner.CreateFileInfo(info, path, f.mtimefs, f.SyncOwnership, f.SyncXattrs, f.SyncPosixACLs, f.SyncAlternateStreams, f.XattrFilter, f.AlternateStreamFilter)
 		if err != nil {
 			// Lets just assume the file has changed.
 			scanChan <- path
 			hasToBeScanned = true
 			return nil //nolint:nilerr
 		}
+		scanner.UnmapOwnership(&diskFile, cf, f.OwnershipMapping)
 		if !cf.IsEquivalentOptional(diskFile, protocol.FileInfoComparison{
-			ModTimeWindow:   f.modTimeWindow,
-			IgnorePerms:     f.IgnorePerms,
-			IgnoreBlocks:    true,
-			IgnoreFlags:     protocol.LocalAllFlags,
-			IgnoreOwnership: !f.SyncOwnership,
-			IgnoreXattrs:    !f.SyncXattrs,
+			ModTimeWindow:          f.modTimeWindow,
+			IgnorePerms:            f.IgnorePerms,
+			IgnoreBlocks:           true,
+			IgnoreFlags:            protocol.LocalAllFlags,
+			IgnoreOwnership:        !f.SyncOwnership,
+			IgnoreXattrs:           !f.SyncXattrs,
+			IgnorePosixACLs:        !f.SyncPosixACLs,
+			IgnoreAlternateStreams: !f.SyncAlternateStreams,
 		}) {
 			// File on disk changed compared to what we have in db
 			// -> schedule scan.
@@ -2092,17 +2215,20 @@ func (f *sendReceiveFolder) scanIfItemChanged(name string, stat fs.FileInfo, ite
 	// to the database. If there's a mismatch here, there might be local
 	// changes that we don't know about yet and we should scan before
 	// touching the item.
-	statItem, err := scanner.CreateFileInfo(stat, item.Name, f.mtimefs, f.SyncOwnership, f.SyncXattrs, f.XattrFilter)
+	statItem, err := scanner.CreateFileInfo(stat, item.Name, f.mtimefs, f.SyncOwnership, f.SyncXattrs, f.SyncPosixACLs, f.SyncAlternateStreams, f.XattrFilter, f.AlternateStreamFilter)
 	if err != nil {
 		return fmt.Errorf("comparing item on disk to db: %w", err)
 	}
+	scanner.UnmapOwnership(&statItem, item, f.OwnershipMapping)
 	if !statItem.IsEquivalentOptional(item, protocol.FileInfoComparison{
-		ModTimeWindow:   f.modTimeWindow,
-		IgnorePerms:     f.IgnorePerms,
-		IgnoreBlocks:    true,
-		IgnoreFlags:     protocol.LocalAllFlags,
-		IgnoreOwnership: fromDelete || !f.SyncOwnership,
-		IgnoreXattrs:    fromDelete || !f.SyncXattrs,
+		ModTimeWindow:          f.modTimeWindow,
+		IgnorePerms:            f.IgnorePerms,
+		IgnoreBlocks:           true,
+		IgnoreFlags:            protocol.LocalAllFlags,
+		IgnoreOwnership:        fromDelete || !f.SyncOwnership,
+		IgnoreXattrs:           fromDelete || !f.SyncXattrs,
+		IgnorePosixACLs:        fromDelete || !f.SyncPosixACLs,
+		IgnoreAlternateStreams: fromDelete || !f.SyncAlternateStreams,
 	}) {
 		return errModified
 	}
@@ -2149,6 +2275,18 @@ func (f *sendReceiveFolder) setPlatformData(file *protocol.FileInfo, name string
 		}
 	}
 
+	if f.SyncAlternateStreams && !file.IsSymlink() {
+		var streams []protocol.AlternateStream
+		if file.Platform.AlternateStreams != nil {
+			streams = file.Platform.AlternateStreams.Streams
+		}
+		if err := fs.SetAlternateStreams(f.mtimefs, name, streams, f.AlternateStreamFilter); errors.Is(err, fs.ErrAlternateStreamsNotSupported) {
+			f.sl.Debug("Cannot set alternate streams (not supported)", slogutil.FilePath(file.Name), slogutil.Error(err))
+		} else if err != nil {
+			return err
+		}
+	}
+
 	if f.SyncOwnership {
 		// Set ownership based on file metadata.
 		if err := f.syncOwnership(file, name); err != nil {
@@ -2161,9 +2299,22 @@ func (f *sendReceiveFolder) setPlatformData(file *protocol.FileInfo, name string
 		}
 	}
 
+	if f.SyncPosixACLs && !file.IsSymlink() {
+		// Set the ACLs last, as changing ownership or permissions may
+		// change them.
+		if err := f.syncPosixACL(file, name); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// localMode returns the permission bits the file gets here.
+func (f *sendReceiveFolder) localMode(file protocol.FileInfo) fs.FileMode {
+	return fs.FileMode(f.OwnershipMapping.MapPermissions(file.Permissions) & 0o777)
+}
+
 func (f *sendReceiveFolder) copyOwnershipFromParent(path string) error {
 	if build.IsWindows {
 		// Can't do anything.
@@ -2219,6 +2370,8 @@ func (f *sendReceiveFolder) updateFileInfoChangeTime(file *protocol.FileInfo) er
 type FileError struct {
 	Path string `json:"path"`
 	Err  string `json:"error"`
+	// Category is the kind of error; see fileErrorCategory.
+	Category string `json:"category,omitempty"`
 }
 
 func conflictName(name, lastModBy string) string {
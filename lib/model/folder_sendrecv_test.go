@@ -104,7 +104,7 @@ func createEmptyFileInfo(t *testing.T, name string, fs fs.Filesystem) protocol.F
 	writeFile(t, fs, name, nil)
 	fi, err := fs.Stat(name)
 	must(t, err)
-	file, err := scanner.CreateFileInfo(fi, name, fs, false, false, config.XattrFilter{})
+	file, err := scanner.CreateFileInfo(fi, name, fs, false, false, false, false, config.XattrFilter{}, config.AlternateStreamFilter{})
 	must(t, err)
 	return file
 }
@@ -118,7 +118,7 @@ func setupSendReceiveFolder(t testing.TB, files ...protocol.FileInfo) (*testMode
 	<-model.stopped
 	r, _ := model.folderRunners.Get(fcfg.ID)
 	f := r.(*sendReceiveFolder)
-	f.tempPullErrors = make(map[string]string)
+	f.tempPullErrors = make(map[string]FileError)
 
 	// Update index
 	if files != nil {
@@ -646,7 +646,7 @@ func TestDeleteIgnorePerms(t *testing.T) {
 
 	stat, err := file.Stat()
 	must(t, err)
-	fi, err := scanner.CreateFileInfo(stat, name, ffs, false, false, config.XattrFilter{})
+	fi, err := scanner.CreateFileInfo(stat, name, ffs, false, false, false, false, config.XattrFilter{}, config.AlternateStreamFilter{})
 	must(t, err)
 	ffs.Chmod(name, 0o600)
 	if info, err := ffs.Stat(name); err == nil {
@@ -1061,10 +1061,12 @@ func testPullCaseOnlyDirOrSymlink(t *testing.T, dir bool) {
 		t.Error("no need to scan anything here")
 	default:
 	}
-	if errStr, ok := f.tempPullErrors[remote.Name]; !ok {
+	if fe, ok := f.tempPullErrors[remote.Name]; !ok {
 		t.Error("missing error for", remote.Name)
-	} else if !strings.Contains(errStr, "uses different upper or lowercase") {
-		t.Error("unexpected error", errStr, "for", remote.Name)
+	} else if !strings.Contains(fe.Err, "uses different upper or lowercase") {
+		t.Error("unexpected error", fe.Err, "for", remote.Name)
+	} else if fe.Category != ErrorCategoryCaseConflict {
+		t.Error("unexpected error category", fe.Category, "for", remote.Name)
 	}
 }
 
@@ -10,9 +10,13 @@
 package model
 
 import (
+	"errors"
 	"os/user"
 	"strconv"
+	"syscall"
 
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/fs"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
 
@@ -22,20 +26,23 @@ func (f *sendReceiveFolder) syncOwnership(file *protocol.FileInfo, path string)
 		return nil
 	}
 
-	// Try to look up the user and group by name, defaulting to the
-	// numerical UID and GID if there is no match.
+	// Map the owner and group to local ones, if so configured, then try to
+	// look up the user and group by name, defaulting to the numerical UID
+	// and GID if there is no match.
 
-	uid := strconv.Itoa(file.Platform.Unix.UID)
-	if file.Platform.Unix.OwnerName != "" {
-		us, err := user.Lookup(file.Platform.Unix.OwnerName)
+	ownerName, ownerID := f.OwnershipMapping.MapOwner(file.Platform.Unix.OwnerName, file.Platform.Unix.UID)
+	uid := strconv.Itoa(ownerID)
+	if ownerName != "" {
+		us, err := user.Lookup(ownerName)
 		if err == nil && us.Uid != "" {
 			uid = us.Uid
 		}
 	}
 
-	gid := strconv.Itoa(file.Platform.Unix.GID)
-	if file.Platform.Unix.GroupName != "" {
-		gr, err := user.LookupGroup(file.Platform.Unix.GroupName)
+	groupName, groupID := f.OwnershipMapping.MapGroup(file.Platform.Unix.GroupName, file.Platform.Unix.GID)
+	gid := strconv.Itoa(groupID)
+	if groupName != "" {
+		gr, err := user.LookupGroup(groupName)
 		if err == nil && gr.Gid != "" {
 			gid = gr.Gid
 		}
@@ -43,3 +50,52 @@ func (f *sendReceiveFolder) syncOwnership(file *protocol.FileInfo, path string)
 
 	return f.mtimefs.Lchown(path, uid, gid)
 }
+
+func (f *sendReceiveFolder) syncPosixACL(file *protocol.FileInfo, path string) error {
+	acl := file.Platform.PosixACL
+	if acl == nil {
+		// No ACL data, nothing to do
+		return nil
+	}
+
+	local := &protocol.PosixACL{
+		Access:  f.localPosixACLEntries(acl.Access),
+		Default: f.localPosixACLEntries(acl.Default),
+	}
+	err := fs.SetPosixACL(f.mtimefs, path, local)
+	if errors.Is(err, fs.ErrXattrsNotSupported) || errors.Is(err, syscall.ENOTSUP) {
+		// The permission bits are what we can do.
+		f.sl.Debug("Cannot set POSIX ACLs (not supported)", slogutil.FilePath(file.Name), slogutil.Error(err))
+		return nil
+	}
+	return err
+}
+
+// localPosixACLEntries translates the users and groups of named entries
+// like the owner and group of files, mapping them if so configured and
+// looking them up by name, defaulting to the numerical ID.
+func (f *sendReceiveFolder) localPosixACLEntries(entries []protocol.PosixACLEntry) []protocol.PosixACLEntry {
+	local := make([]protocol.PosixACLEntry, len(entries))
+	for i, e := range entries {
+		local[i] = e
+		switch e.Tag {
+		case protocol.PosixACLTagUser:
+			name, id := f.OwnershipMapping.MapOwner(e.Name, e.ID)
+			local[i].Name, local[i].ID = name, id
+			if us, err := user.Lookup(name); name != "" && err == nil {
+				if uid, err := strconv.Atoi(us.Uid); err == nil {
+					local[i].ID = uid
+				}
+			}
+		case protocol.PosixACLTagGroup:
+			name, id := f.OwnershipMapping.MapGroup(e.Name, e.ID)
+			local[i].Name, local[i].ID = name, id
+			if gr, err := user.LookupGroup(name); name != "" && err == nil {
+				if gid, err := strconv.Atoi(gr.Gid); err == nil {
+					local[i].ID = gid
+				}
+			}
+		}
+	}
+	return local
+}
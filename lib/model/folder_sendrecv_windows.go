@@ -20,8 +20,19 @@ func (f *sendReceiveFolder) syncOwnership(file *protocol.FileInfo, path string)
 		return nil
 	}
 
-	l.Debugf("Owner name for %s is %s (group=%v)", path, file.Platform.Windows.OwnerName, file.Platform.Windows.OwnerIsGroup)
-	usid, gsid, err := lookupUserAndGroup(file.Platform.Windows.OwnerName, file.Platform.Windows.OwnerIsGroup)
+	ownerName := file.Platform.Windows.OwnerName
+	if file.Platform.Windows.OwnerIsGroup {
+		ownerName, _ = f.OwnershipMapping.MapGroup(ownerName, -1)
+	} else {
+		ownerName, _ = f.OwnershipMapping.MapOwner(ownerName, -1)
+	}
+	if ownerName == "" {
+		// Mapped to a numeric ID, which means nothing here.
+		return nil
+	}
+
+	l.Debugf("Owner name for %s is %s (group=%v)", path, ownerName, file.Platform.Windows.OwnerIsGroup)
+	usid, gsid, err := lookupUserAndGroup(ownerName, file.Platform.Windows.OwnerIsGroup)
 	if err != nil {
 		return err
 	}
@@ -30,6 +41,11 @@ func (f *sendReceiveFolder) syncOwnership(file *protocol.FileInfo, path string)
 	return f.mtimefs.Lchown(path, usid, gsid)
 }
 
+func (*sendReceiveFolder) syncPosixACL(*protocol.FileInfo, string) error {
+	// POSIX ACLs don't exist here; the permission bits are what we can do.
+	return nil
+}
+
 func lookupUserAndGroup(name string, group bool) (string, string, error) {
 	// Look up either the the user or the group, returning the other kind as
 	// blank. This might seem an odd maneuver, but it matches what Chown
@@ -116,6 +116,14 @@ type FolderSummary struct {
 
 	IgnorePatterns bool   `json:"ignorePatterns"`
 	WatchError     string `json:"watchError"`
+
+	// Items the last full scan passed over, and the time it spent matching
+	// against the ignore patterns.
+	ScanSkippedIgnored     int     `json:"scanSkippedIgnored"`
+	ScanSkippedTemporary   int     `json:"scanSkippedTemporary"`
+	ScanSkippedInternal    int     `json:"scanSkippedInternal"`
+	ScanSkippedSpecial     int     `json:"scanSkippedSpecial"`
+	ScanIgnoreMatchSeconds float64 `json:"scanIgnoreMatchSeconds"`
 }
 
 func (c *folderSummaryService) Summary(folder string) (*FolderSummary, error) {
@@ -199,6 +207,14 @@ func (c *folderSummaryService) Summary(folder string) (*FolderSummary, error) {
 		res.WatchError = err.Error()
 	}
 
+	if skipped, err := c.model.ScanSkipped(folder); err == nil {
+		res.ScanSkippedIgnored = skipped.Ignored
+		res.ScanSkippedTemporary = skipped.Temporary
+		res.ScanSkippedInternal = skipped.Internal
+		res.ScanSkippedSpecial = skipped.Special
+		res.ScanIgnoreMatchSeconds = skipped.IgnoreMatching.Seconds()
+	}
+
 	return res, nil
 }
 
@@ -406,6 +422,11 @@ func (c *folderSummaryService) sendSummary(ctx context.Context, folder string) {
 			l.Debugf("Error getting completion for folder %v, device %v: %v", folder, devCfg.DeviceID, err)
 			continue
 		}
+		dev := devCfg.DeviceID.String()
+		metricFolderRemoteNeed.WithLabelValues(folder, dev, metricTypeItems).Set(float64(comp.NeedItems))
+		metricFolderRemoteNeed.WithLabelValues(folder, dev, metricTypeDeleted).Set(float64(comp.NeedDeletes))
+		metricFolderRemoteNeed.WithLabelValues(folder, dev, metricTypeBytes).Set(float64(comp.NeedBytes))
+
 		ev := comp.Map()
 		ev["folder"] = folder
 		ev["device"] = devCfg.DeviceID.String()
@@ -183,3 +183,20 @@ func TestSetPlatformData(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestWatcherOnlyNoRescans(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	fcfg.FSWatcherEnabled = true
+	fcfg.FSWatcherOnly = true
+	fcfg.RescanIntervalS = 3600
+	setFolder(t, w, fcfg)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	m.mut.RLock()
+	r, _ := m.folderRunners.Get(fcfg.ID)
+	m.mut.RUnlock()
+	if f := r.(*sendReceiveFolder); f.scanInterval != 0 {
+		t.Error("expected no periodic rescans, got interval", f.scanInterval)
+	}
+}
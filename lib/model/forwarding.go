@@ -0,0 +1,100 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/scanner"
+)
+
+// Devices opting in per folder forward requests for blocks they don't have
+// to a connected device that has the file, so that devices that can't
+// connect to each other, as in hub-and-spoke setups, don't need to wait
+// for the hub to sync the file first. They announce this in the cluster
+// config, and devices that have no other source for a file request it
+// from them. Forwarded requests are marked as such and never forwarded
+// again, so requests don't bounce between forwarding devices.
+
+const forwardRequestTimeout = time.Minute
+
+// forwardsRequestsFor returns whether we forward requests in the folder
+// for the given device. Untrusted devices aren't served plaintext data, and
+// receive-encrypted folders have none to verify.
+func forwardsRequestsFor(fcfg config.FolderConfiguration, device protocol.DeviceID) bool {
+	if !fcfg.ForwardRequests || fcfg.Type == config.FolderTypeReceiveEncrypted {
+		return false
+	}
+	fd, ok := fcfg.Device(device)
+	return ok && fd.EncryptionPassword == ""
+}
+
+// forwardRequest fetches the requested block from another connected device
+// that has the file into buf, and returns whether it did.
+func (m *model) forwardRequest(fcfg config.FolderConfiguration, requester protocol.DeviceID, req *protocol.Request, buf []byte) bool {
+	if req.Forwarded || req.FromTemporary || len(req.Hash) == 0 || !forwardsRequestsFor(fcfg, requester) {
+		return false
+	}
+
+	m.mut.RLock()
+	sources := m.connectedFileAvailabilityRLocked(fcfg, protocol.FileInfo{Name: req.Name})
+	m.mut.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), forwardRequestTimeout)
+	defer cancel()
+	for _, source := range sources {
+		if source.ID == requester {
+			continue
+		}
+		conn, ok := m.requestConnectionForDevice(source.ID)
+		if !ok {
+			continue
+		}
+		data, err := conn.Request(ctx, &protocol.Request{
+			Folder:      req.Folder,
+			Name:        req.Name,
+			Offset:      req.Offset,
+			Size:        req.Size,
+			Hash:        req.Hash,
+			BlockNo:     req.BlockNo,
+			Interactive: req.Interactive,
+			Forwarded:   true,
+		})
+		if err != nil {
+			l.Debugf("%v REQ(fwd) to %s failed (%v): %s: %q / %q o=%d s=%d", m, source.ID.Short(), err, requester.Short(), req.Folder, req.Name, req.Offset, req.Size)
+			continue
+		}
+		if len(data) != len(buf) || !scanner.Validate(data, req.Hash) {
+			l.Debugf("%v REQ(fwd) to %s returned invalid data: %s: %q / %q o=%d s=%d", m, source.ID.Short(), requester.Short(), req.Folder, req.Name, req.Offset, req.Size)
+			continue
+		}
+		copy(buf, data)
+		return true
+	}
+	return false
+}
+
+// forwardingAvailabilityRLocked returns the connected devices that forward
+// requests for the folder to us.
+func (m *model) forwardingAvailabilityRLocked(fcfg config.FolderConfiguration) []Availability {
+	var availabilities []Availability
+	for _, device := range fcfg.Devices {
+		if device.DeviceID == m.id || m.remoteFolderStates[device.DeviceID][fcfg.ID] != remoteFolderValid {
+			continue
+		}
+		if _, ok := m.undrainedConnectionRLocked(device.DeviceID); !ok {
+			continue
+		}
+		if m.remoteFolderInfos.get(device.DeviceID, fcfg.ID).forwardsRequests {
+			availabilities = append(availabilities, Availability{ID: device.DeviceID, Forwarding: true})
+		}
+	}
+	return availabilities
+}
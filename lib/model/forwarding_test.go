@@ -0,0 +1,102 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestForwardRequest(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	fcfg.Type = config.FolderTypeSendOnly // so that we don't pull the file ourselves
+	fcfg.ForwardRequests = true
+	addDevice2(t, w, fcfg)
+	fcfg, _ = w.Folder(fcfg.ID)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	if cc, _ := m.generateClusterConfig(device2); !cc.Folders[0].ForwardsRequests {
+		t.Error("expected the folder to be announced as forwarding requests")
+	}
+
+	contents := []byte("forwarded contents\n")
+	origin := addFakeConn(m, device1, fcfg.ID)
+	origin.addFile("file", 0o644, protocol.FileInfoTypeFile, contents)
+	origin.sendIndexUpdate()
+	requester := addFakeConn(m, device2, fcfg.ID)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for len(m.fileAvailability(fcfg, protocol.FileInfo{Name: "file"})) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the index")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	req := &protocol.Request{Folder: fcfg.ID, Name: "file", Size: len(contents), Hash: origin.files[0].Blocks[0].Hash}
+	res, err := m.Request(requester, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res.Data(), contents) {
+		t.Errorf("expected %q, got %q", contents, res.Data())
+	}
+	res.Close()
+	if origin.RequestCallCount() != 1 {
+		t.Fatalf("expected one request to the origin, got %d", origin.RequestCallCount())
+	}
+	if _, fwd := origin.RequestArgsForCall(0); !fwd.Forwarded {
+		t.Error("expected the request to the origin to be marked as forwarded")
+	}
+
+	// Forwarded requests aren't forwarded again.
+	req.Forwarded = true
+	if _, err := m.Request(requester, req); !errors.Is(err, protocol.ErrNoSuchFile) {
+		t.Errorf("expected %v, got %v", protocol.ErrNoSuchFile, err)
+	}
+	if origin.RequestCallCount() != 1 {
+		t.Error("expected a forwarded request not to be forwarded again")
+	}
+}
+
+func TestForwardingAvailability(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	addDevice2(t, w, fcfg)
+	fcfg, _ = w.Folder(fcfg.ID)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	origin := addFakeConn(m, device1, fcfg.ID)
+	origin.addFile("file", 0o644, protocol.FileInfoTypeFile, []byte("contents"))
+	origin.sendIndexUpdate()
+
+	hub := newFakeConnection(device2, m)
+	m.AddConnection(hub, protocol.Hello{})
+	m.ClusterConfig(hub, &protocol.ClusterConfig{
+		Folders: []protocol.Folder{
+			{
+				ID:               fcfg.ID,
+				ForwardsRequests: true,
+				Devices:          []protocol.Device{{ID: myID}, {ID: device2}},
+			},
+		},
+	})
+
+	// Devices that have the file are preferred over forwarding ones.
+	if av := m.fileAvailability(fcfg, protocol.FileInfo{Name: "file"}); len(av) != 1 || av[0] != (Availability{ID: device1}) {
+		t.Errorf("expected the file to be available from %v only, got %v", device1, av)
+	}
+	if av := m.fileAvailability(fcfg, protocol.FileInfo{Name: "other"}); len(av) != 1 || av[0] != (Availability{ID: device2, Forwarding: true}) {
+		t.Errorf("expected the file to be available through %v only, got %v", device2, av)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+// While globally paused, connections to all devices are closed and not
+// made or accepted by the connection service, and scans happen only on
+// request, as with suspended scans. The pause is kept in the config, so
+// that it survives restarts.
+
+// PauseAll pauses all syncing, until resumed or, with a non-zero
+// duration, until the duration has passed. The reason and originator are
+// recorded for display.
+func (m *model) PauseAll(reason, originator string, duration time.Duration) error {
+	now := time.Now()
+	pause := config.GlobalPause{
+		Paused:     true,
+		Reason:     reason,
+		Originator: originator,
+		Since:      now,
+	}
+	if duration > 0 {
+		pause.Until = now.Add(duration)
+	}
+	return m.setGlobalPause(pause)
+}
+
+// ResumeAll ends a global pause.
+func (m *model) ResumeAll() error {
+	return m.setGlobalPause(config.GlobalPause{})
+}
+
+func (m *model) setGlobalPause(pause config.GlobalPause) error {
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		cfg.Options.GlobalPause = pause
+	})
+	if err != nil {
+		return err
+	}
+	waiter.Wait()
+	return nil
+}
+
+// updateGlobalPause takes note of the global pause in the config and
+// arranges for it to end when due. It returns true if the pause just
+// started, in which case the connections are to be closed.
+func (m *model) updateGlobalPause(pause config.GlobalPause) bool {
+	if pause.Paused && !pause.Until.IsZero() {
+		m.globalResumeTimer.Reset(max(time.Until(pause.Until), 0))
+	} else {
+		m.globalResumeTimer.Stop()
+	}
+
+	paused := pause.Active(time.Now())
+	if m.globalPaused.Swap(paused) == paused {
+		return false
+	}
+	if !paused {
+		slog.Info("Resuming syncing")
+		if !m.scansSuspended.Load() {
+			m.notifyScansResumed()
+		}
+		return false
+	}
+	slog.Info("Pausing all syncing", slog.String("reason", pause.Reason), slog.String("originator", pause.Originator))
+	return true
+}
+
+// endGlobalPause ends a global pause whose time is up.
+func (m *model) endGlobalPause() {
+	pause := m.cfg.Options().GlobalPause
+	if !pause.Paused || pause.Until.IsZero() || pause.Active(time.Now()) {
+		return
+	}
+	// Modifying the config waits for us to commit it, which must not
+	// happen on the serve routine.
+	go m.cfg.Modify(func(cfg *config.Configuration) {
+		if cfg.Options.GlobalPause.Until.Equal(pause.Until) {
+			cfg.Options.GlobalPause = config.GlobalPause{}
+		}
+	})
+}
+
+// scansPaused returns true if scans due to the rescan interval or the
+// filesystem watcher are to be postponed.
+func (m *model) scansPaused() bool {
+	return m.scansSuspended.Load() || m.globalPaused.Load()
+}
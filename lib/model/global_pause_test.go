@@ -0,0 +1,47 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+func TestGlobalPause(t *testing.T) {
+	w, _ := newDefaultCfgWrapper(t)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	must(t, m.PauseAll("low battery", config.PauseOriginatorBattery, 0))
+	pause := w.Options().GlobalPause
+	if !pause.Active(time.Now()) || pause.Reason != "low battery" || pause.Originator != config.PauseOriginatorBattery || !pause.Until.IsZero() {
+		t.Fatal("unexpected global pause", pause)
+	}
+	if !m.scansPaused() {
+		t.Error("expected scans to be paused")
+	}
+
+	must(t, m.ResumeAll())
+	if w.Options().GlobalPause.Paused || m.scansPaused() {
+		t.Error("expected syncing to be resumed")
+	}
+
+	// A pause with a duration ends by itself.
+	must(t, m.PauseAll("", config.PauseOriginatorUser, 50*time.Millisecond))
+	if !m.scansPaused() {
+		t.Error("expected scans to be paused")
+	}
+	for range 100 {
+		if !w.Options().GlobalPause.Paused && !m.scansPaused() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("expected the global pause to end")
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/ignore"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// A NewlyIgnoredFile is a file we currently sync that proposed ignore
+// patterns would ignore.
+type NewlyIgnoredFile struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Deletable is true when the matching pattern allows removing the file
+	// from disk, as happens when its parent directory is deleted.
+	Deletable bool `json:"deletable"`
+}
+
+// PreviewIgnores returns the files in the local index that would become
+// ignored if the folder's ignore patterns were replaced by the given
+// content, sorted by name. Nothing is changed.
+func (m *model) PreviewIgnores(folder string, content []string) ([]NewlyIgnoredFile, error) {
+	m.mut.RLock()
+	cfg, ok := m.folderCfgs[folder]
+	m.mut.RUnlock()
+	if !ok {
+		return nil, ErrFolderMissing
+	}
+	if cfg.Type == config.FolderTypeReceiveEncrypted {
+		return nil, nil
+	}
+
+	matcher := ignore.New(cfg.Filesystem())
+	if err := matcher.Parse(strings.NewReader(strings.Join(content, "\n")), ".stignore"); err != nil {
+		return nil, err
+	}
+
+	var files []NewlyIgnoredFile
+	it, errFn := m.sdb.AllLocalFiles(folder, protocol.LocalDeviceID)
+	for fi := range it {
+		if fi.IsIgnored() || fi.IsDeleted() {
+			continue
+		}
+		res := matcher.Match(fi.Name)
+		if !res.IsIgnored() {
+			continue
+		}
+		files = append(files, NewlyIgnoredFile{
+			Name:      fi.Name,
+			Type:      fi.FileType().String(),
+			Deletable: res.IsDeletable(),
+		})
+	}
+	if err := errFn(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(files, func(a, b NewlyIgnoredFile) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return files, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestPreviewIgnores(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	ffs := fcfg.Filesystem()
+	must(t, ffs.MkdirAll("photos", 0o755))
+	writeFile(t, ffs, "photos/a.jpg", []byte("a"))
+	writeFile(t, ffs, "photos/b.raw", []byte("b"))
+	writeFile(t, ffs, "notes.txt", []byte("c"))
+	writeFile(t, ffs, "old.tmp", []byte("d"))
+	writeFile(t, ffs, ".stignore", []byte("*.tmp\n"))
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	files, err := m.PreviewIgnores(fcfg.ID, []string{"*.tmp", "(?d)*.raw", "notes.txt"})
+	must(t, err)
+
+	expected := []NewlyIgnoredFile{
+		{Name: "notes.txt", Type: protocol.FileInfoTypeFile.String()},
+		{Name: "photos/b.raw", Type: protocol.FileInfoTypeFile.String(), Deletable: true},
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, files)
+	}
+	for i := range files {
+		if files[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], files[i])
+		}
+	}
+
+	// The patterns are not applied.
+	lines, _, err := m.CurrentIgnores(fcfg.ID)
+	must(t, err)
+	if len(lines) != 1 || lines[0] != "*.tmp" {
+		t.Error("unexpected current ignores", lines)
+	}
+
+	if _, err := m.PreviewIgnores("nonexistent", nil); err != ErrFolderMissing {
+		t.Error("expected missing folder error, got", err)
+	}
+}
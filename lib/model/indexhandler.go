@@ -14,8 +14,11 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/internal/tracing"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/events"
 	"github.com/syncthing/syncthing/lib/protocol"
@@ -28,6 +31,7 @@ type indexHandler struct {
 	downloads                *deviceDownloadState
 	folder                   string
 	folderIsReceiveEncrypted bool
+	filter                   *shareFilter // nil if everything is shared
 	evLogger                 events.Logger
 
 	// We track the latest / highest sequence number in two ways for two
@@ -57,6 +61,7 @@ func newIndexHandler(conn protocol.Connection, downloads *deviceDownloadState, f
 	if err != nil {
 		return nil, err
 	}
+	myIndexID = sharedIndexID(myIndexID, folder, conn.DeviceID())
 	mySequence, err := sdb.GetDeviceSequence(folder.ID, protocol.LocalDeviceID)
 	if err != nil {
 		return nil, err
@@ -98,6 +103,13 @@ func newIndexHandler(conn protocol.Connection, downloads *deviceDownloadState, f
 		startSequence = 0
 	}
 
+	if dev, ok := folder.Device(conn.DeviceID()); ok && dev.RotatingEncryption() {
+		// Everything is encrypted anew with the new password, so they need
+		// all of it.
+		l.Debugf("Device %v folder %s is rotating encryption password", conn.DeviceID().Short(), folder.Description())
+		startSequence = 0
+	}
+
 	// This is the other side's description of themselves. We
 	// check to see that it matches the IndexID we have on file,
 	// otherwise we drop our old index data and expect to get a
@@ -133,6 +145,7 @@ func newIndexHandler(conn protocol.Connection, downloads *deviceDownloadState, f
 		downloads:                downloads,
 		folder:                   folder.ID,
 		folderIsReceiveEncrypted: folder.Type == config.FolderTypeReceiveEncrypted,
+		filter:                   newShareFilter(folder, conn.DeviceID()),
 		localPrevSequence:        startSequence,
 		sentPrevSequence:         startSequence,
 		evLogger:                 evLogger,
@@ -279,6 +292,8 @@ func (s *indexHandler) sendIndexTo(ctx context.Context) error {
 		l.Debugf("%v: Sending %d files (<%d bytes)", s, len(fs), batch.Size())
 
 		lastSequence := fs[len(fs)-1].Sequence
+		ctx, span := tracing.Start(ctx, "send index", tracing.Folder(s.folder), tracing.Device(s.conn.DeviceID()),
+			attribute.Bool("syncthing.update", !initial), attribute.Int("syncthing.files", len(fs)))
 		var err error
 		if initial {
 			initial = false
@@ -295,6 +310,7 @@ func (s *indexHandler) sendIndexTo(ctx context.Context) error {
 				LastSequence: lastSequence,
 			})
 		}
+		tracing.End(span, err)
 		if err != nil {
 			batchError = err
 			return err
@@ -344,6 +360,11 @@ func (s *indexHandler) sendIndexTo(ctx context.Context) error {
 			continue
 		}
 
+		// Items not shared with the device are not announced.
+		if !s.filter.allows(fi.Name) {
+			continue
+		}
+
 		f = prepareFileInfoForIndex(f)
 
 		previousWasDelete = f.IsDeleted()
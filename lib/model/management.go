@@ -0,0 +1,215 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/build"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+var (
+	ErrRemoteManagementDenied = errors.New("remote management not allowed for this device")
+	ErrDeviceNotConnected     = errors.New("device is not connected")
+	errUnknownManagementOp    = errors.New("unknown management operation")
+)
+
+// ManagementStatus is the status summary a managed device returns to the
+// device managing it.
+type ManagementStatus struct {
+	DeviceID protocol.DeviceID                 `json:"deviceID"`
+	Version  string                            `json:"version"`
+	Folders  map[string]ManagementFolderStatus `json:"folders"`
+	Devices  map[string]ManagementDeviceStatus `json:"devices"`
+}
+
+type ManagementFolderStatus struct {
+	Label        string    `json:"label"`
+	Paused       bool      `json:"paused"`
+	State        string    `json:"state"`
+	StateChanged time.Time `json:"stateChanged"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type ManagementDeviceStatus struct {
+	Name      string `json:"name"`
+	Paused    bool   `json:"paused"`
+	Connected bool   `json:"connected"`
+}
+
+// ManageDevice sends a management request to the given device and returns
+// the response body. The remote device decides whether we are allowed to
+// manage it.
+func (m *model) ManageDevice(ctx context.Context, deviceID protocol.DeviceID, op protocol.ManagementOperation, body []byte) ([]byte, error) {
+	conn, ok := m.requestConnectionForDevice(deviceID)
+	if !ok {
+		return nil, ErrDeviceNotConnected
+	}
+	l.Debugf("%v MGMT(out): %s (%s): %v", m, deviceID.Short(), conn, op)
+	return conn.ManagementRequest(ctx, &protocol.ManagementRequest{Operation: op, Body: body})
+}
+
+// ManagementRequest handles a management request from a remote device. It
+// is refused unless the remote device has been explicitly allowed to manage
+// us.
+func (m *model) ManagementRequest(conn protocol.Connection, req *protocol.ManagementRequest) ([]byte, error) {
+	deviceID := conn.DeviceID()
+	devCfg, ok := m.cfg.Device(deviceID)
+	if !ok || !devCfg.AllowRemoteManagement {
+		l.Debugf("%v MGMT(in): %s: %v denied", m, deviceID.Short(), req.Operation)
+		return nil, ErrRemoteManagementDenied
+	}
+	l.Debugf("%v MGMT(in): %s: %v", m, deviceID.Short(), req.Operation)
+
+	switch req.Operation {
+	case protocol.ManagementOperationStatus:
+		return json.Marshal(m.managementStatus())
+
+	case protocol.ManagementOperationGetConfig:
+		return json.Marshal(redactedForRemote(m.cfg.RawCopy()))
+
+	case protocol.ManagementOperationSetConfig:
+		if err := m.setConfigFromRemote(req.Body); err != nil {
+			return nil, err
+		}
+		slog.Info("Configuration changed by remote management", deviceID.LogAttr())
+		return nil, nil
+
+	default:
+		return nil, errUnknownManagementOp
+	}
+}
+
+func (m *model) managementStatus() ManagementStatus {
+	cfg := m.cfg.RawCopy()
+	status := ManagementStatus{
+		DeviceID: m.id,
+		Version:  build.Version,
+		Folders:  make(map[string]ManagementFolderStatus, len(cfg.Folders)),
+		Devices:  make(map[string]ManagementDeviceStatus, len(cfg.Devices)),
+	}
+	for _, fcfg := range cfg.Folders {
+		fs := ManagementFolderStatus{
+			Label:  fcfg.Label,
+			Paused: fcfg.Paused,
+		}
+		state, changed, err := m.State(fcfg.ID)
+		fs.State = state
+		fs.StateChanged = changed
+		if err != nil {
+			fs.Error = err.Error()
+		}
+		status.Folders[fcfg.ID] = fs
+	}
+	for _, dcfg := range cfg.Devices {
+		if dcfg.DeviceID == m.id {
+			continue
+		}
+		status.Devices[dcfg.DeviceID.String()] = ManagementDeviceStatus{
+			Name:      dcfg.Name,
+			Paused:    dcfg.Paused,
+			Connected: m.ConnectedTo(dcfg.DeviceID),
+		}
+	}
+	return status
+}
+
+// redactedPlaceholder replaces secrets in the configuration given to a
+// remote device.
+const redactedPlaceholder = "REDACTED"
+
+// redactedForRemote returns the configuration with the GUI credentials and
+// the folder encryption passwords replaced by a placeholder. The remote
+// device can tell whether they are set, but not what they are.
+func redactedForRemote(cfg config.Configuration) config.Configuration {
+	redact := func(s *string) {
+		if *s != "" {
+			*s = redactedPlaceholder
+		}
+	}
+	redact(&cfg.GUI.APIKey)
+	redact(&cfg.GUI.Password)
+	redact(&cfg.GUI.TOTPSecret)
+	cfg.GUI.TOTPRecoveryCodes = nil
+	for i := range cfg.Folders {
+		for j := range cfg.Folders[i].Devices {
+			dev := &cfg.Folders[i].Devices[j]
+			redact(&dev.EncryptionPassword)
+			redact(&dev.PreviousEncryptionPassword)
+			for k := range dev.EncryptionSubtrees {
+				redact(&dev.EncryptionSubtrees[k].EncryptionPassword)
+			}
+		}
+	}
+	return cfg
+}
+
+// setConfigFromRemote replaces the configuration with the one given. The
+// GUI and LDAP settings are never accepted from a remote device, so that
+// access to the local GUI stays under local control, and neither is
+// anything that runs commands or holds a secret; see keepLocalOnly.
+func (m *model) setConfigFromRemote(body []byte) error {
+	to, err := config.ReadJSON(bytes.NewReader(body), m.id)
+	if err != nil {
+		return fmt.Errorf("decoding config: %w", err)
+	}
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		keepLocalOnly(cfg, &to)
+		*cfg = to
+	})
+	if err != nil {
+		return err
+	}
+	waiter.Wait()
+	return nil
+}
+
+// keepLocalOnly copies the settings that only the local user may change
+// from the current configuration to the remotely given one: The GUI and
+// LDAP settings, hooks, plugins, external versioners and encryption
+// passwords. New folders don't get an external versioner.
+func keepLocalOnly(from *config.Configuration, to *config.Configuration) {
+	to.GUI = from.GUI
+	to.LDAP = from.LDAP
+	to.Hooks = from.Hooks
+	to.Plugins = from.Plugins
+
+	for i := range to.Folders {
+		fcfg := &to.Folders[i]
+		local, _, ok := from.Folder(fcfg.ID)
+		if fcfg.Versioning.Type == "external" || ok && local.Versioning.Type == "external" {
+			if ok {
+				fcfg.Versioning = local.Versioning.Copy()
+			} else {
+				fcfg.Versioning = config.VersioningConfiguration{}
+			}
+		}
+		for j := range fcfg.Devices {
+			dev := &fcfg.Devices[j]
+			localDev, ok := local.Device(dev.DeviceID)
+			if !ok {
+				dev.PreviousEncryptionPassword = ""
+				if dev.EncryptionPassword == redactedPlaceholder {
+					dev.EncryptionPassword = ""
+				}
+				continue
+			}
+			dev.EncryptionPassword = localDev.EncryptionPassword
+			dev.PreviousEncryptionPassword = localDev.PreviousEncryptionPassword
+			dev.EncryptionSubtrees = slices.Clone(localDev.EncryptionSubtrees)
+		}
+	}
+}
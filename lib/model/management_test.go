@@ -0,0 +1,150 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestManagementRequestConsent(t *testing.T) {
+	t.Parallel()
+
+	m, fc, fcfg := setupModelWithConnection(t)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	req := &protocol.ManagementRequest{Operation: protocol.ManagementOperationStatus}
+	if _, err := m.ManagementRequest(fc, req); !errors.Is(err, ErrRemoteManagementDenied) {
+		t.Fatalf("expected management to be denied, got %v", err)
+	}
+
+	dev, _ := m.cfg.Device(device1)
+	dev.AllowRemoteManagement = true
+	setDevice(t, m.cfg, dev)
+
+	bs, err := m.ManagementRequest(fc, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status ManagementStatus
+	if err := json.Unmarshal(bs, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.DeviceID != myID {
+		t.Errorf("unexpected device ID %v", status.DeviceID)
+	}
+	if _, ok := status.Folders[fcfg.ID]; !ok {
+		t.Errorf("folder %v missing from status", fcfg.ID)
+	}
+	if _, ok := status.Devices[device1.String()]; !ok {
+		t.Errorf("device %v missing from status", device1)
+	}
+}
+
+func TestManagementSetConfigKeepsGUI(t *testing.T) {
+	t.Parallel()
+
+	m, fc, fcfg := setupModelWithConnection(t)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	dev, _ := m.cfg.Device(device1)
+	dev.AllowRemoteManagement = true
+	setDevice(t, m.cfg, dev)
+
+	bs, err := m.ManagementRequest(fc, &protocol.ManagementRequest{Operation: protocol.ManagementOperationGetConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	origGUI := m.cfg.GUI()
+	var remote map[string]any
+	if err := json.Unmarshal(bs, &remote); err != nil {
+		t.Fatal(err)
+	}
+	remote["gui"].(map[string]any)["address"] = "0.0.0.0:1234"
+	remote["options"].(map[string]any)["maxSendKbps"] = 42
+	bs, _ = json.Marshal(remote)
+
+	if _, err := m.ManagementRequest(fc, &protocol.ManagementRequest{Operation: protocol.ManagementOperationSetConfig, Body: bs}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := m.cfg.Options().MaxSendKbps; got != 42 {
+		t.Errorf("MaxSendKbps not applied, got %d", got)
+	}
+	if got := m.cfg.GUI().RawAddress; got != origGUI.RawAddress {
+		t.Errorf("GUI address changed remotely to %q", got)
+	}
+}
+
+func TestManagementConfigSecrets(t *testing.T) {
+	t.Parallel()
+
+	m, fc, fcfg := setupModelWithConnection(t)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	dev, _ := m.cfg.Device(device1)
+	dev.AllowRemoteManagement = true
+	setDevice(t, m.cfg, dev)
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		cfg.GUI.APIKey = "secret-api-key"
+		cfg.GUI.Password = "secret-password-hash"
+		for i := range cfg.Folders[0].Devices {
+			if cfg.Folders[0].Devices[i].DeviceID == device1 {
+				cfg.Folders[0].Devices[i].EncryptionPassword = "secret-encryption-password"
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiter.Wait()
+
+	bs, err := m.ManagementRequest(fc, &protocol.ManagementRequest{Operation: protocol.ManagementOperationGetConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(bs, []byte("secret-")) {
+		t.Fatalf("secret in remote config: %s", bs)
+	}
+
+	var remote config.Configuration
+	if err := json.Unmarshal(bs, &remote); err != nil {
+		t.Fatal(err)
+	}
+	remote.Folders[0].Versioning = config.VersioningConfiguration{Type: "external", Params: map[string]string{"command": "evil"}}
+	remote.Hooks.Hooks = []config.HookConfiguration{{Event: config.HookFolderSyncStarted, Command: "evil"}}
+	remote.Plugins = []config.PluginConfiguration{{ID: "evil", Command: "evil"}}
+	remote.Options.MaxSendKbps = 42
+	bs, _ = json.Marshal(remote)
+
+	if _, err := m.ManagementRequest(fc, &protocol.ManagementRequest{Operation: protocol.ManagementOperationSetConfig, Body: bs}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := m.cfg.RawCopy()
+	if cfg.Options.MaxSendKbps != 42 {
+		t.Errorf("MaxSendKbps not applied, got %d", cfg.Options.MaxSendKbps)
+	}
+	if cfg.GUI.APIKey != "secret-api-key" || cfg.GUI.Password != "secret-password-hash" {
+		t.Error("GUI credentials changed remotely")
+	}
+	if cfg.Folders[0].Versioning.Type == "external" {
+		t.Error("external versioner set remotely")
+	}
+	if len(cfg.Hooks.Hooks) != 0 || len(cfg.Plugins) != 0 {
+		t.Error("hooks or plugins set remotely")
+	}
+	if fd, _ := cfg.Folders[0].Device(device1); fd.EncryptionPassword != "secret-encryption-password" {
+		t.Errorf("encryption password changed remotely to %q", fd.EncryptionPassword)
+	}
+}
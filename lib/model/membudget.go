@@ -0,0 +1,59 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/syncthing/syncthing/lib/semaphore"
+)
+
+const (
+	memoryUsePull  = "pull"  // block data of in-flight requests
+	memoryUseHash  = "hash"  // files being hashed
+	memoryUseIndex = "index" // batches of file infos
+)
+
+// A memoryBudget hands out the memory a folder may use for a given purpose,
+// taking it from both the folder's own budget and the global budget shared
+// by all folders, and tracks the usage in a metric.
+type memoryBudget struct {
+	sem  semaphore.MultiSemaphore
+	used prometheus.Gauge
+}
+
+func newMemoryBudget(folderID, use string, folder, global *semaphore.Semaphore) *memoryBudget {
+	return &memoryBudget{
+		sem:  semaphore.MultiSemaphore{folder, global},
+		used: metricFolderMemoryBytes.WithLabelValues(folderID, use),
+	}
+}
+
+func (b *memoryBudget) TakeWithContext(ctx context.Context, size int) error {
+	if err := b.sem.TakeWithContext(ctx, size); err != nil {
+		return err
+	}
+	b.used.Add(float64(size))
+	return nil
+}
+
+// TryTake takes the memory if it's available without waiting, returning
+// whether it did.
+func (b *memoryBudget) TryTake(size int) bool {
+	if !b.sem.TryTake(size) {
+		return false
+	}
+	b.used.Add(float64(size))
+	return true
+}
+
+func (b *memoryBudget) Give(size int) {
+	b.sem.Give(size)
+	b.used.Sub(float64(size))
+}
@@ -58,12 +58,68 @@ var (
 		Help:      "Total amount of data processed during folder syncing, per folder ID and data source (network/local_origin/local_other/skipped)",
 	}, []string{"folder", "source"})
 
+	metricFolderProcessedBlocksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_processed_blocks_total",
+		Help:      "Total number of blocks processed during folder syncing, per folder ID and data source (network/local_origin/local_other/skipped)",
+	}, []string{"folder", "source"})
+
 	metricFolderConflictsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "syncthing",
 		Subsystem: "model",
 		Name:      "folder_conflicts_total",
 		Help:      "Total number of conflicts",
 	}, []string{"folder"})
+
+	metricFolderPullErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_pull_errors_total",
+		Help:      "Total number of errors syncing items, per folder ID and error category",
+	}, []string{"folder", "category"})
+
+	metricFolderScanDurationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_scan_duration_seconds",
+		Help:      "Duration of folder scan iterations, per folder ID",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+	}, []string{"folder"})
+
+	metricFolderDeviceBytesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_device_bytes_total",
+		Help:      "Total amount of block data exchanged with other devices, per folder ID, device ID and direction (pulled/pushed)",
+	}, []string{"folder", "device", "direction"})
+
+	metricFolderRemoteNeed = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_remote_need",
+		Help:      "Amount of data a remote device needs to be in sync, per folder ID, device ID and type (items/deleted/bytes)",
+	}, []string{"folder", "device", "type"})
+
+	metricFolderWatchedDirs = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_watched_directories",
+		Help:      "Number of directories watched for changes, per folder ID",
+	}, []string{"folder"})
+	metricFolderUnwatchedSubtrees = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_unwatched_subtrees",
+		Help:      "Number of subtrees left unwatched to stay within the watch budget, per folder ID",
+	}, []string{"folder"})
+
+	metricFolderMemoryBytes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_memory_bytes",
+		Help:      "Memory taken from the memory budget, per folder ID and use (pull/hash/index)",
+	}, []string{"folder", "use"})
 )
 
 const (
@@ -81,6 +137,10 @@ const (
 	metricTypeSymlinks    = "symlinks"
 	metricTypeDeleted     = "deleted"
 	metricTypeBytes       = "bytes"
+	metricTypeItems       = "items"
+
+	metricDirectionPulled = "pulled"
+	metricDirectionPushed = "pushed"
 )
 
 func registerFolderMetrics(folderID string) {
@@ -95,5 +155,23 @@ func registerFolderMetrics(folderID string) {
 	metricFolderProcessedBytesTotal.WithLabelValues(folderID, metricSourceLocalOrigin)
 	metricFolderProcessedBytesTotal.WithLabelValues(folderID, metricSourceLocalOther)
 	metricFolderProcessedBytesTotal.WithLabelValues(folderID, metricSourceSkipped)
+	metricFolderProcessedBlocksTotal.WithLabelValues(folderID, metricSourceNetwork)
+	metricFolderProcessedBlocksTotal.WithLabelValues(folderID, metricSourceLocalOrigin)
+	metricFolderProcessedBlocksTotal.WithLabelValues(folderID, metricSourceLocalOther)
+	metricFolderProcessedBlocksTotal.WithLabelValues(folderID, metricSourceSkipped)
 	metricFolderConflictsTotal.WithLabelValues(folderID)
+	metricFolderScanDurationSeconds.WithLabelValues(folderID)
+	metricFolderMemoryBytes.WithLabelValues(folderID, memoryUsePull)
+	metricFolderMemoryBytes.WithLabelValues(folderID, memoryUseHash)
+	metricFolderMemoryBytes.WithLabelValues(folderID, memoryUseIndex)
+	metricFolderWatchedDirs.WithLabelValues(folderID)
+	metricFolderUnwatchedSubtrees.WithLabelValues(folderID)
+}
+
+// unregisterDeviceMetrics removes the metrics about a removed device, so
+// that they don't linger with their last values.
+func unregisterDeviceMetrics(deviceID string) {
+	labels := prometheus.Labels{"device": deviceID}
+	metricFolderDeviceBytesTotal.DeletePartialMatch(labels)
+	metricFolderRemoteNeed.DeletePartialMatch(labels)
 }
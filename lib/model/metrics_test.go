@@ -0,0 +1,60 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+func TestFileErrorCategory(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		err error
+		exp string
+	}{
+		{errNotAvailable, ErrorCategoryNotAvailable},
+		{fmt.Errorf("finishing: %w", errModified), ErrorCategoryModified},
+		{fmt.Errorf("%w in folder x", config.ErrInsufficientSpace), ErrorCategoryNoSpace},
+		{&fs.PathError{Op: "open", Path: "foo", Err: fs.ErrPermission}, ErrorCategoryPermission},
+		{fmt.Errorf("delete file: %w", fs.ErrNotExist), ErrorCategoryNotExist},
+		{&fs.PathError{Op: "open", Path: "foo", Err: syscall.ENOSPC}, ErrorCategoryNoSpace},
+		{&fs.PathError{Op: "rename", Path: "foo", Err: syscall.ENAMETOOLONG}, ErrorCategoryPathTooLong},
+		{errors.New("something else"), ErrorCategoryOther},
+	}
+	for _, tc := range cases {
+		if cat := fileErrorCategory(tc.err); cat != tc.exp {
+			t.Errorf("fileErrorCategory(%v) = %q, expected %q", tc.err, cat, tc.exp)
+		}
+	}
+}
+
+func TestUnregisterDeviceMetrics(t *testing.T) {
+	t.Parallel()
+
+	metricFolderRemoteNeed.WithLabelValues("unregister-a", "dev-gone", metricTypeItems).Set(1)
+	metricFolderRemoteNeed.WithLabelValues("unregister-b", "dev-gone", metricTypeBytes).Set(1)
+	metricFolderRemoteNeed.WithLabelValues("unregister-a", "dev-kept", metricTypeItems).Set(1)
+	metricFolderDeviceBytesTotal.WithLabelValues("unregister-a", "dev-gone", metricDirectionPulled).Add(1)
+
+	unregisterDeviceMetrics("dev-gone")
+
+	if metricFolderRemoteNeed.DeleteLabelValues("unregister-a", "dev-gone", metricTypeItems) ||
+		metricFolderRemoteNeed.DeleteLabelValues("unregister-b", "dev-gone", metricTypeBytes) ||
+		metricFolderDeviceBytesTotal.DeleteLabelValues("unregister-a", "dev-gone", metricDirectionPulled) {
+		t.Error("metrics for removed device still present")
+	}
+	if !metricFolderRemoteNeed.DeleteLabelValues("unregister-a", "dev-kept", metricTypeItems) {
+		t.Error("metrics for other device removed")
+	}
+}
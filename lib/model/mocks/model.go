@@ -9,8 +9,10 @@ import (
 	"time"
 
 	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/lib/fs"
 	"github.com/syncthing/syncthing/lib/model"
 	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/scanner"
 	"github.com/syncthing/syncthing/lib/stats"
 	"github.com/syncthing/syncthing/lib/ur/contract"
 	"github.com/syncthing/syncthing/lib/versioner"
@@ -23,6 +25,19 @@ type Model struct {
 		arg1 protocol.Connection
 		arg2 protocol.Hello
 	}
+	AdoptStub        func(string) (int, error)
+	adoptMutex       sync.RWMutex
+	adoptArgsForCall []struct {
+		arg1 string
+	}
+	adoptReturns struct {
+		result1 int
+		result2 error
+	}
+	adoptReturnsOnCall map[int]struct {
+		result1 int
+		result2 error
+	}
 	AllGlobalFilesStub        func(string) (iter.Seq[db.FileMetadata], func() error)
 	allGlobalFilesMutex       sync.RWMutex
 	allGlobalFilesArgsForCall []struct {
@@ -36,6 +51,17 @@ type Model struct {
 		result1 iter.Seq[db.FileMetadata]
 		result2 func() error
 	}
+	ApproveRemoteChangesStub        func(string) error
+	approveRemoteChangesMutex       sync.RWMutex
+	approveRemoteChangesArgsForCall []struct {
+		arg1 string
+	}
+	approveRemoteChangesReturns struct {
+		result1 error
+	}
+	approveRemoteChangesReturnsOnCall map[int]struct {
+		result1 error
+	}
 	AvailabilityStub        func(string, protocol.FileInfo, protocol.BlockInfo) ([]model.Availability, error)
 	availabilityMutex       sync.RWMutex
 	availabilityArgsForCall []struct {
@@ -57,6 +83,19 @@ type Model struct {
 		arg1 string
 		arg2 string
 	}
+	CaseConflictsStub        func(string) ([]model.CaseConflict, error)
+	caseConflictsMutex       sync.RWMutex
+	caseConflictsArgsForCall []struct {
+		arg1 string
+	}
+	caseConflictsReturns struct {
+		result1 []model.CaseConflict
+		result2 error
+	}
+	caseConflictsReturnsOnCall map[int]struct {
+		result1 []model.CaseConflict
+		result2 error
+	}
 	ClosedStub        func(protocol.Connection, error)
 	closedMutex       sync.RWMutex
 	closedArgsForCall []struct {
@@ -75,6 +114,16 @@ type Model struct {
 	clusterConfigReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ClusterStateStub        func() []model.ClusterFolderState
+	clusterStateMutex       sync.RWMutex
+	clusterStateArgsForCall []struct {
+	}
+	clusterStateReturns struct {
+		result1 []model.ClusterFolderState
+	}
+	clusterStateReturnsOnCall map[int]struct {
+		result1 []model.ClusterFolderState
+	}
 	CompletionStub        func(protocol.DeviceID, string) (model.FolderCompletion, error)
 	completionMutex       sync.RWMutex
 	completionArgsForCall []struct {
@@ -163,6 +212,20 @@ type Model struct {
 		arg1 string
 		arg2 time.Duration
 	}
+	DeletedFilesStub        func(string, string) ([]model.DeletedFile, error)
+	deletedFilesMutex       sync.RWMutex
+	deletedFilesArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	deletedFilesReturns struct {
+		result1 []model.DeletedFile
+		result2 error
+	}
+	deletedFilesReturnsOnCall map[int]struct {
+		result1 []model.DeletedFile
+		result2 error
+	}
 	DeviceStatisticsStub        func() (map[protocol.DeviceID]stats.DeviceStatistics, error)
 	deviceStatisticsMutex       sync.RWMutex
 	deviceStatisticsArgsForCall []struct {
@@ -210,6 +273,32 @@ type Model struct {
 	downloadProgressReturnsOnCall map[int]struct {
 		result1 error
 	}
+	EncryptionRotationStub        func(string, protocol.DeviceID) (model.EncryptionRotation, error)
+	encryptionRotationMutex       sync.RWMutex
+	encryptionRotationArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	encryptionRotationReturns struct {
+		result1 model.EncryptionRotation
+		result2 error
+	}
+	encryptionRotationReturnsOnCall map[int]struct {
+		result1 model.EncryptionRotation
+		result2 error
+	}
+	FileActivityStub        func() (map[string][]stats.DailyActivity, error)
+	fileActivityMutex       sync.RWMutex
+	fileActivityArgsForCall []struct {
+	}
+	fileActivityReturns struct {
+		result1 map[string][]stats.DailyActivity
+		result2 error
+	}
+	fileActivityReturnsOnCall map[int]struct {
+		result1 map[string][]stats.DailyActivity
+		result2 error
+	}
 	FolderErrorsStub        func(string) ([]model.FileError, error)
 	folderErrorsMutex       sync.RWMutex
 	folderErrorsArgsForCall []struct {
@@ -223,6 +312,16 @@ type Model struct {
 		result1 []model.FileError
 		result2 error
 	}
+	FolderPathWarningsStub        func() []model.FolderPathWarning
+	folderPathWarningsMutex       sync.RWMutex
+	folderPathWarningsArgsForCall []struct {
+	}
+	folderPathWarningsReturns struct {
+		result1 []model.FolderPathWarning
+	}
+	folderPathWarningsReturnsOnCall map[int]struct {
+		result1 []model.FolderPathWarning
+	}
 	FolderProgressBytesCompletedStub        func(string) int64
 	folderProgressBytesCompletedMutex       sync.RWMutex
 	folderProgressBytesCompletedArgsForCall []struct {
@@ -234,6 +333,19 @@ type Model struct {
 	folderProgressBytesCompletedReturnsOnCall map[int]struct {
 		result1 int64
 	}
+	FolderSizeHistoryStub        func(string) (stats.SizeHistory, error)
+	folderSizeHistoryMutex       sync.RWMutex
+	folderSizeHistoryArgsForCall []struct {
+		arg1 string
+	}
+	folderSizeHistoryReturns struct {
+		result1 stats.SizeHistory
+		result2 error
+	}
+	folderSizeHistoryReturnsOnCall map[int]struct {
+		result1 stats.SizeHistory
+		result2 error
+	}
 	FolderStatisticsStub        func() (map[string]stats.FolderStatistics, error)
 	folderStatisticsMutex       sync.RWMutex
 	folderStatisticsArgsForCall []struct {
@@ -259,6 +371,21 @@ type Model struct {
 		result1 map[string][]versioner.FileVersion
 		result2 error
 	}
+	GetFolderVersionsBetweenStub        func(string, time.Time, time.Time) (map[string][]versioner.FileVersion, error)
+	getFolderVersionsBetweenMutex       sync.RWMutex
+	getFolderVersionsBetweenArgsForCall []struct {
+		arg1 string
+		arg2 time.Time
+		arg3 time.Time
+	}
+	getFolderVersionsBetweenReturns struct {
+		result1 map[string][]versioner.FileVersion
+		result2 error
+	}
+	getFolderVersionsBetweenReturnsOnCall map[int]struct {
+		result1 map[string][]versioner.FileVersion
+		result2 error
+	}
 	GlobalDirectoryTreeStub        func(string, string, int, bool) ([]*model.TreeEntry, error)
 	globalDirectoryTreeMutex       sync.RWMutex
 	globalDirectoryTreeArgsForCall []struct {
@@ -385,6 +512,36 @@ type Model struct {
 		result1 db.Counts
 		result2 error
 	}
+	ManageDeviceStub        func(context.Context, protocol.DeviceID, protocol.ManagementOperation, []byte) ([]byte, error)
+	manageDeviceMutex       sync.RWMutex
+	manageDeviceArgsForCall []struct {
+		arg1 context.Context
+		arg2 protocol.DeviceID
+		arg3 protocol.ManagementOperation
+		arg4 []byte
+	}
+	manageDeviceReturns struct {
+		result1 []byte
+		result2 error
+	}
+	manageDeviceReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	ManagementRequestStub        func(protocol.Connection, *protocol.ManagementRequest) ([]byte, error)
+	managementRequestMutex       sync.RWMutex
+	managementRequestArgsForCall []struct {
+		arg1 protocol.Connection
+		arg2 *protocol.ManagementRequest
+	}
+	managementRequestReturns struct {
+		result1 []byte
+		result2 error
+	}
+	managementRequestReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	NeedFolderFilesStub        func(string, int, int) ([]protocol.FileInfo, []protocol.FileInfo, []protocol.FileInfo, error)
 	needFolderFilesMutex       sync.RWMutex
 	needFolderFilesArgsForCall []struct {
@@ -404,6 +561,19 @@ type Model struct {
 		result3 []protocol.FileInfo
 		result4 error
 	}
+	NeedFolderFilesIterStub        func(string) (iter.Seq[protocol.FileInfo], func() error)
+	needFolderFilesIterMutex       sync.RWMutex
+	needFolderFilesIterArgsForCall []struct {
+		arg1 string
+	}
+	needFolderFilesIterReturns struct {
+		result1 iter.Seq[protocol.FileInfo]
+		result2 func() error
+	}
+	needFolderFilesIterReturnsOnCall map[int]struct {
+		result1 iter.Seq[protocol.FileInfo]
+		result2 func() error
+	}
 	NeedSizeStub        func(string, protocol.DeviceID) (db.Counts, error)
 	needSizeMutex       sync.RWMutex
 	needSizeArgsForCall []struct {
@@ -431,11 +601,39 @@ type Model struct {
 	onHelloReturnsOnCall map[int]struct {
 		result1 error
 	}
+	OpenFolderVersionStub        func(string, string, time.Time) (fs.File, error)
+	openFolderVersionMutex       sync.RWMutex
+	openFolderVersionArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 time.Time
+	}
+	openFolderVersionReturns struct {
+		result1 fs.File
+		result2 error
+	}
+	openFolderVersionReturnsOnCall map[int]struct {
+		result1 fs.File
+		result2 error
+	}
 	OverrideStub        func(string)
 	overrideMutex       sync.RWMutex
 	overrideArgsForCall []struct {
 		arg1 string
 	}
+	PauseAllStub        func(string, string, time.Duration) error
+	pauseAllMutex       sync.RWMutex
+	pauseAllArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 time.Duration
+	}
+	pauseAllReturns struct {
+		result1 error
+	}
+	pauseAllReturnsOnCall map[int]struct {
+		result1 error
+	}
 	PendingDevicesStub        func() (map[protocol.DeviceID]db.ObservedDevice, error)
 	pendingDevicesMutex       sync.RWMutex
 	pendingDevicesArgsForCall []struct {
@@ -461,6 +659,61 @@ type Model struct {
 		result1 map[string]db.PendingFolder
 		result2 error
 	}
+	PendingRemoteChangesStub        func(string) (model.PendingApproval, bool, error)
+	pendingRemoteChangesMutex       sync.RWMutex
+	pendingRemoteChangesArgsForCall []struct {
+		arg1 string
+	}
+	pendingRemoteChangesReturns struct {
+		result1 model.PendingApproval
+		result2 bool
+		result3 error
+	}
+	pendingRemoteChangesReturnsOnCall map[int]struct {
+		result1 model.PendingApproval
+		result2 bool
+		result3 error
+	}
+	PinnedStub        func(string) ([]string, error)
+	pinnedMutex       sync.RWMutex
+	pinnedArgsForCall []struct {
+		arg1 string
+	}
+	pinnedReturns struct {
+		result1 []string
+		result2 error
+	}
+	pinnedReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	PreviewIgnoresStub        func(string, []string) ([]model.NewlyIgnoredFile, error)
+	previewIgnoresMutex       sync.RWMutex
+	previewIgnoresArgsForCall []struct {
+		arg1 string
+		arg2 []string
+	}
+	previewIgnoresReturns struct {
+		result1 []model.NewlyIgnoredFile
+		result2 error
+	}
+	previewIgnoresReturnsOnCall map[int]struct {
+		result1 []model.NewlyIgnoredFile
+		result2 error
+	}
+	PreviewPullStub        func(string) (model.PullPreview, error)
+	previewPullMutex       sync.RWMutex
+	previewPullArgsForCall []struct {
+		arg1 string
+	}
+	previewPullReturns struct {
+		result1 model.PullPreview
+		result2 error
+	}
+	previewPullReturnsOnCall map[int]struct {
+		result1 model.PullPreview
+		result2 error
+	}
 	ReceiveOnlySizeStub        func(string) (db.Counts, error)
 	receiveOnlySizeMutex       sync.RWMutex
 	receiveOnlySizeArgsForCall []struct {
@@ -517,18 +770,19 @@ type Model struct {
 		result1 protocol.RequestResponse
 		result2 error
 	}
-	RequestGlobalStub        func(context.Context, protocol.DeviceID, string, string, int, int64, int, []byte, bool) ([]byte, error)
+	RequestGlobalStub        func(context.Context, protocol.DeviceID, string, string, int, int64, int, []byte, bool, bool) ([]byte, error)
 	requestGlobalMutex       sync.RWMutex
 	requestGlobalArgsForCall []struct {
-		arg1 context.Context
-		arg2 protocol.DeviceID
-		arg3 string
-		arg4 string
-		arg5 int
-		arg6 int64
-		arg7 int
-		arg8 []byte
-		arg9 bool
+		arg1  context.Context
+		arg2  protocol.DeviceID
+		arg3  string
+		arg4  string
+		arg5  int
+		arg6  int64
+		arg7  int
+		arg8  []byte
+		arg9  bool
+		arg10 bool
 	}
 	requestGlobalReturns struct {
 		result1 []byte
@@ -538,6 +792,20 @@ type Model struct {
 		result1 []byte
 		result2 error
 	}
+	RescanFileStub        func(string, string) (protocol.FileInfo, error)
+	rescanFileMutex       sync.RWMutex
+	rescanFileArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	rescanFileReturns struct {
+		result1 protocol.FileInfo
+		result2 error
+	}
+	rescanFileReturnsOnCall map[int]struct {
+		result1 protocol.FileInfo
+		result2 error
+	}
 	ResetFolderStub        func(string) error
 	resetFolderMutex       sync.RWMutex
 	resetFolderArgsForCall []struct {
@@ -549,6 +817,35 @@ type Model struct {
 	resetFolderReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ResolveCaseConflictStub        func(string, string, string) error
+	resolveCaseConflictMutex       sync.RWMutex
+	resolveCaseConflictArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	resolveCaseConflictReturns struct {
+		result1 error
+	}
+	resolveCaseConflictReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RestoreFolderSnapshotStub        func(string, string, time.Time, string) (map[string]error, error)
+	restoreFolderSnapshotMutex       sync.RWMutex
+	restoreFolderSnapshotArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 time.Time
+		arg4 string
+	}
+	restoreFolderSnapshotReturns struct {
+		result1 map[string]error
+		result2 error
+	}
+	restoreFolderSnapshotReturnsOnCall map[int]struct {
+		result1 map[string]error
+		result2 error
+	}
 	RestoreFolderVersionsStub        func(string, map[string]time.Time) (map[string]error, error)
 	restoreFolderVersionsMutex       sync.RWMutex
 	restoreFolderVersionsArgsForCall []struct {
@@ -563,6 +860,16 @@ type Model struct {
 		result1 map[string]error
 		result2 error
 	}
+	ResumeAllStub        func() error
+	resumeAllMutex       sync.RWMutex
+	resumeAllArgsForCall []struct {
+	}
+	resumeAllReturns struct {
+		result1 error
+	}
+	resumeAllReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RevertStub        func(string)
 	revertMutex       sync.RWMutex
 	revertArgsForCall []struct {
@@ -601,6 +908,29 @@ type Model struct {
 	scanFoldersReturnsOnCall map[int]struct {
 		result1 map[string]error
 	}
+	ScanScheduleStub        func() []model.ScheduledScan
+	scanScheduleMutex       sync.RWMutex
+	scanScheduleArgsForCall []struct {
+	}
+	scanScheduleReturns struct {
+		result1 []model.ScheduledScan
+	}
+	scanScheduleReturnsOnCall map[int]struct {
+		result1 []model.ScheduledScan
+	}
+	ScanSkippedStub        func(string) (scanner.SkipCounts, error)
+	scanSkippedMutex       sync.RWMutex
+	scanSkippedArgsForCall []struct {
+		arg1 string
+	}
+	scanSkippedReturns struct {
+		result1 scanner.SkipCounts
+		result2 error
+	}
+	scanSkippedReturnsOnCall map[int]struct {
+		result1 scanner.SkipCounts
+		result2 error
+	}
 	SequenceStub        func(string, protocol.DeviceID) (int64, error)
 	sequenceMutex       sync.RWMutex
 	sequenceArgsForCall []struct {
@@ -626,6 +956,18 @@ type Model struct {
 	serveReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetFolderScheduleStub        func(string, []string) error
+	setFolderScheduleMutex       sync.RWMutex
+	setFolderScheduleArgsForCall []struct {
+		arg1 string
+		arg2 []string
+	}
+	setFolderScheduleReturns struct {
+		result1 error
+	}
+	setFolderScheduleReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SetIgnoresStub        func(string, []string) error
 	setIgnoresMutex       sync.RWMutex
 	setIgnoresArgsForCall []struct {
@@ -638,6 +980,24 @@ type Model struct {
 	setIgnoresReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetPinnedStub        func(string, string, bool) error
+	setPinnedMutex       sync.RWMutex
+	setPinnedArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 bool
+	}
+	setPinnedReturns struct {
+		result1 error
+	}
+	setPinnedReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetScansSuspendedStub        func(bool)
+	setScansSuspendedMutex       sync.RWMutex
+	setScansSuspendedArgsForCall []struct {
+		arg1 bool
+	}
 	StateStub        func(string) (string, time.Time, error)
 	stateMutex       sync.RWMutex
 	stateArgsForCall []struct {
@@ -653,6 +1013,30 @@ type Model struct {
 		result2 time.Time
 		result3 error
 	}
+	TransferHistoryStub        func(time.Time) model.TransferHistory
+	transferHistoryMutex       sync.RWMutex
+	transferHistoryArgsForCall []struct {
+		arg1 time.Time
+	}
+	transferHistoryReturns struct {
+		result1 model.TransferHistory
+	}
+	transferHistoryReturnsOnCall map[int]struct {
+		result1 model.TransferHistory
+	}
+	UndeleteStub        func(context.Context, string, string) error
+	undeleteMutex       sync.RWMutex
+	undeleteArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	undeleteReturns struct {
+		result1 error
+	}
+	undeleteReturnsOnCall map[int]struct {
+		result1 error
+	}
 	UsageReportingStatsStub        func(*contract.Report, int, bool)
 	usageReportingStatsMutex       sync.RWMutex
 	usageReportingStatsArgsForCall []struct {
@@ -671,6 +1055,16 @@ type Model struct {
 	watchErrorReturnsOnCall map[int]struct {
 		result1 error
 	}
+	WatchReportStub        func() model.WatchReport
+	watchReportMutex       sync.RWMutex
+	watchReportArgsForCall []struct {
+	}
+	watchReportReturns struct {
+		result1 model.WatchReport
+	}
+	watchReportReturnsOnCall map[int]struct {
+		result1 model.WatchReport
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -708,6 +1102,70 @@ func (fake *Model) AddConnectionArgsForCall(i int) (protocol.Connection, protoco
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *Model) Adopt(arg1 string) (int, error) {
+	fake.adoptMutex.Lock()
+	ret, specificReturn := fake.adoptReturnsOnCall[len(fake.adoptArgsForCall)]
+	fake.adoptArgsForCall = append(fake.adoptArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.AdoptStub
+	fakeReturns := fake.adoptReturns
+	fake.recordInvocation("Adopt", []interface{}{arg1})
+	fake.adoptMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) AdoptCallCount() int {
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	return len(fake.adoptArgsForCall)
+}
+
+func (fake *Model) AdoptCalls(stub func(string) (int, error)) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = stub
+}
+
+func (fake *Model) AdoptArgsForCall(i int) string {
+	fake.adoptMutex.RLock()
+	defer fake.adoptMutex.RUnlock()
+	argsForCall := fake.adoptArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) AdoptReturns(result1 int, result2 error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = nil
+	fake.adoptReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) AdoptReturnsOnCall(i int, result1 int, result2 error) {
+	fake.adoptMutex.Lock()
+	defer fake.adoptMutex.Unlock()
+	fake.AdoptStub = nil
+	if fake.adoptReturnsOnCall == nil {
+		fake.adoptReturnsOnCall = make(map[int]struct {
+			result1 int
+			result2 error
+		})
+	}
+	fake.adoptReturnsOnCall[i] = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) AllGlobalFiles(arg1 string) (iter.Seq[db.FileMetadata], func() error) {
 	fake.allGlobalFilesMutex.Lock()
 	ret, specificReturn := fake.allGlobalFilesReturnsOnCall[len(fake.allGlobalFilesArgsForCall)]
@@ -772,40 +1230,101 @@ func (fake *Model) AllGlobalFilesReturnsOnCall(i int, result1 iter.Seq[db.FileMe
 	}{result1, result2}
 }
 
-func (fake *Model) Availability(arg1 string, arg2 protocol.FileInfo, arg3 protocol.BlockInfo) ([]model.Availability, error) {
-	fake.availabilityMutex.Lock()
-	ret, specificReturn := fake.availabilityReturnsOnCall[len(fake.availabilityArgsForCall)]
-	fake.availabilityArgsForCall = append(fake.availabilityArgsForCall, struct {
+func (fake *Model) ApproveRemoteChanges(arg1 string) error {
+	fake.approveRemoteChangesMutex.Lock()
+	ret, specificReturn := fake.approveRemoteChangesReturnsOnCall[len(fake.approveRemoteChangesArgsForCall)]
+	fake.approveRemoteChangesArgsForCall = append(fake.approveRemoteChangesArgsForCall, struct {
 		arg1 string
-		arg2 protocol.FileInfo
-		arg3 protocol.BlockInfo
-	}{arg1, arg2, arg3})
-	stub := fake.AvailabilityStub
-	fakeReturns := fake.availabilityReturns
-	fake.recordInvocation("Availability", []interface{}{arg1, arg2, arg3})
-	fake.availabilityMutex.Unlock()
+	}{arg1})
+	stub := fake.ApproveRemoteChangesStub
+	fakeReturns := fake.approveRemoteChangesReturns
+	fake.recordInvocation("ApproveRemoteChanges", []interface{}{arg1})
+	fake.approveRemoteChangesMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1
 }
 
-func (fake *Model) AvailabilityCallCount() int {
-	fake.availabilityMutex.RLock()
-	defer fake.availabilityMutex.RUnlock()
-	return len(fake.availabilityArgsForCall)
+func (fake *Model) ApproveRemoteChangesCallCount() int {
+	fake.approveRemoteChangesMutex.RLock()
+	defer fake.approveRemoteChangesMutex.RUnlock()
+	return len(fake.approveRemoteChangesArgsForCall)
 }
 
-func (fake *Model) AvailabilityCalls(stub func(string, protocol.FileInfo, protocol.BlockInfo) ([]model.Availability, error)) {
-	fake.availabilityMutex.Lock()
-	defer fake.availabilityMutex.Unlock()
-	fake.AvailabilityStub = stub
+func (fake *Model) ApproveRemoteChangesCalls(stub func(string) error) {
+	fake.approveRemoteChangesMutex.Lock()
+	defer fake.approveRemoteChangesMutex.Unlock()
+	fake.ApproveRemoteChangesStub = stub
 }
 
-func (fake *Model) AvailabilityArgsForCall(i int) (string, protocol.FileInfo, protocol.BlockInfo) {
+func (fake *Model) ApproveRemoteChangesArgsForCall(i int) string {
+	fake.approveRemoteChangesMutex.RLock()
+	defer fake.approveRemoteChangesMutex.RUnlock()
+	argsForCall := fake.approveRemoteChangesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) ApproveRemoteChangesReturns(result1 error) {
+	fake.approveRemoteChangesMutex.Lock()
+	defer fake.approveRemoteChangesMutex.Unlock()
+	fake.ApproveRemoteChangesStub = nil
+	fake.approveRemoteChangesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ApproveRemoteChangesReturnsOnCall(i int, result1 error) {
+	fake.approveRemoteChangesMutex.Lock()
+	defer fake.approveRemoteChangesMutex.Unlock()
+	fake.ApproveRemoteChangesStub = nil
+	if fake.approveRemoteChangesReturnsOnCall == nil {
+		fake.approveRemoteChangesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.approveRemoteChangesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) Availability(arg1 string, arg2 protocol.FileInfo, arg3 protocol.BlockInfo) ([]model.Availability, error) {
+	fake.availabilityMutex.Lock()
+	ret, specificReturn := fake.availabilityReturnsOnCall[len(fake.availabilityArgsForCall)]
+	fake.availabilityArgsForCall = append(fake.availabilityArgsForCall, struct {
+		arg1 string
+		arg2 protocol.FileInfo
+		arg3 protocol.BlockInfo
+	}{arg1, arg2, arg3})
+	stub := fake.AvailabilityStub
+	fakeReturns := fake.availabilityReturns
+	fake.recordInvocation("Availability", []interface{}{arg1, arg2, arg3})
+	fake.availabilityMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) AvailabilityCallCount() int {
+	fake.availabilityMutex.RLock()
+	defer fake.availabilityMutex.RUnlock()
+	return len(fake.availabilityArgsForCall)
+}
+
+func (fake *Model) AvailabilityCalls(stub func(string, protocol.FileInfo, protocol.BlockInfo) ([]model.Availability, error)) {
+	fake.availabilityMutex.Lock()
+	defer fake.availabilityMutex.Unlock()
+	fake.AvailabilityStub = stub
+}
+
+func (fake *Model) AvailabilityArgsForCall(i int) (string, protocol.FileInfo, protocol.BlockInfo) {
 	fake.availabilityMutex.RLock()
 	defer fake.availabilityMutex.RUnlock()
 	argsForCall := fake.availabilityArgsForCall[i]
@@ -871,6 +1390,70 @@ func (fake *Model) BringToFrontArgsForCall(i int) (string, string) {
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *Model) CaseConflicts(arg1 string) ([]model.CaseConflict, error) {
+	fake.caseConflictsMutex.Lock()
+	ret, specificReturn := fake.caseConflictsReturnsOnCall[len(fake.caseConflictsArgsForCall)]
+	fake.caseConflictsArgsForCall = append(fake.caseConflictsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CaseConflictsStub
+	fakeReturns := fake.caseConflictsReturns
+	fake.recordInvocation("CaseConflicts", []interface{}{arg1})
+	fake.caseConflictsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) CaseConflictsCallCount() int {
+	fake.caseConflictsMutex.RLock()
+	defer fake.caseConflictsMutex.RUnlock()
+	return len(fake.caseConflictsArgsForCall)
+}
+
+func (fake *Model) CaseConflictsCalls(stub func(string) ([]model.CaseConflict, error)) {
+	fake.caseConflictsMutex.Lock()
+	defer fake.caseConflictsMutex.Unlock()
+	fake.CaseConflictsStub = stub
+}
+
+func (fake *Model) CaseConflictsArgsForCall(i int) string {
+	fake.caseConflictsMutex.RLock()
+	defer fake.caseConflictsMutex.RUnlock()
+	argsForCall := fake.caseConflictsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) CaseConflictsReturns(result1 []model.CaseConflict, result2 error) {
+	fake.caseConflictsMutex.Lock()
+	defer fake.caseConflictsMutex.Unlock()
+	fake.CaseConflictsStub = nil
+	fake.caseConflictsReturns = struct {
+		result1 []model.CaseConflict
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) CaseConflictsReturnsOnCall(i int, result1 []model.CaseConflict, result2 error) {
+	fake.caseConflictsMutex.Lock()
+	defer fake.caseConflictsMutex.Unlock()
+	fake.CaseConflictsStub = nil
+	if fake.caseConflictsReturnsOnCall == nil {
+		fake.caseConflictsReturnsOnCall = make(map[int]struct {
+			result1 []model.CaseConflict
+			result2 error
+		})
+	}
+	fake.caseConflictsReturnsOnCall[i] = struct {
+		result1 []model.CaseConflict
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) Closed(arg1 protocol.Connection, arg2 error) {
 	fake.closedMutex.Lock()
 	fake.closedArgsForCall = append(fake.closedArgsForCall, struct {
@@ -966,6 +1549,59 @@ func (fake *Model) ClusterConfigReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Model) ClusterState() []model.ClusterFolderState {
+	fake.clusterStateMutex.Lock()
+	ret, specificReturn := fake.clusterStateReturnsOnCall[len(fake.clusterStateArgsForCall)]
+	fake.clusterStateArgsForCall = append(fake.clusterStateArgsForCall, struct {
+	}{})
+	stub := fake.ClusterStateStub
+	fakeReturns := fake.clusterStateReturns
+	fake.recordInvocation("ClusterState", []interface{}{})
+	fake.clusterStateMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) ClusterStateCallCount() int {
+	fake.clusterStateMutex.RLock()
+	defer fake.clusterStateMutex.RUnlock()
+	return len(fake.clusterStateArgsForCall)
+}
+
+func (fake *Model) ClusterStateCalls(stub func() []model.ClusterFolderState) {
+	fake.clusterStateMutex.Lock()
+	defer fake.clusterStateMutex.Unlock()
+	fake.ClusterStateStub = stub
+}
+
+func (fake *Model) ClusterStateReturns(result1 []model.ClusterFolderState) {
+	fake.clusterStateMutex.Lock()
+	defer fake.clusterStateMutex.Unlock()
+	fake.ClusterStateStub = nil
+	fake.clusterStateReturns = struct {
+		result1 []model.ClusterFolderState
+	}{result1}
+}
+
+func (fake *Model) ClusterStateReturnsOnCall(i int, result1 []model.ClusterFolderState) {
+	fake.clusterStateMutex.Lock()
+	defer fake.clusterStateMutex.Unlock()
+	fake.ClusterStateStub = nil
+	if fake.clusterStateReturnsOnCall == nil {
+		fake.clusterStateReturnsOnCall = make(map[int]struct {
+			result1 []model.ClusterFolderState
+		})
+	}
+	fake.clusterStateReturnsOnCall[i] = struct {
+		result1 []model.ClusterFolderState
+	}{result1}
+}
+
 func (fake *Model) Completion(arg1 protocol.DeviceID, arg2 string) (model.FolderCompletion, error) {
 	fake.completionMutex.Lock()
 	ret, specificReturn := fake.completionReturnsOnCall[len(fake.completionArgsForCall)]
@@ -1381,6 +2017,71 @@ func (fake *Model) DelayScanArgsForCall(i int) (string, time.Duration) {
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *Model) DeletedFiles(arg1 string, arg2 string) ([]model.DeletedFile, error) {
+	fake.deletedFilesMutex.Lock()
+	ret, specificReturn := fake.deletedFilesReturnsOnCall[len(fake.deletedFilesArgsForCall)]
+	fake.deletedFilesArgsForCall = append(fake.deletedFilesArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeletedFilesStub
+	fakeReturns := fake.deletedFilesReturns
+	fake.recordInvocation("DeletedFiles", []interface{}{arg1, arg2})
+	fake.deletedFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) DeletedFilesCallCount() int {
+	fake.deletedFilesMutex.RLock()
+	defer fake.deletedFilesMutex.RUnlock()
+	return len(fake.deletedFilesArgsForCall)
+}
+
+func (fake *Model) DeletedFilesCalls(stub func(string, string) ([]model.DeletedFile, error)) {
+	fake.deletedFilesMutex.Lock()
+	defer fake.deletedFilesMutex.Unlock()
+	fake.DeletedFilesStub = stub
+}
+
+func (fake *Model) DeletedFilesArgsForCall(i int) (string, string) {
+	fake.deletedFilesMutex.RLock()
+	defer fake.deletedFilesMutex.RUnlock()
+	argsForCall := fake.deletedFilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) DeletedFilesReturns(result1 []model.DeletedFile, result2 error) {
+	fake.deletedFilesMutex.Lock()
+	defer fake.deletedFilesMutex.Unlock()
+	fake.DeletedFilesStub = nil
+	fake.deletedFilesReturns = struct {
+		result1 []model.DeletedFile
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) DeletedFilesReturnsOnCall(i int, result1 []model.DeletedFile, result2 error) {
+	fake.deletedFilesMutex.Lock()
+	defer fake.deletedFilesMutex.Unlock()
+	fake.DeletedFilesStub = nil
+	if fake.deletedFilesReturnsOnCall == nil {
+		fake.deletedFilesReturnsOnCall = make(map[int]struct {
+			result1 []model.DeletedFile
+			result2 error
+		})
+	}
+	fake.deletedFilesReturnsOnCall[i] = struct {
+		result1 []model.DeletedFile
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) DeviceStatistics() (map[protocol.DeviceID]stats.DeviceStatistics, error) {
 	fake.deviceStatisticsMutex.Lock()
 	ret, specificReturn := fake.deviceStatisticsReturnsOnCall[len(fake.deviceStatisticsArgsForCall)]
@@ -1622,6 +2323,127 @@ func (fake *Model) DownloadProgressReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Model) EncryptionRotation(arg1 string, arg2 protocol.DeviceID) (model.EncryptionRotation, error) {
+	fake.encryptionRotationMutex.Lock()
+	ret, specificReturn := fake.encryptionRotationReturnsOnCall[len(fake.encryptionRotationArgsForCall)]
+	fake.encryptionRotationArgsForCall = append(fake.encryptionRotationArgsForCall, struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.EncryptionRotationStub
+	fakeReturns := fake.encryptionRotationReturns
+	fake.recordInvocation("EncryptionRotation", []interface{}{arg1, arg2})
+	fake.encryptionRotationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) EncryptionRotationCallCount() int {
+	fake.encryptionRotationMutex.RLock()
+	defer fake.encryptionRotationMutex.RUnlock()
+	return len(fake.encryptionRotationArgsForCall)
+}
+
+func (fake *Model) EncryptionRotationCalls(stub func(string, protocol.DeviceID) (model.EncryptionRotation, error)) {
+	fake.encryptionRotationMutex.Lock()
+	defer fake.encryptionRotationMutex.Unlock()
+	fake.EncryptionRotationStub = stub
+}
+
+func (fake *Model) EncryptionRotationArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.encryptionRotationMutex.RLock()
+	defer fake.encryptionRotationMutex.RUnlock()
+	argsForCall := fake.encryptionRotationArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) EncryptionRotationReturns(result1 model.EncryptionRotation, result2 error) {
+	fake.encryptionRotationMutex.Lock()
+	defer fake.encryptionRotationMutex.Unlock()
+	fake.EncryptionRotationStub = nil
+	fake.encryptionRotationReturns = struct {
+		result1 model.EncryptionRotation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) EncryptionRotationReturnsOnCall(i int, result1 model.EncryptionRotation, result2 error) {
+	fake.encryptionRotationMutex.Lock()
+	defer fake.encryptionRotationMutex.Unlock()
+	fake.EncryptionRotationStub = nil
+	if fake.encryptionRotationReturnsOnCall == nil {
+		fake.encryptionRotationReturnsOnCall = make(map[int]struct {
+			result1 model.EncryptionRotation
+			result2 error
+		})
+	}
+	fake.encryptionRotationReturnsOnCall[i] = struct {
+		result1 model.EncryptionRotation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) FileActivity() (map[string][]stats.DailyActivity, error) {
+	fake.fileActivityMutex.Lock()
+	ret, specificReturn := fake.fileActivityReturnsOnCall[len(fake.fileActivityArgsForCall)]
+	fake.fileActivityArgsForCall = append(fake.fileActivityArgsForCall, struct {
+	}{})
+	stub := fake.FileActivityStub
+	fakeReturns := fake.fileActivityReturns
+	fake.recordInvocation("FileActivity", []interface{}{})
+	fake.fileActivityMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) FileActivityCallCount() int {
+	fake.fileActivityMutex.RLock()
+	defer fake.fileActivityMutex.RUnlock()
+	return len(fake.fileActivityArgsForCall)
+}
+
+func (fake *Model) FileActivityCalls(stub func() (map[string][]stats.DailyActivity, error)) {
+	fake.fileActivityMutex.Lock()
+	defer fake.fileActivityMutex.Unlock()
+	fake.FileActivityStub = stub
+}
+
+func (fake *Model) FileActivityReturns(result1 map[string][]stats.DailyActivity, result2 error) {
+	fake.fileActivityMutex.Lock()
+	defer fake.fileActivityMutex.Unlock()
+	fake.FileActivityStub = nil
+	fake.fileActivityReturns = struct {
+		result1 map[string][]stats.DailyActivity
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) FileActivityReturnsOnCall(i int, result1 map[string][]stats.DailyActivity, result2 error) {
+	fake.fileActivityMutex.Lock()
+	defer fake.fileActivityMutex.Unlock()
+	fake.FileActivityStub = nil
+	if fake.fileActivityReturnsOnCall == nil {
+		fake.fileActivityReturnsOnCall = make(map[int]struct {
+			result1 map[string][]stats.DailyActivity
+			result2 error
+		})
+	}
+	fake.fileActivityReturnsOnCall[i] = struct {
+		result1 map[string][]stats.DailyActivity
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) FolderErrors(arg1 string) ([]model.FileError, error) {
 	fake.folderErrorsMutex.Lock()
 	ret, specificReturn := fake.folderErrorsReturnsOnCall[len(fake.folderErrorsArgsForCall)]
@@ -1686,6 +2508,59 @@ func (fake *Model) FolderErrorsReturnsOnCall(i int, result1 []model.FileError, r
 	}{result1, result2}
 }
 
+func (fake *Model) FolderPathWarnings() []model.FolderPathWarning {
+	fake.folderPathWarningsMutex.Lock()
+	ret, specificReturn := fake.folderPathWarningsReturnsOnCall[len(fake.folderPathWarningsArgsForCall)]
+	fake.folderPathWarningsArgsForCall = append(fake.folderPathWarningsArgsForCall, struct {
+	}{})
+	stub := fake.FolderPathWarningsStub
+	fakeReturns := fake.folderPathWarningsReturns
+	fake.recordInvocation("FolderPathWarnings", []interface{}{})
+	fake.folderPathWarningsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) FolderPathWarningsCallCount() int {
+	fake.folderPathWarningsMutex.RLock()
+	defer fake.folderPathWarningsMutex.RUnlock()
+	return len(fake.folderPathWarningsArgsForCall)
+}
+
+func (fake *Model) FolderPathWarningsCalls(stub func() []model.FolderPathWarning) {
+	fake.folderPathWarningsMutex.Lock()
+	defer fake.folderPathWarningsMutex.Unlock()
+	fake.FolderPathWarningsStub = stub
+}
+
+func (fake *Model) FolderPathWarningsReturns(result1 []model.FolderPathWarning) {
+	fake.folderPathWarningsMutex.Lock()
+	defer fake.folderPathWarningsMutex.Unlock()
+	fake.FolderPathWarningsStub = nil
+	fake.folderPathWarningsReturns = struct {
+		result1 []model.FolderPathWarning
+	}{result1}
+}
+
+func (fake *Model) FolderPathWarningsReturnsOnCall(i int, result1 []model.FolderPathWarning) {
+	fake.folderPathWarningsMutex.Lock()
+	defer fake.folderPathWarningsMutex.Unlock()
+	fake.FolderPathWarningsStub = nil
+	if fake.folderPathWarningsReturnsOnCall == nil {
+		fake.folderPathWarningsReturnsOnCall = make(map[int]struct {
+			result1 []model.FolderPathWarning
+		})
+	}
+	fake.folderPathWarningsReturnsOnCall[i] = struct {
+		result1 []model.FolderPathWarning
+	}{result1}
+}
+
 func (fake *Model) FolderProgressBytesCompleted(arg1 string) int64 {
 	fake.folderProgressBytesCompletedMutex.Lock()
 	ret, specificReturn := fake.folderProgressBytesCompletedReturnsOnCall[len(fake.folderProgressBytesCompletedArgsForCall)]
@@ -1747,6 +2622,70 @@ func (fake *Model) FolderProgressBytesCompletedReturnsOnCall(i int, result1 int6
 	}{result1}
 }
 
+func (fake *Model) FolderSizeHistory(arg1 string) (stats.SizeHistory, error) {
+	fake.folderSizeHistoryMutex.Lock()
+	ret, specificReturn := fake.folderSizeHistoryReturnsOnCall[len(fake.folderSizeHistoryArgsForCall)]
+	fake.folderSizeHistoryArgsForCall = append(fake.folderSizeHistoryArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderSizeHistoryStub
+	fakeReturns := fake.folderSizeHistoryReturns
+	fake.recordInvocation("FolderSizeHistory", []interface{}{arg1})
+	fake.folderSizeHistoryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) FolderSizeHistoryCallCount() int {
+	fake.folderSizeHistoryMutex.RLock()
+	defer fake.folderSizeHistoryMutex.RUnlock()
+	return len(fake.folderSizeHistoryArgsForCall)
+}
+
+func (fake *Model) FolderSizeHistoryCalls(stub func(string) (stats.SizeHistory, error)) {
+	fake.folderSizeHistoryMutex.Lock()
+	defer fake.folderSizeHistoryMutex.Unlock()
+	fake.FolderSizeHistoryStub = stub
+}
+
+func (fake *Model) FolderSizeHistoryArgsForCall(i int) string {
+	fake.folderSizeHistoryMutex.RLock()
+	defer fake.folderSizeHistoryMutex.RUnlock()
+	argsForCall := fake.folderSizeHistoryArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) FolderSizeHistoryReturns(result1 stats.SizeHistory, result2 error) {
+	fake.folderSizeHistoryMutex.Lock()
+	defer fake.folderSizeHistoryMutex.Unlock()
+	fake.FolderSizeHistoryStub = nil
+	fake.folderSizeHistoryReturns = struct {
+		result1 stats.SizeHistory
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) FolderSizeHistoryReturnsOnCall(i int, result1 stats.SizeHistory, result2 error) {
+	fake.folderSizeHistoryMutex.Lock()
+	defer fake.folderSizeHistoryMutex.Unlock()
+	fake.FolderSizeHistoryStub = nil
+	if fake.folderSizeHistoryReturnsOnCall == nil {
+		fake.folderSizeHistoryReturnsOnCall = make(map[int]struct {
+			result1 stats.SizeHistory
+			result2 error
+		})
+	}
+	fake.folderSizeHistoryReturnsOnCall[i] = struct {
+		result1 stats.SizeHistory
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) FolderStatistics() (map[string]stats.FolderStatistics, error) {
 	fake.folderStatisticsMutex.Lock()
 	ret, specificReturn := fake.folderStatisticsReturnsOnCall[len(fake.folderStatisticsArgsForCall)]
@@ -1867,21 +2806,20 @@ func (fake *Model) GetFolderVersionsReturnsOnCall(i int, result1 map[string][]ve
 	}{result1, result2}
 }
 
-func (fake *Model) GlobalDirectoryTree(arg1 string, arg2 string, arg3 int, arg4 bool) ([]*model.TreeEntry, error) {
-	fake.globalDirectoryTreeMutex.Lock()
-	ret, specificReturn := fake.globalDirectoryTreeReturnsOnCall[len(fake.globalDirectoryTreeArgsForCall)]
-	fake.globalDirectoryTreeArgsForCall = append(fake.globalDirectoryTreeArgsForCall, struct {
+func (fake *Model) GetFolderVersionsBetween(arg1 string, arg2 time.Time, arg3 time.Time) (map[string][]versioner.FileVersion, error) {
+	fake.getFolderVersionsBetweenMutex.Lock()
+	ret, specificReturn := fake.getFolderVersionsBetweenReturnsOnCall[len(fake.getFolderVersionsBetweenArgsForCall)]
+	fake.getFolderVersionsBetweenArgsForCall = append(fake.getFolderVersionsBetweenArgsForCall, struct {
 		arg1 string
-		arg2 string
-		arg3 int
-		arg4 bool
-	}{arg1, arg2, arg3, arg4})
-	stub := fake.GlobalDirectoryTreeStub
-	fakeReturns := fake.globalDirectoryTreeReturns
-	fake.recordInvocation("GlobalDirectoryTree", []interface{}{arg1, arg2, arg3, arg4})
-	fake.globalDirectoryTreeMutex.Unlock()
+		arg2 time.Time
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	stub := fake.GetFolderVersionsBetweenStub
+	fakeReturns := fake.getFolderVersionsBetweenReturns
+	fake.recordInvocation("GetFolderVersionsBetween", []interface{}{arg1, arg2, arg3})
+	fake.getFolderVersionsBetweenMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -1889,14 +2827,81 @@ func (fake *Model) GlobalDirectoryTree(arg1 string, arg2 string, arg3 int, arg4
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *Model) GlobalDirectoryTreeCallCount() int {
-	fake.globalDirectoryTreeMutex.RLock()
-	defer fake.globalDirectoryTreeMutex.RUnlock()
-	return len(fake.globalDirectoryTreeArgsForCall)
+func (fake *Model) GetFolderVersionsBetweenCallCount() int {
+	fake.getFolderVersionsBetweenMutex.RLock()
+	defer fake.getFolderVersionsBetweenMutex.RUnlock()
+	return len(fake.getFolderVersionsBetweenArgsForCall)
 }
 
-func (fake *Model) GlobalDirectoryTreeCalls(stub func(string, string, int, bool) ([]*model.TreeEntry, error)) {
-	fake.globalDirectoryTreeMutex.Lock()
+func (fake *Model) GetFolderVersionsBetweenCalls(stub func(string, time.Time, time.Time) (map[string][]versioner.FileVersion, error)) {
+	fake.getFolderVersionsBetweenMutex.Lock()
+	defer fake.getFolderVersionsBetweenMutex.Unlock()
+	fake.GetFolderVersionsBetweenStub = stub
+}
+
+func (fake *Model) GetFolderVersionsBetweenArgsForCall(i int) (string, time.Time, time.Time) {
+	fake.getFolderVersionsBetweenMutex.RLock()
+	defer fake.getFolderVersionsBetweenMutex.RUnlock()
+	argsForCall := fake.getFolderVersionsBetweenArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) GetFolderVersionsBetweenReturns(result1 map[string][]versioner.FileVersion, result2 error) {
+	fake.getFolderVersionsBetweenMutex.Lock()
+	defer fake.getFolderVersionsBetweenMutex.Unlock()
+	fake.GetFolderVersionsBetweenStub = nil
+	fake.getFolderVersionsBetweenReturns = struct {
+		result1 map[string][]versioner.FileVersion
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) GetFolderVersionsBetweenReturnsOnCall(i int, result1 map[string][]versioner.FileVersion, result2 error) {
+	fake.getFolderVersionsBetweenMutex.Lock()
+	defer fake.getFolderVersionsBetweenMutex.Unlock()
+	fake.GetFolderVersionsBetweenStub = nil
+	if fake.getFolderVersionsBetweenReturnsOnCall == nil {
+		fake.getFolderVersionsBetweenReturnsOnCall = make(map[int]struct {
+			result1 map[string][]versioner.FileVersion
+			result2 error
+		})
+	}
+	fake.getFolderVersionsBetweenReturnsOnCall[i] = struct {
+		result1 map[string][]versioner.FileVersion
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) GlobalDirectoryTree(arg1 string, arg2 string, arg3 int, arg4 bool) ([]*model.TreeEntry, error) {
+	fake.globalDirectoryTreeMutex.Lock()
+	ret, specificReturn := fake.globalDirectoryTreeReturnsOnCall[len(fake.globalDirectoryTreeArgsForCall)]
+	fake.globalDirectoryTreeArgsForCall = append(fake.globalDirectoryTreeArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 int
+		arg4 bool
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.GlobalDirectoryTreeStub
+	fakeReturns := fake.globalDirectoryTreeReturns
+	fake.recordInvocation("GlobalDirectoryTree", []interface{}{arg1, arg2, arg3, arg4})
+	fake.globalDirectoryTreeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) GlobalDirectoryTreeCallCount() int {
+	fake.globalDirectoryTreeMutex.RLock()
+	defer fake.globalDirectoryTreeMutex.RUnlock()
+	return len(fake.globalDirectoryTreeArgsForCall)
+}
+
+func (fake *Model) GlobalDirectoryTreeCalls(stub func(string, string, int, bool) ([]*model.TreeEntry, error)) {
+	fake.globalDirectoryTreeMutex.Lock()
 	defer fake.globalDirectoryTreeMutex.Unlock()
 	fake.GlobalDirectoryTreeStub = stub
 }
@@ -2451,6 +3456,143 @@ func (fake *Model) LocalSizeReturnsOnCall(i int, result1 db.Counts, result2 erro
 	}{result1, result2}
 }
 
+func (fake *Model) ManageDevice(arg1 context.Context, arg2 protocol.DeviceID, arg3 protocol.ManagementOperation, arg4 []byte) ([]byte, error) {
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.manageDeviceMutex.Lock()
+	ret, specificReturn := fake.manageDeviceReturnsOnCall[len(fake.manageDeviceArgsForCall)]
+	fake.manageDeviceArgsForCall = append(fake.manageDeviceArgsForCall, struct {
+		arg1 context.Context
+		arg2 protocol.DeviceID
+		arg3 protocol.ManagementOperation
+		arg4 []byte
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.ManageDeviceStub
+	fakeReturns := fake.manageDeviceReturns
+	fake.recordInvocation("ManageDevice", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.manageDeviceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) ManageDeviceCallCount() int {
+	fake.manageDeviceMutex.RLock()
+	defer fake.manageDeviceMutex.RUnlock()
+	return len(fake.manageDeviceArgsForCall)
+}
+
+func (fake *Model) ManageDeviceCalls(stub func(context.Context, protocol.DeviceID, protocol.ManagementOperation, []byte) ([]byte, error)) {
+	fake.manageDeviceMutex.Lock()
+	defer fake.manageDeviceMutex.Unlock()
+	fake.ManageDeviceStub = stub
+}
+
+func (fake *Model) ManageDeviceArgsForCall(i int) (context.Context, protocol.DeviceID, protocol.ManagementOperation, []byte) {
+	fake.manageDeviceMutex.RLock()
+	defer fake.manageDeviceMutex.RUnlock()
+	argsForCall := fake.manageDeviceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Model) ManageDeviceReturns(result1 []byte, result2 error) {
+	fake.manageDeviceMutex.Lock()
+	defer fake.manageDeviceMutex.Unlock()
+	fake.ManageDeviceStub = nil
+	fake.manageDeviceReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ManageDeviceReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.manageDeviceMutex.Lock()
+	defer fake.manageDeviceMutex.Unlock()
+	fake.ManageDeviceStub = nil
+	if fake.manageDeviceReturnsOnCall == nil {
+		fake.manageDeviceReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.manageDeviceReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ManagementRequest(arg1 protocol.Connection, arg2 *protocol.ManagementRequest) ([]byte, error) {
+	fake.managementRequestMutex.Lock()
+	ret, specificReturn := fake.managementRequestReturnsOnCall[len(fake.managementRequestArgsForCall)]
+	fake.managementRequestArgsForCall = append(fake.managementRequestArgsForCall, struct {
+		arg1 protocol.Connection
+		arg2 *protocol.ManagementRequest
+	}{arg1, arg2})
+	stub := fake.ManagementRequestStub
+	fakeReturns := fake.managementRequestReturns
+	fake.recordInvocation("ManagementRequest", []interface{}{arg1, arg2})
+	fake.managementRequestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) ManagementRequestCallCount() int {
+	fake.managementRequestMutex.RLock()
+	defer fake.managementRequestMutex.RUnlock()
+	return len(fake.managementRequestArgsForCall)
+}
+
+func (fake *Model) ManagementRequestCalls(stub func(protocol.Connection, *protocol.ManagementRequest) ([]byte, error)) {
+	fake.managementRequestMutex.Lock()
+	defer fake.managementRequestMutex.Unlock()
+	fake.ManagementRequestStub = stub
+}
+
+func (fake *Model) ManagementRequestArgsForCall(i int) (protocol.Connection, *protocol.ManagementRequest) {
+	fake.managementRequestMutex.RLock()
+	defer fake.managementRequestMutex.RUnlock()
+	argsForCall := fake.managementRequestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) ManagementRequestReturns(result1 []byte, result2 error) {
+	fake.managementRequestMutex.Lock()
+	defer fake.managementRequestMutex.Unlock()
+	fake.ManagementRequestStub = nil
+	fake.managementRequestReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ManagementRequestReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.managementRequestMutex.Lock()
+	defer fake.managementRequestMutex.Unlock()
+	fake.ManagementRequestStub = nil
+	if fake.managementRequestReturnsOnCall == nil {
+		fake.managementRequestReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.managementRequestReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) NeedFolderFiles(arg1 string, arg2 int, arg3 int) ([]protocol.FileInfo, []protocol.FileInfo, []protocol.FileInfo, error) {
 	fake.needFolderFilesMutex.Lock()
 	ret, specificReturn := fake.needFolderFilesReturnsOnCall[len(fake.needFolderFilesArgsForCall)]
@@ -2523,6 +3665,70 @@ func (fake *Model) NeedFolderFilesReturnsOnCall(i int, result1 []protocol.FileIn
 	}{result1, result2, result3, result4}
 }
 
+func (fake *Model) NeedFolderFilesIter(arg1 string) (iter.Seq[protocol.FileInfo], func() error) {
+	fake.needFolderFilesIterMutex.Lock()
+	ret, specificReturn := fake.needFolderFilesIterReturnsOnCall[len(fake.needFolderFilesIterArgsForCall)]
+	fake.needFolderFilesIterArgsForCall = append(fake.needFolderFilesIterArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.NeedFolderFilesIterStub
+	fakeReturns := fake.needFolderFilesIterReturns
+	fake.recordInvocation("NeedFolderFilesIter", []interface{}{arg1})
+	fake.needFolderFilesIterMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) NeedFolderFilesIterCallCount() int {
+	fake.needFolderFilesIterMutex.RLock()
+	defer fake.needFolderFilesIterMutex.RUnlock()
+	return len(fake.needFolderFilesIterArgsForCall)
+}
+
+func (fake *Model) NeedFolderFilesIterCalls(stub func(string) (iter.Seq[protocol.FileInfo], func() error)) {
+	fake.needFolderFilesIterMutex.Lock()
+	defer fake.needFolderFilesIterMutex.Unlock()
+	fake.NeedFolderFilesIterStub = stub
+}
+
+func (fake *Model) NeedFolderFilesIterArgsForCall(i int) string {
+	fake.needFolderFilesIterMutex.RLock()
+	defer fake.needFolderFilesIterMutex.RUnlock()
+	argsForCall := fake.needFolderFilesIterArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) NeedFolderFilesIterReturns(result1 iter.Seq[protocol.FileInfo], result2 func() error) {
+	fake.needFolderFilesIterMutex.Lock()
+	defer fake.needFolderFilesIterMutex.Unlock()
+	fake.NeedFolderFilesIterStub = nil
+	fake.needFolderFilesIterReturns = struct {
+		result1 iter.Seq[protocol.FileInfo]
+		result2 func() error
+	}{result1, result2}
+}
+
+func (fake *Model) NeedFolderFilesIterReturnsOnCall(i int, result1 iter.Seq[protocol.FileInfo], result2 func() error) {
+	fake.needFolderFilesIterMutex.Lock()
+	defer fake.needFolderFilesIterMutex.Unlock()
+	fake.NeedFolderFilesIterStub = nil
+	if fake.needFolderFilesIterReturnsOnCall == nil {
+		fake.needFolderFilesIterReturnsOnCall = make(map[int]struct {
+			result1 iter.Seq[protocol.FileInfo]
+			result2 func() error
+		})
+	}
+	fake.needFolderFilesIterReturnsOnCall[i] = struct {
+		result1 iter.Seq[protocol.FileInfo]
+		result2 func() error
+	}{result1, result2}
+}
+
 func (fake *Model) NeedSize(arg1 string, arg2 protocol.DeviceID) (db.Counts, error) {
 	fake.needSizeMutex.Lock()
 	ret, specificReturn := fake.needSizeReturnsOnCall[len(fake.needSizeArgsForCall)]
@@ -2651,6 +3857,72 @@ func (fake *Model) OnHelloReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Model) OpenFolderVersion(arg1 string, arg2 string, arg3 time.Time) (fs.File, error) {
+	fake.openFolderVersionMutex.Lock()
+	ret, specificReturn := fake.openFolderVersionReturnsOnCall[len(fake.openFolderVersionArgsForCall)]
+	fake.openFolderVersionArgsForCall = append(fake.openFolderVersionArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 time.Time
+	}{arg1, arg2, arg3})
+	stub := fake.OpenFolderVersionStub
+	fakeReturns := fake.openFolderVersionReturns
+	fake.recordInvocation("OpenFolderVersion", []interface{}{arg1, arg2, arg3})
+	fake.openFolderVersionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) OpenFolderVersionCallCount() int {
+	fake.openFolderVersionMutex.RLock()
+	defer fake.openFolderVersionMutex.RUnlock()
+	return len(fake.openFolderVersionArgsForCall)
+}
+
+func (fake *Model) OpenFolderVersionCalls(stub func(string, string, time.Time) (fs.File, error)) {
+	fake.openFolderVersionMutex.Lock()
+	defer fake.openFolderVersionMutex.Unlock()
+	fake.OpenFolderVersionStub = stub
+}
+
+func (fake *Model) OpenFolderVersionArgsForCall(i int) (string, string, time.Time) {
+	fake.openFolderVersionMutex.RLock()
+	defer fake.openFolderVersionMutex.RUnlock()
+	argsForCall := fake.openFolderVersionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) OpenFolderVersionReturns(result1 fs.File, result2 error) {
+	fake.openFolderVersionMutex.Lock()
+	defer fake.openFolderVersionMutex.Unlock()
+	fake.OpenFolderVersionStub = nil
+	fake.openFolderVersionReturns = struct {
+		result1 fs.File
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) OpenFolderVersionReturnsOnCall(i int, result1 fs.File, result2 error) {
+	fake.openFolderVersionMutex.Lock()
+	defer fake.openFolderVersionMutex.Unlock()
+	fake.OpenFolderVersionStub = nil
+	if fake.openFolderVersionReturnsOnCall == nil {
+		fake.openFolderVersionReturnsOnCall = make(map[int]struct {
+			result1 fs.File
+			result2 error
+		})
+	}
+	fake.openFolderVersionReturnsOnCall[i] = struct {
+		result1 fs.File
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) Override(arg1 string) {
 	fake.overrideMutex.Lock()
 	fake.overrideArgsForCall = append(fake.overrideArgsForCall, struct {
@@ -2683,6 +3955,69 @@ func (fake *Model) OverrideArgsForCall(i int) string {
 	return argsForCall.arg1
 }
 
+func (fake *Model) PauseAll(arg1 string, arg2 string, arg3 time.Duration) error {
+	fake.pauseAllMutex.Lock()
+	ret, specificReturn := fake.pauseAllReturnsOnCall[len(fake.pauseAllArgsForCall)]
+	fake.pauseAllArgsForCall = append(fake.pauseAllArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.PauseAllStub
+	fakeReturns := fake.pauseAllReturns
+	fake.recordInvocation("PauseAll", []interface{}{arg1, arg2, arg3})
+	fake.pauseAllMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) PauseAllCallCount() int {
+	fake.pauseAllMutex.RLock()
+	defer fake.pauseAllMutex.RUnlock()
+	return len(fake.pauseAllArgsForCall)
+}
+
+func (fake *Model) PauseAllCalls(stub func(string, string, time.Duration) error) {
+	fake.pauseAllMutex.Lock()
+	defer fake.pauseAllMutex.Unlock()
+	fake.PauseAllStub = stub
+}
+
+func (fake *Model) PauseAllArgsForCall(i int) (string, string, time.Duration) {
+	fake.pauseAllMutex.RLock()
+	defer fake.pauseAllMutex.RUnlock()
+	argsForCall := fake.pauseAllArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) PauseAllReturns(result1 error) {
+	fake.pauseAllMutex.Lock()
+	defer fake.pauseAllMutex.Unlock()
+	fake.PauseAllStub = nil
+	fake.pauseAllReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) PauseAllReturnsOnCall(i int, result1 error) {
+	fake.pauseAllMutex.Lock()
+	defer fake.pauseAllMutex.Unlock()
+	fake.PauseAllStub = nil
+	if fake.pauseAllReturnsOnCall == nil {
+		fake.pauseAllReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.pauseAllReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Model) PendingDevices() (map[protocol.DeviceID]db.ObservedDevice, error) {
 	fake.pendingDevicesMutex.Lock()
 	ret, specificReturn := fake.pendingDevicesReturnsOnCall[len(fake.pendingDevicesArgsForCall)]
@@ -2803,69 +4138,334 @@ func (fake *Model) PendingFoldersReturnsOnCall(i int, result1 map[string]db.Pend
 	}{result1, result2}
 }
 
-func (fake *Model) ReceiveOnlySize(arg1 string) (db.Counts, error) {
-	fake.receiveOnlySizeMutex.Lock()
-	ret, specificReturn := fake.receiveOnlySizeReturnsOnCall[len(fake.receiveOnlySizeArgsForCall)]
-	fake.receiveOnlySizeArgsForCall = append(fake.receiveOnlySizeArgsForCall, struct {
+func (fake *Model) PendingRemoteChanges(arg1 string) (model.PendingApproval, bool, error) {
+	fake.pendingRemoteChangesMutex.Lock()
+	ret, specificReturn := fake.pendingRemoteChangesReturnsOnCall[len(fake.pendingRemoteChangesArgsForCall)]
+	fake.pendingRemoteChangesArgsForCall = append(fake.pendingRemoteChangesArgsForCall, struct {
 		arg1 string
 	}{arg1})
-	stub := fake.ReceiveOnlySizeStub
-	fakeReturns := fake.receiveOnlySizeReturns
-	fake.recordInvocation("ReceiveOnlySize", []interface{}{arg1})
-	fake.receiveOnlySizeMutex.Unlock()
+	stub := fake.PendingRemoteChangesStub
+	fakeReturns := fake.pendingRemoteChangesReturns
+	fake.recordInvocation("PendingRemoteChanges", []interface{}{arg1})
+	fake.pendingRemoteChangesMutex.Unlock()
 	if stub != nil {
 		return stub(arg1)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1, ret.result2, ret.result3
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
 }
 
-func (fake *Model) ReceiveOnlySizeCallCount() int {
-	fake.receiveOnlySizeMutex.RLock()
-	defer fake.receiveOnlySizeMutex.RUnlock()
-	return len(fake.receiveOnlySizeArgsForCall)
+func (fake *Model) PendingRemoteChangesCallCount() int {
+	fake.pendingRemoteChangesMutex.RLock()
+	defer fake.pendingRemoteChangesMutex.RUnlock()
+	return len(fake.pendingRemoteChangesArgsForCall)
 }
 
-func (fake *Model) ReceiveOnlySizeCalls(stub func(string) (db.Counts, error)) {
-	fake.receiveOnlySizeMutex.Lock()
-	defer fake.receiveOnlySizeMutex.Unlock()
-	fake.ReceiveOnlySizeStub = stub
+func (fake *Model) PendingRemoteChangesCalls(stub func(string) (model.PendingApproval, bool, error)) {
+	fake.pendingRemoteChangesMutex.Lock()
+	defer fake.pendingRemoteChangesMutex.Unlock()
+	fake.PendingRemoteChangesStub = stub
 }
 
-func (fake *Model) ReceiveOnlySizeArgsForCall(i int) string {
-	fake.receiveOnlySizeMutex.RLock()
-	defer fake.receiveOnlySizeMutex.RUnlock()
-	argsForCall := fake.receiveOnlySizeArgsForCall[i]
+func (fake *Model) PendingRemoteChangesArgsForCall(i int) string {
+	fake.pendingRemoteChangesMutex.RLock()
+	defer fake.pendingRemoteChangesMutex.RUnlock()
+	argsForCall := fake.pendingRemoteChangesArgsForCall[i]
 	return argsForCall.arg1
 }
 
-func (fake *Model) ReceiveOnlySizeReturns(result1 db.Counts, result2 error) {
-	fake.receiveOnlySizeMutex.Lock()
-	defer fake.receiveOnlySizeMutex.Unlock()
-	fake.ReceiveOnlySizeStub = nil
-	fake.receiveOnlySizeReturns = struct {
-		result1 db.Counts
-		result2 error
-	}{result1, result2}
+func (fake *Model) PendingRemoteChangesReturns(result1 model.PendingApproval, result2 bool, result3 error) {
+	fake.pendingRemoteChangesMutex.Lock()
+	defer fake.pendingRemoteChangesMutex.Unlock()
+	fake.PendingRemoteChangesStub = nil
+	fake.pendingRemoteChangesReturns = struct {
+		result1 model.PendingApproval
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
 }
 
-func (fake *Model) ReceiveOnlySizeReturnsOnCall(i int, result1 db.Counts, result2 error) {
-	fake.receiveOnlySizeMutex.Lock()
-	defer fake.receiveOnlySizeMutex.Unlock()
-	fake.ReceiveOnlySizeStub = nil
-	if fake.receiveOnlySizeReturnsOnCall == nil {
-		fake.receiveOnlySizeReturnsOnCall = make(map[int]struct {
-			result1 db.Counts
-			result2 error
+func (fake *Model) PendingRemoteChangesReturnsOnCall(i int, result1 model.PendingApproval, result2 bool, result3 error) {
+	fake.pendingRemoteChangesMutex.Lock()
+	defer fake.pendingRemoteChangesMutex.Unlock()
+	fake.PendingRemoteChangesStub = nil
+	if fake.pendingRemoteChangesReturnsOnCall == nil {
+		fake.pendingRemoteChangesReturnsOnCall = make(map[int]struct {
+			result1 model.PendingApproval
+			result2 bool
+			result3 error
 		})
 	}
-	fake.receiveOnlySizeReturnsOnCall[i] = struct {
-		result1 db.Counts
-		result2 error
-	}{result1, result2}
-}
+	fake.pendingRemoteChangesReturnsOnCall[i] = struct {
+		result1 model.PendingApproval
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *Model) Pinned(arg1 string) ([]string, error) {
+	fake.pinnedMutex.Lock()
+	ret, specificReturn := fake.pinnedReturnsOnCall[len(fake.pinnedArgsForCall)]
+	fake.pinnedArgsForCall = append(fake.pinnedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.PinnedStub
+	fakeReturns := fake.pinnedReturns
+	fake.recordInvocation("Pinned", []interface{}{arg1})
+	fake.pinnedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) PinnedCallCount() int {
+	fake.pinnedMutex.RLock()
+	defer fake.pinnedMutex.RUnlock()
+	return len(fake.pinnedArgsForCall)
+}
+
+func (fake *Model) PinnedCalls(stub func(string) ([]string, error)) {
+	fake.pinnedMutex.Lock()
+	defer fake.pinnedMutex.Unlock()
+	fake.PinnedStub = stub
+}
+
+func (fake *Model) PinnedArgsForCall(i int) string {
+	fake.pinnedMutex.RLock()
+	defer fake.pinnedMutex.RUnlock()
+	argsForCall := fake.pinnedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) PinnedReturns(result1 []string, result2 error) {
+	fake.pinnedMutex.Lock()
+	defer fake.pinnedMutex.Unlock()
+	fake.PinnedStub = nil
+	fake.pinnedReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PinnedReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.pinnedMutex.Lock()
+	defer fake.pinnedMutex.Unlock()
+	fake.PinnedStub = nil
+	if fake.pinnedReturnsOnCall == nil {
+		fake.pinnedReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.pinnedReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PreviewIgnores(arg1 string, arg2 []string) ([]model.NewlyIgnoredFile, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.previewIgnoresMutex.Lock()
+	ret, specificReturn := fake.previewIgnoresReturnsOnCall[len(fake.previewIgnoresArgsForCall)]
+	fake.previewIgnoresArgsForCall = append(fake.previewIgnoresArgsForCall, struct {
+		arg1 string
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.PreviewIgnoresStub
+	fakeReturns := fake.previewIgnoresReturns
+	fake.recordInvocation("PreviewIgnores", []interface{}{arg1, arg2Copy})
+	fake.previewIgnoresMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) PreviewIgnoresCallCount() int {
+	fake.previewIgnoresMutex.RLock()
+	defer fake.previewIgnoresMutex.RUnlock()
+	return len(fake.previewIgnoresArgsForCall)
+}
+
+func (fake *Model) PreviewIgnoresCalls(stub func(string, []string) ([]model.NewlyIgnoredFile, error)) {
+	fake.previewIgnoresMutex.Lock()
+	defer fake.previewIgnoresMutex.Unlock()
+	fake.PreviewIgnoresStub = stub
+}
+
+func (fake *Model) PreviewIgnoresArgsForCall(i int) (string, []string) {
+	fake.previewIgnoresMutex.RLock()
+	defer fake.previewIgnoresMutex.RUnlock()
+	argsForCall := fake.previewIgnoresArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) PreviewIgnoresReturns(result1 []model.NewlyIgnoredFile, result2 error) {
+	fake.previewIgnoresMutex.Lock()
+	defer fake.previewIgnoresMutex.Unlock()
+	fake.PreviewIgnoresStub = nil
+	fake.previewIgnoresReturns = struct {
+		result1 []model.NewlyIgnoredFile
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PreviewIgnoresReturnsOnCall(i int, result1 []model.NewlyIgnoredFile, result2 error) {
+	fake.previewIgnoresMutex.Lock()
+	defer fake.previewIgnoresMutex.Unlock()
+	fake.PreviewIgnoresStub = nil
+	if fake.previewIgnoresReturnsOnCall == nil {
+		fake.previewIgnoresReturnsOnCall = make(map[int]struct {
+			result1 []model.NewlyIgnoredFile
+			result2 error
+		})
+	}
+	fake.previewIgnoresReturnsOnCall[i] = struct {
+		result1 []model.NewlyIgnoredFile
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PreviewPull(arg1 string) (model.PullPreview, error) {
+	fake.previewPullMutex.Lock()
+	ret, specificReturn := fake.previewPullReturnsOnCall[len(fake.previewPullArgsForCall)]
+	fake.previewPullArgsForCall = append(fake.previewPullArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.PreviewPullStub
+	fakeReturns := fake.previewPullReturns
+	fake.recordInvocation("PreviewPull", []interface{}{arg1})
+	fake.previewPullMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) PreviewPullCallCount() int {
+	fake.previewPullMutex.RLock()
+	defer fake.previewPullMutex.RUnlock()
+	return len(fake.previewPullArgsForCall)
+}
+
+func (fake *Model) PreviewPullCalls(stub func(string) (model.PullPreview, error)) {
+	fake.previewPullMutex.Lock()
+	defer fake.previewPullMutex.Unlock()
+	fake.PreviewPullStub = stub
+}
+
+func (fake *Model) PreviewPullArgsForCall(i int) string {
+	fake.previewPullMutex.RLock()
+	defer fake.previewPullMutex.RUnlock()
+	argsForCall := fake.previewPullArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) PreviewPullReturns(result1 model.PullPreview, result2 error) {
+	fake.previewPullMutex.Lock()
+	defer fake.previewPullMutex.Unlock()
+	fake.PreviewPullStub = nil
+	fake.previewPullReturns = struct {
+		result1 model.PullPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PreviewPullReturnsOnCall(i int, result1 model.PullPreview, result2 error) {
+	fake.previewPullMutex.Lock()
+	defer fake.previewPullMutex.Unlock()
+	fake.PreviewPullStub = nil
+	if fake.previewPullReturnsOnCall == nil {
+		fake.previewPullReturnsOnCall = make(map[int]struct {
+			result1 model.PullPreview
+			result2 error
+		})
+	}
+	fake.previewPullReturnsOnCall[i] = struct {
+		result1 model.PullPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ReceiveOnlySize(arg1 string) (db.Counts, error) {
+	fake.receiveOnlySizeMutex.Lock()
+	ret, specificReturn := fake.receiveOnlySizeReturnsOnCall[len(fake.receiveOnlySizeArgsForCall)]
+	fake.receiveOnlySizeArgsForCall = append(fake.receiveOnlySizeArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReceiveOnlySizeStub
+	fakeReturns := fake.receiveOnlySizeReturns
+	fake.recordInvocation("ReceiveOnlySize", []interface{}{arg1})
+	fake.receiveOnlySizeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) ReceiveOnlySizeCallCount() int {
+	fake.receiveOnlySizeMutex.RLock()
+	defer fake.receiveOnlySizeMutex.RUnlock()
+	return len(fake.receiveOnlySizeArgsForCall)
+}
+
+func (fake *Model) ReceiveOnlySizeCalls(stub func(string) (db.Counts, error)) {
+	fake.receiveOnlySizeMutex.Lock()
+	defer fake.receiveOnlySizeMutex.Unlock()
+	fake.ReceiveOnlySizeStub = stub
+}
+
+func (fake *Model) ReceiveOnlySizeArgsForCall(i int) string {
+	fake.receiveOnlySizeMutex.RLock()
+	defer fake.receiveOnlySizeMutex.RUnlock()
+	argsForCall := fake.receiveOnlySizeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) ReceiveOnlySizeReturns(result1 db.Counts, result2 error) {
+	fake.receiveOnlySizeMutex.Lock()
+	defer fake.receiveOnlySizeMutex.Unlock()
+	fake.ReceiveOnlySizeStub = nil
+	fake.receiveOnlySizeReturns = struct {
+		result1 db.Counts
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ReceiveOnlySizeReturnsOnCall(i int, result1 db.Counts, result2 error) {
+	fake.receiveOnlySizeMutex.Lock()
+	defer fake.receiveOnlySizeMutex.Unlock()
+	fake.ReceiveOnlySizeStub = nil
+	if fake.receiveOnlySizeReturnsOnCall == nil {
+		fake.receiveOnlySizeReturnsOnCall = make(map[int]struct {
+			result1 db.Counts
+			result2 error
+		})
+	}
+	fake.receiveOnlySizeReturnsOnCall[i] = struct {
+		result1 db.Counts
+		result2 error
+	}{result1, result2}
+}
 
 func (fake *Model) RemoteNeedFolderFiles(arg1 string, arg2 protocol.DeviceID, arg3 int, arg4 int) ([]protocol.FileInfo, error) {
 	fake.remoteNeedFolderFilesMutex.Lock()
@@ -3063,7 +4663,7 @@ func (fake *Model) RequestReturnsOnCall(i int, result1 protocol.RequestResponse,
 	}{result1, result2}
 }
 
-func (fake *Model) RequestGlobal(arg1 context.Context, arg2 protocol.DeviceID, arg3 string, arg4 string, arg5 int, arg6 int64, arg7 int, arg8 []byte, arg9 bool) ([]byte, error) {
+func (fake *Model) RequestGlobal(arg1 context.Context, arg2 protocol.DeviceID, arg3 string, arg4 string, arg5 int, arg6 int64, arg7 int, arg8 []byte, arg9 bool, arg10 bool) ([]byte, error) {
 	var arg8Copy []byte
 	if arg8 != nil {
 		arg8Copy = make([]byte, len(arg8))
@@ -3072,22 +4672,23 @@ func (fake *Model) RequestGlobal(arg1 context.Context, arg2 protocol.DeviceID, a
 	fake.requestGlobalMutex.Lock()
 	ret, specificReturn := fake.requestGlobalReturnsOnCall[len(fake.requestGlobalArgsForCall)]
 	fake.requestGlobalArgsForCall = append(fake.requestGlobalArgsForCall, struct {
-		arg1 context.Context
-		arg2 protocol.DeviceID
-		arg3 string
-		arg4 string
-		arg5 int
-		arg6 int64
-		arg7 int
-		arg8 []byte
-		arg9 bool
-	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8Copy, arg9})
+		arg1  context.Context
+		arg2  protocol.DeviceID
+		arg3  string
+		arg4  string
+		arg5  int
+		arg6  int64
+		arg7  int
+		arg8  []byte
+		arg9  bool
+		arg10 bool
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8Copy, arg9, arg10})
 	stub := fake.RequestGlobalStub
 	fakeReturns := fake.requestGlobalReturns
-	fake.recordInvocation("RequestGlobal", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8Copy, arg9})
+	fake.recordInvocation("RequestGlobal", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8Copy, arg9, arg10})
 	fake.requestGlobalMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9)
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -3101,17 +4702,17 @@ func (fake *Model) RequestGlobalCallCount() int {
 	return len(fake.requestGlobalArgsForCall)
 }
 
-func (fake *Model) RequestGlobalCalls(stub func(context.Context, protocol.DeviceID, string, string, int, int64, int, []byte, bool) ([]byte, error)) {
+func (fake *Model) RequestGlobalCalls(stub func(context.Context, protocol.DeviceID, string, string, int, int64, int, []byte, bool, bool) ([]byte, error)) {
 	fake.requestGlobalMutex.Lock()
 	defer fake.requestGlobalMutex.Unlock()
 	fake.RequestGlobalStub = stub
 }
 
-func (fake *Model) RequestGlobalArgsForCall(i int) (context.Context, protocol.DeviceID, string, string, int, int64, int, []byte, bool) {
+func (fake *Model) RequestGlobalArgsForCall(i int) (context.Context, protocol.DeviceID, string, string, int, int64, int, []byte, bool, bool) {
 	fake.requestGlobalMutex.RLock()
 	defer fake.requestGlobalMutex.RUnlock()
 	argsForCall := fake.requestGlobalArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7, argsForCall.arg8, argsForCall.arg9
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7, argsForCall.arg8, argsForCall.arg9, argsForCall.arg10
 }
 
 func (fake *Model) RequestGlobalReturns(result1 []byte, result2 error) {
@@ -3140,6 +4741,71 @@ func (fake *Model) RequestGlobalReturnsOnCall(i int, result1 []byte, result2 err
 	}{result1, result2}
 }
 
+func (fake *Model) RescanFile(arg1 string, arg2 string) (protocol.FileInfo, error) {
+	fake.rescanFileMutex.Lock()
+	ret, specificReturn := fake.rescanFileReturnsOnCall[len(fake.rescanFileArgsForCall)]
+	fake.rescanFileArgsForCall = append(fake.rescanFileArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.RescanFileStub
+	fakeReturns := fake.rescanFileReturns
+	fake.recordInvocation("RescanFile", []interface{}{arg1, arg2})
+	fake.rescanFileMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) RescanFileCallCount() int {
+	fake.rescanFileMutex.RLock()
+	defer fake.rescanFileMutex.RUnlock()
+	return len(fake.rescanFileArgsForCall)
+}
+
+func (fake *Model) RescanFileCalls(stub func(string, string) (protocol.FileInfo, error)) {
+	fake.rescanFileMutex.Lock()
+	defer fake.rescanFileMutex.Unlock()
+	fake.RescanFileStub = stub
+}
+
+func (fake *Model) RescanFileArgsForCall(i int) (string, string) {
+	fake.rescanFileMutex.RLock()
+	defer fake.rescanFileMutex.RUnlock()
+	argsForCall := fake.rescanFileArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) RescanFileReturns(result1 protocol.FileInfo, result2 error) {
+	fake.rescanFileMutex.Lock()
+	defer fake.rescanFileMutex.Unlock()
+	fake.RescanFileStub = nil
+	fake.rescanFileReturns = struct {
+		result1 protocol.FileInfo
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) RescanFileReturnsOnCall(i int, result1 protocol.FileInfo, result2 error) {
+	fake.rescanFileMutex.Lock()
+	defer fake.rescanFileMutex.Unlock()
+	fake.RescanFileStub = nil
+	if fake.rescanFileReturnsOnCall == nil {
+		fake.rescanFileReturnsOnCall = make(map[int]struct {
+			result1 protocol.FileInfo
+			result2 error
+		})
+	}
+	fake.rescanFileReturnsOnCall[i] = struct {
+		result1 protocol.FileInfo
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) ResetFolder(arg1 string) error {
 	fake.resetFolderMutex.Lock()
 	ret, specificReturn := fake.resetFolderReturnsOnCall[len(fake.resetFolderArgsForCall)]
@@ -3201,6 +4867,136 @@ func (fake *Model) ResetFolderReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Model) ResolveCaseConflict(arg1 string, arg2 string, arg3 string) error {
+	fake.resolveCaseConflictMutex.Lock()
+	ret, specificReturn := fake.resolveCaseConflictReturnsOnCall[len(fake.resolveCaseConflictArgsForCall)]
+	fake.resolveCaseConflictArgsForCall = append(fake.resolveCaseConflictArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ResolveCaseConflictStub
+	fakeReturns := fake.resolveCaseConflictReturns
+	fake.recordInvocation("ResolveCaseConflict", []interface{}{arg1, arg2, arg3})
+	fake.resolveCaseConflictMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) ResolveCaseConflictCallCount() int {
+	fake.resolveCaseConflictMutex.RLock()
+	defer fake.resolveCaseConflictMutex.RUnlock()
+	return len(fake.resolveCaseConflictArgsForCall)
+}
+
+func (fake *Model) ResolveCaseConflictCalls(stub func(string, string, string) error) {
+	fake.resolveCaseConflictMutex.Lock()
+	defer fake.resolveCaseConflictMutex.Unlock()
+	fake.ResolveCaseConflictStub = stub
+}
+
+func (fake *Model) ResolveCaseConflictArgsForCall(i int) (string, string, string) {
+	fake.resolveCaseConflictMutex.RLock()
+	defer fake.resolveCaseConflictMutex.RUnlock()
+	argsForCall := fake.resolveCaseConflictArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) ResolveCaseConflictReturns(result1 error) {
+	fake.resolveCaseConflictMutex.Lock()
+	defer fake.resolveCaseConflictMutex.Unlock()
+	fake.ResolveCaseConflictStub = nil
+	fake.resolveCaseConflictReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ResolveCaseConflictReturnsOnCall(i int, result1 error) {
+	fake.resolveCaseConflictMutex.Lock()
+	defer fake.resolveCaseConflictMutex.Unlock()
+	fake.ResolveCaseConflictStub = nil
+	if fake.resolveCaseConflictReturnsOnCall == nil {
+		fake.resolveCaseConflictReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.resolveCaseConflictReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) RestoreFolderSnapshot(arg1 string, arg2 string, arg3 time.Time, arg4 string) (map[string]error, error) {
+	fake.restoreFolderSnapshotMutex.Lock()
+	ret, specificReturn := fake.restoreFolderSnapshotReturnsOnCall[len(fake.restoreFolderSnapshotArgsForCall)]
+	fake.restoreFolderSnapshotArgsForCall = append(fake.restoreFolderSnapshotArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 time.Time
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RestoreFolderSnapshotStub
+	fakeReturns := fake.restoreFolderSnapshotReturns
+	fake.recordInvocation("RestoreFolderSnapshot", []interface{}{arg1, arg2, arg3, arg4})
+	fake.restoreFolderSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) RestoreFolderSnapshotCallCount() int {
+	fake.restoreFolderSnapshotMutex.RLock()
+	defer fake.restoreFolderSnapshotMutex.RUnlock()
+	return len(fake.restoreFolderSnapshotArgsForCall)
+}
+
+func (fake *Model) RestoreFolderSnapshotCalls(stub func(string, string, time.Time, string) (map[string]error, error)) {
+	fake.restoreFolderSnapshotMutex.Lock()
+	defer fake.restoreFolderSnapshotMutex.Unlock()
+	fake.RestoreFolderSnapshotStub = stub
+}
+
+func (fake *Model) RestoreFolderSnapshotArgsForCall(i int) (string, string, time.Time, string) {
+	fake.restoreFolderSnapshotMutex.RLock()
+	defer fake.restoreFolderSnapshotMutex.RUnlock()
+	argsForCall := fake.restoreFolderSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Model) RestoreFolderSnapshotReturns(result1 map[string]error, result2 error) {
+	fake.restoreFolderSnapshotMutex.Lock()
+	defer fake.restoreFolderSnapshotMutex.Unlock()
+	fake.RestoreFolderSnapshotStub = nil
+	fake.restoreFolderSnapshotReturns = struct {
+		result1 map[string]error
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) RestoreFolderSnapshotReturnsOnCall(i int, result1 map[string]error, result2 error) {
+	fake.restoreFolderSnapshotMutex.Lock()
+	defer fake.restoreFolderSnapshotMutex.Unlock()
+	fake.RestoreFolderSnapshotStub = nil
+	if fake.restoreFolderSnapshotReturnsOnCall == nil {
+		fake.restoreFolderSnapshotReturnsOnCall = make(map[int]struct {
+			result1 map[string]error
+			result2 error
+		})
+	}
+	fake.restoreFolderSnapshotReturnsOnCall[i] = struct {
+		result1 map[string]error
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) RestoreFolderVersions(arg1 string, arg2 map[string]time.Time) (map[string]error, error) {
 	fake.restoreFolderVersionsMutex.Lock()
 	ret, specificReturn := fake.restoreFolderVersionsReturnsOnCall[len(fake.restoreFolderVersionsArgsForCall)]
@@ -3266,6 +5062,59 @@ func (fake *Model) RestoreFolderVersionsReturnsOnCall(i int, result1 map[string]
 	}{result1, result2}
 }
 
+func (fake *Model) ResumeAll() error {
+	fake.resumeAllMutex.Lock()
+	ret, specificReturn := fake.resumeAllReturnsOnCall[len(fake.resumeAllArgsForCall)]
+	fake.resumeAllArgsForCall = append(fake.resumeAllArgsForCall, struct {
+	}{})
+	stub := fake.ResumeAllStub
+	fakeReturns := fake.resumeAllReturns
+	fake.recordInvocation("ResumeAll", []interface{}{})
+	fake.resumeAllMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) ResumeAllCallCount() int {
+	fake.resumeAllMutex.RLock()
+	defer fake.resumeAllMutex.RUnlock()
+	return len(fake.resumeAllArgsForCall)
+}
+
+func (fake *Model) ResumeAllCalls(stub func() error) {
+	fake.resumeAllMutex.Lock()
+	defer fake.resumeAllMutex.Unlock()
+	fake.ResumeAllStub = stub
+}
+
+func (fake *Model) ResumeAllReturns(result1 error) {
+	fake.resumeAllMutex.Lock()
+	defer fake.resumeAllMutex.Unlock()
+	fake.ResumeAllStub = nil
+	fake.resumeAllReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ResumeAllReturnsOnCall(i int, result1 error) {
+	fake.resumeAllMutex.Lock()
+	defer fake.resumeAllMutex.Unlock()
+	fake.ResumeAllStub = nil
+	if fake.resumeAllReturnsOnCall == nil {
+		fake.resumeAllReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.resumeAllReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Model) Revert(arg1 string) {
 	fake.revertMutex.Lock()
 	fake.revertArgsForCall = append(fake.revertArgsForCall, struct {
@@ -3479,6 +5328,123 @@ func (fake *Model) ScanFoldersReturnsOnCall(i int, result1 map[string]error) {
 	}{result1}
 }
 
+func (fake *Model) ScanSchedule() []model.ScheduledScan {
+	fake.scanScheduleMutex.Lock()
+	ret, specificReturn := fake.scanScheduleReturnsOnCall[len(fake.scanScheduleArgsForCall)]
+	fake.scanScheduleArgsForCall = append(fake.scanScheduleArgsForCall, struct {
+	}{})
+	stub := fake.ScanScheduleStub
+	fakeReturns := fake.scanScheduleReturns
+	fake.recordInvocation("ScanSchedule", []interface{}{})
+	fake.scanScheduleMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) ScanScheduleCallCount() int {
+	fake.scanScheduleMutex.RLock()
+	defer fake.scanScheduleMutex.RUnlock()
+	return len(fake.scanScheduleArgsForCall)
+}
+
+func (fake *Model) ScanScheduleCalls(stub func() []model.ScheduledScan) {
+	fake.scanScheduleMutex.Lock()
+	defer fake.scanScheduleMutex.Unlock()
+	fake.ScanScheduleStub = stub
+}
+
+func (fake *Model) ScanScheduleReturns(result1 []model.ScheduledScan) {
+	fake.scanScheduleMutex.Lock()
+	defer fake.scanScheduleMutex.Unlock()
+	fake.ScanScheduleStub = nil
+	fake.scanScheduleReturns = struct {
+		result1 []model.ScheduledScan
+	}{result1}
+}
+
+func (fake *Model) ScanScheduleReturnsOnCall(i int, result1 []model.ScheduledScan) {
+	fake.scanScheduleMutex.Lock()
+	defer fake.scanScheduleMutex.Unlock()
+	fake.ScanScheduleStub = nil
+	if fake.scanScheduleReturnsOnCall == nil {
+		fake.scanScheduleReturnsOnCall = make(map[int]struct {
+			result1 []model.ScheduledScan
+		})
+	}
+	fake.scanScheduleReturnsOnCall[i] = struct {
+		result1 []model.ScheduledScan
+	}{result1}
+}
+
+func (fake *Model) ScanSkipped(arg1 string) (scanner.SkipCounts, error) {
+	fake.scanSkippedMutex.Lock()
+	ret, specificReturn := fake.scanSkippedReturnsOnCall[len(fake.scanSkippedArgsForCall)]
+	fake.scanSkippedArgsForCall = append(fake.scanSkippedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ScanSkippedStub
+	fakeReturns := fake.scanSkippedReturns
+	fake.recordInvocation("ScanSkipped", []interface{}{arg1})
+	fake.scanSkippedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) ScanSkippedCallCount() int {
+	fake.scanSkippedMutex.RLock()
+	defer fake.scanSkippedMutex.RUnlock()
+	return len(fake.scanSkippedArgsForCall)
+}
+
+func (fake *Model) ScanSkippedCalls(stub func(string) (scanner.SkipCounts, error)) {
+	fake.scanSkippedMutex.Lock()
+	defer fake.scanSkippedMutex.Unlock()
+	fake.ScanSkippedStub = stub
+}
+
+func (fake *Model) ScanSkippedArgsForCall(i int) string {
+	fake.scanSkippedMutex.RLock()
+	defer fake.scanSkippedMutex.RUnlock()
+	argsForCall := fake.scanSkippedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) ScanSkippedReturns(result1 scanner.SkipCounts, result2 error) {
+	fake.scanSkippedMutex.Lock()
+	defer fake.scanSkippedMutex.Unlock()
+	fake.ScanSkippedStub = nil
+	fake.scanSkippedReturns = struct {
+		result1 scanner.SkipCounts
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ScanSkippedReturnsOnCall(i int, result1 scanner.SkipCounts, result2 error) {
+	fake.scanSkippedMutex.Lock()
+	defer fake.scanSkippedMutex.Unlock()
+	fake.ScanSkippedStub = nil
+	if fake.scanSkippedReturnsOnCall == nil {
+		fake.scanSkippedReturnsOnCall = make(map[int]struct {
+			result1 scanner.SkipCounts
+			result2 error
+		})
+	}
+	fake.scanSkippedReturnsOnCall[i] = struct {
+		result1 scanner.SkipCounts
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) Sequence(arg1 string, arg2 protocol.DeviceID) (int64, error) {
 	fake.sequenceMutex.Lock()
 	ret, specificReturn := fake.sequenceReturnsOnCall[len(fake.sequenceArgsForCall)]
@@ -3605,6 +5571,73 @@ func (fake *Model) ServeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Model) SetFolderSchedule(arg1 string, arg2 []string) error {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.setFolderScheduleMutex.Lock()
+	ret, specificReturn := fake.setFolderScheduleReturnsOnCall[len(fake.setFolderScheduleArgsForCall)]
+	fake.setFolderScheduleArgsForCall = append(fake.setFolderScheduleArgsForCall, struct {
+		arg1 string
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.SetFolderScheduleStub
+	fakeReturns := fake.setFolderScheduleReturns
+	fake.recordInvocation("SetFolderSchedule", []interface{}{arg1, arg2Copy})
+	fake.setFolderScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) SetFolderScheduleCallCount() int {
+	fake.setFolderScheduleMutex.RLock()
+	defer fake.setFolderScheduleMutex.RUnlock()
+	return len(fake.setFolderScheduleArgsForCall)
+}
+
+func (fake *Model) SetFolderScheduleCalls(stub func(string, []string) error) {
+	fake.setFolderScheduleMutex.Lock()
+	defer fake.setFolderScheduleMutex.Unlock()
+	fake.SetFolderScheduleStub = stub
+}
+
+func (fake *Model) SetFolderScheduleArgsForCall(i int) (string, []string) {
+	fake.setFolderScheduleMutex.RLock()
+	defer fake.setFolderScheduleMutex.RUnlock()
+	argsForCall := fake.setFolderScheduleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) SetFolderScheduleReturns(result1 error) {
+	fake.setFolderScheduleMutex.Lock()
+	defer fake.setFolderScheduleMutex.Unlock()
+	fake.SetFolderScheduleStub = nil
+	fake.setFolderScheduleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) SetFolderScheduleReturnsOnCall(i int, result1 error) {
+	fake.setFolderScheduleMutex.Lock()
+	defer fake.setFolderScheduleMutex.Unlock()
+	fake.SetFolderScheduleStub = nil
+	if fake.setFolderScheduleReturnsOnCall == nil {
+		fake.setFolderScheduleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setFolderScheduleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Model) SetIgnores(arg1 string, arg2 []string) error {
 	var arg2Copy []string
 	if arg2 != nil {
@@ -3672,6 +5705,101 @@ func (fake *Model) SetIgnoresReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Model) SetPinned(arg1 string, arg2 string, arg3 bool) error {
+	fake.setPinnedMutex.Lock()
+	ret, specificReturn := fake.setPinnedReturnsOnCall[len(fake.setPinnedArgsForCall)]
+	fake.setPinnedArgsForCall = append(fake.setPinnedArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 bool
+	}{arg1, arg2, arg3})
+	stub := fake.SetPinnedStub
+	fakeReturns := fake.setPinnedReturns
+	fake.recordInvocation("SetPinned", []interface{}{arg1, arg2, arg3})
+	fake.setPinnedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) SetPinnedCallCount() int {
+	fake.setPinnedMutex.RLock()
+	defer fake.setPinnedMutex.RUnlock()
+	return len(fake.setPinnedArgsForCall)
+}
+
+func (fake *Model) SetPinnedCalls(stub func(string, string, bool) error) {
+	fake.setPinnedMutex.Lock()
+	defer fake.setPinnedMutex.Unlock()
+	fake.SetPinnedStub = stub
+}
+
+func (fake *Model) SetPinnedArgsForCall(i int) (string, string, bool) {
+	fake.setPinnedMutex.RLock()
+	defer fake.setPinnedMutex.RUnlock()
+	argsForCall := fake.setPinnedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) SetPinnedReturns(result1 error) {
+	fake.setPinnedMutex.Lock()
+	defer fake.setPinnedMutex.Unlock()
+	fake.SetPinnedStub = nil
+	fake.setPinnedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) SetPinnedReturnsOnCall(i int, result1 error) {
+	fake.setPinnedMutex.Lock()
+	defer fake.setPinnedMutex.Unlock()
+	fake.SetPinnedStub = nil
+	if fake.setPinnedReturnsOnCall == nil {
+		fake.setPinnedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setPinnedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) SetScansSuspended(arg1 bool) {
+	fake.setScansSuspendedMutex.Lock()
+	fake.setScansSuspendedArgsForCall = append(fake.setScansSuspendedArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	stub := fake.SetScansSuspendedStub
+	fake.recordInvocation("SetScansSuspended", []interface{}{arg1})
+	fake.setScansSuspendedMutex.Unlock()
+	if stub != nil {
+		fake.SetScansSuspendedStub(arg1)
+	}
+}
+
+func (fake *Model) SetScansSuspendedCallCount() int {
+	fake.setScansSuspendedMutex.RLock()
+	defer fake.setScansSuspendedMutex.RUnlock()
+	return len(fake.setScansSuspendedArgsForCall)
+}
+
+func (fake *Model) SetScansSuspendedCalls(stub func(bool)) {
+	fake.setScansSuspendedMutex.Lock()
+	defer fake.setScansSuspendedMutex.Unlock()
+	fake.SetScansSuspendedStub = stub
+}
+
+func (fake *Model) SetScansSuspendedArgsForCall(i int) bool {
+	fake.setScansSuspendedMutex.RLock()
+	defer fake.setScansSuspendedMutex.RUnlock()
+	argsForCall := fake.setScansSuspendedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *Model) State(arg1 string) (string, time.Time, error) {
 	fake.stateMutex.Lock()
 	ret, specificReturn := fake.stateReturnsOnCall[len(fake.stateArgsForCall)]
@@ -3739,6 +5867,130 @@ func (fake *Model) StateReturnsOnCall(i int, result1 string, result2 time.Time,
 	}{result1, result2, result3}
 }
 
+func (fake *Model) TransferHistory(arg1 time.Time) model.TransferHistory {
+	fake.transferHistoryMutex.Lock()
+	ret, specificReturn := fake.transferHistoryReturnsOnCall[len(fake.transferHistoryArgsForCall)]
+	fake.transferHistoryArgsForCall = append(fake.transferHistoryArgsForCall, struct {
+		arg1 time.Time
+	}{arg1})
+	stub := fake.TransferHistoryStub
+	fakeReturns := fake.transferHistoryReturns
+	fake.recordInvocation("TransferHistory", []interface{}{arg1})
+	fake.transferHistoryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) TransferHistoryCallCount() int {
+	fake.transferHistoryMutex.RLock()
+	defer fake.transferHistoryMutex.RUnlock()
+	return len(fake.transferHistoryArgsForCall)
+}
+
+func (fake *Model) TransferHistoryCalls(stub func(time.Time) model.TransferHistory) {
+	fake.transferHistoryMutex.Lock()
+	defer fake.transferHistoryMutex.Unlock()
+	fake.TransferHistoryStub = stub
+}
+
+func (fake *Model) TransferHistoryArgsForCall(i int) time.Time {
+	fake.transferHistoryMutex.RLock()
+	defer fake.transferHistoryMutex.RUnlock()
+	argsForCall := fake.transferHistoryArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) TransferHistoryReturns(result1 model.TransferHistory) {
+	fake.transferHistoryMutex.Lock()
+	defer fake.transferHistoryMutex.Unlock()
+	fake.TransferHistoryStub = nil
+	fake.transferHistoryReturns = struct {
+		result1 model.TransferHistory
+	}{result1}
+}
+
+func (fake *Model) TransferHistoryReturnsOnCall(i int, result1 model.TransferHistory) {
+	fake.transferHistoryMutex.Lock()
+	defer fake.transferHistoryMutex.Unlock()
+	fake.TransferHistoryStub = nil
+	if fake.transferHistoryReturnsOnCall == nil {
+		fake.transferHistoryReturnsOnCall = make(map[int]struct {
+			result1 model.TransferHistory
+		})
+	}
+	fake.transferHistoryReturnsOnCall[i] = struct {
+		result1 model.TransferHistory
+	}{result1}
+}
+
+func (fake *Model) Undelete(arg1 context.Context, arg2 string, arg3 string) error {
+	fake.undeleteMutex.Lock()
+	ret, specificReturn := fake.undeleteReturnsOnCall[len(fake.undeleteArgsForCall)]
+	fake.undeleteArgsForCall = append(fake.undeleteArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.UndeleteStub
+	fakeReturns := fake.undeleteReturns
+	fake.recordInvocation("Undelete", []interface{}{arg1, arg2, arg3})
+	fake.undeleteMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) UndeleteCallCount() int {
+	fake.undeleteMutex.RLock()
+	defer fake.undeleteMutex.RUnlock()
+	return len(fake.undeleteArgsForCall)
+}
+
+func (fake *Model) UndeleteCalls(stub func(context.Context, string, string) error) {
+	fake.undeleteMutex.Lock()
+	defer fake.undeleteMutex.Unlock()
+	fake.UndeleteStub = stub
+}
+
+func (fake *Model) UndeleteArgsForCall(i int) (context.Context, string, string) {
+	fake.undeleteMutex.RLock()
+	defer fake.undeleteMutex.RUnlock()
+	argsForCall := fake.undeleteArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) UndeleteReturns(result1 error) {
+	fake.undeleteMutex.Lock()
+	defer fake.undeleteMutex.Unlock()
+	fake.UndeleteStub = nil
+	fake.undeleteReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) UndeleteReturnsOnCall(i int, result1 error) {
+	fake.undeleteMutex.Lock()
+	defer fake.undeleteMutex.Unlock()
+	fake.UndeleteStub = nil
+	if fake.undeleteReturnsOnCall == nil {
+		fake.undeleteReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.undeleteReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Model) UsageReportingStats(arg1 *contract.Report, arg2 int, arg3 bool) {
 	fake.usageReportingStatsMutex.Lock()
 	fake.usageReportingStatsArgsForCall = append(fake.usageReportingStatsArgsForCall, struct {
@@ -3834,6 +6086,59 @@ func (fake *Model) WatchErrorReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Model) WatchReport() model.WatchReport {
+	fake.watchReportMutex.Lock()
+	ret, specificReturn := fake.watchReportReturnsOnCall[len(fake.watchReportArgsForCall)]
+	fake.watchReportArgsForCall = append(fake.watchReportArgsForCall, struct {
+	}{})
+	stub := fake.WatchReportStub
+	fakeReturns := fake.watchReportReturns
+	fake.recordInvocation("WatchReport", []interface{}{})
+	fake.watchReportMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) WatchReportCallCount() int {
+	fake.watchReportMutex.RLock()
+	defer fake.watchReportMutex.RUnlock()
+	return len(fake.watchReportArgsForCall)
+}
+
+func (fake *Model) WatchReportCalls(stub func() model.WatchReport) {
+	fake.watchReportMutex.Lock()
+	defer fake.watchReportMutex.Unlock()
+	fake.WatchReportStub = stub
+}
+
+func (fake *Model) WatchReportReturns(result1 model.WatchReport) {
+	fake.watchReportMutex.Lock()
+	defer fake.watchReportMutex.Unlock()
+	fake.WatchReportStub = nil
+	fake.watchReportReturns = struct {
+		result1 model.WatchReport
+	}{result1}
+}
+
+func (fake *Model) WatchReportReturnsOnCall(i int, result1 model.WatchReport) {
+	fake.watchReportMutex.Lock()
+	defer fake.watchReportMutex.Unlock()
+	fake.WatchReportStub = nil
+	if fake.watchReportReturnsOnCall == nil {
+		fake.watchReportReturnsOnCall = make(map[int]struct {
+			result1 model.WatchReport
+		})
+	}
+	fake.watchReportReturnsOnCall[i] = struct {
+		result1 model.WatchReport
+	}{result1}
+}
+
 func (fake *Model) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -18,6 +18,7 @@ import (
 	"iter"
 	"log/slog"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -29,11 +30,12 @@ import (
 	"time"
 
 	"github.com/thejerf/suture/v4"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/internal/itererr"
 	"github.com/syncthing/syncthing/internal/slogutil"
-	"github.com/syncthing/syncthing/lib/build"
+	"github.com/syncthing/syncthing/internal/tracing"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/connections"
 	"github.com/syncthing/syncthing/lib/events"
@@ -60,17 +62,30 @@ type service interface {
 	SchedulePull()                                    // something relevant changed, we should try a pull
 	Jobs(page, perpage int) ([]string, []string, int) // In progress, Queued, skipped
 	Scan(subs []string) error
+	RescanFile(name string) (protocol.FileInfo, error)
+	Adopt() (int, error)
 	Errors() []FileError
 	WatchError() error
+	ScanSkipped() scanner.SkipCounts
 	ScheduleForceRescan(path string)
 	GetStatistics() (stats.FolderStatistics, error)
+	GetSizeHistory() (stats.SizeHistory, error)
+	RecordSizes(sample stats.SizeSample) error
 
 	getState() (folderState, time.Time, error)
+	scansResumed()
 }
 
+// The number of concurrent requests per device served as interactive.
+// Browsing or streaming a file needs no more than a few at a time.
+const maxInteractiveRequestsPerDevice = 4
+
 type Availability struct {
 	ID            protocol.DeviceID `json:"id"`
 	FromTemporary bool              `json:"fromTemporary"`
+	// Forwarding is set for devices that don't have the file but forward
+	// requests for it to one that does.
+	Forwarding bool `json:"forwarding"`
 }
 
 type Model interface {
@@ -83,18 +98,41 @@ type Model interface {
 	ScanFolder(folder string) error
 	ScanFolders() map[string]error
 	ScanFolderSubdirs(folder string, subs []string) error
+	RescanFile(folder, name string) (protocol.FileInfo, error)
 	State(folder string) (string, time.Time, error)
 	FolderErrors(folder string) ([]FileError, error)
 	WatchError(folder string) error
+	ScanSkipped(folder string) (scanner.SkipCounts, error)
 	Override(folder string)
 	Revert(folder string)
 	BringToFront(folder, file string)
 	LoadIgnores(folder string) ([]string, []string, error)
 	CurrentIgnores(folder string) ([]string, []string, error)
 	SetIgnores(folder string, content []string) error
+	PreviewIgnores(folder string, content []string) ([]NewlyIgnoredFile, error)
+	PreviewPull(folder string) (PullPreview, error)
+	Adopt(folder string) (int, error)
+	Pinned(folder string) ([]string, error)
+	PendingRemoteChanges(folder string) (PendingApproval, bool, error)
+	ApproveRemoteChanges(folder string) error
+	ClusterState() []ClusterFolderState
+	FolderPathWarnings() []FolderPathWarning
+	WatchReport() WatchReport
+	DeletedFiles(folder, prefix string) ([]DeletedFile, error)
+	Undelete(ctx context.Context, folder, name string) error
+	CaseConflicts(folder string) ([]CaseConflict, error)
+	ResolveCaseConflict(folder, name, newName string) error
+	SetPinned(folder, path string, pinned bool) error
+	SetFolderSchedule(folder string, activeTimes []string) error
+	PauseAll(reason, originator string, duration time.Duration) error
+	ResumeAll() error
+	ScanSchedule() []ScheduledScan
 
 	GetFolderVersions(folder string) (map[string][]versioner.FileVersion, error)
+	GetFolderVersionsBetween(folder string, from, to time.Time) (map[string][]versioner.FileVersion, error)
+	OpenFolderVersion(folder, file string, versionTime time.Time) (fs.File, error)
 	RestoreFolderVersions(folder string, versions map[string]time.Time) (map[string]error, error)
+	RestoreFolderSnapshot(folder, dir string, asOf time.Time, dest string) (map[string]error, error)
 
 	LocalFiles(folder string, device protocol.DeviceID) (iter.Seq[protocol.FileInfo], func() error)
 	LocalFilesSequenced(folder string, device protocol.DeviceID, startSet int64) (iter.Seq[protocol.FileInfo], func() error)
@@ -107,6 +145,7 @@ type Model interface {
 	RemoteSequences(folder string) (map[protocol.DeviceID]int64, error)
 
 	NeedFolderFiles(folder string, page, perpage int) ([]protocol.FileInfo, []protocol.FileInfo, []protocol.FileInfo, error)
+	NeedFolderFilesIter(folder string) (iter.Seq[protocol.FileInfo], func() error)
 	RemoteNeedFolderFiles(folder string, device protocol.DeviceID, page, perpage int) ([]protocol.FileInfo, error)
 	LocalChangedFolderFiles(folder string, page, perpage int) ([]protocol.FileInfo, error)
 	FolderProgressBytesCompleted(folder string) int64
@@ -116,9 +155,13 @@ type Model interface {
 	Availability(folder string, file protocol.FileInfo, block protocol.BlockInfo) ([]Availability, error)
 
 	Completion(device protocol.DeviceID, folder string) (FolderCompletion, error)
+	EncryptionRotation(folder string, device protocol.DeviceID) (EncryptionRotation, error)
 	ConnectionStats() map[string]interface{}
+	TransferHistory(since time.Time) TransferHistory
 	DeviceStatistics() (map[protocol.DeviceID]stats.DeviceStatistics, error)
+	FileActivity() (map[string][]stats.DailyActivity, error)
 	FolderStatistics() (map[string]stats.FolderStatistics, error)
+	FolderSizeHistory(folder string) (stats.SizeHistory, error)
 	UsageReportingStats(report *contract.Report, version int, preview bool)
 	ConnectedTo(remoteID protocol.DeviceID) bool
 
@@ -129,7 +172,9 @@ type Model interface {
 
 	GlobalDirectoryTree(folder, prefix string, levels int, dirsOnly bool) ([]*TreeEntry, error)
 
-	RequestGlobal(ctx context.Context, deviceID protocol.DeviceID, folder, name string, blockNo int, offset int64, size int, hash []byte, fromTemporary bool) ([]byte, error)
+	RequestGlobal(ctx context.Context, deviceID protocol.DeviceID, folder, name string, blockNo int, offset int64, size int, hash []byte, fromTemporary, interactive bool) ([]byte, error)
+	ManageDevice(ctx context.Context, deviceID protocol.DeviceID, op protocol.ManagementOperation, body []byte) ([]byte, error)
+	SetScansSuspended(suspended bool)
 }
 
 type model struct {
@@ -148,14 +193,41 @@ type model struct {
 	// globalRequestLimiter limits the amount of data in concurrent incoming
 	// requests
 	globalRequestLimiter *semaphore.Semaphore
-	// folderIOLimiter limits the number of concurrent I/O heavy operations,
-	// such as scans and pulls.
-	folderIOLimiter *semaphore.Semaphore
-	fatalChan       chan error
-	started         chan struct{}
-	keyGen          *protocol.KeyGenerator
-	promotionTimer  *time.Timer
-	observed        *db.ObservedDB
+	// uploadSlots limits the number of concurrent incoming requests,
+	// overall and per device, serving waiting devices fairly.
+	uploadSlots *semaphore.FairSemaphore[protocol.DeviceID]
+	// interactiveRequestLimiter limits the amount of data in concurrent
+	// incoming interactive requests, which don't wait behind the others
+	interactiveRequestLimiter *semaphore.Semaphore
+	// interactiveSlots limits the number of concurrent incoming requests
+	// per device served as interactive; beyond that they're regular.
+	interactiveSlots *semaphore.FairSemaphore[protocol.DeviceID]
+	// scheduler limits the number of concurrent I/O heavy operations, such
+	// as scans and pulls, and of files being hashed, across all folders.
+	scheduler *scheduler
+	// memoryLimiter limits the memory used by all folders together for
+	// pulling, hashing and index batches.
+	memoryLimiter  *semaphore.Semaphore
+	fatalChan      chan error
+	started        chan struct{}
+	keyGen         *protocol.KeyGenerator
+	promotionTimer *time.Timer
+	// shareExpiryTimer fires when the next folder share expires.
+	shareExpiryTimer *time.Timer
+	// globalResumeTimer fires when a global pause is due to end.
+	globalResumeTimer *time.Timer
+	transferHistory   *transferHistory
+	folderAlarms      *folderAlarms
+	folderSchedules   *folderSchedules
+	scanSchedule      *scanSchedule
+	remoteChanges     *remoteChanges
+	watchUsages       *watchUsages
+	shareFilters      *shareFilters
+	observed          *db.ObservedDB
+	// readOnlyAnnouncements caches the devices peers announce as
+	// read-only, per folder.
+	readOnlyAnnouncements *readOnlyAnnouncements
+	fileActivity          *fileActivity
 
 	// fields protected by mut
 	mut                            sync.RWMutex
@@ -166,7 +238,9 @@ type model struct {
 	folderRestartMuts              syncMutexMap                                           // folder -> restart mutex
 	folderVersioners               map[string]versioner.Versioner                         // folder -> versioner (may be nil)
 	folderEncryptionPasswordTokens map[string][]byte                                      // folder -> encryption token (may be missing, and only for encryption type folders)
+	folderPreviousPasswordTokens   map[string][]byte                                      // folder -> token of the password being rotated away from (only while rotating)
 	folderEncryptionFailures       map[string]map[protocol.DeviceID]error                 // folder -> device -> error regarding encryption consistency (may be missing)
+	folderBlockCaches              map[string]*blockCache                                 // folder -> recently requested blocks, if enabled
 	connections                    map[string]protocol.Connection                         // connection ID -> connection
 	deviceConnIDs                  map[protocol.DeviceID][]string                         // device -> connection IDs (invariant: if the key exists, the value is len >= 1, with the primary connection at the start of the slice)
 	promotedConnID                 map[protocol.DeviceID]string                           // device -> latest promoted connection ID
@@ -175,15 +249,21 @@ type model struct {
 	helloMessages                  map[protocol.DeviceID]protocol.Hello
 	deviceDownloads                map[protocol.DeviceID]*deviceDownloadState
 	remoteFolderStates             map[protocol.DeviceID]map[string]remoteFolderState // deviceID -> folders
+	clusterIndexInfo               *clusterIndexInfo
+	space                          *spaceTracker
+	remoteFolderInfos              *remoteFolderInfos
 	indexHandlers                  *serviceMap[protocol.DeviceID, *indexHandlerRegistry]
 
+	scansSuspended atomic.Bool
+	globalPaused   atomic.Bool
+
 	// for testing only
 	foldersRunning atomic.Int32
 }
 
 var _ config.Verifier = &model{}
 
-type folderFactory func(*model, *ignore.Matcher, config.FolderConfiguration, versioner.Versioner, events.Logger, *semaphore.Semaphore) service
+type folderFactory func(*model, *ignore.Matcher, config.FolderConfiguration, versioner.Versioner, events.Logger, *scheduler) service
 
 var folderFactories = make(map[config.FolderType]folderFactory)
 
@@ -225,15 +305,30 @@ func NewModel(cfg config.Wrapper, id protocol.DeviceID, sdb db.DB, protectedFile
 		evLogger:       evLogger,
 
 		// constant or concurrency safe fields
-		progressEmitter:      NewProgressEmitter(cfg, evLogger),
-		shortID:              id.Short(),
-		globalRequestLimiter: semaphore.New(1024 * cfg.Options().MaxConcurrentIncomingRequestKiB()),
-		folderIOLimiter:      semaphore.New(cfg.Options().MaxFolderConcurrency()),
-		fatalChan:            make(chan error),
-		started:              make(chan struct{}),
-		keyGen:               keyGen,
-		promotionTimer:       time.NewTimer(0),
-		observed:             db.NewObservedDB(sdb),
+		progressEmitter:           NewProgressEmitter(cfg, evLogger),
+		shortID:                   id.Short(),
+		globalRequestLimiter:      semaphore.New(1024 * cfg.Options().MaxConcurrentIncomingRequestKiB()),
+		uploadSlots:               semaphore.NewFair[protocol.DeviceID](cfg.Options().MaxUploadSlots, cfg.Options().MaxUploadSlotsPerDevice),
+		interactiveRequestLimiter: semaphore.New(1024 * defaultPullerPendingKiB),
+		interactiveSlots:          semaphore.NewFair[protocol.DeviceID](0, maxInteractiveRequestsPerDevice),
+		scheduler:                 newScheduler(cfg.Options()),
+		memoryLimiter:             semaphore.New(cfg.Options().MaxMemoryBudgetMiB << 20),
+		fatalChan:                 make(chan error),
+		started:                   make(chan struct{}),
+		keyGen:                    keyGen,
+		promotionTimer:            time.NewTimer(0),
+		shareExpiryTimer:          time.NewTimer(0),
+		globalResumeTimer:         time.NewTimer(0),
+		transferHistory:           newTransferHistory(),
+		folderAlarms:              newFolderAlarms(),
+		folderSchedules:           newFolderSchedules(),
+		scanSchedule:              newScanSchedule(),
+		remoteChanges:             newRemoteChanges(),
+		watchUsages:               newWatchUsages(),
+		shareFilters:              newShareFilters(),
+		observed:                  db.NewObservedDB(sdb),
+		readOnlyAnnouncements:     newReadOnlyAnnouncements(),
+		fileActivity:              newFileActivity(sdb),
 
 		// fields protected by mut
 		folderCfgs:                     make(map[string]config.FolderConfiguration),
@@ -242,7 +337,9 @@ func NewModel(cfg config.Wrapper, id protocol.DeviceID, sdb db.DB, protectedFile
 		folderRunners:                  newServiceMap[string, service](evLogger),
 		folderVersioners:               make(map[string]versioner.Versioner),
 		folderEncryptionPasswordTokens: make(map[string][]byte),
+		folderPreviousPasswordTokens:   make(map[string][]byte),
 		folderEncryptionFailures:       make(map[string]map[protocol.DeviceID]error),
+		folderBlockCaches:              make(map[string]*blockCache),
 		connections:                    make(map[string]protocol.Connection),
 		deviceConnIDs:                  make(map[protocol.DeviceID][]string),
 		promotedConnID:                 make(map[protocol.DeviceID]string),
@@ -251,6 +348,9 @@ func NewModel(cfg config.Wrapper, id protocol.DeviceID, sdb db.DB, protectedFile
 		helloMessages:                  make(map[protocol.DeviceID]protocol.Hello),
 		deviceDownloads:                make(map[protocol.DeviceID]*deviceDownloadState),
 		remoteFolderStates:             make(map[protocol.DeviceID]map[string]remoteFolderState),
+		clusterIndexInfo:               newClusterIndexInfo(),
+		space:                          newSpaceTracker(),
+		remoteFolderInfos:              newRemoteFolderInfos(),
 		indexHandlers:                  newServiceMap[protocol.DeviceID, *indexHandlerRegistry](evLogger),
 	}
 	for devID, cfg := range cfg.Devices() {
@@ -278,6 +378,17 @@ func (m *model) serve(ctx context.Context) error {
 
 	close(m.started)
 
+	m.checkSpace()
+	spaceTicker := time.NewTicker(spaceCheckInterval)
+	defer spaceTicker.Stop()
+	sizeHistoryTicker := time.NewTicker(sizeHistoryInterval)
+	defer sizeHistoryTicker.Stop()
+	transferTicker := time.NewTicker(transferInterval)
+	defer transferTicker.Stop()
+	m.applyFolderSchedules(time.Now())
+	scheduleTicker := time.NewTicker(folderScheduleInterval)
+	defer scheduleTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -289,11 +400,25 @@ func (m *model) serve(ctx context.Context) error {
 		case <-m.promotionTimer.C:
 			slog.Debug("Promotion timer fired")
 			m.promoteConnections()
+		case <-m.shareExpiryTimer.C:
+			m.expireShares()
+		case <-m.globalResumeTimer.C:
+			m.endGlobalPause()
+		case <-sizeHistoryTicker.C:
+			m.recordSizeHistory()
+		case <-transferTicker.C:
+			m.sampleTransfers()
+		case <-spaceTicker.C:
+			m.checkSpace()
+		case now := <-scheduleTicker.C:
+			m.applyFolderSchedules(now)
 		}
 	}
 }
 
 func (m *model) initFolders(cfg config.Configuration) error {
+	m.updateGlobalPause(cfg.Options.GlobalPause)
+
 	clusterConfigDevices := make(deviceIDSet, len(cfg.Devices))
 	for _, folderCfg := range cfg.Folders {
 		if folderCfg.Paused {
@@ -350,6 +475,10 @@ func (m *model) addAndStartFolderLocked(cfg config.FolderConfiguration, cacheIgn
 func (m *model) addAndStartFolderLockedWithIgnores(cfg config.FolderConfiguration, ignores *ignore.Matcher) {
 	m.folderCfgs[cfg.ID] = cfg
 	m.folderIgnores[cfg.ID] = ignores
+	m.loadPinned(cfg, ignores)
+	if cfg.RequestCacheMiB > 0 {
+		m.folderBlockCaches[cfg.ID] = newBlockCache(cfg.RequestCacheMiB << 20)
+	}
 
 	_, ok := m.folderRunners.Get(cfg.ID)
 	if ok {
@@ -394,8 +523,11 @@ func (m *model) addAndStartFolderLockedWithIgnores(cfg config.FolderConfiguratio
 	}
 
 	if cfg.Type == config.FolderTypeReceiveEncrypted {
-		if encryptionToken, err := readEncryptionToken(cfg); err == nil {
-			m.folderEncryptionPasswordTokens[folder] = encryptionToken
+		if stored, err := readStoredEncryptionToken(cfg); err == nil {
+			m.folderEncryptionPasswordTokens[folder] = stored.Token
+			if len(stored.PreviousToken) > 0 {
+				m.folderPreviousPasswordTokens[folder] = stored.PreviousToken
+			}
 		} else if !fs.IsNotExist(err) {
 			slog.Error("Failed to read encryption token", cfg.LogAttr(), slogutil.Error(err))
 		}
@@ -410,21 +542,45 @@ func (m *model) addAndStartFolderLockedWithIgnores(cfg config.FolderConfiguratio
 	var ver versioner.Versioner
 	if cfg.Versioning.Type != "" {
 		var err error
-		ver, err = versioner.New(cfg)
+		ver, err = m.newVersioner(cfg)
 		if err != nil {
 			panic(fmt.Errorf("creating versioner: %w", err))
 		}
+		versioner.UseCatalog(ver, m.versionCatalog(folder))
 	}
 	m.folderVersioners[folder] = ver
 
 	m.warnAboutOverwritingProtectedFiles(cfg, ignores)
 
-	p := folderFactory(m, ignores, cfg, ver, m.evLogger, m.folderIOLimiter)
+	p := folderFactory(m, ignores, cfg, ver, m.evLogger, m.scheduler)
 	m.folderRunners.Add(folder, p)
 
 	slog.Info("Ready to synchronize", cfg.LogAttr())
 }
 
+// newVersioner creates the versioner for the folder. A folder keeping its
+// versions in another folder has that folder scanned as versions change.
+func (m *model) newVersioner(cfg config.FolderConfiguration) (versioner.Versioner, error) {
+	if cfg.Versioning.Type != versioner.RemoteType {
+		return versioner.New(cfg)
+	}
+	target, _ := m.cfg.Folder(cfg.Versioning.Params["folder"])
+	return versioner.NewRemote(cfg, target, func(paths []string) {
+		go func() { _ = m.ScanFolderSubdirs(target.ID, paths) }()
+	}), nil
+}
+
+// versionCatalog is where the index of the archived versions of the folder
+// is kept, for listing them without walking the versions directory. The
+// folder ID is escaped, so that no catalog's keys start with another's.
+func (m *model) versionCatalog(folder string) *db.Typed {
+	return db.NewTyped(m.sdb, "versions/"+url.PathEscape(folder)+"/")
+}
+
+func (m *model) forgetVersionCatalog(folder string) {
+	_ = m.versionCatalog(folder).ReplacePrefixBytes("", nil)
+}
+
 func (m *model) warnAboutOverwritingProtectedFiles(cfg config.FolderConfiguration, ignores *ignore.Matcher) {
 	if cfg.Type == config.FolderTypeSendOnly {
 		return
@@ -496,6 +652,9 @@ func (m *model) removeFolder(cfg config.FolderConfiguration) {
 
 	// Remove it from the database
 	_ = m.sdb.DropFolder(cfg.ID)
+	_ = m.pendingApprovals().Delete(cfg.ID)
+	m.forgetReadOnlyDevices(cfg.ID)
+	m.forgetVersionCatalog(cfg.ID)
 }
 
 // Need to hold lock on m.mut when calling this.
@@ -506,7 +665,10 @@ func (m *model) cleanupFolderLocked(cfg config.FolderConfiguration) {
 	delete(m.folderIgnores, cfg.ID)
 	delete(m.folderVersioners, cfg.ID)
 	delete(m.folderEncryptionPasswordTokens, cfg.ID)
+	delete(m.folderPreviousPasswordTokens, cfg.ID)
 	delete(m.folderEncryptionFailures, cfg.ID)
+	delete(m.folderBlockCaches, cfg.ID)
+	m.folderAlarms.forget(cfg.ID)
 }
 
 func (m *model) restartFolder(from, to config.FolderConfiguration, cacheIgnoredFiles bool) error {
@@ -1064,6 +1226,33 @@ func (m *model) NeedFolderFiles(folder string, page, perpage int) ([]protocol.Fi
 	return progress, queued, rest, nil
 }
 
+// NeedFolderFilesIter returns all the files currently needed in the folder,
+// in alphabetical order.
+func (m *model) NeedFolderFilesIter(folder string) (iter.Seq[protocol.FileInfo], func() error) {
+	m.mut.RLock()
+	cfg, ok := m.folderCfgs[folder]
+	m.mut.RUnlock()
+
+	if !ok {
+		return func(yield func(protocol.FileInfo) bool) {}, func() error { return ErrFolderMissing }
+	}
+
+	it, errFn := db.AllNeededGlobalFilesCursor(m.sdb, folder, protocol.LocalDeviceID)
+	if !cfg.IgnoreDelete {
+		return it, errFn
+	}
+	return func(yield func(protocol.FileInfo) bool) {
+		for f := range it {
+			if f.IsDeleted() {
+				continue
+			}
+			if !yield(f) {
+				return
+			}
+		}
+	}, errFn
+}
+
 // RemoteNeedFolderFiles returns paginated list of currently needed files for a
 // remote device to become synced with a folder.
 func (m *model) RemoteNeedFolderFiles(folder string, device protocol.DeviceID, page, perpage int) ([]protocol.FileInfo, error) {
@@ -1169,6 +1358,10 @@ func (m *model) handleIndex(conn protocol.Connection, folder string, fs []protoc
 	deviceID := conn.DeviceID()
 	l.Debugf("%v (in): %s / %q: %d files", op, deviceID, folder, len(fs))
 
+	_, span := tracing.Start(context.Background(), "receive index", tracing.Folder(folder), tracing.Device(deviceID),
+		attribute.Bool("syncthing.update", update), attribute.Int("syncthing.files", len(fs)))
+	defer span.End()
+
 	if cfg, ok := m.cfg.Folder(folder); !ok || !cfg.SharedWith(deviceID) {
 		slog.Warn(`Operation for unexpected folder ID; ensure that the folder exists and that this device is selected under "Share With" in the folder configuration.`, slog.String("operation", op), cfg.LogAttr(), deviceID.LogAttr())
 		return fmt.Errorf("%s: %w", folder, ErrFolderMissing)
@@ -1189,7 +1382,27 @@ func (m *model) handleIndex(conn protocol.Connection, folder string, fs []protoc
 		return fmt.Errorf("%s: %w", folder, ErrFolderNotRunning)
 	}
 
-	return indexHandler.ReceiveIndex(folder, fs, update, op, prevSequence, lastSequence)
+	m.refuseReadOnlyChanges(folder, deviceID, fs)
+	m.refuseUnsharedChanges(folder, deviceID, fs)
+	if update {
+		// Before receiving the update, as that schedules a pull.
+		m.checkRemoteApproval(folder, deviceID, fs)
+	}
+	if err := indexHandler.ReceiveIndex(folder, fs, update, op, prevSequence, lastSequence); err != nil {
+		return err
+	}
+	m.clusterIndexInfo.received(deviceID, folder, lastSequence, time.Now())
+
+	// A full index contains all the deleted files the device knows of,
+	// not only the recently deleted.
+	deletes := 0
+	if update {
+		deletes = countDeleted(fs)
+	}
+	m.checkFolderAlarms(folder, deletes)
+
+	m.checkEncryptionRotationDone(folder, deviceID)
+	return nil
 }
 
 type clusterConfigDeviceInfo struct {
@@ -1248,7 +1461,17 @@ func (m *model) ClusterConfig(conn protocol.Connection, cm *protocol.ClusterConf
 			slog.Warn("Device sent cluster-config without the device info for us locally", folder.LogAttr(), deviceID.LogAttr())
 			return errMissingLocalInClusterConfig
 		}
+		if m.takeFullIndexPending(folder.ID, deviceID) {
+			// Pretend they have nothing of ours.
+			info.local.MaxSequence = 0
+		}
 		ccDeviceInfos[folder.ID] = info
+		m.clusterIndexInfo.announced(deviceID, folder.ID, info.remote.MaxSequence)
+		m.space.announced(deviceID, folder.ID, folder.AvailableBytes, time.Now())
+		m.remoteFolderInfos.announced(deviceID, folder)
+		if fcfg, ok := m.cfg.Folder(folder.ID); ok {
+			m.checkRemoteSpace(deviceID, fcfg)
+		}
 	}
 
 	for _, info := range ccDeviceInfos {
@@ -1484,6 +1707,10 @@ func (m *model) ccHandleFolders(folders []protocol.Folder, deviceCfg config.Devi
 		}
 		m.mut.Unlock()
 
+		if !deviceCfg.Untrusted && folderDevice.EncryptionPassword == "" {
+			m.recordReadOnlyDevices(cfg.ID, deviceID, folder.Devices)
+		}
+
 		// Handle indexes
 
 		if folder.Type != protocol.FolderTypeReceiveEncrypted {
@@ -1570,6 +1797,16 @@ func (m *model) ccCheckEncryption(fcfg config.FolderConfiguration, folderDevice
 			// hasTokenRemote == true
 			match = bytes.Equal(passwordToken, ccDeviceInfos.remote.EncryptionPasswordToken)
 		}
+		if !match && folderDevice.RotatingEncryption() {
+			// The untrusted device may not have taken on the new password
+			// yet.
+			previousToken := protocol.PasswordToken(m.keyGen, fcfg.ID, folderDevice.PreviousEncryptionPassword)
+			if hasTokenLocal {
+				match = bytes.Equal(previousToken, ccDeviceInfos.local.EncryptionPasswordToken)
+			} else {
+				match = bytes.Equal(previousToken, ccDeviceInfos.remote.EncryptionPasswordToken)
+			}
+		}
 		if !match {
 			return errEncryptionPassword
 		}
@@ -1578,13 +1815,14 @@ func (m *model) ccCheckEncryption(fcfg config.FolderConfiguration, folderDevice
 
 	// isEncryptedLocal == true
 
-	var ccToken []byte
+	var ccDevice protocol.Device
 	if hasTokenLocal {
-		ccToken = ccDeviceInfos.local.EncryptionPasswordToken
+		ccDevice = ccDeviceInfos.local
 	} else {
 		// hasTokenRemote == true
-		ccToken = ccDeviceInfos.remote.EncryptionPasswordToken
+		ccDevice = ccDeviceInfos.remote
 	}
+	ccToken := ccDevice.EncryptionPasswordToken
 	m.mut.RLock()
 	token, ok := m.folderEncryptionPasswordTokens[fcfg.ID]
 	m.mut.RUnlock()
@@ -1625,7 +1863,11 @@ func (m *model) ccCheckEncryption(fcfg config.FolderConfiguration, folderDevice
 		}
 	}
 	if !bytes.Equal(token, ccToken) {
-		return errEncryptionPassword
+		return m.ccCheckEncryptionRotation(fcfg, folderDevice.DeviceID, token, ccDevice)
+	}
+	if len(ccDevice.PreviousEncryptionPasswordToken) == 0 {
+		// The remote is done rotating, if it ever was.
+		m.finishEncryptionRotation(fcfg)
 	}
 	return nil
 }
@@ -1855,6 +2097,7 @@ func (m *model) introduceDevice(device protocol.Device, introducerCfg config.Dev
 	newDeviceCfg.DeviceID = device.ID
 	newDeviceCfg.Name = device.Name
 	newDeviceCfg.Compression = introducerCfg.Compression
+	newDeviceCfg.CompressionAlgorithm = introducerCfg.CompressionAlgorithm
 	newDeviceCfg.Addresses = addresses
 	newDeviceCfg.CertName = device.CertName
 	newDeviceCfg.IntroducedBy = introducerCfg.DeviceID
@@ -1971,6 +2214,7 @@ func (m *model) Request(conn protocol.Connection, req *protocol.Request) (out pr
 	m.mut.RLock()
 	folderCfg, ok := m.folderCfgs[req.Folder]
 	folderIgnores := m.folderIgnores[req.Folder]
+	cache := m.folderBlockCaches[req.Folder]
 	m.mut.RUnlock()
 	if !ok {
 		// The folder might be already unpaused in the config, but not yet
@@ -1987,6 +2231,10 @@ func (m *model) Request(conn protocol.Connection, req *protocol.Request) (out pr
 		l.Debugf("Request from %s for file %s in paused folder %q", deviceID.Short(), req.Name, req.Folder)
 		return nil, protocol.ErrGeneric
 	}
+	if folderCfg.PauseSendingToFullDevices && m.space.full(deviceID, req.Folder) {
+		l.Debugf("Request from %s for file %s in folder %q it doesn't have space for", deviceID.Short(), req.Name, req.Folder)
+		return nil, protocol.ErrGeneric
+	}
 
 	// Make sure the path is valid and in canonical form
 	if name, err := fs.Canonicalize(req.Name); err != nil {
@@ -2010,6 +2258,11 @@ func (m *model) Request(conn protocol.Connection, req *protocol.Request) (out pr
 		return nil, protocol.ErrInvalid
 	}
 
+	if filter := m.shareFilters.get(folderCfg, deviceID); !filter.allows(req.Name) {
+		l.Debugf("%v REQ(in) for file not shared with device: %s: %q / %q o=%d s=%d", m, deviceID.Short(), req.Folder, req.Name, req.Offset, req.Size)
+		return nil, protocol.ErrNoSuchFile
+	}
+
 	// Restrict parallel requests by connection/device
 
 	m.mut.RLock()
@@ -2017,13 +2270,37 @@ func (m *model) Request(conn protocol.Connection, req *protocol.Request) (out pr
 	m.mut.RUnlock()
 
 	// The requestResponse releases the bytes to the buffer pool and the
-	// limiters when its Close method is called.
-	res := newLimitedRequestResponse(req.Size, limiter, m.globalRequestLimiter)
+	// limiters when its Close method is called. Requests first wait for an
+	// upload slot, so that the byte limits are shared fairly between
+	// devices. Interactive requests go ahead of the regular ones waiting,
+	// but as the flag is up to the peer only a few per device are served
+	// that way, and the rest are regular requests (also when forwarded).
+	interactive := req.Interactive && m.interactiveSlots.TryTake(deviceID)
+	req.Interactive = interactive
+	var res *requestResponse
+	if interactive {
+		m.uploadSlots.TakeAhead(deviceID)
+		res = newLimitedRequestResponse(req.Size, limiter, m.globalRequestLimiter, m.interactiveRequestLimiter)
+	} else {
+		m.uploadSlots.Take(deviceID)
+		res = newLimitedRequestResponse(req.Size, limiter, m.globalRequestLimiter)
+	}
+	go func() {
+		res.Wait()
+		m.uploadSlots.Give(deviceID)
+		if interactive {
+			m.interactiveSlots.Give(deviceID)
+		}
+	}()
 
 	defer func() {
 		// Close it ourselves if it isn't returned due to an error
 		if err != nil {
 			res.Close()
+			return
+		}
+		if deviceID != protocol.LocalDeviceID {
+			metricFolderDeviceBytesTotal.WithLabelValues(req.Folder, deviceID.String(), metricDirectionPushed).Add(float64(req.Size))
 		}
 	}()
 
@@ -2060,13 +2337,26 @@ func (m *model) Request(conn protocol.Connection, req *protocol.Request) (out pr
 		// Reject reads for anything that doesn't exist or is something
 		// other than a regular file.
 		l.Debugf("%v REQ(in) failed stating file (%v): %s: %q / %q o=%d s=%d", m, err, deviceID.Short(), req.Folder, req.Name, req.Offset, req.Size)
+		if m.forwardRequest(folderCfg, deviceID, req, res.data) {
+			return res, nil
+		}
 		return nil, protocol.ErrNoSuchFile
 	}
 
+	// Blocks requested by many devices can be served from memory. The
+	// cache is keyed by hash so the data is the same wherever it came
+	// from.
+	if cache != nil && len(req.Hash) > 0 && cache.get(req.Hash, res.data) {
+		return res, nil
+	}
+
 	n, err := readOffsetIntoBuf(folderFs, req.Name, req.Offset, res.data)
 	switch {
 	case fs.IsNotExist(err):
 		l.Debugf("%v REQ(in) file doesn't exist: %s: %q / %q o=%d s=%d", m, deviceID.Short(), req.Folder, req.Name, req.Offset, req.Size)
+		if m.forwardRequest(folderCfg, deviceID, req, res.data) {
+			return res, nil
+		}
 		return nil, protocol.ErrNoSuchFile
 	case errors.Is(err, io.EOF):
 		// Read beyond end of file. This might indicate a problem, or it
@@ -2082,9 +2372,16 @@ func (m *model) Request(conn protocol.Connection, req *protocol.Request) (out pr
 	if folderCfg.Type != config.FolderTypeReceiveEncrypted && len(req.Hash) > 0 && !scanner.Validate(res.data[:n], req.Hash) {
 		m.recheckFile(deviceID, req.Folder, req.Name, req.Offset, req.Hash)
 		l.Debugf("%v REQ(in) failed validating data: %s: %q / %q o=%d s=%d", m, deviceID.Short(), req.Folder, req.Name, req.Offset, req.Size)
+		if m.forwardRequest(folderCfg, deviceID, req, res.data) {
+			return res, nil
+		}
 		return nil, protocol.ErrNoSuchFile
 	}
 
+	if cache != nil && n == len(res.data) {
+		cache.put(req.Hash, res.data)
+	}
+
 	return res, nil
 }
 
@@ -2298,6 +2595,8 @@ func (m *model) AddConnection(conn protocol.Connection, hello protocol.Hello) {
 	connID := conn.ConnectionID()
 	closed := make(chan struct{})
 
+	conn.SetCompressionPolicy(deviceCfg.CompressionPolicy(m.cfg.FolderList()))
+
 	m.mut.Lock()
 
 	m.connections[connID] = conn
@@ -2345,9 +2644,34 @@ func (m *model) AddConnection(conn protocol.Connection, hello protocol.Hello) {
 	}
 
 	m.deviceWasSeen(deviceID)
+	if addr := dialAddress(conn); addr != "" {
+		m.mut.RLock()
+		sr, ok := m.deviceStatRefs[deviceID]
+		m.mut.RUnlock()
+		if ok {
+			_ = sr.LastAddress(addr)
+		}
+	}
 	m.scheduleConnectionPromotion()
 }
 
+// dialAddress returns the address to dial to get the same connection
+// again, for outgoing direct connections, or "" otherwise.
+func dialAddress(conn protocol.Connection) string {
+	addr := conn.RemoteAddr()
+	if addr == nil {
+		return ""
+	}
+	switch conn.Type() {
+	case "tcp-client":
+		return "tcp://" + addr.String()
+	case "quic-client":
+		return "quic://" + addr.String()
+	default:
+		return ""
+	}
+}
+
 func (m *model) scheduleConnectionPromotion() {
 	// Keeps deferring to prevent multiple executions in quick succession,
 	// e.g. if multiple connections to a single device are closed.
@@ -2451,14 +2775,16 @@ func (m *model) deviceDidCloseRLocked(deviceID protocol.DeviceID, duration time.
 	}
 }
 
-func (m *model) RequestGlobal(ctx context.Context, deviceID protocol.DeviceID, folder, name string, blockNo int, offset int64, size int, hash []byte, fromTemporary bool) ([]byte, error) {
+// RequestGlobal requests a block from the device. Interactive requests, made
+// on behalf of the user, are served ahead of regular syncing.
+func (m *model) RequestGlobal(ctx context.Context, deviceID protocol.DeviceID, folder, name string, blockNo int, offset int64, size int, hash []byte, fromTemporary, interactive bool) ([]byte, error) {
 	conn, connOK := m.requestConnectionForDevice(deviceID)
 	if !connOK {
 		return nil, fmt.Errorf("requestGlobal: no connection to device: %s", deviceID.Short())
 	}
 
-	l.Debugf("%v REQ(out): %s (%s): %q / %q b=%d o=%d s=%d h=%x ft=%t", m, deviceID.Short(), conn, folder, name, blockNo, offset, size, hash, fromTemporary)
-	return conn.Request(ctx, &protocol.Request{Folder: folder, Name: name, BlockNo: blockNo, Offset: offset, Size: size, Hash: hash, FromTemporary: fromTemporary})
+	l.Debugf("%v REQ(out): %s (%s): %q / %q b=%d o=%d s=%d h=%x ft=%t i=%t", m, deviceID.Short(), conn, folder, name, blockNo, offset, size, hash, fromTemporary, interactive)
+	return conn.Request(ctx, &protocol.Request{Folder: folder, Name: name, BlockNo: blockNo, Offset: offset, Size: size, Hash: hash, FromTemporary: fromTemporary, Interactive: interactive})
 }
 
 // requestConnectionForDevice returns a connection to the given device, to
@@ -2485,9 +2811,23 @@ func (m *model) requestConnectionForDevice(deviceID protocol.DeviceID) (protocol
 	}
 
 	conn, connOK := m.connections[connID]
+	if connOK && conn.Draining() {
+		return m.undrainedConnectionRLocked(deviceID)
+	}
 	return conn, connOK
 }
 
+// undrainedConnectionRLocked returns a connection to the device that takes
+// new requests, i.e. isn't about to be closed.
+func (m *model) undrainedConnectionRLocked(deviceID protocol.DeviceID) (protocol.Connection, bool) {
+	for _, connID := range m.deviceConnIDs[deviceID] {
+		if conn, ok := m.connections[connID]; ok && !conn.Draining() {
+			return conn, true
+		}
+	}
+	return nil, false
+}
+
 func (m *model) ScanFolders() map[string]error {
 	m.mut.RLock()
 	folders := make([]string, 0, len(m.folderCfgs))
@@ -2533,6 +2873,19 @@ func (m *model) ScanFolderSubdirs(folder string, subs []string) error {
 	return runner.Scan(subs)
 }
 
+func (m *model) RescanFile(folder, name string) (protocol.FileInfo, error) {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	runner, _ := m.folderRunners.Get(folder)
+	m.mut.RUnlock()
+
+	if err != nil {
+		return protocol.FileInfo{}, err
+	}
+
+	return runner.RescanFile(name)
+}
+
 func (m *model) DelayScan(folder string, next time.Duration) {
 	m.mut.RLock()
 	runner, ok := m.folderRunners.Get(folder)
@@ -2544,17 +2897,13 @@ func (m *model) DelayScan(folder string, next time.Duration) {
 }
 
 // numHashers returns the number of hasher routines to use for a given folder,
-// taking into account configuration and available CPU cores.
+// taking into account configuration and available CPU cores. The number of
+// files actually being hashed at once across all folders is limited by the
+// scheduler, so a folder may use the whole hashing capacity while the others
+// are idle.
 func (m *model) numHashers(folder string) int {
 	m.mut.RLock()
 	folderCfg := m.folderCfgs[folder]
-	numFolders := max(1, len(m.folderCfgs))
-	// MaxFolderConcurrency already limits the number of scanned folders, so
-	// prefer it over the overall number of folders to avoid limiting performance
-	// further for no reason.
-	if concurrency := m.cfg.Options().MaxFolderConcurrency(); concurrency > 0 {
-		numFolders = min(numFolders, concurrency)
-	}
 	m.mut.RUnlock()
 
 	if folderCfg.Hashers > 0 {
@@ -2562,35 +2911,26 @@ func (m *model) numHashers(folder string) int {
 		return folderCfg.Hashers
 	}
 
-	numCpus := runtime.GOMAXPROCS(-1)
-	if build.IsWindows || build.IsDarwin || build.IsIOS || build.IsAndroid {
-		// Interactive operating systems; don't load the system too heavily by
-		// default.
-		numCpus = max(1, numCpus/4)
+	if n := m.cfg.Options().MaxConcurrentHashers(); n > 0 {
+		return n
 	}
 
-	// For other operating systems and architectures, lets try to get some
-	// work done... Divide the available CPU cores among the configured
-	// folders.
-	if perFolder := numCpus / numFolders; perFolder > 0 {
-		return perFolder
-	}
-
-	return 1
+	// No global limit, use the number of CPU cores.
+	return max(1, runtime.GOMAXPROCS(-1))
 }
 
 // generateClusterConfig returns a ClusterConfigMessage that is correct and the
 // set of folder passwords for the given peer device
-func (m *model) generateClusterConfig(device protocol.DeviceID) (*protocol.ClusterConfig, map[string]string) {
+func (m *model) generateClusterConfig(device protocol.DeviceID) (*protocol.ClusterConfig, map[string]protocol.EncryptionPassword) {
 	m.mut.RLock()
 	defer m.mut.RUnlock()
 	return m.generateClusterConfigRLocked(device)
 }
 
-func (m *model) generateClusterConfigRLocked(device protocol.DeviceID) (*protocol.ClusterConfig, map[string]string) {
+func (m *model) generateClusterConfigRLocked(device protocol.DeviceID) (*protocol.ClusterConfig, map[string]protocol.EncryptionPassword) {
 	message := &protocol.ClusterConfig{}
 	folders := m.cfg.FolderList()
-	passwords := make(map[string]string, len(folders))
+	passwords := make(map[string]protocol.EncryptionPassword, len(folders))
 	for _, folderCfg := range folders {
 		if !folderCfg.SharedWith(device) {
 			continue
@@ -2604,8 +2944,14 @@ func (m *model) generateClusterConfigRLocked(device protocol.DeviceID) (*protoco
 		}
 
 		protocolFolder := protocol.Folder{
-			ID:    folderCfg.ID,
-			Label: folderCfg.Label,
+			ID:               folderCfg.ID,
+			Label:            folderCfg.Label,
+			AvailableBytes:   m.space.localAvailable(folderCfg.ID),
+			ForwardsRequests: forwardsRequestsFor(folderCfg, device),
+		}
+		if fd, _ := folderCfg.Device(device); folderCfg.AdvertisePath && fd.EncryptionPassword == "" {
+			protocolFolder.Path = folderCfg.Filesystem().URI()
+			protocolFolder.MarkerPresent = folderCfg.CheckPath() == nil
 		}
 
 		// Even if we aren't paused, if we haven't started the folder yet
@@ -2627,6 +2973,7 @@ func (m *model) generateClusterConfigRLocked(device protocol.DeviceID) (*protoco
 				Compression: deviceCfg.Compression.ToProtocol(),
 				CertName:    deviceCfg.CertName,
 				Introducer:  deviceCfg.Introducer,
+				ReadOnly:    folderDevice.ReadOnly,
 			}
 
 			if deviceCfg.DeviceID == m.id && hasEncryptionToken {
@@ -2640,14 +2987,26 @@ func (m *model) generateClusterConfigRLocked(device protocol.DeviceID) (*protoco
 				// for them.
 				if folderDevice.DeviceID == device {
 					protocolDevice.EncryptionPasswordToken = protocol.PasswordToken(m.keyGen, folderCfg.ID, folderDevice.EncryptionPassword)
-					passwords[folderCfg.ID] = folderDevice.EncryptionPassword
+					password := protocol.EncryptionPassword{
+						Password: folderDevice.EncryptionPassword,
+						Compress: folderDevice.CompressEncrypted,
+					}
+					if folderDevice.RotatingEncryption() {
+						protocolDevice.PreviousEncryptionPasswordToken = protocol.PasswordToken(m.keyGen, folderCfg.ID, folderDevice.PreviousEncryptionPassword)
+						password.Previous = folderDevice.PreviousEncryptionPassword
+					}
+					for _, st := range folderDevice.EncryptionSubtrees {
+						password.Subtrees = append(password.Subtrees, protocol.EncryptionSubtree{Path: st.Path, Password: st.EncryptionPassword})
+					}
+					passwords[folderCfg.ID] = password
 				} else {
 					continue nextDevice
 				}
 			}
 
 			if deviceCfg.DeviceID == m.id {
-				protocolDevice.IndexID, _ = m.sdb.GetIndexID(folderCfg.ID, protocol.LocalDeviceID)
+				indexID, _ := m.sdb.GetIndexID(folderCfg.ID, protocol.LocalDeviceID)
+				protocolDevice.IndexID = sharedIndexID(indexID, folderCfg, device)
 				protocolDevice.MaxSequence, _ = m.sdb.GetDeviceSequence(folderCfg.ID, protocol.LocalDeviceID)
 			} else {
 				protocolDevice.IndexID, _ = m.sdb.GetIndexID(folderCfg.ID, deviceCfg.DeviceID)
@@ -2699,6 +3058,17 @@ func (m *model) WatchError(folder string) error {
 	return runner.WatchError()
 }
 
+func (m *model) ScanSkipped(folder string) (scanner.SkipCounts, error) {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	runner, _ := m.folderRunners.Get(folder)
+	m.mut.RUnlock()
+	if err != nil {
+		return scanner.SkipCounts{}, err
+	}
+	return runner.ScanSkipped(), nil
+}
+
 func (m *model) Override(folder string) {
 	// Grab the runner and the file set.
 
@@ -2810,6 +3180,29 @@ func (m *model) GlobalDirectoryTree(folder, prefix string, levels int, dirsOnly
 }
 
 func (m *model) GetFolderVersions(folder string) (map[string][]versioner.FileVersion, error) {
+	return m.GetFolderVersionsBetween(folder, time.Time{}, time.Time{})
+}
+
+// GetFolderVersionsBetween returns the versions archived in the range
+// [from, to), where a zero time leaves that end open.
+func (m *model) GetFolderVersionsBetween(folder string, from, to time.Time) (map[string][]versioner.FileVersion, error) {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	ver := m.folderVersioners[folder]
+	m.mut.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if ver == nil {
+		return nil, errNoVersioner
+	}
+
+	return versioner.VersionsBetween(ver, from, to)
+}
+
+// OpenFolderVersion opens an archived version of a file for reading, such
+// as to preview it before restoring.
+func (m *model) OpenFolderVersion(folder, file string, versionTime time.Time) (fs.File, error) {
 	m.mut.RLock()
 	err := m.checkFolderRunningRLocked(folder)
 	ver := m.folderVersioners[folder]
@@ -2821,7 +3214,7 @@ func (m *model) GetFolderVersions(folder string) (map[string][]versioner.FileVer
 		return nil, errNoVersioner
 	}
 
-	return ver.GetVersions()
+	return ver.OpenVersion(file, versionTime)
 }
 
 func (m *model) RestoreFolderVersions(folder string, versions map[string]time.Time) (map[string]error, error) {
@@ -2853,6 +3246,62 @@ func (m *model) RestoreFolderVersions(folder string, versions map[string]time.Ti
 	return restoreErrors, nil
 }
 
+// RestoreFolderSnapshot restores the files in dir as they were at the
+// given time to dest, which is either an absolute path or a path in the
+// folder outside of dir.
+func (m *model) RestoreFolderSnapshot(folder, dir string, asOf time.Time, dest string) (map[string]error, error) {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	fcfg := m.folderCfgs[folder]
+	ver := m.folderVersioners[folder]
+	m.mut.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if ver == nil {
+		return nil, errNoVersioner
+	}
+
+	dir, err = fs.Canonicalize(dir)
+	if err != nil {
+		return nil, err
+	}
+	folderFs := fcfg.Filesystem()
+	var destFs fs.Filesystem
+	inFolder := !filepath.IsAbs(dest)
+	if inFolder {
+		dest, err = fs.Canonicalize(dest)
+		if err != nil {
+			return nil, err
+		}
+		if dest == "." || dest == dir || fs.IsParent(dest, dir) || dir == "." || fs.IsInternal(dest) {
+			return nil, errors.New("destination must be outside of the restored directory")
+		}
+		destFs = fs.NewFilesystem(folderFs.Type(), filepath.Join(folderFs.URI(), dest))
+	} else {
+		// A destination in the folder is given relative to it, so that
+		// it's checked as such.
+		dest = filepath.Clean(dest)
+		if rel, err := filepath.Rel(filepath.Clean(folderFs.URI()), dest); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, errors.New("destination in the folder must be a path relative to it")
+		}
+		destFs = fs.NewFilesystem(fs.FilesystemTypeBasic, dest)
+	}
+
+	restoreErrors, err := versioner.RestoreSnapshot(ver, folderFs, dir, asOf, destFs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Announce the restored files right away rather than waiting for
+	// the watcher or the next full scan.
+	if inFolder {
+		go func() { _ = m.ScanFolderSubdirs(folder, []string{dest}) }()
+	}
+
+	return restoreErrors, nil
+}
+
 func (m *model) Availability(folder string, file protocol.FileInfo, block protocol.BlockInfo) ([]Availability, error) {
 	m.mut.RLock()
 	defer m.mut.RUnlock()
@@ -2886,7 +3335,16 @@ func (m *model) fileAvailability(cfg config.FolderConfiguration, file protocol.F
 	return m.fileAvailabilityRLocked(cfg, file)
 }
 
+// fileAvailabilityRLocked returns the connected devices that have the
+// file or, if there are none, those that forward requests for the folder.
 func (m *model) fileAvailabilityRLocked(cfg config.FolderConfiguration, file protocol.FileInfo) []Availability {
+	if availabilities := m.connectedFileAvailabilityRLocked(cfg, file); len(availabilities) > 0 {
+		return availabilities
+	}
+	return m.forwardingAvailabilityRLocked(cfg)
+}
+
+func (m *model) connectedFileAvailabilityRLocked(cfg config.FolderConfiguration, file protocol.FileInfo) []Availability {
 	var availabilities []Availability
 	devs, err := m.sdb.GetGlobalAvailability(cfg.ID, file.Name)
 	if err != nil {
@@ -2899,7 +3357,7 @@ func (m *model) fileAvailabilityRLocked(cfg config.FolderConfiguration, file pro
 		if state := m.remoteFolderStates[device][cfg.ID]; state != remoteFolderValid {
 			continue
 		}
-		_, ok := m.deviceConnIDs[device]
+		_, ok := m.undrainedConnectionRLocked(device)
 		if ok {
 			availabilities = append(availabilities, Availability{ID: device, FromTemporary: false})
 		}
@@ -2970,6 +3428,8 @@ func (m *model) CommitConfiguration(from, to config.Configuration) bool {
 	// Delay processing config changes until after the initial setup
 	<-m.started
 
+	m.scheduleShareExpiry(to)
+
 	// Go through the folder configs and figure out if we need to restart or not.
 
 	// Tracks devices affected by any configuration change to resend ClusterConfig.
@@ -3009,6 +3469,29 @@ func (m *model) CommitConfiguration(from, to config.Configuration) bool {
 			continue
 		}
 
+		// Forget what an untrusted device has when rotating its encryption
+		// password, as it all needs to be encrypted anew.
+		for _, dev := range encryptionRotationsStarted(fromCfg, toCfg) {
+			slog.Info("Starting to rotate encryption password", toCfg.LogAttr(), dev.LogAttr())
+			if err := m.sdb.DropAllFiles(folderID, dev); err != nil {
+				m.fatal(err)
+				return true
+			}
+			clusterConfigDevices.add([]protocol.DeviceID{dev})
+		}
+		for _, dev := range encryptionSubtreesChanged(fromCfg, toCfg) {
+			slog.Info("Encrypted subtrees changed, sending full index", toCfg.LogAttr(), dev.LogAttr())
+			if err := m.sdb.DropAllFiles(folderID, dev); err != nil {
+				m.fatal(err)
+				return true
+			}
+			if err := m.setFullIndexPending(folderID, dev); err != nil {
+				m.fatal(err)
+				return true
+			}
+			clusterConfigDevices.add([]protocol.DeviceID{dev})
+		}
+
 		// This folder exists on both sides. Settings might have changed.
 		// Check if anything differs that requires a restart.
 		if !reflect.DeepEqual(fromCfg.RequiresRestartOnly(), toCfg.RequiresRestartOnly()) || from.Options.CacheIgnoredFiles != to.Options.CacheIgnoredFiles {
@@ -3082,6 +3565,14 @@ func (m *model) CommitConfiguration(from, to config.Configuration) bool {
 		}
 	}
 
+	if m.updateGlobalPause(to.Options.GlobalPause) {
+		m.mut.RLock()
+		for deviceID := range m.deviceConnIDs {
+			closeDevices = append(closeDevices, deviceID)
+		}
+		m.mut.RUnlock()
+	}
+
 	// Clean up after removed devices
 	removedDevices := make([]protocol.DeviceID, 0, len(fromDevices))
 	m.mut.Lock()
@@ -3089,6 +3580,7 @@ func (m *model) CommitConfiguration(from, to config.Configuration) bool {
 		delete(m.deviceStatRefs, deviceID)
 		removedDevices = append(removedDevices, deviceID)
 		delete(clusterConfigDevices, deviceID)
+		unregisterDeviceMetrics(deviceID.String())
 	}
 	m.mut.Unlock()
 
@@ -3109,6 +3601,16 @@ func (m *model) CommitConfiguration(from, to config.Configuration) bool {
 			}
 		}
 	}
+	// Compression settings apply to the messages sent from now on, no
+	// need to reconnect.
+	for deviceID, conns := range m.deviceConnIDs {
+		if deviceCfg, ok := toDevices[deviceID]; ok {
+			policy := deviceCfg.CompressionPolicy(to.Folders)
+			for _, connID := range conns {
+				m.connections[connID].SetCompressionPolicy(policy)
+			}
+		}
+	}
 	m.mut.RUnlock()
 	// Generating cluster-configs acquires the mutex.
 	m.sendClusterConfig(clusterConfigDevices.AsSlice())
@@ -3117,7 +3619,9 @@ func (m *model) CommitConfiguration(from, to config.Configuration) bool {
 	m.cleanPending(toDevices, toFolders, ignoredDevices, removedFolders)
 
 	m.globalRequestLimiter.SetCapacity(1024 * to.Options.MaxConcurrentIncomingRequestKiB())
-	m.folderIOLimiter.SetCapacity(to.Options.MaxFolderConcurrency())
+	m.uploadSlots.SetLimits(to.Options.MaxUploadSlots, to.Options.MaxUploadSlotsPerDevice)
+	m.scheduler.setOptions(to.Options)
+	m.memoryLimiter.SetCapacity(to.Options.MaxMemoryBudgetMiB << 20)
 
 	// Some options don't require restart as those components handle it fine
 	// by themselves. Compare the options structs containing only the
@@ -3423,34 +3927,47 @@ func encryptionTokenPath(cfg config.FolderConfiguration) string {
 }
 
 type storedEncryptionToken struct {
-	FolderID string
-	Token    []byte
+	FolderID      string
+	Token         []byte
+	PreviousToken []byte `json:",omitempty"` // while rotating to Token
 }
 
 func readEncryptionToken(cfg config.FolderConfiguration) ([]byte, error) {
-	fd, err := cfg.Filesystem().Open(encryptionTokenPath(cfg))
+	stored, err := readStoredEncryptionToken(cfg)
 	if err != nil {
 		return nil, err
 	}
+	return stored.Token, nil
+}
+
+func readStoredEncryptionToken(cfg config.FolderConfiguration) (storedEncryptionToken, error) {
+	fd, err := cfg.Filesystem().Open(encryptionTokenPath(cfg))
+	if err != nil {
+		return storedEncryptionToken{}, err
+	}
 	defer fd.Close()
 	var stored storedEncryptionToken
 	if err := json.NewDecoder(fd).Decode(&stored); err != nil {
-		return nil, err
+		return storedEncryptionToken{}, err
 	}
-	return stored.Token, nil
+	return stored, nil
 }
 
 func writeEncryptionToken(token []byte, cfg config.FolderConfiguration) error {
+	return writeStoredEncryptionToken(storedEncryptionToken{
+		FolderID: cfg.ID,
+		Token:    token,
+	}, cfg)
+}
+
+func writeStoredEncryptionToken(stored storedEncryptionToken, cfg config.FolderConfiguration) error {
 	tokenName := encryptionTokenPath(cfg)
-	fd, err := cfg.Filesystem().OpenFile(tokenName, fs.OptReadWrite|fs.OptCreate, 0o666)
+	fd, err := cfg.Filesystem().OpenFile(tokenName, fs.OptReadWrite|fs.OptCreate|fs.OptTruncate, 0o666)
 	if err != nil {
 		return err
 	}
 	defer fd.Close()
-	return json.NewEncoder(fd).Encode(storedEncryptionToken{
-		FolderID: cfg.ID,
-		Token:    token,
-	})
+	return json.NewEncoder(fd).Encode(stored)
 }
 
 func newFolderConfiguration(w config.Wrapper, id, label string, fsType config.FilesystemType, path string) config.FolderConfiguration {
@@ -37,6 +37,7 @@ import (
 	protocolmocks "github.com/syncthing/syncthing/lib/protocol/mocks"
 	srand "github.com/syncthing/syncthing/lib/rand"
 	"github.com/syncthing/syncthing/lib/semaphore"
+	"github.com/syncthing/syncthing/lib/stats"
 	"github.com/syncthing/syncthing/lib/testutil"
 	"github.com/syncthing/syncthing/lib/versioner"
 )
@@ -220,7 +221,7 @@ func BenchmarkRequestOut(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		data, err := m.RequestGlobal(b.Context(), device1, "default", files[i%n].Name, 0, 0, 32, nil, false)
+		data, err := m.RequestGlobal(b.Context(), device1, "default", files[i%n].Name, 0, 0, 32, nil, false, false)
 		if err != nil {
 			b.Error(err)
 		}
@@ -1653,6 +1654,126 @@ func TestEmptyIgnores(t *testing.T) {
 	}
 }
 
+func TestScanSkipped(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	ffs := fcfg.Filesystem()
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	writeFile(t, ffs, "kept", []byte("kept"))
+	writeFile(t, ffs, "debug.log", []byte("log"))
+	must(t, ffs.MkdirAll("sub", 0o755))
+	writeFile(t, ffs, "sub/trace.log", []byte("log"))
+	if err := m.SetIgnores(fcfg.ID, []string{"*.log"}); err != nil {
+		t.Fatal(err)
+	}
+	must(t, m.ScanFolder(fcfg.ID))
+
+	skipped, err := m.ScanSkipped(fcfg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped.Ignored != 2 {
+		t.Errorf("expected 2 ignored items, got %d", skipped.Ignored)
+	}
+
+	// Scans of parts of the folder leave the counts alone.
+	must(t, m.ScanFolderSubdirs(fcfg.ID, []string{"sub"}))
+	if skipped, _ := m.ScanSkipped(fcfg.ID); skipped.Ignored != 2 {
+		t.Errorf("expected 2 ignored items after scanning a subdirectory, got %d", skipped.Ignored)
+	}
+}
+
+func TestRescanFile(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	ffs := fcfg.Filesystem()
+	must(t, ffs.MkdirAll("dir", 0o755))
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	writeFile(t, ffs, "dir/file", []byte("contents"))
+	writeFile(t, ffs, "dir/other", []byte("other"))
+
+	file, err := m.RescanFile(fcfg.ID, "dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Size != int64(len("contents")) || len(file.Blocks) != 1 {
+		t.Errorf("expected the file as scanned, got %v", file)
+	}
+	if _, ok, _ := m.CurrentFolderFile(fcfg.ID, filepath.Join("dir", "other")); ok {
+		t.Error("expected other files in the directory not to be scanned")
+	}
+
+	if _, err := m.RescanFile(fcfg.ID, "dir"); err == nil {
+		t.Error("expected an error rescanning a directory")
+	}
+
+	must(t, ffs.Remove(filepath.Join("dir", "file")))
+	file, err = m.RescanFile(fcfg.ID, "dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !file.IsDeleted() {
+		t.Errorf("expected the file to be deleted, got %v", file)
+	}
+
+	if _, err := m.RescanFile(fcfg.ID, "nonexistent"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %v, got %v", fs.ErrNotExist, err)
+	}
+
+	// A file in a new directory comes with the directory.
+	must(t, ffs.MkdirAll("new", 0o755))
+	writeFile(t, ffs, "new/file", []byte("contents"))
+	if _, err := m.RescanFile(fcfg.ID, "new/file"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := m.CurrentFolderFile(fcfg.ID, "new"); !ok {
+		t.Error("expected the new directory to be scanned as well")
+	}
+}
+
+func TestFileActivity(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	ffs := fcfg.Filesystem()
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	writeFile(t, ffs, "a", []byte("a"))
+	writeFile(t, ffs, "b", []byte("b"))
+	must(t, m.ScanFolder(fcfg.ID))
+	writeFile(t, ffs, "a", []byte("changed"))
+	must(t, ffs.Remove("b"))
+	must(t, m.ScanFolder(fcfg.ID))
+
+	// Changes by devices we don't know are counted by their short ID.
+	unknown := protocol.DeviceID{7}
+	m.mut.RLock()
+	r, _ := m.folderRunners.Get(fcfg.ID)
+	m.mut.RUnlock()
+	f := r.(*sendReceiveFolder)
+	must(t, f.updateLocalsFromPulling([]protocol.FileInfo{{Name: "c", ModifiedBy: unknown.Short(), Version: protocol.Vector{}.Update(unknown.Short())}}))
+
+	activity, err := m.FileActivity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	days := activity[myID.String()]
+	if len(days) != 1 {
+		t.Fatalf("expected activity on one day, got %+v", days)
+	}
+	if days[0].Activity != (stats.Activity{Added: 2, Changed: 1, Deleted: 1}) {
+		t.Errorf("unexpected activity %+v", days[0])
+	}
+	if len(activity[device1.String()]) != 0 {
+		t.Errorf("expected no activity for %v, got %+v", device1, activity[device1.String()])
+	}
+	days = activity[unknown.Short().String()]
+	if len(days) != 1 || days[0].Activity != (stats.Activity{Added: 1}) {
+		t.Errorf("unexpected activity for unknown device %+v", days)
+	}
+}
+
 func waitForState(t *testing.T, sub events.Subscription, folder, expected string) {
 	t.Helper()
 	timeout := time.After(5 * time.Second)
@@ -2828,6 +2949,64 @@ func TestIssue4094(t *testing.T) {
 	}
 }
 
+func TestForgetVersionCatalog(t *testing.T) {
+	m, _, _ := setupModelWithConnection(t)
+	defer cleanupModel(m)
+
+	// Forgetting one folder's catalog leaves those of folders whose ID
+	// starts with the same characters alone.
+	for _, folder := range []string{"a", "a/", "a/b"} {
+		must(t, m.versionCatalog(folder).PutBool("complete", true))
+	}
+	m.forgetVersionCatalog("a")
+	if _, ok, _ := m.versionCatalog("a").Bool("complete"); ok {
+		t.Error("expected catalog to be forgotten")
+	}
+	for _, folder := range []string{"a/", "a/b"} {
+		if _, ok, _ := m.versionCatalog(folder).Bool("complete"); !ok {
+			t.Errorf("expected catalog of %q to be kept", folder)
+		}
+	}
+}
+
+func TestRestoreFolderSnapshot(t *testing.T) {
+	fcfg := newFolderConfiguration(defaultCfgWrapper, "default", "default", config.FilesystemTypeBasic, t.TempDir())
+	fcfg.Versioning.Type = "simple"
+	fcfg.FSWatcherEnabled = false
+	ffs := fcfg.Filesystem()
+	must(t, ffs.MkdirAll("dir", 0o755))
+	writeFile(t, ffs, filepath.Join("dir", "file"), []byte("data"))
+
+	w, cancel := newConfigWrapper(config.Configuration{Version: config.CurrentVersion, Folders: []config.FolderConfiguration{fcfg}})
+	defer cancel()
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+	must(t, m.ScanFolder("default"))
+
+	// An absolute path into the folder would escape the checks that the
+	// destination isn't the restored directory itself.
+	if _, err := m.RestoreFolderSnapshot("default", "dir", time.Now(), filepath.Join(ffs.URI(), "dir", ".")); err == nil {
+		t.Fatal("expected an absolute destination in the folder to be refused")
+	}
+
+	// The restored files are scanned without waiting for the watcher or
+	// the next full scan.
+	restoreErrors, err := m.RestoreFolderSnapshot("default", "dir", time.Now(), "restored")
+	must(t, err)
+	if len(restoreErrors) != 0 {
+		t.Fatal("unexpected restore errors:", restoreErrors)
+	}
+	name := filepath.Join("restored", "file")
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if _, ok := m.testCurrentFolderFile("default", name); ok {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("restored file was not scanned")
+		}
+	}
+}
+
 func TestIssue4903(t *testing.T) {
 	wrapper, cancel := newConfigWrapper(config.Configuration{Version: config.CurrentVersion})
 	defer cancel()
@@ -3014,6 +3193,109 @@ func TestRequestLimit(t *testing.T) {
 	}
 }
 
+func TestRequestLimitInteractive(t *testing.T) {
+	wrapper, fcfg := newDefaultCfgWrapper(t)
+	ffs := fcfg.Filesystem()
+
+	file := "tmpfile"
+	fd, err := ffs.Create(file)
+	must(t, err)
+	fd.Close()
+	waiter, err := wrapper.Modify(func(cfg *config.Configuration) {
+		cfg.Options.MaxUploadSlotsPerDevice = 1
+	})
+	must(t, err)
+	waiter.Wait()
+	m, conn := setupModelWithConnectionFromWrapper(t, wrapper)
+	defer cleanupModel(m)
+	m.ScanFolder("default")
+
+	first, err := m.Request(conn, &protocol.Request{Folder: "default", Name: file, Size: 2000})
+	if err != nil {
+		t.Fatalf("First request failed: %v", err)
+	}
+
+	// An interactive request waits for the device's upload slot like any
+	// other, but goes ahead of the regular request that waited before it.
+	returned := make(chan bool, 2)
+	request := func(interactive bool) {
+		res, err := m.Request(conn, &protocol.Request{Folder: "default", Name: file, Size: 2000, Interactive: interactive})
+		if err != nil {
+			t.Errorf("Request failed: %v", err)
+		} else {
+			defer res.Close()
+		}
+		returned <- interactive
+	}
+	go request(false)
+	time.Sleep(100 * time.Millisecond)
+	go request(true)
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-returned:
+		t.Fatal("Request returned before the first was done")
+	default:
+	}
+
+	first.Close()
+	for _, exp := range []bool{true, false} {
+		select {
+		case interactive := <-returned:
+			if interactive != exp {
+				t.Errorf("Expected interactive request to be served first")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Request did not return after the first was done")
+		}
+	}
+}
+
+func TestRequestInteractivePerDevice(t *testing.T) {
+	wrapper, fcfg := newDefaultCfgWrapper(t)
+	ffs := fcfg.Filesystem()
+
+	file := "tmpfile"
+	fd, err := ffs.Create(file)
+	must(t, err)
+	fd.Close()
+	m, conn := setupModelWithConnectionFromWrapper(t, wrapper)
+	defer cleanupModel(m)
+	m.ScanFolder("default")
+
+	// Only so many requests from a device are served as interactive at
+	// once; the flag is cleared for the rest, also for their responses.
+	var held []protocol.RequestResponse
+	for range maxInteractiveRequestsPerDevice {
+		req := &protocol.Request{Folder: "default", Name: file, Size: 100, Interactive: true}
+		res, err := m.Request(conn, req)
+		must(t, err)
+		if !req.Interactive {
+			t.Fatal("Expected request to be served as interactive")
+		}
+		held = append(held, res)
+	}
+	req := &protocol.Request{Folder: "default", Name: file, Size: 100, Interactive: true}
+	res, err := m.Request(conn, req)
+	must(t, err)
+	res.Close()
+	if req.Interactive {
+		t.Error("Expected request beyond the limit to be served as regular")
+	}
+
+	held[0].Close()
+	time.Sleep(10 * time.Millisecond)
+	req = &protocol.Request{Folder: "default", Name: file, Size: 100, Interactive: true}
+	res, err = m.Request(conn, req)
+	must(t, err)
+	res.Close()
+	if !req.Interactive {
+		t.Error("Expected request to be served as interactive again")
+	}
+	for _, res := range held[1:] {
+		res.Close()
+	}
+}
+
 // TestConnCloseOnRestart checks that there is no deadlock when calling Close
 // on a protocol connection that has a blocking reader (blocking writer can't
 // be done as the test requires clusterconfigs to go through).
@@ -3170,6 +3452,29 @@ func TestDeviceWasSeen(t *testing.T) {
 	}
 }
 
+func TestFolderSizeHistory(t *testing.T) {
+	wcfg, fcfg := newDefaultCfgWrapper(t)
+	m := setupModel(t, wcfg)
+	defer cleanupModel(m)
+
+	writeFile(t, fcfg.Filesystem(), "foo", []byte("foobar"))
+	must(t, m.ScanFolder(fcfg.ID))
+
+	m.recordSizeHistory()
+	hist, err := m.FolderSizeHistory(fcfg.ID)
+	must(t, err)
+	if len(hist.Hourly) != 1 || len(hist.Daily) != 1 {
+		t.Fatalf("expected one sample, got %+v", hist)
+	}
+	if sample := hist.Hourly[0]; sample.GlobalBytes != 6 || sample.LocalBytes != 6 || sample.NeedItems != 0 {
+		t.Errorf("unexpected sample %+v", sample)
+	}
+
+	if _, err := m.FolderSizeHistory("nonexistent"); !errors.Is(err, ErrFolderMissing) {
+		t.Error("expected missing folder error, got", err)
+	}
+}
+
 func TestNewLimitedRequestResponse(t *testing.T) {
 	l0 := semaphore.New(0)
 	l1 := semaphore.New(1024)
@@ -3591,11 +3896,11 @@ func testConfigChangeTriggersClusterConfigs(t *testing.T, expectFirst, expectSec
 	cc1 := make(chan struct{}, 1)
 	cc2 := make(chan struct{}, 1)
 	fc1 := newFakeConnection(device1, m)
-	fc1.ClusterConfigCalls(func(_ *protocol.ClusterConfig, _ map[string]string) {
+	fc1.ClusterConfigCalls(func(_ *protocol.ClusterConfig, _ map[string]protocol.EncryptionPassword) {
 		cc1 <- struct{}{}
 	})
 	fc2 := newFakeConnection(device2, m)
-	fc2.ClusterConfigCalls(func(_ *protocol.ClusterConfig, _ map[string]string) {
+	fc2.ClusterConfigCalls(func(_ *protocol.ClusterConfig, _ map[string]protocol.EncryptionPassword) {
 		cc2 <- struct{}{}
 	})
 	m.AddConnection(fc1, protocol.Hello{})
@@ -3898,6 +4203,78 @@ func TestCcCheckEncryption(t *testing.T) {
 	}
 }
 
+func TestCcCheckEncryptionRotation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping on short testing - generating encryption tokens is slow")
+	}
+
+	w, fcfg := newDefaultCfgWrapper(t)
+	m := setupModel(t, w)
+	m.cancel()
+	defer cleanupModel(m)
+
+	oldToken := protocol.PasswordToken(m.keyGen, fcfg.ID, "old")
+	newToken := protocol.PasswordToken(m.keyGen, fcfg.ID, "new")
+	otherToken := protocol.PasswordToken(m.keyGen, fcfg.ID, "other")
+
+	check := func(fcfg config.FolderConfiguration, dcfg config.FolderDeviceConfiguration, remote protocol.Device) error {
+		return m.ccCheckEncryption(fcfg, dcfg, &clusterConfigDeviceInfo{
+			remote: remote,
+			local:  protocol.Device{ID: myID},
+		}, true)
+	}
+
+	// The trusted side accepts both passwords while rotating.
+	dcfg := config.FolderDeviceConfiguration{DeviceID: device1, EncryptionPassword: "new", PreviousEncryptionPassword: "old"}
+	for _, token := range [][]byte{oldToken, newToken} {
+		if err := check(fcfg, dcfg, protocol.Device{ID: device1, EncryptionPasswordToken: token}); err != nil {
+			t.Error("Expected token to be accepted while rotating, got", err)
+		}
+	}
+	if err := check(fcfg, dcfg, protocol.Device{ID: device1, EncryptionPasswordToken: otherToken}); err != errEncryptionPassword {
+		t.Errorf("Expected error %v, got %v", errEncryptionPassword, err)
+	}
+
+	// The untrusted side takes on the new token when the previous one is
+	// announced with it.
+	efcfg := fcfg.Copy()
+	efcfg.Type = config.FolderTypeReceiveEncrypted
+	dcfg = config.FolderDeviceConfiguration{DeviceID: device1}
+	m.folderEncryptionPasswordTokens[fcfg.ID] = oldToken
+	rotating := protocol.Device{ID: device1, EncryptionPasswordToken: newToken, PreviousEncryptionPasswordToken: oldToken}
+	if err := check(efcfg, dcfg, protocol.Device{ID: device1, EncryptionPasswordToken: newToken}); err != errEncryptionPassword {
+		t.Errorf("Expected error %v, got %v", errEncryptionPassword, err)
+	}
+	if err := check(efcfg, dcfg, rotating); err != nil {
+		t.Fatal("Expected rotation to be accepted, got", err)
+	}
+	if token := m.folderEncryptionPasswordTokens[fcfg.ID]; !bytes.Equal(token, newToken) {
+		t.Error("Expected to have taken on the new token")
+	}
+	if stored, err := readStoredEncryptionToken(efcfg); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(stored.Token, newToken) || !bytes.Equal(stored.PreviousToken, oldToken) {
+		t.Error("Expected both tokens to be stored while rotating")
+	}
+
+	// Devices that haven't rotated yet are still accepted.
+	if err := check(efcfg, dcfg, protocol.Device{ID: device2, EncryptionPasswordToken: oldToken}); err != nil {
+		t.Error("Expected previous token to be accepted while rotating, got", err)
+	}
+
+	// Once the trusted device stops announcing the previous token, it
+	// isn't accepted anymore.
+	if err := check(efcfg, dcfg, protocol.Device{ID: device1, EncryptionPasswordToken: newToken}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.folderPreviousPasswordTokens[fcfg.ID]; ok {
+		t.Error("Expected rotation to be finished")
+	}
+	if err := check(efcfg, dcfg, protocol.Device{ID: device2, EncryptionPasswordToken: oldToken}); err != errEncryptionPassword {
+		t.Errorf("Expected error %v, got %v", errEncryptionPassword, err)
+	}
+}
+
 func TestCCFolderNotRunning(t *testing.T) {
 	// Create the folder, but don't start it.
 	w, fcfg := newDefaultCfgWrapper(t)
@@ -4101,3 +4478,78 @@ func countIterator[T any](t *testing.T) func(it iter.Seq[T], errFn func() error)
 		return count
 	}
 }
+
+func TestShareExpiry(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	for i := range fcfg.Devices {
+		if fcfg.Devices[i].DeviceID == device1 {
+			fcfg.Devices[i].Expires = time.Now().Add(100 * time.Millisecond)
+		}
+	}
+	setFolder(t, w, fcfg)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		fcfg, _ = w.Folder(fcfg.ID)
+		if _, ok := fcfg.Device(device1); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the share to expire")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, ok := fcfg.Device(myID); !ok {
+		t.Error("Expected folder to still be shared with ourselves")
+	}
+}
+
+func TestFolderCompressionPolicy(t *testing.T) {
+	m, fc, fcfg := setupModelWithConnection(t)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	if policy := fc.SetCompressionPolicyArgsForCall(0); len(policy.Folders) != 0 {
+		t.Errorf("expected no folder overrides, got %v", policy.Folders)
+	}
+
+	fcfg, _ = m.cfg.Folder(fcfg.ID)
+	for i := range fcfg.Devices {
+		if fcfg.Devices[i].DeviceID == device1 {
+			fcfg.Devices[i].Compression = config.CompressionOverrideNever
+		}
+	}
+	setFolder(t, m.cfg, fcfg)
+
+	policy := fc.SetCompressionPolicyArgsForCall(fc.SetCompressionPolicyCallCount() - 1)
+	if s, ok := policy.Folders[fcfg.ID]; !ok || s.Compression != protocol.CompressionNever {
+		t.Errorf("expected the folder to override compression, got %v", policy.Folders)
+	}
+}
+
+func TestDrainingDeviceUnavailable(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	fc := addFakeConn(m, device1, fcfg.ID)
+	fc.addFile("file", 0o644, protocol.FileInfoTypeFile, []byte("contents"))
+	fc.sendIndexUpdate()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for len(m.fileAvailability(fcfg, protocol.FileInfo{Name: "file"})) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the index")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	fc.DrainingReturns(true)
+	if av := m.fileAvailability(fcfg, protocol.FileInfo{Name: "file"}); len(av) != 0 {
+		t.Errorf("expected the file to be unavailable from a draining device, got %v", av)
+	}
+	if _, ok := m.requestConnectionForDevice(device1); ok {
+		t.Error("expected no connection to take requests")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/ignore"
+)
+
+// Pinned paths are always kept locally and up to date, even when the
+// ignore patterns say otherwise. A pinned directory pins everything below
+// it. The pins are kept in the database rather than in .stignore, as they
+// are a property of this device only.
+
+func (m *model) pins(folder string) *db.Typed {
+	return db.NewTyped(m.sdb, "pins/"+folder)
+}
+
+// Pinned returns the pinned paths of the folder, sorted.
+func (m *model) Pinned(folder string) ([]string, error) {
+	if _, ok := m.cfg.Folder(folder); !ok {
+		return nil, ErrFolderMissing
+	}
+	return m.readPinned(folder)
+}
+
+func (m *model) readPinned(folder string) ([]string, error) {
+	it, errFn := m.pins(folder).PrefixBytes("")
+	var pinned []string
+	for kv := range it {
+		pinned = append(pinned, kv.Key)
+	}
+	slices.Sort(pinned)
+	return pinned, errFn()
+}
+
+// SetPinned pins or unpins the path in the folder, and then syncs the
+// folder to get or let go of the pinned files.
+func (m *model) SetPinned(folder, name string, pinned bool) error {
+	cfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return ErrFolderMissing
+	}
+	if cfg.Type == config.FolderTypeReceiveEncrypted {
+		return fmt.Errorf("folder %s: cannot pin files in a receive-encrypted folder", cfg.Description())
+	}
+	name = strings.Trim(path.Clean("/"+strings.ReplaceAll(name, `\`, "/")), "/")
+	if name == "" {
+		return fmt.Errorf("folder %s: cannot pin the folder root", cfg.Description())
+	}
+
+	kv := m.pins(folder)
+	var err error
+	if pinned {
+		err = kv.PutBool(name, true)
+	} else {
+		err = kv.Delete(name)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.mut.RLock()
+	ignores, ok := m.folderIgnores[folder]
+	runner, _ := m.folderRunners.Get(folder)
+	m.mut.RUnlock()
+	if ok {
+		m.loadPinned(cfg, ignores)
+	}
+	if runner != nil {
+		runner.ScheduleScan()
+		runner.SchedulePull()
+	}
+	return nil
+}
+
+// loadPinned sets the pinned paths of the folder on its ignore matcher.
+func (m *model) loadPinned(cfg config.FolderConfiguration, ignores *ignore.Matcher) {
+	if cfg.Type == config.FolderTypeReceiveEncrypted {
+		return
+	}
+	pinned, err := m.readPinned(cfg.ID)
+	if err != nil {
+		slog.Warn("Failed to load pinned paths", cfg.LogAttr(), slogutil.Error(err))
+		return
+	}
+	ignores.SetPinned(pinned)
+}
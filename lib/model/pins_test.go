@@ -0,0 +1,54 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPinned(t *testing.T) {
+	wcfg, fcfg := newDefaultCfgWrapper(t)
+	m := setupModel(t, wcfg)
+	defer cleanupModel(m)
+
+	must(t, m.SetIgnores(fcfg.ID, []string{"/photos"}))
+	must(t, m.ScanFolder(fcfg.ID))
+	m.mut.RLock()
+	ignores := m.folderIgnores[fcfg.ID]
+	m.mut.RUnlock()
+	if !ignores.Match("photos/cover.jpg").IsIgnored() {
+		t.Fatal("expected photos to be ignored")
+	}
+
+	must(t, m.SetPinned(fcfg.ID, "/photos//cover.jpg", true))
+	must(t, m.SetPinned(fcfg.ID, "notes", true))
+	pinned, err := m.Pinned(fcfg.ID)
+	must(t, err)
+	if !slices.Equal(pinned, []string{"notes", "photos/cover.jpg"}) {
+		t.Errorf("unexpected pinned paths %v", pinned)
+	}
+	if ignores.Match("photos/cover.jpg").IsIgnored() {
+		t.Error("expected pinned file not to be ignored")
+	}
+	if !ignores.Match("photos/other.jpg").IsIgnored() {
+		t.Error("expected other file to be ignored")
+	}
+
+	must(t, m.SetPinned(fcfg.ID, "photos/cover.jpg", false))
+	if !ignores.Match("photos/cover.jpg").IsIgnored() {
+		t.Error("expected unpinned file to be ignored")
+	}
+
+	if err := m.SetPinned(fcfg.ID, "/", true); err == nil {
+		t.Error("expected error pinning the folder root")
+	}
+	if _, err := m.Pinned("nonexistent"); !errors.Is(err, ErrFolderMissing) {
+		t.Error("expected missing folder error, got", err)
+	}
+}
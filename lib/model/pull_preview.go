@@ -0,0 +1,169 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// A PullPreview is what pulling a folder would do, as of its current
+// indexes.
+type PullPreview struct {
+	Created []string        `json:"created"`
+	Updated []string        `json:"updated"`
+	Deleted []string        `json:"deleted"`
+	Renamed []PreviewRename `json:"renamed"`
+	// BytesToTransfer is the size of the blocks that would be requested
+	// from other devices, those not found in the folder's local files.
+	BytesToTransfer int64 `json:"bytesToTransfer"`
+}
+
+// A PreviewRename is a deleted file that would be renamed to a new file
+// with the same content, rather than removed.
+type PreviewRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PreviewPull returns what a pull of the folder would do, judged the way
+// the puller does it, without pulling. Pulls that would fail, for example
+// for lack of space or availability, are included as if they succeeded.
+func (m *model) PreviewPull(folder string) (PullPreview, error) {
+	m.mut.RLock()
+	cfg, ok := m.folderCfgs[folder]
+	ignores := m.folderIgnores[folder]
+	m.mut.RUnlock()
+	if !ok {
+		return PullPreview{}, ErrFolderMissing
+	}
+
+	preview := PullPreview{
+		Created: []string{},
+		Updated: []string{},
+		Deleted: []string{},
+		Renamed: []PreviewRename{},
+	}
+	if cfg.Type == config.FolderTypeSendOnly {
+		return preview, nil
+	}
+
+	// Deleted files with a local copy, by the hash of their blocks, as
+	// candidates for renames.
+	deletions := make(map[string]struct{})
+	buckets := make(map[string][]protocol.FileInfo)
+	var toPull []protocol.FileInfo
+
+	for file, err := range itererr.Zip(m.sdb.AllNeededGlobalFiles(folder, protocol.LocalDeviceID, cfg.Order, 0, 0)) {
+		if err != nil {
+			return PullPreview{}, err
+		}
+		if cfg.IgnoreDelete && file.IsDeleted() {
+			continue
+		}
+		if ignores != nil && ignores.Match(file.Name).IsIgnored() {
+			continue
+		}
+
+		cur, hasCur, err := m.sdb.GetDeviceFile(folder, protocol.LocalDeviceID, file.Name)
+		if err != nil {
+			return PullPreview{}, err
+		}
+		exists := hasCur && !cur.IsDeleted()
+
+		switch {
+		case file.IsDeleted():
+			if !exists {
+				continue
+			}
+			if file.Type == protocol.FileInfoTypeFile && cur.Type == protocol.FileInfoTypeFile && !cur.IsInvalid() {
+				deletions[file.Name] = struct{}{}
+				key := string(cur.BlocksHash)
+				buckets[key] = append(buckets[key], cur)
+				continue
+			}
+			preview.Deleted = append(preview.Deleted, file.Name)
+
+		case file.Type == protocol.FileInfoTypeFile:
+			if exists && file.BlocksEqual(cur) {
+				// Only the metadata changes.
+				preview.Updated = append(preview.Updated, file.Name)
+				continue
+			}
+			toPull = append(toPull, file)
+
+		case exists:
+			preview.Updated = append(preview.Updated, file.Name)
+
+		default:
+			preview.Created = append(preview.Created, file.Name)
+		}
+	}
+
+	// Files that have the content of a deleted file are renamed from it,
+	// the others are pulled.
+	available := make(map[string]bool)
+	for _, file := range toPull {
+		if candidate, ok := popCandidate(buckets, string(file.BlocksHash)); ok {
+			delete(deletions, candidate.Name)
+			preview.Renamed = append(preview.Renamed, PreviewRename{From: candidate.Name, To: file.Name})
+			continue
+		}
+
+		cur, hasCur, err := m.sdb.GetDeviceFile(folder, protocol.LocalDeviceID, file.Name)
+		if err != nil {
+			return PullPreview{}, err
+		}
+		if hasCur && !cur.IsDeleted() {
+			preview.Updated = append(preview.Updated, file.Name)
+		} else {
+			preview.Created = append(preview.Created, file.Name)
+		}
+
+		for _, block := range file.Blocks {
+			key := string(block.Hash)
+			have, ok := available[key]
+			if !ok {
+				have, err = m.hasLocalBlock(folder, block.Hash)
+				if err != nil {
+					return PullPreview{}, err
+				}
+				available[key] = have
+			}
+			if !have {
+				preview.BytesToTransfer += int64(block.Size)
+				// Once pulled, the block can be copied for other files.
+				available[key] = true
+			}
+		}
+	}
+	for name := range deletions {
+		preview.Deleted = append(preview.Deleted, name)
+	}
+
+	slices.Sort(preview.Created)
+	slices.Sort(preview.Updated)
+	slices.Sort(preview.Deleted)
+	slices.SortFunc(preview.Renamed, func(a, b PreviewRename) int {
+		return strings.Compare(a.To, b.To)
+	})
+	return preview, nil
+}
+
+// hasLocalBlock returns true if a local file of the folder has a block
+// with the given hash.
+func (m *model) hasLocalBlock(folder string, hash []byte) (bool, error) {
+	it, errFn := m.sdb.AllLocalBlocksWithHash(folder, hash)
+	for range it {
+		return true, nil
+	}
+	return false, errFn()
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestPreviewPull(t *testing.T) {
+	local := func(name string, blockNumbers ...int) protocol.FileInfo {
+		fi := setupFile(name, blockNumbers)
+		fi.Version = protocol.Vector{}.Update(myID.Short())
+		return fi
+	}
+	var sequence int64
+	remote := func(fi protocol.FileInfo) protocol.FileInfo {
+		sequence++
+		fi.Version = fi.Version.Update(device1.Short())
+		fi.Sequence = sequence
+		return fi
+	}
+	deleted := func(fi protocol.FileInfo) protocol.FileInfo {
+		fi.Deleted = true
+		fi.Blocks = nil
+		return remote(fi)
+	}
+
+	keep, moved, gone := local("keep", 1, 2), local("moved", 3), local("gone", 4)
+	m, f := setupSendReceiveFolder(t, keep, moved, gone)
+	defer cleanupModel(m)
+
+	changed := keep
+	changed.Blocks = setupFile("", []int{1, 5}).Blocks
+	must(t, m.sdb.Update(f.folderID, device1, []protocol.FileInfo{
+		remote(changed),
+		deleted(moved),
+		deleted(gone),
+		remote(setupFile("renamed", []int{3})),
+		remote(setupFile("new", []int{2, 6})),
+		remote(protocol.FileInfo{Name: "newdir", Type: protocol.FileInfoTypeDirectory}),
+	}))
+
+	preview, err := m.PreviewPull(f.folderID)
+	must(t, err)
+
+	if !slices.Equal(preview.Created, []string{"new", "newdir"}) {
+		t.Error("unexpected created files", preview.Created)
+	}
+	if !slices.Equal(preview.Updated, []string{"keep"}) {
+		t.Error("unexpected updated files", preview.Updated)
+	}
+	if !slices.Equal(preview.Deleted, []string{"gone"}) {
+		t.Error("unexpected deleted files", preview.Deleted)
+	}
+	if !slices.Equal(preview.Renamed, []PreviewRename{{From: "moved", To: "renamed"}}) {
+		t.Error("unexpected renamed files", preview.Renamed)
+	}
+	// Blocks 5 and 6 are not to be found locally.
+	if expected := int64(blocks[5].Size + blocks[6].Size); preview.BytesToTransfer != expected {
+		t.Errorf("expected %d bytes to transfer, got %d", expected, preview.BytesToTransfer)
+	}
+
+	// Nothing was pulled.
+	if _, ok, err := m.sdb.GetDeviceFile(f.folderID, protocol.LocalDeviceID, "new"); err != nil || ok {
+		t.Error("file should not have been pulled")
+	}
+
+	if _, err := m.PreviewPull("nonexistent"); err != ErrFolderMissing {
+		t.Error("expected missing folder error, got", err)
+	}
+}
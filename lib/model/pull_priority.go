@@ -0,0 +1,66 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/ignore"
+)
+
+// pullPriority ranks files to pull by the folder's pull first and pull last
+// patterns.
+type pullPriority struct {
+	first []*ignore.Matcher
+	last  []*ignore.Matcher
+}
+
+// newPullPriority returns the pull priority for the folder, or nil if it
+// has no patterns. Invalid patterns are logged and left out.
+func newPullPriority(cfg config.FolderConfiguration) *pullPriority {
+	if len(cfg.PullFirst) == 0 && len(cfg.PullLast) == 0 {
+		return nil
+	}
+	return &pullPriority{
+		first: pullPriorityMatchers(cfg, cfg.PullFirst),
+		last:  pullPriorityMatchers(cfg, cfg.PullLast),
+	}
+}
+
+func pullPriorityMatchers(cfg config.FolderConfiguration, patterns []string) []*ignore.Matcher {
+	matchers := make([]*ignore.Matcher, 0, len(patterns))
+	for _, pattern := range patterns {
+		matcher := ignore.New(cfg.Filesystem(), ignore.WithCache(false))
+		if err := matcher.Parse(strings.NewReader(pattern), ".stignore"); err != nil {
+			slog.Warn("Ignoring invalid pull priority pattern", cfg.LogAttr(), slog.String("pattern", pattern), slogutil.Error(err))
+			continue
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers
+}
+
+// rank returns the rank of the named file, lower ranks being pulled first:
+// negative for files matching a pull first pattern, positive for those
+// matching a pull last pattern and zero for the others. The first matching
+// pattern decides.
+func (p *pullPriority) rank(name string) int {
+	for i, m := range p.first {
+		if m.Match(name).IsIgnored() {
+			return i - len(p.first)
+		}
+	}
+	for i, m := range p.last {
+		if m.Match(name).IsIgnored() {
+			return i + 1
+		}
+	}
+	return 0
+}
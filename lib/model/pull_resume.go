@@ -0,0 +1,104 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// When a pull is interrupted, e.g. by a restart, the temporary file is
+// kept with what was written so far. Resuming from it normally means
+// hashing the whole temporary file to find the blocks we already have,
+// which takes a while for large files. Instead we remember which blocks
+// are done, along with the size and modification time of the temporary
+// file to tell whether it was touched since.
+
+type pullProgress struct {
+	BlocksHash []byte    `json:"blocksHash"`
+	BlockSize  int       `json:"blockSize"`
+	Available  []int     `json:"available"`
+	TempSize   int64     `json:"tempSize"`
+	TempMod    time.Time `json:"tempMod"`
+}
+
+func (f *sendReceiveFolder) pullProgress() *db.Typed {
+	return db.NewTyped(f.model.sdb, "pullprogress/"+f.folderID+"/")
+}
+
+// savePullProgress remembers which blocks of the temporary file are done,
+// after the pull was interrupted and the temporary file closed.
+func (f *sendReceiveFolder) savePullProgress(state *sharedPullerState) {
+	if len(state.file.BlocksHash) == 0 {
+		return
+	}
+	state.mut.RLock()
+	available := append([]int(nil), state.available...)
+	state.mut.RUnlock()
+	if len(available) == 0 {
+		return
+	}
+	info, err := f.mtimefs.Lstat(state.tempName)
+	if err != nil {
+		return
+	}
+	bs, err := json.Marshal(pullProgress{
+		BlocksHash: state.file.BlocksHash,
+		BlockSize:  state.file.BlockSize(),
+		Available:  available,
+		TempSize:   info.Size(),
+		TempMod:    info.ModTime(),
+	})
+	if err != nil {
+		return
+	}
+	if err := f.pullProgress().PutBytes(state.file.Name, bs); err != nil {
+		slog.Warn("Failed to save pull progress", f.LogAttr(), slogutil.FilePath(state.file.Name), slogutil.Error(err))
+	}
+}
+
+// resumedBlocks returns the indexes of the blocks of the file that are
+// done in the temporary file, if we know them from an interrupted pull of
+// the same file and the temporary file is as we left it.
+func (f *sendReceiveFolder) resumedBlocks(file protocol.FileInfo, tempName string) ([]int, bool) {
+	kv := f.pullProgress()
+	bs, ok, err := kv.Bytes(file.Name)
+	if err != nil || !ok {
+		return nil, false
+	}
+	// A resumed pull saves its progress again if interrupted.
+	_ = kv.Delete(file.Name)
+
+	var progress pullProgress
+	if err := json.Unmarshal(bs, &progress); err != nil {
+		return nil, false
+	}
+	if !bytes.Equal(progress.BlocksHash, file.BlocksHash) || progress.BlockSize != file.BlockSize() {
+		return nil, false
+	}
+	info, err := f.mtimefs.Lstat(tempName)
+	if err != nil || info.Size() != progress.TempSize || !info.ModTime().Equal(progress.TempMod) {
+		return nil, false
+	}
+	for _, i := range progress.Available {
+		if i < 0 || i >= len(file.Blocks) {
+			return nil, false
+		}
+	}
+	return progress.Available, true
+}
+
+// clearPullProgress forgets about an interrupted pull of the file.
+func (f *sendReceiveFolder) clearPullProgress(name string) {
+	_ = f.pullProgress().Delete(name)
+}
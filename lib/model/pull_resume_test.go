@@ -0,0 +1,58 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+func TestResumePull(t *testing.T) {
+	_, f := setupSendReceiveFolder(t)
+
+	file := setupFile("file", []int{1, 2, 3, 4})
+	file.BlocksHash = []byte("blockshash")
+	tempName := fs.TempName(file.Name)
+	writeFile(t, f.mtimefs, tempName, []byte("partial"))
+
+	state := &sharedPullerState{file: file, tempName: tempName, available: []int{0, 2}}
+	f.savePullProgress(state)
+
+	available, ok := f.resumedBlocks(file, tempName)
+	if !ok || !slices.Equal(available, []int{0, 2}) {
+		t.Fatalf("expected blocks 0 and 2 to be resumed, got %v, %v", available, ok)
+	}
+	if _, ok := f.resumedBlocks(file, tempName); ok {
+		t.Error("expected progress to be used only once")
+	}
+
+	// A changed temporary file can't be trusted.
+	f.savePullProgress(state)
+	future := time.Now().Add(time.Hour)
+	must(t, f.mtimefs.Chtimes(tempName, future, future))
+	if _, ok := f.resumedBlocks(file, tempName); ok {
+		t.Error("expected progress to be ignored for a modified temporary file")
+	}
+
+	// Nor is progress for a different version of the file.
+	f.savePullProgress(state)
+	changed := file
+	changed.BlocksHash = []byte("other")
+	if _, ok := f.resumedBlocks(changed, tempName); ok {
+		t.Error("expected progress to be ignored for different blocks")
+	}
+
+	// Resuming skips hashing the temporary file.
+	f.savePullProgress(state)
+	blocks, reused := f.reuseBlocks(t.Context(), slices.Clone(file.Blocks), nil, file, tempName)
+	if !slices.Equal(reused, []int{0, 2}) || len(blocks) != 2 {
+		t.Errorf("unexpected reused blocks %v, %d blocks to fetch", reused, len(blocks))
+	}
+}
@@ -7,6 +7,8 @@
 package model
 
 import (
+	"cmp"
+	"slices"
 	"sync"
 	"time"
 )
@@ -122,6 +124,21 @@ func (q *jobQueue) Jobs(page, perpage int) ([]string, []string, int) {
 	return progress, queued, (page - 1) * perpage
 }
 
+// SortByRank reorders the queued files by rank, lowest first, keeping the
+// order of files of the same rank.
+func (q *jobQueue) SortByRank(rank func(name string) int) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	ranks := make(map[string]int, len(q.queued))
+	for _, e := range q.queued {
+		ranks[e.name] = rank(e.name)
+	}
+	slices.SortStableFunc(q.queued, func(a, b jobQueueEntry) int {
+		return cmp.Compare(ranks[a.name], ranks[b.name])
+	})
+}
+
 func (q *jobQueue) Reset() {
 	q.mut.Lock()
 	defer q.mut.Unlock()
@@ -9,6 +9,7 @@ package model
 import (
 	"fmt"
 	"math/rand"
+	"path/filepath"
 	"slices"
 	"testing"
 	"time"
@@ -196,6 +197,25 @@ func BenchmarkJobQueuePushPopDone10k(b *testing.B) {
 	}
 }
 
+func TestSortByPullPriority(t *testing.T) {
+	cfg := newFolderConfig()
+	cfg.PullFirst = []string{"*.nfo", "docs"}
+	cfg.PullLast = []string{"*.iso"}
+	prio := newPullPriority(cfg)
+
+	q := newJobQueue()
+	for _, name := range []string{"a.iso", "b", filepath.Join("docs", "c"), "d.nfo", "e"} {
+		q.Push(name, 0, time.Time{})
+	}
+	q.SortByRank(prio.rank)
+
+	_, queued, _ := q.Jobs(1, 100)
+	expected := []string{"d.nfo", filepath.Join("docs", "c"), "b", "e", "a.iso"}
+	if diff, equal := messagediff.PrettyDiff(expected, queued); !equal {
+		t.Errorf("Order does not match. Diff:\n%s", diff)
+	}
+}
+
 func TestQueuePagination(t *testing.T) {
 	q := newJobQueue()
 	// Ten random actions
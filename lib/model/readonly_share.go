@@ -0,0 +1,263 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// A folder may be shared read-only with a device: the device receives the
+// folder, but the changes it makes are refused. The flag is announced in
+// the cluster config, and we remember which devices each trusted peer
+// announces as read-only, so that all devices sharing the folder refuse
+// the changes, not only the one that set the flag. A change is refused by
+// marking the file invalid, so that it can't become the global version.
+// What the device announces is only accepted where it is identical to the
+// global version, by version and block hashes, or older, so that it can
+// still serve as a source for the data. The same holds for changes other
+// devices announce as made by the read-only device. The block hashes are
+// compared by a SHA-256 hash we compute over them, not by the hash the
+// device claims for them.
+
+func (m *model) readOnlyAnnounced(folder string) *db.Typed {
+	return db.NewTyped(m.sdb, "readonly/"+folder+"/")
+}
+
+// readOnlyAnnouncements caches what is stored by readOnlyAnnounced, as
+// it's needed for every index update.
+type readOnlyAnnouncements struct {
+	mut     sync.Mutex
+	folders map[string]map[string][]protocol.DeviceID // folder -> announcing peer -> read-only devices
+}
+
+func newReadOnlyAnnouncements() *readOnlyAnnouncements {
+	return &readOnlyAnnouncements{folders: make(map[string]map[string][]protocol.DeviceID)}
+}
+
+// recordReadOnlyDevices remembers the devices the peer announces as
+// read-only in the folder, replacing what it announced before.
+func (m *model) recordReadOnlyDevices(folder string, peer protocol.DeviceID, devices []protocol.Device) {
+	var ids []protocol.DeviceID
+	for _, dev := range devices {
+		if dev.ReadOnly && dev.ID != peer {
+			ids = append(ids, dev.ID)
+		}
+	}
+
+	c := m.readOnlyAnnouncements
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if announced, ok := c.folders[folder]; ok && slices.Equal(announced[peer.String()], ids) {
+		return
+	}
+	kv := m.readOnlyAnnounced(folder)
+	var err error
+	if len(ids) == 0 {
+		err = kv.Delete(peer.String())
+	} else {
+		strs := make([]string, len(ids))
+		for i, id := range ids {
+			strs[i] = id.String()
+		}
+		err = kv.PutString(peer.String(), strings.Join(strs, ","))
+	}
+	if err != nil {
+		slog.Warn("Failed to store read-only devices announced by peer", slog.String("folder", folder), peer.LogAttr(), slogutil.Error(err))
+		delete(c.folders, folder)
+		return
+	}
+	if announced, ok := c.folders[folder]; ok {
+		if len(ids) == 0 {
+			delete(announced, peer.String())
+		} else {
+			announced[peer.String()] = ids
+		}
+	}
+}
+
+// announcedReadOnlyDevices returns the devices each peer announces as
+// read-only in the folder. The result is a copy, as the cache is updated
+// concurrently.
+func (m *model) announcedReadOnlyDevices(folder string) map[string][]protocol.DeviceID {
+	c := m.readOnlyAnnouncements
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if announced, ok := c.folders[folder]; ok {
+		return cloneAnnounced(announced)
+	}
+
+	announced := make(map[string][]protocol.DeviceID)
+	it, errFn := m.readOnlyAnnounced(folder).PrefixBytes("")
+	for kv := range it {
+		for _, id := range strings.Split(string(kv.Value), ",") {
+			if dev, err := protocol.DeviceIDFromString(id); err == nil {
+				announced[kv.Key] = append(announced[kv.Key], dev)
+			}
+		}
+	}
+	if err := errFn(); err != nil {
+		slog.Warn("Failed to load read-only devices announced by peers", slog.String("folder", folder), slogutil.Error(err))
+		return announced
+	}
+	c.folders[folder] = announced
+	return cloneAnnounced(announced)
+}
+
+func cloneAnnounced(announced map[string][]protocol.DeviceID) map[string][]protocol.DeviceID {
+	res := make(map[string][]protocol.DeviceID, len(announced))
+	for peer, devices := range announced {
+		res[peer] = slices.Clone(devices)
+	}
+	return res
+}
+
+// readOnlyDevices returns the devices whose changes are refused in the
+// folder, by its configuration or as announced by the trusted devices
+// sharing it.
+func (m *model) readOnlyDevices(cfg config.FolderConfiguration) map[protocol.DeviceID]struct{} {
+	readOnly := make(map[protocol.DeviceID]struct{})
+	trusted := make(map[string]struct{})
+	for _, dev := range cfg.Devices {
+		if dev.ReadOnly {
+			readOnly[dev.DeviceID] = struct{}{}
+		} else if dev.EncryptionPassword == "" {
+			trusted[dev.DeviceID.String()] = struct{}{}
+		}
+	}
+
+	for peer, devices := range m.announcedReadOnlyDevices(cfg.ID) {
+		if _, ok := trusted[peer]; !ok {
+			continue
+		}
+		for _, dev := range devices {
+			readOnly[dev] = struct{}{}
+		}
+	}
+
+	delete(readOnly, m.id)
+	return readOnly
+}
+
+// refuseReadOnlyChanges marks the changes made by read-only devices in the
+// index update from the device as invalid, surfacing them as a security
+// event.
+func (m *model) refuseReadOnlyChanges(folder string, device protocol.DeviceID, fs []protocol.FileInfo) {
+	cfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return
+	}
+	readOnly := m.readOnlyDevices(cfg)
+	if len(readOnly) == 0 {
+		return
+	}
+	_, fromReadOnly := readOnly[device]
+	modifiers := make(map[protocol.ShortID]protocol.DeviceID, len(readOnly))
+	for dev := range readOnly {
+		modifiers[dev.Short()] = dev
+	}
+
+	var suspect []int
+	var names []string
+	for i, f := range fs {
+		if f.IsInvalid() {
+			continue
+		}
+		if _, ok := modifiers[f.ModifiedBy]; !ok && !fromReadOnly {
+			continue
+		}
+		suspect = append(suspect, i)
+		names = append(names, f.Name)
+	}
+	if len(suspect) == 0 {
+		return
+	}
+
+	// Look up the global versions of all the changes at once, rather than
+	// one at a time, as a read-only device may send many of them.
+	globals := make(map[string]protocol.FileInfo, len(names))
+	for global, err := range itererr.Zip(m.sdb.AllGlobalFilesNamed(folder, names)) {
+		if err != nil {
+			// Refuse all of them, rather than accept changes we couldn't
+			// check.
+			clear(globals)
+			break
+		}
+		globals[global.Name] = global
+	}
+
+	refused := 0
+	var example string
+	for _, i := range suspect {
+		f := fs[i]
+		if global, ok := globals[f.Name]; ok && matchesGlobal(f, global) {
+			continue
+		}
+		fs[i].LocalFlags |= protocol.FlagLocalRemoteInvalid
+		if refused == 0 {
+			example = f.Name
+		}
+		refused++
+	}
+	if refused == 0 {
+		return
+	}
+
+	slog.Warn("Refused changes made by read-only device", cfg.LogAttr(), device.LogAttr(), slog.Int("files", refused), slog.String("file", example))
+	events.LogSecurity(m.evLogger, events.SecurityReadOnlyViolation, map[string]string{
+		"folder": cfg.ID,
+		"label":  cfg.Label,
+		"device": device.String(),
+		"files":  strconv.Itoa(refused),
+		"file":   example,
+	})
+}
+
+// matchesGlobal returns true if the file is the global version of it, or
+// older.
+func matchesGlobal(f, global protocol.FileInfo) bool {
+	switch f.Version.Compare(global.Version) {
+	case protocol.Lesser:
+		return true
+	case protocol.Equal:
+		return f.IsDeleted() == global.IsDeleted() && f.Size == global.Size &&
+			bytes.Equal(protocol.BlocksHash(f.Blocks), protocol.BlocksHash(global.Blocks))
+	default:
+		return false
+	}
+}
+
+func (m *model) forgetReadOnlyDevices(folder string) {
+	c := m.readOnlyAnnouncements
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	delete(c.folders, folder)
+
+	kv := m.readOnlyAnnounced(folder)
+	it, errFn := kv.PrefixBytes("")
+	var peers []string
+	for entry := range it {
+		peers = append(peers, entry.Key)
+	}
+	if errFn() != nil {
+		return
+	}
+	for _, peer := range peers {
+		_ = kv.Delete(peer)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestReadOnlyShare(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	for i := range fcfg.Devices {
+		fcfg.Devices[i].ReadOnly = fcfg.Devices[i].DeviceID == device1
+	}
+	setFolder(t, w, fcfg)
+	m, fc := setupModelWithConnectionFromWrapper(t, w)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	sub := m.evLogger.Subscribe(events.SecurityEvent)
+	defer sub.Unsubscribe()
+
+	fc.addFile("a", 0o644, protocol.FileInfoTypeFile, []byte("a"))
+	fc.sendIndexUpdate()
+
+	select {
+	case ev := <-sub.C():
+		data := ev.Data.(map[string]string)
+		if data["kind"] != events.SecurityReadOnlyViolation || data["device"] != device1.String() || data["file"] != "a" {
+			t.Errorf("unexpected security event %v", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for security event")
+	}
+
+	// The file is known, but only as invalid, so it's never pulled.
+	for i := 0; ; i++ {
+		f, ok, err := m.sdb.GetGlobalFile(fcfg.ID, "a")
+		must(t, err)
+		if ok {
+			if !f.IsInvalid() {
+				t.Error("change by read-only device became global")
+			}
+			break
+		}
+		if i == 100 {
+			t.Fatal("timed out waiting for index update")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestReadOnlyDevicesAnnounced(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device2})
+	setFolder(t, w, fcfg)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	m.recordReadOnlyDevices(fcfg.ID, device2, []protocol.Device{{ID: device1, ReadOnly: true}, {ID: myID, ReadOnly: true}})
+	readOnly := m.readOnlyDevices(fcfg)
+	if _, ok := readOnly[device1]; !ok || len(readOnly) != 1 {
+		t.Errorf("expected device1 to be read-only, got %v", readOnly)
+	}
+
+	// A peer that isn't sharing the folder with us isn't trusted.
+	fcfg.Devices = fcfg.Devices[:len(fcfg.Devices)-1]
+	if readOnly := m.readOnlyDevices(fcfg); len(readOnly) != 0 {
+		t.Errorf("expected no read-only devices, got %v", readOnly)
+	}
+
+	// What was announced is kept in the database.
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device2})
+	m.readOnlyAnnouncements = newReadOnlyAnnouncements()
+	if _, ok := m.readOnlyDevices(fcfg)[device1]; !ok {
+		t.Error("expected device1 to be read-only after reloading")
+	}
+	fcfg.Devices = fcfg.Devices[:len(fcfg.Devices)-1]
+
+	m.recordReadOnlyDevices(fcfg.ID, device2, nil)
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device2})
+	if readOnly := m.readOnlyDevices(fcfg); len(readOnly) != 0 {
+		t.Errorf("expected no read-only devices, got %v", readOnly)
+	}
+}
+
+func TestReadOnlyMatchesGlobal(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	for i := range fcfg.Devices {
+		fcfg.Devices[i].ReadOnly = fcfg.Devices[i].DeviceID == device1
+	}
+	setFolder(t, w, fcfg)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	var globals []protocol.FileInfo
+	for _, name := range []string{"a", "b"} {
+		blocks := []protocol.BlockInfo{{Size: 3, Hash: []byte("hash of abc")}}
+		globals = append(globals, protocol.FileInfo{
+			Name:       name,
+			Size:       3,
+			Version:    protocol.Vector{}.Update(myID.Short()),
+			Blocks:     blocks,
+			BlocksHash: protocol.BlocksHash(blocks),
+			Sequence:   int64(len(globals) + 1),
+		})
+	}
+	must(t, m.sdb.Update(fcfg.ID, protocol.LocalDeviceID, globals))
+
+	// Claiming the hash of the global blocks doesn't make other blocks
+	// match, and files we don't have don't match either.
+	changed := globals[1]
+	changed.Blocks = []protocol.BlockInfo{{Size: 3, Hash: []byte("hash of xyz")}}
+	missing := globals[0]
+	missing.Name = "c"
+	fs := []protocol.FileInfo{globals[0], changed, missing}
+	m.refuseReadOnlyChanges(fcfg.ID, device1, fs)
+
+	if fs[0].IsInvalid() {
+		t.Error("expected the global version to match")
+	}
+	if !fs[1].IsInvalid() {
+		t.Error("expected different blocks not to match")
+	}
+	if !fs[2].IsInvalid() {
+		t.Error("expected a file without global version not to match")
+	}
+}
+
+func TestReadOnlyDevicesConcurrent(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device2})
+	setFolder(t, w, fcfg)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	// Run with the race detector; the announcements are updated while
+	// they're being used.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 100 {
+			m.recordReadOnlyDevices(fcfg.ID, device2, []protocol.Device{{ID: device1, ReadOnly: i%2 == 0}})
+		}
+	}()
+	for range 100 {
+		m.readOnlyDevices(fcfg)
+	}
+	<-done
+}
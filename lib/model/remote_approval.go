@@ -0,0 +1,205 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// A folder may require approval of large remote changes. The changes
+// received from other devices are counted over a window, and once they
+// exceed the folder's limits the folder stops pulling. What we receive
+// from then on is held as well, until the changes are approved. The index
+// data itself is stored as usual, as it's what the other devices have;
+// only applying it to our files waits.
+
+const remoteApprovalWindow = 5 * time.Minute
+
+var errApprovalPending = errors.New("remote changes are waiting for approval")
+
+// PendingApproval describes the remote changes held back in a folder.
+type PendingApproval struct {
+	Since        time.Time           `json:"since"`
+	Devices      []protocol.DeviceID `json:"devices"`
+	Files        int                 `json:"files"`
+	DeletedBytes int64               `json:"deletedBytes"`
+}
+
+func (p *PendingApproval) add(device protocol.DeviceID, files int, deletedBytes int64) {
+	if !slices.Contains(p.Devices, device) {
+		p.Devices = append(p.Devices, device)
+	}
+	p.Files += files
+	p.DeletedBytes += deletedBytes
+}
+
+// remoteChanges counts the remote changes per folder over the window.
+type remoteChanges struct {
+	mut     sync.Mutex
+	folders map[string]*PendingApproval
+}
+
+func newRemoteChanges() *remoteChanges {
+	return &remoteChanges{folders: make(map[string]*PendingApproval)}
+}
+
+// add counts the changes, returning those within the window.
+func (c *remoteChanges) add(now time.Time, folder string, device protocol.DeviceID, files int, deletedBytes int64) PendingApproval {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	changes, ok := c.folders[folder]
+	if !ok || now.Sub(changes.Since) > remoteApprovalWindow {
+		changes = &PendingApproval{Since: now}
+		c.folders[folder] = changes
+	}
+	changes.add(device, files, deletedBytes)
+	res := *changes
+	res.Devices = slices.Clone(changes.Devices)
+	return res
+}
+
+func (c *remoteChanges) reset(folder string) {
+	c.mut.Lock()
+	delete(c.folders, folder)
+	c.mut.Unlock()
+}
+
+func (m *model) pendingApprovals() *db.Typed {
+	return db.NewTyped(m.sdb, "pendingapproval/")
+}
+
+// PendingRemoteChanges returns the remote changes waiting for approval in
+// the folder, if any.
+func (m *model) PendingRemoteChanges(folder string) (PendingApproval, bool, error) {
+	if _, ok := m.cfg.Folder(folder); !ok {
+		return PendingApproval{}, false, ErrFolderMissing
+	}
+	return m.pendingRemoteChanges(folder)
+}
+
+func (m *model) pendingRemoteChanges(folder string) (PendingApproval, bool, error) {
+	bs, ok, err := m.pendingApprovals().Bytes(folder)
+	if err != nil || !ok {
+		return PendingApproval{}, false, err
+	}
+	var pending PendingApproval
+	if err := json.Unmarshal(bs, &pending); err != nil {
+		return PendingApproval{}, false, err
+	}
+	return pending, true, nil
+}
+
+func (m *model) setPendingRemoteChanges(folder string, pending PendingApproval) error {
+	bs, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	return m.pendingApprovals().PutBytes(folder, bs)
+}
+
+// ApproveRemoteChanges lets the folder pull the remote changes that were
+// waiting for approval.
+func (m *model) ApproveRemoteChanges(folder string) error {
+	cfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return ErrFolderMissing
+	}
+	if err := m.pendingApprovals().Delete(folder); err != nil {
+		return err
+	}
+	m.remoteChanges.reset(folder)
+	slog.Info("Remote changes approved", cfg.LogAttr())
+
+	m.mut.RLock()
+	runner, ok := m.folderRunners.Get(folder)
+	m.mut.RUnlock()
+	if ok {
+		runner.SchedulePull()
+	}
+	return nil
+}
+
+// checkRemoteApproval counts the changes in an index update from the
+// device, holding them back if they exceed the limits of the folder or if
+// changes are held already.
+func (m *model) checkRemoteApproval(folder string, device protocol.DeviceID, fs []protocol.FileInfo) {
+	cfg, ok := m.cfg.Folder(folder)
+	if !ok || !cfg.RemoteApproval.Enabled() || cfg.Type == config.FolderTypeSendOnly {
+		return
+	}
+
+	var deletedBytes int64
+	if cfg.RemoteApproval.MaxDeletePct > 0 {
+		for _, f := range fs {
+			if !f.IsDeleted() || f.IsInvalid() {
+				continue
+			}
+			if local, ok, err := m.sdb.GetDeviceFile(folder, protocol.LocalDeviceID, f.Name); err == nil && ok && !local.IsDeleted() {
+				deletedBytes += local.Size
+			}
+		}
+	}
+
+	pending, held, err := m.pendingRemoteChanges(folder)
+	if err != nil {
+		slog.Warn("Failed to check remote changes waiting for approval", cfg.LogAttr(), slogutil.Error(err))
+		return
+	}
+	if held {
+		pending.add(device, len(fs), deletedBytes)
+		if err := m.setPendingRemoteChanges(folder, pending); err != nil {
+			slog.Warn("Failed to store remote changes waiting for approval", cfg.LogAttr(), slogutil.Error(err))
+		}
+		return
+	}
+
+	changes := m.remoteChanges.add(time.Now(), folder, device, len(fs), deletedBytes)
+	if !m.needsApproval(cfg, changes) {
+		return
+	}
+	if err := m.setPendingRemoteChanges(folder, changes); err != nil {
+		slog.Warn("Failed to store remote changes waiting for approval", cfg.LogAttr(), slogutil.Error(err))
+		return
+	}
+	slog.Warn("Holding back large remote changes until approved", cfg.LogAttr(), slog.Int("files", changes.Files), slog.Int64("deletedBytes", changes.DeletedBytes))
+	m.evLogger.Log(events.RemoteChangesPending, map[string]interface{}{
+		"folder":       cfg.ID,
+		"label":        cfg.Label,
+		"devices":      changes.Devices,
+		"files":        changes.Files,
+		"deletedBytes": changes.DeletedBytes,
+	})
+}
+
+func (m *model) needsApproval(cfg config.FolderConfiguration, changes PendingApproval) bool {
+	if limit := cfg.RemoteApproval.MaxFiles; limit > 0 && changes.Files > limit {
+		return true
+	}
+	if pct := cfg.RemoteApproval.MaxDeletePct; pct > 0 && changes.DeletedBytes > 0 {
+		local, err := m.sdb.CountLocal(cfg.ID, protocol.LocalDeviceID)
+		return err == nil && changes.DeletedBytes*100 > local.Bytes*int64(pct)
+	}
+	return false
+}
+
+// remoteChangesPending returns true if the folder must not pull as there
+// are changes waiting for approval.
+func (m *model) remoteChangesPending(folder string) bool {
+	_, held, err := m.pendingRemoteChanges(folder)
+	return err == nil && held
+}
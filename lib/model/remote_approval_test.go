@@ -0,0 +1,64 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestRemoteApproval(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	fcfg.RemoteApproval = config.RemoteApproval{MaxFiles: 2}
+	setFolder(t, w, fcfg)
+	m, fc := setupModelWithConnectionFromWrapper(t, w)
+	tfs := fcfg.Filesystem()
+	defer cleanupModelAndRemoveDir(m, tfs.URI())
+
+	synced := make(chan struct{}, 1)
+	fc.setIndexFn(func(_ context.Context, _ string, fs []protocol.FileInfo) error {
+		for _, f := range fs {
+			if f.Name == "c" {
+				synced <- struct{}{}
+			}
+		}
+		return nil
+	})
+
+	for _, name := range []string{"a", "b", "c"} {
+		fc.addFile(name, 0o644, protocol.FileInfoTypeFile, []byte(name))
+	}
+	fc.sendIndexUpdate()
+
+	pending, ok, err := m.PendingRemoteChanges(fcfg.ID)
+	must(t, err)
+	if !ok || pending.Files != 3 || len(pending.Devices) != 1 || pending.Devices[0] != device1 {
+		t.Fatalf("expected changes to be waiting for approval, got %v %+v", ok, pending)
+	}
+	select {
+	case <-synced:
+		t.Fatal("pulled changes waiting for approval")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, err := tfs.Lstat("c"); err == nil {
+		t.Fatal("file waiting for approval exists")
+	}
+
+	must(t, m.ApproveRemoteChanges(fcfg.ID))
+	if _, ok, _ := m.PendingRemoteChanges(fcfg.ID); ok {
+		t.Error("expected no changes waiting for approval")
+	}
+	select {
+	case <-synced:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for approved changes")
+	}
+}
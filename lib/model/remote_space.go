@@ -0,0 +1,216 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Devices announce in their cluster configs how much disk space they can
+// still use for each folder, so that we can tell in advance when a device
+// is about to run out of space, and optionally stop sending it data.
+
+const (
+	// How often we check our own free space, to announce it again if it
+	// changed, and what the devices we share with have announced.
+	spaceCheckInterval = 5 * time.Minute
+	// Announced space changes smaller than this fraction are not worth a
+	// new cluster config.
+	spaceAnnounceThreshold = 0.01
+	// We warn about devices expected to run out of space within this
+	// time.
+	spaceWarningHorizon = 7 * 24 * time.Hour
+)
+
+// folderAvailableBytes returns the disk space we can still use for the
+// folder, above its minimum free space, in the form we announce it: zero
+// if unknown and negative if we're out of space.
+func folderAvailableBytes(fcfg config.FolderConfiguration) int64 {
+	if fcfg.Type == config.FolderTypeSendOnly {
+		return 0
+	}
+	usage, err := fcfg.Filesystem().Usage(".")
+	if err != nil || usage.Total == 0 {
+		return 0
+	}
+	reserve := fcfg.MinDiskFree.BaseValue()
+	if fcfg.MinDiskFree.Percentage() {
+		reserve = float64(usage.Total) * reserve / 100
+	}
+	available := int64(float64(usage.Free) - reserve)
+	if available <= 0 {
+		return -1
+	}
+	return available
+}
+
+// spaceTracker keeps the space we last announced for our folders, and what
+// devices announced for theirs.
+type spaceTracker struct {
+	mut    sync.Mutex
+	local  map[string]int64
+	remote map[protocol.DeviceID]map[string]remoteSpace
+}
+
+type remoteSpace struct {
+	available int64
+	at        time.Time
+	// The rate at which the available space shrinks, in bytes per
+	// second, averaged over announcements. Negative when it grows.
+	rate   float64
+	full   bool // as of the last check
+	warned bool
+}
+
+func newSpaceTracker() *spaceTracker {
+	return &spaceTracker{
+		local:  make(map[string]int64),
+		remote: make(map[protocol.DeviceID]map[string]remoteSpace),
+	}
+}
+
+// localAvailable returns what we last announced for the folder.
+func (s *spaceTracker) localAvailable(folder string) int64 {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.local[folder]
+}
+
+// setLocal records the space available for the folder, returning true if
+// it changed enough to be announced again.
+func (s *spaceTracker) setLocal(folder string, available int64) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	prev, ok := s.local[folder]
+	s.local[folder] = available
+	if !ok || prev <= 0 || available <= 0 {
+		return prev != available
+	}
+	return math.Abs(float64(available-prev)) > spaceAnnounceThreshold*float64(prev)
+}
+
+// announced records the space a device announced for a folder.
+func (s *spaceTracker) announced(device protocol.DeviceID, folder string, available int64, now time.Time) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	folders, ok := s.remote[device]
+	if !ok {
+		folders = make(map[string]remoteSpace)
+		s.remote[device] = folders
+	}
+	if available == 0 {
+		delete(folders, folder)
+		return
+	}
+	rs := folders[folder]
+	if !rs.at.IsZero() && rs.available > 0 && available > 0 {
+		if dt := now.Sub(rs.at).Seconds(); dt > 0 {
+			rate := float64(rs.available-available) / dt
+			if rs.rate == 0 {
+				rs.rate = rate
+			} else {
+				rs.rate = 0.7*rs.rate + 0.3*rate
+			}
+		}
+	}
+	rs.available = available
+	rs.at = now
+	folders[folder] = rs
+}
+
+func (s *spaceTracker) get(device protocol.DeviceID, folder string) (remoteSpace, bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	rs, ok := s.remote[device][folder]
+	return rs, ok
+}
+
+// update sets the outcome of a check, returning true if the device newly
+// needs a warning.
+func (s *spaceTracker) update(device protocol.DeviceID, folder string, full, warn bool) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	rs, ok := s.remote[device][folder]
+	if !ok {
+		return false
+	}
+	newWarning := warn && !rs.warned
+	rs.full = full
+	rs.warned = warn
+	s.remote[device][folder] = rs
+	return newWarning
+}
+
+// full returns true if the device was out of space for the folder as of
+// the last check.
+func (s *spaceTracker) full(device protocol.DeviceID, folder string) bool {
+	rs, _ := s.get(device, folder)
+	return rs.full
+}
+
+// timeUntilFull returns the estimated time until the device runs out of
+// space for the folder, zero if it already has, and false if it isn't
+// expected to or hasn't told us.
+func (m *model) timeUntilFull(device protocol.DeviceID, folder string) (time.Duration, bool) {
+	rs, ok := m.space.get(device, folder)
+	if !ok {
+		return 0, false
+	}
+	if rs.available < 0 {
+		return 0, true
+	}
+	if need, err := m.sdb.CountNeed(folder, device); err == nil && need.Bytes > rs.available {
+		return 0, true
+	}
+	if rs.rate <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(rs.available) / rs.rate * float64(time.Second)), true
+}
+
+// checkRemoteSpace warns, once, when the device is out of space for the
+// folder or is expected to be soon.
+func (m *model) checkRemoteSpace(device protocol.DeviceID, fcfg config.FolderConfiguration) {
+	left, ok := m.timeUntilFull(device, fcfg.ID)
+	full := ok && left == 0
+	warn := ok && left <= spaceWarningHorizon
+	if !m.space.update(device, fcfg.ID, full, warn) {
+		return
+	}
+	if left == 0 {
+		slog.Warn("Device does not have enough disk space for the folder", fcfg.LogAttr(), device.LogAttr(), slog.Bool("sendingPaused", fcfg.PauseSendingToFullDevices))
+	} else {
+		slog.Warn("Device will run out of disk space for the folder", fcfg.LogAttr(), device.LogAttr(), slog.Duration("in", left.Round(time.Hour)))
+	}
+}
+
+// checkSpace announces our available space anew where it changed, and
+// checks that of the devices we share with.
+func (m *model) checkSpace() {
+	announce := make(deviceIDSet)
+	for _, fcfg := range m.cfg.Folders() {
+		if fcfg.Paused {
+			continue
+		}
+		if m.space.setLocal(fcfg.ID, folderAvailableBytes(fcfg)) {
+			announce.add(fcfg.DeviceIDs())
+		}
+		for _, dev := range fcfg.Devices {
+			if dev.DeviceID != m.id {
+				m.checkRemoteSpace(dev.DeviceID, fcfg)
+			}
+		}
+	}
+	delete(announce, m.id)
+	m.sendClusterConfig(announce.AsSlice())
+}
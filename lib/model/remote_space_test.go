@@ -0,0 +1,87 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpaceTrackerSetLocal(t *testing.T) {
+	s := newSpaceTracker()
+	if !s.setLocal("f", 1000) {
+		t.Error("first value should be announced")
+	}
+	if s.setLocal("f", 995) {
+		t.Error("small change should not be announced")
+	}
+	if !s.setLocal("f", 900) {
+		t.Error("large change should be announced")
+	}
+	if !s.setLocal("f", -1) {
+		t.Error("running out should be announced")
+	}
+	if s.setLocal("f", -1) {
+		t.Error("unchanged value should not be announced")
+	}
+	if s.localAvailable("f") != -1 {
+		t.Error("unexpected local value", s.localAvailable("f"))
+	}
+}
+
+func TestSpaceTrackerRate(t *testing.T) {
+	s := newSpaceTracker()
+	now := time.Now()
+	s.announced(device1, "f", 10000, now)
+	s.announced(device1, "f", 9000, now.Add(10*time.Second))
+	rs, ok := s.get(device1, "f")
+	if !ok || rs.available != 9000 || rs.rate != 100 {
+		t.Fatalf("unexpected state %+v", rs)
+	}
+	s.announced(device1, "f", 9000, now.Add(20*time.Second))
+	if rs, _ := s.get(device1, "f"); rs.rate != 70 {
+		t.Errorf("expected averaged rate 70, got %v", rs.rate)
+	}
+
+	// Not announcing forgets what we knew.
+	s.announced(device1, "f", 0, now.Add(30*time.Second))
+	if _, ok := s.get(device1, "f"); ok {
+		t.Error("expected unknown space")
+	}
+}
+
+func TestRemoteSpaceWarning(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	now := time.Now()
+	m.space.announced(device1, fcfg.ID, 1<<30, now.Add(-time.Hour))
+	m.checkRemoteSpace(device1, fcfg)
+	if m.space.full(device1, fcfg.ID) {
+		t.Error("device should not be full")
+	}
+	if rs, _ := m.space.get(device1, fcfg.ID); rs.warned {
+		t.Error("should not warn without a trend")
+	}
+
+	// Shrinking by half a gigabyte an hour gives an hour to go.
+	m.space.announced(device1, fcfg.ID, 1<<29, now)
+	if left, ok := m.timeUntilFull(device1, fcfg.ID); !ok || left != time.Hour {
+		t.Errorf("expected an hour left, got %v, %v", left, ok)
+	}
+	m.checkRemoteSpace(device1, fcfg)
+	if rs, _ := m.space.get(device1, fcfg.ID); !rs.warned || rs.full {
+		t.Errorf("unexpected state %+v", rs)
+	}
+
+	m.space.announced(device1, fcfg.ID, -1, now.Add(time.Hour))
+	m.checkRemoteSpace(device1, fcfg)
+	if !m.space.full(device1, fcfg.ID) {
+		t.Error("device should be full")
+	}
+}
@@ -952,6 +952,16 @@ func TestNeedFolderFiles(t *testing.T) {
 			t.Errorf("Got %v needed items on page %v, expected %v", got, page, exp)
 		}
 	}
+
+	it, errFn := m.NeedFolderFilesIter(fcfg.ID)
+	got := 0
+	for range it {
+		got++
+	}
+	must(t, errFn())
+	if got != num {
+		t.Errorf("Got %v needed items from iterator, expected %v", got, num)
+	}
 }
 
 // TestIgnoreDeleteUnignore checks that the deletion of an ignored file is not
@@ -1231,7 +1241,7 @@ func TestRequestIndexSenderClusterConfigBeforeStart(t *testing.T) {
 		}
 		return nil
 	})
-	fc.ClusterConfigCalls(func(cc *protocol.ClusterConfig, _ map[string]string) {
+	fc.ClusterConfigCalls(func(cc *protocol.ClusterConfig, _ map[string]protocol.EncryptionPassword) {
 		select {
 		case ccChan <- cc:
 		case <-done:
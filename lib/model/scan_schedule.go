@@ -0,0 +1,120 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Periodic scans of different folders are spread out, so that a device
+// with many folders doesn't scan them all at once every interval. Each
+// scan is jittered, then moved later until it is at least a spacing away
+// from the scans due for the other folders. The spacing shrinks with the
+// number of folders, so that all of them fit in a quarter of the interval.
+
+const maxScanStaggerSpacing = time.Minute
+
+// ScheduledScan is the next scan due for a folder.
+type ScheduledScan struct {
+	Folder    string    `json:"folder"`
+	At        time.Time `json:"at"`
+	IntervalS int       `json:"intervalS"`
+	Periodic  bool      `json:"periodic"` // due to the rescan interval, as opposed to a request or the watcher
+}
+
+type scanSchedule struct {
+	mut  sync.Mutex
+	next map[string]ScheduledScan // folder -> next scan
+}
+
+func newScanSchedule() *scanSchedule {
+	return &scanSchedule{next: make(map[string]ScheduledScan)}
+}
+
+// stagger records the next periodic scan of the folder, moved away from
+// the scans of other folders, and returns when it is due.
+func (s *scanSchedule) stagger(folder string, at time.Time, interval time.Duration) time.Time {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.pruneLocked(time.Now())
+	spacing := min(maxScanStaggerSpacing, interval/time.Duration(4*(len(s.next)+1)))
+	for range len(s.next) {
+		moved := false
+		for other, scan := range s.next {
+			if other == folder {
+				continue
+			}
+			if d := at.Sub(scan.At); d > -spacing && d < spacing {
+				at = scan.At.Add(spacing)
+				moved = true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+
+	s.next[folder] = ScheduledScan{Folder: folder, At: at, IntervalS: int(interval / time.Second), Periodic: true}
+	return at
+}
+
+// set records the next scan of the folder, when it is not periodic.
+func (s *scanSchedule) set(folder string, at time.Time, interval time.Duration) {
+	s.mut.Lock()
+	s.next[folder] = ScheduledScan{Folder: folder, At: at, IntervalS: int(interval / time.Second)}
+	s.mut.Unlock()
+}
+
+func (s *scanSchedule) forget(folder string) {
+	s.mut.Lock()
+	delete(s.next, folder)
+	s.mut.Unlock()
+}
+
+// upcoming returns the scans due, soonest first.
+func (s *scanSchedule) upcoming() []ScheduledScan {
+	s.mut.Lock()
+	s.pruneLocked(time.Now())
+	scans := make([]ScheduledScan, 0, len(s.next))
+	for _, scan := range s.next {
+		scans = append(scans, scan)
+	}
+	s.mut.Unlock()
+	slices.SortFunc(scans, func(a, b ScheduledScan) int {
+		if c := a.At.Compare(b.At); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Folder, b.Folder)
+	})
+	return scans
+}
+
+// pruneLocked drops the scans that were due before now, which have been
+// done or are being done, until the folder schedules its next one.
+func (s *scanSchedule) pruneLocked(now time.Time) {
+	for folder, scan := range s.next {
+		if scan.At.Before(now) {
+			delete(s.next, folder)
+		}
+	}
+}
+
+// ScanSchedule returns the next scan due for each running folder, soonest
+// first.
+func (m *model) ScanSchedule() []ScheduledScan {
+	return m.scanSchedule.upcoming()
+}
+
+// setScanTimer schedules the next scan, outside of the periodic ones.
+func (f *folder) setScanTimer(d time.Duration) {
+	f.model.scanSchedule.set(f.ID, time.Now().Add(d), f.scanInterval)
+	f.scanTimer.Reset(d)
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestScanScheduleStagger(t *testing.T) {
+	s := newScanSchedule()
+	now := time.Now().Add(time.Minute)
+
+	// Folders all wanting to scan at the same time are spread out, at
+	// most a minute apart.
+	const folders = 10
+	for i := range folders {
+		s.stagger(fmt.Sprint(i), now, time.Hour)
+	}
+	scans := s.upcoming()
+	if len(scans) != folders {
+		t.Fatalf("expected %d scans, got %d", folders, len(scans))
+	}
+	for i := 1; i < len(scans); i++ {
+		d := scans[i].At.Sub(scans[i-1].At)
+		if d <= 0 || d > maxScanStaggerSpacing {
+			t.Errorf("scans %s and %s are %v apart", scans[i-1].Folder, scans[i].Folder, d)
+		}
+	}
+	if last := scans[len(scans)-1].At; last.Sub(now) > 15*time.Minute {
+		t.Errorf("expected the scans to fit in a quarter of the interval, last at %v", last.Sub(now))
+	}
+
+	// Rescheduling a folder doesn't move it away from itself.
+	at := s.stagger("0", scans[0].At, time.Hour)
+	if !at.Equal(scans[0].At) {
+		t.Errorf("expected the scan to stay at %v, got %v", scans[0].At, at)
+	}
+
+	// Scans far enough apart stay as they are.
+	later := now.Add(30 * time.Minute)
+	if at := s.stagger("other", later, time.Hour); !at.Equal(later) {
+		t.Errorf("expected the scan to stay at %v, got %v", later, at)
+	}
+
+	s.forget("other")
+	if len(s.upcoming()) != folders {
+		t.Error("expected the forgotten folder to be gone")
+	}
+}
+
+func TestScanSchedulePrune(t *testing.T) {
+	s := newScanSchedule()
+	now := time.Now()
+
+	// Scans that were due are dropped when the schedule is looked at, and
+	// don't push later ones away.
+	s.set("past", now.Add(-time.Minute), time.Hour)
+	s.set("future", now.Add(time.Hour), time.Hour)
+	scans := s.upcoming()
+	if len(scans) != 1 || scans[0].Folder != "future" {
+		t.Fatalf("expected only the future scan, got %v", scans)
+	}
+
+	s.set("past", now.Add(-time.Minute), time.Hour)
+	at := now.Add(time.Minute)
+	if got := s.stagger("other", at, time.Hour); !got.Equal(at) {
+		t.Errorf("expected the scan to stay at %v, got %v", at, got)
+	}
+	if len(s.next) != 2 {
+		t.Errorf("expected the past scan to be pruned, got %v", s.next)
+	}
+}
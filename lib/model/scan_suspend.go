@@ -0,0 +1,56 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+)
+
+// SetScansSuspended suspends the scans triggered by the rescan interval
+// and the filesystem watcher for all folders, e.g. while a mobile app is in
+// the background. Explicitly requested scans still happen. On resuming,
+// the scans that were due in the meantime are done.
+func (m *model) SetScansSuspended(suspended bool) {
+	if m.scansSuspended.Swap(suspended) == suspended || suspended || m.globalPaused.Load() {
+		return
+	}
+	m.notifyScansResumed()
+}
+
+// notifyScansResumed lets the folders do the scans that were postponed.
+func (m *model) notifyScansResumed() {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	_ = m.folderRunners.Each(func(_ string, runner service) error {
+		runner.scansResumed()
+		return nil
+	})
+}
+
+func (f *folder) scansResumed() {
+	select {
+	case f.scansResumedChan <- struct{}{}:
+	default:
+	}
+}
+
+// resumeScans does the scans that were due while scans were suspended:
+// A full one if the rescan interval passed, otherwise only the changes the
+// watcher saw.
+func (f *folder) resumeScans(ctx context.Context) error {
+	full, subs := f.suspendedFullScan, f.suspendedSubs
+	f.suspendedFullScan, f.suspendedSubs = false, nil
+	if full {
+		f.setScanTimer(0)
+		return nil
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+	f.sl.DebugContext(ctx, "Scanning changes seen while scans were suspended")
+	return f.scanSubdirs(ctx, subs)
+}
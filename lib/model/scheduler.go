@@ -0,0 +1,59 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/semaphore"
+)
+
+// The scheduler shares the device-wide capacity for I/O heavy operations
+// (scans and pulls) and for hashing among the folders, letting folders with
+// a higher priority go first when there is contention.
+type scheduler struct {
+	io   *semaphore.PrioritySemaphore
+	hash *semaphore.PrioritySemaphore
+}
+
+func newScheduler(opts config.OptionsConfiguration) *scheduler {
+	return &scheduler{
+		io:   semaphore.NewPriority(opts.MaxFolderConcurrency()),
+		hash: semaphore.NewPriority(opts.MaxConcurrentHashers()),
+	}
+}
+
+func (s *scheduler) setOptions(opts config.OptionsConfiguration) {
+	s.io.SetCapacity(opts.MaxFolderConcurrency())
+	s.hash.SetCapacity(opts.MaxConcurrentHashers())
+}
+
+// ioLimiter returns the limiter for scans and pulls of a folder with the
+// given priority.
+func (s *scheduler) ioLimiter(priority int) *priorityLimiter {
+	return &priorityLimiter{sem: s.io, priority: priority}
+}
+
+// hashLimiter returns the limiter for files being hashed in a folder with
+// the given priority.
+func (s *scheduler) hashLimiter(priority int) *priorityLimiter {
+	return &priorityLimiter{sem: s.hash, priority: priority}
+}
+
+type priorityLimiter struct {
+	sem      *semaphore.PrioritySemaphore
+	priority int
+}
+
+func (l *priorityLimiter) TakeWithContext(ctx context.Context, size int) error {
+	return l.sem.TakeWithContext(ctx, l.priority, size)
+}
+
+func (l *priorityLimiter) Give(size int) {
+	l.sem.Give(size)
+}
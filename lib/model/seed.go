@@ -0,0 +1,185 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"maps"
+	"sync"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/scanner"
+)
+
+// seedIndex finds blocks by hash in the files of a seed directory. The
+// directory is hashed in the background after each full scan of the folder,
+// each file with the block size a file of its size usually has, so that it
+// holds the blocks of files it has a copy of. Files that were given another
+// block size (see scanner.BlockSizeProfiles) are only found once the seed
+// has been hashed again with that one too. Until the first hashing
+// completes nothing is found. Blocks are keyed by a prefix of their hash
+// only; as copied blocks are verified, a collision merely misses the block.
+type seedIndex struct {
+	fs      fs.Filesystem
+	refresh chan struct{}
+
+	mut        sync.RWMutex
+	names      []string
+	blocks     map[seedKey]seedBlock
+	blockSizes map[int]struct{} // wanted besides the usual ones
+}
+
+type seedKey struct {
+	blockSize int
+	hash      uint64 // prefix of the block hash
+}
+
+type seedBlock struct {
+	name   int // index into names
+	offset int64
+}
+
+func newSeedIndex(path string) *seedIndex {
+	return &seedIndex{
+		fs:         fs.NewFilesystem(fs.FilesystemTypeBasic, path),
+		refresh:    make(chan struct{}, 1),
+		blockSizes: make(map[int]struct{}),
+	}
+}
+
+// serve hashes the seed each time it's invalidated, until the context is
+// cancelled.
+func (s *seedIndex) serve(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.refresh:
+		}
+		s.build(ctx)
+	}
+}
+
+// invalidate makes the seed be hashed again. The current index is used
+// until that's done.
+func (s *seedIndex) invalidate() {
+	select {
+	case s.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// lookup returns the file and offset in the seed of a block of file with
+// the given hash, if there is one.
+func (s *seedIndex) lookup(hash []byte, file protocol.FileInfo) (string, int64, bool) {
+	if len(hash) < 8 {
+		return "", 0, false
+	}
+	blockSize := file.BlockSize()
+
+	s.mut.RLock()
+	b, ok := s.blocks[seedKey{blockSize, binary.BigEndian.Uint64(hash)}]
+	_, wanted := s.blockSizes[blockSize]
+	var name string
+	if ok {
+		name = s.names[b.name]
+	}
+	s.mut.RUnlock()
+
+	if !ok && !wanted && blockSize != protocol.BlockSize(file.Size) {
+		s.mut.Lock()
+		s.blockSizes[blockSize] = struct{}{}
+		s.mut.Unlock()
+		s.invalidate()
+	}
+	return name, b.offset, ok
+}
+
+// build hashes the seed and replaces the index with the result. A build
+// that's cut short leaves the index as it was.
+func (s *seedIndex) build(ctx context.Context) {
+	var names []string
+	blocks := make(map[seedKey]seedBlock)
+	s.mut.RLock()
+	blockSizes := maps.Clone(s.blockSizes)
+	s.mut.RUnlock()
+	l := slog.With(slog.String("path", s.fs.URI()))
+	l.Info("Hashing seed directory")
+
+	err := s.fs.Walk(".", func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if fs.IsInternal(path) {
+			return fs.SkipDir
+		}
+		if !info.IsRegular() || info.Size() == 0 {
+			return nil
+		}
+		if s.add(ctx, blocks, blockSizes, len(names), path, info.Size()) {
+			names = append(names, path)
+		}
+		return nil
+	})
+	if ctx.Err() != nil {
+		// The folder is stopping; it's hashed again once it runs.
+		return
+	}
+	if err != nil {
+		l.Warn("Failed to hash seed directory", slogutil.Error(err))
+		return
+	}
+
+	s.mut.Lock()
+	s.names, s.blocks = names, blocks
+	s.mut.Unlock()
+	l.Info("Hashed seed directory", slog.Int("blocks", len(blocks)))
+}
+
+// add hashes the named file into blocks as names[idx], with the usual
+// block size and those of the wanted ones a file of its size may have
+// instead, returning whether it could be read.
+func (s *seedIndex) add(ctx context.Context, blocks map[seedKey]seedBlock, blockSizes map[int]struct{}, idx int, name string, size int64) bool {
+	fd, err := s.fs.Open(name)
+	if err != nil {
+		return false
+	}
+	defer fd.Close()
+
+	usual := protocol.BlockSize(size)
+	sizes := []int{usual}
+	for bs := range blockSizes {
+		// Retained and profiled block sizes are at most a tier off.
+		if bs != usual && bs >= usual/2 && bs <= usual*2 {
+			sizes = append(sizes, bs)
+		}
+	}
+	for _, blockSize := range sizes {
+		if _, err := fd.Seek(0, io.SeekStart); err != nil {
+			return false
+		}
+		hashed, err := scanner.Blocks(ctx, fd, blockSize, size, nil)
+		if err != nil {
+			return false
+		}
+		for _, b := range hashed {
+			key := seedKey{blockSize, binary.BigEndian.Uint64(b.Hash)}
+			if _, ok := blocks[key]; !ok {
+				blocks[key] = seedBlock{name: idx, offset: b.Offset}
+			}
+		}
+	}
+	return true
+}
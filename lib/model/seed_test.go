@@ -0,0 +1,107 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestSeedIndex(t *testing.T) {
+	dir := t.TempDir()
+	seedFs := fs.NewFilesystem(fs.FilesystemTypeBasic, dir)
+	data := append(bytes.Repeat([]byte("a"), protocol.MinBlockSize), bytes.Repeat([]byte("b"), protocol.MinBlockSize)...)
+	must(t, seedFs.MkdirAll("sub", 0o755))
+	writeFile(t, seedFs, "sub/file", data)
+
+	s := newSeedIndex(dir)
+	file := protocol.FileInfo{Size: int64(len(data)), RawBlockSize: protocol.MinBlockSize}
+	second := sha256.Sum256(data[protocol.MinBlockSize:])
+	if _, _, ok := s.lookup(second[:], file); ok {
+		t.Error("unexpected block found before hashing")
+	}
+
+	// A cancelled build leaves the index as it was.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.build(ctx)
+	if _, _, ok := s.lookup(second[:], file); ok {
+		t.Error("unexpected block found after cancelled hashing")
+	}
+
+	s.build(context.Background())
+	name, offset, ok := s.lookup(second[:], file)
+	if !ok || name != filepath.Join("sub", "file") || offset != protocol.MinBlockSize {
+		t.Errorf("unexpected lookup result %q %d %v", name, offset, ok)
+	}
+
+	missing := sha256.Sum256([]byte("missing"))
+	if _, _, ok := s.lookup(missing[:], file); ok {
+		t.Error("unexpected block found")
+	}
+
+	// A file with larger blocks than usual is found once the seed has been
+	// hashed with those too.
+	file.RawBlockSize = 2 * protocol.MinBlockSize
+	whole := sha256.Sum256(data)
+	if _, _, ok := s.lookup(whole[:], file); ok {
+		t.Error("unexpected block found before hashing with its block size")
+	}
+	s.build(context.Background())
+	name, offset, ok = s.lookup(whole[:], file)
+	if !ok || name != filepath.Join("sub", "file") || offset != 0 {
+		t.Errorf("unexpected lookup result %q %d %v", name, offset, ok)
+	}
+	file.RawBlockSize = protocol.MinBlockSize
+	if _, _, ok := s.lookup(second[:], file); !ok {
+		t.Error("expected block with the usual block size to be found still")
+	}
+}
+
+func TestSeedIndexInvalidate(t *testing.T) {
+	dir := t.TempDir()
+	seedFs := fs.NewFilesystem(fs.FilesystemTypeBasic, dir)
+	data := bytes.Repeat([]byte("a"), protocol.MinBlockSize)
+	writeFile(t, seedFs, "file", data)
+	hash := sha256.Sum256(data)
+	file := protocol.FileInfo{Size: int64(len(data)), RawBlockSize: protocol.MinBlockSize}
+
+	s := newSeedIndex(dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.serve(ctx)
+
+	s.invalidate()
+	for i := 0; ; i++ {
+		if _, _, ok := s.lookup(hash[:], file); ok {
+			break
+		}
+		if i == 100 {
+			t.Fatal("seed not hashed after invalidation")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	must(t, seedFs.Remove("file"))
+	s.invalidate()
+	for i := 0; ; i++ {
+		if _, _, ok := s.lookup(hash[:], file); !ok {
+			break
+		}
+		if i == 100 {
+			t.Fatal("removed seed file still found after invalidation")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+// nextShareExpiry returns when the next folder share expires, or the zero
+// time if none does.
+func nextShareExpiry(cfg config.Configuration) time.Time {
+	var next time.Time
+	for _, fcfg := range cfg.Folders {
+		for _, dev := range fcfg.Devices {
+			if !dev.Expires.IsZero() && (next.IsZero() || dev.Expires.Before(next)) {
+				next = dev.Expires
+			}
+		}
+	}
+	return next
+}
+
+// scheduleShareExpiry arranges for the next expiring folder share to be
+// revoked when it expires.
+func (m *model) scheduleShareExpiry(cfg config.Configuration) {
+	next := nextShareExpiry(cfg)
+	if next.IsZero() {
+		m.shareExpiryTimer.Stop()
+		return
+	}
+	m.shareExpiryTimer.Reset(max(time.Until(next), 0))
+}
+
+// expireShares unshares folders from devices whose share has expired. The
+// devices are notified by the cluster config sent on committing the
+// change, in which the folder isn't shared with them anymore.
+func (m *model) expireShares() {
+	now := time.Now()
+	expired := false
+	for _, fcfg := range m.cfg.Folders() {
+		if slices.ContainsFunc(fcfg.Devices, func(dev config.FolderDeviceConfiguration) bool { return dev.Expired(now) }) {
+			expired = true
+			break
+		}
+	}
+	if !expired {
+		m.scheduleShareExpiry(m.cfg.RawCopy())
+		return
+	}
+
+	m.cfg.Modify(func(cfg *config.Configuration) {
+		for i := range cfg.Folders {
+			fcfg := &cfg.Folders[i]
+			fcfg.Devices = slices.DeleteFunc(fcfg.Devices, func(dev config.FolderDeviceConfiguration) bool {
+				if !dev.Expired(now) {
+					return false
+				}
+				slog.Info("Share with device expired, unsharing folder", fcfg.LogAttr(), dev.DeviceID.LogAttr())
+				return true
+			})
+		}
+	})
+}
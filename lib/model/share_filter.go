@@ -0,0 +1,148 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/ignore"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// shareFilter is the part of a folder shared with a device that has a
+// filter on its share. Items matching the filter are shared with all that
+// is below them, as are the directories leading to literal paths in it, so
+// that the device can create them.
+type shareFilter struct {
+	patterns []string
+	matcher  *ignore.Matcher
+	parents  map[string]struct{}
+}
+
+// newShareFilter returns the filter of the folder's share with the device,
+// or nil if everything is shared with it.
+func newShareFilter(fcfg config.FolderConfiguration, device protocol.DeviceID) *shareFilter {
+	dev, ok := fcfg.Device(device)
+	if !ok || len(dev.Filter) == 0 {
+		return nil
+	}
+
+	s := &shareFilter{
+		patterns: dev.Filter,
+		matcher:  ignore.New(fcfg.Filesystem(), ignore.WithCache(false)),
+		parents:  make(map[string]struct{}),
+	}
+	if err := s.matcher.Parse(strings.NewReader(strings.Join(dev.Filter, "\n")), ".stignore"); err != nil {
+		// Sharing nothing rather than everything is the safe side.
+		slog.Warn("Invalid share filter; sharing nothing", fcfg.LogAttr(), device.LogAttr(), slogutil.Error(err))
+		return s
+	}
+	for _, pattern := range dev.Filter {
+		if strings.ContainsAny(pattern, "*?[{!(\\") {
+			continue
+		}
+		name := filepath.FromSlash(strings.Trim(pattern, "/"))
+		for dir := filepath.Dir(name); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+			s.parents[dir] = struct{}{}
+		}
+	}
+	return s
+}
+
+// allows returns true if the named item is shared. A nil filter allows
+// everything.
+func (s *shareFilter) allows(name string) bool {
+	if s == nil {
+		return true
+	}
+	if _, ok := s.parents[name]; ok {
+		return true
+	}
+	for ; name != "." && name != string(filepath.Separator); name = filepath.Dir(name) {
+		if s.matcher.Match(name).IsIgnored() {
+			return true
+		}
+	}
+	return false
+}
+
+// sharedIndexID returns the index ID of our index of the folder as
+// announced to the device. With a filter on the share it's derived from the
+// patterns, so that when they change the device sees a new index ID, drops
+// what it had from us, and gets a full index of what's now shared.
+func sharedIndexID(id protocol.IndexID, fcfg config.FolderConfiguration, device protocol.DeviceID) protocol.IndexID {
+	dev, ok := fcfg.Device(device)
+	if !ok || len(dev.Filter) == 0 || id == 0 {
+		return id
+	}
+	h := sha256.New()
+	_ = binary.Write(h, binary.BigEndian, uint64(id))
+	for _, pattern := range dev.Filter {
+		h.Write([]byte(pattern))
+		h.Write([]byte{0})
+	}
+	derived := protocol.IndexID(binary.BigEndian.Uint64(h.Sum(nil)))
+	if derived == 0 {
+		derived = 1
+	}
+	return derived
+}
+
+// refuseUnsharedChanges marks the items outside the filter of the folder's
+// share with the device in an index update from it as invalid, as the
+// device isn't meant to change them.
+func (m *model) refuseUnsharedChanges(folder string, device protocol.DeviceID, fs []protocol.FileInfo) {
+	cfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return
+	}
+	filter := m.shareFilters.get(cfg, device)
+	if filter == nil {
+		return
+	}
+	for i := range fs {
+		if !filter.allows(fs[i].Name) {
+			fs[i].LocalFlags |= protocol.FlagLocalRemoteInvalid
+		}
+	}
+}
+
+// shareFilters holds the filters of the shares with devices, made when
+// first needed and again when they change.
+type shareFilters struct {
+	mut     sync.Mutex
+	filters map[string]*shareFilter // by folder and device
+}
+
+func newShareFilters() *shareFilters {
+	return &shareFilters{filters: make(map[string]*shareFilter)}
+}
+
+func (c *shareFilters) get(fcfg config.FolderConfiguration, device protocol.DeviceID) *shareFilter {
+	dev, ok := fcfg.Device(device)
+	if !ok || len(dev.Filter) == 0 {
+		return nil
+	}
+
+	key := fcfg.ID + "/" + device.String()
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if s, ok := c.filters[key]; ok && slices.Equal(s.patterns, dev.Filter) {
+		return s
+	}
+	s := newShareFilter(fcfg, device)
+	c.filters[key] = s
+	return s
+}
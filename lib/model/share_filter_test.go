@@ -0,0 +1,107 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestShareFilter(t *testing.T) {
+	fcfg := newFolderConfig()
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{
+		DeviceID: device2,
+		Filter:   []string{"photos/2024", "*.pdf"},
+	})
+
+	if newShareFilter(fcfg, device1) != nil {
+		t.Error("expected no filter for a device without one")
+	}
+
+	filter := newShareFilter(fcfg, device2)
+	for name, allowed := range map[string]bool{
+		"photos":                        true, // leads to photos/2024
+		filepath.Join("photos", "2024"): true,
+		filepath.Join("photos", "2024", "a", "b"):   true,
+		filepath.Join("photos", "2023"):             false,
+		filepath.Join("photos", "2023", "file.jpg"): false,
+		filepath.Join("docs", "report.pdf"):         true,
+		"notes.txt":                                 false,
+	} {
+		if got := filter.allows(name); got != allowed {
+			t.Errorf("%s: expected allowed %v, got %v", name, allowed, got)
+		}
+	}
+}
+
+func TestSharedIndexID(t *testing.T) {
+	id := protocol.NewIndexID()
+	fcfg := newFolderConfig()
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device2})
+
+	if got := sharedIndexID(id, fcfg, device2); got != id {
+		t.Error("expected our index ID for a share without filter")
+	}
+
+	fcfg.Devices[len(fcfg.Devices)-1].Filter = []string{"photos"}
+	filtered := sharedIndexID(id, fcfg, device2)
+	if filtered == id || filtered == 0 {
+		t.Error("expected a derived index ID for a filtered share, got", filtered)
+	}
+	if got := sharedIndexID(id, fcfg, device2); got != filtered {
+		t.Error("expected the same index ID for the same filter")
+	}
+
+	// Narrowing the filter makes for a new index, replacing what the
+	// device had.
+	fcfg.Devices[len(fcfg.Devices)-1].Filter = []string{"photos/2024"}
+	if got := sharedIndexID(id, fcfg, device2); got == filtered || got == id {
+		t.Error("expected a new index ID for a changed filter")
+	}
+}
+
+func TestShareFilterReceive(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	for i := range fcfg.Devices {
+		if fcfg.Devices[i].DeviceID == device1 {
+			fcfg.Devices[i].Filter = []string{"shared"}
+		}
+	}
+	setFolder(t, w, fcfg)
+	m, fc := setupModelWithConnectionFromWrapper(t, w)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	fc.addFile("shared", 0o644, protocol.FileInfoTypeFile, []byte("a"))
+	fc.addFile("other", 0o644, protocol.FileInfoTypeFile, []byte("b"))
+	fc.sendIndexUpdate()
+
+	// Changes outside of the filter are known, but only as invalid, so
+	// they're never pulled.
+	for i := 0; ; i++ {
+		shared, okShared, err := m.sdb.GetGlobalFile(fcfg.ID, "shared")
+		must(t, err)
+		other, okOther, err := m.sdb.GetGlobalFile(fcfg.ID, "other")
+		must(t, err)
+		if okShared && okOther {
+			if shared.IsInvalid() {
+				t.Error("change inside the filter became invalid")
+			}
+			if !other.IsInvalid() {
+				t.Error("change outside the filter became global")
+			}
+			break
+		}
+		if i == 100 {
+			t.Fatal("timed out waiting for index update")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -7,15 +7,19 @@
 package model
 
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"sort"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/syncthing/syncthing/internal/protoutil"
+	"github.com/syncthing/syncthing/internal/tracing"
 	"github.com/syncthing/syncthing/lib/fs"
 	"github.com/syncthing/syncthing/lib/osutil"
 	"github.com/syncthing/syncthing/lib/protocol"
@@ -37,6 +41,8 @@ type sharedPullerState struct {
 	sparse      bool
 	created     time.Time
 	fsync       bool
+	span        trace.Span // Covers the whole pull of the file, may be nil
+	spanEnded   sync.Once
 
 	// Mutable, must be locked for access
 	err              error           // The first error we hit
@@ -268,10 +274,12 @@ func (s *sharedPullerState) copiedFromOrigin(bytes int) {
 	s.updated = time.Now()
 	s.mut.Unlock()
 	metricFolderProcessedBytesTotal.WithLabelValues(s.folder, metricSourceLocalOrigin).Add(float64(bytes))
+	metricFolderProcessedBlocksTotal.WithLabelValues(s.folder, metricSourceLocalOrigin).Inc()
 }
 
 func (s *sharedPullerState) copiedFromElsewhere(bytes int) {
 	metricFolderProcessedBytesTotal.WithLabelValues(s.folder, metricSourceLocalOther).Add(float64(bytes))
+	metricFolderProcessedBlocksTotal.WithLabelValues(s.folder, metricSourceLocalOther).Inc()
 }
 
 func (s *sharedPullerState) skippedSparseBlock(bytes int) {
@@ -281,6 +289,7 @@ func (s *sharedPullerState) skippedSparseBlock(bytes int) {
 	s.updated = time.Now()
 	s.mut.Unlock()
 	metricFolderProcessedBytesTotal.WithLabelValues(s.folder, metricSourceSkipped).Add(float64(bytes))
+	metricFolderProcessedBlocksTotal.WithLabelValues(s.folder, metricSourceSkipped).Inc()
 }
 
 func (s *sharedPullerState) pullStarted() {
@@ -303,6 +312,7 @@ func (s *sharedPullerState) pullDone(block protocol.BlockInfo) {
 	l.Debugln("sharedPullerState", s.folder, s.file.Name, "pullNeeded done ->", s.pullNeeded)
 	s.mut.Unlock()
 	metricFolderProcessedBytesTotal.WithLabelValues(s.folder, metricSourceNetwork).Add(float64(block.Size))
+	metricFolderProcessedBlocksTotal.WithLabelValues(s.folder, metricSourceNetwork).Inc()
 }
 
 // finalClose atomically closes and returns closed status of a file. A true
@@ -329,6 +339,7 @@ func (s *sharedPullerState) finalClose() (bool, error) {
 		// time to do so. This also truncates the file to the correct size
 		// if we're using sparse file.
 		if err := s.addWriterLocked(); err != nil {
+			s.endSpan(err)
 			return false, err
 		}
 	}
@@ -338,6 +349,8 @@ func (s *sharedPullerState) finalClose() (bool, error) {
 			// This is our error as we weren't errored before.
 			s.err = err
 		}
+	} else if s.sparse && s.err == nil {
+		s.punchHolesLocked()
 	}
 
 	if s.writer != nil {
@@ -359,6 +372,64 @@ func (s *sharedPullerState) finalClose() (bool, error) {
 	return true, s.err
 }
 
+// punchHolesLocked deallocates the regions that are holes in the file on
+// the other device. Empty blocks are not written in the first place, but
+// holes need not be aligned to blocks. The holes are only what the other
+// device says, so just the parts of them that are known to be zeroes are
+// deallocated, lest a wrong hole discards verified data. Failing to do so
+// only costs space.
+func (s *sharedPullerState) punchHolesLocked() {
+	if err := fs.PunchHoles(s.writer.fd, s.zeroHolesLocked()); err != nil {
+		l.Debugln("failed to punch holes:", s.tempName, err)
+	}
+}
+
+// zeroHolesLocked returns the parts of the file's holes that are zeroes:
+// those in empty blocks, and those in other blocks that read back as
+// zeroes.
+func (s *sharedPullerState) zeroHolesLocked() []protocol.Hole {
+	var holes []protocol.Hole
+	var buf []byte
+	blocks := s.file.Blocks
+	for _, h := range s.file.Holes {
+		end := h.Offset + h.Size
+		first := sort.Search(len(blocks), func(i int) bool {
+			return blocks[i].Offset+int64(blocks[i].Size) > h.Offset
+		})
+		for _, b := range blocks[first:] {
+			if b.Offset >= end {
+				break
+			}
+			part := protocol.Hole{Offset: max(h.Offset, b.Offset)}
+			part.Size = min(end, b.Offset+int64(b.Size)) - part.Offset
+			if !b.IsEmpty() {
+				if int64(cap(buf)) < part.Size {
+					buf = make([]byte, part.Size)
+				}
+				buf = buf[:part.Size]
+				if _, err := s.writer.fd.ReadAt(buf, part.Offset); err != nil || !isZeroes(buf) {
+					continue
+				}
+			}
+			if n := len(holes); n > 0 && holes[n-1].Offset+holes[n-1].Size == part.Offset {
+				holes[n-1].Size += part.Size
+			} else {
+				holes = append(holes, part)
+			}
+		}
+	}
+	return holes
+}
+
+func isZeroes(bs []byte) bool {
+	for _, b := range bs {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // finalizeEncrypted adds a trailer to the encrypted file containing the
 // serialized FileInfo and the length of that FileInfo. When initializing a
 // folder from encrypted data we can extract this FileInfo from the end of
@@ -452,3 +523,22 @@ func blocksToSize(blocks, blocksInFile, blockSize int, fileSize int64) int64 {
 	}
 	return int64(blocks)*int64(blockSize) - (int64(blockSize)-fileSize%int64(blockSize))*int64(blocks)/int64(blocksInFile)
 }
+
+// traceContext returns a context carrying the file's span, so that spans
+// started from it become children of the file pull.
+func (s *sharedPullerState) traceContext(ctx context.Context) context.Context {
+	if s.span == nil {
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, s.span)
+}
+
+// endSpan ends the file's span, if any. Only the first call has an effect.
+func (s *sharedPullerState) endSpan(err error) {
+	if s.span == nil {
+		return
+	}
+	s.spanEnded.Do(func() {
+		tracing.End(s.span, err)
+	})
+}
@@ -7,9 +7,13 @@
 package model
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"slices"
 	"testing"
 
 	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
 	"github.com/syncthing/syncthing/lib/rand"
 )
 
@@ -34,3 +38,45 @@ func TestReadOnlyDir(t *testing.T) {
 	s.fail(nil)
 	s.finalClose()
 }
+
+func TestZeroHoles(t *testing.T) {
+	ffs := fs.NewFilesystem(fs.FilesystemTypeBasic, t.TempDir())
+	fd, err := ffs.Create("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+
+	// A block of data with zeroes in its second half, then an empty block
+	// that isn't written at all.
+	const size = protocol.MinBlockSize
+	data := make([]byte, size)
+	copy(data, bytes.Repeat([]byte("a"), size/2))
+	if _, err := fd.WriteAt(data, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := fd.Truncate(2 * size); err != nil {
+		t.Fatal(err)
+	}
+	dataHash := sha256.Sum256(data)
+	emptyHash := sha256.Sum256(make([]byte, size))
+
+	s := sharedPullerState{
+		file: protocol.FileInfo{
+			Size: 2 * size,
+			Blocks: []protocol.BlockInfo{
+				{Offset: 0, Size: size, Hash: dataHash[:]},
+				{Offset: size, Size: size, Hash: emptyHash[:]},
+			},
+			// The first hole claims data that isn't zeroes.
+			Holes: []protocol.Hole{{Offset: 0, Size: size / 4}, {Offset: size / 2, Size: 3 * size / 2}},
+		},
+		writer: &lockedWriterAt{fd: fd},
+	}
+
+	holes := s.zeroHolesLocked()
+	expected := []protocol.Hole{{Offset: size / 2, Size: 3 * size / 2}}
+	if !slices.Equal(holes, expected) {
+		t.Errorf("expected holes %v, got %v", expected, holes)
+	}
+}
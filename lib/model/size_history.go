@@ -0,0 +1,83 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/stats"
+)
+
+// sizeHistoryInterval is how often the sizes of the folders are recorded.
+// The history keeps the last sample of each hour and day.
+const sizeHistoryInterval = 15 * time.Minute
+
+// FolderSizeHistory returns how the sizes of the folder developed over
+// time.
+func (m *model) FolderSizeHistory(folder string) (stats.SizeHistory, error) {
+	m.mut.RLock()
+	_, cfgOK := m.folderCfgs[folder]
+	runner, runnerOK := m.folderRunners.Get(folder)
+	m.mut.RUnlock()
+	if !cfgOK {
+		return stats.SizeHistory{}, ErrFolderMissing
+	}
+	if !runnerOK {
+		return stats.SizeHistory{}, ErrFolderPaused
+	}
+	return runner.GetSizeHistory()
+}
+
+// recordSizeHistory adds the current sizes of the running folders to their
+// size history.
+func (m *model) recordSizeHistory() {
+	now := time.Now()
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	_ = m.folderRunners.Each(func(folder string, runner service) error {
+		sample, err := m.sizeSample(folder, now)
+		if err == nil {
+			err = runner.RecordSizes(sample)
+		}
+		if err != nil {
+			slog.Warn("Failed to record folder sizes", slog.String("folder", folder), slogutil.Error(err))
+		}
+		return nil
+	})
+}
+
+func (m *model) sizeSample(folder string, at time.Time) (stats.SizeSample, error) {
+	global, err := m.sdb.CountGlobal(folder)
+	if err != nil {
+		return stats.SizeSample{}, err
+	}
+	local, err := m.sdb.CountLocal(folder, protocol.LocalDeviceID)
+	if err != nil {
+		return stats.SizeSample{}, err
+	}
+	need, err := m.sdb.CountNeed(folder, protocol.LocalDeviceID)
+	if err != nil {
+		return stats.SizeSample{}, err
+	}
+	return stats.SizeSample{
+		At:          at,
+		GlobalItems: existingItems(global),
+		GlobalBytes: global.Bytes,
+		LocalItems:  existingItems(local),
+		LocalBytes:  local.Bytes,
+		NeedItems:   need.TotalItems(),
+		NeedBytes:   need.Bytes,
+	}, nil
+}
+
+func existingItems(c db.Counts) int {
+	return c.Files + c.Directories + c.Symlinks
+}
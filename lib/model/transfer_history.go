@@ -0,0 +1,180 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	transferInterval = 10 * time.Second
+	transferSamples  = int(24 * time.Hour / transferInterval)
+)
+
+// TransferHistory holds transfer rates over the last day, per device and in
+// total, oldest first.
+type TransferHistory struct {
+	IntervalS int                                  `json:"intervalS"`
+	Total     []TransferRate                       `json:"total"`
+	Devices   map[protocol.DeviceID][]TransferRate `json:"devices"`
+}
+
+// TransferRate is the average rate over the interval ending at the given
+// time.
+type TransferRate struct {
+	At                time.Time `json:"at"`
+	InBytesPerSecond  int64     `json:"inBytesPerSecond"`
+	OutBytesPerSecond int64     `json:"outBytesPerSecond"`
+}
+
+type transferBytes struct {
+	in, out int64
+}
+
+// transferRing keeps the bytes transferred in the last transferSamples
+// intervals.
+type transferRing struct {
+	buckets []transferBytes
+	next    int
+}
+
+func (r *transferRing) add(b transferBytes) {
+	if len(r.buckets) < transferSamples {
+		r.buckets = append(r.buckets, b)
+		return
+	}
+	r.buckets[r.next] = b
+	r.next = (r.next + 1) % transferSamples
+}
+
+// rates returns the rates for the samples after since, given the time of
+// the last one.
+func (r *transferRing) rates(last, since time.Time) []TransferRate {
+	n := len(r.buckets)
+	rates := make([]TransferRate, 0, n)
+	for i := range n {
+		at := last.Add(-time.Duration(n-1-i) * transferInterval)
+		if !at.After(since) {
+			continue
+		}
+		b := r.buckets[(r.next+i)%n]
+		rates = append(rates, TransferRate{
+			At:                at,
+			InBytesPerSecond:  b.in / int64(transferInterval/time.Second),
+			OutBytesPerSecond: b.out / int64(transferInterval/time.Second),
+		})
+	}
+	return rates
+}
+
+// transferHistory samples the bytes transferred at every interval.
+type transferHistory struct {
+	mut      sync.Mutex
+	last     time.Time
+	prevConn map[string]transferBytes // connection ID -> totals at the last sample
+	prevTot  transferBytes
+	total    transferRing
+	devices  map[protocol.DeviceID]*transferRing
+}
+
+func newTransferHistory() *transferHistory {
+	in, out := protocol.TotalInOut()
+	return &transferHistory{
+		prevConn: make(map[string]transferBytes),
+		prevTot:  transferBytes{in, out},
+		devices:  make(map[protocol.DeviceID]*transferRing),
+	}
+}
+
+// sample records what was transferred since the last sample, which is
+// returned per device. The totals are given per connection of each device;
+// bytes transferred on connections that closed since are not counted for
+// the device.
+func (h *transferHistory) sample(now time.Time, conns map[protocol.DeviceID]map[string]transferBytes, total transferBytes) map[protocol.DeviceID]transferBytes {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	prevConn := h.prevConn
+	h.prevConn = make(map[string]transferBytes, len(prevConn))
+	deltas := make(map[protocol.DeviceID]transferBytes, len(conns))
+	for dev, devConns := range conns {
+		var sum transferBytes
+		for id, cur := range devConns {
+			prev := prevConn[id]
+			sum.in += cur.in - prev.in
+			sum.out += cur.out - prev.out
+			h.prevConn[id] = cur
+		}
+		ring, ok := h.devices[dev]
+		if !ok {
+			ring = new(transferRing)
+			h.devices[dev] = ring
+		}
+		ring.add(sum)
+		deltas[dev] = sum
+	}
+	for dev := range h.devices {
+		if _, ok := conns[dev]; !ok {
+			delete(h.devices, dev)
+		}
+	}
+
+	h.total.add(transferBytes{total.in - h.prevTot.in, total.out - h.prevTot.out})
+	h.prevTot = total
+	h.last = now
+	return deltas
+}
+
+func (h *transferHistory) history(since time.Time) TransferHistory {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	res := TransferHistory{
+		IntervalS: int(transferInterval / time.Second),
+		Total:     h.total.rates(h.last, since),
+		Devices:   make(map[protocol.DeviceID][]TransferRate, len(h.devices)),
+	}
+	for dev, ring := range h.devices {
+		res.Devices[dev] = ring.rates(h.last, since)
+	}
+	return res
+}
+
+// TransferHistory returns the transfer rates after since, with a sample
+// every ten seconds for the last day.
+func (m *model) TransferHistory(since time.Time) TransferHistory {
+	return m.transferHistory.history(since)
+}
+
+// sampleTransfers records the bytes transferred with each configured
+// device, connected or not, and in total, and accounts them against the
+// data quotas.
+func (m *model) sampleTransfers() {
+	devs := m.cfg.Devices()
+	conns := make(map[protocol.DeviceID]map[string]transferBytes, len(devs))
+	m.mut.RLock()
+	for dev := range devs {
+		if dev == m.id {
+			continue
+		}
+		devConns := make(map[string]transferBytes)
+		for _, id := range m.deviceConnIDs[dev] {
+			st := m.connections[id].Statistics()
+			devConns[id] = transferBytes{st.InBytesTotal, st.OutBytesTotal}
+		}
+		conns[dev] = devConns
+	}
+	m.mut.RUnlock()
+
+	in, out := protocol.TotalInOut()
+	now := time.Now().Truncate(time.Second)
+	deltas := m.transferHistory.sample(now, conns, transferBytes{in, out})
+	m.accountUsage(now, deltas)
+}
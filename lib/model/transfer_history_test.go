@@ -0,0 +1,77 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestTransferHistory(t *testing.T) {
+	h := &transferHistory{
+		prevConn: make(map[string]transferBytes),
+		devices:  make(map[protocol.DeviceID]*transferRing),
+	}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Two connections to device1, the second of which is replaced.
+	h.sample(start, map[protocol.DeviceID]map[string]transferBytes{
+		device1: {"a": {100, 200}, "b": {10, 20}},
+		device2: {},
+	}, transferBytes{110, 220})
+	h.sample(start.Add(transferInterval), map[protocol.DeviceID]map[string]transferBytes{
+		device1: {"a": {300, 200}, "c": {50, 0}},
+		device2: {},
+	}, transferBytes{410, 220})
+
+	hist := h.history(time.Time{})
+	if len(hist.Total) != 2 || hist.Total[1].InBytesPerSecond != 30 || hist.Total[1].OutBytesPerSecond != 0 {
+		t.Errorf("unexpected total %+v", hist.Total)
+	}
+	dev1 := hist.Devices[device1]
+	if len(dev1) != 2 || dev1[0].InBytesPerSecond != 11 || dev1[1].InBytesPerSecond != 25 {
+		t.Errorf("unexpected rates for device1 %+v", dev1)
+	}
+	if !dev1[1].At.Equal(start.Add(transferInterval)) {
+		t.Errorf("unexpected time %v for the last sample", dev1[1].At)
+	}
+	if len(hist.Devices[device2]) != 2 {
+		t.Errorf("expected samples for disconnected device2")
+	}
+
+	if hist := h.history(start); len(hist.Total) != 1 {
+		t.Errorf("expected one sample after the first, got %d", len(hist.Total))
+	}
+
+	// A removed device is forgotten.
+	h.sample(start.Add(2*transferInterval), map[protocol.DeviceID]map[string]transferBytes{
+		device1: {},
+	}, transferBytes{410, 220})
+	if _, ok := h.history(time.Time{}).Devices[device2]; ok {
+		t.Error("expected removed device2 to be forgotten")
+	}
+}
+
+func TestTransferRing(t *testing.T) {
+	var r transferRing
+	for i := range transferSamples + 5 {
+		r.add(transferBytes{in: int64(i) * 10})
+	}
+	last := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rates := r.rates(last, time.Time{})
+	if len(rates) != transferSamples {
+		t.Fatalf("got %d samples, expected %d", len(rates), transferSamples)
+	}
+	if rates[0].InBytesPerSecond != 5 || rates[len(rates)-1].InBytesPerSecond != int64(transferSamples+4) {
+		t.Errorf("unexpected oldest %+v or newest %+v sample", rates[0], rates[len(rates)-1])
+	}
+	if !rates[len(rates)-1].At.Equal(last) {
+		t.Errorf("unexpected time %v for the newest sample", rates[len(rates)-1].At)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/scanner"
+	"github.com/syncthing/syncthing/lib/versioner"
+)
+
+var errNotRestorable = errors.New("file is not deleted or there is no copy to restore")
+
+// A DeletedFile is a file deleted in the global index of which a copy may
+// still exist, in our versions or on devices that have not yet applied the
+// delete.
+type DeletedFile struct {
+	Name     string                  `json:"name"`
+	ModTime  time.Time               `json:"modTime"`
+	Versions []versioner.FileVersion `json:"versions"`
+	Devices  []protocol.DeviceID     `json:"devices"`
+}
+
+// DeletedFiles returns the files under the prefix that are deleted in the
+// global index and can be restored.
+func (m *model) DeletedFiles(folder, prefix string) ([]DeletedFile, error) {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	fcfg := m.folderCfgs[folder]
+	ver := m.folderVersioners[folder]
+	m.mut.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions map[string][]versioner.FileVersion
+	if ver != nil {
+		if versions, err = ver.GetVersions(); err != nil {
+			return nil, err
+		}
+	}
+
+	var deleted []DeletedFile
+	it, errFn := m.sdb.AllGlobalFilesPrefix(folder, prefix)
+	for meta := range it {
+		if !meta.Deleted || meta.IsDirectory() {
+			continue
+		}
+		devices, err := m.devicesWithCopy(fcfg, meta.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(devices) == 0 && len(versions[meta.Name]) == 0 {
+			continue
+		}
+		deleted = append(deleted, DeletedFile{
+			Name:     meta.Name,
+			ModTime:  meta.ModTime(),
+			Versions: versions[meta.Name],
+			Devices:  devices,
+		})
+	}
+	if err := errFn(); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// devicesWithCopy returns the other devices sharing the folder that still
+// announce a regular, valid file by the given name.
+func (m *model) devicesWithCopy(fcfg config.FolderConfiguration, name string) ([]protocol.DeviceID, error) {
+	var devices []protocol.DeviceID
+	for _, dev := range fcfg.Devices {
+		if dev.DeviceID == m.id {
+			continue
+		}
+		fi, ok, err := m.sdb.GetDeviceFile(fcfg.ID, dev.DeviceID, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok && !fi.IsDeleted() && !fi.IsInvalid() && fi.Type == protocol.FileInfoTypeFile {
+			devices = append(devices, dev.DeviceID)
+		}
+	}
+	return devices, nil
+}
+
+// Undelete brings back a file that is deleted in the global index, from
+// the latest local version if there is one and otherwise by pulling the
+// copy a device still has. The restored file is scanned, so that it
+// becomes a new version overriding the delete.
+func (m *model) Undelete(ctx context.Context, folder, name string) error {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	fcfg := m.folderCfgs[folder]
+	ver := m.folderVersioners[folder]
+	m.mut.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if name, err = fs.Canonicalize(name); err != nil {
+		return err
+	}
+	global, ok, err := m.sdb.GetGlobalFile(folder, name)
+	if err != nil {
+		return err
+	}
+	if !ok || !global.IsDeleted() {
+		return errNotRestorable
+	}
+
+	if ver != nil {
+		versions, err := ver.GetVersions()
+		if err != nil {
+			return err
+		}
+		if vs := versions[name]; len(vs) > 0 {
+			latest := vs[0]
+			for _, v := range vs[1:] {
+				if v.VersionTime.After(latest.VersionTime) {
+					latest = v
+				}
+			}
+			if err := ver.Restore(name, latest.VersionTime); err != nil {
+				return err
+			}
+			return m.ScanFolderSubdirs(folder, []string{name})
+		}
+	}
+
+	devices, err := m.devicesWithCopy(fcfg, name)
+	if err != nil {
+		return err
+	}
+	err = errNotRestorable
+	for _, dev := range devices {
+		if !m.ConnectedTo(dev) {
+			continue
+		}
+		if err = m.pullCopy(ctx, fcfg, dev, name); err == nil {
+			return m.ScanFolderSubdirs(folder, []string{name})
+		}
+	}
+	return err
+}
+
+// pullCopy writes the device's version of the file into the folder.
+func (m *model) pullCopy(ctx context.Context, fcfg config.FolderConfiguration, device protocol.DeviceID, name string) error {
+	file, ok, err := m.sdb.GetDeviceFile(fcfg.ID, device, name)
+	if err != nil {
+		return err
+	}
+	if !ok || file.IsDeleted() || file.IsInvalid() || file.Type != protocol.FileInfoTypeFile {
+		return errNotRestorable
+	}
+
+	ffs := fcfg.Filesystem()
+	if err := ffs.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	tempName := fs.TempName(name)
+	fd, err := ffs.Create(tempName)
+	if err != nil {
+		return err
+	}
+	defer ffs.Remove(tempName)
+
+	for i, block := range file.Blocks {
+		data, err := m.RequestGlobal(ctx, device, fcfg.ID, name, i, block.Offset, block.Size, block.Hash, false, true)
+		if err == nil && !scanner.Validate(data, block.Hash) {
+			err = errors.New("hash mismatch")
+		}
+		if err == nil {
+			_, err = fd.WriteAt(data, block.Offset)
+		}
+		if err != nil {
+			fd.Close()
+			return fmt.Errorf("block %d of %s from %s: %w", i, name, device.Short(), err)
+		}
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+	if err := ffs.Chtimes(tempName, file.ModTime(), file.ModTime()); err != nil {
+		return err
+	}
+	if _, err := ffs.Lstat(name); err == nil {
+		return fmt.Errorf("%s: file exists", name)
+	}
+	return ffs.Rename(tempName, name)
+}
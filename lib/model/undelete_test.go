@@ -0,0 +1,52 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestDeletedFiles(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device2})
+	setFolder(t, w, fcfg)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	// device1 has a file that device2 has since deleted.
+	file := protocol.FileInfo{
+		Name:    "a",
+		Type:    protocol.FileInfoTypeFile,
+		Size:    1,
+		Version: protocol.Vector{}.Update(device1.Short()),
+		Blocks:  []protocol.BlockInfo{{Size: 1, Hash: []byte("hash")}},
+	}
+	deleted := file
+	deleted.Deleted = true
+	deleted.Blocks = nil
+	deleted.Version = file.Version.Update(device2.Short())
+	must(t, m.sdb.Update(fcfg.ID, device1, []protocol.FileInfo{file}))
+	must(t, m.sdb.Update(fcfg.ID, device2, []protocol.FileInfo{deleted}))
+
+	files, err := m.DeletedFiles(fcfg.ID, "")
+	must(t, err)
+	if len(files) != 1 || files[0].Name != "a" || !slices.Equal(files[0].Devices, []protocol.DeviceID{device1}) {
+		t.Fatalf("expected a to be restorable from device1, got %+v", files)
+	}
+
+	// Once device1 has deleted it too, there is nothing to restore.
+	must(t, m.sdb.Update(fcfg.ID, device1, []protocol.FileInfo{deleted}))
+	files, err = m.DeletedFiles(fcfg.ID, "")
+	must(t, err)
+	if len(files) != 0 {
+		t.Errorf("expected no restorable files, got %+v", files)
+	}
+}
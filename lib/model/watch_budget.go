@@ -0,0 +1,237 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/ignore/ignoreresult"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Each watched directory takes an inotify watch on Linux, and there is a
+// limit on those per user. Rather than failing to watch a folder that would
+// take it over the limit, or over its own maximum, the folder watches what
+// it may and leaves its least recently modified subtrees unwatched. Those
+// are picked up by the periodic rescans, or in watcher only mode by rescans
+// of just them on the rescan interval.
+
+// watchSystemShare is the share of the system limit all folders may use
+// together, leaving the rest to other programs.
+const watchSystemShare = 0.8
+
+// WatchUsage is how many directories a folder watches, within its budget.
+type WatchUsage struct {
+	Watched   int      `json:"watched"`
+	Budget    int      `json:"budget"`    // zero if unlimited
+	Unwatched []string `json:"unwatched"` // subtrees left to rescans
+}
+
+// WatchReport is the watch usage of all watching folders.
+type WatchReport struct {
+	SystemLimit int                   `json:"systemLimit"` // zero if unknown
+	Folders     map[string]WatchUsage `json:"folders"`
+}
+
+type watchUsages struct {
+	mut     sync.Mutex
+	folders map[string]WatchUsage
+}
+
+func newWatchUsages() *watchUsages {
+	return &watchUsages{folders: make(map[string]WatchUsage)}
+}
+
+func (u *watchUsages) set(folder string, usage WatchUsage) {
+	u.mut.Lock()
+	u.folders[folder] = usage
+	u.mut.Unlock()
+	metricFolderWatchedDirs.WithLabelValues(folder).Set(float64(usage.Watched))
+	metricFolderUnwatchedSubtrees.WithLabelValues(folder).Set(float64(len(usage.Unwatched)))
+}
+
+func (u *watchUsages) remove(folder string) {
+	u.mut.Lock()
+	delete(u.folders, folder)
+	u.mut.Unlock()
+	metricFolderWatchedDirs.WithLabelValues(folder).Set(0)
+	metricFolderUnwatchedSubtrees.WithLabelValues(folder).Set(0)
+}
+
+// usedByOthers returns the number of watches used by the other folders.
+func (u *watchUsages) usedByOthers(folder string) int {
+	u.mut.Lock()
+	defer u.mut.Unlock()
+	var n int
+	for id, usage := range u.folders {
+		if id != folder {
+			n += usage.Watched
+		}
+	}
+	return n
+}
+
+func (m *model) WatchReport() WatchReport {
+	limit, _ := fs.MaxWatches()
+	m.watchUsages.mut.Lock()
+	defer m.watchUsages.mut.Unlock()
+	folders := make(map[string]WatchUsage, len(m.watchUsages.folders))
+	for id, usage := range m.watchUsages.folders {
+		folders[id] = usage
+	}
+	return WatchReport{SystemLimit: limit, Folders: folders}
+}
+
+// watchBudget returns the number of directories the folder may watch, or
+// zero if unlimited. Each time watching failed on the system limit, the
+// budget is halved.
+func (f *folder) watchBudget(limitErrors int) int {
+	budget := f.FSWatcherMaxWatches
+	if limit, ok := fs.MaxWatches(); ok && f.FilesystemType == config.FilesystemTypeBasic {
+		share := max(int(float64(limit)*watchSystemShare)-f.model.watchUsages.usedByOthers(f.folderID), 1)
+		if budget <= 0 || share < budget {
+			budget = share
+		}
+	}
+	if budget > 0 {
+		budget = max(budget>>limitErrors, 1)
+	}
+	return budget
+}
+
+// planWatch returns the matcher to watch the folder with, leaving out the
+// least recently modified subtrees as needed to stay within the budget,
+// and the usage it results in.
+func (f *folder) planWatch(budget int) (fs.Matcher, WatchUsage, error) {
+	type subtree struct {
+		dirs    int       // directories in the subtree, itself included
+		watched int       // of those, not in unwatched subtrees
+		latest  time.Time // latest modification in the subtree
+	}
+	subtrees := map[string]*subtree{".": {dirs: 1}}
+	get := func(name string) *subtree {
+		st, ok := subtrees[name]
+		if !ok {
+			st = &subtree{}
+			subtrees[name] = st
+		}
+		return st
+	}
+
+	it, errFn := f.db.AllLocalFiles(f.folderID, protocol.LocalDeviceID)
+	for fi := range it {
+		if fi.IsDeleted() || fi.IsInvalid() {
+			continue
+		}
+		isDir := fi.IsDirectory()
+		name := fi.Name
+		if !isDir {
+			name = filepath.Dir(name)
+		}
+		modTime := fi.ModTime()
+		for {
+			st := get(name)
+			if isDir {
+				st.dirs++
+			}
+			if modTime.After(st.latest) {
+				st.latest = modTime
+			}
+			if name == "." {
+				break
+			}
+			name = filepath.Dir(name)
+		}
+	}
+	if err := errFn(); err != nil {
+		return nil, WatchUsage{}, err
+	}
+
+	usage := WatchUsage{Watched: subtrees["."].dirs, Budget: budget}
+	if budget <= 0 || usage.Watched <= budget {
+		return f.ignores, usage, nil
+	}
+
+	// Leave out the least recently modified, and of those the largest,
+	// subtrees until within the budget.
+	candidates := make([]string, 0, len(subtrees)-1)
+	for name, st := range subtrees {
+		st.watched = st.dirs
+		if name != "." {
+			candidates = append(candidates, name)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b string) int {
+		sa, sb := subtrees[a], subtrees[b]
+		if c := sa.latest.Compare(sb.latest); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(sb.dirs, sa.dirs); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	unwatched := make(map[string]struct{})
+	for _, name := range candidates {
+		if usage.Watched <= budget {
+			break
+		}
+		if inUnwatched(unwatched, filepath.Dir(name)) {
+			continue
+		}
+		n := subtrees[name].watched
+		unwatched[name] = struct{}{}
+		usage.Watched -= n
+		for p := filepath.Dir(name); ; p = filepath.Dir(p) {
+			subtrees[p].watched -= n
+			if p == "." {
+				break
+			}
+		}
+	}
+	// Subtrees left out before one of their parents need not be listed.
+	for name := range unwatched {
+		if name != "." && inUnwatched(unwatched, filepath.Dir(name)) {
+			continue
+		}
+		usage.Unwatched = append(usage.Unwatched, name)
+	}
+	slices.Sort(usage.Unwatched)
+
+	return unwatchedMatcher{Matcher: f.ignores, unwatched: unwatched}, usage, nil
+}
+
+// inUnwatched returns true if the named directory is in, or is, one of the
+// unwatched subtrees.
+func inUnwatched(unwatched map[string]struct{}, name string) bool {
+	for ; name != "." && name != string(filepath.Separator); name = filepath.Dir(name) {
+		if _, ok := unwatched[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// unwatchedMatcher is the folder's ignores, with the unwatched subtrees
+// skipped as well.
+type unwatchedMatcher struct {
+	fs.Matcher
+	unwatched map[string]struct{}
+}
+
+func (m unwatchedMatcher) Match(name string) ignoreresult.R {
+	if inUnwatched(m.unwatched, name) {
+		return ignoreresult.IgnoreAndSkip
+	}
+	return m.Matcher.Match(name)
+}
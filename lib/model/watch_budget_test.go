@@ -0,0 +1,61 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestPlanWatch(t *testing.T) {
+	w, fcfg := newDefaultCfgWrapper(t)
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	old := time.Now().Add(-365 * 24 * time.Hour).Unix()
+	recent := time.Now().Unix()
+	entry := func(name string, typ protocol.FileInfoType, modified int64) protocol.FileInfo {
+		return protocol.FileInfo{Name: name, Type: typ, ModifiedS: modified, Version: protocol.Vector{}.Update(myID.Short())}
+	}
+	must(t, m.sdb.Update(fcfg.ID, protocol.LocalDeviceID, []protocol.FileInfo{
+		entry("old", protocol.FileInfoTypeDirectory, old),
+		entry(filepath.Join("old", "a"), protocol.FileInfoTypeDirectory, old),
+		entry(filepath.Join("old", "b"), protocol.FileInfoTypeDirectory, old),
+		entry(filepath.Join("old", "b", "file"), protocol.FileInfoTypeFile, old),
+		entry("new", protocol.FileInfoTypeDirectory, old),
+		entry(filepath.Join("new", "file"), protocol.FileInfoTypeFile, recent),
+	}))
+
+	m.mut.RLock()
+	r, _ := m.folderRunners.Get(fcfg.ID)
+	m.mut.RUnlock()
+	f := r.(*sendReceiveFolder)
+
+	// Within the budget, everything is watched.
+	_, usage, err := f.planWatch(5)
+	must(t, err)
+	if usage.Watched != 5 || len(usage.Unwatched) != 0 {
+		t.Error("unexpected usage", usage)
+	}
+
+	// Otherwise the least recently modified subtree is left out.
+	matcher, usage, err := f.planWatch(3)
+	must(t, err)
+	if usage.Watched != 2 || !slices.Equal(usage.Unwatched, []string{"old"}) {
+		t.Error("unexpected usage", usage)
+	}
+	if !matcher.Match(filepath.Join("old", "b", "file")).CanSkipDir() {
+		t.Error("unwatched subtree should be skipped")
+	}
+	if matcher.Match(filepath.Join("new", "file")).IsIgnored() {
+		t.Error("watched subtree should not be skipped")
+	}
+}
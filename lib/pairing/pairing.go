@@ -0,0 +1,330 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package pairing lets two devices exchange their device IDs using a short
+// one-time code instead of the full IDs.
+//
+// Both sides derive the same throwaway identity (key and certificate) from
+// the code. The offering side joins a relay under that identity and
+// announces the relay to the global discovery servers; the accepting side
+// looks the identity up and connects through the relay. The TLS session
+// between them is only established when both present the identity, that
+// is, when both know the code. Over it they exchange their real device IDs.
+package pairing
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/discover"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/relay/client"
+)
+
+const (
+	// codeChars is the number of characters in a code, giving 60 bits of
+	// entropy. The code only needs to hold out for as long as the
+	// offering side waits.
+	codeChars = 12
+	codeGroup = 4
+
+	exchangeTimeout = 30 * time.Second
+	relayTimeout    = 10 * time.Second
+	lookupInterval  = 10 * time.Second
+)
+
+var ErrInvalidCode = errors.New("invalid pairing code")
+
+// A Peer is what the two sides tell each other about themselves.
+type Peer struct {
+	DeviceID protocol.DeviceID `json:"deviceID"`
+	Name     string            `json:"name"`
+}
+
+// NewCode returns a new random code in the form XXXX-XXXX-XXXX.
+func NewCode() string {
+	var bs [10]byte
+	_, _ = rand.Read(bs[:])
+	code := base32.StdEncoding.EncodeToString(bs[:])[:codeChars]
+	return formatCode(code)
+}
+
+// NormalizeCode returns the code in canonical form, accepting lower case
+// and missing or misplaced dashes and spaces.
+func NormalizeCode(code string) (string, error) {
+	code = strings.ToUpper(code)
+	code = strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+	if len(code) != codeChars {
+		return "", ErrInvalidCode
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < '2' || r > '7') {
+			return "", ErrInvalidCode
+		}
+	}
+	return formatCode(code), nil
+}
+
+func formatCode(code string) string {
+	var groups []string
+	for i := 0; i < len(code); i += codeGroup {
+		groups = append(groups, code[i:i+codeGroup])
+	}
+	return strings.Join(groups, "-")
+}
+
+// Identity returns the certificate derived from the given (normalized)
+// code. It is the same every time for the same code.
+func Identity(code string) (tls.Certificate, error) {
+	seed, err := scrypt.Key([]byte(code), []byte("syncthing pairing"), 32768, 8, 1, ed25519.SeedSize)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	priv := ed25519.NewKeyFromSeed(seed)
+	serial := sha256.Sum256(seed)
+
+	// Everything in the certificate is fixed, and Ed25519 signatures are
+	// deterministic, so the certificate and thus the device ID depend
+	// only on the code.
+	template := x509.Certificate{
+		SerialNumber:          new(big.Int).SetBytes(serial[:8]),
+		Subject:               pkix.Name{CommonName: "syncthing-pairing"},
+		NotBefore:             time.Unix(0, 0).UTC(),
+		NotAfter:              time.Date(2099, 12, 31, 0, 0, 0, 0, time.UTC),
+		SignatureAlgorithm:    x509.PureEd25519,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, priv.Public(), priv)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: priv}, nil
+}
+
+// Offer makes the code reachable through the given relay (or relay pool)
+// and waits for the other side to connect and exchange details with us.
+func Offer(ctx context.Context, code string, relay *url.URL, discoveryServers []string, self Peer) (Peer, error) {
+	cert, err := Identity(code)
+	if err != nil {
+		return Peer{}, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rc, err := client.NewClient(relay, []tls.Certificate{cert}, relayTimeout)
+	if err != nil {
+		return Peer{}, err
+	}
+	go func() { _ = rc.Serve(ctx) }()
+
+	announced := false
+	addrs := &relayAddressLister{client: rc}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case inv := <-rc.Invitations():
+			conn, err := client.JoinSession(ctx, inv)
+			if err != nil {
+				slog.DebugContext(ctx, "Failed to join pairing session", slogutil.Error(err))
+				continue
+			}
+			peer, err := handshake(conn, cert, inv.ServerSocket, self)
+			if err != nil {
+				// Someone who doesn't know the code, or a network issue;
+				// keep waiting for the real thing.
+				slog.DebugContext(ctx, "Failed pairing handshake", slogutil.Error(err))
+				continue
+			}
+			return peer, nil
+
+		case <-ticker.C:
+			if announced || rc.URI() == nil || rc.Error() != nil {
+				continue
+			}
+			// We're on a relay, so tell the discovery servers about it
+			for _, srv := range discoveryServers {
+				disco, err := discover.NewGlobal(srv, cert, addrs, events.NoopLogger, nil)
+				if err != nil {
+					slog.DebugContext(ctx, "Skipping discovery server", "server", srv, slogutil.Error(err))
+					continue
+				}
+				go func() { _ = disco.Serve(ctx) }()
+			}
+			announced = true
+
+		case <-ctx.Done():
+			return Peer{}, ctx.Err()
+		}
+	}
+}
+
+// Accept connects to the side that offered the code and exchanges details
+// with it. It keeps looking until the offer shows up in global discovery
+// or the context is cancelled.
+func Accept(ctx context.Context, code string, discoveryServers []string, self Peer) (Peer, error) {
+	cert, err := Identity(code)
+	if err != nil {
+		return Peer{}, err
+	}
+	id := protocol.NewDeviceID(cert.Certificate[0])
+
+	var finders []discover.Finder
+	for _, srv := range discoveryServers {
+		disco, err := discover.NewGlobal(srv, cert, nil, events.NoopLogger, nil)
+		if err != nil {
+			slog.DebugContext(ctx, "Skipping discovery server", "server", srv, slogutil.Error(err))
+			continue
+		}
+		finders = append(finders, disco)
+	}
+	if len(finders) == 0 {
+		return Peer{}, errors.New("no usable global discovery servers")
+	}
+
+	for {
+		for _, finder := range finders {
+			addrs, err := finder.Lookup(ctx, id)
+			if err != nil {
+				slog.DebugContext(ctx, "Pairing lookup failed", "finder", finder, slogutil.Error(err))
+				continue
+			}
+			for _, addr := range addrs {
+				peer, err := acceptVia(ctx, addr, id, cert, self)
+				if err != nil {
+					slog.DebugContext(ctx, "Failed to pair via address", "address", addr, slogutil.Error(err))
+					continue
+				}
+				return peer, nil
+			}
+		}
+
+		select {
+		case <-time.After(lookupInterval):
+		case <-ctx.Done():
+			return Peer{}, ctx.Err()
+		}
+	}
+}
+
+func acceptVia(ctx context.Context, addr string, id protocol.DeviceID, cert tls.Certificate, self Peer) (Peer, error) {
+	uri, err := url.Parse(addr)
+	if err != nil {
+		return Peer{}, err
+	}
+	if uri.Scheme != "relay" {
+		return Peer{}, fmt.Errorf("unsupported address %q", addr)
+	}
+	inv, err := client.GetInvitationFromRelay(ctx, uri, id, []tls.Certificate{cert}, relayTimeout)
+	if err != nil {
+		return Peer{}, err
+	}
+	conn, err := client.JoinSession(ctx, inv)
+	if err != nil {
+		return Peer{}, err
+	}
+	return handshake(conn, cert, inv.ServerSocket, self)
+}
+
+// handshake sets up TLS over the relayed connection, requiring the other side
+// to present the same identity as us, and then exchanges details. The
+// connection is closed when done.
+func handshake(conn net.Conn, cert tls.Certificate, server bool, self Peer) (Peer, error) {
+	defer conn.Close()
+
+	id := protocol.NewDeviceID(cert.Certificate[0])
+	tlsCfg := &tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		ClientAuth:         tls.RequireAnyClientCert,
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS13,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) != 1 || protocol.NewDeviceID(rawCerts[0]) != id {
+				return errors.New("peer does not know the pairing code")
+			}
+			return nil
+		},
+	}
+	var tc *tls.Conn
+	if server {
+		tc = tls.Server(conn, tlsCfg)
+	} else {
+		tc = tls.Client(conn, tlsCfg)
+	}
+	_ = tc.SetDeadline(time.Now().Add(exchangeTimeout))
+	if err := tc.Handshake(); err != nil {
+		return Peer{}, err
+	}
+	return Exchange(tc, self)
+}
+
+// Exchange sends our details and reads the other side's over an
+// established connection.
+func Exchange(conn net.Conn, self Peer) (Peer, error) {
+	errC := make(chan error, 1)
+	go func() {
+		errC <- json.NewEncoder(conn).Encode(self)
+	}()
+
+	var peer Peer
+	if err := json.NewDecoder(conn).Decode(&peer); err != nil {
+		return Peer{}, fmt.Errorf("reading peer details: %w", err)
+	}
+	if err := <-errC; err != nil {
+		return Peer{}, fmt.Errorf("sending details: %w", err)
+	}
+	if peer.DeviceID == protocol.EmptyDeviceID {
+		return Peer{}, errors.New("peer sent no device ID")
+	}
+	if peer.DeviceID == self.DeviceID {
+		return Peer{}, errors.New("cannot pair a device with itself")
+	}
+	return peer, nil
+}
+
+// relayAddressLister provides the address of the relay we're currently on
+// for announcement.
+type relayAddressLister struct {
+	client client.RelayClient
+}
+
+func (l *relayAddressLister) ExternalAddresses() []string {
+	if uri := l.client.URI(); uri != nil {
+		return []string{uri.String()}
+	}
+	return nil
+}
+
+func (l *relayAddressLister) AllAddresses() []string {
+	return l.ExternalAddresses()
+}
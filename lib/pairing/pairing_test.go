@@ -0,0 +1,146 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package pairing
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestNormalizeCode(t *testing.T) {
+	t.Parallel()
+
+	code := NewCode()
+	if norm, err := NormalizeCode(code); err != nil || norm != code {
+		t.Fatalf("new code %q not normal: %q, %v", code, norm, err)
+	}
+
+	cases := []struct {
+		in, out string
+	}{
+		{"ABCD-EFGH-2345", "ABCD-EFGH-2345"},
+		{"abcd efgh 2345", "ABCD-EFGH-2345"},
+		{"abcdefgh2345", "ABCD-EFGH-2345"},
+		{"ABC-DEFG-H2345", "ABCD-EFGH-2345"},
+		{"ABCD-EFGH-234", ""},
+		{"ABCD-EFGH-2340", ""},
+		{"ABCD-EFGH-23456", ""},
+	}
+	for _, tc := range cases {
+		norm, err := NormalizeCode(tc.in)
+		if tc.out == "" {
+			if !errors.Is(err, ErrInvalidCode) {
+				t.Errorf("%q: expected invalid, got %q, %v", tc.in, norm, err)
+			}
+			continue
+		}
+		if err != nil || norm != tc.out {
+			t.Errorf("%q: expected %q, got %q, %v", tc.in, tc.out, norm, err)
+		}
+	}
+}
+
+func TestIdentityDeterministic(t *testing.T) {
+	t.Parallel()
+
+	a, err := Identity("ABCD-EFGH-2345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Identity("ABCD-EFGH-2345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Certificate[0], b.Certificate[0]) {
+		t.Error("same code should give the same certificate")
+	}
+	c, err := Identity("ABCD-EFGH-2346")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protocol.NewDeviceID(a.Certificate[0]) == protocol.NewDeviceID(c.Certificate[0]) {
+		t.Error("different codes should give different identities")
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	t.Parallel()
+
+	cert, err := Identity("ABCD-EFGH-2345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Identity("ABCD-EFGH-2346")
+	if err != nil {
+		t.Fatal(err)
+	}
+	offerer := Peer{DeviceID: protocol.DeviceID{1}, Name: "offerer"}
+	accepter := Peer{DeviceID: protocol.DeviceID{2}, Name: "accepter"}
+
+	t.Run("same code", func(t *testing.T) {
+		t.Parallel()
+		c0, c1 := tcpPipe(t)
+		resC := make(chan Peer, 1)
+		go func() {
+			peer, err := handshake(c0, cert, true, offerer)
+			if err != nil {
+				t.Error(err)
+			}
+			resC <- peer
+		}()
+		peer, err := handshake(c1, cert, false, accepter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if peer != offerer {
+			t.Errorf("accepter got %v", peer)
+		}
+		if peer := <-resC; peer != accepter {
+			t.Errorf("offerer got %v", peer)
+		}
+	})
+
+	t.Run("different code", func(t *testing.T) {
+		t.Parallel()
+		c0, c1 := tcpPipe(t)
+		errC := make(chan error, 1)
+		go func() {
+			_, err := handshake(c0, cert, true, offerer)
+			errC <- err
+		}()
+		if _, err := handshake(c1, other, false, accepter); err == nil {
+			t.Error("handshake with wrong code should fail")
+		}
+		if err := <-errC; err == nil {
+			t.Error("handshake with wrong code should fail")
+		}
+	})
+}
+
+// tcpPipe returns a connected pair of loopback TCP connections. Unlike
+// net.Pipe they are buffered, like a relayed connection.
+func tcpPipe(t *testing.T) (net.Conn, net.Conn) {
+	t.Helper()
+	lst, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lst.Close()
+	c0, err := net.Dial("tcp", lst.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1, err := lst.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c0, c1
+}
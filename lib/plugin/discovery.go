@@ -0,0 +1,56 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package plugin
+
+import (
+	"context"
+	"sync"
+
+	"github.com/syncthing/syncthing/internal/gen/pluginproto"
+	"github.com/syncthing/syncthing/lib/discover"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// A finder looks up device addresses through a plugin.
+type finder struct {
+	identity string
+	client   pluginproto.DiscoveryClient
+
+	mut sync.Mutex
+	err error
+}
+
+func newFinder(identity string, client pluginproto.DiscoveryClient) *finder {
+	return &finder{identity: identity, client: client}
+}
+
+func (f *finder) Lookup(ctx context.Context, deviceID protocol.DeviceID) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+	resp, err := f.client.Lookup(ctx, &pluginproto.LookupRequest{DeviceId: deviceID[:]})
+	f.mut.Lock()
+	f.err = err
+	f.mut.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return resp.Addresses, nil
+}
+
+func (f *finder) Error() error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	return f.err
+}
+
+func (f *finder) String() string {
+	return f.identity
+}
+
+func (*finder) Cache() map[protocol.DeviceID]discover.CacheEntry {
+	return nil
+}
@@ -0,0 +1,399 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package plugin runs plugins: separate processes that extend Syncthing by
+// serving the services in proto/pluginproto over gRPC on a Unix socket.
+//
+// A plugin is started with the command from its configuration, with the
+// ID and socket path in the ST_PLUGIN_ID and ST_PLUGIN_SOCKET environment
+// variables. It listens on the socket (see Listen) and serves the Plugin
+// service, whose Describe call tells what else it provides:
+//
+//   - Versioners, used by folders with versioning type "plugin" and the
+//     versioner name in the "versioner" parameter.
+//   - Filesystem wrappers, used by folders listing them in their
+//     filesystemWrappers, and asked before every modifying operation.
+//   - Discovery, asked for device addresses besides the global and local
+//     discovery.
+//   - Event consumers, which get the events of the given types.
+//
+// Plugins are restarted when they exit, and stopped when removed from the
+// configuration.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/thejerf/suture/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/syncthing/syncthing/internal/gen/pluginproto"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/build"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/discover"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/svcutil"
+)
+
+const (
+	// How long a plugin gets to start listening on its socket.
+	startTimeout = 30 * time.Second
+	// How long a call to a plugin may take.
+	callTimeout = 30 * time.Second
+)
+
+// Listen returns a listener on the socket Syncthing gave to this process,
+// for a plugin to serve its services on.
+func Listen() (net.Listener, error) {
+	socket := os.Getenv("ST_PLUGIN_SOCKET")
+	if socket == "" {
+		return nil, errors.New("ST_PLUGIN_SOCKET is not set; not started as a plugin?")
+	}
+	return net.Listen("unix", socket)
+}
+
+// The Manager runs the configured plugins.
+type Manager struct {
+	*suture.Supervisor
+
+	cfg        config.Wrapper
+	evLogger   events.Logger
+	discoverer discover.Manager
+
+	mut     sync.Mutex
+	plugins map[string]runningPlugin // by ID
+}
+
+type runningPlugin struct {
+	cfg   config.PluginConfiguration
+	token suture.ServiceToken
+}
+
+func NewManager(cfg config.Wrapper, evLogger events.Logger, discoverer discover.Manager) *Manager {
+	m := &Manager{
+		Supervisor: suture.New("plugin.Manager", svcutil.SpecWithDebugLogger()),
+		cfg:        cfg,
+		evLogger:   evLogger,
+		discoverer: discoverer,
+		plugins:    make(map[string]runningPlugin),
+	}
+	m.Add(svcutil.AsService(m.serve, m.String()))
+	return m
+}
+
+func (m *Manager) serve(ctx context.Context) error {
+	m.CommitConfiguration(config.Configuration{}, m.cfg.Subscribe(m))
+	<-ctx.Done()
+	m.cfg.Unsubscribe(m)
+	return nil
+}
+
+func (m *Manager) CommitConfiguration(_, to config.Configuration) bool {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	toPlugins := make(map[string]config.PluginConfiguration, len(to.Plugins))
+	for _, pcfg := range to.Plugins {
+		toPlugins[pcfg.ID] = pcfg
+	}
+
+	// Stop the plugins that are gone or changed, and start the new ones.
+	for id, running := range m.plugins {
+		if pcfg, ok := toPlugins[id]; !ok || pcfg != running.cfg {
+			if err := m.Remove(running.token); err != nil {
+				slog.Warn("Failed to stop plugin", "plugin", id, slogutil.Error(err))
+			}
+			delete(m.plugins, id)
+		}
+	}
+	for id, pcfg := range toPlugins {
+		if _, ok := m.plugins[id]; ok {
+			continue
+		}
+		p := &plugin{cfg: pcfg, evLogger: m.evLogger, discoverer: m.discoverer}
+		m.plugins[id] = runningPlugin{cfg: pcfg, token: m.Add(p)}
+	}
+
+	return true
+}
+
+func (*Manager) String() string {
+	return "plugin.Manager"
+}
+
+// A plugin runs the process of one plugin and registers what it provides.
+type plugin struct {
+	cfg        config.PluginConfiguration
+	evLogger   events.Logger
+	discoverer discover.Manager
+}
+
+func (p *plugin) Serve(ctx context.Context) error {
+	words, err := shellquote.Split(p.cfg.Command)
+	if err != nil {
+		return fmt.Errorf("plugin %s: command is invalid: %w", p.cfg.ID, err)
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("plugin %s: command is empty", p.cfg.ID)
+	}
+
+	// The socket is in a directory of its own, readable only by us.
+	dir, err := os.MkdirTemp("", "syncthing-plugin-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "plugin.sock")
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	output := &outputLogger{id: p.cfg.ID}
+	cmd := exec.CommandContext(runCtx, words[0], words[1:]...)
+	cmd.Env = append(pluginEnviron(), "ST_PLUGIN_ID="+p.cfg.ID, "ST_PLUGIN_SOCKET="+socket)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = time.Second
+
+	l.Debugln("starting plugin", p.cfg.ID, words)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("plugin %s: %w", p.cfg.ID, err)
+	}
+	proc := &process{done: make(chan struct{})}
+	go func() {
+		proc.err = cmd.Wait()
+		close(proc.done)
+	}()
+
+	err = p.run(runCtx, socket, proc)
+	cancel()
+	<-proc.done
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("plugin %s: %w", p.cfg.ID, err)
+	}
+	return nil
+}
+
+// A process is a started plugin process; err is set when done is closed.
+type process struct {
+	done chan struct{}
+	err  error
+}
+
+func (p *process) exitError(prefix string) error {
+	if p.err == nil {
+		return errors.New(prefix)
+	}
+	return fmt.Errorf("%s: %w", prefix, p.err)
+}
+
+// run connects to the started plugin and registers what it provides,
+// until it exits or the context is cancelled.
+func (p *plugin) run(ctx context.Context, socket string, proc *process) error {
+	if err := waitForSocket(ctx, socket, proc); err != nil {
+		return err
+	}
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	descCtx, cancel := context.WithTimeout(ctx, callTimeout)
+	desc, err := pluginproto.NewPluginClient(conn).Describe(descCtx, &pluginproto.DescribeRequest{
+		PluginId:         p.cfg.ID,
+		SyncthingVersion: build.Version,
+	})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("describe: %w", err)
+	}
+
+	defer p.register(conn, desc)()
+	slog.Info("Started plugin", "plugin", p.cfg.ID, "versioners", desc.Versioners, "filesystemWrappers", desc.FilesystemWrappers, "discovery", desc.Discovery, "events", desc.EventTypes)
+
+	if len(desc.EventTypes) > 0 {
+		go p.forwardEvents(ctx, pluginproto.NewEventConsumerClient(conn), desc.EventTypes)
+	}
+
+	select {
+	case <-proc.done:
+		return proc.exitError("exited")
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func waitForSocket(ctx context.Context, socket string, proc *process) error {
+	timeout := time.NewTimer(startTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if _, err := os.Stat(socket); err == nil {
+			return nil
+		}
+		select {
+		case <-proc.done:
+			return proc.exitError("exited before listening")
+		case <-timeout.C:
+			return errors.New("did not start listening in time")
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// register makes what the plugin provides available, returning a function
+// that unregisters it again.
+func (p *plugin) register(conn *grpc.ClientConn, desc *pluginproto.DescribeResponse) func() {
+	versionerClient := pluginproto.NewVersionerClient(conn)
+	for _, name := range desc.Versioners {
+		registerVersioner(name, p.cfg.ID, versionerClient)
+	}
+
+	wrapperClient := pluginproto.NewFilesystemWrapperClient(conn)
+	for _, name := range desc.FilesystemWrappers {
+		fs.RegisterInterceptor(name, interceptor(name, wrapperClient))
+	}
+
+	identity := "plugin " + p.cfg.ID
+	if desc.Discovery {
+		// Lookup results are cached like those of global discovery.
+		p.discoverer.AddFinder(identity, newFinder(identity, pluginproto.NewDiscoveryClient(conn)), 5*time.Minute, time.Minute)
+	}
+
+	return func() {
+		for _, name := range desc.Versioners {
+			unregisterVersioner(name, p.cfg.ID)
+		}
+		for _, name := range desc.FilesystemWrappers {
+			fs.UnregisterInterceptor(name)
+		}
+		if desc.Discovery {
+			p.discoverer.RemoveFinder(identity)
+		}
+	}
+}
+
+func interceptor(name string, client pluginproto.FilesystemWrapperClient) fs.Interceptor {
+	return func(op fs.Operation) error {
+		ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+		defer cancel()
+		resp, err := client.Intercept(ctx, &pluginproto.InterceptRequest{
+			Wrapper:   name,
+			Root:      op.Root,
+			Operation: op.Op,
+			Name:      op.Name,
+			NewName:   op.NewName,
+		})
+		if err != nil {
+			return fmt.Errorf("%s %s: filesystem wrapper %s: %w", op.Op, op.Name, name, err)
+		}
+		if resp.Deny {
+			reason := resp.Reason
+			if reason == "" {
+				reason = "operation not permitted"
+			}
+			return fmt.Errorf("%s %s: denied by filesystem wrapper %s: %s", op.Op, op.Name, name, reason)
+		}
+		return nil
+	}
+}
+
+func (p *plugin) forwardEvents(ctx context.Context, client pluginproto.EventConsumerClient, types []string) {
+	var mask events.EventType
+	for _, name := range types {
+		if t := events.UnmarshalEventType(name); t != 0 {
+			mask |= t
+		} else {
+			slog.Warn("Plugin wants unknown event type", "plugin", p.cfg.ID, "type", name)
+		}
+	}
+	if mask == 0 {
+		return
+	}
+
+	sub := p.evLogger.Subscribe(mask)
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case ev, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(ev.Data)
+			if err != nil {
+				continue
+			}
+			callCtx, cancel := context.WithTimeout(ctx, callTimeout)
+			_, err = client.Event(callCtx, &pluginproto.EventRequest{
+				Id:   int64(ev.GlobalID),
+				Type: ev.Type.String(),
+				Time: ev.Time.UnixNano(),
+				Data: data,
+			})
+			cancel()
+			if err != nil {
+				l.Debugln("plugin", p.cfg.ID, "event", ev.Type, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *plugin) String() string {
+	return fmt.Sprintf("plugin@%p:%s", p, p.cfg.ID)
+}
+
+// pluginEnviron returns our environment without the GUI credentials.
+func pluginEnviron() []string {
+	var env []string
+	for _, x := range os.Environ() {
+		if !strings.HasPrefix(x, "STGUIAUTH=") && !strings.HasPrefix(x, "STGUIAPIKEY=") {
+			env = append(env, x)
+		}
+	}
+	return env
+}
+
+// outputLogger logs the output of a plugin, line by line.
+type outputLogger struct {
+	id  string
+	buf []byte
+}
+
+func (w *outputLogger) Write(bs []byte) (int, error) {
+	w.buf = append(w.buf, bs...)
+	for {
+		line, rest, ok := bytes.Cut(w.buf, []byte("\n"))
+		if !ok {
+			break
+		}
+		slog.Info("Plugin output", "plugin", w.id, "line", string(line))
+		w.buf = rest
+	}
+	return len(bs), nil
+}
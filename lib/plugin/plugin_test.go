@@ -0,0 +1,161 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package plugin
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	"google.golang.org/grpc"
+
+	"github.com/syncthing/syncthing/internal/gen/pluginproto"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/discover/mocks"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// The test binary doubles as the plugin, when started as one by the test.
+func TestMain(m *testing.M) {
+	if os.Getenv("STPLUGIN_TEST_HELPER") != "" && os.Getenv("ST_PLUGIN_SOCKET") != "" {
+		if err := serveTestPlugin(); err != nil {
+			os.Stderr.WriteString(err.Error() + "\n")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type testPlugin struct {
+	pluginproto.UnimplementedPluginServer
+	pluginproto.UnimplementedVersionerServer
+	pluginproto.UnimplementedFilesystemWrapperServer
+	pluginproto.UnimplementedDiscoveryServer
+}
+
+func serveTestPlugin() error {
+	lst, err := Listen()
+	if err != nil {
+		return err
+	}
+	srv := grpc.NewServer()
+	p := &testPlugin{}
+	pluginproto.RegisterPluginServer(srv, p)
+	pluginproto.RegisterVersionerServer(srv, p)
+	pluginproto.RegisterFilesystemWrapperServer(srv, p)
+	pluginproto.RegisterDiscoveryServer(srv, p)
+	return srv.Serve(lst)
+}
+
+func (*testPlugin) Describe(context.Context, *pluginproto.DescribeRequest) (*pluginproto.DescribeResponse, error) {
+	return &pluginproto.DescribeResponse{
+		Versioners:         []string{"testversioner"},
+		FilesystemWrappers: []string{"testwrapper"},
+		Discovery:          true,
+	}, nil
+}
+
+func (*testPlugin) GetVersions(_ context.Context, req *pluginproto.GetVersionsRequest) (*pluginproto.GetVersionsResponse, error) {
+	return &pluginproto.GetVersionsResponse{Files: []*pluginproto.FileVersions{{
+		Name:     req.Folder.FolderId + "/" + req.Folder.Params["extra"],
+		Versions: []*pluginproto.FileVersion{{VersionTime: 1e18, Size: 42}},
+	}}}, nil
+}
+
+func (*testPlugin) Intercept(_ context.Context, req *pluginproto.InterceptRequest) (*pluginproto.InterceptResponse, error) {
+	if req.Name == "protected" {
+		return &pluginproto.InterceptResponse{Deny: true, Reason: "protected by test"}, nil
+	}
+	return &pluginproto.InterceptResponse{}, nil
+}
+
+func (*testPlugin) Lookup(_ context.Context, req *pluginproto.LookupRequest) (*pluginproto.LookupResponse, error) {
+	if id, err := protocol.DeviceIDFromBytes(req.DeviceId); err == nil && id == protocol.LocalDeviceID {
+		return &pluginproto.LookupResponse{Addresses: []string{"tcp://192.0.2.42:22000"}}, nil
+	}
+	return &pluginproto.LookupResponse{}, nil
+}
+
+func TestPlugin(t *testing.T) {
+	t.Setenv("STPLUGIN_TEST_HELPER", "1")
+	discoverer := &mocks.Manager{}
+	p := &plugin{
+		cfg:        config.PluginConfiguration{ID: "test", Command: shellquote.Join(os.Args[0])},
+		evLogger:   events.NoopLogger,
+		discoverer: discoverer,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- p.Serve(ctx)
+	}()
+
+	vcfg := config.FolderConfiguration{ID: "f1", Versioning: config.VersioningConfiguration{
+		Type:   "plugin",
+		Params: map[string]string{"versioner": "testversioner", "extra": "param"},
+	}}
+	ver := newVersioner(vcfg)
+	deadline := time.Now().Add(20 * time.Second)
+	for discoverer.AddFinderCallCount() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("plugin did not register")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	got, err := ver.GetVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fv := got["f1/param"]; len(fv) != 1 || fv[0].Size != 42 || !fv[0].VersionTime.Equal(time.Unix(0, 1e18)) {
+		t.Errorf("unexpected versions %v", got)
+	}
+
+	ffs := fs.NewFilesystem(fs.FilesystemTypeFake, "/plugintest", fs.NewInterceptOption("testwrapper"))
+	if err := ffs.Mkdir("allowed", 0o755); err != nil {
+		t.Error(err)
+	}
+	if err := ffs.Mkdir("protected", 0o755); err == nil {
+		t.Error("expected the wrapper to deny the operation")
+	}
+
+	identity, finder, _, _ := discoverer.AddFinderArgsForCall(0)
+	addrs, err := finder.Lookup(ctx, protocol.LocalDeviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(addrs, []string{"tcp://192.0.2.42:22000"}) {
+		t.Errorf("unexpected addresses %v", addrs)
+	}
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("plugin did not stop")
+	}
+
+	if discoverer.RemoveFinderCallCount() != 1 || discoverer.RemoveFinderArgsForCall(0) != identity {
+		t.Error("finder should be removed")
+	}
+	if _, err := ver.GetVersions(); err == nil {
+		t.Error("versioner should not be available after stopping")
+	}
+	if err := ffs.Mkdir("other", 0o755); err == nil {
+		t.Error("wrapper should not be available after stopping")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/gen/pluginproto"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/versioner"
+)
+
+func init() {
+	// Folders use a plugin versioner with versioning type "plugin", naming
+	// the versioner in the "versioner" parameter.
+	versioner.Register("plugin", newVersioner)
+}
+
+type registeredVersioner struct {
+	pluginID string
+	client   pluginproto.VersionerClient
+}
+
+var (
+	versioners    = make(map[string]registeredVersioner)
+	versionersMut sync.RWMutex
+)
+
+func registerVersioner(name, pluginID string, client pluginproto.VersionerClient) {
+	versionersMut.Lock()
+	defer versionersMut.Unlock()
+	if cur, ok := versioners[name]; ok && cur.pluginID != pluginID {
+		slog.Warn("Versioner is provided by more than one plugin; using the latest", "versioner", name, "plugin", pluginID, "previous", cur.pluginID)
+	}
+	versioners[name] = registeredVersioner{pluginID: pluginID, client: client}
+}
+
+func unregisterVersioner(name, pluginID string) {
+	versionersMut.Lock()
+	defer versionersMut.Unlock()
+	if versioners[name].pluginID == pluginID {
+		delete(versioners, name)
+	}
+}
+
+// A pluginVersioner passes the calls for a folder on to the plugin
+// providing the versioner, as long as there is one.
+type pluginVersioner struct {
+	folder *pluginproto.VersionedFolder
+}
+
+func newVersioner(cfg config.FolderConfiguration) versioner.Versioner {
+	return &pluginVersioner{
+		folder: &pluginproto.VersionedFolder{
+			Versioner:  cfg.Versioning.Params["versioner"],
+			FolderId:   cfg.ID,
+			FolderPath: cfg.Path,
+			Params:     cfg.Versioning.Params,
+		},
+	}
+}
+
+func (v *pluginVersioner) client() (pluginproto.VersionerClient, error) {
+	versionersMut.RLock()
+	defer versionersMut.RUnlock()
+	reg, ok := versioners[v.folder.Versioner]
+	if !ok {
+		return nil, fmt.Errorf("plugin versioner %q is not available", v.folder.Versioner)
+	}
+	return reg.client, nil
+}
+
+func (v *pluginVersioner) Archive(filePath string) error {
+	client, err := v.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	_, err = client.Archive(ctx, &pluginproto.ArchiveRequest{Folder: v.folder, Name: filePath})
+	return err
+}
+
+func (v *pluginVersioner) GetVersions() (map[string][]versioner.FileVersion, error) {
+	client, err := v.client()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := client.GetVersions(ctx, &pluginproto.GetVersionsRequest{Folder: v.folder})
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]versioner.FileVersion, len(resp.Files))
+	for _, file := range resp.Files {
+		versions := make([]versioner.FileVersion, len(file.Versions))
+		for i, fv := range file.Versions {
+			versions[i] = versioner.FileVersion{
+				VersionTime: time.Unix(0, fv.VersionTime),
+				ModTime:     time.Unix(0, fv.ModTime),
+				Size:        fv.Size,
+			}
+		}
+		files[file.Name] = versions
+	}
+	return files, nil
+}
+
+// OpenVersion isn't part of the plugin protocol; versions are only
+// available through restoring them.
+func (*pluginVersioner) OpenVersion(_ string, _ time.Time) (fs.File, error) {
+	return nil, versioner.ErrRestorationNotSupported
+}
+
+func (v *pluginVersioner) Restore(filePath string, versionTime time.Time) error {
+	client, err := v.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	_, err = client.Restore(ctx, &pluginproto.RestoreRequest{Folder: v.folder, Name: filePath, VersionTime: versionTime.UnixNano()})
+	return err
+}
+
+func (v *pluginVersioner) Clean(ctx context.Context) error {
+	client, err := v.client()
+	if err != nil {
+		return err
+	}
+	_, err = client.Clean(ctx, &pluginproto.CleanRequest{Folder: v.folder})
+	return err
+}
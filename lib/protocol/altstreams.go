@@ -0,0 +1,75 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"bytes"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+)
+
+// AlternateStreamData holds the NTFS alternate data streams of an item,
+// sorted by name. The unnamed stream holding the contents of a file is not
+// among them.
+type AlternateStreamData struct {
+	Streams []AlternateStream
+}
+
+type AlternateStream struct {
+	Name string
+	Data []byte
+}
+
+func (a *AlternateStreamData) toWire() *bep.AlternateStreamData {
+	if a == nil {
+		return nil
+	}
+	streams := make([]*bep.AlternateStream, len(a.Streams))
+	for i, s := range a.Streams {
+		streams[i] = &bep.AlternateStream{
+			Name: s.Name,
+			Data: s.Data,
+		}
+	}
+	return &bep.AlternateStreamData{
+		Streams: streams,
+	}
+}
+
+func alternateStreamDataFromWire(w *bep.AlternateStreamData) *AlternateStreamData {
+	if w == nil {
+		return nil
+	}
+	a := &AlternateStreamData{}
+	a.Streams = make([]AlternateStream, len(w.Streams))
+	for i, s := range w.Streams {
+		a.Streams[i] = AlternateStream{
+			Name: s.Name,
+			Data: s.Data,
+		}
+	}
+	return a
+}
+
+func alternateStreamsEqual(a, b *AlternateStreamData) bool {
+	var as, bs []AlternateStream
+	if a != nil {
+		as = a.Streams
+	}
+	if b != nil {
+		bs = b.Streams
+	}
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if as[i].Name != bs[i].Name || !bytes.Equal(as[i].Data, bs[i].Data) {
+			return false
+		}
+	}
+	return true
+}
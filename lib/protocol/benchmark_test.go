@@ -198,3 +198,7 @@ func (*fakeModel) Closed(Connection, error) {
 func (*fakeModel) DownloadProgress(Connection, *DownloadProgress) error {
 	return nil
 }
+
+func (*fakeModel) ManagementRequest(Connection, *ManagementRequest) ([]byte, error) {
+	return nil, nil
+}
@@ -72,7 +72,22 @@ type Folder struct {
 	Label      string
 	Type       FolderType
 	StopReason FolderStopReason
-	Devices    []Device
+	// AvailableBytes is the disk space the sender can still use for the
+	// folder, zero if unknown and negative if it is out of space.
+	AvailableBytes int64
+	// Path is the local path of the folder on the sender, and
+	// MarkerPresent whether the folder marker exists there. Both are only
+	// set when the sender advertises its folder paths.
+	Path          string
+	MarkerPresent bool
+	// ForwardsRequests is set when the sender fetches blocks it doesn't
+	// have from other devices when asked for them.
+	ForwardsRequests bool
+	// CompressedEncryption is set when the sender handles blocks
+	// compressed before encryption for untrusted devices in the folder.
+	// The protocol layer sets it on the folders it sends.
+	CompressedEncryption bool
+	Devices              []Device
 }
 
 func (f *Folder) toWire() *bep.Folder {
@@ -81,11 +96,16 @@ func (f *Folder) toWire() *bep.Folder {
 		devices[i] = d.toWire()
 	}
 	return &bep.Folder{
-		Id:         f.ID,
-		Label:      f.Label,
-		Type:       bep.FolderType(f.Type),
-		StopReason: bep.FolderStopReason(f.StopReason),
-		Devices:    devices,
+		Id:                   f.ID,
+		Label:                f.Label,
+		Type:                 bep.FolderType(f.Type),
+		StopReason:           bep.FolderStopReason(f.StopReason),
+		AvailableBytes:       f.AvailableBytes,
+		Path:                 f.Path,
+		MarkerPresent:        f.MarkerPresent,
+		ForwardsRequests:     f.ForwardsRequests,
+		CompressedEncryption: f.CompressedEncryption,
+		Devices:              devices,
 	}
 }
 
@@ -95,11 +115,16 @@ func folderFromWire(w *bep.Folder) Folder {
 		devices[i] = deviceFromWire(d)
 	}
 	return Folder{
-		ID:         w.Id,
-		Label:      w.Label,
-		Type:       FolderType(w.Type),
-		StopReason: FolderStopReason(w.StopReason),
-		Devices:    devices,
+		ID:                   w.Id,
+		Label:                w.Label,
+		Type:                 FolderType(w.Type),
+		StopReason:           FolderStopReason(w.StopReason),
+		AvailableBytes:       w.AvailableBytes,
+		Path:                 w.Path,
+		MarkerPresent:        w.MarkerPresent,
+		ForwardsRequests:     w.ForwardsRequests,
+		CompressedEncryption: w.CompressedEncryption,
+		Devices:              devices,
 	}
 }
 
@@ -138,6 +163,12 @@ type Device struct {
 	IndexID                  IndexID
 	SkipIntroductionRemovals bool
 	EncryptionPasswordToken  []byte
+	// The token of the password being rotated away from, while the data
+	// is re-encrypted with the new one.
+	PreviousEncryptionPasswordToken []byte
+	// The device only receives the folder; index data announcing changes
+	// made by it are refused.
+	ReadOnly bool
 }
 
 func (d *Device) toWire() *bep.Device {
@@ -152,6 +183,9 @@ func (d *Device) toWire() *bep.Device {
 		IndexId:                  uint64(d.IndexID),
 		SkipIntroductionRemovals: d.SkipIntroductionRemovals,
 		EncryptionPasswordToken:  d.EncryptionPasswordToken,
+
+		PreviousEncryptionPasswordToken: d.PreviousEncryptionPasswordToken,
+		ReadOnly:                        d.ReadOnly,
 	}
 }
 
@@ -167,5 +201,8 @@ func deviceFromWire(w *bep.Device) Device {
 		IndexID:                  IndexID(w.IndexId),
 		SkipIntroductionRemovals: w.SkipIntroductionRemovals,
 		EncryptionPasswordToken:  w.EncryptionPasswordToken,
+
+		PreviousEncryptionPasswordToken: w.PreviousEncryptionPasswordToken,
+		ReadOnly:                        w.ReadOnly,
 	}
 }
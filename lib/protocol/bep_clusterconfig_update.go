@@ -0,0 +1,101 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+)
+
+// Devices that accept them are sent cluster config updates, holding only
+// the folders that changed since the previous cluster config, instead of
+// all of them every time. Both ends remember the last cluster config, the
+// sender to compute the update and the receiver to apply it, so that the
+// model always sees the full cluster config.
+
+// clusterConfigUpdate returns the update taking a peer from the previous
+// cluster config to the next, or nil when the full cluster config should
+// be sent instead.
+func clusterConfigUpdate(prev, next *bep.ClusterConfig) *bep.ClusterConfigUpdate {
+	// An update carries only folders; anything else that changed needs the
+	// full cluster config.
+	if !proto.Equal(withoutFolders(prev), withoutFolders(next)) {
+		return nil
+	}
+
+	prevFolders := make(map[string]*bep.Folder, len(prev.Folders))
+	for _, f := range prev.Folders {
+		prevFolders[f.Id] = f
+	}
+
+	up := &bep.ClusterConfigUpdate{}
+	for _, f := range next.Folders {
+		if pf, ok := prevFolders[f.Id]; !ok || !proto.Equal(pf, f) {
+			up.Folders = append(up.Folders, f)
+		}
+		delete(prevFolders, f.Id)
+	}
+	for _, f := range prev.Folders {
+		if _, ok := prevFolders[f.Id]; ok {
+			up.RemovedFolders = append(up.RemovedFolders, f.Id)
+		}
+	}
+
+	if proto.Size(up) >= proto.Size(next) {
+		return nil
+	}
+	return up
+}
+
+// applyClusterConfigUpdate returns the cluster config resulting from the
+// update to the previous one, which is not modified.
+func applyClusterConfigUpdate(prev *bep.ClusterConfig, up *bep.ClusterConfigUpdate) *bep.ClusterConfig {
+	changed := make(map[string]*bep.Folder, len(up.Folders))
+	for _, f := range up.Folders {
+		changed[f.Id] = f
+	}
+	removed := make(map[string]struct{}, len(up.RemovedFolders))
+	for _, id := range up.RemovedFolders {
+		removed[id] = struct{}{}
+	}
+
+	next := withoutFolders(prev)
+	next.Folders = make([]*bep.Folder, 0, len(prev.Folders)+len(up.Folders))
+	for _, f := range prev.Folders {
+		if _, ok := removed[f.Id]; ok {
+			continue
+		}
+		if cf, ok := changed[f.Id]; ok {
+			f = cf
+			delete(changed, f.Id)
+		}
+		next.Folders = append(next.Folders, f)
+	}
+	for _, f := range up.Folders {
+		if _, ok := changed[f.Id]; ok {
+			next.Folders = append(next.Folders, f)
+		}
+	}
+	return next
+}
+
+// withoutFolders returns a cluster config with everything but the folders
+// of the given one, including fields unknown to us.
+func withoutFolders(cc *bep.ClusterConfig) *bep.ClusterConfig {
+	c := &bep.ClusterConfig{}
+	src, dst := cc.ProtoReflect(), c.ProtoReflect()
+	src.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Name() != "folders" {
+			dst.Set(fd, v)
+		}
+		return true
+	})
+	dst.SetUnknown(src.GetUnknown())
+	return c
+}
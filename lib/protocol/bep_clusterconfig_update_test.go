@@ -0,0 +1,94 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+)
+
+func testClusterConfigFolders(ids ...string) []*bep.Folder {
+	folders := make([]*bep.Folder, len(ids))
+	for i, id := range ids {
+		folders[i] = &bep.Folder{
+			Id:    id,
+			Label: "Folder " + id,
+			Devices: []*bep.Device{
+				{Id: c0ID[:], Name: "c0", MaxSequence: 100},
+				{Id: c1ID[:], Name: "c1", MaxSequence: 200},
+			},
+		}
+	}
+	return folders
+}
+
+func TestClusterConfigUpdate(t *testing.T) {
+	t.Parallel()
+
+	var ids []string
+	for i := range 20 {
+		ids = append(ids, fmt.Sprint("f", i))
+	}
+	prev := &bep.ClusterConfig{
+		Folders:           testClusterConfigFolders(ids...),
+		AcceptsUpdates:    true,
+		IndexDictionaries: []uint32{1, 2},
+		AnswersPings:      true,
+		AcceptsDraining:   true,
+	}
+
+	// One folder changed, one removed and one added.
+	next := &bep.ClusterConfig{
+		Folders:           testClusterConfigFolders(slices.Concat(ids[:10], ids[11:], []string{"new"})...),
+		AcceptsUpdates:    true,
+		IndexDictionaries: []uint32{1, 2},
+		AnswersPings:      true,
+		AcceptsDraining:   true,
+	}
+	next.Folders[3].Devices[1].MaxSequence++
+
+	up := clusterConfigUpdate(prev, next)
+	if up == nil {
+		t.Fatal("expected an update")
+	}
+	if len(up.Folders) != 2 || up.Folders[0].Id != "f3" || up.Folders[1].Id != "new" {
+		t.Error("unexpected folders in update", up.Folders)
+	}
+	if !slices.Equal(up.RemovedFolders, []string{"f10"}) {
+		t.Error("unexpected removed folders in update", up.RemovedFolders)
+	}
+
+	if res := applyClusterConfigUpdate(prev, up); !proto.Equal(res, next) {
+		t.Error("applying the update did not result in the next cluster config")
+	}
+	if len(prev.Folders) != len(ids) || prev.Folders[3].Devices[1].MaxSequence != 200 {
+		t.Error("applying the update modified the previous cluster config")
+	}
+
+	// An update where everything changed is no smaller than the full
+	// cluster config.
+	if up := clusterConfigUpdate(prev, &bep.ClusterConfig{Folders: testClusterConfigFolders("a", "b")}); up != nil {
+		t.Error("expected no update when everything changed, got", up)
+	}
+	// Secondary connections always get the full cluster config, as does
+	// any change outside of the folders.
+	secondary := withoutFolders(prev)
+	secondary.Folders, secondary.Secondary = prev.Folders, true
+	if up := clusterConfigUpdate(prev, secondary); up != nil {
+		t.Error("expected no update when turning secondary, got", up)
+	}
+	dictionaries := withoutFolders(prev)
+	dictionaries.Folders, dictionaries.IndexDictionaries = prev.Folders, []uint32{3}
+	if up := clusterConfigUpdate(prev, dictionaries); up != nil {
+		t.Error("expected no update when the dictionaries changed, got", up)
+	}
+}
@@ -120,6 +120,7 @@ type FileInfo struct {
 	Version            Vector
 	Sequence           int64
 	Blocks             []BlockInfo
+	Holes              []Hole
 	SymlinkTarget      []byte
 	BlocksHash         []byte
 	PreviousBlocksHash []byte
@@ -161,6 +162,13 @@ func (f *FileInfo) ToWire(withInternalFields bool) *bep.FileInfo {
 	for j, b := range f.Blocks {
 		blocks[j] = b.ToWire()
 	}
+	var holes []*bep.Hole
+	if len(f.Holes) > 0 {
+		holes = make([]*bep.Hole, len(f.Holes))
+		for j, h := range f.Holes {
+			holes[j] = h.toWire()
+		}
+	}
 	w := &bep.FileInfo{
 		Name:               f.Name,
 		Size:               f.Size,
@@ -169,6 +177,7 @@ func (f *FileInfo) ToWire(withInternalFields bool) *bep.FileInfo {
 		Version:            f.Version.ToWire(),
 		Sequence:           f.Sequence,
 		Blocks:             blocks,
+		Holes:              holes,
 		SymlinkTarget:      f.SymlinkTarget,
 		BlocksHash:         f.BlocksHash,
 		PreviousBlocksHash: f.PreviousBlocksHash,
@@ -263,7 +272,14 @@ func FileInfoFromWire(w *bep.FileInfo) FileInfo {
 			blocks[j] = BlockInfoFromWire(b)
 		}
 	}
-	return fileInfoFromWireWithBlocks(w, blocks)
+	f := fileInfoFromWireWithBlocks(w, blocks)
+	if len(w.Holes) > 0 {
+		f.Holes = make([]Hole, len(w.Holes))
+		for j, h := range w.Holes {
+			f.Holes[j] = holeFromWire(h)
+		}
+	}
+	return f
 }
 
 type FileInfoWithoutBlocks interface {
@@ -471,12 +487,14 @@ func (f FileInfo) FileBlocksHash() []byte {
 }
 
 type FileInfoComparison struct {
-	ModTimeWindow   time.Duration
-	IgnorePerms     bool
-	IgnoreBlocks    bool
-	IgnoreFlags     FlagLocal
-	IgnoreOwnership bool
-	IgnoreXattrs    bool
+	ModTimeWindow          time.Duration
+	IgnorePerms            bool
+	IgnoreBlocks           bool
+	IgnoreFlags            FlagLocal
+	IgnoreOwnership        bool
+	IgnoreXattrs           bool
+	IgnorePosixACLs        bool
+	IgnoreAlternateStreams bool
 }
 
 func (f FileInfo) IsEquivalent(other FileInfo, modTimeWindow time.Duration) bool {
@@ -550,6 +568,12 @@ func (f FileInfo) isEquivalent(other FileInfo, comp FileInfoComparison) bool {
 			return false
 		}
 	}
+	if !comp.IgnorePosixACLs && !posixACLsEqual(f.Platform.PosixACL, other.Platform.PosixACL) {
+		return false
+	}
+	if !comp.IgnoreAlternateStreams && !alternateStreamsEqual(f.Platform.AlternateStreams, other.Platform.AlternateStreams) {
+		return false
+	}
 
 	if !comp.IgnorePerms && !f.NoPermissions && !other.NoPermissions && !PermsEqual(f.Permissions, other.Permissions) {
 		return false
@@ -628,6 +652,7 @@ func (f *FileInfo) setLocalFlags(flags FlagLocal) {
 
 func (f *FileInfo) setNoContent() {
 	f.Blocks = nil
+	f.Holes = nil
 	f.BlocksHash = nil
 	f.Size = 0
 }
@@ -654,6 +679,27 @@ func BlockInfoFromWire(w *bep.BlockInfo) BlockInfo {
 	}
 }
 
+// A Hole is a region of a sparse file that has no data allocated on disk,
+// reading as zeroes.
+type Hole struct {
+	Offset int64
+	Size   int64
+}
+
+func (h Hole) toWire() *bep.Hole {
+	return &bep.Hole{
+		Offset: h.Offset,
+		Size:   h.Size,
+	}
+}
+
+func holeFromWire(w *bep.Hole) Hole {
+	return Hole{
+		Offset: w.Offset,
+		Size:   w.Size,
+	}
+}
+
 func (b BlockInfo) String() string {
 	return fmt.Sprintf("Block{%d/%d/%x}", b.Offset, b.Size, b.Hash)
 }
@@ -767,6 +813,12 @@ func (p *PlatformData) MergeWith(other *PlatformData) {
 	if p.NetBSD == nil {
 		p.NetBSD = other.NetBSD
 	}
+	if p.PosixACL == nil {
+		p.PosixACL = other.PosixACL
+	}
+	if p.AlternateStreams == nil {
+		p.AlternateStreams = other.AlternateStreams
+	}
 }
 
 // blocksEqual returns whether two slices of blocks are exactly the same hash
@@ -792,16 +844,21 @@ type PlatformData struct {
 	Darwin  *XattrData
 	FreeBSD *XattrData
 	NetBSD  *XattrData
+
+	PosixACL         *PosixACL
+	AlternateStreams *AlternateStreamData
 }
 
 func (p *PlatformData) toWire() *bep.PlatformData {
 	return &bep.PlatformData{
-		Unix:    p.Unix.toWire(),
-		Windows: p.Windows,
-		Linux:   p.Linux.toWire(),
-		Darwin:  p.Darwin.toWire(),
-		Freebsd: p.FreeBSD.toWire(),
-		Netbsd:  p.NetBSD.toWire(),
+		Unix:             p.Unix.toWire(),
+		Windows:          p.Windows,
+		Linux:            p.Linux.toWire(),
+		Darwin:           p.Darwin.toWire(),
+		Freebsd:          p.FreeBSD.toWire(),
+		Netbsd:           p.NetBSD.toWire(),
+		PosixAcl:         p.PosixACL.toWire(),
+		AlternateStreams: p.AlternateStreams.toWire(),
 	}
 }
 
@@ -810,12 +867,14 @@ func platformDataFromWire(w *bep.PlatformData) PlatformData {
 		return PlatformData{}
 	}
 	return PlatformData{
-		Unix:    unixDataFromWire(w.Unix),
-		Windows: w.Windows,
-		Linux:   xattrDataFromWire(w.Linux),
-		Darwin:  xattrDataFromWire(w.Darwin),
-		FreeBSD: xattrDataFromWire(w.Freebsd),
-		NetBSD:  xattrDataFromWire(w.Netbsd),
+		Unix:             unixDataFromWire(w.Unix),
+		Windows:          w.Windows,
+		Linux:            xattrDataFromWire(w.Linux),
+		Darwin:           xattrDataFromWire(w.Darwin),
+		FreeBSD:          xattrDataFromWire(w.Freebsd),
+		NetBSD:           xattrDataFromWire(w.Netbsd),
+		PosixACL:         posixACLFromWire(w.PosixAcl),
+		AlternateStreams: alternateStreamDataFromWire(w.AlternateStreams),
 	}
 }
 
@@ -36,6 +36,7 @@ type Hello struct {
 	ClientVersion  string
 	NumConnections int
 	Timestamp      int64
+	Moved          bool
 }
 
 func (h *Hello) toWire() *bep.Hello {
@@ -45,6 +46,7 @@ func (h *Hello) toWire() *bep.Hello {
 		ClientVersion:  h.ClientVersion,
 		NumConnections: int32(h.NumConnections),
 		Timestamp:      h.Timestamp,
+		Moved:          h.Moved,
 	}
 }
 
@@ -55,6 +57,7 @@ func helloFromWire(w *bep.Hello) Hello {
 		ClientVersion:  w.ClientVersion,
 		NumConnections: int(w.NumConnections),
 		Timestamp:      w.Timestamp,
+		Moved:          w.Moved,
 	}
 }
 
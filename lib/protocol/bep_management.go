@@ -0,0 +1,76 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"errors"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+)
+
+type ManagementOperation = bep.ManagementOperation
+
+const (
+	ManagementOperationStatus    = bep.ManagementOperation_MANAGEMENT_OPERATION_STATUS
+	ManagementOperationGetConfig = bep.ManagementOperation_MANAGEMENT_OPERATION_GET_CONFIG
+	ManagementOperationSetConfig = bep.ManagementOperation_MANAGEMENT_OPERATION_SET_CONFIG
+)
+
+// A ManagementRequest asks the remote device to perform a management
+// operation on our behalf. The body is operation specific, typically JSON.
+type ManagementRequest struct {
+	ID        int
+	Operation ManagementOperation
+	Body      []byte
+}
+
+func (r *ManagementRequest) toWire() *bep.ManagementRequest {
+	return &bep.ManagementRequest{
+		Id:        int32(r.ID),
+		Operation: r.Operation,
+		Body:      r.Body,
+	}
+}
+
+func managementRequestFromWire(w *bep.ManagementRequest) *ManagementRequest {
+	return &ManagementRequest{
+		ID:        int(w.Id),
+		Operation: w.Operation,
+		Body:      w.Body,
+	}
+}
+
+// A ManagementResponse carries the result of a ManagementRequest. A
+// non-empty Error means the operation failed or was refused.
+type ManagementResponse struct {
+	ID    int
+	Body  []byte
+	Error string
+}
+
+func (r *ManagementResponse) toWire() *bep.ManagementResponse {
+	return &bep.ManagementResponse{
+		Id:    int32(r.ID),
+		Body:  r.Body,
+		Error: r.Error,
+	}
+}
+
+func managementResponseFromWire(w *bep.ManagementResponse) *ManagementResponse {
+	return &ManagementResponse{
+		ID:    int(w.Id),
+		Body:  w.Body,
+		Error: w.Error,
+	}
+}
+
+func (r *ManagementResponse) err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return errors.New(r.Error)
+}
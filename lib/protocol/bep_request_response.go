@@ -26,6 +26,12 @@ type Request struct {
 	Hash          []byte
 	FromTemporary bool
 	BlockNo       int
+	// Interactive requests are made on behalf of the user and served
+	// ahead of regular syncing on both ends of the connection.
+	Interactive bool
+	// Forwarded requests are made on behalf of another device and are
+	// not forwarded again.
+	Forwarded bool
 }
 
 func (r *Request) toWire() *bep.Request {
@@ -38,6 +44,8 @@ func (r *Request) toWire() *bep.Request {
 		Hash:          r.Hash,
 		FromTemporary: r.FromTemporary,
 		BlockNo:       int32(r.BlockNo),
+		Interactive:   r.Interactive,
+		Forwarded:     r.Forwarded,
 	}
 }
 
@@ -51,6 +59,8 @@ func requestFromWire(w *bep.Request) *Request {
 		Hash:          w.Hash,
 		FromTemporary: w.FromTemporary,
 		BlockNo:       int(w.BlockNo),
+		Interactive:   w.Interactive,
+		Forwarded:     w.Forwarded,
 	}
 }
 
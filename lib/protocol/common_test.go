@@ -20,6 +20,8 @@ type TestModel struct {
 	fromTemporary bool
 	indexFn       func(string, []FileInfo)
 	ccFn          func(*ClusterConfig)
+	requestFn     func(*Request)
+	managementFn  func(*ManagementRequest) ([]byte, error)
 	closedCh      chan struct{}
 	closedErr     error
 }
@@ -42,6 +44,9 @@ func (*TestModel) IndexUpdate(Connection, *IndexUpdate) error {
 }
 
 func (t *TestModel) Request(_ Connection, req *Request) (RequestResponse, error) {
+	if t.requestFn != nil {
+		t.requestFn(req)
+	}
 	t.folder = req.Folder
 	t.name = req.Name
 	t.offset = req.Offset
@@ -69,6 +74,13 @@ func (*TestModel) DownloadProgress(Connection, *DownloadProgress) error {
 	return nil
 }
 
+func (t *TestModel) ManagementRequest(_ Connection, req *ManagementRequest) ([]byte, error) {
+	if t.managementFn != nil {
+		return t.managementFn(req)
+	}
+	return nil, nil
+}
+
 func (t *TestModel) closedError() error {
 	select {
 	case <-t.closedCh:
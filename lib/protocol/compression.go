@@ -0,0 +1,69 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+)
+
+// CompressionAlgorithm is how the messages a connection compresses are
+// compressed.
+type CompressionAlgorithm int
+
+const (
+	CompressionAlgorithmLZ4 CompressionAlgorithm = iota
+	// Zstd is used with peers that can decompress it, and LZ4 with others.
+	CompressionAlgorithmZstd
+)
+
+// CompressionSettings are which messages are compressed, and how.
+type CompressionSettings struct {
+	Compression Compression
+	Algorithm   CompressionAlgorithm
+}
+
+// CompressionPolicy is the compression settings of a connection, for the
+// messages of each folder. Messages of folders not in Folders, and those
+// not about any folder, use the default settings. Index messages are
+// compressed with the index dictionary where the peer has it, whatever
+// the algorithm.
+type CompressionPolicy struct {
+	Default CompressionSettings
+	Folders map[string]CompressionSettings
+}
+
+func (p *CompressionPolicy) settings(folder string) CompressionSettings {
+	if s, ok := p.Folders[folder]; ok {
+		return s
+	}
+	return p.Default
+}
+
+// SetCompressionPolicy replaces the compression policy of the connection,
+// for the messages sent from now on.
+func (c *rawConnection) SetCompressionPolicy(policy CompressionPolicy) {
+	c.compression.Store(&policy)
+}
+
+// messageFolder returns the folder a message is about, if any. Responses
+// don't say; their folder is that of the request.
+func messageFolder(msg proto.Message) string {
+	switch msg := msg.(type) {
+	case *bep.Index:
+		return msg.Folder
+	case *bep.IndexUpdate:
+		return msg.Folder
+	case *bep.Request:
+		return msg.Folder
+	case *bep.DownloadProgress:
+		return msg.Folder
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+)
+
+// A connection that is closed regularly, on shutdown or when the device is
+// paused, first drains: it tells the peer, which then stops sending new
+// requests and stops choosing us as a source, and waits for the requests
+// it is serving to the peer to finish before sending the Close message.
+// Transfers in progress thus end cleanly instead of being cut off. Our own
+// requests in flight are not waited for; closing fails them right away,
+// and they are retried from other devices.
+
+// DrainTimeout is the longest we'll wait for the requests in flight to
+// finish when closing the connection.
+// Should not be modified in production code, just for testing.
+var DrainTimeout = 10 * time.Second
+
+var ErrDraining = errors.New("connection is draining")
+
+// inFlight counts the requests in flight on a connection.
+type inFlight struct {
+	mut  sync.Mutex
+	n    int
+	idle chan struct{} // closed when n drops to zero, if waited for
+}
+
+func (f *inFlight) add() {
+	f.mut.Lock()
+	f.n++
+	f.mut.Unlock()
+}
+
+func (f *inFlight) done() {
+	f.mut.Lock()
+	f.n--
+	if f.n == 0 && f.idle != nil {
+		close(f.idle)
+		f.idle = nil
+	}
+	f.mut.Unlock()
+}
+
+// wait returns a channel that is closed once no requests are in flight.
+func (f *inFlight) wait() <-chan struct{} {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.n == 0 {
+		idle := make(chan struct{})
+		close(idle)
+		return idle
+	}
+	if f.idle == nil {
+		f.idle = make(chan struct{})
+	}
+	return f.idle
+}
+
+// Draining returns whether either side is about to close the connection,
+// so that it takes no new requests.
+func (c *rawConnection) Draining() bool {
+	return c.draining.Load() || c.peerDraining.Load()
+}
+
+// drain stops new requests, lets the peer know, and waits up to
+// DrainTimeout for the requests we're serving to finish.
+func (c *rawConnection) drain() {
+	if c.draining.Swap(true) {
+		return
+	}
+	select {
+	case <-c.started:
+	default:
+		return
+	}
+
+	// The peer is told first in any case, so that it stops choosing us
+	// right away rather than once the connection is closed.
+	if c.peerAcceptsDraining.Load() {
+		ctx, cancel := context.WithTimeout(context.Background(), CloseTimeout)
+		c.sendTo(ctx, c.priorityOutbox, asyncMessage{msg: &bep.Draining{}})
+		cancel()
+	}
+
+	timeout := time.NewTimer(DrainTimeout)
+	defer timeout.Stop()
+	select {
+	case <-c.inFlight.wait():
+	case <-timeout.C:
+		l.Debugf("drain of connection to %s timed out", c.deviceID.Short())
+	case <-c.closed:
+	}
+}
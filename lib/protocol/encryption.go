@@ -7,6 +7,7 @@
 package protocol
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/base32"
@@ -14,17 +15,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path"
+	"slices"
 	"strings"
 	"sync"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/miscreant/miscreant.go"
+	lz4 "github.com/pierrec/lz4/v4"
 	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/hkdf"
 	"golang.org/x/crypto/scrypt"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/syncthing/syncthing/internal/gen/bep"
+	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/rand"
 )
 
@@ -59,38 +64,48 @@ func newEncryptedModel(model rawModel, keyGen *KeyGenerator) encryptedModel {
 }
 
 func (e encryptedModel) Index(idx *Index) error {
-	if folderKey, ok := e.folderKeys.get(idx.Folder); ok {
+	if keys, ok := e.folderKeys.get(idx.Folder); ok {
 		// incoming index data to be decrypted
-		if err := decryptFileInfos(e.keyGen, idx.Files, folderKey); err != nil {
+		files, err := decryptFileInfos(e.keyGen, idx.Files, keys)
+		if err != nil {
 			return err
 		}
+		idx.Files = files
 	}
 	return e.model.Index(idx)
 }
 
 func (e encryptedModel) IndexUpdate(idxUp *IndexUpdate) error {
-	if folderKey, ok := e.folderKeys.get(idxUp.Folder); ok {
+	if keys, ok := e.folderKeys.get(idxUp.Folder); ok {
 		// incoming index data to be decrypted
-		if err := decryptFileInfos(e.keyGen, idxUp.Files, folderKey); err != nil {
+		files, err := decryptFileInfos(e.keyGen, idxUp.Files, keys)
+		if err != nil {
 			return err
 		}
+		idxUp.Files = files
 	}
 	return e.model.IndexUpdate(idxUp)
 }
 
 func (e encryptedModel) Request(req *Request) (RequestResponse, error) {
-	folderKey, ok := e.folderKeys.get(req.Folder)
+	keys, ok := e.folderKeys.get(req.Folder)
 	if !ok {
 		return e.model.Request(req)
 	}
 
 	// Figure out the real file name, offset and size from the encrypted /
-	// tweaked values.
+	// tweaked values. Requests for data still encrypted with a key no
+	// longer used for it, such as the previous password while rotating,
+	// are answered in kind.
 
-	realName, err := decryptName(req.Name, folderKey)
+	realName, folderKey, err := keys.decryptName(req.Name)
 	if err != nil {
 		return nil, fmt.Errorf("decrypting name: %w", err)
 	}
+	if folderKey == nil {
+		// An unencrypted subtree
+		return e.model.Request(req)
+	}
 	realSize := req.Size - blockOverhead
 	realOffset := req.Offset - int64(req.BlockNo*blockOverhead)
 
@@ -143,7 +158,13 @@ func (e encryptedModel) Request(req *Request) (RequestResponse, error) {
 		}
 		data = nd
 	}
-	enc := encryptBytes(data, fileKey)
+	var enc []byte
+	if keys.compress && e.folderKeys.peerCompressed(req.Folder) {
+		enc = encryptCompressed(data, fileKey)
+	}
+	if enc == nil {
+		enc = encryptBytes(data, fileKey)
+	}
 	resp.Close()
 	return rawResponse{enc}, nil
 }
@@ -160,6 +181,7 @@ func (e encryptedModel) DownloadProgress(p *DownloadProgress) error {
 }
 
 func (e encryptedModel) ClusterConfig(config *ClusterConfig) error {
+	e.folderKeys.setPeerCompressed(config.Folders)
 	return e.model.ClusterConfig(config)
 }
 
@@ -167,6 +189,10 @@ func (e encryptedModel) Closed(err error) {
 	e.model.Closed(err)
 }
 
+func (e encryptedModel) ManagementRequest(req *ManagementRequest) ([]byte, error) {
+	return e.model.ManagementRequest(req)
+}
+
 // The encryptedConnection sits between the model and the encrypted device. It
 // encrypts outgoing metadata and decrypts incoming responses.
 type encryptedConnection struct {
@@ -195,24 +221,29 @@ func (e encryptedConnection) DeviceID() DeviceID {
 }
 
 func (e encryptedConnection) Index(ctx context.Context, idx *Index) error {
-	if folderKey, ok := e.folderKeys.get(idx.Folder); ok {
-		encryptFileInfos(e.keyGen, idx.Files, folderKey)
+	if keys, ok := e.folderKeys.get(idx.Folder); ok {
+		encryptFileInfos(e.keyGen, idx.Files, keys)
 	}
 	return e.conn.Index(ctx, idx)
 }
 
 func (e encryptedConnection) IndexUpdate(ctx context.Context, idxUp *IndexUpdate) error {
-	if folderKey, ok := e.folderKeys.get(idxUp.Folder); ok {
-		encryptFileInfos(e.keyGen, idxUp.Files, folderKey)
+	if keys, ok := e.folderKeys.get(idxUp.Folder); ok {
+		encryptFileInfos(e.keyGen, idxUp.Files, keys)
 	}
 	return e.conn.IndexUpdate(ctx, idxUp)
 }
 
 func (e encryptedConnection) Request(ctx context.Context, req *Request) ([]byte, error) {
-	folderKey, ok := e.folderKeys.get(req.Folder)
+	keys, ok := e.folderKeys.get(req.Folder)
 	if !ok {
 		return e.conn.Request(ctx, req)
 	}
+	folderKey := keys.keyFor(req.Name)
+	if folderKey == nil {
+		// An unencrypted subtree
+		return e.conn.Request(ctx, req)
+	}
 	fileKey := e.keyGen.FileKey(req.Name, folderKey)
 
 	// Encrypt / adjust the request parameters.
@@ -261,11 +292,23 @@ func (e encryptedConnection) DownloadProgress(ctx context.Context, dp *DownloadP
 	// No need to send these
 }
 
-func (e encryptedConnection) ClusterConfig(config *ClusterConfig, passwords map[string]string) {
+func (e encryptedConnection) ManagementRequest(ctx context.Context, req *ManagementRequest) ([]byte, error) {
+	return e.conn.ManagementRequest(ctx, req)
+}
+
+func (e encryptedConnection) ClusterConfig(config *ClusterConfig, passwords map[string]EncryptionPassword) {
 	e.folderKeys.setPasswords(e.keyGen, passwords)
 	e.conn.ClusterConfig(config, passwords)
 }
 
+func (e encryptedConnection) SetCompressionPolicy(policy CompressionPolicy) {
+	e.conn.SetCompressionPolicy(policy)
+}
+
+func (e encryptedConnection) Draining() bool {
+	return e.conn.Draining()
+}
+
 func (e encryptedConnection) Close(err error) {
 	e.conn.Close(err)
 }
@@ -278,9 +321,11 @@ func (e encryptedConnection) Statistics() Statistics {
 	return e.conn.Statistics()
 }
 
-func encryptFileInfos(keyGen *KeyGenerator, files []FileInfo, folderKey *[keySize]byte) {
+func encryptFileInfos(keyGen *KeyGenerator, files []FileInfo, keys folderKeys) {
 	for i, fi := range files {
-		files[i] = encryptFileInfo(keyGen, fi, folderKey)
+		if folderKey := keys.keyFor(fi.Name); folderKey != nil {
+			files[i] = encryptFileInfo(keyGen, fi, folderKey)
+		}
 	}
 }
 
@@ -386,15 +431,22 @@ func encryptBlockHash(hash []byte, offset int64, fileKey *[keySize]byte) []byte
 	return encryptDeterministic(hash, fileKey, additional[:])
 }
 
-func decryptFileInfos(keyGen *KeyGenerator, files []FileInfo, folderKey *[keySize]byte) error {
-	for i, fi := range files {
-		decFI, err := DecryptFileInfo(keyGen, fi, folderKey)
+// decryptFileInfos decrypts the files in place. Files encrypted with a key
+// no longer used for them, such as the previous password while rotating,
+// are skipped, and the returned slice may be shorter.
+func decryptFileInfos(keyGen *KeyGenerator, files []FileInfo, keys folderKeys) ([]FileInfo, error) {
+	res := files[:0]
+	for _, fi := range files {
+		decFI, key, err := keys.decryptFileInfo(keyGen, fi)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		files[i] = decFI
+		if !sameKey(key, keys.keyFor(decFI.Name)) {
+			continue
+		}
+		res = append(res, decFI)
 	}
-	return nil
+	return res, nil
 }
 
 // DecryptFileInfo extracts the encrypted portion of a FileInfo, decrypts it
@@ -512,7 +564,80 @@ func DecryptBytes(data []byte, key *[keySize]byte) ([]byte, error) {
 		panic("crypto parameter mismatch")
 	}
 
-	return aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	dec, err := aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err == nil {
+		return dec, nil
+	}
+
+	// A compressed block has the length of the sealed data at the end of
+	// the nonce, and zeroes after it.
+	sealed := int(binary.BigEndian.Uint32(data[nonceSize-4 : nonceSize]))
+	if sealed < tagSize || sealed > len(data)-nonceSize {
+		return nil, err
+	}
+	compressed, cerr := aead.Open(nil, data[:nonceSize], data[nonceSize:nonceSize+sealed], nil)
+	if cerr != nil {
+		return nil, err
+	}
+	if len(compressed) < 4 {
+		return nil, errors.New("compressed data too short")
+	}
+	size := binary.BigEndian.Uint32(compressed)
+	if size > uint32(len(data)) {
+		return nil, errors.New("compressed data too large")
+	}
+	dec = make([]byte, size)
+	n, err := lz4.UncompressBlock(compressed[4:], dec)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing: %w", err)
+	}
+	return dec[:n], nil
+}
+
+// encryptCompressed encrypts the compressed data, padded with zeroes to
+// the size that encryptBytes would return, or returns nil if the data
+// doesn't compress well enough for it to be worthwhile. The zeroes can be
+// left as holes in a sparse file.
+func encryptCompressed(data []byte, key *[keySize]byte) []byte {
+	buf := make([]byte, len(data)+blockOverhead)
+	compressed := buf[nonceSize:]
+	n, err := lz4Compress(data, compressed)
+	if err != nil || n+tagSize > len(data)-len(data)/8 {
+		return nil
+	}
+
+	// The last four bytes of the otherwise random nonce hold the length
+	// of the sealed data.
+	nonce := randomNonce()
+	binary.BigEndian.PutUint32(nonce[nonceSize-4:], uint32(n+tagSize))
+	sealed := encrypt(compressed[:n], nonce, key)
+	copy(buf, sealed)
+	clear(buf[len(sealed):])
+	return buf
+}
+
+// CompressedPadding returns the length of the zero padding after the
+// sealed data of a block compressed before encryption, as recorded in its
+// nonce, or zero for any other block. The untrusted device can leave the
+// padding as a hole in a sparse file.
+func CompressedPadding(data []byte) int {
+	if len(data) < nonceSize {
+		return 0
+	}
+	sealed := binary.BigEndian.Uint32(data[nonceSize-4 : nonceSize])
+	if sealed < tagSize || uint64(sealed) >= uint64(len(data)-nonceSize) {
+		return 0
+	}
+	// In a regular block the nonce is random, and could only by chance
+	// point at a run of zeroes up to the end. Leaving those out of a
+	// sparse file wouldn't change anything either.
+	end := nonceSize + int(sealed)
+	for _, b := range data[end:] {
+		if b != 0 {
+			return 0
+		}
+	}
+	return len(data) - end
 }
 
 // randomNonce is a normal, cryptographically random nonce
@@ -524,12 +649,161 @@ func randomNonce() *[nonceSize]byte {
 	return &nonce
 }
 
+// EncryptionPassword is the password for the data of a folder shared with
+// an untrusted device.
+type EncryptionPassword struct {
+	Password string
+	// Previous is the password being rotated away from, if any. Data still
+	// encrypted with it is accepted from the untrusted device, but nothing
+	// new is encrypted with it.
+	Previous string
+	// Subtrees are encrypted with a password of their own instead.
+	Subtrees []EncryptionSubtree
+	// Compress data before encrypting it, where that makes it smaller, so
+	// that it takes less space on the untrusted device. Every trusted
+	// device sharing the folder with it must be able to decrypt that.
+	Compress bool
+}
+
+// An EncryptionSubtree is a directory of the folder, and everything below
+// it, encrypted with a different password than the rest of the folder.
+type EncryptionSubtree struct {
+	Path     string // slash separated, relative to the folder root
+	Password string // empty for no encryption
+}
+
+type folderKeys struct {
+	current, previous *[keySize]byte
+	subtrees          []subtreeKey // deepest first
+	compress          bool
+}
+
+type subtreeKey struct {
+	path string
+	key  *[keySize]byte // nil for no encryption
+}
+
+// keyFor returns the key the named file is encrypted with, or nil if it's
+// not encrypted.
+func (k folderKeys) keyFor(name string) *[keySize]byte {
+	for _, st := range k.subtrees {
+		if st.key == nil && strings.HasPrefix(st.path, name+"/") {
+			// The parents of an unencrypted subtree need to exist in the
+			// clear to hold it.
+			return nil
+		}
+	}
+	for _, st := range k.subtrees {
+		if name == st.path || strings.HasPrefix(name, st.path+"/") {
+			return st.key
+		}
+	}
+	return k.current
+}
+
+// all returns every key data may be encrypted with.
+func (k folderKeys) all() []*[keySize]byte {
+	keys := make([]*[keySize]byte, 0, len(k.subtrees)+2)
+	keys = append(keys, k.current)
+	for _, st := range k.subtrees {
+		if st.key != nil {
+			keys = append(keys, st.key)
+		}
+	}
+	if k.previous != nil {
+		keys = append(keys, k.previous)
+	}
+	return keys
+}
+
+// decryptName decrypts the name with whichever key it was encrypted with,
+// returning that key. Names that aren't encrypted are returned as is, with
+// a nil key, if they belong to an unencrypted subtree.
+func (k folderKeys) decryptName(name string) (string, *[keySize]byte, error) {
+	var err error
+	for _, key := range k.all() {
+		var realName string
+		if realName, err = decryptName(name, key); err == nil {
+			return realName, key, nil
+		}
+	}
+	if !isCanonicalName(name) {
+		// Would escape the unencrypted subtree it seems to be in, once
+		// made a path on disk.
+		return "", nil, ErrInvalid
+	}
+	if k.keyFor(name) == nil {
+		return name, nil, nil
+	}
+	return "", nil, err
+}
+
+// decryptFileInfo is like DecryptFileInfo with whichever key the file was
+// encrypted with, returning that key. Files that aren't encrypted are
+// returned as is, with a nil key, if they belong to an unencrypted subtree.
+func (k folderKeys) decryptFileInfo(keyGen *KeyGenerator, fi FileInfo) (FileInfo, *[keySize]byte, error) {
+	var err error
+	for _, key := range k.all() {
+		var dec FileInfo
+		if dec, err = DecryptFileInfo(keyGen, fi, key); err == nil {
+			return dec, key, nil
+		}
+	}
+	if !isCanonicalName(fi.Name) {
+		return FileInfo{}, nil, ErrInvalid
+	}
+	if k.keyFor(fi.Name) == nil {
+		return fi, nil, nil
+	}
+	return FileInfo{}, nil, err
+}
+
+// isCanonicalName returns whether the name is a clean relative path, so
+// that subtrees can be matched against it as is. Untrusted devices may
+// send anything for the names in unencrypted subtrees.
+func isCanonicalName(name string) bool {
+	if name == "" || path.Clean(name) != name || path.IsAbs(name) {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	// Backslashes separate path components on Windows.
+	return !build.IsWindows || !strings.Contains(name, `\`)
+}
+
+func sameKey(a, b *[keySize]byte) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 // keysFromPasswords converts a set of folder ID to password into a set of
-// folder ID to encryption key, using our key derivation function.
-func keysFromPasswords(keyGen *KeyGenerator, passwords map[string]string) map[string]*[keySize]byte {
-	res := make(map[string]*[keySize]byte, len(passwords))
+// folder ID to encryption keys, using our key derivation function.
+func keysFromPasswords(keyGen *KeyGenerator, passwords map[string]EncryptionPassword) map[string]folderKeys {
+	res := make(map[string]folderKeys, len(passwords))
 	for folder, password := range passwords {
-		res[folder] = keyGen.KeyFromPassword(folder, password)
+		keys := folderKeys{
+			current:  keyGen.KeyFromPassword(folder, password.Password),
+			compress: password.Compress,
+		}
+		if password.Previous != "" {
+			keys.previous = keyGen.KeyFromPassword(folder, password.Previous)
+		}
+		for _, st := range password.Subtrees {
+			sk := subtreeKey{path: st.Path}
+			if st.Password != "" {
+				sk.key = keyGen.KeyFromPassword(folder, st.Password)
+			}
+			keys.subtrees = append(keys.subtrees, sk)
+		}
+		slices.SortFunc(keys.subtrees, func(a, b subtreeKey) int {
+			return cmp.Compare(len(b.path), len(a.path))
+		})
+		res[folder] = keys
 	}
 	return res
 }
@@ -682,25 +956,48 @@ func IsEncryptedParent(pathComponents []string) bool {
 }
 
 type folderKeyRegistry struct {
-	keys map[string]*[keySize]byte // folder ID -> key
-	mut  sync.RWMutex
+	keys map[string]folderKeys // folder ID -> keys
+	// Folders for which the peer handles blocks compressed before
+	// encryption, as announced in its cluster config.
+	compressed map[string]struct{}
+	mut        sync.RWMutex
 }
 
 func newFolderKeyRegistry() *folderKeyRegistry {
 	return &folderKeyRegistry{
-		keys: make(map[string]*[keySize]byte),
+		keys:       make(map[string]folderKeys),
+		compressed: make(map[string]struct{}),
 	}
 }
 
-func (r *folderKeyRegistry) get(folder string) (*[keySize]byte, bool) {
+func (r *folderKeyRegistry) get(folder string) (folderKeys, bool) {
 	r.mut.RLock()
-	key, ok := r.keys[folder]
+	keys, ok := r.keys[folder]
 	r.mut.RUnlock()
-	return key, ok
+	return keys, ok
 }
 
-func (r *folderKeyRegistry) setPasswords(keyGen *KeyGenerator, passwords map[string]string) {
+func (r *folderKeyRegistry) setPasswords(keyGen *KeyGenerator, passwords map[string]EncryptionPassword) {
 	r.mut.Lock()
 	r.keys = keysFromPasswords(keyGen, passwords)
 	r.mut.Unlock()
 }
+
+func (r *folderKeyRegistry) peerCompressed(folder string) bool {
+	r.mut.RLock()
+	_, ok := r.compressed[folder]
+	r.mut.RUnlock()
+	return ok
+}
+
+func (r *folderKeyRegistry) setPeerCompressed(folders []Folder) {
+	compressed := make(map[string]struct{})
+	for _, f := range folders {
+		if f.CompressedEncryption {
+			compressed[f.ID] = struct{}{}
+		}
+	}
+	r.mut.Lock()
+	r.compressed = compressed
+	r.mut.Unlock()
+}
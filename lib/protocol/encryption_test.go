@@ -8,10 +8,12 @@ package protocol
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
@@ -144,6 +146,104 @@ func TestEnDecryptBytes(t *testing.T) {
 	}
 }
 
+func TestEnDecryptCompressed(t *testing.T) {
+	var key [32]byte
+	data := bytes.Repeat([]byte("compressible "), 10000)
+	enc := encryptCompressed(data, &key)
+	if enc == nil {
+		t.Fatal("data should have been compressed")
+	}
+	if len(enc) != len(data)+blockOverhead {
+		t.Fatalf("compressed block should be padded to %d bytes, not %d", len(data)+blockOverhead, len(enc))
+	}
+	trimmed := bytes.TrimRight(enc, "\x00")
+	if len(trimmed) > len(data)/2 {
+		t.Errorf("expected a long run of zeroes, got %d of %d bytes of data", len(trimmed), len(enc))
+	}
+	dec, err := DecryptBytes(enc, &key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dec, data) {
+		t.Error("mismatch after decryption")
+	}
+
+	// A tampered block fails decryption, like any other.
+	enc[len(trimmed)-1]++
+	if _, err := DecryptBytes(enc, &key); err == nil {
+		t.Error("tampered block should fail decryption")
+	}
+
+	// The padding is known from the block itself.
+	if pad := CompressedPadding(enc); pad != len(enc)-len(trimmed) {
+		t.Errorf("expected padding of %d bytes, got %d", len(enc)-len(trimmed), pad)
+	}
+	plain := encryptBytes(append(data[:len(data)-100:len(data)-100], make([]byte, 100)...), &key)
+	if pad := CompressedPadding(plain); pad != 0 {
+		t.Errorf("expected no padding for a regular block, got %d", pad)
+	}
+
+	// Random data isn't worth compressing.
+	random := make([]byte, 10000)
+	rand.Read(random)
+	if encryptCompressed(random, &key) != nil {
+		t.Error("random data should not be compressed")
+	}
+}
+
+func TestEncryptedRequestCompression(t *testing.T) {
+	data := bytes.Repeat([]byte("compressible "), 1000)
+	em := newEncryptedModel(blockModel{data}, testKeyGen)
+	em.folderKeys.setPasswords(testKeyGen, map[string]EncryptionPassword{
+		"folder": {Password: "pw", Compress: true},
+	})
+	request := func() []byte {
+		t.Helper()
+		name := encryptName("file", testKeyGen.KeyFromPassword("folder", "pw"))
+		res, err := em.Request(&Request{Folder: "folder", Name: name, Size: len(data) + blockOverhead})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Data()
+	}
+
+	// Blocks are only compressed once the peer has announced that it
+	// handles that for the folder.
+	if CompressedPadding(request()) != 0 {
+		t.Error("block compressed for a peer that didn't announce it")
+	}
+	if err := em.ClusterConfig(&ClusterConfig{Folders: []Folder{{ID: "folder", CompressedEncryption: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	if CompressedPadding(request()) == 0 {
+		t.Error("block not compressed for a peer that announced it")
+	}
+	if err := em.ClusterConfig(&ClusterConfig{Folders: []Folder{{ID: "folder"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if CompressedPadding(request()) != 0 {
+		t.Error("block compressed for a peer that no longer announces it")
+	}
+}
+
+// blockModel answers every request with the same data.
+type blockModel struct {
+	data []byte
+}
+
+func (blockModel) Index(*Index) error                       { return nil }
+func (blockModel) IndexUpdate(*IndexUpdate) error           { return nil }
+func (blockModel) ClusterConfig(*ClusterConfig) error       { return nil }
+func (blockModel) Closed(error)                             {}
+func (blockModel) DownloadProgress(*DownloadProgress) error { return nil }
+func (blockModel) ManagementRequest(*ManagementRequest) ([]byte, error) {
+	return nil, nil
+}
+
+func (m blockModel) Request(req *Request) (RequestResponse, error) {
+	return &fakeRequestResponse{m.data[:req.Size]}, nil
+}
+
 func encFileInfo() FileInfo {
 	return FileInfo{
 		Name:        "hello",
@@ -208,6 +308,162 @@ func TestEnDecryptFileInfo(t *testing.T) {
 	}
 }
 
+func TestDecryptFileInfosPreviousKey(t *testing.T) {
+	if cryptoIsBrokenUnderRaceDetector {
+		t.Skip("cannot test")
+	}
+
+	key := testKeyGen.KeyFromPassword("folder", "new")
+	prevKey := testKeyGen.KeyFromPassword("folder", "old")
+	otherKey := testKeyGen.KeyFromPassword("folder", "other")
+
+	fi := encFileInfo()
+	files := []FileInfo{
+		encryptFileInfo(testKeyGen, fi, prevKey),
+		encryptFileInfo(testKeyGen, fi, key),
+	}
+
+	// Files still encrypted with the previous key are skipped.
+	dec, err := decryptFileInfos(testKeyGen, files, folderKeys{current: key, previous: prevKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dec) != 1 || dec[0].Name != fi.Name {
+		t.Errorf("expected only the file encrypted with the new key, got %v", dec)
+	}
+
+	// Any other key is still an error.
+	files = []FileInfo{encryptFileInfo(testKeyGen, fi, otherKey)}
+	if _, err := decryptFileInfos(testKeyGen, files, folderKeys{current: key, previous: prevKey}); err == nil {
+		t.Error("expected error decrypting with an unknown key")
+	}
+}
+
+func TestEncryptionSubtrees(t *testing.T) {
+	if cryptoIsBrokenUnderRaceDetector {
+		t.Skip("cannot test")
+	}
+
+	keys := keysFromPasswords(testKeyGen, map[string]EncryptionPassword{
+		"folder": {
+			Password: "root",
+			Subtrees: []EncryptionSubtree{
+				{Path: "archive", Password: "archive"},
+				{Path: "archive/public"},
+			},
+		},
+	})["folder"]
+	rootKey := testKeyGen.KeyFromPassword("folder", "root")
+	archiveKey := testKeyGen.KeyFromPassword("folder", "archive")
+
+	cases := []struct {
+		name string
+		key  *[keySize]byte
+	}{
+		{"foo", rootKey},
+		{"archives", rootKey},
+		{"archive", nil}, // parent of an unencrypted subtree
+		{"archive/foo", archiveKey},
+		{"archive/public", nil},
+		{"archive/public/foo", nil},
+	}
+	for _, tc := range cases {
+		if key := keys.keyFor(tc.name); !sameKey(key, tc.key) {
+			t.Errorf("%s: unexpected key", tc.name)
+		}
+	}
+
+	files := make([]FileInfo, len(cases))
+	for i, tc := range cases {
+		fi := encFileInfo()
+		fi.Name = tc.name
+		files[i] = fi
+	}
+	encryptFileInfos(testKeyGen, files, keys)
+	for i, tc := range cases {
+		if encrypted := files[i].Name != tc.name; encrypted != (tc.key != nil) {
+			t.Errorf("%s: expected encrypted %v, got %v", tc.name, tc.key != nil, encrypted)
+		}
+	}
+
+	// Data encrypted with the key for the rest of the folder in what is
+	// now a subtree of its own is skipped.
+	stale := encFileInfo()
+	stale.Name = "archive/bar"
+	files = append(files, encryptFileInfo(testKeyGen, stale, rootKey))
+
+	dec, err := decryptFileInfos(testKeyGen, files, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dec) != len(cases) {
+		t.Fatalf("expected %d files, got %d", len(cases), len(dec))
+	}
+	for i, tc := range cases {
+		if dec[i].Name != tc.name {
+			t.Errorf("expected %s, got %s", tc.name, dec[i].Name)
+		}
+	}
+
+	// Names outside of unencrypted subtrees must be encrypted.
+	plain := encFileInfo()
+	plain.Name = "foo"
+	if _, err := decryptFileInfos(testKeyGen, []FileInfo{plain}, keys); err == nil {
+		t.Error("expected error for unencrypted file outside of unencrypted subtree")
+	}
+}
+
+// requestRecorder is a model that records the requests it gets.
+type requestRecorder struct {
+	rawModel
+	requested []string
+}
+
+func (r *requestRecorder) Request(req *Request) (RequestResponse, error) {
+	r.requested = append(r.requested, req.Name)
+	return rawResponse{make([]byte, req.Size)}, nil
+}
+
+func TestEncryptionSubtreeTraversal(t *testing.T) {
+	if cryptoIsBrokenUnderRaceDetector {
+		t.Skip("cannot test")
+	}
+
+	rec := &requestRecorder{}
+	em := newEncryptedModel(rec, testKeyGen)
+	em.folderKeys.setPasswords(testKeyGen, map[string]EncryptionPassword{
+		"folder": {
+			Password: "root",
+			Subtrees: []EncryptionSubtree{{Path: "public"}},
+		},
+	})
+
+	// A name that only looks like it's in the unencrypted subtree must not
+	// reach the model in the clear, where it would name an encrypted file.
+	for _, name := range []string{"public/../secret.txt", "public/./secret.txt", "public//secret.txt", "public/sub/../../secret.txt"} {
+		_, err := em.Request(&Request{Folder: "folder", Name: name, Size: minPaddedSize})
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected request to be invalid, got %v", name, err)
+		}
+
+		fi := encFileInfo()
+		fi.Name = name
+		if err := em.Index(&Index{Folder: "folder", Files: []FileInfo{fi}}); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected index to be invalid, got %v", name, err)
+		}
+	}
+	if len(rec.requested) != 0 {
+		t.Errorf("expected no requests to be passed on, got %v", rec.requested)
+	}
+
+	if _, err := em.Request(&Request{Folder: "folder", Name: "public/file.txt", Size: minPaddedSize}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(rec.requested, []string{"public/file.txt"}) {
+		t.Errorf("expected request in the unencrypted subtree to be passed on, got %v", rec.requested)
+	}
+}
+
 func TestEncryptedFileInfoConsistency(t *testing.T) {
 	if cryptoIsBrokenUnderRaceDetector {
 		t.Skip("cannot test")
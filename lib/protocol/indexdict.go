@@ -0,0 +1,71 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	_ "embed"
+	"slices"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Index messages are mostly many small FileInfos that look much alike, and
+// too little of each message resembles the rest of it for LZ4 to do well.
+// Between devices that both have it, they are compressed with zstd and a
+// dictionary built from typical FileInfos instead; see script/indexdict.go.
+
+//go:embed index.dict
+var indexDict []byte
+
+type indexDictCodec struct {
+	id  uint32
+	enc *zstd.Encoder
+	dec *zstd.Decoder
+}
+
+var loadIndexDict = sync.OnceValue(func() *indexDictCodec {
+	info, err := zstd.InspectDictionary(indexDict)
+	if err != nil {
+		panic("bug: invalid index dictionary: " + err.Error())
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderDict(indexDict), zstd.WithEncoderLevel(zstd.SpeedDefault))
+	if err != nil {
+		panic("bug: index dictionary encoder: " + err.Error())
+	}
+	dec, err := zstd.NewReader(nil, zstd.WithDecoderDicts(indexDict), zstd.WithDecoderMaxMemory(MaxMessageLen))
+	if err != nil {
+		panic("bug: index dictionary decoder: " + err.Error())
+	}
+	return &indexDictCodec{id: info.ID(), enc: enc, dec: dec}
+})
+
+// indexDictionaries returns the IDs of the dictionaries we can decompress
+// index messages with, to advertise in the cluster config.
+func indexDictionaries() []uint32 {
+	return []uint32{loadIndexDict().id}
+}
+
+// peerHasIndexDict returns whether we can compress index messages with our
+// dictionary for a peer advertising the given dictionaries.
+func peerHasIndexDict(ids []uint32) bool {
+	return slices.Contains(ids, loadIndexDict().id)
+}
+
+func zstdCompress(src, buf []byte) (int, error) {
+	out := loadIndexDict().enc.EncodeAll(src, buf[:0])
+	if len(out) > len(buf) {
+		return -1, errNotCompressible
+	}
+	// A no-op unless the encoder outgrew buf.
+	copy(buf, out)
+	return len(out), nil
+}
+
+func zstdDecompress(src []byte) ([]byte, error) {
+	return loadIndexDict().dec.DecodeAll(src, nil)
+}
@@ -26,11 +26,11 @@ type Connection struct {
 	closedReturnsOnCall map[int]struct {
 		result1 <-chan struct{}
 	}
-	ClusterConfigStub        func(*protocol.ClusterConfig, map[string]string)
+	ClusterConfigStub        func(*protocol.ClusterConfig, map[string]protocol.EncryptionPassword)
 	clusterConfigMutex       sync.RWMutex
 	clusterConfigArgsForCall []struct {
 		arg1 *protocol.ClusterConfig
-		arg2 map[string]string
+		arg2 map[string]protocol.EncryptionPassword
 	}
 	ConnectionIDStub        func() string
 	connectionIDMutex       sync.RWMutex
@@ -68,6 +68,16 @@ type Connection struct {
 		arg1 context.Context
 		arg2 *protocol.DownloadProgress
 	}
+	DrainingStub        func() bool
+	drainingMutex       sync.RWMutex
+	drainingArgsForCall []struct {
+	}
+	drainingReturns struct {
+		result1 bool
+	}
+	drainingReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	EstablishedAtStub        func() time.Time
 	establishedAtMutex       sync.RWMutex
 	establishedAtArgsForCall []struct {
@@ -112,6 +122,20 @@ type Connection struct {
 	isLocalReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	ManagementRequestStub        func(context.Context, *protocol.ManagementRequest) ([]byte, error)
+	managementRequestMutex       sync.RWMutex
+	managementRequestArgsForCall []struct {
+		arg1 context.Context
+		arg2 *protocol.ManagementRequest
+	}
+	managementRequestReturns struct {
+		result1 []byte
+		result2 error
+	}
+	managementRequestReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	PriorityStub        func() int
 	priorityMutex       sync.RWMutex
 	priorityArgsForCall []struct {
@@ -146,6 +170,11 @@ type Connection struct {
 		result1 []byte
 		result2 error
 	}
+	SetCompressionPolicyStub        func(protocol.CompressionPolicy)
+	setCompressionPolicyMutex       sync.RWMutex
+	setCompressionPolicyArgsForCall []struct {
+		arg1 protocol.CompressionPolicy
+	}
 	StartStub        func()
 	startMutex       sync.RWMutex
 	startArgsForCall []struct {
@@ -279,11 +308,11 @@ func (fake *Connection) ClosedReturnsOnCall(i int, result1 <-chan struct{}) {
 	}{result1}
 }
 
-func (fake *Connection) ClusterConfig(arg1 *protocol.ClusterConfig, arg2 map[string]string) {
+func (fake *Connection) ClusterConfig(arg1 *protocol.ClusterConfig, arg2 map[string]protocol.EncryptionPassword) {
 	fake.clusterConfigMutex.Lock()
 	fake.clusterConfigArgsForCall = append(fake.clusterConfigArgsForCall, struct {
 		arg1 *protocol.ClusterConfig
-		arg2 map[string]string
+		arg2 map[string]protocol.EncryptionPassword
 	}{arg1, arg2})
 	stub := fake.ClusterConfigStub
 	fake.recordInvocation("ClusterConfig", []interface{}{arg1, arg2})
@@ -299,13 +328,13 @@ func (fake *Connection) ClusterConfigCallCount() int {
 	return len(fake.clusterConfigArgsForCall)
 }
 
-func (fake *Connection) ClusterConfigCalls(stub func(*protocol.ClusterConfig, map[string]string)) {
+func (fake *Connection) ClusterConfigCalls(stub func(*protocol.ClusterConfig, map[string]protocol.EncryptionPassword)) {
 	fake.clusterConfigMutex.Lock()
 	defer fake.clusterConfigMutex.Unlock()
 	fake.ClusterConfigStub = stub
 }
 
-func (fake *Connection) ClusterConfigArgsForCall(i int) (*protocol.ClusterConfig, map[string]string) {
+func (fake *Connection) ClusterConfigArgsForCall(i int) (*protocol.ClusterConfig, map[string]protocol.EncryptionPassword) {
 	fake.clusterConfigMutex.RLock()
 	defer fake.clusterConfigMutex.RUnlock()
 	argsForCall := fake.clusterConfigArgsForCall[i]
@@ -504,6 +533,59 @@ func (fake *Connection) DownloadProgressArgsForCall(i int) (context.Context, *pr
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *Connection) Draining() bool {
+	fake.drainingMutex.Lock()
+	ret, specificReturn := fake.drainingReturnsOnCall[len(fake.drainingArgsForCall)]
+	fake.drainingArgsForCall = append(fake.drainingArgsForCall, struct {
+	}{})
+	stub := fake.DrainingStub
+	fakeReturns := fake.drainingReturns
+	fake.recordInvocation("Draining", []interface{}{})
+	fake.drainingMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Connection) DrainingCallCount() int {
+	fake.drainingMutex.RLock()
+	defer fake.drainingMutex.RUnlock()
+	return len(fake.drainingArgsForCall)
+}
+
+func (fake *Connection) DrainingCalls(stub func() bool) {
+	fake.drainingMutex.Lock()
+	defer fake.drainingMutex.Unlock()
+	fake.DrainingStub = stub
+}
+
+func (fake *Connection) DrainingReturns(result1 bool) {
+	fake.drainingMutex.Lock()
+	defer fake.drainingMutex.Unlock()
+	fake.DrainingStub = nil
+	fake.drainingReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *Connection) DrainingReturnsOnCall(i int, result1 bool) {
+	fake.drainingMutex.Lock()
+	defer fake.drainingMutex.Unlock()
+	fake.DrainingStub = nil
+	if fake.drainingReturnsOnCall == nil {
+		fake.drainingReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.drainingReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *Connection) EstablishedAt() time.Time {
 	fake.establishedAtMutex.Lock()
 	ret, specificReturn := fake.establishedAtReturnsOnCall[len(fake.establishedAtArgsForCall)]
@@ -734,6 +816,71 @@ func (fake *Connection) IsLocalReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *Connection) ManagementRequest(arg1 context.Context, arg2 *protocol.ManagementRequest) ([]byte, error) {
+	fake.managementRequestMutex.Lock()
+	ret, specificReturn := fake.managementRequestReturnsOnCall[len(fake.managementRequestArgsForCall)]
+	fake.managementRequestArgsForCall = append(fake.managementRequestArgsForCall, struct {
+		arg1 context.Context
+		arg2 *protocol.ManagementRequest
+	}{arg1, arg2})
+	stub := fake.ManagementRequestStub
+	fakeReturns := fake.managementRequestReturns
+	fake.recordInvocation("ManagementRequest", []interface{}{arg1, arg2})
+	fake.managementRequestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Connection) ManagementRequestCallCount() int {
+	fake.managementRequestMutex.RLock()
+	defer fake.managementRequestMutex.RUnlock()
+	return len(fake.managementRequestArgsForCall)
+}
+
+func (fake *Connection) ManagementRequestCalls(stub func(context.Context, *protocol.ManagementRequest) ([]byte, error)) {
+	fake.managementRequestMutex.Lock()
+	defer fake.managementRequestMutex.Unlock()
+	fake.ManagementRequestStub = stub
+}
+
+func (fake *Connection) ManagementRequestArgsForCall(i int) (context.Context, *protocol.ManagementRequest) {
+	fake.managementRequestMutex.RLock()
+	defer fake.managementRequestMutex.RUnlock()
+	argsForCall := fake.managementRequestArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Connection) ManagementRequestReturns(result1 []byte, result2 error) {
+	fake.managementRequestMutex.Lock()
+	defer fake.managementRequestMutex.Unlock()
+	fake.ManagementRequestStub = nil
+	fake.managementRequestReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Connection) ManagementRequestReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.managementRequestMutex.Lock()
+	defer fake.managementRequestMutex.Unlock()
+	fake.ManagementRequestStub = nil
+	if fake.managementRequestReturnsOnCall == nil {
+		fake.managementRequestReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.managementRequestReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Connection) Priority() int {
 	fake.priorityMutex.Lock()
 	ret, specificReturn := fake.priorityReturnsOnCall[len(fake.priorityArgsForCall)]
@@ -905,6 +1052,38 @@ func (fake *Connection) RequestReturnsOnCall(i int, result1 []byte, result2 erro
 	}{result1, result2}
 }
 
+func (fake *Connection) SetCompressionPolicy(arg1 protocol.CompressionPolicy) {
+	fake.setCompressionPolicyMutex.Lock()
+	fake.setCompressionPolicyArgsForCall = append(fake.setCompressionPolicyArgsForCall, struct {
+		arg1 protocol.CompressionPolicy
+	}{arg1})
+	stub := fake.SetCompressionPolicyStub
+	fake.recordInvocation("SetCompressionPolicy", []interface{}{arg1})
+	fake.setCompressionPolicyMutex.Unlock()
+	if stub != nil {
+		fake.SetCompressionPolicyStub(arg1)
+	}
+}
+
+func (fake *Connection) SetCompressionPolicyCallCount() int {
+	fake.setCompressionPolicyMutex.RLock()
+	defer fake.setCompressionPolicyMutex.RUnlock()
+	return len(fake.setCompressionPolicyArgsForCall)
+}
+
+func (fake *Connection) SetCompressionPolicyCalls(stub func(protocol.CompressionPolicy)) {
+	fake.setCompressionPolicyMutex.Lock()
+	defer fake.setCompressionPolicyMutex.Unlock()
+	fake.SetCompressionPolicyStub = stub
+}
+
+func (fake *Connection) SetCompressionPolicyArgsForCall(i int) protocol.CompressionPolicy {
+	fake.setCompressionPolicyMutex.RLock()
+	defer fake.setCompressionPolicyMutex.RUnlock()
+	argsForCall := fake.setCompressionPolicyArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *Connection) Start() {
 	fake.startMutex.Lock()
 	fake.startArgsForCall = append(fake.startArgsForCall, struct {
@@ -0,0 +1,113 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import "github.com/syncthing/syncthing/internal/gen/bep"
+
+type PosixACLTag = bep.PosixAclTag
+
+const (
+	PosixACLTagUserObj  = bep.PosixAclTag_POSIX_ACL_TAG_USER_OBJ
+	PosixACLTagUser     = bep.PosixAclTag_POSIX_ACL_TAG_USER
+	PosixACLTagGroupObj = bep.PosixAclTag_POSIX_ACL_TAG_GROUP_OBJ
+	PosixACLTagGroup    = bep.PosixAclTag_POSIX_ACL_TAG_GROUP
+	PosixACLTagMask     = bep.PosixAclTag_POSIX_ACL_TAG_MASK
+	PosixACLTagOther    = bep.PosixAclTag_POSIX_ACL_TAG_OTHER
+)
+
+// PosixACL holds the POSIX access ACL of an item, and the default ACL of a
+// directory. An empty ACL means the item has none beyond its permission
+// bits.
+type PosixACL struct {
+	Access  []PosixACLEntry
+	Default []PosixACLEntry
+}
+
+type PosixACLEntry struct {
+	Tag   PosixACLTag
+	Name  string // user or group name of named entries, when known
+	ID    int    // UID or GID of named entries
+	Perms uint32
+}
+
+// IsNamed returns true for entries about a specific user or group.
+func (e PosixACLEntry) IsNamed() bool {
+	return e.Tag == PosixACLTagUser || e.Tag == PosixACLTagGroup
+}
+
+func (a *PosixACL) toWire() *bep.PosixAcl {
+	if a == nil {
+		return nil
+	}
+	return &bep.PosixAcl{
+		Access:  posixACLEntriesToWire(a.Access),
+		Default: posixACLEntriesToWire(a.Default),
+	}
+}
+
+func posixACLFromWire(w *bep.PosixAcl) *PosixACL {
+	if w == nil {
+		return nil
+	}
+	return &PosixACL{
+		Access:  posixACLEntriesFromWire(w.Access),
+		Default: posixACLEntriesFromWire(w.Default),
+	}
+}
+
+func posixACLEntriesToWire(es []PosixACLEntry) []*bep.PosixAclEntry {
+	ws := make([]*bep.PosixAclEntry, len(es))
+	for i, e := range es {
+		ws[i] = &bep.PosixAclEntry{
+			Tag:   e.Tag,
+			Name:  e.Name,
+			Id:    int32(e.ID), //nolint:gosec
+			Perms: e.Perms,
+		}
+	}
+	return ws
+}
+
+func posixACLEntriesFromWire(ws []*bep.PosixAclEntry) []PosixACLEntry {
+	es := make([]PosixACLEntry, len(ws))
+	for i, w := range ws {
+		es[i] = PosixACLEntry{
+			Tag:   w.Tag,
+			Name:  w.Name,
+			ID:    int(w.Id),
+			Perms: w.Perms,
+		}
+	}
+	return es
+}
+
+func posixACLsEqual(a, b *PosixACL) bool {
+	if a == nil {
+		a = &PosixACL{}
+	}
+	if b == nil {
+		b = &PosixACL{}
+	}
+	return posixACLEntriesEqual(a.Access, b.Access) && posixACLEntriesEqual(a.Default, b.Default)
+}
+
+// posixACLEntriesEqual compares entries like ownership is compared: named
+// entries are the same if either their IDs or their names are.
+func posixACLEntriesEqual(a, b []PosixACLEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Tag != b[i].Tag || a[i].Perms != b[i].Perms {
+			return false
+		}
+		if a[i].IsNamed() && a[i].ID != b[i].ID && (a[i].Name == "" || a[i].Name != b[i].Name) {
+			return false
+		}
+	}
+	return true
+}
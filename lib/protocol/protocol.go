@@ -14,6 +14,7 @@
 package protocol
 
 import (
+	"bufio"
 	"context"
 	"encoding/binary"
 	"errors"
@@ -23,6 +24,7 @@ import (
 	"path"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	lz4 "github.com/pierrec/lz4/v4"
@@ -56,6 +58,12 @@ const (
 
 	// don't bother compressing messages smaller than this many bytes
 	compressionThreshold = 128
+
+	// size of the read and write buffers on the connection
+	connBufferSize = 64 << KiB
+
+	// management requests from the peer handled at the same time
+	maxManagementHandlers = 4
 )
 
 var errNotCompressible = errors.New("not compressible")
@@ -81,7 +89,8 @@ type Model interface {
 	Index(conn Connection, idx *Index) error
 	// An index update was received from the peer device
 	IndexUpdate(conn Connection, idxUp *IndexUpdate) error
-	// A request was made by the peer device
+	// A request was made by the peer device. The model clears
+	// req.Interactive if it doesn't serve the request as such.
 	Request(conn Connection, req *Request) (RequestResponse, error)
 	// A cluster configuration message was received
 	ClusterConfig(conn Connection, config *ClusterConfig) error
@@ -89,6 +98,8 @@ type Model interface {
 	Closed(conn Connection, err error)
 	// The peer device sent progress updates for the files it is currently downloading
 	DownloadProgress(conn Connection, p *DownloadProgress) error
+	// The peer device asked us to perform a management operation
+	ManagementRequest(conn Connection, req *ManagementRequest) ([]byte, error)
 }
 
 // rawModel is the Model interface, but without the initial Connection
@@ -100,6 +111,7 @@ type rawModel interface {
 	ClusterConfig(*ClusterConfig) error
 	Closed(err error)
 	DownloadProgress(*DownloadProgress) error
+	ManagementRequest(*ManagementRequest) ([]byte, error)
 }
 
 type RequestResponse interface {
@@ -129,13 +141,24 @@ type Connection interface {
 	// used further by the caller.
 	// For any folder that must be encrypted for the connected device, the
 	// password must be provided.
-	ClusterConfig(config *ClusterConfig, passwords map[string]string)
+	ClusterConfig(config *ClusterConfig, passwords map[string]EncryptionPassword)
 
 	// Send a Download Progress message to the peer device. The message in
 	// the parameter may be altered by the connection and should not be used
 	// further by the caller.
 	DownloadProgress(ctx context.Context, dp *DownloadProgress)
 
+	// Send a Management Request message to the peer device and return the
+	// response body. The peer must have consented to being managed by us.
+	ManagementRequest(ctx context.Context, req *ManagementRequest) ([]byte, error)
+
+	// Set which messages are compressed, and how, from now on.
+	SetCompressionPolicy(policy CompressionPolicy)
+
+	// Whether the connection is about to be closed, by either side, and
+	// takes no new requests.
+	Draining() bool
+
 	Start()
 	Close(err error)
 	DeviceID() DeviceID
@@ -168,23 +191,39 @@ type rawConnection struct {
 
 	cr     *countingReader
 	cw     *countingWriter
-	closer io.Closer // Closing the underlying connection and thus cr and cw
+	wbuf   *bufio.Writer // Below cw, flushed when no more messages are waiting
+	closer io.Closer     // Closing the underlying connection and thus cr and cw
+
+	unflushed []chan struct{} // done channels of buffered messages; writer loop only
 
 	awaitingMut sync.Mutex // Protects awaiting and nextID.
-	awaiting    map[int]chan asyncResult
+	awaiting    map[int]awaitingResponse
 	nextID      int
 
+	managementSlots chan struct{} // limits the management requests we handle at once
+
 	idxMut sync.Mutex // ensures serialization of Index calls
 
 	inbox                 chan proto.Message
 	outbox                chan asyncMessage
+	priorityOutbox        chan asyncMessage // interactive requests and their responses
 	closeBox              chan asyncMessage
 	clusterConfigBox      chan *ClusterConfig
+	sentClusterConfig     *bep.ClusterConfig // last sent; writer loop only
+	recvClusterConfig     *bep.ClusterConfig // last received; dispatcher loop only
+	peerAcceptsUpdates    atomic.Bool        // peer understands ClusterConfigUpdate
+	peerHasIndexDict      atomic.Bool        // peer can decompress index messages with our dictionary
+	peerAnswersPings      atomic.Bool        // peer replies to probe pings
+	peerAcceptsDraining   atomic.Bool        // peer understands Draining
+	peerDraining          atomic.Bool        // peer is about to close
+	draining              atomic.Bool        // we are about to close
+	inFlight              inFlight           // requests from the peer we're serving
+	tuner                 *connTuner
 	dispatcherLoopStopped chan struct{}
 	closed                chan struct{}
 	closeOnce             sync.Once
 	sendCloseOnce         sync.Once
-	compression           Compression
+	compression           atomic.Pointer[CompressionPolicy]
 	startStopMut          sync.Mutex // start and stop must be serialized
 
 	loopWG sync.WaitGroup // Need to ensure no leftover routines in testing
@@ -195,9 +234,17 @@ type asyncResult struct {
 	err error
 }
 
+// awaitingResponse is a request we sent. Block and management requests
+// share the same IDs, so the response must also be of the right kind.
+type awaitingResponse struct {
+	rc         chan asyncResult
+	management bool
+}
+
 type asyncMessage struct {
-	msg  proto.Message
-	done chan struct{} // done closes when we're done sending the message
+	msg    proto.Message
+	done   chan struct{} // done closes when we're done sending the message
+	folder string        // of a response, which doesn't say
 }
 
 const (
@@ -238,11 +285,15 @@ func NewConnection(deviceID DeviceID, reader io.Reader, writer io.Writer, closer
 
 func newRawConnection(deviceID DeviceID, reader io.Reader, writer io.Writer, closer io.Closer, receiver rawModel, connInfo ConnectionInfo, compress Compression) *rawConnection {
 	idString := deviceID.String()
-	cr := &countingReader{Reader: reader, idString: idString}
-	cw := &countingWriter{Writer: writer, idString: idString}
+	// Buffering on both sides lets many small messages, such as the
+	// requests and responses for folders full of tiny files, share reads,
+	// writes and packets on the wire.
+	cr := &countingReader{Reader: bufio.NewReaderSize(reader, connBufferSize), idString: idString}
+	wbuf := bufio.NewWriterSize(writer, connBufferSize)
+	cw := &countingWriter{Writer: wbuf, idString: idString}
 	registerDeviceMetrics(idString)
 
-	return &rawConnection{
+	c := &rawConnection{
 		ConnectionInfo:        connInfo,
 		deviceID:              deviceID,
 		idString:              deviceID.String(),
@@ -250,17 +301,22 @@ func newRawConnection(deviceID DeviceID, reader io.Reader, writer io.Writer, clo
 		started:               make(chan struct{}),
 		cr:                    cr,
 		cw:                    cw,
+		wbuf:                  wbuf,
 		closer:                closer,
-		awaiting:              make(map[int]chan asyncResult),
+		awaiting:              make(map[int]awaitingResponse),
+		managementSlots:       make(chan struct{}, maxManagementHandlers),
 		inbox:                 make(chan proto.Message),
 		outbox:                make(chan asyncMessage),
+		priorityOutbox:        make(chan asyncMessage),
 		closeBox:              make(chan asyncMessage),
 		clusterConfigBox:      make(chan *ClusterConfig),
 		dispatcherLoopStopped: make(chan struct{}),
 		closed:                make(chan struct{}),
-		compression:           compress,
+		tuner:                 newConnTuner(),
 		loopWG:                sync.WaitGroup{},
 	}
+	c.SetCompressionPolicy(CompressionPolicy{Default: CompressionSettings{Compression: compress}})
+	return c
 }
 
 // Start creates the goroutines for sending and receiving of messages. It must
@@ -280,14 +336,14 @@ func (c *rawConnection) Start() {
 	default:
 	}
 
-	c.loopWG.Add(5)
+	c.loopWG.Add(6)
 	go func() {
 		c.readerLoop()
 		c.loopWG.Done()
 	}()
 	go func() {
 		err := c.dispatcherLoop()
-		c.Close(err)
+		c.close(err)
 		c.loopWG.Done()
 	}()
 	go func() {
@@ -302,6 +358,10 @@ func (c *rawConnection) Start() {
 		c.pingReceiver()
 		c.loopWG.Done()
 	}()
+	go func() {
+		c.tuningLoop()
+		c.loopWG.Done()
+	}()
 
 	c.startTime = time.Now().Truncate(time.Second)
 	close(c.started)
@@ -351,24 +411,72 @@ func (c *rawConnection) Request(ctx context.Context, req *Request) ([]byte, erro
 	default:
 	}
 
+	// Requests are limited to the bytes the link can usefully have in
+	// flight, interactive ones included.
+	if err := c.tuner.window.TakeWithContext(ctx, req.Size); err != nil {
+		return nil, err
+	}
+	defer c.tuner.window.Give(req.Size)
+
+	if c.Draining() {
+		return nil, ErrDraining
+	}
+	id, rc := c.awaitResponse(false)
+	req.ID = id
+	ok := c.sendTo(ctx, c.outboxFor(req), asyncMessage{msg: req.toWire()})
+	if !ok {
+		c.forgetResponse(id)
+		return nil, ErrClosed
+	}
+	return c.waitResponse(ctx, id, rc)
+}
+
+// ManagementRequest sends a management request to the connected peer and
+// returns the response body.
+func (c *rawConnection) ManagementRequest(ctx context.Context, req *ManagementRequest) ([]byte, error) {
+	select {
+	case <-c.closed:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	id, rc := c.awaitResponse(true)
+	req.ID = id
+	ok := c.send(ctx, req.toWire(), nil)
+	if !ok {
+		c.forgetResponse(id)
+		return nil, ErrClosed
+	}
+	return c.waitResponse(ctx, id, rc)
+}
+
+// awaitResponse allocates a new message ID and registers a channel on which
+// the response to that ID will be delivered. Block and management requests
+// share the same ID space, and only a response of the same kind as the
+// request is delivered.
+func (c *rawConnection) awaitResponse(management bool) (int, chan asyncResult) {
 	rc := make(chan asyncResult, 1)
 
 	c.awaitingMut.Lock()
+	defer c.awaitingMut.Unlock()
 	id := c.nextID
 	c.nextID++
 	if _, ok := c.awaiting[id]; ok {
-		c.awaitingMut.Unlock()
 		panic("id taken")
 	}
-	c.awaiting[id] = rc
-	c.awaitingMut.Unlock()
+	c.awaiting[id] = awaitingResponse{rc: rc, management: management}
+	return id, rc
+}
 
-	req.ID = id
-	ok := c.send(ctx, req.toWire(), nil)
-	if !ok {
-		return nil, ErrClosed
-	}
+func (c *rawConnection) forgetResponse(id int) {
+	c.awaitingMut.Lock()
+	delete(c.awaiting, id)
+	c.awaitingMut.Unlock()
+}
 
+func (c *rawConnection) waitResponse(ctx context.Context, id int, rc chan asyncResult) ([]byte, error) {
 	select {
 	case res, ok := <-rc:
 		if !ok {
@@ -376,18 +484,41 @@ func (c *rawConnection) Request(ctx context.Context, req *Request) ([]byte, erro
 		}
 		return res.val, res.err
 	case <-ctx.Done():
+		c.forgetResponse(id)
 		return nil, ctx.Err()
 	}
 }
 
 // ClusterConfig sends the cluster configuration message to the peer.
-func (c *rawConnection) ClusterConfig(config *ClusterConfig, _ map[string]string) {
+func (c *rawConnection) ClusterConfig(config *ClusterConfig, _ map[string]EncryptionPassword) {
 	select {
 	case c.clusterConfigBox <- config:
 	case <-c.closed:
 	}
 }
 
+// clusterConfigMessage returns the message to send for the cluster config:
+// an update against the previous one if the peer accepts that and it is
+// smaller, otherwise the full cluster config.
+func (c *rawConnection) clusterConfigMessage(cc *ClusterConfig) proto.Message {
+	msg := cc.toWire()
+	msg.AcceptsUpdates = true
+	msg.IndexDictionaries = indexDictionaries()
+	msg.AnswersPings = true
+	msg.AcceptsDraining = true
+	for _, f := range msg.Folders {
+		f.CompressedEncryption = true
+	}
+	prev := c.sentClusterConfig
+	c.sentClusterConfig = msg
+	if prev != nil && c.peerAcceptsUpdates.Load() {
+		if up := clusterConfigUpdate(prev, msg); up != nil {
+			return up
+		}
+	}
+	return msg
+}
+
 func (c *rawConnection) Closed() <-chan struct{} {
 	return c.closed
 }
@@ -413,6 +544,13 @@ func (c *rawConnection) readerLoop() {
 			c.internalClose(err)
 			return
 		}
+		if ping, ok := msg.(*bep.Ping); ok && ping.Id != 0 {
+			// Probes are answered and timed as they are read, so that the
+			// round trip time doesn't include the time spent dispatching
+			// the messages read before them.
+			c.handlePing(ping)
+			continue
+		}
 		select {
 		case c.inbox <- msg:
 		case <-c.closed:
@@ -468,8 +606,17 @@ func (c *rawConnection) dispatcherLoop() (err error) {
 
 		switch msg := msg.(type) {
 		case *bep.ClusterConfig:
+			c.peerAcceptsUpdates.Store(msg.AcceptsUpdates)
+			c.peerHasIndexDict.Store(peerHasIndexDict(msg.IndexDictionaries))
+			c.peerAnswersPings.Store(msg.AnswersPings)
+			c.peerAcceptsDraining.Store(msg.AcceptsDraining)
+			c.recvClusterConfig = msg
 			err = c.model.ClusterConfig(clusterConfigFromWire(msg))
 
+		case *bep.ClusterConfigUpdate:
+			c.recvClusterConfig = applyClusterConfigUpdate(c.recvClusterConfig, msg)
+			err = c.model.ClusterConfig(clusterConfigFromWire(c.recvClusterConfig))
+
 		case *bep.Index:
 			idx := indexFromWire(msg)
 			if err := checkIndexConsistency(idx.Files); err != nil {
@@ -485,6 +632,7 @@ func (c *rawConnection) dispatcherLoop() (err error) {
 			err = c.handleIndexUpdate(idxUp)
 
 		case *bep.Request:
+			c.inFlight.add()
 			go c.handleRequest(requestFromWire(msg))
 
 		case *bep.Response:
@@ -492,6 +640,26 @@ func (c *rawConnection) dispatcherLoop() (err error) {
 
 		case *bep.DownloadProgress:
 			err = c.model.DownloadProgress(downloadProgressFromWire(msg))
+
+		case *bep.ManagementRequest:
+			// Waits for one of the handlers to finish if they're all
+			// busy, rather than starting any number of them.
+			select {
+			case c.managementSlots <- struct{}{}:
+			case <-c.closed:
+				return ErrClosed
+			}
+			go c.handleManagementRequest(managementRequestFromWire(msg))
+
+		case *bep.ManagementResponse:
+			c.handleManagementResponse(managementResponseFromWire(msg))
+
+		case *bep.Ping:
+			c.handlePing(msg)
+
+		case *bep.Draining:
+			l.Debugf("connection to %s is draining", c.deviceID.Short())
+			c.peerDraining.Store(true)
 		}
 		if err != nil {
 			return newHandleError(err, msgContext)
@@ -543,6 +711,13 @@ func (c *rawConnection) readMessageAfterHeader(hdr *bep.Header, fourByteBuf []by
 		}
 		buf = decomp
 
+	case bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD:
+		decomp, err := zstdDecompress(buf)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing message: %w", err)
+		}
+		buf = decomp
+
 	default:
 		return nil, fmt.Errorf("unknown message compression %d", hdr.Compression)
 	}
@@ -666,13 +841,17 @@ func checkFilename(name string) error {
 }
 
 func (c *rawConnection) handleRequest(req *Request) {
+	defer c.inFlight.done()
+	// The model decides whether the request is served as interactive, so
+	// which outbox the response goes to is only known after.
 	res, err := c.model.Request(req)
+	outbox := c.outboxFor(req)
 	if err != nil {
 		resp := &Response{
 			ID:   req.ID,
 			Code: errorToCode(err),
 		}
-		c.send(context.Background(), resp.toWire(), nil)
+		c.sendTo(context.Background(), outbox, asyncMessage{msg: resp.toWire(), folder: req.Folder})
 		return
 	}
 	done := make(chan struct{})
@@ -681,45 +860,79 @@ func (c *rawConnection) handleRequest(req *Request) {
 		Data: res.Data(),
 		Code: errorToCode(nil),
 	}
-	c.send(context.Background(), resp.toWire(), done)
+	c.sendTo(context.Background(), outbox, asyncMessage{msg: resp.toWire(), done: done, folder: req.Folder})
 	<-done
 	res.Close()
 }
 
 func (c *rawConnection) handleResponse(resp *Response) {
+	c.deliverResponse(resp.ID, false, asyncResult{resp.Data, codeToError(resp.Code)})
+}
+
+func (c *rawConnection) handleManagementRequest(req *ManagementRequest) {
+	defer func() { <-c.managementSlots }()
+	resp := &ManagementResponse{ID: req.ID}
+	body, err := c.model.ManagementRequest(req)
+	if err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Body = body
+	}
+	c.send(context.Background(), resp.toWire(), nil)
+}
+
+func (c *rawConnection) handleManagementResponse(resp *ManagementResponse) {
+	c.deliverResponse(resp.ID, true, asyncResult{resp.Body, resp.err()})
+}
+
+// deliverResponse delivers the response to the request waiting for it,
+// unless that is a request of another kind.
+func (c *rawConnection) deliverResponse(id int, management bool, res asyncResult) {
 	c.awaitingMut.Lock()
-	if rc := c.awaiting[resp.ID]; rc != nil {
-		delete(c.awaiting, resp.ID)
-		rc <- asyncResult{resp.Data, codeToError(resp.Code)}
-		close(rc)
+	if aw, ok := c.awaiting[id]; ok && aw.management == management {
+		delete(c.awaiting, id)
+		aw.rc <- res
+		close(aw.rc)
 	}
 	c.awaitingMut.Unlock()
 }
 
 func (c *rawConnection) send(ctx context.Context, msg proto.Message, done chan struct{}) bool {
+	return c.sendTo(ctx, c.outbox, asyncMessage{msg: msg, done: done})
+}
+
+// outboxFor returns the outbox for a request and its response, letting
+// interactive ones skip ahead of regular syncing.
+func (c *rawConnection) outboxFor(req *Request) chan asyncMessage {
+	if req.Interactive {
+		return c.priorityOutbox
+	}
+	return c.outbox
+}
+
+func (c *rawConnection) sendTo(ctx context.Context, outbox chan asyncMessage, hm asyncMessage) bool {
 	select {
-	case c.outbox <- asyncMessage{msg, done}:
+	case outbox <- hm:
 		return true
 	case <-c.closed:
 	case <-ctx.Done():
 	}
-	if done != nil {
-		close(done)
+	if hm.done != nil {
+		close(hm.done)
 	}
 	return false
 }
 
 func (c *rawConnection) writerLoop() {
+	defer c.closeUnflushed()
+
 	select {
 	case cc := <-c.clusterConfigBox:
-		err := c.writeMessage(cc.toWire())
-		if err != nil {
-			c.internalClose(err)
+		if !c.writeAsync(asyncMessage{msg: c.clusterConfigMessage(cc)}) || !c.flush() {
 			return
 		}
 	case hm := <-c.closeBox:
-		_ = c.writeMessage(hm.msg)
-		close(hm.done)
+		c.writeClose(hm)
 		return
 	case <-c.closed:
 		return
@@ -729,33 +942,62 @@ func (c *rawConnection) writerLoop() {
 		// immediately, not compete with the (potentially very busy) outbox.
 		select {
 		case hm := <-c.closeBox:
-			_ = c.writeMessage(hm.msg)
-			close(hm.done)
+			c.writeClose(hm)
 			return
 		case <-c.closed:
 			return
 		default:
 		}
+		// Interactive traffic goes ahead of whatever is waiting in the
+		// outbox.
+		select {
+		case hm := <-c.priorityOutbox:
+			if !c.writeAsync(hm) || !c.flush() {
+				return
+			}
+			continue
+		default:
+		}
+		// Keep buffering while there are more messages to send, and put
+		// what we have on the wire before waiting for more. This only
+		// coalesces the writes; each message is still sent and answered
+		// on its own. Interactive messages that came in during the flush
+		// go first after it.
+		if c.wbuf.Buffered() > 0 {
+			select {
+			case cc := <-c.clusterConfigBox:
+				if !c.writeAsync(asyncMessage{msg: c.clusterConfigMessage(cc)}) {
+					return
+				}
+				continue
+			case hm := <-c.outbox:
+				if !c.writeAsync(hm) {
+					return
+				}
+				continue
+			default:
+			}
+			if !c.flush() {
+				return
+			}
+			continue
+		}
 		select {
 		case cc := <-c.clusterConfigBox:
-			err := c.writeMessage(cc.toWire())
-			if err != nil {
-				c.internalClose(err)
+			if !c.writeAsync(asyncMessage{msg: c.clusterConfigMessage(cc)}) {
 				return
 			}
-		case hm := <-c.outbox:
-			err := c.writeMessage(hm.msg)
-			if hm.done != nil {
-				close(hm.done)
+		case hm := <-c.priorityOutbox:
+			if !c.writeAsync(hm) || !c.flush() {
+				return
 			}
-			if err != nil {
-				c.internalClose(err)
+		case hm := <-c.outbox:
+			if !c.writeAsync(hm) {
 				return
 			}
 
 		case hm := <-c.closeBox:
-			_ = c.writeMessage(hm.msg)
-			close(hm.done)
+			c.writeClose(hm)
 			return
 
 		case <-c.closed:
@@ -764,7 +1006,51 @@ func (c *rawConnection) writerLoop() {
 	}
 }
 
-func (c *rawConnection) writeMessage(msg proto.Message) error {
+// writeAsync writes a message from an outbox, returning false if the
+// connection was closed due to an error. Its done channel is closed once
+// the message is flushed.
+func (c *rawConnection) writeAsync(hm asyncMessage) bool {
+	err := c.writeMessage(hm.msg, hm.folder)
+	if hm.done != nil {
+		c.unflushed = append(c.unflushed, hm.done)
+	}
+	if err != nil {
+		c.internalClose(err)
+		return false
+	}
+	return true
+}
+
+// flush writes out buffered messages, returning false if the connection
+// was closed due to an error.
+func (c *rawConnection) flush() bool {
+	err := c.wbuf.Flush()
+	c.closeUnflushed()
+	if err != nil {
+		c.internalClose(fmt.Errorf("writing message: %w", err))
+		return false
+	}
+	return true
+}
+
+func (c *rawConnection) closeUnflushed() {
+	for _, done := range c.unflushed {
+		close(done)
+	}
+	c.unflushed = c.unflushed[:0]
+}
+
+// writeClose writes the close message and whatever was buffered before it.
+func (c *rawConnection) writeClose(hm asyncMessage) {
+	if err := c.writeMessage(hm.msg, hm.folder); err == nil {
+		_ = c.wbuf.Flush()
+	}
+	close(hm.done)
+}
+
+// writeMessage writes a message, compressed as set for the folder it is
+// about, or the given folder if it doesn't say.
+func (c *rawConnection) writeMessage(msg proto.Message, folder string) error {
 	msgContext, _ := messageContext(msg)
 	l.Debugf("Writing %v", msgContext)
 
@@ -791,8 +1077,12 @@ func (c *rawConnection) writeMessage(msg proto.Message) error {
 		return fmt.Errorf("marshalling message: %w", err)
 	}
 
-	if c.shouldCompressMessage(msg) {
-		ok, err := c.writeCompressedMessage(msg, buf[overhead:])
+	if f := messageFolder(msg); f != "" {
+		folder = f
+	}
+	settings := c.compression.Load().settings(folder)
+	if shouldCompressMessage(msg, settings.Compression) {
+		ok, err := c.writeCompressedMessage(msg, buf[overhead:], c.messageCompression(msg, settings.Algorithm))
 		if ok {
 			return err
 		}
@@ -822,10 +1112,10 @@ func (c *rawConnection) writeMessage(msg proto.Message) error {
 //
 // The first return value indicates whether compression succeeded.
 // If not, the caller should retry without compression.
-func (c *rawConnection) writeCompressedMessage(msg proto.Message, marshaled []byte) (ok bool, err error) {
+func (c *rawConnection) writeCompressedMessage(msg proto.Message, marshaled []byte, compression bep.MessageCompression) (ok bool, err error) {
 	hdr := &bep.Header{
 		Type:        typeOf(msg),
-		Compression: bep.MessageCompression_MESSAGE_COMPRESSION_LZ4,
+		Compression: compression,
 	}
 	hdrSize := proto.Size(hdr)
 	if hdrSize > 1<<16-1 {
@@ -843,7 +1133,12 @@ func (c *rawConnection) writeCompressedMessage(msg proto.Message, marshaled []by
 	buf := BufferPool.Get(maxCompressed)
 	defer BufferPool.Put(buf)
 
-	compressedSize, err := lz4Compress(marshaled, buf[cOverhead:])
+	var compressedSize int
+	if compression == bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD {
+		compressedSize, err = zstdCompress(marshaled, buf[cOverhead:])
+	} else {
+		compressedSize, err = lz4Compress(marshaled, buf[cOverhead:])
+	}
 	totSize := compressedSize + cOverhead
 	if err != nil {
 		return false, nil
@@ -884,6 +1179,14 @@ func typeOf(msg proto.Message) bep.MessageType {
 		return bep.MessageType_MESSAGE_TYPE_PING
 	case *bep.Close:
 		return bep.MessageType_MESSAGE_TYPE_CLOSE
+	case *bep.ManagementRequest:
+		return bep.MessageType_MESSAGE_TYPE_MANAGEMENT_REQUEST
+	case *bep.ManagementResponse:
+		return bep.MessageType_MESSAGE_TYPE_MANAGEMENT_RESPONSE
+	case *bep.ClusterConfigUpdate:
+		return bep.MessageType_MESSAGE_TYPE_CLUSTER_CONFIG_UPDATE
+	case *bep.Draining:
+		return bep.MessageType_MESSAGE_TYPE_DRAINING
 	default:
 		panic("bug: unknown message type")
 	}
@@ -907,13 +1210,37 @@ func newMessage(t bep.MessageType) (proto.Message, error) {
 		return new(bep.Ping), nil
 	case bep.MessageType_MESSAGE_TYPE_CLOSE:
 		return new(bep.Close), nil
+	case bep.MessageType_MESSAGE_TYPE_MANAGEMENT_REQUEST:
+		return new(bep.ManagementRequest), nil
+	case bep.MessageType_MESSAGE_TYPE_MANAGEMENT_RESPONSE:
+		return new(bep.ManagementResponse), nil
+	case bep.MessageType_MESSAGE_TYPE_CLUSTER_CONFIG_UPDATE:
+		return new(bep.ClusterConfigUpdate), nil
+	case bep.MessageType_MESSAGE_TYPE_DRAINING:
+		return new(bep.Draining), nil
 	default:
 		return nil, errUnknownMessage
 	}
 }
 
-func (c *rawConnection) shouldCompressMessage(msg proto.Message) bool {
-	switch c.compression {
+// messageCompression returns how to compress a message that should be
+// compressed. Peers that have our index dictionary can decompress zstd.
+func (c *rawConnection) messageCompression(msg proto.Message, algo CompressionAlgorithm) bep.MessageCompression {
+	if !c.peerHasIndexDict.Load() {
+		return bep.MessageCompression_MESSAGE_COMPRESSION_LZ4
+	}
+	switch msg.(type) {
+	case *bep.Index, *bep.IndexUpdate:
+		return bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD
+	}
+	if algo == CompressionAlgorithmZstd {
+		return bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD
+	}
+	return bep.MessageCompression_MESSAGE_COMPRESSION_LZ4
+}
+
+func shouldCompressMessage(msg proto.Message, compression Compression) bool {
+	switch compression {
 	case CompressionNever:
 		return false
 
@@ -932,14 +1259,22 @@ func (c *rawConnection) shouldCompressMessage(msg proto.Message) bool {
 }
 
 // Close is called when the connection is regularly closed and thus the Close
-// BEP message is sent before terminating the actual connection. The error
-// argument specifies the reason for closing the connection.
+// BEP message is sent before terminating the actual connection, after
+// draining the requests in flight. The error argument specifies the reason
+// for closing the connection.
 func (c *rawConnection) Close(err error) {
+	c.drain()
+	c.close(err)
+}
+
+// close sends the Close message and terminates the connection, without
+// draining.
+func (c *rawConnection) close(err error) {
 	c.sendCloseOnce.Do(func() {
 		done := make(chan struct{})
 		timeout := time.NewTimer(CloseTimeout)
 		select {
-		case c.closeBox <- asyncMessage{&bep.Close{Reason: err.Error()}, done}:
+		case c.closeBox <- asyncMessage{msg: &bep.Close{Reason: err.Error()}, done: done}:
 			select {
 			case <-done:
 			case <-timeout.C:
@@ -969,11 +1304,9 @@ func (c *rawConnection) internalClose(err error) {
 		close(c.closed)
 
 		c.awaitingMut.Lock()
-		for i, ch := range c.awaiting {
-			if ch != nil {
-				close(ch)
-				delete(c.awaiting, i)
-			}
+		for i, aw := range c.awaiting {
+			close(aw.rc)
+			delete(c.awaiting, i)
 		}
 		c.awaitingMut.Unlock()
 
@@ -1018,6 +1351,55 @@ func (c *rawConnection) pingSender() {
 	}
 }
 
+// The tuningLoop regularly samples the connection and probes the round
+// trip time, and adjusts the socket buffers and request window to match.
+func (c *rawConnection) tuningLoop() {
+	ticker := time.NewTicker(tuningInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			c.tuner.sample(now, c.cr.Tot(), c.cw.Tot())
+			if size, ok := c.tuner.tune(); ok {
+				c.setSocketBuffers(size)
+			}
+			if c.peerAnswersPings.Load() {
+				id := c.tuner.probe(now)
+				c.sendTo(context.Background(), c.priorityOutbox, asyncMessage{msg: &bep.Ping{Id: id}})
+			}
+
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func (c *rawConnection) setSocketBuffers(size int) {
+	sbs, ok := c.ConnectionInfo.(socketBufferSetter)
+	if !ok {
+		c.tuner.setBufferFailed()
+		return
+	}
+	if err := sbs.SetSocketBuffers(size); err != nil {
+		l.Debugln(c.deviceID, "setting socket buffers:", err)
+		c.tuner.setBufferFailed()
+		return
+	}
+	l.Debugln(c.deviceID, "socket buffers set to", size)
+}
+
+// handlePing answers probes and records the answers to ours.
+func (c *rawConnection) handlePing(msg *bep.Ping) {
+	switch {
+	case msg.Reply:
+		c.tuner.answered(msg.Id, time.Now())
+	case msg.Id != 0:
+		go c.sendTo(context.Background(), c.priorityOutbox, asyncMessage{msg: &bep.Ping{Id: msg.Id, Reply: true}})
+	}
+}
+
 // The pingReceiver checks that we've received a message (any message will do,
 // but we expect pings in the absence of other messages) within the last
 // ReceiveTimeout. If not, we close the connection with an ErrTimeout.
@@ -1047,6 +1429,8 @@ type Statistics struct {
 	InBytesTotal  int64     `json:"inBytesTotal"`
 	OutBytesTotal int64     `json:"outBytesTotal"`
 	StartedAt     time.Time `json:"startedAt"`
+
+	Tuning ConnectionTuning `json:"tuning"`
 }
 
 func (c *rawConnection) Statistics() Statistics {
@@ -1055,6 +1439,7 @@ func (c *rawConnection) Statistics() Statistics {
 		InBytesTotal:  c.cr.Tot(),
 		OutBytesTotal: c.cw.Tot(),
 		StartedAt:     c.startTime,
+		Tuning:        c.tuner.tuning(),
 	}
 }
 
@@ -1097,6 +1482,8 @@ func messageContext(msg proto.Message) (string, error) {
 	switch msg := msg.(type) {
 	case *bep.ClusterConfig:
 		return "cluster-config", nil
+	case *bep.ClusterConfigUpdate:
+		return "cluster-config-update", nil
 	case *bep.Index:
 		return fmt.Sprintf("index for %v", msg.Folder), nil
 	case *bep.IndexUpdate:
@@ -1111,6 +1498,12 @@ func messageContext(msg proto.Message) (string, error) {
 		return "ping", nil
 	case *bep.Close:
 		return "close", nil
+	case *bep.ManagementRequest:
+		return fmt.Sprintf("management-request %v", msg.Operation), nil
+	case *bep.ManagementResponse:
+		return "management-response", nil
+	case *bep.Draining:
+		return "draining", nil
 	default:
 		return "", errors.New("unknown or empty message")
 	}
@@ -1147,3 +1540,7 @@ func (c *connectionWrappingModel) Closed(err error) {
 func (c *connectionWrappingModel) DownloadProgress(p *DownloadProgress) error {
 	return c.model.DownloadProgress(c.conn, p)
 }
+
+func (c *connectionWrappingModel) ManagementRequest(req *ManagementRequest) ([]byte, error) {
+	return c.model.ManagementRequest(c.conn, req)
+}
@@ -11,7 +11,10 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -50,6 +53,185 @@ func TestPing(t *testing.T) {
 	}
 }
 
+func TestManagementRequest(t *testing.T) {
+	m1 := newTestModel()
+	m1.managementFn = func(req *ManagementRequest) ([]byte, error) {
+		switch req.Operation {
+		case ManagementOperationGetConfig:
+			return []byte("config"), nil
+		default:
+			return nil, errors.New("refused")
+		}
+	}
+
+	ar, aw := io.Pipe()
+	br, bw := io.Pipe()
+
+	c0 := getRawConnection(NewConnection(c0ID, ar, bw, testutil.NoopCloser{}, newTestModel(), new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c0.Start()
+	defer closeAndWait(c0, ar, bw)
+	c1 := getRawConnection(NewConnection(c1ID, br, aw, testutil.NoopCloser{}, m1, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c1.Start()
+	defer closeAndWait(c1, ar, bw)
+	c0.ClusterConfig(&ClusterConfig{}, nil)
+	c1.ClusterConfig(&ClusterConfig{}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bs, err := c0.ManagementRequest(ctx, &ManagementRequest{Operation: ManagementOperationGetConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != "config" {
+		t.Errorf("unexpected response %q", bs)
+	}
+
+	_, err = c0.ManagementRequest(ctx, &ManagementRequest{Operation: ManagementOperationSetConfig})
+	if err == nil || err.Error() != "refused" {
+		t.Errorf("expected refusal, got %v", err)
+	}
+}
+
+func TestManagementResponseKind(t *testing.T) {
+	c := getRawConnection(NewConnection(c0ID, &testutil.BlockingRW{}, &testutil.BlockingRW{}, testutil.NoopCloser{}, newTestModel(), new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+
+	// A block response doesn't answer a management request with the same
+	// ID, nor the other way around.
+	mgmtID, mgmtRC := c.awaitResponse(true)
+	reqID, reqRC := c.awaitResponse(false)
+	c.handleResponse(&Response{ID: mgmtID, Data: []byte("block")})
+	c.handleManagementResponse(&ManagementResponse{ID: reqID, Body: []byte("management")})
+	select {
+	case res := <-mgmtRC:
+		t.Fatalf("management request got block response %q", res.val)
+	case res := <-reqRC:
+		t.Fatalf("block request got management response %q", res.val)
+	default:
+	}
+
+	c.handleManagementResponse(&ManagementResponse{ID: mgmtID, Body: []byte("management")})
+	if res := <-mgmtRC; string(res.val) != "management" {
+		t.Errorf("unexpected management response %q", res.val)
+	}
+	c.handleResponse(&Response{ID: reqID, Data: []byte("block")})
+	if res := <-reqRC; string(res.val) != "block" {
+		t.Errorf("unexpected block response %q", res.val)
+	}
+}
+
+func TestManagementRequestsLimited(t *testing.T) {
+	var mut sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	m1 := newTestModel()
+	m1.managementFn = func(*ManagementRequest) ([]byte, error) {
+		mut.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mut.Unlock()
+		<-release
+		mut.Lock()
+		running--
+		mut.Unlock()
+		return nil, nil
+	}
+
+	ar, aw := io.Pipe()
+	br, bw := io.Pipe()
+
+	c0 := getRawConnection(NewConnection(c0ID, ar, bw, testutil.NoopCloser{}, newTestModel(), new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c0.Start()
+	defer closeAndWait(c0, ar, bw)
+	c1 := getRawConnection(NewConnection(c1ID, br, aw, testutil.NoopCloser{}, m1, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c1.Start()
+	defer closeAndWait(c1, ar, bw)
+	c0.ClusterConfig(&ClusterConfig{}, nil)
+	c1.ClusterConfig(&ClusterConfig{}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	const requests = 3 * maxManagementHandlers
+	var wg sync.WaitGroup
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c0.ManagementRequest(ctx, &ManagementRequest{Operation: ManagementOperationGetConfig}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	// Let the handlers pile up before releasing them one at a time.
+	time.Sleep(100 * time.Millisecond)
+	for range requests {
+		release <- struct{}{}
+	}
+	wg.Wait()
+
+	mut.Lock()
+	defer mut.Unlock()
+	if maxRunning > maxManagementHandlers {
+		t.Errorf("expected at most %d management requests handled at once, got %d", maxManagementHandlers, maxRunning)
+	}
+}
+
+func TestClusterConfigUpdates(t *testing.T) {
+	m0 := newTestModel()
+	c0Received := make(chan struct{}, 1)
+	m0.ccFn = func(*ClusterConfig) { c0Received <- struct{}{} }
+	m1 := newTestModel()
+	c1Received := make(chan *ClusterConfig, 1)
+	m1.ccFn = func(cc *ClusterConfig) { c1Received <- cc }
+
+	ar, aw := io.Pipe()
+	br, bw := io.Pipe()
+
+	c0 := getRawConnection(NewConnection(c0ID, ar, bw, testutil.NoopCloser{}, m0, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c0.Start()
+	defer closeAndWait(c0, ar, bw)
+	c1 := getRawConnection(NewConnection(c1ID, br, aw, testutil.NoopCloser{}, m1, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c1.Start()
+	defer closeAndWait(c1, ar, bw)
+
+	folder := func(id, label string) Folder {
+		return Folder{ID: id, Label: label, Devices: []Device{{ID: c0ID, Name: "c0"}, {ID: c1ID, Name: "c1"}}}
+	}
+	receive := func() *ClusterConfig {
+		t.Helper()
+		select {
+		case cc := <-c1Received:
+			return cc
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for cluster config")
+			return nil
+		}
+	}
+
+	c1.ClusterConfig(&ClusterConfig{}, nil)
+	c0.ClusterConfig(&ClusterConfig{Folders: []Folder{folder("a", "A"), folder("b", "B"), folder("c", "C")}}, nil)
+	receive()
+	<-c0Received
+	if !c0.peerAcceptsUpdates.Load() {
+		t.Fatal("expected the peer to accept updates")
+	}
+
+	c0.ClusterConfig(&ClusterConfig{Folders: []Folder{folder("a", "A"), folder("c", "C2"), folder("d", "D")}}, nil)
+	cc := receive()
+	var labels []string
+	for _, f := range cc.Folders {
+		labels = append(labels, f.ID+"="+f.Label)
+	}
+	if exp := []string{"a=A", "c=C2", "d=D"}; !slices.Equal(labels, exp) {
+		t.Errorf("expected folders %v, got %v", exp, labels)
+	}
+	if !c1.peerAcceptsUpdates.Load() {
+		t.Error("expected the peer to still accept updates")
+	}
+}
+
 var errManual = errors.New("manual close")
 
 func TestClose(t *testing.T) {
@@ -200,7 +382,7 @@ func TestClusterConfigFirst(t *testing.T) {
 	defer closeAndWait(c, rw)
 
 	select {
-	case c.outbox <- asyncMessage{&bep.Ping{}, nil}:
+	case c.outbox <- asyncMessage{msg: &bep.Ping{}}:
 		t.Fatal("able to send ping before cluster config")
 	case <-time.After(100 * time.Millisecond):
 		// Allow some time for c.writerLoop to set up after c.Start
@@ -264,6 +446,125 @@ func TestCloseTimeout(t *testing.T) {
 	}
 }
 
+// TestDrain checks that a closing connection finishes the requests in
+// flight, and that the peer takes no new ones meanwhile.
+func TestDrain(t *testing.T) {
+	m0 := newTestModel()
+	m1 := newTestModel()
+	m1.data = []byte("block")
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	m1.requestFn = func(*Request) {
+		close(requested)
+		<-release
+	}
+
+	ar, aw := io.Pipe()
+	br, bw := io.Pipe()
+
+	c0 := getRawConnection(NewConnection(c0ID, ar, bw, testutil.NoopCloser{}, m0, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c0.Start()
+	defer closeAndWait(c0, ar, bw)
+	c1 := getRawConnection(NewConnection(c1ID, br, aw, testutil.NoopCloser{}, m1, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c1.Start()
+	defer closeAndWait(c1, ar, bw)
+	c0.ClusterConfig(&ClusterConfig{}, nil)
+	c1.ClusterConfig(&ClusterConfig{}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := make(chan error, 1)
+	go func() {
+		data, err := c0.Request(ctx, &Request{Folder: "default", Name: "foo", Size: len(m1.data)})
+		if err == nil && string(data) != "block" {
+			err = fmt.Errorf("unexpected response %q", data)
+		}
+		res <- err
+	}()
+	select {
+	case <-requested:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the request")
+	}
+
+	go c1.Close(errManual)
+	for !c0.Draining() {
+		if ctx.Err() != nil {
+			t.Fatal("timed out waiting for the peer to drain")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := c0.Request(ctx, &Request{Folder: "default", Name: "bar", Size: 1}); !errors.Is(err, ErrDraining) {
+		t.Errorf("expected %v, got %v", ErrDraining, err)
+	}
+	select {
+	case <-m0.closedCh:
+		t.Fatal("connection closed with a request in flight")
+	default:
+	}
+
+	close(release)
+	if err := <-res; err != nil {
+		t.Fatal(err)
+	}
+	if err := m0.closedError(); err == nil || !strings.Contains(err.Error(), errManual.Error()) {
+		t.Errorf("expected to be closed by the peer, got %v", err)
+	}
+}
+
+// TestDrainIdle checks that the peer is told about draining even when
+// nothing is in flight, and that our own requests don't hold up closing.
+func TestDrainIdle(t *testing.T) {
+	m0 := newTestModel()
+	m1 := newTestModel()
+	requested := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	m0.requestFn = func(*Request) {
+		close(requested)
+		<-release
+	}
+
+	ar, aw := io.Pipe()
+	br, bw := io.Pipe()
+
+	c0 := getRawConnection(NewConnection(c0ID, ar, bw, testutil.NoopCloser{}, m0, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c0.Start()
+	defer closeAndWait(c0, ar, bw)
+	c1 := getRawConnection(NewConnection(c1ID, br, aw, testutil.NoopCloser{}, m1, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c1.Start()
+	defer closeAndWait(c1, ar, bw)
+	c0.ClusterConfig(&ClusterConfig{}, nil)
+	c1.ClusterConfig(&ClusterConfig{}, nil)
+
+	// Wait for the cluster configs to be exchanged.
+	for !c0.peerAcceptsDraining.Load() || !c1.peerAcceptsDraining.Load() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	go c1.Request(t.Context(), &Request{Folder: "default", Name: "foo", Size: 1})
+	select {
+	case <-requested:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the request")
+	}
+
+	start := time.Now()
+	c1.Close(errManual)
+	if d := time.Since(start); d >= DrainTimeout {
+		t.Errorf("closing took %v, waiting for our own request", d)
+	}
+	select {
+	case <-m0.closedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the peer to see the close")
+	}
+	if !c0.peerDraining.Load() {
+		t.Error("expected the peer to be told about draining")
+	}
+}
+
 func TestUnmarshalFDPUv16v17(t *testing.T) {
 	var fdpu bep.FileDownloadProgressUpdate
 
@@ -282,10 +583,10 @@ func TestWriteCompressed(t *testing.T) {
 	for _, random := range []bool{false, true} {
 		buf := new(bytes.Buffer)
 		c := &rawConnection{
-			cr:          &countingReader{Reader: buf},
-			cw:          &countingWriter{Writer: buf},
-			compression: CompressionAlways,
+			cr: &countingReader{Reader: buf},
+			cw: &countingWriter{Writer: buf},
 		}
+		c.SetCompressionPolicy(CompressionPolicy{Default: CompressionSettings{Compression: CompressionAlways}})
 
 		msg := (&Response{Data: make([]byte, 10240)}).toWire()
 		if random {
@@ -293,7 +594,7 @@ func TestWriteCompressed(t *testing.T) {
 			rand.Read(msg.Data)
 		}
 
-		if err := c.writeMessage(msg); err != nil {
+		if err := c.writeMessage(msg, ""); err != nil {
 			t.Fatal(err)
 		}
 		got, err := c.readMessage(make([]byte, 4))
@@ -313,6 +614,115 @@ func TestWriteCompressed(t *testing.T) {
 	}
 }
 
+func TestWriteIndexDictCompressed(t *testing.T) {
+	files := make([]*bep.FileInfo, 1000)
+	for i := range files {
+		hash := make([]byte, 32)
+		rand.Read(hash)
+		fi := FileInfo{
+			Name:        fmt.Sprintf("Photos/2025/IMG_%04d.jpg", i),
+			Size:        int64(1000000 + i),
+			ModifiedS:   1700000000 + int64(i),
+			Permissions: 0o644,
+			Version:     Vector{}.Update(c0ID.Short()),
+			Sequence:    int64(i + 1),
+			Blocks:      []BlockInfo{{Size: 1000000 + i, Hash: hash}},
+		}
+		files[i] = fi.ToWire(false)
+	}
+	msg := &bep.Index{Folder: "default", Files: files}
+
+	sizes := make(map[bep.MessageCompression]int64)
+	for _, hasDict := range []bool{false, true} {
+		buf := new(bytes.Buffer)
+		c := &rawConnection{
+			cr: &countingReader{Reader: buf},
+			cw: &countingWriter{Writer: buf},
+		}
+		c.SetCompressionPolicy(CompressionPolicy{Default: CompressionSettings{Compression: CompressionMetadata}})
+		c.peerHasIndexDict.Store(hasDict)
+
+		if err := c.writeMessage(msg, ""); err != nil {
+			t.Fatal(err)
+		}
+		fourByteBuf := make([]byte, 4)
+		hdr, err := c.readHeader(fourByteBuf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.readMessageAfterHeader(hdr, fourByteBuf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(got, msg) {
+			t.Error("received the wrong message")
+		}
+		sizes[hdr.Compression] = c.cr.Tot()
+	}
+
+	lz4Size, ok := sizes[bep.MessageCompression_MESSAGE_COMPRESSION_LZ4]
+	if !ok {
+		t.Fatal("expected an LZ4 compressed message without the dictionary")
+	}
+	zstdSize, ok := sizes[bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD]
+	if !ok {
+		t.Fatal("expected a zstd compressed message with the dictionary")
+	}
+	t.Logf("LZ4: %d bytes, zstd with dictionary: %d bytes", lz4Size, zstdSize)
+	if zstdSize >= lz4Size {
+		t.Error("expected the dictionary to compress better than LZ4")
+	}
+}
+
+func TestWriteFolderCompression(t *testing.T) {
+	policy := CompressionPolicy{
+		Default: CompressionSettings{Compression: CompressionNever},
+		Folders: map[string]CompressionSettings{
+			"docs": {Compression: CompressionAlways, Algorithm: CompressionAlgorithmZstd},
+		},
+	}
+	cases := []struct {
+		msg      proto.Message
+		folder   string
+		hasDict  bool
+		expected bep.MessageCompression
+	}{
+		{msg: &bep.Request{Folder: "docs", Name: strings.Repeat("a", 1000)}, hasDict: true, expected: bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD},
+		{msg: &bep.Request{Folder: "docs", Name: strings.Repeat("a", 1000)}, expected: bep.MessageCompression_MESSAGE_COMPRESSION_LZ4},
+		{msg: &bep.Request{Folder: "media", Name: strings.Repeat("a", 1000)}, hasDict: true, expected: bep.MessageCompression_MESSAGE_COMPRESSION_NONE},
+		{msg: &bep.Response{Data: make([]byte, 10240)}, folder: "docs", hasDict: true, expected: bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD},
+		{msg: &bep.Response{Data: make([]byte, 10240)}, folder: "media", hasDict: true, expected: bep.MessageCompression_MESSAGE_COMPRESSION_NONE},
+	}
+	for i, tc := range cases {
+		buf := new(bytes.Buffer)
+		c := &rawConnection{
+			cr: &countingReader{Reader: buf},
+			cw: &countingWriter{Writer: buf},
+		}
+		c.SetCompressionPolicy(policy)
+		c.peerHasIndexDict.Store(tc.hasDict)
+
+		if err := c.writeMessage(tc.msg, tc.folder); err != nil {
+			t.Fatal(err)
+		}
+		fourByteBuf := make([]byte, 4)
+		hdr, err := c.readHeader(fourByteBuf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Compression != tc.expected {
+			t.Errorf("%d: expected %v, got %v", i, tc.expected, hdr.Compression)
+		}
+		got, err := c.readMessageAfterHeader(hdr, fourByteBuf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(got, tc.msg) {
+			t.Errorf("%d: received the wrong message", i)
+		}
+	}
+}
+
 func TestLZ4Compression(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		dataLen := 150 + rand.Intn(150)
@@ -555,6 +965,103 @@ func TestIndexIDString(t *testing.T) {
 	}
 }
 
+func TestInteractiveRequestFirst(t *testing.T) {
+	m := newTestModel()
+
+	rw := testutil.NewBlockingRW()
+	w := &gatedWriter{gate: make(chan struct{}), closed: make(chan struct{})}
+	c := getRawConnection(NewConnection(c0ID, rw, w, testutil.NoopCloser{}, m, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c.Start()
+	defer closeAndWait(c, rw, w)
+
+	go c.ClusterConfig(&ClusterConfig{}, nil)
+	w.gate <- struct{}{}
+
+	// Keep the writer busy while a regular and an interactive message
+	// queue up.
+	go c.send(context.Background(), &bep.Ping{}, nil)
+	time.Sleep(100 * time.Millisecond)
+	regular, interactive := make(chan struct{}), make(chan struct{})
+	go c.send(context.Background(), &bep.Ping{}, regular)
+	time.Sleep(100 * time.Millisecond)
+	go c.sendTo(context.Background(), c.outboxFor(&Request{Interactive: true}), asyncMessage{msg: &bep.Ping{}, done: interactive})
+	time.Sleep(100 * time.Millisecond)
+
+	w.gate <- struct{}{}
+	w.gate <- struct{}{}
+	select {
+	case <-interactive:
+	case <-regular:
+		t.Fatal("regular message sent before the interactive one")
+	case <-time.After(time.Second):
+		t.Fatal("timed out before interactive message was sent")
+	}
+}
+
+func TestWritesCoalesced(t *testing.T) {
+	m := newTestModel()
+
+	rw := testutil.NewBlockingRW()
+	w := &gatedWriter{gate: make(chan struct{}), closed: make(chan struct{})}
+	c := getRawConnection(NewConnection(c0ID, rw, w, testutil.NoopCloser{}, m, new(mockedConnectionInfo), CompressionNever, testKeyGen))
+	c.Start()
+	defer closeAndWait(c, rw, w)
+
+	go c.ClusterConfig(&ClusterConfig{}, nil)
+	w.gate <- struct{}{}
+
+	// Keep the writer busy while more messages queue up.
+	go c.send(context.Background(), &bep.Ping{}, nil)
+	time.Sleep(100 * time.Millisecond)
+	var dones []chan struct{}
+	for range 10 {
+		done := make(chan struct{})
+		dones = append(dones, done)
+		go c.send(context.Background(), &bep.Ping{}, done)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// One write for the first ping, and one for all the others.
+	w.gate <- struct{}{}
+	select {
+	case w.gate <- struct{}{}:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the buffered messages to be written")
+	}
+	for _, done := range dones {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for messages to be sent")
+		}
+	}
+	select {
+	case w.gate <- struct{}{}:
+		t.Fatal("unexpected extra write")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// gatedWriter lets one write through for each value on the gate.
+type gatedWriter struct {
+	gate   chan struct{}
+	closed chan struct{}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.gate:
+		return len(p), nil
+	case <-w.closed:
+		return 0, io.ErrClosedPipe
+	}
+}
+
+func (w *gatedWriter) Close() error {
+	close(w.closed)
+	return nil
+}
+
 func closeAndWait(c interface{}, closers ...io.Closer) {
 	for _, closer := range closers {
 		closer.Close()
@@ -0,0 +1,207 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/semaphore"
+)
+
+// Each connection measures the round trip time by probing with pings the
+// peer answers, and the throughput and probe loss, and from those derives
+// the bandwidth-delay product. The socket buffers and the number of
+// request bytes in flight are sized to a multiple of that, so that slow or
+// long links are kept busy without queueing up more than they can carry.
+const (
+	tuningInterval    = 10 * time.Second
+	tuningWeight      = 0.3 // of each new sample in the moving averages
+	minSocketBuffer   = 256 << 10
+	maxSocketBuffer   = 16 << 20
+	minRequestWindow  = 4 << 20
+	maxRequestWindow  = 256 << 20
+	lossyProbeRatio   = 0.1 // above which the request window is halved
+	socketBufferBDPs  = 2   // times the bandwidth-delay product
+	requestWindowBDPs = 4   // times the bandwidth-delay product
+)
+
+// ConnectionTuning is what a connection measured and chose. Values are
+// zero until measured; a zero socket buffer means the system default is
+// in use.
+type ConnectionTuning struct {
+	RTTMs             float64 `json:"rttMs"`
+	InBytesPerSecond  int64   `json:"inBytesPerSecond"`
+	OutBytesPerSecond int64   `json:"outBytesPerSecond"`
+	ProbeLoss         float64 `json:"probeLoss"`
+	SocketBuffer      int     `json:"socketBuffer"`
+	MaxInFlightBytes  int     `json:"maxInFlightBytes"`
+}
+
+// socketBufferSetter is implemented by connection infos for connections
+// whose socket buffer sizes can be set.
+type socketBufferSetter interface {
+	SetSocketBuffers(size int) error
+}
+
+type connTuner struct {
+	window *semaphore.Semaphore // request bytes in flight
+
+	mut          sync.Mutex
+	lastProbeID  int32
+	probeID      int32 // outstanding probe, or zero
+	probeSent    time.Time
+	rtt          float64 // nanoseconds, moving average
+	inRate       float64 // bytes per second, moving average
+	outRate      float64
+	loss         float64 // fraction of probes unanswered, moving average
+	rated        bool    // rates have been sampled
+	probed       bool    // loss has been sampled
+	lastIn       int64
+	lastOut      int64
+	lastSample   time.Time
+	socketBuffer int
+	windowSize   int
+	noSetBuffer  bool // setting the socket buffers is not supported
+}
+
+func newConnTuner() *connTuner {
+	return &connTuner{
+		window:     semaphore.New(maxRequestWindow),
+		windowSize: maxRequestWindow,
+	}
+}
+
+// probe returns the ID of a new probe to send, forgetting any outstanding
+// one.
+func (t *connTuner) probe(now time.Time) int32 {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	t.lastProbeID++
+	if t.lastProbeID <= 0 {
+		t.lastProbeID = 1
+	}
+	t.probeID = t.lastProbeID
+	t.probeSent = now
+	return t.probeID
+}
+
+// answered records the reply to a probe.
+func (t *connTuner) answered(id int32, now time.Time) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	if id == 0 || id != t.probeID {
+		return
+	}
+	t.probeID = 0
+	t.rtt = movingAverage(t.rtt, float64(now.Sub(t.probeSent)), t.rtt == 0)
+	t.sampleLoss(0)
+}
+
+// sample records the byte counters of the connection, and an outstanding
+// probe as lost.
+func (t *connTuner) sample(now time.Time, in, out int64) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	if !t.lastSample.IsZero() {
+		secs := now.Sub(t.lastSample).Seconds()
+		if secs > 0 {
+			t.inRate = movingAverage(t.inRate, float64(in-t.lastIn)/secs, !t.rated)
+			t.outRate = movingAverage(t.outRate, float64(out-t.lastOut)/secs, !t.rated)
+			t.rated = true
+		}
+	}
+	t.lastIn, t.lastOut, t.lastSample = in, out, now
+	if t.probeID != 0 {
+		t.probeID = 0
+		t.sampleLoss(1)
+	}
+}
+
+func (t *connTuner) sampleLoss(lost float64) {
+	t.loss = movingAverage(t.loss, lost, !t.probed)
+	t.probed = true
+}
+
+// tune recomputes the socket buffer size and the request window from the
+// measurements, and returns the socket buffer size to set, if it changed.
+func (t *connTuner) tune() (int, bool) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	if t.rtt == 0 {
+		// Nothing to go on until the peer answers a probe.
+		return 0, false
+	}
+
+	bdp := max(t.inRate, t.outRate) * t.rtt / float64(time.Second)
+
+	window := clamp(nextPowerOfTwo(requestWindowBDPs*bdp), minRequestWindow, maxRequestWindow)
+	if t.loss > lossyProbeRatio {
+		window = max(window/2, minRequestWindow)
+	}
+	if window != t.windowSize {
+		t.windowSize = window
+		t.window.SetCapacity(window)
+	}
+
+	if t.noSetBuffer {
+		return 0, false
+	}
+	buf := clamp(nextPowerOfTwo(socketBufferBDPs*bdp), minSocketBuffer, maxSocketBuffer)
+	if buf == t.socketBuffer {
+		return 0, false
+	}
+	t.socketBuffer = buf
+	return buf, true
+}
+
+// setBufferFailed records that the socket buffers could not be set, and
+// won't be tried again.
+func (t *connTuner) setBufferFailed() {
+	t.mut.Lock()
+	t.socketBuffer = 0
+	t.noSetBuffer = true
+	t.mut.Unlock()
+}
+
+func (t *connTuner) tuning() ConnectionTuning {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	return ConnectionTuning{
+		RTTMs:             math.Round(t.rtt/float64(time.Millisecond)*10) / 10,
+		InBytesPerSecond:  int64(t.inRate),
+		OutBytesPerSecond: int64(t.outRate),
+		ProbeLoss:         math.Round(t.loss*100) / 100,
+		SocketBuffer:      t.socketBuffer,
+		MaxInFlightBytes:  t.windowSize,
+	}
+}
+
+func movingAverage(prev, sample float64, first bool) float64 {
+	if first {
+		return sample
+	}
+	return (1-tuningWeight)*prev + tuningWeight*sample
+}
+
+// nextPowerOfTwo returns the smallest power of two not less than v, up to
+// the largest that fits an int on 32 bit platforms.
+func nextPowerOfTwo(v float64) int {
+	if v <= 1 {
+		return 1
+	}
+	if v >= 1<<30 {
+		return 1 << 30
+	}
+	return 1 << bits.Len64(uint64(math.Ceil(v))-1)
+}
+
+func clamp(v, lo, hi int) int {
+	return min(max(v, lo), hi)
+}
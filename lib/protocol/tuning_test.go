@@ -0,0 +1,148 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+	"github.com/syncthing/syncthing/lib/testutil"
+)
+
+func TestConnTuner(t *testing.T) {
+	t.Parallel()
+
+	tu := newConnTuner()
+	if _, ok := tu.tune(); ok {
+		t.Error("expected no tuning before measuring")
+	}
+	if w := tu.tuning().MaxInFlightBytes; w != maxRequestWindow {
+		t.Errorf("expected the maximum request window before measuring, got %d", w)
+	}
+
+	// 50 MB/s with a round trip time of 100 ms makes for a bandwidth-delay
+	// product of 5 MB.
+	now := time.Now()
+	tu.sample(now, 0, 0)
+	id := tu.probe(now)
+	tu.answered(id, now.Add(100*time.Millisecond))
+	now = now.Add(tuningInterval)
+	tu.sample(now, 500e6, 1e6)
+
+	size, ok := tu.tune()
+	if !ok || size != 16<<20 {
+		t.Errorf("expected a 16 MiB socket buffer, got %d, %v", size, ok)
+	}
+	tun := tu.tuning()
+	if tun.RTTMs != 100 || tun.InBytesPerSecond != 50e6 || tun.ProbeLoss != 0 {
+		t.Error("unexpected measurements", tun)
+	}
+	if tun.MaxInFlightBytes != 32<<20 {
+		t.Errorf("expected a 32 MiB request window, got %d", tun.MaxInFlightBytes)
+	}
+	if _, ok := tu.tune(); ok {
+		t.Error("expected no change in socket buffer size when retuning")
+	}
+
+	// Unanswered probes shrink the window.
+	for i := range int64(3) {
+		tu.probe(now)
+		now = now.Add(tuningInterval)
+		tu.sample(now, (i+2)*500e6, 1e6)
+	}
+	tu.tune()
+	if tun := tu.tuning(); tun.ProbeLoss <= lossyProbeRatio || tun.MaxInFlightBytes != 16<<20 {
+		t.Errorf("expected a 16 MiB request window on a lossy link, got %d at loss %v", tun.MaxInFlightBytes, tun.ProbeLoss)
+	}
+
+	// Once setting the buffers fails, it is not tried again.
+	tu.setBufferFailed()
+	if _, ok := tu.tune(); ok || tu.tuning().SocketBuffer != 0 {
+		t.Error("expected no socket buffer after failing to set it")
+	}
+}
+
+func TestProbePing(t *testing.T) {
+	ar, aw := io.Pipe()
+	br, bw := io.Pipe()
+
+	c0 := getRawConnection(NewConnection(c0ID, ar, bw, testutil.NoopCloser{}, newTestModel(), new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c0.Start()
+	defer closeAndWait(c0, ar, bw)
+	// The peer is busy handling an index when the probe arrives, which
+	// doesn't hold up the answer.
+	m1 := newTestModel()
+	indexing := make(chan struct{})
+	release := make(chan struct{})
+	m1.indexFn = func(string, []FileInfo) {
+		close(indexing)
+		<-release
+	}
+	c1 := getRawConnection(NewConnection(c1ID, br, aw, testutil.NoopCloser{}, m1, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c1.Start()
+	defer closeAndWait(c1, ar, bw)
+	defer close(release)
+	c0.ClusterConfig(&ClusterConfig{}, nil)
+	c1.ClusterConfig(&ClusterConfig{}, nil)
+	if err := c0.Index(t.Context(), &Index{Folder: "default"}); err != nil {
+		t.Fatal(err)
+	}
+	<-indexing
+
+	id := c0.tuner.probe(time.Now())
+	c0.sendTo(t.Context(), c0.priorityOutbox, asyncMessage{msg: &bep.Ping{Id: id}})
+
+	answered := func() bool {
+		c0.tuner.mut.Lock()
+		defer c0.tuner.mut.Unlock()
+		return c0.tuner.rtt > 0
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !answered() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the probe to be answered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNextPowerOfTwo(t *testing.T) {
+	t.Parallel()
+
+	for v, exp := range map[float64]int{
+		0:       1,
+		1:       1,
+		3:       4,
+		4:       4,
+		5e6:     8 << 20,
+		1 << 40: 1 << 30, // capped to fit an int everywhere
+	} {
+		if got := nextPowerOfTwo(v); got != exp {
+			t.Errorf("nextPowerOfTwo(%v) = %d, expected %d", v, got, exp)
+		}
+	}
+}
+
+func TestInteractiveRequestWindow(t *testing.T) {
+	t.Parallel()
+
+	ar, aw := io.Pipe()
+	c := getRawConnection(NewConnection(c0ID, ar, aw, testutil.NoopCloser{}, newTestModel(), new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+
+	// With the window full, interactive requests wait like the others.
+	c.tuner.window.SetCapacity(minRequestWindow)
+	c.tuner.window.Take(minRequestWindow)
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.Request(ctx, &Request{Folder: "default", Name: "file", Size: 1024, Interactive: true}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected the interactive request to wait for the window, got", err)
+	}
+}
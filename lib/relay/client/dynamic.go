@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -78,6 +79,8 @@ func (c *dynamicClient) serve(ctx context.Context) error {
 		addrs = append(addrs, ruri.String())
 	}
 
+	// Why each relay failed, to tell in the error when none works.
+	var failures []string
 	for _, addr := range relayAddressesOrder(ctx, addrs) {
 		select {
 		case <-ctx.Done():
@@ -96,6 +99,9 @@ func (c *dynamicClient) serve(ctx context.Context) error {
 
 			err = c.client.Serve(ctx)
 			l.Debugf("Disconnected from %s://%s: %v", c.client.URI().Scheme, c.client.URI().Host, err)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", ruri.Host, err))
+			}
 
 			c.mut.Lock()
 			c.client = nil
@@ -103,7 +109,10 @@ func (c *dynamicClient) serve(ctx context.Context) error {
 		}
 	}
 	l.Debugln(c, "could not find a connectable relay")
-	return errors.New("could not find a connectable relay")
+	if len(failures) == 0 {
+		return errors.New("could not find a connectable relay")
+	}
+	return fmt.Errorf("could not find a connectable relay (%s)", strings.Join(failures, "; "))
 }
 
 func (c *dynamicClient) Error() error {
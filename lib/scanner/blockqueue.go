@@ -10,6 +10,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"unsafe"
 
 	"github.com/syncthing/syncthing/lib/fs"
 	"github.com/syncthing/syncthing/lib/protocol"
@@ -17,10 +18,17 @@ import (
 
 // HashFile hashes the files and returns a list of blocks representing the file.
 func HashFile(ctx context.Context, folderID string, fs fs.Filesystem, path string, blockSize int, counter Counter) ([]protocol.BlockInfo, error) {
-	fd, err := fs.Open(path)
+	blocks, _, err := hashFile(ctx, folderID, fs, path, blockSize, counter)
+	return blocks, err
+}
+
+// hashFile hashes the file like HashFile, and also returns the holes in it
+// if it's a sparse file.
+func hashFile(ctx context.Context, folderID string, filesystem fs.Filesystem, path string, blockSize int, counter Counter) ([]protocol.BlockInfo, []protocol.Hole, error) {
+	fd, err := filesystem.Open(path)
 	if err != nil {
 		l.Debugln("open:", err)
-		return nil, err
+		return nil, nil, err
 	}
 	defer fd.Close()
 
@@ -29,17 +37,26 @@ func HashFile(ctx context.Context, folderID string, fs fs.Filesystem, path strin
 	fi, err := fd.Stat()
 	if err != nil {
 		l.Debugln("stat before:", err)
-		return nil, err
+		return nil, nil, err
 	}
 	size := fi.Size()
 	modTime := fi.ModTime()
 
+	// Find the holes, if any. Not knowing them only costs disk space on
+	// the other devices.
+
+	holes, err := fs.Holes(fd, size)
+	if err != nil {
+		l.Debugln("holes:", err)
+		holes = nil
+	}
+
 	// Hash the file. This may take a while for large files.
 
 	blocks, err := Blocks(ctx, fd, blockSize, size, counter)
 	if err != nil {
 		l.Debugln("blocks:", err)
-		return nil, err
+		return nil, nil, err
 	}
 
 	metricHashedBytes.WithLabelValues(folderID).Add(float64(size))
@@ -50,13 +67,13 @@ func HashFile(ctx context.Context, folderID string, fs fs.Filesystem, path strin
 	fi, err = fd.Stat()
 	if err != nil {
 		l.Debugln("stat after:", err)
-		return nil, err
+		return nil, nil, err
 	}
 	if size != fi.Size() || !modTime.Equal(fi.ModTime()) {
-		return nil, errors.New("file changed during hashing")
+		return nil, nil, errors.New("file changed during hashing")
 	}
 
-	return blocks, nil
+	return blocks, holes, nil
 }
 
 // The parallel hasher reads FileInfo structures from the inbox, hashes the
@@ -70,10 +87,12 @@ type parallelHasher struct {
 	inbox    <-chan protocol.FileInfo
 	counter  Counter
 	done     chan<- struct{}
+	hashes   Limiter
+	memory   Limiter
 	wg       sync.WaitGroup
 }
 
-func newParallelHasher(ctx context.Context, folderID string, fs fs.Filesystem, workers int, outbox chan<- ScanResult, inbox <-chan protocol.FileInfo, counter Counter, done chan<- struct{}) {
+func newParallelHasher(ctx context.Context, folderID string, fs fs.Filesystem, workers int, outbox chan<- ScanResult, inbox <-chan protocol.FileInfo, counter Counter, done chan<- struct{}, hashes, memory Limiter) {
 	ph := &parallelHasher{
 		folderID: folderID,
 		fs:       fs,
@@ -81,6 +100,8 @@ func newParallelHasher(ctx context.Context, folderID string, fs fs.Filesystem, w
 		inbox:    inbox,
 		counter:  counter,
 		done:     done,
+		hashes:   hashes,
+		memory:   memory,
 	}
 
 	ph.wg.Add(workers)
@@ -107,13 +128,14 @@ func (ph *parallelHasher) hashFiles(ctx context.Context) {
 				panic("Bug. Asked to hash a directory or a deleted file.")
 			}
 
-			blocks, err := HashFile(ctx, ph.folderID, ph.fs, f.Name, f.BlockSize(), ph.counter)
+			blocks, holes, err := ph.hashFile(ctx, f)
 			if err != nil {
 				handleError(ctx, "hashing", f.Name, err, ph.outbox)
 				continue
 			}
 
 			f.Blocks = blocks
+			f.Holes = holes
 			f.BlocksHash = protocol.BlocksHash(blocks)
 
 			// The size we saw when initially deciding to hash the file
@@ -138,6 +160,31 @@ func (ph *parallelHasher) hashFiles(ctx context.Context) {
 	}
 }
 
+// hashFile hashes the file once the limiters allow it.
+func (ph *parallelHasher) hashFile(ctx context.Context, f protocol.FileInfo) ([]protocol.BlockInfo, []protocol.Hole, error) {
+	if ph.hashes != nil {
+		if err := ph.hashes.TakeWithContext(ctx, 1); err != nil {
+			return nil, nil, err
+		}
+		defer ph.hashes.Give(1)
+	}
+	if ph.memory != nil {
+		mem := hashMemory(f)
+		if err := ph.memory.TakeWithContext(ctx, mem); err != nil {
+			return nil, nil, err
+		}
+		defer ph.memory.Give(mem)
+	}
+	return hashFile(ctx, ph.folderID, ph.fs, f.Name, f.BlockSize(), ph.counter)
+}
+
+// hashMemory estimates the memory needed to hash the file: the read buffer
+// plus the resulting block list.
+func hashMemory(f protocol.FileInfo) int {
+	numBlocks := int(f.Size/int64(f.BlockSize())) + 1
+	return bufSize + numBlocks*(hashLength+int(unsafe.Sizeof(protocol.BlockInfo{})))
+}
+
 func (ph *parallelHasher) closeWhenDone() {
 	ph.wg.Wait()
 	// In case the hasher aborted on context, wait for filesystem
@@ -0,0 +1,76 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package scanner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// With adaptive block sizes, files get a block size one tier off the usual
+// for their size, depending on how they have been changing. Files that
+// keep growing, such as logs, or that keep changing often get smaller
+// blocks, so that a change means transferring less again. Files that had
+// long been static when first seen, such as media, get larger blocks, so
+// that their block lists are shorter. As every change of block size
+// changes every block hash, and so means transferring the whole file
+// again, a file that was just modified never gets larger blocks, and only
+// gets smaller ones once it has been changing often over several scans.
+// Block sizes stay within the tiers the protocol allows.
+
+const (
+	// A file modified again within this time changes often.
+	hotFileInterval = 24 * time.Hour
+	// A file not modified for this long before we first hash it is
+	// static.
+	staticFileAge = 30 * 24 * time.Hour
+)
+
+// BlockSizeProfiles remembers, across the scans of a folder, which files
+// were changing often the last time they were hashed.
+type BlockSizeProfiles struct {
+	mut sync.Mutex
+	hot map[string]struct{}
+}
+
+func NewBlockSizeProfiles() *BlockSizeProfiles {
+	return &BlockSizeProfiles{hot: make(map[string]struct{})}
+}
+
+// blockSize returns the block size for the named file of the given size
+// and modification time, and whether it was chosen for how the file has
+// been changing rather than for its size alone. cur is the previous
+// version of the file, if hasCur.
+func (p *BlockSizeProfiles) blockSize(name string, size int64, modTime time.Time, cur protocol.FileInfo, hasCur bool, now time.Time) (int, bool) {
+	blockSize := protocol.BlockSize(size)
+
+	if !hasCur {
+		if now.Sub(modTime) > staticFileAge && blockSize < protocol.MaxBlockSize {
+			return blockSize * 2, true
+		}
+		return blockSize, false
+	}
+
+	since := modTime.Sub(cur.ModTime())
+	hot := size > cur.Size || since > 0 && since < hotFileInterval
+
+	p.mut.Lock()
+	_, wasHot := p.hot[name]
+	if hot {
+		p.hot[name] = struct{}{}
+	} else {
+		delete(p.hot, name)
+	}
+	p.mut.Unlock()
+
+	if hot && wasHot && blockSize > protocol.MinBlockSize {
+		return blockSize / 2, true
+	}
+	return blockSize, false
+}
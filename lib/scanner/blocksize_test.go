@@ -0,0 +1,85 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestProfiledBlockSize(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	const size = 500 << 20 // 512 KiB blocks by size alone
+
+	cases := []struct {
+		name      string
+		size      int64
+		modTime   time.Time
+		cur       *protocol.FileInfo
+		blockSize int
+		profiled  bool
+	}{
+		{"new file", size, now.Add(-time.Hour), nil, 512 << 10, false},
+		{"new static file", size, now.Add(-365 * 24 * time.Hour), nil, 1 << 20, true},
+		{"grown", size, now, &protocol.FileInfo{Size: size / 2, ModifiedS: now.Add(-7 * 24 * time.Hour).Unix()}, 256 << 10, true},
+		{"changed often", size, now, &protocol.FileInfo{Size: size, ModifiedS: now.Add(-time.Hour).Unix()}, 256 << 10, true},
+		{"changed now and then", size, now, &protocol.FileInfo{Size: size, ModifiedS: now.Add(-7 * 24 * time.Hour).Unix()}, 512 << 10, false},
+		{"changed rarely", size, now, &protocol.FileInfo{Size: size, ModifiedS: now.Add(-365 * 24 * time.Hour).Unix()}, 512 << 10, false},
+		{"metadata changed", size, now, &protocol.FileInfo{Size: size, ModifiedS: now.Unix()}, 512 << 10, false},
+		{"modification time moved back", size, now.Add(-time.Hour), &protocol.FileInfo{Size: size, ModifiedS: now.Unix()}, 512 << 10, false},
+		{"small and changed often", 1 << 20, now, &protocol.FileInfo{Size: 1 << 10, ModifiedS: now.Unix()}, protocol.MinBlockSize, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var cur protocol.FileInfo
+			if tc.cur != nil {
+				cur = *tc.cur
+			}
+			// Changes are only profiled once they keep happening.
+			p := NewBlockSizeProfiles()
+			if blockSize, profiled := p.blockSize("file", tc.size, tc.modTime, cur, tc.cur != nil, now); profiled && tc.cur != nil {
+				t.Errorf("got %d, %v after the first change; expected no profile yet", blockSize, profiled)
+			}
+			blockSize, profiled := p.blockSize("file", tc.size, tc.modTime, cur, tc.cur != nil, now)
+			if blockSize != tc.blockSize || profiled != tc.profiled {
+				t.Errorf("got %d, %v; expected %d, %v", blockSize, profiled, tc.blockSize, tc.profiled)
+			}
+		})
+	}
+}
+
+func TestProfiledBlockSizeStable(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	const size = 500 << 20 // 512 KiB blocks by size alone
+	p := NewBlockSizeProfiles()
+	change := func(since time.Duration) int {
+		t.Helper()
+		cur := protocol.FileInfo{Size: size, ModifiedS: now.Add(-since).Unix(), RawBlockSize: 512 << 10}
+		blockSize, _ := p.blockSize("file", size, now, cur, true, now)
+		return blockSize
+	}
+
+	// A single change soon after a long quiet spell isn't a profile.
+	if bs := change(365 * 24 * time.Hour); bs != 512<<10 {
+		t.Fatal("expected the usual block size after a change after a long time, got", bs)
+	}
+	if bs := change(time.Hour); bs != 512<<10 {
+		t.Fatal("expected the usual block size after one quick change, got", bs)
+	}
+	if bs := change(time.Hour); bs != 256<<10 {
+		t.Fatal("expected smaller blocks after changing often, got", bs)
+	}
+	if bs := change(7 * 24 * time.Hour); bs != 512<<10 {
+		t.Fatal("expected the profile to be forgotten once the file calms down, got", bs)
+	}
+	if bs := change(time.Hour); bs != 512<<10 {
+		t.Fatal("expected the usual block size after one quick change, got", bs)
+	}
+}
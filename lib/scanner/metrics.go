@@ -25,6 +25,27 @@ var (
 		Name:      "scanned_items_total",
 		Help:      "Total number of items (files/directories) inspected, per folder",
 	}, []string{"folder"})
+
+	metricSkippedItems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "scanner",
+		Name:      "skipped_items_total",
+		Help:      "Total number of items passed over, per folder and reason (ignored/temporary/internal/special)",
+	}, []string{"folder", "reason"})
+
+	metricIgnoreMatchSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "scanner",
+		Name:      "ignore_match_seconds_total",
+		Help:      "Total time spent matching items against the ignore patterns, per folder",
+	}, []string{"folder"})
+)
+
+const (
+	metricSkipReasonIgnored   = "ignored"
+	metricSkipReasonTemporary = "temporary"
+	metricSkipReasonInternal  = "internal"
+	metricSkipReasonSpecial   = "special"
 )
 
 func registerFolderMetrics(folderID string) {
@@ -32,4 +53,8 @@ func registerFolderMetrics(folderID string) {
 	// when zero.
 	metricHashedBytes.WithLabelValues(folderID)
 	metricScannedItems.WithLabelValues(folderID)
+	for _, reason := range []string{metricSkipReasonIgnored, metricSkipReasonTemporary, metricSkipReasonInternal, metricSkipReasonSpecial} {
+		metricSkippedItems.WithLabelValues(folderID, reason)
+	}
+	metricIgnoreMatchSeconds.WithLabelValues(folderID)
 }
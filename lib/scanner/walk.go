@@ -67,6 +67,40 @@ type Config struct {
 	ScanXattrs bool
 	// Filter for extended attributes
 	XattrFilter XattrFilter
+	// If ScanPosixACLs is true, we pick up POSIX ACLs on files while scanning.
+	ScanPosixACLs bool
+	// If ScanAlternateStreams is true, we pick up NTFS alternate data
+	// streams on files while scanning.
+	ScanAlternateStreams bool
+	// Filter for alternate data streams
+	AlternateStreamFilter XattrFilter
+	// If HashLimiter is not nil, one unit is taken from it for each file
+	// being hashed.
+	HashLimiter Limiter
+	// If MemoryLimiter is not nil, memory for the block lists being hashed
+	// is taken from it.
+	MemoryLimiter Limiter
+	// If OwnershipMapper is not nil, ownership and permissions that are
+	// what it maps those of the current file to are considered unchanged.
+	OwnershipMapper OwnershipMapper
+	// If BlockSizeProfiles is not nil, the block size of files depends on
+	// how they have been changing, as remembered there across scans, as
+	// well as on their size.
+	BlockSizeProfiles *BlockSizeProfiles
+	// If Skipped is not nil, the items passed over are counted in it. It
+	// may be read once the channel of results is closed.
+	Skipped *SkipCounts
+}
+
+// SkipCounts are the numbers of items a walk passed over, by reason, and
+// the time it spent matching names against the ignore patterns. An
+// ignored directory that isn't descended into counts as one item.
+type SkipCounts struct {
+	Ignored        int // matched by the ignore patterns
+	Temporary      int // our temporary files
+	Internal       int // our own files, such as the folder marker
+	Special        int // neither files, directories nor symlinks
+	IgnoreMatching time.Duration
 }
 
 type CurrentFiler interface {
@@ -74,12 +108,26 @@ type CurrentFiler interface {
 	CurrentFile(name string) (protocol.FileInfo, bool)
 }
 
+// A Limiter bounds the resources used for hashing.
+type Limiter interface {
+	TakeWithContext(ctx context.Context, size int) error
+	Give(size int)
+}
+
 type XattrFilter interface {
 	Permit(string) bool
 	GetMaxSingleEntrySize() int
 	GetMaxTotalSize() int
 }
 
+// An OwnershipMapper translates the ownership and permissions of items on
+// other devices into the local ones.
+type OwnershipMapper interface {
+	MapOwner(name string, id int) (string, int)
+	MapGroup(name string, id int) (string, int)
+	MapPermissions(perm uint32) uint32
+}
+
 type ScanResult struct {
 	File protocol.FileInfo
 	Err  error
@@ -136,7 +184,7 @@ func (w *walker) walk(ctx context.Context) chan ScanResult {
 	// We're not required to emit scan progress events, just kick off hashers,
 	// and feed inputs directly from the walker.
 	if w.ProgressTickIntervalS < 0 {
-		newParallelHasher(ctx, w.Folder, w.Filesystem, w.Hashers, finishedChan, toHashChan, nil, nil)
+		newParallelHasher(ctx, w.Folder, w.Filesystem, w.Hashers, finishedChan, toHashChan, nil, nil, w.HashLimiter, w.MemoryLimiter)
 		return finishedChan
 	}
 
@@ -170,7 +218,7 @@ func (w *walker) walk(ctx context.Context) chan ScanResult {
 		done := make(chan struct{})
 		progress := newByteCounter()
 
-		newParallelHasher(ctx, w.Folder, w.Filesystem, w.Hashers, finishedChan, realToHashChan, progress, done)
+		newParallelHasher(ctx, w.Folder, w.Filesystem, w.Hashers, finishedChan, realToHashChan, progress, done, w.HashLimiter, w.MemoryLimiter)
 
 		// A routine which actually emits the FolderScanProgress events
 		// every w.ProgressTicker ticks, until the hasher routines terminate.
@@ -275,6 +323,9 @@ func isWarnableError(err error) bool {
 func (w *walker) walkAndHashFiles(ctx context.Context, toHashChan chan<- protocol.FileInfo, finishedChan chan<- ScanResult) fs.WalkFunc {
 	now := time.Now()
 	ignoredParent := ""
+	if w.Skipped == nil {
+		w.Skipped = new(SkipCounts)
+	}
 
 	return func(path string, info fs.FileInfo, err error) error {
 		select {
@@ -300,6 +351,8 @@ func (w *walker) walkAndHashFiles(ctx context.Context, toHashChan chan<- protoco
 
 		if fs.IsTemporary(path) {
 			l.Debugln(w, "temporary:", path, "err:", err)
+			w.Skipped.Temporary++
+			metricSkippedItems.WithLabelValues(w.Folder, metricSkipReasonTemporary).Inc()
 			if err == nil && info.IsRegular() && info.ModTime().Add(w.TempLifetime).Before(now) {
 				w.Filesystem.Remove(path)
 				l.Debugln(w, "removing temporary:", path, info.ModTime())
@@ -309,6 +362,8 @@ func (w *walker) walkAndHashFiles(ctx context.Context, toHashChan chan<- protoco
 
 		if fs.IsInternal(path) {
 			l.Debugln(w, "ignored (internal):", path)
+			w.Skipped.Internal++
+			metricSkippedItems.WithLabelValues(w.Folder, metricSkipReasonInternal).Inc()
 			return skip
 		}
 
@@ -317,8 +372,15 @@ func (w *walker) walkAndHashFiles(ctx context.Context, toHashChan chan<- protoco
 		nonNormPath := path
 		path = normalizePath(path)
 
-		if m := w.Matcher.Match(path); m.IsIgnored() {
+		t0 := time.Now()
+		m := w.Matcher.Match(path)
+		matching := time.Since(t0)
+		w.Skipped.IgnoreMatching += matching
+		metricIgnoreMatchSeconds.WithLabelValues(w.Folder).Add(matching.Seconds())
+		if m.IsIgnored() {
 			l.Debugln(w, "ignored (patterns):", path)
+			w.Skipped.Ignored++
+			metricSkippedItems.WithLabelValues(w.Folder, metricSkipReasonIgnored).Inc()
 			// Only descend if matcher says so and the current file is not a symlink.
 			if err != nil || m.CanSkipDir() || info.IsSymlink() {
 				return skip
@@ -426,6 +488,8 @@ func (w *walker) handleItem(ctx context.Context, path string, info fs.FileInfo,
 	default:
 		// A special file, socket, fifo, etc. -- do nothing, just skip and continue scanning.
 		l.Debugf("Skipping non-regular file %s (%s)", path, info.Mode())
+		w.Skipped.Special++
+		metricSkippedItems.WithLabelValues(w.Folder, metricSkipReasonSpecial).Inc()
 		return nil
 	}
 }
@@ -433,39 +497,25 @@ func (w *walker) handleItem(ctx context.Context, path string, info fs.FileInfo,
 func (w *walker) walkRegular(ctx context.Context, relPath string, info fs.FileInfo, toHashChan chan<- protocol.FileInfo) error {
 	curFile, hasCurFile := w.CurrentFiler.CurrentFile(relPath)
 
-	blockSize := protocol.BlockSize(info.Size())
-
-	if hasCurFile {
-		// Check if we should retain current block size.
-		curBlockSize := curFile.BlockSize()
-		if blockSize > curBlockSize && blockSize/curBlockSize <= 2 {
-			// New block size is larger, but not more than twice larger.
-			// Retain.
-			blockSize = curBlockSize
-		} else if curBlockSize > blockSize && curBlockSize/blockSize <= 2 {
-			// Old block size is larger, but not more than twice larger.
-			// Retain.
-			blockSize = curBlockSize
-		}
-	}
-
-	f, err := CreateFileInfo(info, relPath, w.Filesystem, w.ScanOwnership, w.ScanXattrs, w.XattrFilter)
+	f, err := CreateFileInfo(info, relPath, w.Filesystem, w.ScanOwnership, w.ScanXattrs, w.ScanPosixACLs, w.ScanAlternateStreams, w.XattrFilter, w.AlternateStreamFilter)
 	if err != nil {
 		return err
 	}
 	f = w.updateFileInfo(f, curFile)
 	f.NoPermissions = w.IgnorePerms
-	f.RawBlockSize = int32(blockSize)
 	l.Debugln(w, "checking:", f)
 
 	if hasCurFile {
+		w.unmapOwnership(&f, curFile)
 		if curFile.IsEquivalentOptional(f, protocol.FileInfoComparison{
-			ModTimeWindow:   w.ModTimeWindow,
-			IgnorePerms:     w.IgnorePerms,
-			IgnoreBlocks:    true,
-			IgnoreFlags:     w.LocalFlags,
-			IgnoreOwnership: !w.ScanOwnership,
-			IgnoreXattrs:    !w.ScanXattrs,
+			ModTimeWindow:          w.ModTimeWindow,
+			IgnorePerms:            w.IgnorePerms,
+			IgnoreBlocks:           true,
+			IgnoreFlags:            w.LocalFlags,
+			IgnoreOwnership:        !w.ScanOwnership,
+			IgnoreXattrs:           !w.ScanXattrs,
+			IgnorePosixACLs:        !w.ScanPosixACLs,
+			IgnoreAlternateStreams: !w.ScanAlternateStreams,
 		}) {
 			l.Debugln(w, "unchanged:", curFile)
 			return nil
@@ -483,6 +533,9 @@ func (w *walker) walkRegular(ctx context.Context, relPath string, info fs.FileIn
 		l.Debugln(w, "rescan:", curFile)
 	}
 
+	// Only files that changed are profiled, and only their blocks depend
+	// on the block size.
+	f.RawBlockSize = int32(w.blockSize(relPath, info, curFile, hasCurFile))
 	l.Debugln(w, "to hash:", relPath, f)
 
 	select {
@@ -494,10 +547,33 @@ func (w *walker) walkRegular(ctx context.Context, relPath string, info fs.FileIn
 	return nil
 }
 
+// blockSize returns the block size to hash the changed file with.
+func (w *walker) blockSize(relPath string, info fs.FileInfo, curFile protocol.FileInfo, hasCurFile bool) int {
+	blockSize, profiled := protocol.BlockSize(info.Size()), false
+	if w.BlockSizeProfiles != nil {
+		blockSize, profiled = w.BlockSizeProfiles.blockSize(relPath, info.Size(), info.ModTime(), curFile, hasCurFile && !curFile.IsDeleted(), time.Now())
+	}
+
+	if hasCurFile && !profiled {
+		// Check if we should retain current block size.
+		curBlockSize := curFile.BlockSize()
+		if blockSize > curBlockSize && blockSize/curBlockSize <= 2 {
+			// New block size is larger, but not more than twice larger.
+			// Retain.
+			blockSize = curBlockSize
+		} else if curBlockSize > blockSize && curBlockSize/blockSize <= 2 {
+			// Old block size is larger, but not more than twice larger.
+			// Retain.
+			blockSize = curBlockSize
+		}
+	}
+	return blockSize
+}
+
 func (w *walker) walkDir(ctx context.Context, relPath string, info fs.FileInfo, finishedChan chan<- ScanResult) error {
 	curFile, hasCurFile := w.CurrentFiler.CurrentFile(relPath)
 
-	f, err := CreateFileInfo(info, relPath, w.Filesystem, w.ScanOwnership, w.ScanXattrs, w.XattrFilter)
+	f, err := CreateFileInfo(info, relPath, w.Filesystem, w.ScanOwnership, w.ScanXattrs, w.ScanPosixACLs, w.ScanAlternateStreams, w.XattrFilter, w.AlternateStreamFilter)
 	if err != nil {
 		return err
 	}
@@ -506,13 +582,16 @@ func (w *walker) walkDir(ctx context.Context, relPath string, info fs.FileInfo,
 	l.Debugln(w, "checking:", f)
 
 	if hasCurFile {
+		w.unmapOwnership(&f, curFile)
 		if curFile.IsEquivalentOptional(f, protocol.FileInfoComparison{
-			ModTimeWindow:   w.ModTimeWindow,
-			IgnorePerms:     w.IgnorePerms,
-			IgnoreBlocks:    true,
-			IgnoreFlags:     w.LocalFlags,
-			IgnoreOwnership: !w.ScanOwnership,
-			IgnoreXattrs:    !w.ScanXattrs,
+			ModTimeWindow:          w.ModTimeWindow,
+			IgnorePerms:            w.IgnorePerms,
+			IgnoreBlocks:           true,
+			IgnoreFlags:            w.LocalFlags,
+			IgnoreOwnership:        !w.ScanOwnership,
+			IgnoreXattrs:           !w.ScanXattrs,
+			IgnorePosixACLs:        !w.ScanPosixACLs,
+			IgnoreAlternateStreams: !w.ScanAlternateStreams,
 		}) {
 			l.Debugln(w, "unchanged:", curFile)
 			return nil
@@ -548,7 +627,7 @@ func (w *walker) walkSymlink(ctx context.Context, relPath string, info fs.FileIn
 		return nil
 	}
 
-	f, err := CreateFileInfo(info, relPath, w.Filesystem, w.ScanOwnership, w.ScanXattrs, w.XattrFilter)
+	f, err := CreateFileInfo(info, relPath, w.Filesystem, w.ScanOwnership, w.ScanXattrs, w.ScanPosixACLs, w.ScanAlternateStreams, w.XattrFilter, w.AlternateStreamFilter)
 	if err != nil {
 		return err
 	}
@@ -558,13 +637,16 @@ func (w *walker) walkSymlink(ctx context.Context, relPath string, info fs.FileIn
 	l.Debugln(w, "checking:", f)
 
 	if hasCurFile {
+		w.unmapOwnership(&f, curFile)
 		if curFile.IsEquivalentOptional(f, protocol.FileInfoComparison{
-			ModTimeWindow:   w.ModTimeWindow,
-			IgnorePerms:     w.IgnorePerms,
-			IgnoreBlocks:    true,
-			IgnoreFlags:     w.LocalFlags,
-			IgnoreOwnership: !w.ScanOwnership,
-			IgnoreXattrs:    !w.ScanXattrs,
+			ModTimeWindow:          w.ModTimeWindow,
+			IgnorePerms:            w.IgnorePerms,
+			IgnoreBlocks:           true,
+			IgnoreFlags:            w.LocalFlags,
+			IgnoreOwnership:        !w.ScanOwnership,
+			IgnoreXattrs:           !w.ScanXattrs,
+			IgnorePosixACLs:        !w.ScanPosixACLs,
+			IgnoreAlternateStreams: !w.ScanAlternateStreams,
 		}) {
 			l.Debugln(w, "unchanged:", curFile, info.ModTime().Unix(), info.Mode()&fs.ModePerm)
 			return nil
@@ -663,6 +745,12 @@ func (w *walker) updateFileInfo(dst, src protocol.FileInfo) protocol.FileInfo {
 	return dst
 }
 
+func (w *walker) unmapOwnership(f *protocol.FileInfo, cur protocol.FileInfo) {
+	if w.OwnershipMapper != nil {
+		UnmapOwnership(f, cur, w.OwnershipMapper)
+	}
+}
+
 func handleError(ctx context.Context, context, path string, err error, finishedChan chan<- ScanResult) {
 	l.Debugf("handle error on '%v': %v: %v", path, context, err)
 	select {
@@ -730,7 +818,7 @@ func (noCurrentFiler) CurrentFile(_ string) (protocol.FileInfo, bool) {
 	return protocol.FileInfo{}, false
 }
 
-func CreateFileInfo(fi fs.FileInfo, name string, filesystem fs.Filesystem, scanOwnership bool, scanXattrs bool, xattrFilter XattrFilter) (protocol.FileInfo, error) {
+func CreateFileInfo(fi fs.FileInfo, name string, filesystem fs.Filesystem, scanOwnership bool, scanXattrs bool, scanPosixACLs bool, scanAlternateStreams bool, xattrFilter XattrFilter, streamFilter XattrFilter) (protocol.FileInfo, error) {
 	f := protocol.FileInfo{Name: name}
 	if scanOwnership || scanXattrs {
 		if plat, err := filesystem.PlatformData(name, scanOwnership, scanXattrs, xattrFilter); err == nil {
@@ -739,6 +827,20 @@ func CreateFileInfo(fi fs.FileInfo, name string, filesystem fs.Filesystem, scanO
 			return protocol.FileInfo{}, fmt.Errorf("reading platform data: %w", err)
 		}
 	}
+	if scanPosixACLs && !fi.IsSymlink() {
+		acl, err := fs.GetPosixACL(filesystem, name)
+		if err != nil {
+			return protocol.FileInfo{}, fmt.Errorf("reading platform data: %w", err)
+		}
+		f.Platform.PosixACL = acl
+	}
+	if scanAlternateStreams && !fi.IsSymlink() {
+		streams, err := fs.GetAlternateStreams(filesystem, name, streamFilter)
+		if err != nil {
+			return protocol.FileInfo{}, fmt.Errorf("reading platform data: %w", err)
+		}
+		f.Platform.AlternateStreams = &protocol.AlternateStreamData{Streams: streams}
+	}
 
 	if ct := fi.InodeChangeTime(); !ct.IsZero() {
 		f.InodeChangeNs = ct.UnixNano()
@@ -771,3 +873,75 @@ func CreateFileInfo(fi fs.FileInfo, name string, filesystem fs.Filesystem, scanO
 
 	return f, nil
 }
+
+// UnmapOwnership takes the permissions and ownership of cur over into f,
+// the same item as found on disk, where those on disk are what the mapper
+// makes of the ones of cur. This way the item is not considered changed
+// because of the mapping.
+func UnmapOwnership(f *protocol.FileInfo, cur protocol.FileInfo, m OwnershipMapper) {
+	if !f.NoPermissions && !cur.NoPermissions && cur.Permissions != f.Permissions &&
+		m.MapPermissions(cur.Permissions)&uint32(fs.ModePerm) == f.Permissions {
+		f.Permissions = cur.Permissions
+	}
+
+	if cur.Platform.Unix != nil && f.Platform.Unix != nil {
+		c, d := cur.Platform.Unix, f.Platform.Unix
+		owner, uid := m.MapOwner(c.OwnerName, c.UID)
+		group, gid := m.MapGroup(c.GroupName, c.GID)
+		mapped := owner != c.OwnerName || uid != c.UID || group != c.GroupName || gid != c.GID
+		if mapped && isOwner(d.OwnerName, d.UID, owner, uid) && isOwner(d.GroupName, d.GID, group, gid) {
+			f.Platform.Unix = c
+		}
+	}
+
+	if cur.Platform.Windows != nil && f.Platform.Windows != nil {
+		c, d := cur.Platform.Windows, f.Platform.Windows
+		mapFn := m.MapOwner
+		if c.OwnerIsGroup {
+			mapFn = m.MapGroup
+		}
+		if owner, _ := mapFn(c.OwnerName, -1); owner != "" && owner != c.OwnerName && owner == d.OwnerName {
+			f.Platform.Windows = c
+		}
+	}
+
+	if c, d := cur.Platform.PosixACL, f.Platform.PosixACL; c != nil && d != nil {
+		if mapsPosixACL(d.Access, c.Access, m) && mapsPosixACL(d.Default, c.Default, m) {
+			f.Platform.PosixACL = c
+		}
+	}
+}
+
+// mapsPosixACL returns true if the ACL entries on disk are those of cur,
+// with the users and groups of named entries mapped.
+func mapsPosixACL(disk, cur []protocol.PosixACLEntry, m OwnershipMapper) bool {
+	if len(disk) != len(cur) {
+		return false
+	}
+	for i, c := range cur {
+		d := disk[i]
+		if d.Tag != c.Tag || d.Perms != c.Perms {
+			return false
+		}
+		if !c.IsNamed() {
+			continue
+		}
+		mapFn := m.MapOwner
+		if c.Tag == protocol.PosixACLTagGroup {
+			mapFn = m.MapGroup
+		}
+		if name, id := mapFn(c.Name, c.ID); !isOwner(d.Name, d.ID, name, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// isOwner returns true if the owner (or group) of an item on disk is the
+// mapped one, which is given by name or, lacking a name, by ID.
+func isOwner(name string, id int, mappedName string, mappedID int) bool {
+	if mappedName != "" {
+		return name == mappedName
+	}
+	return id == mappedID
+}
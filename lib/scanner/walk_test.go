@@ -928,6 +928,54 @@ func TestIncludedSubdir(t *testing.T) {
 	}
 }
 
+func TestSkipCounts(t *testing.T) {
+	fss := fs.NewFilesystem(fs.FilesystemTypeFake, "")
+	for _, dir := range []string{".stfolder", "build/sub"} {
+		if err := fss.MkdirAll(dir, 0o777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"kept", "build/a", "build/sub/b", "debug.log", fs.TempName("partial")} {
+		fd, err := fss.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fd.Close()
+	}
+
+	pats := ignore.New(fss)
+	if err := pats.Parse(bytes.NewBufferString("build\n*.log\n"), ".stignore"); err != nil {
+		t.Fatal(err)
+	}
+
+	var skipped SkipCounts
+	fchan := Walk(context.TODO(), Config{
+		CurrentFiler: make(fakeCurrentFiler),
+		Filesystem:   fss,
+		Matcher:      pats,
+		Skipped:      &skipped,
+	})
+	for f := range fchan {
+		if f.Err != nil {
+			t.Fatalf("Error while scanning %v: %v", f.Err, f.Path)
+		}
+	}
+
+	// The ignored directory isn't descended into, so counts once.
+	if skipped.Ignored != 2 {
+		t.Errorf("expected 2 ignored items, got %d", skipped.Ignored)
+	}
+	if skipped.Temporary != 1 {
+		t.Errorf("expected 1 temporary item, got %d", skipped.Temporary)
+	}
+	if skipped.Internal != 1 {
+		t.Errorf("expected 1 internal item, got %d", skipped.Internal)
+	}
+	if skipped.IgnoreMatching <= 0 {
+		t.Error("expected time spent matching against the ignore patterns")
+	}
+}
+
 // Verify returns nil or an error describing the mismatch between the block
 // list and actual reader contents
 func verify(r io.Reader, blocksize int, blocks []protocol.BlockInfo) error {
@@ -999,3 +1047,56 @@ func BenchmarkWalk(b *testing.B) {
 		walkDir(testFs, "/", nil, nil, 0)
 	}
 }
+
+type testOwnershipMapper struct{}
+
+func (testOwnershipMapper) MapOwner(name string, id int) (string, int) {
+	if name == "alice" {
+		return "bob", id
+	}
+	return name, id
+}
+
+func (testOwnershipMapper) MapGroup(name string, id int) (string, int) {
+	if id == 100 {
+		return "", 200
+	}
+	return name, id
+}
+
+func (testOwnershipMapper) MapPermissions(perm uint32) uint32 {
+	return perm &^ 0o077
+}
+
+func TestUnmapOwnership(t *testing.T) {
+	cur := protocol.FileInfo{
+		Permissions: 0o755,
+		Platform: protocol.PlatformData{
+			Unix: &protocol.UnixData{OwnerName: "alice", UID: 1000, GroupName: "staff", GID: 100},
+		},
+	}
+
+	// On disk as mapped, the item is the current one.
+	disk := protocol.FileInfo{
+		Permissions: 0o700,
+		Platform: protocol.PlatformData{
+			Unix: &protocol.UnixData{OwnerName: "bob", UID: 1001, GroupName: "wheel", GID: 200},
+		},
+	}
+	UnmapOwnership(&disk, cur, testOwnershipMapper{})
+	if !disk.IsEquivalentOptional(cur, protocol.FileInfoComparison{IgnoreBlocks: true}) {
+		t.Error("expected the mapped item to be unchanged, got", disk)
+	}
+
+	// Otherwise changes are kept.
+	disk = protocol.FileInfo{
+		Permissions: 0o755,
+		Platform: protocol.PlatformData{
+			Unix: &protocol.UnixData{OwnerName: "carol", UID: 1002, GroupName: "wheel", GID: 200},
+		},
+	}
+	UnmapOwnership(&disk, cur, testOwnershipMapper{})
+	if disk.Permissions != 0o755 || disk.Platform.Unix.OwnerName != "carol" {
+		t.Error("expected the changed item to be kept, got", disk)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package sdnotify implements the systemd service notification protocol,
+// telling the service manager about our state and sending watchdog
+// keepalives. See sd_notify(3).
+package sdnotify
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// Status returns the state for a free form status message shown by
+// the service manager.
+func Status(status string) string {
+	return "STATUS=" + status
+}
+
+// ExtendTimeout returns the state for asking the service manager to wait
+// at least the given time longer for us to start up or shut down.
+func ExtendTimeout(d time.Duration) string {
+	return "EXTEND_TIMEOUT_USEC=" + strconv.FormatInt(d.Microseconds(), 10)
+}
+
+// Enabled returns true if we're running under a service manager that
+// accepts notifications.
+func Enabled() bool {
+	return os.Getenv("NOTIFY_SOCKET") != ""
+}
+
+// Notify sends the given states to the service manager. It does nothing
+// when not running under a service manager.
+func Notify(states ...string) error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+	// A leading @ is an abstract socket, which the net package handles.
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(strings.Join(states, "\n")))
+	return err
+}
+
+// WatchdogInterval returns the interval within which the service manager
+// expects watchdog keepalives, or zero if the watchdog isn't enabled for
+// us. The interval is also accepted when set for our parent process, which
+// is the case when running under the monitor process; that requires
+// NotifyAccess=all in the unit.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pidStr := os.Getenv("WATCHDOG_PID"); pidStr != "" {
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil || (pid != os.Getpid() && pid != os.Getppid()) {
+			return 0
+		}
+	}
+	return time.Duration(usec) * time.Microsecond
+}
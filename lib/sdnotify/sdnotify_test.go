@@ -0,0 +1,81 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package sdnotify
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no unixgram sockets on Windows")
+	}
+
+	// Socket paths have a short length limit, so not t.TempDir().
+	dir, err := os.MkdirTemp("", "sdnotify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", "")
+	if err := Notify(Ready); err != nil {
+		t.Error("expected no error without a service manager, got", err)
+	}
+
+	t.Setenv("NOTIFY_SOCKET", addr)
+	if !Enabled() {
+		t.Error("expected notifications to be enabled")
+	}
+	if err := Notify(Ready, Status("Up to date")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1024)
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := string(buf[:n]); msg != "READY=1\nSTATUS=Up to date" {
+		t.Errorf("unexpected notification %q", msg)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	t.Setenv("WATCHDOG_PID", "")
+	if d := WatchdogInterval(); d != 0 {
+		t.Error("expected no watchdog, got", d)
+	}
+
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	if d := WatchdogInterval(); d != 30*time.Second {
+		t.Error("expected 30s, got", d)
+	}
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	if d := WatchdogInterval(); d != 30*time.Second {
+		t.Error("expected 30s, got", d)
+	}
+	t.Setenv("WATCHDOG_PID", "1")
+	if os.Getppid() != 1 {
+		if d := WatchdogInterval(); d != 0 {
+			t.Error("expected no watchdog for another process, got", d)
+		}
+	}
+}
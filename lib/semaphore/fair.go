@@ -0,0 +1,152 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package semaphore
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// A FairSemaphore hands out a number of slots, overall and per key, to
+// waiters identified by a key. When a slot frees up it goes to the waiting
+// key currently holding the fewest slots, and among waiters with the same
+// key in the order they started waiting, so that one key taking many slots
+// cannot starve the others. Zero means no limit.
+type FairSemaphore[K comparable] struct {
+	max       int
+	maxPerKey int
+	taken     int
+	perKey    map[K]int
+	waiters   []*fairWaiter[K]
+	mut       sync.Mutex
+}
+
+type fairWaiter[K comparable] struct {
+	key   K
+	ahead bool
+	ready chan struct{}
+}
+
+func NewFair[K comparable](slots, slotsPerKey int) *FairSemaphore[K] {
+	return &FairSemaphore[K]{
+		max:       max(slots, 0),
+		maxPerKey: max(slotsPerKey, 0),
+		perKey:    make(map[K]int),
+	}
+}
+
+func (s *FairSemaphore[K]) TakeWithContext(ctx context.Context, key K) error {
+	return s.take(ctx, key, false)
+}
+
+func (s *FairSemaphore[K]) Take(key K) {
+	_ = s.take(context.Background(), key, false)
+}
+
+// TakeAhead waits for a slot like Take, but is served before the waiters
+// that used Take. The slot counts towards the key's share all the same.
+func (s *FairSemaphore[K]) TakeAhead(key K) {
+	_ = s.take(context.Background(), key, true)
+}
+
+// TryTake takes a slot if one is free for the key right away, returning
+// whether it did.
+func (s *FairSemaphore[K]) TryTake(key K) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if (s.max > 0 && s.taken >= s.max) || (s.maxPerKey > 0 && s.perKey[key] >= s.maxPerKey) {
+		return false
+	}
+	s.taken++
+	s.perKey[key]++
+	return true
+}
+
+func (s *FairSemaphore[K]) take(ctx context.Context, key K, ahead bool) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mut.Lock()
+	w := &fairWaiter[K]{key: key, ahead: ahead, ready: make(chan struct{})}
+	s.waiters = append(s.waiters, w)
+	s.grant()
+	s.mut.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	select {
+	case <-w.ready:
+		// Granted while we were giving up; hand it on.
+		s.give(key)
+	default:
+		s.waiters = slices.DeleteFunc(s.waiters, func(o *fairWaiter[K]) bool { return o == w })
+	}
+	return ctx.Err()
+}
+
+func (s *FairSemaphore[K]) Give(key K) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.give(key)
+}
+
+// SetLimits changes the overall and per key number of slots. Slots already
+// taken beyond the new limits are kept until given back.
+func (s *FairSemaphore[K]) SetLimits(slots, slotsPerKey int) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.max = max(slots, 0)
+	s.maxPerKey = max(slotsPerKey, 0)
+	s.grant()
+}
+
+func (s *FairSemaphore[K]) give(key K) {
+	if s.perKey[key] <= 0 {
+		return
+	}
+	s.taken--
+	if s.perKey[key]--; s.perKey[key] == 0 {
+		delete(s.perKey, key)
+	}
+	s.grant()
+}
+
+// grant hands out free slots to the waiting keys holding the fewest,
+// waiters that asked to go ahead first.
+func (s *FairSemaphore[K]) grant() {
+	for len(s.waiters) > 0 && (s.max == 0 || s.taken < s.max) {
+		best := -1
+		for i, w := range s.waiters {
+			n := s.perKey[w.key]
+			if s.maxPerKey > 0 && n >= s.maxPerKey {
+				continue
+			}
+			if best == -1 || w.ahead && !s.waiters[best].ahead ||
+				w.ahead == s.waiters[best].ahead && n < s.perKey[s.waiters[best].key] {
+				best = i
+			}
+		}
+		if best == -1 {
+			return
+		}
+		w := s.waiters[best]
+		s.waiters = slices.Delete(s.waiters, best, best+1)
+		s.taken++
+		s.perKey[w.key]++
+		close(w.ready)
+	}
+}
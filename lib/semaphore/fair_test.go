@@ -0,0 +1,130 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package semaphore
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFairSemaphoreOrder(t *testing.T) {
+	t.Parallel()
+
+	s := NewFair[string](2, 0)
+	s.Take("a")
+	s.Take("a")
+
+	// Key a queues up three more before b comes along, but b holds no
+	// slots and so gets the first one freed.
+	got := make(chan string, 4)
+	for i, key := range []string{"a", "a", "a", "b"} {
+		go func() {
+			s.Take(key)
+			got <- key
+		}()
+		for s.numWaiters() != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	var order []string
+	for range 4 {
+		s.Give("a")
+		order = append(order, <-got)
+	}
+	if exp := []string{"b", "a", "a", "a"}; !slices.Equal(order, exp) {
+		t.Errorf("expected order %v, got %v", exp, order)
+	}
+}
+
+func TestFairSemaphorePerKey(t *testing.T) {
+	t.Parallel()
+
+	s := NewFair[string](0, 1)
+	s.Take("a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.TakeWithContext(ctx, "a"); err == nil {
+		t.Fatal("expected second take for the same key to time out")
+	}
+	if err := s.TakeWithContext(context.Background(), "b"); err != nil {
+		t.Fatal(err)
+	}
+	if s.numWaiters() != 0 {
+		t.Errorf("expected no waiters, got %d", s.numWaiters())
+	}
+
+	s.SetLimits(0, 2)
+	if err := s.TakeWithContext(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFairSemaphoreAhead(t *testing.T) {
+	t.Parallel()
+
+	s := NewFair[string](1, 2)
+	s.Take("a")
+
+	// The key going ahead is served first even though it waited last
+	// and, by then, holds more slots.
+	got := make(chan string, 2)
+	go func() {
+		s.Take("b")
+		got <- "b"
+	}()
+	for s.numWaiters() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	go func() {
+		s.TakeAhead("a")
+		got <- "a"
+	}()
+	for s.numWaiters() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	s.Give("a")
+	if key := <-got; key != "a" {
+		t.Errorf("expected a to go ahead, got %s", key)
+	}
+	s.Give("a")
+	if key := <-got; key != "b" {
+		t.Errorf("expected b next, got %s", key)
+	}
+}
+
+func TestFairSemaphoreTryTake(t *testing.T) {
+	t.Parallel()
+
+	s := NewFair[string](2, 1)
+	if !s.TryTake("a") {
+		t.Fatal("expected to take a free slot")
+	}
+	if s.TryTake("a") {
+		t.Error("expected not to take beyond the per key limit")
+	}
+	if !s.TryTake("b") {
+		t.Fatal("expected to take a free slot for another key")
+	}
+	if s.TryTake("c") {
+		t.Error("expected not to take beyond the overall limit")
+	}
+	s.Give("a")
+	if !s.TryTake("c") {
+		t.Error("expected to take the slot given back")
+	}
+}
+
+func (s *FairSemaphore[K]) numWaiters() int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return len(s.waiters)
+}
@@ -0,0 +1,162 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package semaphore
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// A PrioritySemaphore is a semaphore where waiters with a higher priority
+// are served first, and waiters with the same priority in the order they
+// started waiting. As with Semaphore, zero capacity means no limit.
+type PrioritySemaphore struct {
+	max       int
+	available int
+	waiters   waiterHeap
+	seq       int
+	mut       sync.Mutex
+}
+
+type waiter struct {
+	priority int
+	seq      int
+	size     int
+	ready    chan struct{}
+	index    int
+}
+
+func NewPriority(max int) *PrioritySemaphore {
+	if max < 0 {
+		max = 0
+	}
+	return &PrioritySemaphore{
+		max:       max,
+		available: max,
+	}
+}
+
+func (s *PrioritySemaphore) TakeWithContext(ctx context.Context, priority, size int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mut.Lock()
+	w := &waiter{priority: priority, seq: s.seq, size: size, ready: make(chan struct{})}
+	s.seq++
+	heap.Push(&s.waiters, w)
+	s.grant()
+	s.mut.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	select {
+	case <-w.ready:
+		// Granted while we were giving up; hand it on.
+		s.give(w.size)
+	default:
+		heap.Remove(&s.waiters, w.index)
+		// We may have been blocking those behind us.
+		s.grant()
+	}
+	return ctx.Err()
+}
+
+func (s *PrioritySemaphore) Give(size int) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if size > s.max {
+		size = s.max
+	}
+	s.give(size)
+}
+
+func (s *PrioritySemaphore) SetCapacity(capacity int) {
+	if capacity < 0 {
+		capacity = 0
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	diff := capacity - s.max
+	s.max = capacity
+	s.available += diff
+	if s.available < 0 {
+		s.available = 0
+	} else if s.available > s.max {
+		s.available = s.max
+	}
+	s.grant()
+}
+
+func (s *PrioritySemaphore) Available() int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.available
+}
+
+func (s *PrioritySemaphore) give(size int) {
+	s.available = min(s.available+size, s.max)
+	s.grant()
+}
+
+// grant hands out what is available to the waiters in order, stopping at
+// the first one that doesn't fit so that large takes aren't starved by
+// smaller ones.
+func (s *PrioritySemaphore) grant() {
+	for len(s.waiters) > 0 {
+		w := s.waiters[0]
+		if w.size > s.max {
+			w.size = s.max
+		}
+		if w.size > s.available {
+			return
+		}
+		s.available -= w.size
+		heap.Pop(&s.waiters)
+		close(w.ready)
+	}
+}
+
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter) //nolint:forcetypeassert
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return w
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package semaphore
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPrioritySemaphoreOrder(t *testing.T) {
+	t.Parallel()
+
+	s := NewPriority(1)
+	ctx := context.Background()
+	if err := s.TakeWithContext(ctx, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Queue up waiters, waiting for each to be queued so that the order
+	// among equal priorities is known.
+	got := make(chan int, 4)
+	for i, prio := range []int{0, 5, 0, 10} {
+		go func() {
+			if err := s.TakeWithContext(ctx, prio, 1); err != nil {
+				t.Error(err)
+			}
+			got <- i
+		}()
+		for s.numWaiters() != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	var order []int
+	for range 4 {
+		s.Give(1)
+		order = append(order, <-got)
+	}
+	if exp := []int{3, 1, 0, 2}; !slices.Equal(order, exp) {
+		t.Errorf("expected order %v, got %v", exp, order)
+	}
+}
+
+func TestPrioritySemaphoreCancel(t *testing.T) {
+	t.Parallel()
+
+	s := NewPriority(2)
+	s.TakeWithContext(context.Background(), 0, 1)
+
+	// A cancelled large take must not block smaller ones behind it.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.TakeWithContext(ctx, 10, 2); err == nil {
+		t.Fatal("expected take to time out")
+	}
+	if err := s.TakeWithContext(context.Background(), 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if s.Available() != 0 || s.numWaiters() != 0 {
+		t.Errorf("bad state after cancelled take: %d available, %d waiting", s.Available(), s.numWaiters())
+	}
+}
+
+func TestPrioritySemaphoreUnlimited(t *testing.T) {
+	t.Parallel()
+
+	s := NewPriority(0)
+	for range 10 {
+		if err := s.TakeWithContext(context.Background(), 0, 100); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func (s *PrioritySemaphore) numWaiters() int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return len(s.waiters)
+}
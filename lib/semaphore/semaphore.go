@@ -77,6 +77,21 @@ func (s *Semaphore) takeInner(ctx context.Context, size int) error {
 	return nil
 }
 
+// TryTake takes size if it is available right away, returning whether it
+// did.
+func (s *Semaphore) TryTake(size int) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if size > s.max {
+		size = s.max
+	}
+	if size > s.available {
+		return false
+	}
+	s.available -= size
+	return true
+}
+
 func (s *Semaphore) Give(size int) {
 	s.mut.Lock()
 	if size > s.max {
@@ -120,9 +135,10 @@ func (s *Semaphore) Available() int {
 type MultiSemaphore []*Semaphore
 
 func (s MultiSemaphore) TakeWithContext(ctx context.Context, size int) error {
-	for _, limiter := range s {
+	for i, limiter := range s {
 		if limiter != nil {
 			if err := limiter.TakeWithContext(ctx, size); err != nil {
+				s[:i].Give(size)
 				return err
 			}
 		}
@@ -138,6 +154,16 @@ func (s MultiSemaphore) Take(size int) {
 	}
 }
 
+func (s MultiSemaphore) TryTake(size int) bool {
+	for i, limiter := range s {
+		if limiter != nil && !limiter.TryTake(size) {
+			s[:i].Give(size)
+			return false
+		}
+	}
+	return true
+}
+
 func (s MultiSemaphore) Give(size int) {
 	for i := range s {
 		limiter := s[len(s)-1-i]
@@ -6,7 +6,11 @@
 
 package semaphore
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+)
 
 func TestZeroByteSemaphore(t *testing.T) {
 	t.Parallel()
@@ -121,3 +125,49 @@ func TestByteSemaphoreGiveMore(t *testing.T) {
 		t.Errorf("bad state after large take + give with adjustment")
 	}
 }
+
+func TestTryTake(t *testing.T) {
+	t.Parallel()
+
+	a, b := New(100), New(50)
+	multi := MultiSemaphore{a, nil, b}
+
+	if !multi.TryTake(40) {
+		t.Fatal("expected take to succeed")
+	}
+	if multi.TryTake(20) {
+		t.Fatal("expected take to fail, b has only 10 left")
+	}
+	if a.available != 60 || b.available != 10 {
+		t.Errorf("failed take should not hold anything, got %d, %d", a.available, b.available)
+	}
+
+	multi.Give(40)
+	if a.available != 100 || b.available != 50 {
+		t.Errorf("bad state after give: %d, %d", a.available, b.available)
+	}
+
+	// As with Take, sizes beyond the capacity take all of it, and an
+	// unlimited semaphore never refuses.
+	if !b.TryTake(80) || b.available != 0 {
+		t.Error("large take should take everything")
+	}
+	if !New(0).TryTake(1000) {
+		t.Error("zero capacity semaphore should not limit")
+	}
+}
+
+func TestMultiSemaphoreCancelledTake(t *testing.T) {
+	t.Parallel()
+
+	a, b := New(100), New(50)
+	b.Take(50)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := (MultiSemaphore{a, b}).TakeWithContext(ctx, 10); err == nil {
+		t.Fatal("expected take to fail")
+	}
+	if a.available != 100 {
+		t.Errorf("failed take should give back what it took, got %d", a.available)
+	}
+}
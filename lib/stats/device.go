@@ -7,6 +7,8 @@
 package stats
 
 import (
+	"encoding/json"
+	"sync"
 	"time"
 
 	"github.com/syncthing/syncthing/internal/db"
@@ -15,15 +17,65 @@ import (
 const (
 	lastSeenKey     = "lastSeen"
 	connDurationKey = "lastConnDuration"
+	lastAddressKey  = "lastAddress"
+	usageInKey      = "usageIn/"  // + month
+	usageOutKey     = "usageOut/" // + month
+	activityKey     = "activity"
 )
 
+// The number of days activity is kept for.
+const activityDays = 90
+
 type DeviceStatistics struct {
 	LastSeen                time.Time `json:"lastSeen"`
 	LastConnectionDurationS float64   `json:"lastConnectionDurationS"`
+	// The address we last connected to the device at, for dialing it
+	// again right away after a restart.
+	LastAddress string `json:"lastAddress,omitempty"`
+	// The data exchanged with the device in the current calendar month.
+	MonthlyUsage MonthlyUsage `json:"monthlyUsage"`
+}
+
+type MonthlyUsage struct {
+	Month    string `json:"month"` // as YYYY-MM
+	InBytes  int64  `json:"inBytes"`
+	OutBytes int64  `json:"outBytes"`
+}
+
+func (u MonthlyUsage) TotalBytes() int64 {
+	return u.InBytes + u.OutBytes
+}
+
+func usageMonth(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// Activity is the number of files added, changed and deleted.
+type Activity struct {
+	Added   int `json:"added"`
+	Changed int `json:"changed"`
+	Deleted int `json:"deleted"`
+}
+
+func (a Activity) IsZero() bool {
+	return a == Activity{}
+}
+
+// DailyActivity is the activity of a device on a day, in the folders
+// shared with it. Changes count for the device that made them, whichever
+// device we got them from.
+type DailyActivity struct {
+	Day string `json:"day"` // as YYYY-MM-DD
+	Activity
+}
+
+func activityDay(t time.Time) string {
+	return t.Format("2006-01-02")
 }
 
 type DeviceStatisticsReference struct {
-	kv *db.Typed
+	kv  *db.Typed
+	mut sync.Mutex // for AddActivity
 }
 
 func NewDeviceStatisticsReference(kv *db.Typed) *DeviceStatisticsReference {
@@ -62,6 +114,100 @@ func (s *DeviceStatisticsReference) LastConnectionDuration(d time.Duration) erro
 	return s.kv.PutInt64(connDurationKey, d.Nanoseconds())
 }
 
+func (s *DeviceStatisticsReference) GetLastAddress() (string, error) {
+	addr, _, err := s.kv.String(lastAddressKey)
+	return addr, err
+}
+
+func (s *DeviceStatisticsReference) LastAddress(addr string) error {
+	return s.kv.PutString(lastAddressKey, addr)
+}
+
+// GetMonthlyUsage returns the data exchanged with the device in the
+// calendar month of the given time.
+func (s *DeviceStatisticsReference) GetMonthlyUsage(t time.Time) (MonthlyUsage, error) {
+	month := usageMonth(t)
+	in, _, err := s.kv.Int64(usageInKey + month)
+	if err != nil {
+		return MonthlyUsage{}, err
+	}
+	out, _, err := s.kv.Int64(usageOutKey + month)
+	if err != nil {
+		return MonthlyUsage{}, err
+	}
+	return MonthlyUsage{Month: month, InBytes: in, OutBytes: out}, nil
+}
+
+// AddUsage adds to the data exchanged with the device in the calendar
+// month of the given time, returning the new usage for the month.
+func (s *DeviceStatisticsReference) AddUsage(t time.Time, in, out int64) (MonthlyUsage, error) {
+	usage, err := s.GetMonthlyUsage(t)
+	if err != nil {
+		return MonthlyUsage{}, err
+	}
+	if in == 0 && out == 0 {
+		return usage, nil
+	}
+	usage.InBytes += in
+	usage.OutBytes += out
+	if err := s.kv.PutInt64(usageInKey+usage.Month, usage.InBytes); err != nil {
+		return MonthlyUsage{}, err
+	}
+	if err := s.kv.PutInt64(usageOutKey+usage.Month, usage.OutBytes); err != nil {
+		return MonthlyUsage{}, err
+	}
+	return usage, nil
+}
+
+// GetActivity returns the activity of the device for each day it had any
+// in the last activityDays days, oldest first.
+func (s *DeviceStatisticsReference) GetActivity() ([]DailyActivity, error) {
+	bs, ok, err := s.kv.Bytes(activityKey)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return []DailyActivity{}, nil
+	}
+	var activity []DailyActivity
+	if err := json.Unmarshal(bs, &activity); err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// AddActivity adds to the activity of the device on the day of the given
+// time, dropping that of days past keeping.
+func (s *DeviceStatisticsReference) AddActivity(t time.Time, a Activity) error {
+	if a.IsZero() {
+		return nil
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	activity, err := s.GetActivity()
+	if err != nil {
+		return err
+	}
+	day := activityDay(t)
+	if n := len(activity); n > 0 && activity[n-1].Day == day {
+		activity[n-1].Added += a.Added
+		activity[n-1].Changed += a.Changed
+		activity[n-1].Deleted += a.Deleted
+	} else {
+		activity = append(activity, DailyActivity{Day: day, Activity: a})
+	}
+	cutoff := activityDay(t.AddDate(0, 0, -activityDays))
+	for len(activity) > 0 && activity[0].Day <= cutoff {
+		activity = activity[1:]
+	}
+
+	bs, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	return s.kv.PutBytes(activityKey, bs)
+}
+
 func (s *DeviceStatisticsReference) GetStatistics() (DeviceStatistics, error) {
 	lastSeen, err := s.GetLastSeen()
 	if err != nil {
@@ -71,8 +217,18 @@ func (s *DeviceStatisticsReference) GetStatistics() (DeviceStatistics, error) {
 	if err != nil {
 		return DeviceStatistics{}, err
 	}
+	lastAddress, err := s.GetLastAddress()
+	if err != nil {
+		return DeviceStatistics{}, err
+	}
+	usage, err := s.GetMonthlyUsage(time.Now())
+	if err != nil {
+		return DeviceStatistics{}, err
+	}
 	return DeviceStatistics{
 		LastSeen:                lastSeen,
 		LastConnectionDurationS: lastConnDuration.Seconds(),
+		LastAddress:             lastAddress,
+		MonthlyUsage:            usage,
 	}, nil
 }
@@ -7,6 +7,7 @@
 package stats
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/syncthing/syncthing/internal/db"
@@ -98,3 +99,71 @@ func (s *FolderStatisticsReference) GetStatistics() (FolderStatistics, error) {
 		LastScan: lastScanTime,
 	}, nil
 }
+
+const (
+	hourlySizeSamples = 7 * 24
+	dailySizeSamples  = 365
+)
+
+// A SizeSample is a snapshot of the sizes of a folder.
+type SizeSample struct {
+	At          time.Time `json:"at"`
+	GlobalItems int       `json:"globalItems"`
+	GlobalBytes int64     `json:"globalBytes"`
+	LocalItems  int       `json:"localItems"`
+	LocalBytes  int64     `json:"localBytes"`
+	NeedItems   int       `json:"needItems"`
+	NeedBytes   int64     `json:"needBytes"`
+}
+
+// SizeHistory holds a sample per hour for the last week and per day for
+// the last year, oldest first. The sample for an hour or a day is the last
+// one recorded in it.
+type SizeHistory struct {
+	Hourly []SizeSample `json:"hourly"`
+	Daily  []SizeSample `json:"daily"`
+}
+
+func (s *FolderStatisticsReference) GetSizeHistory() (SizeHistory, error) {
+	bs, ok, err := s.kv.Bytes("sizeHistory")
+	if err != nil {
+		return SizeHistory{}, err
+	} else if !ok {
+		return SizeHistory{Hourly: []SizeSample{}, Daily: []SizeSample{}}, nil
+	}
+	var hist SizeHistory
+	if err := json.Unmarshal(bs, &hist); err != nil {
+		return SizeHistory{}, err
+	}
+	return hist, nil
+}
+
+// RecordSizes adds the sample to the size history.
+func (s *FolderStatisticsReference) RecordSizes(sample SizeSample) error {
+	hist, err := s.GetSizeHistory()
+	if err != nil {
+		return err
+	}
+	sample.At = sample.At.Truncate(time.Second)
+	hist.Hourly = addSizeSample(hist.Hourly, sample, time.Hour, hourlySizeSamples)
+	hist.Daily = addSizeSample(hist.Daily, sample, 24*time.Hour, dailySizeSamples)
+	bs, err := json.Marshal(hist)
+	if err != nil {
+		return err
+	}
+	return s.kv.PutBytes("sizeHistory", bs)
+}
+
+// addSizeSample adds the sample to the series, replacing the last one if
+// it's in the same interval, and keeps at most that many samples.
+func addSizeSample(series []SizeSample, sample SizeSample, interval time.Duration, keep int) []SizeSample {
+	if n := len(series); n > 0 && series[n-1].At.UTC().Truncate(interval).Equal(sample.At.UTC().Truncate(interval)) {
+		series[n-1] = sample
+		return series
+	}
+	series = append(series, sample)
+	if len(series) > keep {
+		series = series[len(series)-keep:]
+	}
+	return series
+}
@@ -10,6 +10,7 @@
 package stats
 
 import (
+	"slices"
 	"testing"
 	"time"
 
@@ -33,6 +34,9 @@ func TestDeviceStat(t *testing.T) {
 	if err := sr.LastConnectionDuration(42 * time.Second); err != nil {
 		t.Fatal(err)
 	}
+	if err := sr.LastAddress("tcp://192.0.2.42:22000"); err != nil {
+		t.Fatal(err)
+	}
 
 	stat, err := sr.GetStatistics()
 	if err != nil {
@@ -45,4 +49,132 @@ func TestDeviceStat(t *testing.T) {
 	if d := stat.LastConnectionDurationS; d != 42 {
 		t.Error("Bad last duration:", d)
 	}
+	if addr := stat.LastAddress; addr != "tcp://192.0.2.42:22000" {
+		t.Error("Bad last address:", addr)
+	}
+}
+
+func TestMonthlyUsage(t *testing.T) {
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sdb.Close()
+	})
+
+	sr := NewDeviceStatisticsReference(db.NewTyped(sdb, "devstatref"))
+	oct := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	if _, err := sr.AddUsage(oct, 100, 10); err != nil {
+		t.Fatal(err)
+	}
+	usage, err := sr.AddUsage(oct.Add(time.Hour), 50, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage != (MonthlyUsage{Month: "2026-10", InBytes: 150, OutBytes: 15}) {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	// A new month starts from zero.
+	nov := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	if usage, err := sr.GetMonthlyUsage(nov); err != nil {
+		t.Fatal(err)
+	} else if usage.TotalBytes() != 0 {
+		t.Errorf("expected no usage in the new month, got %+v", usage)
+	}
+	if usage, err := sr.GetMonthlyUsage(oct); err != nil {
+		t.Fatal(err)
+	} else if usage.TotalBytes() != 165 {
+		t.Errorf("expected the previous month to be kept, got %+v", usage)
+	}
+}
+
+func TestDeviceActivity(t *testing.T) {
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sdb.Close()
+	})
+
+	sr := NewDeviceStatisticsReference(db.NewTyped(sdb, "devstatref"))
+	day := time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local)
+	if err := sr.AddActivity(day, Activity{Added: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sr.AddActivity(day.Add(time.Hour), Activity{Changed: 1, Deleted: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sr.AddActivity(day.AddDate(0, 0, 1), Activity{Deleted: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	activity, err := sr.GetActivity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []DailyActivity{
+		{Day: "2026-10-14", Activity: Activity{Added: 2, Changed: 1, Deleted: 3}},
+		{Day: "2026-10-15", Activity: Activity{Deleted: 1}},
+	}
+	if !slices.Equal(activity, expected) {
+		t.Errorf("expected %+v, got %+v", expected, activity)
+	}
+
+	// Days past keeping are dropped.
+	if err := sr.AddActivity(day.AddDate(0, 0, activityDays), Activity{Added: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if activity, err := sr.GetActivity(); err != nil {
+		t.Fatal(err)
+	} else if len(activity) != 2 || activity[0].Day != "2026-10-15" {
+		t.Errorf("expected the first day to be dropped, got %+v", activity)
+	}
+}
+
+func TestFolderSizeHistory(t *testing.T) {
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sdb.Close()
+	})
+
+	sr := NewFolderStatisticsReference(db.NewTyped(sdb, "folderstats/default"))
+	hist, err := sr.GetSizeHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hist.Hourly) != 0 || len(hist.Daily) != 0 {
+		t.Fatal("expected empty history")
+	}
+
+	// Samples every fifteen minutes for a couple of days keep the last
+	// one of each hour and day.
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 8 * 24 * 4 {
+		sample := SizeSample{At: start.Add(time.Duration(i) * 15 * time.Minute), NeedItems: i}
+		if err := sr.RecordSizes(sample); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hist, err = sr.GetSizeHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hist.Hourly) != hourlySizeSamples {
+		t.Errorf("got %d hourly samples, expected %d", len(hist.Hourly), hourlySizeSamples)
+	}
+	if len(hist.Daily) != 8 {
+		t.Errorf("got %d daily samples, expected 8", len(hist.Daily))
+	}
+	if last := hist.Daily[len(hist.Daily)-1]; last.NeedItems != 8*24*4-1 {
+		t.Errorf("got need %d for the last day, expected the last sample", last.NeedItems)
+	}
+	if first := hist.Daily[0]; first.NeedItems != 24*4-1 {
+		t.Errorf("got need %d for the first day, expected its last sample", first.NeedItems)
+	}
 }
@@ -8,13 +8,21 @@ package syncthing
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"iter"
+	"slices"
 	"strings"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru/v2"
+
 	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/model"
 	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/scanner"
 	"github.com/syncthing/syncthing/lib/stats"
 )
 
@@ -22,9 +30,16 @@ import (
 // the package, it is not intended as a stable API at this time. It does however provide a boundary between the more
 // volatile Model interface and upstream users (one of which is an iOS app).
 type Internals struct {
-	model model.Model
+	model  model.Model
+	cfg    config.Wrapper
+	blocks *lru.Cache[string, []byte] // block hash -> data, for DownloadRange
 }
 
+// The number of recently downloaded blocks to keep for range requests.
+// Blocks are at most 16 MiB, but for the files this is typically used on
+// (images, video headers) they are much smaller.
+const rangeCacheBlocks = 16
+
 type Counts = db.Counts
 
 // SnapshotCompat provides a compatibility layer for callers previously using
@@ -34,9 +49,17 @@ type SnapshotCompat struct {
 	folder string
 }
 
-func newInternals(model model.Model) *Internals {
+var (
+	ErrNoFolderTemplate = errors.New("no folder template with given name")
+	ErrFolderExists     = errors.New("folder already exists")
+)
+
+func newInternals(model model.Model, cfg config.Wrapper) *Internals {
+	blocks, _ := lru.New[string, []byte](rangeCacheBlocks)
 	return &Internals{
-		model: model,
+		model:  model,
+		cfg:    cfg,
+		blocks: blocks,
 	}
 }
 
@@ -57,7 +80,243 @@ func (m *Internals) SetIgnores(folderID string, content []string) error {
 }
 
 func (m *Internals) DownloadBlock(ctx context.Context, deviceID protocol.DeviceID, folderID string, path string, blockNumber int, blockInfo protocol.BlockInfo, allowFromTemporary bool) ([]byte, error) {
-	return m.model.RequestGlobal(ctx, deviceID, folderID, path, blockNumber, blockInfo.Offset, blockInfo.Size, blockInfo.Hash, allowFromTemporary)
+	return m.model.RequestGlobal(ctx, deviceID, folderID, path, blockNumber, blockInfo.Offset, blockInfo.Size, blockInfo.Hash, allowFromTemporary, true)
+}
+
+// DownloadFile writes the contents of the global version of the file to w,
+// requesting each block from the device ahead of regular syncing.
+func (m *Internals) DownloadFile(ctx context.Context, deviceID protocol.DeviceID, folderID string, path string, w io.Writer) error {
+	file, ok, err := m.model.CurrentGlobalFile(folderID, path)
+	if err != nil {
+		return err
+	}
+	if !ok || file.IsDeleted() || file.IsInvalid() || file.IsDirectory() || file.IsSymlink() {
+		return fmt.Errorf("%s: no such file", path)
+	}
+	for i, block := range file.Blocks {
+		data, err := m.model.RequestGlobal(ctx, deviceID, folderID, path, i, block.Offset, block.Size, block.Hash, false, true)
+		if err != nil {
+			return fmt.Errorf("requesting block %d of %s: %w", i, path, err)
+		}
+		if !scanner.Validate(data, block.Hash) {
+			return fmt.Errorf("block %d of %s: hash mismatch", i, path)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DownloadRange returns up to length bytes of the global version of the
+// file starting at offset, requesting only the blocks covering the range
+// from the devices that have them, ahead of regular syncing. Recently
+// downloaded blocks are cached, so that reading a file in small ranges
+// doesn't request the same blocks again.
+func (m *Internals) DownloadRange(ctx context.Context, folderID string, path string, offset, length int64) ([]byte, error) {
+	file, ok, err := m.model.CurrentGlobalFile(folderID, path)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || file.IsDeleted() || file.IsInvalid() || file.IsDirectory() || file.IsSymlink() {
+		return nil, fmt.Errorf("%s: no such file", path)
+	}
+	if offset < 0 || length < 0 || offset > file.Size {
+		return nil, fmt.Errorf("%s: range %d+%d out of bounds", path, offset, length)
+	}
+
+	end := min(offset+length, file.Size)
+	data := make([]byte, 0, end-offset)
+	for i, block := range file.Blocks {
+		if block.Offset+int64(block.Size) <= offset {
+			continue
+		}
+		if block.Offset >= end {
+			break
+		}
+		bs, err := m.downloadBlock(ctx, folderID, file, i, block)
+		if err != nil {
+			return nil, err
+		}
+		from := max(offset-block.Offset, 0)
+		to := min(end-block.Offset, int64(len(bs)))
+		data = append(data, bs[from:to]...)
+	}
+	return data, nil
+}
+
+// downloadBlock returns the block from the cache, or requests it from the
+// first device that has it and gives us valid data.
+func (m *Internals) downloadBlock(ctx context.Context, folderID string, file protocol.FileInfo, i int, block protocol.BlockInfo) ([]byte, error) {
+	key := string(block.Hash)
+	if data, ok := m.blocks.Get(key); ok {
+		return data, nil
+	}
+	avail, err := m.model.Availability(folderID, file, block)
+	if err != nil {
+		return nil, err
+	}
+	err = errors.New("not available from any connected device")
+	for _, a := range avail {
+		data, rerr := m.model.RequestGlobal(ctx, a.ID, folderID, file.Name, i, block.Offset, block.Size, block.Hash, a.FromTemporary, true)
+		if rerr != nil {
+			err = rerr
+			continue
+		}
+		if !scanner.Validate(data, block.Hash) {
+			err = errors.New("hash mismatch")
+			continue
+		}
+		m.blocks.Add(key, data)
+		return data, nil
+	}
+	return nil, fmt.Errorf("block %d of %s: %w", i, file.Name, err)
+}
+
+// Pinned returns the paths in the folder that are always kept locally,
+// regardless of ignore patterns.
+func (m *Internals) Pinned(folderID string) ([]string, error) {
+	return m.model.Pinned(folderID)
+}
+
+// SetPinned pins or unpins a file or directory in the folder. Pinned files
+// are always fully present and kept up to date locally, regardless of
+// ignore patterns.
+func (m *Internals) SetPinned(folderID, path string, pinned bool) error {
+	return m.model.SetPinned(folderID, path, pinned)
+}
+
+// SetFolderSchedule sets the times during which the folder syncs, as time
+// windows such as "Mon-Fri 08:00-18:00" or "22:00-06:00". The folder is
+// paused outside of them, and paused or resumed right away to match. An
+// empty schedule removes it, leaving the folder as it is.
+func (m *Internals) SetFolderSchedule(folderID string, schedule []string) error {
+	return m.model.SetFolderSchedule(folderID, schedule)
+}
+
+// FolderTemplates returns the named folder templates.
+func (m *Internals) FolderTemplates() []config.FolderTemplate {
+	return m.cfg.RawCopy().Defaults.FolderTemplates
+}
+
+// SetFolderTemplate adds the template, or replaces the one with the same
+// name.
+func (m *Internals) SetFolderTemplate(tmpl config.FolderTemplate) error {
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		cfg.SetFolderTemplate(tmpl.Copy())
+	})
+	if err != nil {
+		return err
+	}
+	waiter.Wait()
+	return nil
+}
+
+// RemoveFolderTemplate removes the template with the given name, if any.
+func (m *Internals) RemoveFolderTemplate(name string) error {
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		if _, i, ok := cfg.FolderTemplate(name); ok {
+			cfg.Defaults.FolderTemplates = slices.Delete(cfg.Defaults.FolderTemplates, i, i+1)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	waiter.Wait()
+	return nil
+}
+
+// AddFolderFromTemplate adds a new folder with the given ID, label and
+// path, starting out from the settings and ignore patterns of the named
+// template.
+func (m *Internals) AddFolderFromTemplate(template, folderID, label, path string) error {
+	tmpl, ok := m.cfg.FolderTemplate(template)
+	if !ok {
+		return ErrNoFolderTemplate
+	}
+	if _, ok := m.cfg.Folder(folderID); ok {
+		return ErrFolderExists
+	}
+	folder := tmpl.Folder.Copy()
+	folder.ID = folderID
+	folder.Label = label
+	folder.Path = path
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		cfg.SetFolder(folder)
+	})
+	if err != nil {
+		return err
+	}
+	waiter.Wait()
+	if len(tmpl.Ignores.Lines) > 0 {
+		return m.model.SetIgnores(folderID, tmpl.Ignores.Lines)
+	}
+	return nil
+}
+
+// PauseAll pauses all syncing, recording why and on whose behalf, e.g.
+// "battery". With a non-zero duration syncing resumes by itself once it
+// has passed.
+func (m *Internals) PauseAll(reason, originator string, duration time.Duration) error {
+	return m.model.PauseAll(reason, originator, duration)
+}
+
+// ResumeAll ends a pause set by PauseAll.
+func (m *Internals) ResumeAll() error {
+	return m.model.ResumeAll()
+}
+
+// PendingRemoteChanges returns the remote changes in the folder that are
+// held back until approved, if any.
+func (m *Internals) PendingRemoteChanges(folderID string) (model.PendingApproval, bool, error) {
+	return m.model.PendingRemoteChanges(folderID)
+}
+
+// ApproveRemoteChanges lets the folder apply the remote changes that were
+// held back.
+func (m *Internals) ApproveRemoteChanges(folderID string) error {
+	return m.model.ApproveRemoteChanges(folderID)
+}
+
+// ClusterState returns, for each folder, the devices it is shared with
+// and what they have announced about it.
+func (m *Internals) ClusterState() []model.ClusterFolderState {
+	return m.model.ClusterState()
+}
+
+// FolderPathWarnings returns warnings about the paths devices advertise
+// for the folders we share, such as overlapping or nested paths.
+func (m *Internals) FolderPathWarnings() []model.FolderPathWarning {
+	return m.model.FolderPathWarnings()
+}
+
+// DeletedFiles returns the files under the prefix that are deleted in the
+// global index but still have a copy in our versions or on another device.
+func (m *Internals) DeletedFiles(folderID, prefix string) ([]model.DeletedFile, error) {
+	return m.model.DeletedFiles(folderID, prefix)
+}
+
+// Undelete restores a deleted file from our versions or from a device that
+// still has it.
+func (m *Internals) Undelete(ctx context.Context, folderID, path string) error {
+	return m.model.Undelete(ctx, folderID, path)
+}
+
+// CaseConflicts returns the files in the folder whose names differ only in
+// case, which can't all exist on case insensitive filesystems.
+func (m *Internals) CaseConflicts(folderID string) ([]model.CaseConflict, error) {
+	return m.model.CaseConflicts(folderID)
+}
+
+// ResolveCaseConflict renames our variant of a case conflict to newName.
+func (m *Internals) ResolveCaseConflict(folderID, name, newName string) error {
+	return m.model.ResolveCaseConflict(folderID, name, newName)
+}
+
+// PreviewIgnores returns the currently synced files that the given ignore
+// patterns would ignore, without applying them.
+func (m *Internals) PreviewIgnores(folderID string, content []string) ([]model.NewlyIgnoredFile, error) {
+	return m.model.PreviewIgnores(folderID, content)
 }
 
 func (m *Internals) BlockAvailability(folderID string, file protocol.FileInfo, block protocol.BlockInfo) ([]model.Availability, error) {
@@ -92,6 +351,16 @@ func (m *Internals) DeviceStatistics() (map[protocol.DeviceID]stats.DeviceStatis
 	return m.model.DeviceStatistics()
 }
 
+func (m *Internals) FolderSizeHistory(folderID string) (stats.SizeHistory, error) {
+	return m.model.FolderSizeHistory(folderID)
+}
+
+// TransferHistory returns the transfer rates per device and in total after
+// the given time, going back at most a day.
+func (m *Internals) TransferHistory(since time.Time) model.TransferHistory {
+	return m.model.TransferHistory(since)
+}
+
 func (m *Internals) PendingFolders(deviceID protocol.DeviceID) (map[string]db.PendingFolder, error) {
 	return m.model.PendingFolders(deviceID)
 }
@@ -100,6 +369,14 @@ func (m *Internals) ScanFolderSubdirs(folderID string, paths []string) error {
 	return m.model.ScanFolderSubdirs(folderID, paths)
 }
 
+// RescanFile scans the single file at the path, such as one that was just
+// written, and returns it as it is now in the local index. The change is
+// sent to the connected devices right away, without the wait for a scan
+// of the directory it is in.
+func (m *Internals) RescanFile(folderID, path string) (protocol.FileInfo, error) {
+	return m.model.RescanFile(folderID, path)
+}
+
 func (m *Internals) DBSnapshot(folderID string) (*SnapshotCompat, error) {
 	if _, err := m.model.GlobalSize(folderID); err != nil {
 		return nil, err
@@ -148,6 +425,13 @@ func (m *Internals) NeedFolderFiles(folder string, page, perpage int) ([]protoco
 	return m.model.NeedFolderFiles(folder, page, perpage)
 }
 
+// NeedFolderFilesIter returns all the files currently needed in the folder,
+// without the pagination of NeedFolderFiles. The error function is valid
+// once iteration is done.
+func (m *Internals) NeedFolderFilesIter(folder string) (iter.Seq[protocol.FileInfo], func() error) {
+	return m.model.NeedFolderFilesIter(folder)
+}
+
 func (m *Internals) RemoteNeedFolderFiles(folder string, device protocol.DeviceID, page, perpage int) ([]protocol.FileInfo, error) {
 	return m.model.RemoteNeedFolderFiles(folder, device, page, perpage)
 }
@@ -0,0 +1,129 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/model"
+	"github.com/syncthing/syncthing/lib/model/mocks"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestDownloadRange(t *testing.T) {
+	content := make([]byte, 3*protocol.MinBlockSize)
+	for i := range content {
+		content[i] = byte(i / protocol.MinBlockSize)
+	}
+	file := protocol.FileInfo{Name: "photo.jpg", Size: int64(len(content))}
+	for i := range 3 {
+		data := content[i*protocol.MinBlockSize : (i+1)*protocol.MinBlockSize]
+		hash := sha256.Sum256(data)
+		file.Blocks = append(file.Blocks, protocol.BlockInfo{Offset: int64(i * protocol.MinBlockSize), Size: len(data), Hash: hash[:]})
+	}
+
+	m := &mocks.Model{}
+	m.CurrentGlobalFileReturns(file, true, nil)
+	m.AvailabilityReturns([]model.Availability{{ID: protocol.DeviceID{1}}}, nil)
+	m.RequestGlobalCalls(func(_ context.Context, _ protocol.DeviceID, _, _ string, _ int, offset int64, size int, _ []byte, _, _ bool) ([]byte, error) {
+		return content[offset : offset+int64(size)], nil
+	})
+	internals := newInternals(m, nil)
+
+	// A range spanning the end of the first and start of the second block.
+	offset, length := int64(protocol.MinBlockSize-10), int64(20)
+	data, err := internals.DownloadRange(t.Context(), "default", file.Name, offset, length)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content[offset:offset+length]) {
+		t.Error("unexpected data for range")
+	}
+	if n := m.RequestGlobalCallCount(); n != 2 {
+		t.Errorf("expected two blocks requested, got %d", n)
+	}
+
+	// The blocks are cached.
+	if _, err := internals.DownloadRange(t.Context(), "default", file.Name, 0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if n := m.RequestGlobalCallCount(); n != 2 {
+		t.Errorf("expected the cached block to be used, got %d requests", n)
+	}
+
+	// Ranges past the end are cut short.
+	data, err = internals.DownloadRange(t.Context(), "default", file.Name, file.Size-5, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 5 {
+		t.Errorf("expected 5 bytes at the end of the file, got %d", len(data))
+	}
+
+	if _, err := internals.DownloadRange(t.Context(), "default", file.Name, file.Size+1, 1); err == nil {
+		t.Error("expected error for a range past the end of the file")
+	}
+}
+
+func TestAddFolderFromTemplate(t *testing.T) {
+	myID := protocol.DeviceID{1}
+	cfg := config.Wrap("", config.New(myID), myID, events.NoopLogger)
+	go cfg.Serve(t.Context())
+
+	m := &mocks.Model{}
+	internals := newInternals(m, cfg)
+
+	tmpl := config.FolderTemplate{
+		Name:    "photos",
+		Folder:  config.FolderConfiguration{RescanIntervalS: 1234, Devices: []config.FolderDeviceConfiguration{{DeviceID: protocol.DeviceID{2}}}},
+		Ignores: config.Ignores{Lines: []string{"*.tmp"}},
+	}
+	if err := internals.SetFolderTemplate(tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if tmpls := internals.FolderTemplates(); len(tmpls) != 1 || tmpls[0].Name != "photos" {
+		t.Fatalf("unexpected templates %v", tmpls)
+	}
+
+	if err := internals.AddFolderFromTemplate("nonexistent", "f1", "", "/f1"); !errors.Is(err, ErrNoFolderTemplate) {
+		t.Errorf("expected an error for a missing template, got %v", err)
+	}
+	if err := internals.AddFolderFromTemplate("photos", "f1", "Photos", "/f1"); err != nil {
+		t.Fatal(err)
+	}
+	folder, ok := cfg.Folder("f1")
+	if !ok || folder.Label != "Photos" || folder.RescanIntervalS != 1234 {
+		t.Errorf("folder not created from template: %+v", folder)
+	}
+	if m.SetIgnoresCallCount() != 1 {
+		t.Errorf("expected the template ignores to be set")
+	} else if id, lines := m.SetIgnoresArgsForCall(0); id != "f1" || len(lines) != 1 {
+		t.Errorf("unexpected ignores %v for %s", lines, id)
+	}
+	if err := internals.AddFolderFromTemplate("photos", "f1", "", "/f1"); !errors.Is(err, ErrFolderExists) {
+		t.Errorf("expected an error for an existing folder, got %v", err)
+	}
+
+	// Changing the folder doesn't change the template.
+	folder.Devices[0].EncryptionPassword = "changed"
+	if tmpl, _ := cfg.FolderTemplate("photos"); tmpl.Folder.Devices[0].EncryptionPassword != "" {
+		t.Error("template shares devices with the folder")
+	}
+
+	if err := internals.RemoveFolderTemplate("photos"); err != nil {
+		t.Fatal(err)
+	}
+	if tmpls := internals.FolderTemplates(); len(tmpls) != 0 {
+		t.Errorf("expected no templates, got %v", tmpls)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/model"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Lifecycle hooks for mobile apps embedding this package, which get
+// suspended or limited in the background by the OS. They must only be
+// called after Start returned successfully.
+
+const (
+	// How long the folders must be caught up with the connected devices
+	// for a sync cycle to be done, as connecting and exchanging indexes
+	// takes a moment.
+	syncOnceSettleTime = 5 * time.Second
+	syncOncePollTime   = time.Second
+)
+
+var errNotStarted = errors.New("not started")
+
+// EnterBackground prepares for being suspended in the background: Scans due
+// to the rescan interval or filesystem watcher are postponed, listening for
+// and dialing connections stops, and everything is written to the database
+// files so that nothing is lost if the process is killed. Connections
+// already established are kept for as long as the OS lets them be.
+func (a *App) EnterBackground() error {
+	if a.connectionsService == nil {
+		return errNotStarted
+	}
+	a.background.Store(true)
+	a.Internals.model.SetScansSuspended(true)
+	a.connectionsService.SetSuspended(true)
+	return a.sdb.Checkpoint()
+}
+
+// EnterForeground undoes EnterBackground, reconnecting right away and
+// doing the scans that were due in the meantime.
+func (a *App) EnterForeground() {
+	if a.connectionsService == nil {
+		return
+	}
+	a.background.Store(false)
+	a.connectionsService.SetSuspended(false)
+	a.Internals.model.SetScansSuspended(false)
+}
+
+// RunOnceSync does one catch-up cycle, e.g. for a background task: It
+// connects to other devices, scans all folders, and returns once the
+// folders are in sync with the connected devices, or with the context's
+// error if that doesn't happen before it's done. When called in the
+// background, listening and dialing stop again on return.
+func (a *App) RunOnceSync(ctx context.Context) error {
+	if a.connectionsService == nil {
+		return errNotStarted
+	}
+	a.connectionsService.SetSuspended(false)
+	defer func() {
+		if a.background.Load() {
+			a.connectionsService.SetSuspended(true)
+			if err := a.sdb.Checkpoint(); err != nil {
+				slog.Warn("Failed to checkpoint database", slogutil.Error(err))
+			}
+		}
+	}()
+
+	scanned := make(chan struct{})
+	go func() {
+		defer close(scanned)
+		for folder, err := range a.Internals.model.ScanFolders() {
+			slog.Warn("Failed to scan folder", slog.String("folder", folder), slogutil.Error(err))
+		}
+	}()
+	select {
+	case <-scanned:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	ticker := time.NewTicker(syncOncePollTime)
+	defer ticker.Stop()
+	var since time.Time
+	for {
+		if !caughtUp(a.cfg, a.Internals.model) {
+			since = time.Time{}
+		} else if since.IsZero() {
+			since = time.Now()
+		} else if time.Since(since) >= syncOnceSettleTime {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// caughtUp returns true if some device is connected, and no folder is busy
+// or needs anything, nor has anything a connected device needs.
+func caughtUp(cfg config.Wrapper, m model.Model) bool {
+	connected := false
+	for _, fcfg := range cfg.FolderList() {
+		if fcfg.Paused {
+			continue
+		}
+		// A folder in error state won't get anywhere anyway.
+		if state, _, _ := m.State(fcfg.ID); state != "idle" && state != "error" {
+			return false
+		}
+		if need, err := m.NeedSize(fcfg.ID, protocol.LocalDeviceID); err != nil || need.TotalItems() > 0 {
+			return false
+		}
+		for _, dev := range fcfg.DeviceIDs() {
+			if dev == cfg.MyID() || !m.ConnectedTo(dev) {
+				continue
+			}
+			connected = true
+			if comp, err := m.Completion(dev, fcfg.ID); err != nil || comp.NeedItems+comp.NeedDeletes > 0 {
+				return false
+			}
+		}
+	}
+	return connected
+}
@@ -0,0 +1,60 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/model"
+	"github.com/syncthing/syncthing/lib/model/mocks"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestCaughtUp(t *testing.T) {
+	device1 := protocol.DeviceID{1}
+	cfg := config.Wrap(tempCfgFilename(t), config.Configuration{
+		Devices: []config.DeviceConfiguration{{DeviceID: protocol.LocalDeviceID}, {DeviceID: device1}},
+		Folders: []config.FolderConfiguration{{
+			ID:      "default",
+			Devices: []config.FolderDeviceConfiguration{{DeviceID: protocol.LocalDeviceID}, {DeviceID: device1}},
+		}},
+	}, protocol.LocalDeviceID, events.NoopLogger)
+	defer os.Remove(cfg.ConfigPath())
+
+	m := &mocks.Model{}
+	m.StateReturns("idle", time.Time{}, nil)
+	if caughtUp(cfg, m) {
+		t.Error("expected not caught up without connected devices")
+	}
+
+	m.ConnectedToReturns(true)
+	if !caughtUp(cfg, m) {
+		t.Error("expected caught up")
+	}
+
+	m.CompletionReturns(model.FolderCompletion{NeedItems: 1}, nil)
+	if caughtUp(cfg, m) {
+		t.Error("expected not caught up while the remote needs items")
+	}
+	m.CompletionReturns(model.FolderCompletion{}, nil)
+
+	m.NeedSizeReturns(db.Counts{Files: 1}, nil)
+	if caughtUp(cfg, m) {
+		t.Error("expected not caught up while needing items")
+	}
+	m.NeedSizeReturns(db.Counts{}, nil)
+
+	m.StateReturns("syncing", time.Time{}, nil)
+	if caughtUp(cfg, m) {
+		t.Error("expected not caught up while syncing")
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// The securityService emits security events for changes to who may access
+// this device and how, and sends all security events to syslog if
+// configured.
+type securityService struct {
+	cfg      config.Wrapper
+	evLogger events.Logger
+	syslogCh chan string // new syslog address from config changes
+}
+
+func newSecurityService(cfg config.Wrapper, evLogger events.Logger) *securityService {
+	return &securityService{
+		cfg:      cfg,
+		evLogger: evLogger,
+		syslogCh: make(chan string, 1),
+	}
+}
+
+func (s *securityService) Serve(ctx context.Context) error {
+	sub := s.evLogger.Subscribe(events.SecurityEvent)
+	defer sub.Unsubscribe()
+	cfg := s.cfg.Subscribe(s)
+	defer s.cfg.Unsubscribe(s)
+
+	var sink io.WriteCloser
+	defer func() {
+		if sink != nil {
+			sink.Close()
+		}
+	}()
+	setSink := func(addr string) {
+		if sink != nil {
+			sink.Close()
+			sink = nil
+		}
+		if addr == "" {
+			return
+		}
+		var err error
+		if sink, err = dialSecuritySyslog(addr); err != nil {
+			slog.Warn("Failed to connect to syslog for security events", slog.String("address", addr), slogutil.Error(err))
+		}
+	}
+	setSink(cfg.Options.SecuritySyslog)
+
+	for {
+		select {
+		case ev, ok := <-sub.C():
+			if !ok {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			if sink == nil {
+				continue
+			}
+			data, _ := ev.Data.(map[string]string)
+			if _, err := io.WriteString(sink, formatSecurityEvent(data)); err != nil {
+				slog.Warn("Failed to send security event to syslog", slogutil.Error(err))
+			}
+		case addr := <-s.syslogCh:
+			setSink(addr)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *securityService) CommitConfiguration(from, to config.Configuration) bool {
+	if from.Options.SecuritySyslog != to.Options.SecuritySyslog {
+		select {
+		case <-s.syslogCh:
+		default:
+		}
+		s.syslogCh <- to.Options.SecuritySyslog
+	}
+	for _, change := range permissionChanges(s.cfg.MyID(), from, to) {
+		events.LogSecurity(s.evLogger, events.SecurityPermissionChanged, change)
+	}
+	return true
+}
+
+func (s *securityService) String() string {
+	return fmt.Sprintf("securityService@%p", s)
+}
+
+// formatSecurityEvent formats the event data as key=value pairs for
+// syslog, in a stable order.
+func formatSecurityEvent(data map[string]string) string {
+	var b strings.Builder
+	b.WriteString("security event: kind=")
+	b.WriteString(strconv.Quote(data["kind"]))
+	for _, k := range slices.Sorted(maps.Keys(data)) {
+		if k == "kind" {
+			continue
+		}
+		fmt.Fprintf(&b, " %s=%s", k, strconv.Quote(data[k]))
+	}
+	return b.String()
+}
+
+// permissionChanges returns the changes to who may access this device
+// and its data, and how, for security events. Secrets are not included,
+// only that they changed.
+func permissionChanges(myID protocol.DeviceID, from, to config.Configuration) []map[string]string {
+	var changes []map[string]string
+	changed := func(setting string, from, to any, extra ...string) {
+		c := map[string]string{"setting": setting}
+		if from != nil {
+			c["from"] = fmt.Sprint(from)
+			c["to"] = fmt.Sprint(to)
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			c[extra[i]] = extra[i+1]
+		}
+		changes = append(changes, c)
+	}
+
+	fg, tg := from.GUI, to.GUI
+	if fg.User != tg.User {
+		changed("gui.user", fg.User, tg.User)
+	}
+	if fg.Password != tg.Password {
+		changed("gui.password", nil, nil)
+	}
+	if fg.APIKey != tg.APIKey {
+		changed("gui.apiKey", nil, nil)
+	}
+	if fg.TOTPEnabled() != tg.TOTPEnabled() {
+		changed("gui.totp", fg.TOTPEnabled(), tg.TOTPEnabled())
+	}
+	if fg.AuthMode != tg.AuthMode {
+		changed("gui.authMode", fg.AuthMode, tg.AuthMode)
+	}
+	if fg.RawAddress != tg.RawAddress {
+		changed("gui.address", fg.RawAddress, tg.RawAddress)
+	}
+	if fg.RawUseTLS != tg.RawUseTLS {
+		changed("gui.useTLS", fg.RawUseTLS, tg.RawUseTLS)
+	}
+	if fg.InsecureAdminAccess != tg.InsecureAdminAccess {
+		changed("gui.insecureAdminAccess", fg.InsecureAdminAccess, tg.InsecureAdminAccess)
+	}
+	if fg.InsecureSkipHostCheck != tg.InsecureSkipHostCheck {
+		changed("gui.insecureSkipHostcheck", fg.InsecureSkipHostCheck, tg.InsecureSkipHostCheck)
+	}
+	if from.LDAP.Address != to.LDAP.Address {
+		changed("ldap.address", from.LDAP.Address, to.LDAP.Address)
+	}
+
+	fromDevices := from.DeviceMap()
+	toDevices := to.DeviceMap()
+	for _, td := range to.Devices {
+		id := td.DeviceID.String()
+		fd, ok := fromDevices[td.DeviceID]
+		if !ok {
+			changed("device", nil, nil, "device", id, "change", "added")
+			continue
+		}
+		if fd.Untrusted != td.Untrusted {
+			changed("device.untrusted", fd.Untrusted, td.Untrusted, "device", id)
+		}
+		if fd.Introducer != td.Introducer {
+			changed("device.introducer", fd.Introducer, td.Introducer, "device", id)
+		}
+		if fd.AutoAcceptFolders != td.AutoAcceptFolders {
+			changed("device.autoAcceptFolders", fd.AutoAcceptFolders, td.AutoAcceptFolders, "device", id)
+		}
+		if fd.AllowRemoteManagement != td.AllowRemoteManagement {
+			changed("device.allowRemoteManagement", fd.AllowRemoteManagement, td.AllowRemoteManagement, "device", id)
+		}
+		if !slices.Equal(fd.AllowedNetworks, td.AllowedNetworks) {
+			changed("device.allowedNetworks", strings.Join(fd.AllowedNetworks, ","), strings.Join(td.AllowedNetworks, ","), "device", id)
+		}
+	}
+	for _, fd := range from.Devices {
+		if _, ok := toDevices[fd.DeviceID]; !ok {
+			changed("device", nil, nil, "device", fd.DeviceID.String(), "change", "removed")
+		}
+	}
+
+	fromFolders := from.FolderMap()
+	for _, tf := range to.Folders {
+		ff := fromFolders[tf.ID]
+		for _, dev := range tf.Devices {
+			if _, ok := ff.Device(dev.DeviceID); !ok && dev.DeviceID != myID {
+				changed("folder.devices", nil, nil, "folder", tf.ID, "device", dev.DeviceID.String(), "change", "shared")
+			}
+		}
+		for _, dev := range ff.Devices {
+			if _, ok := tf.Device(dev.DeviceID); !ok && dev.DeviceID != myID {
+				changed("folder.devices", nil, nil, "folder", tf.ID, "device", dev.DeviceID.String(), "change", "unshared")
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestPermissionChanges(t *testing.T) {
+	myID := protocol.LocalDeviceID
+	device1, _ := protocol.DeviceIDFromString("AIR6LPZ-7K4PTTV-UXQSMUU-CPQ5YWH-OEDFIIQ-JUG777G-2YQXXR5-YD6AWQR")
+	device2, _ := protocol.DeviceIDFromString("GYRZZQB-IRNPV4Z-T7TC52W-EQYJ3TT-FDQW6MW-DFLMU42-SSSU6EM-FBK2VAY")
+
+	from := config.Configuration{
+		GUI:     config.GUIConfiguration{User: "admin", Password: "secret1", APIKey: "key1"},
+		Devices: []config.DeviceConfiguration{{DeviceID: myID}, {DeviceID: device1}},
+		Folders: []config.FolderConfiguration{{
+			ID:      "default",
+			Devices: []config.FolderDeviceConfiguration{{DeviceID: myID}, {DeviceID: device1}},
+		}},
+	}
+	to := from.Copy()
+	to.GUI.Password = "secret2"
+	to.Devices[1].Introducer = true
+	to.Devices = append(to.Devices, config.DeviceConfiguration{DeviceID: device2})
+	to.Folders[0].Devices = []config.FolderDeviceConfiguration{{DeviceID: myID}, {DeviceID: device2}}
+
+	changes := permissionChanges(myID, from, to)
+	expected := []map[string]string{
+		{"setting": "gui.password"},
+		{"setting": "device.introducer", "from": "false", "to": "true", "device": device1.String()},
+		{"setting": "device", "device": device2.String(), "change": "added"},
+		{"setting": "folder.devices", "folder": "default", "device": device2.String(), "change": "shared"},
+		{"setting": "folder.devices", "folder": "default", "device": device1.String(), "change": "unshared"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d: %v", len(expected), len(changes), changes)
+	}
+	for i, c := range changes {
+		if len(c) != len(expected[i]) {
+			t.Errorf("change %d: expected %v, got %v", i, expected[i], c)
+			continue
+		}
+		for k, v := range expected[i] {
+			if c[k] != v {
+				t.Errorf("change %d: expected %v, got %v", i, expected[i], c)
+			}
+		}
+	}
+	for _, c := range changes {
+		for _, v := range c {
+			if v == "secret1" || v == "secret2" {
+				t.Error("secret in security event:", c)
+			}
+		}
+	}
+
+	if changes := permissionChanges(myID, from, from.Copy()); len(changes) != 0 {
+		t.Error("expected no changes, got", changes)
+	}
+}
+
+func TestFormatSecurityEvent(t *testing.T) {
+	msg := formatSecurityEvent(map[string]string{"kind": "loginFailed", "username": "admin", "remoteAddress": "1.2.3.4"})
+	expected := `security event: kind="loginFailed" remoteAddress="1.2.3.4" username="admin"`
+	if msg != expected {
+		t.Errorf("expected %s, got %s", expected, msg)
+	}
+}
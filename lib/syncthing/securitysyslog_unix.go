@@ -0,0 +1,34 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package syncthing
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"net/url"
+)
+
+// dialSecuritySyslog connects to the local syslog daemon for "local", or to
+// a remote one given as udp://host:port or tcp://host:port.
+func dialSecuritySyslog(addr string) (io.WriteCloser, error) {
+	const priority = syslog.LOG_AUTH | syslog.LOG_WARNING
+	if addr == "local" {
+		return syslog.New(priority, "syncthing")
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" {
+		return nil, fmt.Errorf("unsupported syslog address %q", addr)
+	}
+	return syslog.Dial(u.Scheme, u.Host, priority, "syncthing")
+}
@@ -19,6 +19,7 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/thejerf/suture/v4"
@@ -32,10 +33,13 @@ import (
 	"github.com/syncthing/syncthing/lib/connections/registry"
 	"github.com/syncthing/syncthing/lib/discover"
 	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/hooks"
 	"github.com/syncthing/syncthing/lib/locations"
 	"github.com/syncthing/syncthing/lib/model"
 	"github.com/syncthing/syncthing/lib/osutil"
+	"github.com/syncthing/syncthing/lib/plugin"
 	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/sdnotify"
 	"github.com/syncthing/syncthing/lib/svcutil"
 	"github.com/syncthing/syncthing/lib/tlsutil"
 	"github.com/syncthing/syncthing/lib/upgrade"
@@ -74,6 +78,9 @@ type App struct {
 	stopped           chan struct{}
 	dbService         db.DBService
 
+	connectionsService connections.Service
+	background         atomic.Bool
+
 	// Access to internals for direct users of this package. Note that the interface in Internals is unstable!
 	Internals *Internals
 }
@@ -129,6 +136,7 @@ func (a *App) startup() error {
 	if a.opts.AuditWriter != nil {
 		a.mainService.Add(newAuditService(a.opts.AuditWriter, a.evLogger))
 	}
+	a.mainService.Add(newSecurityService(a.cfg, a.evLogger))
 
 	// Event subscription for the API; must start early to catch the early
 	// events. The LocalChangeDetected event might overwhelm the event
@@ -247,10 +255,18 @@ func (a *App) startup() error {
 
 	keyGen := protocol.NewKeyGenerator()
 	m := model.NewModel(a.cfg, a.myID, a.sdb, protectedFiles, a.evLogger, keyGen)
-	a.Internals = newInternals(m)
+	a.Internals = newInternals(m, a.cfg)
 
 	a.mainService.Add(m)
 
+	a.mainService.Add(hooks.New(a.cfg, a.evLogger))
+
+	var systemd *systemdService
+	if sdnotify.Enabled() {
+		systemd = newSystemdService(a.cfg, a.evLogger, a.sdb, m)
+		a.mainService.Add(systemd)
+	}
+
 	// The TLS configuration is used for both the listening socket and outgoing
 	// connections.
 
@@ -273,9 +289,25 @@ func (a *App) startup() error {
 	connectionsService := connections.NewService(a.cfg, a.myID, m, tlsCfg, discoveryManager, bepProtocolName, tlsDefaultCommonName, a.evLogger, connRegistry, keyGen)
 
 	addrLister.AddressLister = connectionsService
+	a.connectionsService = connectionsService
+
+	// On the first start after the identity was migrated here, let the
+	// other devices know, so that they stop looking for us where we were.
+	// The marker stays until one of them has taken note, so that we try
+	// again next time if we're stopped before that.
+	movedMarker := locations.Get(locations.MovedMarker)
+	if _, err := os.Stat(movedMarker); err == nil {
+		slog.Info("Device identity was migrated to this machine; announcing the move to other devices")
+		connectionsService.AnnounceMoved(func() {
+			if err := os.Remove(movedMarker); err != nil {
+				slog.Warn("Failed to remove migration marker", slogutil.FilePath(movedMarker), slogutil.Error(err))
+			}
+		})
+	}
 
 	a.mainService.Add(discoveryManager)
 	a.mainService.Add(connectionsService)
+	a.mainService.Add(plugin.NewManager(a.cfg, a.evLogger, discoveryManager))
 
 	a.cfg.Modify(func(cfg *config.Configuration) {
 		// Candidate builds always run with usage reporting.
@@ -313,6 +345,9 @@ func (a *App) startup() error {
 	a.evLogger.Log(events.StartupComplete, map[string]string{
 		"myID": a.myID.String(),
 	})
+	if systemd != nil {
+		systemd.setReady()
+	}
 
 	if a.cfg.Options().SetLowPriority {
 		if err := osutil.SetLowPriority(); err != nil {
@@ -0,0 +1,223 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/model"
+	"github.com/syncthing/syncthing/lib/sdnotify"
+)
+
+const (
+	// How often the status is refreshed when there is no watchdog.
+	systemdStatusInterval = 30 * time.Second
+	// The longest a health check may take, regardless of the watchdog
+	// interval.
+	maxHealthCheckTime = 30 * time.Second
+)
+
+var errHealthCheckTimeout = errors.New("not responding")
+
+// The systemdService tells systemd that we're ready or stopping, keeps it
+// updated with a status line, and sends watchdog keepalives as long as the
+// health checks pass, so that a hung instance gets restarted.
+type systemdService struct {
+	cfg      config.Wrapper
+	evLogger events.Logger
+	model    model.Model
+	checks   []*healthCheck
+	ready    chan struct{}
+	readyOne sync.Once
+}
+
+func newSystemdService(cfg config.Wrapper, evLogger events.Logger, sdb db.DB, m model.Model) *systemdService {
+	s := &systemdService{
+		cfg:      cfg,
+		evLogger: evLogger,
+		model:    m,
+		ready:    make(chan struct{}),
+	}
+	s.checks = []*healthCheck{
+		{name: "event loop", fn: func() error {
+			// Subscribing is handled by the event loop.
+			evLogger.Subscribe(0).Unsubscribe()
+			return nil
+		}},
+		{name: "database", fn: func() error {
+			_, err := sdb.ListFolders()
+			return err
+		}},
+		{name: "folders", fn: func() error {
+			_, err := s.folderStates()
+			return err
+		}},
+	}
+	return s
+}
+
+// setReady is called once startup is complete.
+func (s *systemdService) setReady() {
+	s.readyOne.Do(func() { close(s.ready) })
+}
+
+func (s *systemdService) Serve(ctx context.Context) error {
+	s.notify(sdnotify.Status("Starting"))
+	select {
+	case <-s.ready:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	sub := s.evLogger.Subscribe(events.StateChanged)
+	defer sub.Unsubscribe()
+
+	watchdog := sdnotify.WatchdogInterval()
+	interval := systemdStatusInterval
+	if watchdog > 0 {
+		// Keepalives at half the interval, as recommended.
+		interval = watchdog / 2
+		slog.Info("Sending systemd watchdog keepalives", slog.Duration("interval", interval))
+	}
+	timeout := min(interval, maxHealthCheckTime)
+
+	s.update(timeout, watchdog > 0, sdnotify.Ready)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// Folder state changes are reflected in the status soon, but not
+	// immediately, as there may be many.
+	refresh := time.NewTimer(0)
+	<-refresh.C
+	refreshPending := false
+	for {
+		select {
+		case <-ticker.C:
+			s.update(timeout, watchdog > 0)
+		case <-sub.C():
+			if !refreshPending {
+				refresh.Reset(time.Second)
+				refreshPending = true
+			}
+		case <-refresh.C:
+			refreshPending = false
+			s.update(timeout, watchdog > 0)
+		case <-ctx.Done():
+			s.notify(sdnotify.Stopping, sdnotify.Status("Shutting down"))
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *systemdService) String() string {
+	return fmt.Sprintf("systemdService@%p", s)
+}
+
+func (s *systemdService) notify(states ...string) {
+	if err := sdnotify.Notify(states...); err != nil {
+		l.Debugln("Failed to notify systemd:", err)
+	}
+}
+
+// update runs the health checks and sends the status along with the
+// given states, and a watchdog keepalive if enabled and healthy.
+func (s *systemdService) update(timeout time.Duration, watchdog bool, states ...string) {
+	status, healthy := s.status(timeout)
+	states = append(states, sdnotify.Status(status))
+	if watchdog && healthy {
+		states = append(states, sdnotify.Watchdog)
+	}
+	s.notify(states...)
+}
+
+// status runs the health checks and returns the status line, and whether
+// all checks passed.
+func (s *systemdService) status(timeout time.Duration) (string, bool) {
+	for _, check := range s.checks {
+		if err := check.run(timeout); err != nil {
+			slog.Warn("Health check failed", slog.String("check", check.name), slogutil.Error(err))
+			return fmt.Sprintf("Unhealthy: %s: %v", check.name, err), false
+		}
+	}
+	// The folders check has just returned, so this doesn't hang.
+	states, err := s.folderStates()
+	if err != nil {
+		return "Unhealthy: folders: " + err.Error(), false
+	}
+	return folderStatus(states), true
+}
+
+// folderStates returns the number of folders in each state.
+func (s *systemdService) folderStates() (map[string]int, error) {
+	states := make(map[string]int)
+	for _, fcfg := range s.cfg.FolderList() {
+		if fcfg.Paused {
+			continue
+		}
+		state, _, err := s.model.State(fcfg.ID)
+		if err != nil && state != "error" {
+			return nil, err
+		}
+		states[state]++
+	}
+	return states, nil
+}
+
+// folderStatus returns a status line like "Folders: 2 syncing, 1 scanning".
+func folderStatus(states map[string]int) string {
+	var parts []string
+	add := func(n int, what string) {
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, what))
+		}
+	}
+	add(states["syncing"]+states["sync-preparing"]+states["sync-waiting"]+states["cleaning"]+states["clean-waiting"], "syncing")
+	add(states["scanning"]+states["scan-waiting"], "scanning")
+	add(states["error"], "with errors")
+	if len(parts) == 0 {
+		return "Up to date"
+	}
+	return "Folders: " + strings.Join(parts, ", ")
+}
+
+// A healthCheck is run with a timeout. A check that hangs keeps failing
+// until it returns, without starting it again in the meantime.
+type healthCheck struct {
+	name    string
+	fn      func() error
+	running atomic.Bool
+}
+
+func (c *healthCheck) run(timeout time.Duration) error {
+	if !c.running.CompareAndSwap(false, true) {
+		return errHealthCheckTimeout
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer c.running.Store(false)
+		done <- c.fn()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return errHealthCheckTimeout
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFolderStatus(t *testing.T) {
+	cases := []struct {
+		states map[string]int
+		status string
+	}{
+		{map[string]int{}, "Up to date"},
+		{map[string]int{"idle": 3}, "Up to date"},
+		{map[string]int{"idle": 1, "syncing": 1, "sync-waiting": 1, "scanning": 1}, "Folders: 2 syncing, 1 scanning"},
+		{map[string]int{"error": 1}, "Folders: 1 with errors"},
+	}
+	for _, tc := range cases {
+		if status := folderStatus(tc.states); status != tc.status {
+			t.Errorf("%v: expected %q, got %q", tc.states, tc.status, status)
+		}
+	}
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	calls := 0
+	check := &healthCheck{name: "test", fn: func() error {
+		calls++
+		<-release
+		return nil
+	}}
+
+	if err := check.run(10 * time.Millisecond); err != errHealthCheckTimeout {
+		t.Fatal("expected timeout, got", err)
+	}
+	// Still hanging, so not started again.
+	if err := check.run(10 * time.Millisecond); err != errHealthCheckTimeout {
+		t.Fatal("expected timeout, got", err)
+	}
+	close(release)
+	for check.running.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	if err := check.run(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Error("expected two calls, got", calls)
+	}
+}
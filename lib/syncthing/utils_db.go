@@ -0,0 +1,169 @@
+// Copyright (C) 2014 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !wasm
+
+package syncthing
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/db/olddb"
+	"github.com/syncthing/syncthing/internal/db/olddb/backend"
+	"github.com/syncthing/syncthing/internal/db/sqlite"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/build"
+	"github.com/syncthing/syncthing/lib/locations"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Opens a database
+func OpenDatabase(path string, deleteRetention time.Duration) (db.DB, error) {
+	sql, err := sqlite.Open(path, sqlite.WithDeleteRetention(deleteRetention))
+	if err != nil {
+		return nil, err
+	}
+
+	sdb := db.MetricsWrap(sql)
+
+	return sdb, nil
+}
+
+// Attempts migration of the old (LevelDB-based) database type to the new (SQLite-based) type
+// This will attempt to provide a temporary API server during the migration, if `apiAddr` is not empty.
+func TryMigrateDatabase(ctx context.Context, deleteRetention time.Duration) error {
+	oldDBDir := locations.Get(locations.LegacyDatabase)
+	if _, err := os.Lstat(oldDBDir); err != nil {
+		// No old database
+		return nil
+	}
+
+	be, err := backend.OpenLevelDBRO(oldDBDir)
+	if err != nil {
+		// Apparently, not a valid old database
+		return nil
+	}
+	defer be.Close()
+
+	sdb, err := sqlite.OpenForMigration(locations.Get(locations.Database))
+	if err != nil {
+		return err
+	}
+	defer sdb.Close()
+
+	miscDB := db.NewMiscDB(sdb)
+	if when, ok, err := miscDB.Time("migrated-from-leveldb-at"); err == nil && ok {
+		slog.Error("Old-style database present but already migrated; please manually move or remove.", slog.Any("migratedAt", when), slogutil.FilePath(oldDBDir))
+		return nil
+	}
+
+	slog.Info("Migrating old-style database to SQLite; this may take a while...")
+	t0 := time.Now()
+
+	ll, err := olddb.NewLowlevel(be)
+	if err != nil {
+		return err
+	}
+
+	totFiles, totBlocks := 0, 0
+	for _, folder := range ll.ListFolders() {
+		// Start a writer routine
+		fis := make(chan protocol.FileInfo, 50)
+		var writeErr error
+		var wg sync.WaitGroup
+		wg.Add(1)
+		writerDone := make(chan struct{})
+		go func() {
+			defer wg.Done()
+			defer close(writerDone)
+			var batch []protocol.FileInfo
+			files, blocks := 0, 0
+			t0 := time.Now()
+			t1 := time.Now()
+
+			if writeErr = sdb.DropFolder(folder); writeErr != nil {
+				slog.Error("Failed database drop", slogutil.Error(writeErr))
+				return
+			}
+
+			for fi := range fis {
+				batch = append(batch, fi)
+				files++
+				blocks += len(fi.Blocks)
+				if len(batch) == 1000 {
+					writeErr = sdb.Update(folder, protocol.LocalDeviceID, batch)
+					if writeErr != nil {
+						slog.Error("Failed database write", slogutil.Error(writeErr))
+						return
+					}
+					batch = batch[:0]
+					if time.Since(t1) > 10*time.Second {
+						d := time.Since(t0) + 1
+						t1 = time.Now()
+						slog.Info("Still migrating folder", "folder", folder, "files", files, "blocks", blocks, "duration", d.Truncate(time.Second), "blocksrate", float64(blocks)/d.Seconds(), "filesrate", float64(files)/d.Seconds())
+					}
+				}
+			}
+			if len(batch) > 0 {
+				writeErr = sdb.Update(folder, protocol.LocalDeviceID, batch)
+			}
+			d := time.Since(t0) + 1
+			slog.Info("Migrated folder", "folder", folder, "files", files, "blocks", blocks, "duration", d.Truncate(time.Second), "filesrate", float64(files)/d.Seconds())
+			totFiles += files
+			totBlocks += blocks
+		}()
+
+		// Iterate the existing files
+		fs, err := olddb.NewFileSet(folder, ll)
+		if err != nil {
+			return err
+		}
+		snap, err := fs.Snapshot()
+		if err != nil {
+			return err
+		}
+		_ = snap.WithHaveSequence(0, func(fi protocol.FileInfo) bool {
+			if deleteRetention > 0 && fi.Deleted && time.Since(fi.ModTime()) > deleteRetention {
+				// Skip deleted files that match the garbage collection
+				// criteria in the database
+				return true
+			}
+			select {
+			case fis <- fi:
+				return true
+			case <-writerDone:
+				return false
+			}
+		})
+		close(fis)
+		snap.Release()
+
+		// Wait for writes to complete
+		wg.Wait()
+		if writeErr != nil {
+			return writeErr
+		}
+	}
+
+	slog.Info("Migrating virtual mtimes...")
+	if err := ll.IterateMtimes(sdb.PutMtime); err != nil {
+		slog.Warn("Failed to migrate mtimes", slogutil.Error(err))
+	}
+
+	_ = miscDB.PutTime("migrated-from-leveldb-at", time.Now())
+	_ = miscDB.PutString("migrated-from-leveldb-by", build.LongVersion)
+
+	_ = be.Close()
+	_ = os.Rename(oldDBDir, oldDBDir+"-migrated")
+
+	slog.Info("Migration complete", "files", totFiles, "blocks", totBlocks/1000, "duration", time.Since(t0).Truncate(time.Second))
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build wasm
+
+package syncthing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+)
+
+// There is no built in database on wasm; the host passes its own
+// implementation of db.DB to New.
+var errNoDatabase = errors.New("no built in database on wasm")
+
+func OpenDatabase(_ string, _ time.Duration) (db.DB, error) {
+	return nil, errNoDatabase
+}
+
+func TryMigrateDatabase(_ context.Context, _ time.Duration) error {
+	return nil
+}
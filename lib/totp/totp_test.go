@@ -0,0 +1,65 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+func TestRFC6238Vectors(t *testing.T) {
+	// The SHA1 test vectors from RFC 6238 appendix B, truncated to six
+	// digits.
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	cases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tc := range cases {
+		code, err := Code(secret, time.Unix(tc.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tc.code {
+			t.Errorf("code at %d: got %s, expected %s", tc.unix, code, tc.code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := NewSecret()
+	now := time.Now()
+	code, err := Code(secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, ok := Validate(secret, code, now)
+	if !ok || s != step(now) {
+		t.Errorf("expected current code to be valid for step %d, got %d, %v", step(now), s, ok)
+	}
+	if _, ok := Validate(secret, code[:3]+" "+code[3:], now.Add(Period)); !ok {
+		t.Error("expected code to be valid during the next period, with spaces")
+	}
+	if _, ok := Validate(secret, code, now.Add(3*Period)); ok {
+		t.Error("expected code to be invalid three periods later")
+	}
+	if _, ok := Validate(secret, "12345", now); ok {
+		t.Error("expected short code to be invalid")
+	}
+	if _, ok := Validate("not base32!", code, now); ok {
+		t.Error("expected invalid secret to fail validation")
+	}
+}
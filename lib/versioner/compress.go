@@ -0,0 +1,186 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+// compressedSuffix is appended to the tagged name of compressed versions.
+// It can't be mistaken for the extension of an uncompressed version, as
+// that never contains a period.
+const compressedSuffix = ".st.gz"
+
+// The uncompressed size is kept in an extra field of the gzip header, so
+// that versions can be listed without decompressing them.
+var gzipSizeField = [2]byte{'S', 'T'}
+
+var errNoCompressedSize = errors.New("no uncompressed size in header")
+
+// compressionFromParams returns whether the versioning parameters ask for
+// versions to be compressed.
+func compressionFromParams(params map[string]string) bool {
+	switch params["compression"] {
+	case "":
+		return false
+	case "gzip":
+		return true
+	default:
+		slog.Warn("Unsupported version compression, versions will not be compressed", slog.String("compression", params["compression"]))
+		return false
+	}
+}
+
+// trimCompressedSuffix returns the name of a compressed version without
+// the compression suffix, and whether it was one.
+func trimCompressedSuffix(path string) (string, bool) {
+	trimmed, ok := strings.CutSuffix(path, compressedSuffix)
+	if !ok || tagExp.FindStringSubmatch(trimmed) == nil {
+		return path, false
+	}
+	return trimmed, true
+}
+
+func isCompressed(path string) bool {
+	_, ok := trimCompressedSuffix(path)
+	return ok
+}
+
+// compressVersion compresses the version at path, returning the path of
+// the version afterwards. The version is left as is if compression fails.
+func compressVersion(versionsFs fs.Filesystem, path string) string {
+	if path == "" {
+		return path
+	}
+	dst := path + compressedSuffix
+	if err := compressFile(versionsFs, path, dst); err != nil {
+		slog.Warn("Failed to compress version", slogutil.FilePath(path), slogutil.Error(err))
+		return path
+	}
+	if err := versionsFs.Remove(path); err != nil {
+		slog.Warn("Failed to remove compressed version", slogutil.FilePath(path), slogutil.Error(err))
+		_ = versionsFs.Remove(dst)
+		return path
+	}
+	return dst
+}
+
+func compressFile(versionsFs fs.Filesystem, path, dst string) error {
+	src, err := versionsFs.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(filepath.Dir(dst), fs.TempName(filepath.Base(dst)))
+	fd, err := versionsFs.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = versionsFs.Remove(tmp) }()
+
+	gw := gzip.NewWriter(fd)
+	gw.Name = filepath.Base(path)
+	gw.ModTime = info.ModTime()
+	gw.Extra = binary.LittleEndian.AppendUint64(append(gzipSizeField[:], 8, 0), uint64(info.Size()))
+	if _, err := io.Copy(gw, src); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		fd.Close()
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		return err
+	}
+
+	mtime := info.ModTime()
+	_ = versionsFs.Chtimes(tmp, mtime, mtime)
+	return versionsFs.Rename(tmp, dst)
+}
+
+// compressedSize returns the uncompressed size of a compressed version.
+func compressedSize(versionsFs fs.Filesystem, path string) (int64, error) {
+	fd, err := versionsFs.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer fd.Close()
+	gr, err := gzip.NewReader(fd)
+	if err != nil {
+		return 0, err
+	}
+	extra := gr.Extra
+	// The extra field is a sequence of subfields, each with a two byte ID
+	// and a two byte length.
+	for len(extra) >= 4 {
+		n := int(binary.LittleEndian.Uint16(extra[2:]))
+		if len(extra) < 4+n {
+			break
+		}
+		if [2]byte(extra[:2]) == gzipSizeField && n == 8 {
+			return int64(binary.LittleEndian.Uint64(extra[4:])), nil
+		}
+		extra = extra[4+n:]
+	}
+	return 0, errNoCompressedSize
+}
+
+// decompressVersion writes the decompressed content of the version to dst,
+// and removes the version.
+func decompressVersion(srcFs, dstFs fs.Filesystem, src, dst string) error {
+	tmp := filepath.Join(filepath.Dir(dst), fs.TempName(filepath.Base(dst)))
+	if err := decompressFile(srcFs, dstFs, src, tmp); err != nil {
+		_ = dstFs.Remove(tmp)
+		return err
+	}
+	if err := dstFs.Rename(tmp, dst); err != nil {
+		_ = dstFs.Remove(tmp)
+		return err
+	}
+	return srcFs.Remove(src)
+}
+
+func decompressFile(srcFs, dstFs fs.Filesystem, src, dst string) error {
+	in, err := srcFs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	gr, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+
+	out, err := dstFs.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, gr); err != nil {
+		out.Close()
+		return err
+	}
+	if err := gr.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+func TestUntagCompressedFilename(t *testing.T) {
+	cases := [][3]string{
+		{"foo~20240102-030405.txt.st.gz", "foo.txt", "20240102-030405"},
+		{"foo~20240102-030405.st.gz", "foo", "20240102-030405"},
+		{"foo.st~20240102-030405.gz", "foo.st.gz", "20240102-030405"},
+		{"plain.st.gz", "", ""},
+	}
+	for _, tc := range cases {
+		if name, tag := UntagFilename(tc[0]); name != tc[1] || tag != tc[2] {
+			t.Errorf("UntagFilename(%q) = %q, %q", tc[0], name, tag)
+		}
+	}
+}
+
+func TestCompressedVersions(t *testing.T) {
+	versionsDir := t.TempDir()
+	cfg := config.FolderConfiguration{
+		FilesystemType: config.FilesystemTypeBasic,
+		Path:           t.TempDir(),
+		Versioning: config.VersioningConfiguration{
+			FSType: config.FilesystemTypeBasic,
+			FSPath: versionsDir,
+			Params: map[string]string{"compression": "gzip"},
+		},
+	}
+	folderFs := cfg.Filesystem()
+	versionsFs := fs.NewFilesystem(fs.FilesystemTypeBasic, versionsDir)
+
+	content := strings.Repeat("compressible text\n", 1000)
+	writeFile(t, folderFs, "file.txt", content)
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := folderFs.Chtimes("file.txt", mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	v := newSimple(cfg)
+	if err := v.Archive("file.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := versionsFs.Glob("file~*.txt" + compressedSuffix)
+	if err != nil || len(names) != 1 {
+		t.Fatal("expected one compressed version, got", names, err)
+	}
+	if info, err := versionsFs.Lstat(names[0]); err != nil || info.Size() >= int64(len(content)) {
+		t.Error("version not compressed", err)
+	}
+
+	versions, err := v.GetVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions["file.txt"]) != 1 || versions["file.txt"][0].Size != int64(len(content)) {
+		t.Fatal("unexpected versions", versions)
+	}
+	versionTime := versions["file.txt"][0].VersionTime
+
+	fd, err := v.OpenVersion("file.txt", versionTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := io.ReadAll(fd)
+	fd.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != content {
+		t.Error("unexpected version content")
+	}
+
+	if err := v.Restore("file.txt", versionTime); err != nil {
+		t.Fatal(err)
+	}
+	if readFile(t, folderFs, "file.txt") != content {
+		t.Error("unexpected restored content")
+	}
+	if info, err := folderFs.Lstat("file.txt"); err != nil || !info.ModTime().Equal(mtime) {
+		t.Error("unexpected restored modification time", err)
+	}
+	if n := countFiles(t, versionsFs); n != 0 {
+		t.Errorf("expected no versions left, got %d", n)
+	}
+}
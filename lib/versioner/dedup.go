@@ -0,0 +1,473 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/osutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func init() {
+	// Register the constructor for this type of versioner with the name "dedup"
+	factories["dedup"] = newDedup
+}
+
+const (
+	dedupManifestsDir = "files"
+	dedupBlocksDir    = "blocks"
+)
+
+// The dedup versioner stores versions as manifests listing the blocks of
+// the file, with each distinct block stored only once, named by its hash.
+// Blocks are the same size and hash as in the index, so consecutive
+// versions of a large file that changed in a few places share most of
+// their blocks. Blocks no longer referenced by any version are removed by
+// Clean. Retention works as for the simple versioner.
+type dedup struct {
+	keep         int
+	cleanoutDays int
+	policy       config.RetentionPolicy
+	folderFs     fs.Filesystem
+	versionsFs   fs.Filesystem
+	manifests    fs.Filesystem
+	blocks       fs.Filesystem
+	index        *versionIndex
+
+	// Archive and Restore hold the read lock while they need the blocks
+	// they have stored or are reading, Clean holds the write lock while
+	// removing unreferenced blocks.
+	mut sync.RWMutex
+}
+
+type dedupManifest struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Blocks  []string  `json:"blocks"`
+}
+
+func newDedup(cfg config.FolderConfiguration) Versioner {
+	keep, err := strconv.Atoi(cfg.Versioning.Params["keep"])
+	cleanoutDays, _ := strconv.Atoi(cfg.Versioning.Params["cleanoutDays"])
+	// On error we default to 0, "do not clean out the versioned items"
+
+	if err != nil {
+		keep = 5 // A reasonable default
+	}
+
+	versionsFs := versionerFsFromFolderCfg(cfg)
+	v := &dedup{
+		keep:         keep,
+		cleanoutDays: cleanoutDays,
+		policy:       retentionFromConfig(cfg),
+		folderFs:     cfg.Filesystem(),
+		versionsFs:   versionsFs,
+		manifests:    fs.NewFilesystem(versionsFs.Type(), filepath.Join(versionsFs.URI(), dedupManifestsDir)),
+		blocks:       fs.NewFilesystem(versionsFs.Type(), filepath.Join(versionsFs.URI(), dedupBlocksDir)),
+	}
+	v.index = newVersionIndex(v.manifests)
+	v.index.versionOf = v.manifestVersion
+
+	l.Debugf("instantiated %#v", v)
+	return v
+}
+
+func (v *dedup) String() string {
+	return fmt.Sprintf("dedup@%p", v)
+}
+
+// Archive moves the named file away to a version archive. If this function
+// returns nil, the named file does not exist any more (has been archived).
+func (v *dedup) Archive(filePath string) error {
+	v.mut.RLock()
+	defer v.mut.RUnlock()
+	return v.archiveRLocked(osutil.NativeFilename(filePath), "")
+}
+
+// ArchiveWithMetadata is like Archive, noting the device whose change has
+// the file archived.
+func (v *dedup) ArchiveWithMetadata(filePath string, meta ArchiveMetadata) error {
+	v.mut.RLock()
+	defer v.mut.RUnlock()
+	return v.archiveRLocked(osutil.NativeFilename(filePath), meta.ModifiedBy)
+}
+
+func (v *dedup) indexed() *versionIndex {
+	return v.index
+}
+
+func (v *dedup) archiveRLocked(filePath, origin string) error {
+	info, err := v.folderFs.Lstat(filePath)
+	if fs.IsNotExist(err) {
+		l.Debugln("not archiving nonexistent file", filePath)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.IsSymlink() {
+		panic("bug: attempting to version a symlink")
+	}
+
+	if _, err := v.versionsFs.Stat("."); fs.IsNotExist(err) {
+		slog.Debug("Creating versions dir")
+		if err := v.versionsFs.MkdirAll(".", 0o755); err != nil {
+			return err
+		}
+		_ = v.versionsFs.Hide(".")
+	}
+
+	manifest, err := v.storeBlocks(filePath, info)
+	if err != nil {
+		return err
+	}
+
+	ver := TagFilename(filePath, time.Now().Format(TimeFormat))
+	l.Debugln("archiving", filePath, "as", ver, "with", len(manifest.Blocks), "blocks")
+	if err := v.writeManifest(ver, manifest); err != nil {
+		return err
+	}
+	if err := v.folderFs.Remove(filePath); err != nil {
+		_ = v.manifests.Remove(ver)
+		return err
+	}
+	v.index.record(ver, origin)
+
+	cleanVersions(v.manifests, v.index, findAllVersions(v.manifests, filePath), v.toRemove)
+
+	return nil
+}
+
+// storeBlocks stores those blocks of the file that aren't stored yet, and
+// returns the manifest to put it back together.
+func (v *dedup) storeBlocks(filePath string, info fs.FileInfo) (dedupManifest, error) {
+	fd, err := v.folderFs.Open(filePath)
+	if err != nil {
+		return dedupManifest{}, err
+	}
+	defer fd.Close()
+
+	manifest := dedupManifest{
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	buf := make([]byte, protocol.BlockSize(info.Size()))
+	for {
+		n, err := io.ReadFull(fd, buf)
+		if n > 0 {
+			hash := sha256.Sum256(buf[:n])
+			key := hex.EncodeToString(hash[:])
+			if err := v.storeBlock(key, buf[:n]); err != nil {
+				return dedupManifest{}, err
+			}
+			manifest.Blocks = append(manifest.Blocks, key)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return manifest, nil
+		} else if err != nil {
+			return dedupManifest{}, err
+		}
+	}
+}
+
+func blockPath(key string) string {
+	return filepath.Join(key[:2], key)
+}
+
+func (v *dedup) storeBlock(key string, data []byte) error {
+	path := blockPath(key)
+	if _, err := v.blocks.Lstat(path); err == nil {
+		// Already stored for another version.
+		return nil
+	}
+
+	if err := v.blocks.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := fs.TempName(path)
+	fd, err := v.blocks.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(data); err != nil {
+		fd.Close()
+		_ = v.blocks.Remove(tmp)
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		_ = v.blocks.Remove(tmp)
+		return err
+	}
+	return v.blocks.Rename(tmp, path)
+}
+
+func (v *dedup) writeManifest(path string, manifest dedupManifest) error {
+	bs, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err := v.manifests.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	fd, err := v.manifests.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(bs); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
+
+func (v *dedup) readManifest(path string) (dedupManifest, error) {
+	fd, err := v.manifests.Open(path)
+	if err != nil {
+		return dedupManifest{}, err
+	}
+	defer fd.Close()
+	var manifest dedupManifest
+	err = json.NewDecoder(fd).Decode(&manifest)
+	return manifest, err
+}
+
+// manifestVersion is the version of a manifest in the index, with the size
+// and modification time of the file it describes.
+func (v *dedup) manifestVersion(path string, f fs.FileInfo) (string, FileVersion, bool) {
+	name, version, ok := versionOf(path, f)
+	if !ok {
+		return "", FileVersion{}, false
+	}
+	manifest, err := v.readManifest(path)
+	if err != nil {
+		l.Debugln("skipping unreadable manifest", path, err)
+		return "", FileVersion{}, false
+	}
+	version.Size = manifest.Size
+	version.ModTime = manifest.ModTime.Truncate(time.Second)
+	return name, version, true
+}
+
+// assemble writes the file described by the manifest to the given path.
+func (v *dedup) assemble(dstFs fs.Filesystem, path string, manifest dedupManifest) error {
+	fd, err := dstFs.Create(path)
+	if err != nil {
+		return err
+	}
+	for _, key := range manifest.Blocks {
+		if err := v.copyBlock(fd, key); err != nil {
+			fd.Close()
+			_ = dstFs.Remove(path)
+			return err
+		}
+	}
+	if err := fd.Close(); err != nil {
+		_ = dstFs.Remove(path)
+		return err
+	}
+	return dstFs.Chtimes(path, manifest.ModTime, manifest.ModTime)
+}
+
+func (v *dedup) copyBlock(w io.Writer, key string) error {
+	fd, err := v.blocks.Open(blockPath(key))
+	if err != nil {
+		return fmt.Errorf("block %s: %w", key, err)
+	}
+	defer fd.Close()
+	_, err = io.Copy(w, fd)
+	return err
+}
+
+func (v *dedup) GetVersions() (map[string][]FileVersion, error) {
+	return v.index.versions()
+}
+
+// OpenVersion puts the version together in a temporary file, which is
+// removed again when the returned file is closed.
+func (v *dedup) OpenVersion(filePath string, versionTime time.Time) (fs.File, error) {
+	v.mut.RLock()
+	defer v.mut.RUnlock()
+
+	path, err := v.index.lookup(filePath, versionTime)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := v.readManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Versions of files with the same name may be open at the same time,
+	// so each gets a temporary file of its own.
+	tmp, err := os.CreateTemp("", "syncthing-version-*")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	tmpFs := fs.NewFilesystem(fs.FilesystemTypeBasic, filepath.Dir(tmp.Name()))
+	name := filepath.Base(tmp.Name())
+	if err := v.assemble(tmpFs, name, manifest); err != nil {
+		_ = tmpFs.Remove(name)
+		return nil, err
+	}
+	fd, err := tmpFs.Open(name)
+	if err != nil {
+		_ = tmpFs.Remove(name)
+		return nil, err
+	}
+	return &removeOnCloseFile{File: fd, fs: tmpFs, path: name}, nil
+}
+
+func (v *dedup) Restore(filePath string, versionTime time.Time) error {
+	v.mut.RLock()
+	defer v.mut.RUnlock()
+
+	filePath = osutil.NativeFilename(filePath)
+	path, err := v.index.lookup(filePath, versionTime)
+	if err != nil {
+		return err
+	}
+	manifest, err := v.readManifest(path)
+	if err != nil {
+		return err
+	}
+
+	// If the something already exists where we are restoring to, archive existing file for versioning
+	// remove if it's a symlink, or fail if it's a directory
+	if info, err := v.folderFs.Lstat(filePath); err == nil {
+		switch {
+		case info.IsDir():
+			return ErrDirectory
+		case info.IsSymlink():
+			// Remove existing symlinks (as we don't want to archive them)
+			if err := v.folderFs.Remove(filePath); err != nil {
+				return fmt.Errorf("removing existing symlink: %w", err)
+			}
+		case info.IsRegular():
+			if err := v.archiveRLocked(filePath, ""); err != nil {
+				return fmt.Errorf("archiving existing file: %w", err)
+			}
+		default:
+			panic("bug: unknown item type")
+		}
+	} else if !fs.IsNotExist(err) {
+		return err
+	}
+
+	_ = v.folderFs.MkdirAll(filepath.Dir(filePath), 0o755)
+	tmp := fs.TempName(filePath)
+	if err := v.assemble(v.folderFs, tmp, manifest); err != nil {
+		return err
+	}
+	if err := v.folderFs.Rename(tmp, filePath); err != nil {
+		_ = v.folderFs.Remove(tmp)
+		return err
+	}
+
+	// The version has been restored, like with the other versioners. Its
+	// blocks go away on the next clean, unless still used.
+	_ = v.manifests.Remove(path)
+	v.index.update(path)
+	return nil
+}
+
+func (v *dedup) Clean(ctx context.Context) error {
+	if err := clean(ctx, v.manifests, v.index, v.toRemove); err != nil {
+		return err
+	}
+
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	return v.removeUnreferencedBlocks(ctx)
+}
+
+func (v *dedup) removeUnreferencedBlocks(ctx context.Context) error {
+	if _, err := v.blocks.Lstat("."); fs.IsNotExist(err) {
+		return nil
+	}
+
+	referenced := make(map[string]struct{})
+	err := v.manifests.Walk(".", func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			if path == "." && fs.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsRegular() {
+			return nil
+		}
+		manifest, err := v.readManifest(path)
+		if err != nil {
+			return err
+		}
+		for _, key := range manifest.Blocks {
+			referenced[key] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	err = v.blocks.Walk(".", func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if !info.IsRegular() {
+			return nil
+		}
+		if _, ok := referenced[filepath.Base(path)]; ok {
+			return nil
+		}
+		if err := v.blocks.Remove(path); err != nil {
+			slog.WarnContext(ctx, "Failed to remove unreferenced version block", slogutil.FilePath(path), slogutil.Error(err))
+			return nil
+		}
+		removed++
+		return nil
+	})
+	l.Debugln("Versioner: removed", removed, "unreferenced blocks in", v.blocks)
+	return err
+}
+
+func (v *dedup) toRemove(versions []string, now time.Time) []string {
+	return simple{keep: v.keep, cleanoutDays: v.cleanoutDays, policy: v.policy}.toRemove(versions, now)
+}
+
+// removeOnCloseFile is a temporary file that is removed when closed.
+type removeOnCloseFile struct {
+	fs.File
+	fs   fs.Filesystem
+	path string
+}
+
+func (f *removeOnCloseFile) Close() error {
+	err := f.File.Close()
+	_ = f.fs.Remove(f.path)
+	return err
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestDedupVersioner(t *testing.T) {
+	versionsDir := t.TempDir()
+	cfg := config.FolderConfiguration{
+		FilesystemType: config.FilesystemTypeBasic,
+		Path:           t.TempDir(),
+		Versioning: config.VersioningConfiguration{
+			Type:   "dedup",
+			FSType: config.FilesystemTypeBasic,
+			FSPath: versionsDir,
+		},
+	}
+	folderFs := cfg.Filesystem()
+	blocksFs := fs.NewFilesystem(fs.FilesystemTypeBasic, filepath.Join(versionsDir, dedupBlocksDir))
+
+	block := func(b byte) string {
+		return string(bytes.Repeat([]byte{b}, protocol.MinBlockSize))
+	}
+	contentA := block('x') + block('y')
+	contentB := block('x') + block('z')
+	writeFile(t, folderFs, "a", contentA)
+	writeFile(t, folderFs, "b", contentB)
+
+	v := newDedup(cfg)
+	for _, name := range []string{"a", "b"} {
+		if err := v.Archive(name); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := folderFs.Lstat(name); !fs.IsNotExist(err) {
+			t.Fatal("file should have been archived:", err)
+		}
+	}
+	if n := countFiles(t, blocksFs); n != 3 {
+		t.Errorf("expected three distinct blocks stored, got %d", n)
+	}
+
+	versions, err := v.GetVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions["a"]) != 1 || versions["a"][0].Size != int64(len(contentA)) {
+		t.Fatal("unexpected versions", versions)
+	}
+
+	// The same version may be open more than once, and closing one
+	// leaves the other alone.
+	first, err := v.OpenVersion("b", versions["b"][0].VersionTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := v.OpenVersion("b", versions["b"][0].VersionTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Name() == fd.Name() {
+		t.Error("expected a temporary file of its own for each open version")
+	}
+	first.Close()
+	bs, err := io.ReadAll(fd)
+	fd.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != contentB {
+		t.Error("unexpected version content")
+	}
+
+	if err := v.Restore("a", versions["a"][0].VersionTime); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, folderFs, "a"); content != contentA {
+		t.Error("unexpected restored content")
+	}
+
+	// The block only used by the restored version is no longer needed.
+	if err := v.Clean(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := countFiles(t, blocksFs); n != 2 {
+		t.Errorf("expected two blocks left after clean, got %d", n)
+	}
+}
+
+func countFiles(t *testing.T, filesystem fs.Filesystem) int {
+	t.Helper()
+	n := 0
+	err := filesystem.Walk(".", func(_ string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsRegular() {
+			n++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
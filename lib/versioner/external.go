@@ -7,7 +7,9 @@
 package versioner
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -29,6 +31,7 @@ func init() {
 
 type external struct {
 	command    string
+	metadata   bool
 	filesystem fs.Filesystem
 }
 
@@ -41,6 +44,7 @@ func newExternal(cfg config.FolderConfiguration) Versioner {
 
 	s := external{
 		command:    command,
+		metadata:   cfg.Versioning.Params["metadata"] == "true",
 		filesystem: cfg.Filesystem(),
 	}
 
@@ -51,6 +55,16 @@ func newExternal(cfg config.FolderConfiguration) Versioner {
 // Archive moves the named file away to a version archive. If this function
 // returns nil, the named file does not exist any more (has been archived).
 func (v external) Archive(filePath string) error {
+	return v.archive(filePath, nil)
+}
+
+// ArchiveWithMetadata is like Archive, passing the metadata to the command
+// as a JSON document on stdin when the "metadata" parameter is set.
+func (v external) ArchiveWithMetadata(filePath string, meta ArchiveMetadata) error {
+	return v.archive(filePath, &meta)
+}
+
+func (v external) archive(filePath string, meta *ArchiveMetadata) error {
 	info, err := v.filesystem.Lstat(filePath)
 	if fs.IsNotExist(err) {
 		l.Debugln("not archiving nonexistent file", filePath)
@@ -98,6 +112,13 @@ func (v external) Archive(filePath string) error {
 		}
 	}
 	cmd.Env = filteredEnv
+	if v.metadata && meta != nil {
+		bs, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		cmd.Stdin = bytes.NewReader(bs)
+	}
 	combinedOutput, err := cmd.CombinedOutput()
 	l.Debugln("external command output:", string(combinedOutput))
 	if err != nil {
@@ -119,6 +140,10 @@ func (external) GetVersions() (map[string][]FileVersion, error) {
 	return nil, ErrRestorationNotSupported
 }
 
+func (external) OpenVersion(_ string, _ time.Time) (fs.File, error) {
+	return nil, ErrRestorationNotSupported
+}
+
 func (external) Restore(_ string, _ time.Time) error {
 	return ErrRestorationNotSupported
 }
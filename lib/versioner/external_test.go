@@ -7,8 +7,10 @@
 package versioner
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/syncthing/syncthing/lib/build"
@@ -76,6 +78,47 @@ func TestExternal(t *testing.T) {
 	}
 }
 
+func TestExternalMetadata(t *testing.T) {
+	if build.IsWindows {
+		t.Skip("test uses a shell command")
+	}
+
+	file := filepath.Join("testdata", "file.txt")
+	prepForRemoval(t, file)
+	defer os.RemoveAll("testdata")
+
+	// The command saves the metadata it's given on stdin next to the file
+	// it removes.
+
+	e := external{
+		filesystem: fs.NewFilesystem(fs.FilesystemTypeBasic, "."),
+		command:    `sh -c 'cat > "$0.json" && rm "$0"' %FILE_PATH%`,
+		metadata:   true,
+	}
+	meta := ArchiveMetadata{
+		Folder:     "default",
+		Path:       file,
+		OldVersion: map[string]uint64{"AAAAAAA": 1},
+		NewVersion: map[string]uint64{"AAAAAAA": 1, "BBBBBBB": 1},
+		ModifiedBy: "BBBBBBB",
+	}
+	if err := e.ArchiveWithMetadata(file, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := os.ReadFile(file + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ArchiveMetadata
+	if err := json.Unmarshal(bs, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, meta) {
+		t.Errorf("got metadata %+v, expected %+v", got, meta)
+	}
+}
+
 func prepForRemoval(t *testing.T, file string) {
 	if err := os.RemoveAll("testdata"); err != nil {
 		t.Fatal(err)
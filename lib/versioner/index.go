@@ -0,0 +1,394 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+// A versionIndex keeps track of the versions in a versions filesystem, so
+// that listing them doesn't require walking the filesystem every time. It
+// is loaded by a walk on first use, and then kept up to date with the
+// changes the versioner makes itself. Changes made by others are not seen
+// until the index is invalidated. With a catalog, the index is kept in the
+// database as well, and loaded from there instead of walking the versions
+// after a restart.
+type versionIndex struct {
+	fs        fs.Filesystem
+	versionOf func(path string, f fs.FileInfo) (string, FileVersion, bool)
+
+	mut     sync.Mutex
+	catalog *db.Typed                 // nil when not persisted
+	entries map[string]indexedVersion // path in fs -> version; nil when not loaded
+}
+
+const (
+	catalogEntryPrefix = "file/"
+	catalogCompleteKey = "complete"
+	catalogSourceKey   = "source"
+)
+
+// catalogEntry is an indexedVersion as stored in the catalog.
+type catalogEntry struct {
+	Name     string `json:"name"`
+	DiskSize int64  `json:"diskSize"`
+	FileVersion
+}
+
+type indexedVersion struct {
+	name     string
+	diskSize int64
+	FileVersion
+}
+
+func newVersionIndex(fs fs.Filesystem) *versionIndex {
+	x := &versionIndex{fs: fs}
+	x.versionOf = x.fileVersion
+	return x
+}
+
+// fileVersion is the version of a file in the versions filesystem, with
+// the uncompressed size for compressed versions.
+func (x *versionIndex) fileVersion(path string, f fs.FileInfo) (string, FileVersion, bool) {
+	name, version, ok := versionOf(path, f)
+	if !ok || !isCompressed(path) {
+		return name, version, ok
+	}
+	size, err := compressedSize(x.fs, path)
+	if err != nil {
+		l.Debugln("no size for compressed version", path, err)
+		return name, version, ok
+	}
+	version.Size = size
+	return name, version, true
+}
+
+// versions returns the versions per file, ordered by version time.
+func (x *versionIndex) versions() (map[string][]FileVersion, error) {
+	return x.versionsBetween(time.Time{}, time.Time{})
+}
+
+// versionsBetween is like versions, for only the versions archived in the
+// range [from, to). A zero time leaves that end open.
+func (x *versionIndex) versionsBetween(from, to time.Time) (map[string][]FileVersion, error) {
+	x.mut.Lock()
+	defer x.mut.Unlock()
+	if err := x.loadLocked(); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]FileVersion)
+	for _, e := range x.entries {
+		if !inRange(e.VersionTime, from, to) {
+			continue
+		}
+		files[e.name] = append(files[e.name], e.FileVersion)
+	}
+	for _, versions := range files {
+		slices.SortFunc(versions, func(a, b FileVersion) int {
+			return a.VersionTime.Compare(b.VersionTime)
+		})
+	}
+	return files, nil
+}
+
+// open opens the given version of the named file. Compressed versions are
+// decompressed to a temporary file, removed again when closed.
+func (x *versionIndex) open(name string, versionTime time.Time) (fs.File, error) {
+	path, err := x.lookup(name, versionTime)
+	if err != nil {
+		return nil, err
+	}
+	if !isCompressed(path) {
+		return x.fs.Open(path)
+	}
+
+	tmp := fs.TempName(filepath.Base(name))
+	if err := decompressFile(x.fs, x.fs, path, tmp); err != nil {
+		_ = x.fs.Remove(tmp)
+		return nil, err
+	}
+	fd, err := x.fs.Open(tmp)
+	if err != nil {
+		_ = x.fs.Remove(tmp)
+		return nil, err
+	}
+	return &removeOnCloseFile{File: fd, fs: x.fs, path: tmp}, nil
+}
+
+// lookup returns the path of the given version of the named file.
+func (x *versionIndex) lookup(name string, versionTime time.Time) (string, error) {
+	x.mut.Lock()
+	defer x.mut.Unlock()
+	if err := x.loadLocked(); err != nil {
+		return "", err
+	}
+	versionTime = versionTime.Truncate(time.Second)
+	for path, e := range x.entries {
+		if e.name == name && e.VersionTime.Equal(versionTime) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%v: %w", errNotFound, fs.ErrNotExist)
+}
+
+// indexedPath is a version with its path in the filesystem.
+type indexedPath struct {
+	path string
+	indexedVersion
+}
+
+// oldestFirst returns all versions ordered by version time, oldest first,
+// and the total size they take up on disk.
+func (x *versionIndex) oldestFirst() ([]indexedPath, int64, error) {
+	x.mut.Lock()
+	defer x.mut.Unlock()
+	if err := x.loadLocked(); err != nil {
+		return nil, 0, err
+	}
+
+	versions := make([]indexedPath, 0, len(x.entries))
+	var total int64
+	for path, e := range x.entries {
+		versions = append(versions, indexedPath{path: path, indexedVersion: e})
+		total += e.diskSize
+	}
+	slices.SortFunc(versions, func(a, b indexedPath) int {
+		if c := a.VersionTime.Compare(b.VersionTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.path, b.path)
+	})
+	return versions, total, nil
+}
+
+// update brings the entry for the given path up to date with the
+// filesystem, after it has been added or removed.
+func (x *versionIndex) update(path string) {
+	x.record(path, "")
+}
+
+// record is like update, noting the device whose change had an added
+// version archived.
+func (x *versionIndex) record(path, origin string) {
+	if path == "" {
+		return
+	}
+	x.mut.Lock()
+	defer x.mut.Unlock()
+	if x.entries == nil {
+		if x.catalog == nil {
+			// Not loaded; the change will be seen when it is.
+			return
+		}
+		// The catalog must see the change.
+		if err := x.loadLocked(); err != nil {
+			return
+		}
+	}
+	delete(x.entries, path)
+	if info, err := x.fs.Lstat(path); err == nil && info.IsRegular() {
+		if name, version, ok := x.versionOf(path, info); ok {
+			version.Origin = origin
+			e := indexedVersion{name: name, diskSize: info.Size(), FileVersion: version}
+			x.entries[path] = e
+			x.storeLocked(path, e)
+			return
+		}
+	}
+	if x.catalog != nil {
+		if err := x.catalog.Delete(catalogEntryPrefix + path); err != nil {
+			x.catalogFailedLocked(err)
+		}
+	}
+}
+
+// reconcile brings the index up to date with the paths found by a walk of
+// the filesystem, picking up changes made by others.
+func (x *versionIndex) reconcile(paths []string) {
+	x.mut.Lock()
+	if x.entries == nil && x.catalog == nil {
+		x.mut.Unlock()
+		return
+	}
+	if err := x.loadLocked(); err != nil {
+		x.mut.Unlock()
+		return
+	}
+	found := make(map[string]struct{}, len(paths))
+	var changed []string
+	for _, path := range paths {
+		found[path] = struct{}{}
+		if _, ok := x.entries[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	for path := range x.entries {
+		if _, ok := found[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	x.mut.Unlock()
+
+	for _, path := range changed {
+		x.update(path)
+	}
+}
+
+// invalidate drops the index, to be loaded again on next use.
+func (x *versionIndex) invalidate() {
+	x.mut.Lock()
+	x.entries = nil
+	if x.catalog != nil {
+		_ = x.catalog.Delete(catalogCompleteKey)
+	}
+	x.mut.Unlock()
+}
+
+// setCatalog makes the index persisted in the catalog. A catalog of
+// versions elsewhere, from before the configuration changed, is rebuilt.
+func (x *versionIndex) setCatalog(catalog *db.Typed) {
+	x.mut.Lock()
+	defer x.mut.Unlock()
+	x.catalog = catalog
+	x.entries = nil
+
+	source := string(x.fs.Type()) + ":" + x.fs.URI()
+	if prev, _, _ := catalog.String(catalogSourceKey); prev != source {
+		if err := catalog.Delete(catalogCompleteKey); err != nil {
+			x.catalogFailedLocked(err)
+			return
+		}
+		if err := catalog.PutString(catalogSourceKey, source); err != nil {
+			x.catalogFailedLocked(err)
+		}
+	}
+}
+
+// loadCatalogLocked returns the entries from the catalog, if it holds a
+// complete index.
+func (x *versionIndex) loadCatalogLocked() (map[string]indexedVersion, bool) {
+	if complete, ok, err := x.catalog.Bool(catalogCompleteKey); err != nil || !ok || !complete {
+		return nil, false
+	}
+	entries := make(map[string]indexedVersion)
+	it, errFn := x.catalog.PrefixBytes(catalogEntryPrefix)
+	for kv := range it {
+		var e catalogEntry
+		if err := json.Unmarshal(kv.Value, &e); err != nil {
+			l.Debugln("invalid catalog entry", kv.Key, err)
+			return nil, false
+		}
+		path := strings.TrimPrefix(kv.Key, catalogEntryPrefix)
+		entries[path] = indexedVersion{name: e.Name, diskSize: e.DiskSize, FileVersion: e.FileVersion}
+	}
+	if err := errFn(); err != nil {
+		l.Debugln("reading catalog:", err)
+		return nil, false
+	}
+	return entries, true
+}
+
+// storeCatalogLocked replaces the contents of the catalog with the loaded
+// entries, written in one go.
+func (x *versionIndex) storeCatalogLocked() {
+	kvs := make([]db.KeyValue, 0, len(x.entries))
+	for path, e := range x.entries {
+		kvs = append(kvs, db.KeyValue{Key: catalogEntryPrefix + path, Value: catalogValue(e)})
+	}
+	if err := x.catalog.ReplacePrefixBytes(catalogEntryPrefix, kvs); err != nil {
+		x.catalogFailedLocked(err)
+		return
+	}
+	if err := x.catalog.PutBool(catalogCompleteKey, true); err != nil {
+		x.catalogFailedLocked(err)
+	}
+}
+
+func (x *versionIndex) storeLocked(path string, e indexedVersion) bool {
+	if x.catalog == nil {
+		return true
+	}
+	if err := x.catalog.PutBytes(catalogEntryPrefix+path, catalogValue(e)); err != nil {
+		x.catalogFailedLocked(err)
+		return false
+	}
+	return true
+}
+
+func catalogValue(e indexedVersion) []byte {
+	bs, _ := json.Marshal(catalogEntry{Name: e.name, DiskSize: e.diskSize, FileVersion: e.FileVersion})
+	return bs
+}
+
+// catalogFailedLocked stops using a catalog that can't be written, as it
+// would no longer match the versions.
+func (x *versionIndex) catalogFailedLocked(err error) {
+	slog.Warn("Failed to update versions catalog", slogutil.Error(err))
+	_ = x.catalog.Delete(catalogCompleteKey)
+	x.catalog = nil
+}
+
+func (x *versionIndex) loadLocked() error {
+	if x.entries != nil {
+		return nil
+	}
+	if x.catalog != nil {
+		if entries, ok := x.loadCatalogLocked(); ok {
+			x.entries = entries
+			return nil
+		}
+	}
+
+	entries := make(map[string]indexedVersion)
+	err := x.fs.Walk(".", func(path string, f fs.FileInfo, err error) error {
+		// Skip root (which is ok to be a symlink)
+		if path == "." {
+			return nil
+		}
+
+		// Skip walking if we cannot walk...
+		if err != nil {
+			return err
+		}
+
+		// Ignore symlinks
+		if f.IsSymlink() {
+			return fs.SkipDir
+		}
+
+		// No records for directories
+		if f.IsDir() {
+			return nil
+		}
+
+		if name, version, ok := x.versionOf(path, f); ok {
+			entries[path] = indexedVersion{name: name, diskSize: f.Size(), FileVersion: version}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	x.entries = entries
+	if x.catalog != nil {
+		x.storeCatalogLocked()
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/db/sqlite"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+func TestVersionIndex(t *testing.T) {
+	versionsDir := t.TempDir()
+	cfg := config.FolderConfiguration{
+		FilesystemType: config.FilesystemTypeBasic,
+		Path:           t.TempDir(),
+		Versioning: config.VersioningConfiguration{
+			FSType: config.FilesystemTypeBasic,
+			FSPath: versionsDir,
+		},
+	}
+	folderFs := cfg.Filesystem()
+	versionsFs := fs.NewFilesystem(fs.FilesystemTypeBasic, versionsDir)
+
+	writeFile(t, versionsFs, "existing", "old")
+	v := newTrashcan(cfg)
+	versions, err := v.GetVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions["existing"]) != 1 || versions["existing"][0].Size != 3 {
+		t.Fatal("expected existing version to be loaded, got", versions)
+	}
+
+	writeFile(t, folderFs, "file", "content")
+	if err := v.Archive("file"); err != nil {
+		t.Fatal(err)
+	}
+	// Changes made behind the versioner's back aren't seen, as the
+	// versions are not walked again.
+	writeFile(t, versionsFs, "unseen", "x")
+
+	versions, err = v.GetVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || len(versions["file"]) != 1 || versions["file"][0].Size != 7 {
+		t.Fatal("unexpected versions", versions)
+	}
+
+	fd, err := v.OpenVersion("file", versions["file"][0].VersionTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := io.ReadAll(fd)
+	fd.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bs) != "content" {
+		t.Errorf("unexpected content %q", bs)
+	}
+
+	if _, err := v.OpenVersion("file", versions["file"][0].VersionTime.Add(-time.Hour)); !fs.IsNotExist(err) {
+		t.Error("expected missing version to not exist, got", err)
+	}
+
+	// Versions in a time range, which includes its start but not its end.
+	archived := versions["file"][0].VersionTime
+	if inRange, err := VersionsBetween(v, archived, time.Time{}); err != nil {
+		t.Fatal(err)
+	} else if len(inRange["file"]) != 1 {
+		t.Error("expected version archived at the start of the range, got", inRange)
+	}
+	if inRange, err := VersionsBetween(v, archived.Add(-time.Hour), archived); err != nil {
+		t.Fatal(err)
+	} else if _, ok := inRange["file"]; ok {
+		t.Error("expected no version archived at the end of the range, got", inRange)
+	}
+}
+
+func TestVersionIndexCatalog(t *testing.T) {
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sdb.Close() })
+	catalog := db.NewTyped(sdb, "versions/default/")
+
+	versionsDir := t.TempDir()
+	cfg := config.FolderConfiguration{
+		FilesystemType: config.FilesystemTypeBasic,
+		Path:           t.TempDir(),
+		Versioning: config.VersioningConfiguration{
+			FSType: config.FilesystemTypeBasic,
+			FSPath: versionsDir,
+		},
+	}
+	folderFs := cfg.Filesystem()
+	versionsFs := fs.NewFilesystem(fs.FilesystemTypeBasic, versionsDir)
+
+	v := newTrashcan(cfg)
+	if !UseCatalog(v, catalog) {
+		t.Fatal("trashcan should use the catalog")
+	}
+	writeFile(t, folderFs, "file", "content")
+	if err := ArchiveWithMetadata(v, "file", ArchiveMetadata{ModifiedBy: "AAAAAAA"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new versioner with the same catalog loads the versions from there,
+	// and doesn't see what's changed behind its back.
+	writeFile(t, versionsFs, "unseen", "x")
+	v = newTrashcan(cfg)
+	UseCatalog(v, catalog)
+	versions, err := v.GetVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || len(versions["file"]) != 1 || versions["file"][0].Origin != "AAAAAAA" {
+		t.Fatal("unexpected versions", versions)
+	}
+
+	// Until cleaning walks the versions.
+	v = newTrashcan(config.FolderConfiguration{
+		FilesystemType: cfg.FilesystemType,
+		Path:           cfg.Path,
+		Versioning: config.VersioningConfiguration{
+			FSType: cfg.Versioning.FSType,
+			FSPath: cfg.Versioning.FSPath,
+			Params: map[string]string{"cleanoutDays": "1"},
+		},
+	})
+	UseCatalog(v, catalog)
+	if err := v.Clean(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	versions, err = v.GetVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions["file"][0].Origin != "AAAAAAA" {
+		t.Fatal("unexpected versions", versions)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	metricVersionsPruned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "versioner",
+		Name:      "size_pruned_versions_total",
+		Help:      "Total number of versions removed to stay within the maximum versions size, per folder ID",
+	}, []string{"folder"})
+	metricVersionsPrunedBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "versioner",
+		Name:      "size_pruned_bytes_total",
+		Help:      "Total amount of disk space reclaimed by removing versions to stay within the maximum versions size, per folder ID",
+	}, []string{"folder"})
+)
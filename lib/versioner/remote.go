@@ -0,0 +1,165 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+// RemoteType is the versioning type of folders keeping their versions in
+// another folder, named by the "folder" parameter. The versioner is made
+// with NewRemote rather than New, as it needs the other folder.
+const RemoteType = "remote"
+
+// The remote versioner keeps versions like the simple versioner, but in a
+// directory of another folder instead of the versions directory. The
+// versions then become files of that folder and are synced along with it,
+// so a device can keep its history on another device or filesystem. The
+// directory is named by the "path" parameter, defaulting to the ID of the
+// versioned folder.
+type remote struct {
+	simple
+	target string
+	dir    string
+	notify func(paths []string)
+}
+
+// NewRemote returns the versioner for a folder with the remote versioning
+// type, keeping versions in the target folder. The notify function is
+// called with the paths in the target folder that have changed, so that
+// they can be scanned. If the target can't be used, the versioner fails
+// every operation, so that nothing is replaced or removed unversioned.
+func NewRemote(cfg, target config.FolderConfiguration, notify func(paths []string)) Versioner {
+	v, err := newRemote(cfg, target, notify)
+	if err != nil {
+		slog.Error("Versioning target unavailable", cfg.LogAttr(), slogutil.Error(err))
+		v = unavailable{err}
+	}
+	return &versionerWithErrorContext{
+		Versioner: v,
+		vtype:     RemoteType,
+	}
+}
+
+func newRemote(cfg, target config.FolderConfiguration, notify func(paths []string)) (Versioner, error) {
+	if target.ID == "" {
+		return nil, fmt.Errorf("target folder %q does not exist", cfg.Versioning.Params["folder"])
+	}
+	if target.ID == cfg.ID {
+		return nil, errors.New("target folder is the versioned folder")
+	}
+
+	keep, err := strconv.Atoi(cfg.Versioning.Params["keep"])
+	cleanoutDays, _ := strconv.Atoi(cfg.Versioning.Params["cleanoutDays"])
+	// On error we default to 0, "do not clean out the versioned items"
+
+	if err != nil {
+		keep = 5 // A reasonable default
+	}
+
+	dir := cfg.Versioning.Params["path"]
+	if dir == "" {
+		dir = cfg.ID
+	}
+	dir, err = fs.Canonicalize(dir)
+	if err != nil || dir == "" || fs.IsInternal(dir) {
+		return nil, fmt.Errorf("invalid versions path %q in target folder", cfg.Versioning.Params["path"])
+	}
+
+	targetFs := target.Filesystem()
+	versionsFs := fs.NewFilesystem(targetFs.Type(), filepath.Join(targetFs.URI(), dir))
+	v := &remote{
+		simple: simple{
+			keep:            keep,
+			cleanoutDays:    cleanoutDays,
+			policy:          retentionFromConfig(cfg),
+			sizeCap:         sizeCapFromConfig(cfg),
+			folderFs:        cfg.Filesystem(),
+			versionsFs:      versionsFs,
+			index:           newVersionIndex(versionsFs),
+			compress:        compressionFromParams(cfg.Versioning.Params),
+			copyRangeMethod: cfg.CopyRangeMethod.ToFS(),
+		},
+		target: target.ID,
+		dir:    dir,
+		notify: notify,
+	}
+
+	l.Debugf("instantiated %#v", v)
+	return v, nil
+}
+
+func (v *remote) String() string {
+	return fmt.Sprintf("remote(%s/%s)@%p", v.target, v.dir, v)
+}
+
+// Archive moves the named file away to the target folder. If this function
+// returns nil, the named file does not exist any more (has been archived).
+func (v *remote) Archive(filePath string) error {
+	return v.archive(filePath, "")
+}
+
+// ArchiveWithMetadata is like Archive, noting the device whose change has
+// the file archived.
+func (v *remote) ArchiveWithMetadata(filePath string, meta ArchiveMetadata) error {
+	return v.archive(filePath, meta.ModifiedBy)
+}
+
+func (v *remote) archive(filePath, origin string) error {
+	if err := v.simple.archive(filePath, origin); err != nil {
+		return err
+	}
+	v.notify([]string{filepath.Join(v.dir, filepath.Dir(filePath))})
+	return nil
+}
+
+func (v *remote) Restore(filePath string, versionTime time.Time) error {
+	err := v.simple.Restore(filePath, versionTime)
+	v.notify([]string{filepath.Join(v.dir, filepath.Dir(filePath))})
+	return err
+}
+
+func (v *remote) Clean(ctx context.Context) error {
+	err := v.simple.Clean(ctx)
+	v.notify([]string{v.dir})
+	return err
+}
+
+// unavailable is a versioner that can't do anything.
+type unavailable struct {
+	err error
+}
+
+func (v unavailable) Archive(_ string) error {
+	return v.err
+}
+
+func (v unavailable) GetVersions() (map[string][]FileVersion, error) {
+	return nil, v.err
+}
+
+func (v unavailable) OpenVersion(_ string, _ time.Time) (fs.File, error) {
+	return nil, v.err
+}
+
+func (v unavailable) Restore(_ string, _ time.Time) error {
+	return v.err
+}
+
+func (unavailable) Clean(_ context.Context) error {
+	return nil
+}
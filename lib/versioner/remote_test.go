@@ -0,0 +1,73 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+func TestRemoteVersioner(t *testing.T) {
+	cfg := config.FolderConfiguration{
+		ID:             "source",
+		FilesystemType: config.FilesystemTypeBasic,
+		Path:           t.TempDir(),
+		Versioning: config.VersioningConfiguration{
+			Type:   RemoteType,
+			Params: map[string]string{"folder": "target"},
+		},
+	}
+	target := config.FolderConfiguration{
+		ID:             "target",
+		FilesystemType: config.FilesystemTypeBasic,
+		Path:           t.TempDir(),
+	}
+	folderFs := cfg.Filesystem()
+	targetFs := target.Filesystem()
+
+	var notified []string
+	v := NewRemote(cfg, target, func(paths []string) {
+		notified = append(notified, paths...)
+	})
+
+	if err := folderFs.Mkdir("dir", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, folderFs, filepath.Join("dir", "file"), "content")
+	if err := v.Archive(filepath.Join("dir", "file")); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(notified, []string{filepath.Join("source", "dir")}) {
+		t.Error("unexpected notification", notified)
+	}
+	names, err := targetFs.Glob(filepath.Join("source", "dir", "file~*"))
+	if err != nil || len(names) != 1 {
+		t.Fatal("expected version in target folder, got", names, err)
+	}
+
+	versions, err := v.GetVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions[filepath.Join("dir", "file")]) != 1 {
+		t.Error("unexpected versions", versions)
+	}
+
+	// A versioner without a usable target refuses to archive, leaving the
+	// file in place.
+	v = NewRemote(cfg, config.FolderConfiguration{}, nil)
+	writeFile(t, folderFs, "other", "content")
+	if err := v.Archive("other"); err == nil {
+		t.Error("expected archiving without target to fail")
+	}
+	if readFile(t, folderFs, "other") != "content" {
+		t.Error("file should be left in place")
+	}
+}
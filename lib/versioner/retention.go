@@ -0,0 +1,83 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+// retentionFromConfig returns the configured retention policy, if any.
+func retentionFromConfig(cfg config.FolderConfiguration) config.RetentionPolicy {
+	policy, err := config.ParseRetentionPolicy(cfg.Versioning.RetentionPolicy)
+	if err != nil {
+		// Shouldn't happen, as the policy is validated with the config.
+		slog.Warn("Ignoring invalid retention policy", cfg.LogAttr(), slogutil.Error(err))
+		return nil
+	}
+	return policy
+}
+
+// retentionToRemove returns the versions of a file to remove under the
+// policy. Starting from the oldest version, a version is kept when it is
+// at least the interval of the rule for its age younger than the version
+// kept before it.
+func retentionToRemove(policy config.RetentionPolicy, versions []string, now time.Time) []string {
+	var prevAge time.Duration
+	firstFile := true
+	var remove []string
+
+	// The list of versions may or may not be properly sorted.
+	slices.Sort(versions)
+
+	last := policy[len(policy)-1]
+	for _, version := range versions {
+		versionTime, err := time.ParseInLocation(TimeFormat, extractTag(version), time.Local)
+		if err != nil {
+			l.Debugf("Versioner: file name %q is invalid: %v", version, err)
+			continue
+		}
+		age := now.Sub(versionTime).Truncate(time.Second)
+
+		// If the file is older than the age of the last rule, remove it
+		if last.For > 0 && age > last.For {
+			l.Debugln("Versioner: File over maximum age -> delete ", version)
+			remove = append(remove, version)
+			continue
+		}
+
+		// If it's the first (oldest) file in the list we can skip the interval checks
+		if firstFile {
+			prevAge = age
+			firstFile = false
+			continue
+		}
+
+		// Find the rule the file fits in
+		rule := last
+		for _, r := range policy {
+			if age < r.For {
+				rule = r
+				break
+			}
+		}
+
+		if prevAge-age < rule.Every {
+			l.Debugln("too many files in step -> delete", version)
+			remove = append(remove, version)
+			continue
+		}
+
+		prevAge = age
+	}
+
+	return remove
+}
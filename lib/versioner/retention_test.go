@@ -0,0 +1,45 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+func TestSimpleRetentionPolicy(t *testing.T) {
+	cfg := config.FolderConfiguration{
+		FilesystemType: config.FilesystemTypeBasic,
+		Path:           t.TempDir(),
+		Versioning: config.VersioningConfiguration{
+			Params:          map[string]string{"keep": "1"},
+			RetentionPolicy: "keep all for 1d, daily for 7d",
+		},
+	}
+	v := newSimple(cfg).(simple)
+
+	now := parseTime("20260410-120000")
+	versions := []string{
+		"test~20260410-110000", // 1 hour ago
+		"test~20260410-100000", // 2 hours ago
+		"test~20260408-120000", // 2 days ago
+		"test~20260408-110000", // 2 days 1 hour ago
+		"test~20260407-100000", // 3 days 2 hours ago
+		"test~20260401-120000", // 9 days ago
+	}
+	remove := v.toRemove(versions, now)
+	slices.Sort(remove)
+	expected := []string{
+		"test~20260401-120000", // older than the policy
+		"test~20260408-120000", // less than a day after the version before it
+	}
+	if !slices.Equal(remove, expected) {
+		t.Errorf("got %v, expected %v", remove, expected)
+	}
+}
@@ -24,8 +24,12 @@ func init() {
 type simple struct {
 	keep            int
 	cleanoutDays    int
+	policy          config.RetentionPolicy
+	sizeCap         sizeCap
 	folderFs        fs.Filesystem
 	versionsFs      fs.Filesystem
+	index           *versionIndex
+	compress        bool
 	copyRangeMethod fs.CopyRangeMethod
 }
 
@@ -38,11 +42,16 @@ func newSimple(cfg config.FolderConfiguration) Versioner {
 		keep = 5 // A reasonable default
 	}
 
+	versionsFs := versionerFsFromFolderCfg(cfg)
 	s := simple{
 		keep:            keep,
 		cleanoutDays:    cleanoutDays,
+		policy:          retentionFromConfig(cfg),
+		sizeCap:         sizeCapFromConfig(cfg),
 		folderFs:        cfg.Filesystem(),
-		versionsFs:      versionerFsFromFolderCfg(cfg),
+		versionsFs:      versionsFs,
+		index:           newVersionIndex(versionsFs),
+		compress:        compressionFromParams(cfg.Versioning.Params),
 		copyRangeMethod: cfg.CopyRangeMethod.ToFS(),
 	}
 
@@ -53,29 +62,61 @@ func newSimple(cfg config.FolderConfiguration) Versioner {
 // Archive moves the named file away to a version archive. If this function
 // returns nil, the named file does not exist any more (has been archived).
 func (v simple) Archive(filePath string) error {
-	err := archiveFile(v.copyRangeMethod, v.folderFs, v.versionsFs, filePath, TagFilename)
+	return v.archive(filePath, "")
+}
+
+// ArchiveWithMetadata is like Archive, noting the device whose change has
+// the file archived.
+func (v simple) ArchiveWithMetadata(filePath string, meta ArchiveMetadata) error {
+	return v.archive(filePath, meta.ModifiedBy)
+}
+
+func (v simple) archive(filePath, origin string) error {
+	dst, err := archiveFile(v.copyRangeMethod, v.folderFs, v.versionsFs, filePath, TagFilename)
 	if err != nil {
 		return err
 	}
+	if v.compress {
+		dst = compressVersion(v.versionsFs, dst)
+	}
+	v.index.record(dst, origin)
 
-	cleanVersions(v.versionsFs, findAllVersions(v.versionsFs, filePath), v.toRemove)
+	cleanVersions(v.versionsFs, v.index, findAllVersions(v.versionsFs, filePath), v.toRemove)
+	v.sizeCap.enforce(v.versionsFs, v.index)
 
 	return nil
 }
 
+func (v simple) indexed() *versionIndex {
+	return v.index
+}
+
 func (v simple) GetVersions() (map[string][]FileVersion, error) {
-	return retrieveVersions(v.versionsFs)
+	return v.index.versions()
+}
+
+func (v simple) OpenVersion(filePath string, versionTime time.Time) (fs.File, error) {
+	return v.index.open(filePath, versionTime)
 }
 
 func (v simple) Restore(filepath string, versionTime time.Time) error {
+	defer v.index.invalidate()
 	return restoreFile(v.copyRangeMethod, v.versionsFs, v.folderFs, filepath, versionTime, TagFilename)
 }
 
 func (v simple) Clean(ctx context.Context) error {
-	return clean(ctx, v.versionsFs, v.toRemove)
+	if err := clean(ctx, v.versionsFs, v.index, v.toRemove); err != nil {
+		return err
+	}
+	v.sizeCap.enforce(v.versionsFs, v.index)
+	return nil
 }
 
 func (v simple) toRemove(versions []string, now time.Time) []string {
+	if v.policy != nil {
+		return retentionToRemove(v.policy, versions, now)
+	}
+
 	var remove []string
 
 	// The list of versions may or may not be properly sorted.
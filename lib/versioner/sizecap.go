@@ -0,0 +1,83 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"log/slog"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+// A sizeCap limits the total size of the versions of a folder, as a size
+// or a percentage of the disk holding them. The oldest versions, of any
+// file, are removed when the limit is exceeded.
+type sizeCap struct {
+	folder string
+	max    config.Size
+}
+
+func sizeCapFromConfig(cfg config.FolderConfiguration) sizeCap {
+	return sizeCap{folder: cfg.ID, max: cfg.Versioning.MaxSize}
+}
+
+func (c sizeCap) limit(versionsFs fs.Filesystem) (int64, error) {
+	limit := c.max.BaseValue()
+	if limit <= 0 {
+		return 0, nil
+	}
+	if c.max.Percentage() {
+		usage, err := versionsFs.Usage(".")
+		if err != nil {
+			return 0, err
+		}
+		limit = float64(usage.Total) * limit / 100
+	}
+	return int64(limit), nil
+}
+
+// enforce removes the oldest versions until the rest fit within the cap.
+func (c sizeCap) enforce(versionsFs fs.Filesystem, index *versionIndex) {
+	limit, err := c.limit(versionsFs)
+	if err != nil {
+		l.Debugln("Versioner: no size limit for", c.folder, err)
+		return
+	}
+	if limit <= 0 {
+		return
+	}
+
+	versions, total, err := index.oldestFirst()
+	if err != nil {
+		slog.Warn("Failed to list versions for size limit", slog.String("folder", c.folder), slogutil.Error(err))
+		return
+	}
+	if total <= limit {
+		return
+	}
+
+	var removed int
+	var reclaimed int64
+	for _, v := range versions {
+		if total <= limit {
+			break
+		}
+		if err := versionsFs.Remove(v.path); err != nil && !fs.IsNotExist(err) {
+			slog.Warn("Failed to remove versioned file over size limit", slogutil.FilePath(v.path), slogutil.Error(err))
+			continue
+		}
+		index.update(v.path)
+		total -= v.diskSize
+		removed++
+		reclaimed += v.diskSize
+	}
+
+	metricVersionsPruned.WithLabelValues(c.folder).Add(float64(removed))
+	metricVersionsPrunedBytes.WithLabelValues(c.folder).Add(float64(reclaimed))
+	slog.Info("Removed oldest versions over size limit", slog.String("folder", c.folder), slog.Int("count", removed), slog.Int64("bytes", reclaimed))
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+func TestVersionsSizeCap(t *testing.T) {
+	versionsDir := t.TempDir()
+	cfg := config.FolderConfiguration{
+		ID:             "capped",
+		FilesystemType: config.FilesystemTypeBasic,
+		Path:           t.TempDir(),
+		Versioning: config.VersioningConfiguration{
+			FSType:  config.FilesystemTypeBasic,
+			FSPath:  versionsDir,
+			MaxSize: config.Size{Value: 10},
+		},
+	}
+	folderFs := cfg.Filesystem()
+	versionsFs := fs.NewFilesystem(fs.FilesystemTypeBasic, versionsDir)
+
+	writeFile(t, versionsFs, "b~20210101-000000", "bbbb")
+	writeFile(t, versionsFs, "a~20200101-000000", "aaaa")
+	writeFile(t, folderFs, "c", "cccc")
+
+	v := newSimple(cfg)
+	if err := v.Archive("c"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := versionsFs.Lstat("a~20200101-000000"); !fs.IsNotExist(err) {
+		t.Error("oldest version should have been removed:", err)
+	}
+	versions, err := v.GetVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || len(versions["b"]) != 1 || len(versions["c"]) != 1 {
+		t.Error("unexpected versions left", versions)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+var errSnapshotExists = errors.New("file already exists at destination")
+
+// RestoreSnapshot copies the files in dir of the folder, as they were at
+// the given time, to the same paths relative to dir in dst. Files that were
+// replaced or removed since are taken from their versions, the others from
+// the folder itself. Which content was current at the time is judged by
+// the version and modification times, so files that didn't exist yet are
+// left out. Existing files in dst are never overwritten. The returned map
+// holds the errors for the files that couldn't be restored.
+func RestoreSnapshot(v Versioner, folderFs fs.Filesystem, dir string, asOf time.Time, dst fs.Filesystem) (map[string]error, error) {
+	versions, err := v.GetVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	// The current files in dir, which are what the folder looked like at
+	// the time unless they have been versioned since.
+	current := make(map[string]fs.FileInfo)
+	err = folderFs.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fs.IsInternal(path) {
+			return fs.SkipDir
+		}
+		if info.IsRegular() && !fs.IsTemporary(path) {
+			current[path] = info
+		}
+		return nil
+	})
+	if err != nil && !fs.IsNotExist(err) {
+		return nil, err
+	}
+
+	restoreErrors := make(map[string]error)
+	restore := func(name string, modTime time.Time, open func() (io.ReadCloser, error)) {
+		rel, err := filepath.Rel(dir, name)
+		if err == nil {
+			err = copySnapshotFile(dst, rel, modTime, open)
+		}
+		if err != nil {
+			restoreErrors[name] = err
+		}
+	}
+
+	for name, fileVersions := range versions {
+		if !inSnapshotDir(dir, name) {
+			continue
+		}
+		// The content current at the time is that of the first version
+		// archived after it, unless it was written after the time too.
+		idx := slices.IndexFunc(fileVersions, func(fv FileVersion) bool {
+			return fv.VersionTime.After(asOf)
+		})
+		if idx < 0 {
+			continue
+		}
+		delete(current, name)
+		fv := fileVersions[idx]
+		if fv.ModTime.After(asOf) {
+			continue
+		}
+		restore(name, fv.ModTime, func() (io.ReadCloser, error) {
+			return v.OpenVersion(name, fv.VersionTime)
+		})
+	}
+
+	for name, info := range current {
+		if info.ModTime().After(asOf) {
+			continue
+		}
+		restore(name, info.ModTime(), func() (io.ReadCloser, error) {
+			return folderFs.Open(name)
+		})
+	}
+
+	return restoreErrors, nil
+}
+
+func inSnapshotDir(dir, name string) bool {
+	return dir == "." || dir == "" || name == dir || fs.IsParent(name, dir)
+}
+
+// copySnapshotFile writes the content to the named file in dst, by way of
+// a temporary file so that no partial file is left behind.
+func copySnapshotFile(dst fs.Filesystem, name string, modTime time.Time, open func() (io.ReadCloser, error)) error {
+	if _, err := dst.Lstat(name); err == nil {
+		return errSnapshotExists
+	} else if !fs.IsNotExist(err) {
+		return err
+	}
+	if err := dst.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	src, err := open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := fs.TempName(name)
+	fd, err := dst.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(fd, src); err != nil {
+		fd.Close()
+		_ = dst.Remove(tmp)
+		return err
+	}
+	if err := fd.Close(); err != nil {
+		_ = dst.Remove(tmp)
+		return err
+	}
+	_ = dst.Chtimes(tmp, modTime, modTime)
+	if err := dst.Rename(tmp, name); err != nil {
+		_ = dst.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+func TestRestoreSnapshot(t *testing.T) {
+	versionsDir := t.TempDir()
+	cfg := config.FolderConfiguration{
+		FilesystemType: config.FilesystemTypeBasic,
+		Path:           t.TempDir(),
+		Versioning: config.VersioningConfiguration{
+			FSType: config.FilesystemTypeBasic,
+			FSPath: versionsDir,
+		},
+	}
+	folderFs := cfg.Filesystem()
+	versionsFs := fs.NewFilesystem(fs.FilesystemTypeBasic, versionsDir)
+	dstFs := fs.NewFilesystem(fs.FilesystemTypeBasic, t.TempDir())
+
+	day := func(d int) time.Time {
+		return time.Date(2026, 1, d, 12, 0, 0, 0, time.Local)
+	}
+	write := func(fs fs.Filesystem, name, content string, modTime time.Time) {
+		t.Helper()
+		if err := fs.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, fs, name, content)
+		if err := fs.Chtimes(name, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	version := func(name, content string, modTime, versionTime time.Time) {
+		write(versionsFs, TagFilename(name, versionTime.Format(TimeFormat)), content, modTime)
+	}
+
+	// Replaced twice, last after the snapshot time.
+	version(filepath.Join("dir", "a"), "a1", day(1), day(5))
+	version(filepath.Join("dir", "a"), "a2", day(8), day(12))
+	write(folderFs, filepath.Join("dir", "a"), "a3", day(12))
+	// Unchanged since.
+	write(folderFs, filepath.Join("dir", "sub", "b"), "b", day(2))
+	// Created after the snapshot time.
+	write(folderFs, filepath.Join("dir", "c"), "c", day(11))
+	// Removed after the snapshot time.
+	version(filepath.Join("dir", "d"), "d", day(3), day(11))
+	// Outside of the restored directory.
+	write(folderFs, "e", "e", day(1))
+
+	v := newTrashcan(cfg)
+	restoreErrors, err := RestoreSnapshot(v, folderFs, "dir", day(10), dstFs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restoreErrors) != 0 {
+		t.Fatal("unexpected errors", restoreErrors)
+	}
+
+	if got := readFile(t, dstFs, "a"); got != "a2" {
+		t.Errorf("a: got %q, expected version a2", got)
+	}
+	if got := readFile(t, dstFs, filepath.Join("sub", "b")); got != "b" {
+		t.Errorf("b: got %q, expected current content", got)
+	}
+	if got := readFile(t, dstFs, "d"); got != "d" {
+		t.Errorf("d: got %q, expected removed file", got)
+	}
+	for _, name := range []string{"c", "e"} {
+		if _, err := dstFs.Lstat(name); !fs.IsNotExist(err) {
+			t.Errorf("%s should not be restored", name)
+		}
+	}
+
+	// Restoring again doesn't overwrite what's there.
+	restoreErrors, err = RestoreSnapshot(v, folderFs, "dir", day(10), dstFs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restoreErrors) != 3 {
+		t.Error("expected errors for existing files, got", restoreErrors)
+	}
+}
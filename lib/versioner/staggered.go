@@ -9,7 +9,6 @@ package versioner
 import (
 	"context"
 	"fmt"
-	"slices"
 	"strconv"
 	"time"
 
@@ -22,15 +21,13 @@ func init() {
 	factories["staggered"] = newStaggered
 }
 
-type interval struct {
-	step int64
-	end  int64
-}
-
 type staggered struct {
 	folderFs        fs.Filesystem
 	versionsFs      fs.Filesystem
-	interval        [4]interval
+	index           *versionIndex
+	compress        bool
+	policy          config.RetentionPolicy
+	sizeCap         sizeCap
 	copyRangeMethod fs.CopyRangeMethod
 }
 
@@ -41,17 +38,25 @@ func newStaggered(cfg config.FolderConfiguration) Versioner {
 		maxAge = 31536000 // Default: ~1 year
 	}
 
+	policy := retentionFromConfig(cfg)
+	if policy == nil {
+		policy = config.RetentionPolicy{
+			{Every: 30 * time.Second, For: time.Hour},                             // first hour -> 30 sec between versions
+			{Every: time.Hour, For: 24 * time.Hour},                               // next day -> 1 h between versions
+			{Every: 24 * time.Hour, For: 30 * 24 * time.Hour},                     // next 30 days -> 1 day between versions
+			{Every: 7 * 24 * time.Hour, For: time.Duration(maxAge) * time.Second}, // next year -> 1 week between versions
+		}
+	}
+
 	versionsFs := versionerFsFromFolderCfg(cfg)
 
 	s := &staggered{
-		folderFs:   cfg.Filesystem(),
-		versionsFs: versionsFs,
-		interval: [4]interval{
-			{30, 60 * 60},                     // first hour -> 30 sec between versions
-			{60 * 60, 24 * 60 * 60},           // next day -> 1 h between versions
-			{24 * 60 * 60, 30 * 24 * 60 * 60}, // next 30 days -> 1 day between versions
-			{7 * 24 * 60 * 60, maxAge},        // next year -> 1 week between versions
-		},
+		folderFs:        cfg.Filesystem(),
+		versionsFs:      versionsFs,
+		index:           newVersionIndex(versionsFs),
+		compress:        compressionFromParams(params),
+		policy:          policy,
+		sizeCap:         sizeCapFromConfig(cfg),
 		copyRangeMethod: cfg.CopyRangeMethod.ToFS(),
 	}
 
@@ -60,76 +65,59 @@ func newStaggered(cfg config.FolderConfiguration) Versioner {
 }
 
 func (v *staggered) Clean(ctx context.Context) error {
-	return clean(ctx, v.versionsFs, v.toRemove)
+	if err := clean(ctx, v.versionsFs, v.index, v.toRemove); err != nil {
+		return err
+	}
+	v.sizeCap.enforce(v.versionsFs, v.index)
+	return nil
 }
 
 func (v *staggered) toRemove(versions []string, now time.Time) []string {
-	var prevAge int64
-	firstFile := true
-	var remove []string
-
-	// The list of versions may or may not be properly sorted.
-	slices.Sort(versions)
-
-	for _, version := range versions {
-		versionTime, err := time.ParseInLocation(TimeFormat, extractTag(version), time.Local)
-		if err != nil {
-			l.Debugf("Versioner: file name %q is invalid: %v", version, err)
-			continue
-		}
-		age := int64(now.Sub(versionTime).Seconds())
-
-		// If the file is older than the max age of the last interval, remove it
-		if lastIntv := v.interval[len(v.interval)-1]; lastIntv.end > 0 && age > lastIntv.end {
-			l.Debugln("Versioner: File over maximum age -> delete ", version)
-			remove = append(remove, version)
-			continue
-		}
-
-		// If it's the first (oldest) file in the list we can skip the interval checks
-		if firstFile {
-			prevAge = age
-			firstFile = false
-			continue
-		}
-
-		// Find the interval the file fits in
-		var usedInterval interval
-		for _, usedInterval = range v.interval {
-			if age < usedInterval.end {
-				break
-			}
-		}
-
-		if prevAge-age < usedInterval.step {
-			l.Debugln("too many files in step -> delete", version)
-			remove = append(remove, version)
-			continue
-		}
-
-		prevAge = age
-	}
-
-	return remove
+	return retentionToRemove(v.policy, versions, now)
 }
 
 // Archive moves the named file away to a version archive. If this function
 // returns nil, the named file does not exist any more (has been archived).
 func (v *staggered) Archive(filePath string) error {
-	if err := archiveFile(v.copyRangeMethod, v.folderFs, v.versionsFs, filePath, TagFilename); err != nil {
+	return v.archive(filePath, "")
+}
+
+// ArchiveWithMetadata is like Archive, noting the device whose change has
+// the file archived.
+func (v *staggered) ArchiveWithMetadata(filePath string, meta ArchiveMetadata) error {
+	return v.archive(filePath, meta.ModifiedBy)
+}
+
+func (v *staggered) archive(filePath, origin string) error {
+	dst, err := archiveFile(v.copyRangeMethod, v.folderFs, v.versionsFs, filePath, TagFilename)
+	if err != nil {
 		return err
 	}
+	if v.compress {
+		dst = compressVersion(v.versionsFs, dst)
+	}
+	v.index.record(dst, origin)
 
-	cleanVersions(v.versionsFs, findAllVersions(v.versionsFs, filePath), v.toRemove)
+	cleanVersions(v.versionsFs, v.index, findAllVersions(v.versionsFs, filePath), v.toRemove)
+	v.sizeCap.enforce(v.versionsFs, v.index)
 
 	return nil
 }
 
+func (v *staggered) indexed() *versionIndex {
+	return v.index
+}
+
 func (v *staggered) GetVersions() (map[string][]FileVersion, error) {
-	return retrieveVersions(v.versionsFs)
+	return v.index.versions()
+}
+
+func (v *staggered) OpenVersion(filePath string, versionTime time.Time) (fs.File, error) {
+	return v.index.open(filePath, versionTime)
 }
 
 func (v *staggered) Restore(filepath string, versionTime time.Time) error {
+	defer v.index.invalidate()
 	return restoreFile(v.copyRangeMethod, v.versionsFs, v.folderFs, filepath, versionTime, TagFilename)
 }
 
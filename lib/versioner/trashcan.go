@@ -24,7 +24,9 @@ func init() {
 type trashcan struct {
 	folderFs        fs.Filesystem
 	versionsFs      fs.Filesystem
+	index           *versionIndex
 	cleanoutDays    int
+	sizeCap         sizeCap
 	copyRangeMethod fs.CopyRangeMethod
 }
 
@@ -32,10 +34,13 @@ func newTrashcan(cfg config.FolderConfiguration) Versioner {
 	cleanoutDays, _ := strconv.Atoi(cfg.Versioning.Params["cleanoutDays"])
 	// On error we default to 0, "do not clean out the trash can"
 
+	versionsFs := versionerFsFromFolderCfg(cfg)
 	s := &trashcan{
 		folderFs:        cfg.Filesystem(),
-		versionsFs:      versionerFsFromFolderCfg(cfg),
+		versionsFs:      versionsFs,
+		index:           newVersionIndex(versionsFs),
 		cleanoutDays:    cleanoutDays,
+		sizeCap:         sizeCapFromConfig(cfg),
 		copyRangeMethod: cfg.CopyRangeMethod.ToFS(),
 	}
 
@@ -46,9 +51,29 @@ func newTrashcan(cfg config.FolderConfiguration) Versioner {
 // Archive moves the named file away to a version archive. If this function
 // returns nil, the named file does not exist any more (has been archived).
 func (t *trashcan) Archive(filePath string) error {
-	return archiveFile(t.copyRangeMethod, t.folderFs, t.versionsFs, filePath, func(name, tag string) string {
+	return t.archive(filePath, "")
+}
+
+// ArchiveWithMetadata is like Archive, noting the device whose change has
+// the file archived.
+func (t *trashcan) ArchiveWithMetadata(filePath string, meta ArchiveMetadata) error {
+	return t.archive(filePath, meta.ModifiedBy)
+}
+
+func (t *trashcan) archive(filePath, origin string) error {
+	dst, err := archiveFile(t.copyRangeMethod, t.folderFs, t.versionsFs, filePath, func(name, tag string) string {
 		return name
 	})
+	t.index.record(dst, origin)
+	if err != nil {
+		return err
+	}
+	t.sizeCap.enforce(t.versionsFs, t.index)
+	return nil
+}
+
+func (t *trashcan) indexed() *versionIndex {
+	return t.index
 }
 
 func (t *trashcan) String() string {
@@ -56,6 +81,8 @@ func (t *trashcan) String() string {
 }
 
 func (t *trashcan) Clean(ctx context.Context) error {
+	defer t.sizeCap.enforce(t.versionsFs, t.index)
+
 	if t.cleanoutDays <= 0 {
 		return nil
 	}
@@ -66,6 +93,7 @@ func (t *trashcan) Clean(ctx context.Context) error {
 
 	cutoff := time.Now().Add(time.Duration(-24*t.cleanoutDays) * time.Hour)
 	dirTracker := make(emptyDirTracker)
+	var kept []string
 
 	walkFn := func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
@@ -86,10 +114,12 @@ func (t *trashcan) Clean(ctx context.Context) error {
 		if info.ModTime().Before(cutoff) {
 			// The file is too old; remove it.
 			err = t.versionsFs.Remove(path)
+			t.index.update(path)
 		} else {
 			// Keep this file, and remember it so we don't unnecessarily try
 			// to remove this directory.
 			dirTracker.addFile(path)
+			kept = append(kept, path)
 		}
 		return err
 	}
@@ -97,6 +127,7 @@ func (t *trashcan) Clean(ctx context.Context) error {
 	if err := t.versionsFs.Walk(".", walkFn); err != nil {
 		return err
 	}
+	t.index.reconcile(kept)
 
 	dirTracker.deleteEmptyDirs(t.versionsFs)
 
@@ -104,10 +135,16 @@ func (t *trashcan) Clean(ctx context.Context) error {
 }
 
 func (t *trashcan) GetVersions() (map[string][]FileVersion, error) {
-	return retrieveVersions(t.versionsFs)
+	return t.index.versions()
+}
+
+func (t *trashcan) OpenVersion(filePath string, versionTime time.Time) (fs.File, error) {
+	return t.index.open(filePath, versionTime)
 }
 
 func (t *trashcan) Restore(filepath string, versionTime time.Time) error {
+	defer t.index.invalidate()
+
 	// If we have an untagged file A and want to restore it on top of existing file A, we can't first archive the
 	// existing A as we'd overwrite the old A version, therefore when we archive existing file, we archive it with a
 	// tag but when the restoration is finished, we rename it (untag it). This is only important if when restoring A,
@@ -47,6 +47,7 @@ var tagExp = regexp.MustCompile(`.*~([^~.]+)(?:\.[^.]+)?$`)
 
 // extractTag returns the tag from a filename, whether at the end or middle.
 func extractTag(path string) string {
+	path, _ = trimCompressedSuffix(path)
 	match := tagExp.FindStringSubmatch(path)
 	// match is []string{"whole match", "submatch"} when successful
 
@@ -58,6 +59,7 @@ func extractTag(path string) string {
 
 // UntagFilename returns the filename without tag, and the extracted tag
 func UntagFilename(path string) (string, string) {
+	path, _ = trimCompressedSuffix(path)
 	ext := filepath.Ext(path)
 	versionTag := extractTag(path)
 
@@ -76,76 +78,53 @@ func UntagFilename(path string) (string, string) {
 	return name, versionTag
 }
 
-func retrieveVersions(fileSystem fs.Filesystem) (map[string][]FileVersion, error) {
-	files := make(map[string][]FileVersion)
-
-	err := fileSystem.Walk(".", func(path string, f fs.FileInfo, err error) error {
-		// Skip root (which is ok to be a symlink)
-		if path == "." {
-			return nil
-		}
-
-		// Skip walking if we cannot walk...
-		if err != nil {
-			return err
-		}
-
-		// Ignore symlinks
-		if f.IsSymlink() {
-			return fs.SkipDir
-		}
-
-		// No records for directories
-		if f.IsDir() {
-			return nil
-		}
-
-		modTime := f.ModTime().Truncate(time.Second)
+// versionOf returns the name of the file a version in the versions
+// filesystem is of, and the version, or false if it isn't one.
+func versionOf(path string, f fs.FileInfo) (string, FileVersion, bool) {
+	if fs.IsTemporary(path) {
+		// A version being compressed or a preview, not a version itself.
+		return "", FileVersion{}, false
+	}
 
-		path = osutil.NormalizedFilename(path)
+	modTime := f.ModTime().Truncate(time.Second)
 
-		name, tag := UntagFilename(path)
-		// Something invalid, assume it's an untagged file (trashcan versioner stuff)
-		if name == "" || tag == "" {
-			files[path] = append(files[path], FileVersion{
-				VersionTime: modTime,
-				ModTime:     modTime,
-				Size:        f.Size(),
-			})
-			return nil
-		}
-
-		versionTime, err := time.ParseInLocation(TimeFormat, tag, time.Local)
-		if err != nil {
-			// Can't parse it, welp, continue
-			return nil
-		}
+	path = osutil.NormalizedFilename(path)
 
-		files[name] = append(files[name], FileVersion{
-			VersionTime: versionTime,
+	name, tag := UntagFilename(path)
+	// Something invalid, assume it's an untagged file (trashcan versioner stuff)
+	if name == "" || tag == "" {
+		return path, FileVersion{
+			VersionTime: modTime,
 			ModTime:     modTime,
 			Size:        f.Size(),
-		})
+		}, true
+	}
 
-		return nil
-	})
+	versionTime, err := time.ParseInLocation(TimeFormat, tag, time.Local)
 	if err != nil {
-		return nil, err
+		// Can't parse it, welp, continue
+		return "", FileVersion{}, false
 	}
 
-	return files, nil
+	return name, FileVersion{
+		VersionTime: versionTime,
+		ModTime:     modTime,
+		Size:        f.Size(),
+	}, true
 }
 
 type fileTagger func(string, string) string
 
-func archiveFile(method fs.CopyRangeMethod, srcFs, dstFs fs.Filesystem, filePath string, tagger fileTagger) error {
+// archiveFile moves the file to the versions filesystem, returning the path
+// of the new version there, if any.
+func archiveFile(method fs.CopyRangeMethod, srcFs, dstFs fs.Filesystem, filePath string, tagger fileTagger) (string, error) {
 	filePath = osutil.NativeFilename(filePath)
 	info, err := srcFs.Lstat(filePath)
 	if fs.IsNotExist(err) {
 		l.Debugln("not archiving nonexistent file", filePath)
-		return nil
+		return "", nil
 	} else if err != nil {
-		return err
+		return "", err
 	}
 	if info.IsSymlink() {
 		panic("bug: attempting to version a symlink")
@@ -157,11 +136,11 @@ func archiveFile(method fs.CopyRangeMethod, srcFs, dstFs fs.Filesystem, filePath
 			slog.Debug("Creating versions dir")
 			err := dstFs.MkdirAll(".", 0o755)
 			if err != nil {
-				return err
+				return "", err
 			}
 			_ = dstFs.Hide(".")
 		} else {
-			return err
+			return "", err
 		}
 	}
 
@@ -170,7 +149,7 @@ func archiveFile(method fs.CopyRangeMethod, srcFs, dstFs fs.Filesystem, filePath
 	err = dupDirTree(srcFs, dstFs, inFolderPath)
 	if err != nil {
 		l.Debugln("archiving", filePath, err)
-		return err
+		return "", err
 	}
 
 	now := time.Now()
@@ -189,7 +168,7 @@ func archiveFile(method fs.CopyRangeMethod, srcFs, dstFs fs.Filesystem, filePath
 
 	_ = dstFs.Chtimes(dst, mtime, mtime)
 
-	return err
+	return dst, err
 }
 
 func dupDirTree(srcFs, dstFs fs.Filesystem, folderPath string) error {
@@ -252,7 +231,7 @@ func restoreFile(method fs.CopyRangeMethod, src, dst fs.Filesystem, filePath str
 				return fmt.Errorf("removing existing symlink: %w", err)
 			}
 		case info.IsRegular():
-			if err := archiveFile(method, dst, src, filePath, tagger); err != nil {
+			if _, err := archiveFile(method, dst, src, filePath, tagger); err != nil {
 				return fmt.Errorf("archiving existing file: %w", err)
 			}
 		default:
@@ -276,6 +255,16 @@ func restoreFile(method fs.CopyRangeMethod, src, dst fs.Filesystem, filePath str
 		l.Debugln("restore:", taggedFilePath, err.Error())
 	}
 
+	// Check for compressed file
+	compressed := false
+	if sourceFile == "" {
+		if info, err := src.Lstat(taggedFilePath + compressedSuffix); err == nil && info.IsRegular() {
+			sourceFile = taggedFilePath + compressedSuffix
+			sourceMtime = info.ModTime()
+			compressed = true
+		}
+	}
+
 	// Check for untagged file
 	if sourceFile == "" {
 		info, err := src.Lstat(filePath)
@@ -299,7 +288,12 @@ func restoreFile(method fs.CopyRangeMethod, src, dst fs.Filesystem, filePath str
 	}
 
 	_ = dst.MkdirAll(filepath.Dir(filePath), 0o755)
-	err := osutil.RenameOrCopy(method, src, dst, sourceFile, filePath)
+	var err error
+	if compressed {
+		err = decompressVersion(src, dst, sourceFile, filePath)
+	} else {
+		err = osutil.RenameOrCopy(method, src, dst, sourceFile, filePath)
+	}
 	_ = dst.Chtimes(filePath, sourceMtime, sourceMtime)
 	return err
 }
@@ -340,13 +334,19 @@ func findAllVersions(fs fs.Filesystem, filePath string) []string {
 		slog.Warn("Failed to glob for versions", slog.String("pattern", pattern), slogutil.Error(err))
 		return nil
 	}
+	compressed, err := fs.Glob(pattern + compressedSuffix)
+	if err != nil {
+		slog.Warn("Failed to glob for versions", slog.String("pattern", pattern+compressedSuffix), slogutil.Error(err))
+		return nil
+	}
+	versions = append(versions, compressed...)
 	versions = stringutil.UniqueTrimmedStrings(versions)
 	slices.Sort(versions)
 
 	return versions
 }
 
-func clean(ctx context.Context, versionsFs fs.Filesystem, toRemove func([]string, time.Time) []string) error {
+func clean(ctx context.Context, versionsFs fs.Filesystem, index *versionIndex, toRemove func([]string, time.Time) []string) error {
 	l.Debugln("Versioner clean: Cleaning", versionsFs)
 
 	if _, err := versionsFs.Stat("."); fs.IsNotExist(err) {
@@ -356,6 +356,7 @@ func clean(ctx context.Context, versionsFs fs.Filesystem, toRemove func([]string
 
 	versionsPerFile := make(map[string][]string)
 	dirTracker := make(emptyDirTracker)
+	var files []string
 
 	walkFn := func(path string, f fs.FileInfo, err error) error {
 		if err != nil {
@@ -374,6 +375,7 @@ func clean(ctx context.Context, versionsFs fs.Filesystem, toRemove func([]string
 
 		// Regular file, or possibly a symlink.
 		dirTracker.addFile(path)
+		files = append(files, path)
 
 		name, _ := UntagFilename(path)
 		if name == "" {
@@ -391,6 +393,7 @@ func clean(ctx context.Context, versionsFs fs.Filesystem, toRemove func([]string
 		}
 		return err
 	}
+	index.reconcile(files)
 
 	for _, versionList := range versionsPerFile {
 		select {
@@ -398,7 +401,7 @@ func clean(ctx context.Context, versionsFs fs.Filesystem, toRemove func([]string
 			return ctx.Err()
 		default:
 		}
-		cleanVersions(versionsFs, versionList, toRemove)
+		cleanVersions(versionsFs, index, versionList, toRemove)
 	}
 
 	dirTracker.deleteEmptyDirs(versionsFs)
@@ -407,11 +410,12 @@ func clean(ctx context.Context, versionsFs fs.Filesystem, toRemove func([]string
 	return nil
 }
 
-func cleanVersions(versionsFs fs.Filesystem, versions []string, toRemove func([]string, time.Time) []string) {
+func cleanVersions(versionsFs fs.Filesystem, index *versionIndex, versions []string, toRemove func([]string, time.Time) []string) {
 	l.Debugln("Versioner: Expiring versions", versions)
 	for _, file := range toRemove(versions, time.Now()) {
 		if err := versionsFs.Remove(file); err != nil {
 			slog.Warn("Failed to remove versioned file during cleanup", slogutil.FilePath(file), slogutil.Error(err))
 		}
+		index.update(file)
 	}
 }
@@ -12,28 +12,120 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
 )
 
 type Versioner interface {
 	Archive(filePath string) error
 	GetVersions() (map[string][]FileVersion, error)
+	// OpenVersion opens the given version of the file for reading, without
+	// restoring it.
+	OpenVersion(filePath string, versionTime time.Time) (fs.File, error)
 	Restore(filePath string, versionTime time.Time) error
 	Clean(context.Context) error
 }
 
+// ArchiveMetadata describes the change that has a file archived.
+type ArchiveMetadata struct {
+	Folder     string            `json:"folder"`
+	Path       string            `json:"path"`
+	OldVersion map[string]uint64 `json:"oldVersion"`
+	NewVersion map[string]uint64 `json:"newVersion"`
+	ModifiedBy string            `json:"modifiedBy"`
+	Conflict   bool              `json:"conflict"`
+}
+
+// A MetadataArchiver is a Versioner that can make use of the metadata of
+// the change when archiving.
+type MetadataArchiver interface {
+	ArchiveWithMetadata(filePath string, meta ArchiveMetadata) error
+}
+
+// ArchiveWithMetadata archives the file with the metadata, if the
+// versioner makes use of it, or like Archive otherwise.
+func ArchiveWithMetadata(v Versioner, filePath string, meta ArchiveMetadata) error {
+	if ma, ok := v.(MetadataArchiver); ok {
+		return ma.ArchiveWithMetadata(filePath, meta)
+	}
+	return v.Archive(filePath)
+}
+
+// indexed is a versioner keeping a versionIndex.
+type indexed interface {
+	indexed() *versionIndex
+}
+
+// UseCatalog makes the versioner keep its index of versions in the given
+// store as well, so that it's loaded from there rather than by walking all
+// versions after a restart. It returns false if the versioner has no index.
+func UseCatalog(v Versioner, catalog *db.Typed) bool {
+	if w, ok := v.(*versionerWithErrorContext); ok {
+		v = w.Versioner
+	}
+	x, ok := v.(indexed)
+	if !ok {
+		return false
+	}
+	x.indexed().setCatalog(catalog)
+	return true
+}
+
+// VersionsBetween returns the versions archived in the range [from, to),
+// where a zero time leaves that end open. Versioners keeping an index of
+// versions look only at the ones in range.
+func VersionsBetween(v Versioner, from, to time.Time) (map[string][]FileVersion, error) {
+	if w, ok := v.(*versionerWithErrorContext); ok {
+		versions, err := VersionsBetween(w.Versioner, from, to)
+		return versions, w.wrapError(err, "get versions")
+	}
+	if x, ok := v.(indexed); ok {
+		return x.indexed().versionsBetween(from, to)
+	}
+
+	versions, err := v.GetVersions()
+	if err != nil {
+		return nil, err
+	}
+	for name, fileVersions := range versions {
+		fileVersions = slices.DeleteFunc(fileVersions, func(v FileVersion) bool {
+			return !inRange(v.VersionTime, from, to)
+		})
+		if len(fileVersions) == 0 {
+			delete(versions, name)
+		} else {
+			versions[name] = fileVersions
+		}
+	}
+	return versions, nil
+}
+
+func inRange(t, from, to time.Time) bool {
+	return !t.Before(from) && (to.IsZero() || t.Before(to))
+}
+
 type FileVersion struct {
 	VersionTime time.Time `json:"versionTime"`
 	ModTime     time.Time `json:"modTime"`
 	Size        int64     `json:"size"`
+	Origin      string    `json:"origin,omitempty"` // short ID of the device whose change archived it, when known
 }
 
 type factory func(cfg config.FolderConfiguration) Versioner
 
 var factories = make(map[string]factory)
 
+// Register makes a versioner available under the given versioning type.
+// It is meant to be called from init functions, as factories are not
+// protected against concurrent access.
+func Register(vtype string, fn func(cfg config.FolderConfiguration) Versioner) {
+	factories[vtype] = fn
+}
+
 var ErrRestorationNotSupported = errors.New("version restoration not supported with the current versioner")
 
 const (
@@ -70,11 +162,20 @@ func (v *versionerWithErrorContext) Archive(filePath string) error {
 	return v.wrapError(v.Versioner.Archive(filePath), "archive")
 }
 
+func (v *versionerWithErrorContext) ArchiveWithMetadata(filePath string, meta ArchiveMetadata) error {
+	return v.wrapError(ArchiveWithMetadata(v.Versioner, filePath, meta), "archive")
+}
+
 func (v *versionerWithErrorContext) GetVersions() (map[string][]FileVersion, error) {
 	versions, err := v.Versioner.GetVersions()
 	return versions, v.wrapError(err, "get versions")
 }
 
+func (v *versionerWithErrorContext) OpenVersion(filePath string, versionTime time.Time) (fs.File, error) {
+	fd, err := v.Versioner.OpenVersion(filePath, versionTime)
+	return fd, v.wrapError(err, "open version")
+}
+
 func (v *versionerWithErrorContext) Restore(filePath string, versionTime time.Time) error {
 	return v.wrapError(v.Versioner.Restore(filePath, versionTime), "restore")
 }
@@ -0,0 +1,126 @@
+// Copyright (C) 2026 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build ignore
+// +build ignore
+
+// Builds the zstd dictionary used to compress index messages, from the
+// FileInfos of the files in a directory tree, as in
+//
+//	go run script/indexdict.go -dir ~/Sync -o lib/protocol/index.dict
+//
+// A rebuilt dictionary needs a new ID, as devices only use dictionaries
+// they both have.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/scanner"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "Directory to sample files from")
+	out := flag.String("o", "index.dict", "Dictionary file to write")
+	id := flag.Uint("id", 1, "Dictionary ID")
+	maxFiles := flag.Int("files", 10000, "Maximum number of files to sample")
+	batchSize := flag.Int("batch", 100, "Files per sample, as in an index message")
+	historySize := flag.Int("history", 32<<10, "Size of the dictionary history")
+	flag.Parse()
+
+	ctx := context.Background()
+	filesystem := fs.NewFilesystem(fs.FilesystemTypeBasic, *dir)
+	short := protocol.LocalDeviceID.Short()
+
+	var samples [][]byte
+	batch := &bep.IndexUpdate{Folder: "default"}
+	var files int
+	err := filesystem.Walk(".", func(path string, info fs.FileInfo, err error) error {
+		if err != nil || fs.IsInternal(path) {
+			return nil //nolint:nilerr
+		}
+		if path == "." || files >= *maxFiles {
+			return nil
+		}
+		fi, err := scanner.CreateFileInfo(info, path, filesystem, true, false, false, false, nil, nil)
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+		if fi.Type == protocol.FileInfoTypeFile {
+			fd, err := filesystem.Open(path)
+			if err != nil {
+				return nil //nolint:nilerr
+			}
+			fi.RawBlockSize = int32(protocol.BlockSize(fi.Size))
+			fi.Blocks, err = scanner.Blocks(ctx, fd, int(fi.RawBlockSize), fi.Size, nil)
+			fd.Close()
+			if err != nil {
+				return nil //nolint:nilerr
+			}
+		}
+		files++
+		fi.Sequence = int64(files)
+		fi.ModifiedBy = short
+		fi.Version = protocol.Vector{}.Update(short)
+
+		batch.Files = append(batch.Files, fi.ToWire(false))
+		if len(batch.Files) == *batchSize {
+			samples = append(samples, marshal(batch))
+			batch.Files = nil
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(batch.Files) > 0 {
+		samples = append(samples, marshal(batch))
+	}
+	if len(samples) == 0 {
+		log.Fatal("no files to sample")
+	}
+
+	var history []byte
+	for _, s := range samples {
+		history = append(history, s...)
+		if len(history) >= *historySize {
+			break
+		}
+	}
+	history = history[:min(len(history), *historySize)]
+
+	dict, err := zstd.BuildDict(zstd.BuildDictOptions{
+		ID:       uint32(*id),
+		Contents: samples,
+		History:  history,
+		Offsets:  [3]int{1, 4, 8},
+		Level:    zstd.SpeedDefault,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, dict, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %d byte dictionary from %d files", len(dict), files)
+}
+
+func marshal(msg proto.Message) []byte {
+	bs, err := proto.Marshal(msg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return bs
+}